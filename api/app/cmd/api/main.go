@@ -11,6 +11,7 @@ import (
 	"hrms/application"
 	"hrms/config"
 	"hrms/modules/activitylog"
+	"hrms/modules/approval"
 	"hrms/modules/auth"
 	"hrms/modules/bonus"
 	"hrms/modules/branch"
@@ -18,6 +19,8 @@ import (
 	"hrms/modules/dashboard"
 	"hrms/modules/debt"
 	"hrms/modules/employee"
+	"hrms/modules/holiday"
+	"hrms/modules/leave"
 	"hrms/modules/masterdata"
 	"hrms/modules/payoutpt"
 	"hrms/modules/payrollconfig"
@@ -25,6 +28,7 @@ import (
 	"hrms/modules/payrollrun"
 	"hrms/modules/salaryadvance"
 	"hrms/modules/salaryraise"
+	"hrms/modules/shift"
 	"hrms/modules/superadmin"
 	"hrms/modules/tenant"
 	"hrms/modules/user"
@@ -98,11 +102,15 @@ func main() {
 		debt.NewModule(mCtx, tokenSvc),
 		payoutpt.NewModule(mCtx, tokenSvc),
 		masterdata.NewModule(mCtx, tokenSvc),
-		payrollrun.NewModule(mCtx, tokenSvc),
+		payrollrun.NewModule(mCtx, tokenSvc, cfg.PDFFontDir),
 		worklog.NewModule(mCtx, tokenSvc),
 		payrollorgprofile.NewModule(mCtx, tokenSvc),
 		activitylog.NewModule(mCtx, tokenSvc),
 		dashboard.NewModule(mCtx, tokenSvc),
+		approval.NewModule(mCtx, tokenSvc),
+		holiday.NewModule(mCtx, tokenSvc),
+		leave.NewModule(mCtx, tokenSvc),
+		shift.NewModule(mCtx, tokenSvc),
 	)
 
 	app.Run()
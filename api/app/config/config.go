@@ -18,6 +18,7 @@ type Config struct {
 	RefreshTokenTTL  time.Duration `env:"JWT_REFRESH_TTL" envDefault:"720h"` // default 30d
 	GracefulTimeout  time.Duration `env:"GRACEFUL_TIMEOUT" envDefault:"10s"`
 	AllowedOrigins   []string      `env:"ALLOWED_ORIGINS" envDefault:"http://localhost:3000,http://127.0.0.1:3000,http://127.0.0.1:8080" envSeparator:","`
+	PDFFontDir       string        `env:"PDF_FONT_DIR" envDefault:"/app/fonts"` // Sarabun-Regular.ttf / Sarabun-Bold.ttf
 }
 
 func Load() (*Config, error) {
@@ -48,12 +48,21 @@ replace hrms/shared/contracts v0.0.0 => ../shared/contracts
 
 replace hrms/modules/tenant v0.0.0 => ../modules/tenant
 
+replace hrms/modules/approval v0.0.0 => ../modules/approval
+
+replace hrms/modules/holiday v0.0.0 => ../modules/holiday
+
+replace hrms/modules/leave v0.0.0 => ../modules/leave
+
+replace hrms/modules/shift v0.0.0 => ../modules/shift
+
 require (
 	github.com/caarlos0/env/v11 v11.1.0
 	github.com/gofiber/fiber/v3 v3.0.0-rc.3
 	github.com/somprasongd/fiber-swagger v1.0.1
 	github.com/swaggo/swag/v2 v2.0.0-rc4
 	hrms/modules/activitylog v0.0.0
+	hrms/modules/approval v0.0.0
 	hrms/modules/auth v0.0.0
 	hrms/modules/bonus v0.0.0
 	hrms/modules/branch v0.0.0
@@ -61,6 +70,8 @@ require (
 	hrms/modules/dashboard v0.0.0
 	hrms/modules/debt v0.0.0
 	hrms/modules/employee v0.0.0
+	hrms/modules/holiday v0.0.0
+	hrms/modules/leave v0.0.0
 	hrms/modules/masterdata v0.0.0
 	hrms/modules/payoutpt v0.0.0
 	hrms/modules/payrollconfig v0.0.0
@@ -68,6 +79,7 @@ require (
 	hrms/modules/payrollrun v0.0.0
 	hrms/modules/salaryadvance v0.0.0
 	hrms/modules/salaryraise v0.0.0
+	hrms/modules/shift v0.0.0
 	hrms/modules/superadmin v0.0.0
 	hrms/modules/tenant v0.0.0
 	hrms/modules/user v0.0.0
@@ -93,6 +105,7 @@ require (
 	github.com/google/uuid v1.6.0 // indirect
 	github.com/jmoiron/sqlx v1.4.0 // indirect
 	github.com/josharian/intern v1.0.0 // indirect
+	github.com/jung-kurt/gofpdf v1.16.2 // indirect
 	github.com/klauspost/compress v1.18.2 // indirect
 	github.com/leodido/go-urn v1.4.0 // indirect
 	github.com/lib/pq v1.10.9 // indirect
@@ -0,0 +1,120 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/approval/internal/repository"
+	"hrms/shared/common/errs"
+	"hrms/shared/contracts"
+)
+
+type Chain struct {
+	ID        uuid.UUID   `json:"id"`
+	BranchID  *uuid.UUID  `json:"branchId,omitempty"`
+	DocType   string      `json:"docType"`
+	Name      string      `json:"name"`
+	IsActive  bool        `json:"isActive"`
+	Steps     []ChainStep `json:"steps"`
+	CreatedAt time.Time   `json:"createdAt"`
+	UpdatedAt time.Time   `json:"updatedAt"`
+}
+
+type ChainStep struct {
+	StepNo         int        `json:"stepNo"`
+	Name           string     `json:"name"`
+	ApproverRole   *string    `json:"approverRole,omitempty"`
+	ApproverUserID *uuid.UUID `json:"approverUserId,omitempty"`
+}
+
+func FromChain(c repository.Chain) Chain {
+	steps := make([]ChainStep, 0, len(c.Steps))
+	for _, s := range c.Steps {
+		steps = append(steps, ChainStep{
+			StepNo:         s.StepNo,
+			Name:           s.Name,
+			ApproverRole:   s.ApproverRole,
+			ApproverUserID: s.ApproverUserID,
+		})
+	}
+	return Chain{
+		ID:        c.ID,
+		BranchID:  c.BranchID,
+		DocType:   c.DocType,
+		Name:      c.Name,
+		IsActive:  c.IsActive,
+		Steps:     steps,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
+
+func FromRequest(r repository.Request) contracts.ApprovalRequestDTO {
+	steps := make([]contracts.ApprovalStepDTO, 0, len(r.Steps))
+	for _, s := range r.Steps {
+		steps = append(steps, contracts.ApprovalStepDTO{
+			StepNo:         s.StepNo,
+			Name:           s.Name,
+			ApproverRole:   s.ApproverRole,
+			ApproverUserID: s.ApproverUserID,
+			Status:         s.Status,
+			ActedBy:        s.ActedBy,
+			ActedAt:        s.ActedAt,
+			Comment:        s.Comment,
+		})
+	}
+	return contracts.ApprovalRequestDTO{
+		ID:            r.ID,
+		DocType:       r.DocType,
+		DocID:         r.DocID,
+		Status:        r.Status,
+		CurrentStep:   r.CurrentStep,
+		StepCount:     r.StepCount,
+		PreparedBy:    r.PreparedBy,
+		SubmitComment: r.SubmitComment,
+		SubmittedAt:   r.SubmittedAt,
+		ClosedAt:      r.ClosedAt,
+		ClosedBy:      r.ClosedBy,
+		Steps:         steps,
+	}
+}
+
+func FromRequests(rs []repository.Request) []contracts.ApprovalRequestDTO {
+	out := make([]contracts.ApprovalRequestDTO, 0, len(rs))
+	for _, r := range rs {
+		out = append(out, FromRequest(r))
+	}
+	return out
+}
+
+// StepInput is one step of a chain as sent by the client; steps are numbered in the order given.
+type StepInput struct {
+	Name           string     `json:"name" validate:"required,max=100"`
+	ApproverRole   *string    `json:"approverRole" validate:"omitempty,oneof=admin hr"`
+	ApproverUserID *uuid.UUID `json:"approverUserId"`
+}
+
+func ToSteps(in []StepInput) []repository.ChainStep {
+	steps := make([]repository.ChainStep, 0, len(in))
+	for i, s := range in {
+		steps = append(steps, repository.ChainStep{
+			StepNo:         i + 1,
+			Name:           s.Name,
+			ApproverRole:   s.ApproverRole,
+			ApproverUserID: s.ApproverUserID,
+		})
+	}
+	return steps
+}
+
+// ValidateSteps checks what the struct tags cannot: every step names an approver.
+func ValidateSteps(in []StepInput) error {
+	for i, s := range in {
+		if s.ApproverRole == nil && s.ApproverUserID == nil {
+			return errs.BadRequest(fmt.Sprintf("steps[%d] needs approverRole or approverUserId", i))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package cancel
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"hrms/modules/approval/internal/repository"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/contracts"
+)
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*contracts.CancelApprovalCommand, *contracts.CancelApprovalResponse] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+// Handle withdraws the document's open request; only its preparer or an admin may do so.
+func (h *Handler) Handle(ctx context.Context, cmd *contracts.CancelApprovalCommand) (*contracts.CancelApprovalResponse, error) {
+	req, err := h.repo.FindOpenRequest(ctx, cmd.CompanyID, cmd.DocType, cmd.DocID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &contracts.CancelApprovalResponse{Cancelled: false}, nil
+		}
+		logger.FromContext(ctx).Error("failed to load approval request", zap.Error(err), zap.String("doc_id", cmd.DocID.String()))
+		return nil, errs.Internal("failed to load approval request")
+	}
+	if req.PreparedBy != cmd.ActorID && cmd.ActorRole != "admin" {
+		return nil, errs.Forbidden("only the preparer or an admin can withdraw this document")
+	}
+	if err := h.repo.CancelRequest(ctx, req.ID, cmd.ActorID); err != nil {
+		logger.FromContext(ctx).Error("failed to cancel approval request", zap.Error(err), zap.String("request_id", req.ID.String()))
+		return nil, errs.Internal("failed to withdraw approval request")
+	}
+	return &contracts.CancelApprovalResponse{Cancelled: true}, nil
+}
@@ -0,0 +1,113 @@
+package create
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/approval/internal/dto"
+	"hrms/modules/approval/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/storage/sqldb/transactor"
+	"hrms/shared/common/validator"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	DocType  string          `json:"docType" validate:"required,oneof=payroll_run bonus_cycle salary_raise_cycle debt_txn leave_request"`
+	Name     string          `json:"name" validate:"required,max=200"`
+	BranchID *uuid.UUID      `json:"branchId"`
+	IsActive *bool           `json:"isActive"`
+	Steps    []dto.StepInput `json:"steps" validate:"required,min=1,max=10,dive"`
+}
+
+type Response struct {
+	dto.Chain
+}
+
+type Handler struct {
+	repo repository.Repository
+	tx   transactor.Transactor
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, tx transactor.Transactor, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, tx: tx, eb: eb}
+}
+
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	cmd.DocType = strings.TrimSpace(cmd.DocType)
+	cmd.Name = strings.TrimSpace(cmd.Name)
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+	if err := dto.ValidateSteps(cmd.Steps); err != nil {
+		return nil, err
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+	// a branch user can only set up chains for the branch they work in
+	if tenant.HasBranchID() && cmd.BranchID != nil && *cmd.BranchID != tenant.BranchID {
+		return nil, errs.Forbidden("branchId must be the selected branch")
+	}
+
+	chain := repository.Chain{
+		CompanyID: tenant.CompanyID,
+		BranchID:  cmd.BranchID,
+		DocType:   cmd.DocType,
+		Name:      cmd.Name,
+		IsActive:  cmd.IsActive == nil || *cmd.IsActive,
+		Steps:     dto.ToSteps(cmd.Steps),
+	}
+
+	var created *repository.Chain
+	err := h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
+		var err error
+		created, err = h.repo.CreateChain(ctxTx, chain, user.ID)
+		return err
+	})
+	if err != nil {
+		if repository.IsUniqueViolation(err) {
+			return nil, errs.Conflict("an active approval chain already exists for this document type")
+		}
+		if repository.IsForeignKeyViolation(err) {
+			return nil, errs.BadRequest("branch or approver user not found")
+		}
+		logger.FromContext(ctx).Error("failed to create approval chain", zap.Error(err))
+		return nil, errs.Internal("failed to create approval chain")
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   created.BranchID,
+		Action:     "CREATE",
+		EntityName: "APPROVAL_CHAIN",
+		EntityID:   created.ID.String(),
+		Details: map[string]interface{}{
+			"doc_type":  created.DocType,
+			"name":      created.Name,
+			"is_active": created.IsActive,
+			"steps":     len(created.Steps),
+		},
+		Timestamp: time.Now(),
+	})
+
+	return &Response{Chain: dto.FromChain(*created)}, nil
+}
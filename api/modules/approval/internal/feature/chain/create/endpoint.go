@@ -0,0 +1,39 @@
+package create
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// Create approval chain
+// @Summary Create approval chain
+// @Description สร้างลำดับการอนุมัติของเอกสาร (payroll_run, bonus_cycle, salary_raise_cycle, debt_txn, leave_request) แต่ละขั้นกำหนด role หรือผู้อนุมัติเจาะจง
+// @Tags Approvals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body Command true "chain payload"
+// @Success 201 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 409
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /approval-chains [post]
+func NewEndpoint(router fiber.Router) {
+	router.Post("/", func(c fiber.Ctx) error {
+		var cmd Command
+		if err := c.Bind().Body(&cmd); err != nil {
+			return errs.BadRequest("invalid request body")
+		}
+		resp, err := mediator.Send[*Command, *Response](c.Context(), &cmd)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusCreated, resp)
+	})
+}
@@ -0,0 +1,33 @@
+package list
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// List approval chains
+// @Summary List approval chains
+// @Description รายการลำดับการอนุมัติของบริษัท (รวมของสาขาที่เลือก) พร้อมขั้นตอน
+// @Tags Approvals
+// @Produce json
+// @Security BearerAuth
+// @Param docType query string false "payroll_run|bonus_cycle|salary_raise_cycle|debt_txn|leave_request"
+// @Success 200 {object} Response
+// @Failure 401
+// @Failure 403
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /approval-chains [get]
+func NewEndpoint(router fiber.Router) {
+	router.Get("/", func(c fiber.Ctx) error {
+		resp, err := mediator.Send[*Query, *Response](c.Context(), &Query{
+			DocType: c.Query("docType"),
+		})
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
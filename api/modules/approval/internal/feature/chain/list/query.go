@@ -0,0 +1,51 @@
+package list
+
+import (
+	"context"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"hrms/modules/approval/internal/dto"
+	"hrms/modules/approval/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+)
+
+type Query struct {
+	DocType string
+}
+
+type Response struct {
+	Data []dto.Chain `json:"data"`
+}
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*Query, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) Handle(ctx context.Context, q *Query) (*Response, error) {
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+
+	chains, err := h.repo.ListChains(ctx, tenant, strings.TrimSpace(q.DocType))
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list approval chains", zap.Error(err))
+		return nil, errs.Internal("failed to list approval chains")
+	}
+	data := make([]dto.Chain, 0, len(chains))
+	for _, c := range chains {
+		data = append(data, dto.FromChain(c))
+	}
+	return &Response{Data: data}, nil
+}
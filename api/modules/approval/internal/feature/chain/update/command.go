@@ -0,0 +1,114 @@
+package update
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/approval/internal/dto"
+	"hrms/modules/approval/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/storage/sqldb/transactor"
+	"hrms/shared/common/validator"
+	"hrms/shared/events"
+)
+
+// Command replaces a chain's name, scope, activation and steps. The document type is fixed.
+type Command struct {
+	ID       uuid.UUID       `json:"-" validate:"required"`
+	Name     string          `json:"name" validate:"required,max=200"`
+	BranchID *uuid.UUID      `json:"branchId"`
+	IsActive bool            `json:"isActive"`
+	Steps    []dto.StepInput `json:"steps" validate:"required,min=1,max=10,dive"`
+}
+
+type Response struct {
+	dto.Chain
+}
+
+type Handler struct {
+	repo repository.Repository
+	tx   transactor.Transactor
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, tx transactor.Transactor, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, tx: tx, eb: eb}
+}
+
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	cmd.Name = strings.TrimSpace(cmd.Name)
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+	if err := dto.ValidateSteps(cmd.Steps); err != nil {
+		return nil, err
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+	if tenant.HasBranchID() && cmd.BranchID != nil && *cmd.BranchID != tenant.BranchID {
+		return nil, errs.Forbidden("branchId must be the selected branch")
+	}
+
+	chain := repository.Chain{
+		ID:       cmd.ID,
+		BranchID: cmd.BranchID,
+		Name:     cmd.Name,
+		IsActive: cmd.IsActive,
+		Steps:    dto.ToSteps(cmd.Steps),
+	}
+
+	var updated *repository.Chain
+	err := h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
+		var err error
+		updated, err = h.repo.UpdateChain(ctxTx, tenant, chain, user.ID)
+		return err
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, errs.NotFound("approval chain not found")
+		case repository.IsUniqueViolation(err):
+			return nil, errs.Conflict("an active approval chain already exists for this document type")
+		case repository.IsForeignKeyViolation(err):
+			return nil, errs.BadRequest("branch or approver user not found")
+		}
+		logger.FromContext(ctx).Error("failed to update approval chain", zap.Error(err))
+		return nil, errs.Internal("failed to update approval chain")
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   updated.BranchID,
+		Action:     "UPDATE",
+		EntityName: "APPROVAL_CHAIN",
+		EntityID:   updated.ID.String(),
+		Details: map[string]interface{}{
+			"name":      updated.Name,
+			"is_active": updated.IsActive,
+			"steps":     len(updated.Steps),
+		},
+		Timestamp: time.Now(),
+	})
+
+	return &Response{Chain: dto.FromChain(*updated)}, nil
+}
@@ -0,0 +1,123 @@
+package decide
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"hrms/modules/approval/internal/dto"
+	"hrms/modules/approval/internal/repository"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/contracts"
+)
+
+const errSelfApproval = "the preparer cannot approve their own document"
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*contracts.DecideApprovalCommand, *contracts.DecideApprovalResponse] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+// Handle records a decision on the current step of the document's open request. Maker-checker
+// rules: the preparer and anyone in cmd.Preparers never decide (also when no chain applies), the
+// step must be assigned to the actor (by user, or by role when no user is named) and one person
+// decides at most one step of a request.
+func (h *Handler) Handle(ctx context.Context, cmd *contracts.DecideApprovalCommand) (*contracts.DecideApprovalResponse, error) {
+	if slices.Contains(cmd.Preparers, cmd.ActorID) {
+		return nil, errs.Forbidden(errSelfApproval)
+	}
+	req, err := h.repo.FindOpenRequest(ctx, cmd.CompanyID, cmd.DocType, cmd.DocID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// documents without a submit step open their request on the first decision
+		req, err = h.open(ctx, cmd)
+		if req == nil && err == nil {
+			return &contracts.DecideApprovalResponse{Required: false}, nil
+		}
+	}
+	if err != nil {
+		var appErr *errs.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		logger.FromContext(ctx).Error("failed to load approval request", zap.Error(err), zap.String("doc_id", cmd.DocID.String()))
+		return nil, errs.Internal("failed to load approval request")
+	}
+
+	comment := strings.TrimSpace(cmd.Comment)
+	if !cmd.Approve && comment == "" {
+		return nil, errs.BadRequest("comment is required when rejecting")
+	}
+
+	step := req.Step(req.CurrentStep)
+	if step == nil {
+		logger.FromContext(ctx).Error("approval request has no current step", zap.String("request_id", req.ID.String()))
+		return nil, errs.Internal("approval request is inconsistent")
+	}
+	if cmd.ActorID == req.PreparedBy {
+		return nil, errs.Forbidden(errSelfApproval)
+	}
+	if !assigned(*step, cmd) {
+		return nil, errs.Forbidden(fmt.Sprintf("step %d (%s) is not assigned to you", step.StepNo, step.Name))
+	}
+	for _, s := range req.Steps {
+		if s.ActedBy != nil && *s.ActedBy == cmd.ActorID {
+			return nil, errs.Forbidden("you already decided an earlier step of this document")
+		}
+	}
+
+	var commentPtr *string
+	if comment != "" {
+		commentPtr = &comment
+	}
+	updated, err := h.repo.DecideStep(ctx, *req, cmd.ActorID, cmd.Approve, commentPtr)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to record approval decision", zap.Error(err), zap.String("request_id", req.ID.String()))
+		return nil, errs.Internal("failed to record approval decision")
+	}
+
+	out := dto.FromRequest(*updated)
+	return &contracts.DecideApprovalResponse{
+		Required: true,
+		Final:    updated.Status != "pending",
+		Request:  &out,
+	}, nil
+}
+
+// open starts a request for a document that is decided without being submitted first.
+// It returns nil, nil when no chain governs the document.
+func (h *Handler) open(ctx context.Context, cmd *contracts.DecideApprovalCommand) (*repository.Request, error) {
+	chain, err := h.repo.FindActiveChain(ctx, cmd.CompanyID, cmd.BranchID, cmd.DocType)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if cmd.RequireSubmitted {
+		return nil, errs.BadRequest("document must be submitted for approval first")
+	}
+	req, err := h.repo.CreateRequest(ctx, *chain, cmd.BranchID, cmd.DocID, cmd.PreparedBy, nil)
+	if err != nil && repository.IsUniqueViolation(err) {
+		return nil, errs.Conflict("document is being decided by someone else, try again")
+	}
+	return req, err
+}
+
+func assigned(step repository.RequestStep, cmd *contracts.DecideApprovalCommand) bool {
+	if step.ApproverUserID != nil {
+		return *step.ApproverUserID == cmd.ActorID
+	}
+	return step.ApproverRole != nil && *step.ApproverRole == cmd.ActorRole
+}
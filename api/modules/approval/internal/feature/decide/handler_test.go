@@ -0,0 +1,37 @@
+package decide
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/approval/internal/repository"
+	"hrms/shared/common/errs"
+	"hrms/shared/contracts"
+)
+
+// A preparer is refused before any chain is looked up, so the ban also holds for documents
+// that no chain governs (the repository is never reached).
+func TestPreparerCannotDecide(t *testing.T) {
+	maker, submitter := uuid.New(), uuid.New()
+	h := NewHandler(repository.Repository{})
+	for _, approve := range []bool{true, false} {
+		_, err := h.Handle(context.Background(), &contracts.DecideApprovalCommand{
+			CompanyID:  uuid.New(),
+			DocType:    contracts.ApprovalDocPayrollRun,
+			DocID:      uuid.New(),
+			PreparedBy: maker,
+			Preparers:  []uuid.UUID{maker, submitter},
+			ActorID:    submitter,
+			ActorRole:  "admin",
+			Approve:    approve,
+			Comment:    "checked",
+		})
+		var appErr *errs.AppError
+		if !errors.As(err, &appErr) || appErr.Code != errs.CodeForbidden {
+			t.Errorf("approve=%v: err = %v, want forbidden", approve, err)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package history
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// Approval history of a document
+// @Summary List approval requests of a document
+// @Description ประวัติการส่งอนุมัติของเอกสาร ล่าสุดก่อน พร้อมผลและความเห็นแต่ละขั้น
+// @Tags Approvals
+// @Produce json
+// @Security BearerAuth
+// @Param docType path string true "payroll_run|bonus_cycle|salary_raise_cycle|debt_txn|leave_request"
+// @Param docId path string true "document id"
+// @Success 200 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /approvals/{docType}/{docId} [get]
+func NewEndpoint(router fiber.Router) {
+	router.Get("/:docType/:docId", func(c fiber.Ctx) error {
+		docID, err := uuid.Parse(c.Params("docId"))
+		if err != nil {
+			return errs.BadRequest("invalid docId")
+		}
+		resp, err := mediator.Send[*Query, *Response](c.Context(), &Query{
+			DocType: c.Params("docType"),
+			DocID:   docID,
+		})
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
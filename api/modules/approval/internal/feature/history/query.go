@@ -0,0 +1,53 @@
+package history
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/approval/internal/dto"
+	"hrms/modules/approval/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/validator"
+	"hrms/shared/contracts"
+)
+
+type Query struct {
+	DocType string    `validate:"required,oneof=payroll_run bonus_cycle salary_raise_cycle debt_txn leave_request"`
+	DocID   uuid.UUID `validate:"required"`
+}
+
+type Response struct {
+	Data []contracts.ApprovalRequestDTO `json:"data"`
+}
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*Query, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) Handle(ctx context.Context, q *Query) (*Response, error) {
+	if err := validator.Validate(q); err != nil {
+		return nil, err
+	}
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+
+	reqs, err := h.repo.ListDocumentRequests(ctx, tenant, q.DocType, q.DocID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list approval history", zap.Error(err))
+		return nil, errs.Internal("failed to list approval history")
+	}
+	return &Response{Data: dto.FromRequests(reqs)}, nil
+}
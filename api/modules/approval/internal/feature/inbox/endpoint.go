@@ -0,0 +1,33 @@
+package inbox
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// Approval inbox
+// @Summary List documents waiting for my approval
+// @Description เอกสารที่ขั้นปัจจุบันรอผู้ใช้นี้อนุมัติ (ตาม role หรือระบุตัว) ไม่รวมเอกสารที่ตนเป็นผู้จัดทำหรือเคยอนุมัติขั้นก่อนหน้า
+// @Tags Approvals
+// @Produce json
+// @Security BearerAuth
+// @Param docType query string false "payroll_run|bonus_cycle|salary_raise_cycle|debt_txn|leave_request"
+// @Success 200 {object} Response
+// @Failure 401
+// @Failure 403
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /approvals/inbox [get]
+func NewEndpoint(router fiber.Router) {
+	router.Get("/inbox", func(c fiber.Ctx) error {
+		resp, err := mediator.Send[*Query, *Response](c.Context(), &Query{
+			DocType: c.Query("docType"),
+		})
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
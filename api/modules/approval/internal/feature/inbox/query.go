@@ -0,0 +1,52 @@
+package inbox
+
+import (
+	"context"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"hrms/modules/approval/internal/dto"
+	"hrms/modules/approval/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/contracts"
+)
+
+type Query struct {
+	DocType string
+}
+
+type Response struct {
+	Data []contracts.ApprovalRequestDTO `json:"data"`
+}
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*Query, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) Handle(ctx context.Context, q *Query) (*Response, error) {
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+
+	reqs, err := h.repo.ListInbox(ctx, tenant, user.ID, user.Role, strings.TrimSpace(q.DocType))
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list approval inbox", zap.Error(err))
+		return nil, errs.Internal("failed to list approval inbox")
+	}
+	return &Response{Data: dto.FromRequests(reqs)}, nil
+}
@@ -0,0 +1,61 @@
+package submit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/approval/internal/dto"
+	"hrms/modules/approval/internal/repository"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/contracts"
+)
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*contracts.SubmitApprovalCommand, *contracts.SubmitApprovalResponse] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+// Handle opens a request on the chain that governs the document. Callers run it inside the
+// transaction that moves their document to its submitted state.
+func (h *Handler) Handle(ctx context.Context, cmd *contracts.SubmitApprovalCommand) (*contracts.SubmitApprovalResponse, error) {
+	chain, err := h.repo.FindActiveChain(ctx, cmd.CompanyID, cmd.BranchID, cmd.DocType)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &contracts.SubmitApprovalResponse{Required: false}, nil
+		}
+		logger.FromContext(ctx).Error("failed to load approval chain", zap.Error(err), zap.String("doc_type", cmd.DocType))
+		return nil, errs.Internal("failed to load approval chain")
+	}
+
+	var comment *string
+	if c := strings.TrimSpace(cmd.Comment); c != "" {
+		comment = &c
+	}
+	preparedBy := cmd.PreparedBy
+	if preparedBy == uuid.Nil {
+		preparedBy = cmd.ActorID
+	}
+	req, err := h.repo.CreateRequest(ctx, *chain, cmd.BranchID, cmd.DocID, preparedBy, comment)
+	if err != nil {
+		if repository.IsUniqueViolation(err) {
+			return nil, errs.Conflict("document is already waiting for approval")
+		}
+		logger.FromContext(ctx).Error("failed to open approval request", zap.Error(err), zap.String("doc_id", cmd.DocID.String()))
+		return nil, errs.Internal("failed to submit for approval")
+	}
+
+	out := dto.FromRequest(*req)
+	return &contracts.SubmitApprovalResponse{Required: true, Request: &out}, nil
+}
@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/storage/sqldb/transactor"
+)
+
+type Repository struct {
+	dbCtx transactor.DBTXContext
+}
+
+func NewRepository(dbCtx transactor.DBTXContext) Repository {
+	return Repository{dbCtx: dbCtx}
+}
+
+type Chain struct {
+	ID        uuid.UUID   `db:"id"`
+	CompanyID uuid.UUID   `db:"company_id"`
+	BranchID  *uuid.UUID  `db:"branch_id"`
+	DocType   string      `db:"doc_type"`
+	Name      string      `db:"name"`
+	IsActive  bool        `db:"is_active"`
+	CreatedAt time.Time   `db:"created_at"`
+	CreatedBy uuid.UUID   `db:"created_by"`
+	UpdatedAt time.Time   `db:"updated_at"`
+	UpdatedBy uuid.UUID   `db:"updated_by"`
+	Steps     []ChainStep `db:"-"`
+}
+
+type ChainStep struct {
+	ChainID        uuid.UUID  `db:"chain_id"`
+	StepNo         int        `db:"step_no"`
+	Name           string     `db:"name"`
+	ApproverRole   *string    `db:"approver_role"`
+	ApproverUserID *uuid.UUID `db:"approver_user_id"`
+}
+
+const chainColumns = `id, company_id, branch_id, doc_type, name, is_active, created_at, created_by, updated_at, updated_by`
+
+// ListChains returns the company's chains with their steps. A branch tenant sees the
+// company-wide chains as well as its own.
+func (r Repository) ListChains(ctx context.Context, tenant contextx.TenantInfo, docType string) ([]Chain, error) {
+	db := r.dbCtx(ctx)
+	where := "company_id = $1 AND deleted_at IS NULL"
+	args := []interface{}{tenant.CompanyID}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where += fmt.Sprintf(" AND (branch_id IS NULL OR branch_id = $%d)", len(args))
+	}
+	if docType != "" {
+		args = append(args, docType)
+		where += fmt.Sprintf(" AND doc_type = $%d", len(args))
+	}
+	q := fmt.Sprintf(`SELECT %s FROM approval_chain WHERE %s ORDER BY doc_type, branch_id NULLS FIRST, created_at`, chainColumns, where)
+	var chains []Chain
+	if err := db.SelectContext(ctx, &chains, q, args...); err != nil {
+		return nil, err
+	}
+	if len(chains) == 0 {
+		return []Chain{}, nil
+	}
+
+	ids := make([]uuid.UUID, len(chains))
+	for i := range chains {
+		ids[i] = chains[i].ID
+	}
+	var steps []ChainStep
+	if err := db.SelectContext(ctx, &steps, `
+SELECT chain_id, step_no, name, approver_role, approver_user_id
+FROM approval_chain_step
+WHERE chain_id = ANY($1)
+ORDER BY chain_id, step_no`, pq.Array(ids)); err != nil {
+		return nil, err
+	}
+	byChain := make(map[uuid.UUID][]ChainStep, len(chains))
+	for _, s := range steps {
+		byChain[s.ChainID] = append(byChain[s.ChainID], s)
+	}
+	for i := range chains {
+		chains[i].Steps = byChain[chains[i].ID]
+	}
+	return chains, nil
+}
+
+func (r Repository) GetChain(ctx context.Context, tenant contextx.TenantInfo, id uuid.UUID) (*Chain, error) {
+	db := r.dbCtx(ctx)
+	where := "id = $1 AND company_id = $2 AND deleted_at IS NULL"
+	args := []interface{}{id, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where += " AND (branch_id IS NULL OR branch_id = $3)"
+	}
+	var chain Chain
+	if err := db.GetContext(ctx, &chain, fmt.Sprintf(`SELECT %s FROM approval_chain WHERE %s`, chainColumns, where), args...); err != nil {
+		return nil, err
+	}
+	steps, err := r.listChainSteps(ctx, chain.ID)
+	if err != nil {
+		return nil, err
+	}
+	chain.Steps = steps
+	return &chain, nil
+}
+
+// FindActiveChain picks the chain that governs a document: the branch's own chain first,
+// then the company-wide one. Returns sql.ErrNoRows when the document type is not governed.
+func (r Repository) FindActiveChain(ctx context.Context, companyID uuid.UUID, branchID *uuid.UUID, docType string) (*Chain, error) {
+	db := r.dbCtx(ctx)
+	q := fmt.Sprintf(`
+SELECT %s
+FROM approval_chain
+WHERE company_id = $1 AND doc_type = $2 AND is_active AND deleted_at IS NULL
+  AND (branch_id IS NULL OR branch_id = $3)
+ORDER BY branch_id NULLS LAST
+LIMIT 1`, chainColumns)
+	var chain Chain
+	if err := db.GetContext(ctx, &chain, q, companyID, docType, branchID); err != nil {
+		return nil, err
+	}
+	steps, err := r.listChainSteps(ctx, chain.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(steps) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	chain.Steps = steps
+	return &chain, nil
+}
+
+func (r Repository) listChainSteps(ctx context.Context, chainID uuid.UUID) ([]ChainStep, error) {
+	db := r.dbCtx(ctx)
+	var steps []ChainStep
+	if err := db.SelectContext(ctx, &steps, `
+SELECT chain_id, step_no, name, approver_role, approver_user_id
+FROM approval_chain_step
+WHERE chain_id = $1
+ORDER BY step_no`, chainID); err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
+
+func (r Repository) CreateChain(ctx context.Context, chain Chain, actor uuid.UUID) (*Chain, error) {
+	db := r.dbCtx(ctx)
+	q := fmt.Sprintf(`
+INSERT INTO approval_chain (company_id, branch_id, doc_type, name, is_active, created_by, updated_by)
+VALUES ($1, $2, $3, $4, $5, $6, $6)
+RETURNING %s`, chainColumns)
+	var created Chain
+	if err := db.GetContext(ctx, &created, q, chain.CompanyID, chain.BranchID, chain.DocType, chain.Name, chain.IsActive, actor); err != nil {
+		return nil, err
+	}
+	if err := r.insertSteps(ctx, created.ID, chain.Steps); err != nil {
+		return nil, err
+	}
+	return r.withSteps(ctx, created)
+}
+
+// UpdateChain replaces the chain's header and steps. Requests already in flight keep the
+// steps they were submitted with.
+func (r Repository) UpdateChain(ctx context.Context, tenant contextx.TenantInfo, chain Chain, actor uuid.UUID) (*Chain, error) {
+	db := r.dbCtx(ctx)
+	where := "id = $5 AND company_id = $6 AND deleted_at IS NULL"
+	args := []interface{}{chain.BranchID, chain.Name, chain.IsActive, actor, chain.ID, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where += " AND (branch_id IS NULL OR branch_id = $7)"
+	}
+	q := fmt.Sprintf(`
+UPDATE approval_chain
+SET branch_id = $1, name = $2, is_active = $3, updated_by = $4
+WHERE %s
+RETURNING %s`, where, chainColumns)
+	var updated Chain
+	if err := db.GetContext(ctx, &updated, q, args...); err != nil {
+		return nil, err
+	}
+	if _, err := db.ExecContext(ctx, `DELETE FROM approval_chain_step WHERE chain_id = $1`, updated.ID); err != nil {
+		return nil, err
+	}
+	if err := r.insertSteps(ctx, updated.ID, chain.Steps); err != nil {
+		return nil, err
+	}
+	return r.withSteps(ctx, updated)
+}
+
+func (r Repository) SoftDeleteChain(ctx context.Context, tenant contextx.TenantInfo, id, actor uuid.UUID) error {
+	db := r.dbCtx(ctx)
+	q := `UPDATE approval_chain SET deleted_at = now(), deleted_by = $1, is_active = FALSE, updated_by = $1
+WHERE id = $2 AND company_id = $3 AND deleted_at IS NULL`
+	args := []interface{}{actor, id, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		q += " AND (branch_id IS NULL OR branch_id = $4)"
+		args = append(args, tenant.BranchID)
+	}
+	res, err := db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r Repository) insertSteps(ctx context.Context, chainID uuid.UUID, steps []ChainStep) error {
+	db := r.dbCtx(ctx)
+	for i, s := range steps {
+		if _, err := db.ExecContext(ctx, `
+INSERT INTO approval_chain_step (chain_id, step_no, name, approver_role, approver_user_id)
+VALUES ($1, $2, $3, $4, $5)`, chainID, i+1, s.Name, s.ApproverRole, s.ApproverUserID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r Repository) withSteps(ctx context.Context, chain Chain) (*Chain, error) {
+	steps, err := r.listChainSteps(ctx, chain.ID)
+	if err != nil {
+		return nil, err
+	}
+	chain.Steps = steps
+	return &chain, nil
+}
+
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505"
+	}
+	return false
+}
+
+// IsForeignKeyViolation reports an approver user or branch that does not exist.
+func IsForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23503"
+	}
+	return false
+}
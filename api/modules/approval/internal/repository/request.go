@@ -0,0 +1,237 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"hrms/shared/common/contextx"
+)
+
+type Request struct {
+	ID            uuid.UUID     `db:"id"`
+	CompanyID     uuid.UUID     `db:"company_id"`
+	BranchID      *uuid.UUID    `db:"branch_id"`
+	ChainID       uuid.UUID     `db:"chain_id"`
+	DocType       string        `db:"doc_type"`
+	DocID         uuid.UUID     `db:"doc_id"`
+	Status        string        `db:"status"`
+	CurrentStep   int           `db:"current_step"`
+	StepCount     int           `db:"step_count"`
+	PreparedBy    uuid.UUID     `db:"prepared_by"`
+	SubmitComment *string       `db:"submit_comment"`
+	SubmittedAt   time.Time     `db:"submitted_at"`
+	ClosedAt      *time.Time    `db:"closed_at"`
+	ClosedBy      *uuid.UUID    `db:"closed_by"`
+	Steps         []RequestStep `db:"-"`
+}
+
+type RequestStep struct {
+	RequestID      uuid.UUID  `db:"request_id"`
+	StepNo         int        `db:"step_no"`
+	Name           string     `db:"name"`
+	ApproverRole   *string    `db:"approver_role"`
+	ApproverUserID *uuid.UUID `db:"approver_user_id"`
+	Status         string     `db:"status"`
+	ActedBy        *uuid.UUID `db:"acted_by"`
+	ActedAt        *time.Time `db:"acted_at"`
+	Comment        *string    `db:"comment"`
+}
+
+// Step returns the snapshot of step n, or nil when it is out of range.
+func (r Request) Step(n int) *RequestStep {
+	for i := range r.Steps {
+		if r.Steps[i].StepNo == n {
+			return &r.Steps[i]
+		}
+	}
+	return nil
+}
+
+const requestColumns = `id, company_id, branch_id, chain_id, doc_type, doc_id, status, current_step, step_count,
+       prepared_by, submit_comment, submitted_at, closed_at, closed_by`
+
+// FindOpenRequest locks and returns the document's pending request, or sql.ErrNoRows.
+func (r Repository) FindOpenRequest(ctx context.Context, companyID uuid.UUID, docType string, docID uuid.UUID) (*Request, error) {
+	db := r.dbCtx(ctx)
+	q := fmt.Sprintf(`
+SELECT %s
+FROM approval_request
+WHERE company_id = $1 AND doc_type = $2 AND doc_id = $3 AND status = 'pending'
+FOR UPDATE`, requestColumns)
+	var req Request
+	if err := db.GetContext(ctx, &req, q, companyID, docType, docID); err != nil {
+		return nil, err
+	}
+	return r.withRequestSteps(ctx, req)
+}
+
+// CreateRequest opens a request and snapshots the chain's steps onto it.
+func (r Repository) CreateRequest(ctx context.Context, chain Chain, branchID *uuid.UUID, docID, preparedBy uuid.UUID, comment *string) (*Request, error) {
+	db := r.dbCtx(ctx)
+	q := fmt.Sprintf(`
+INSERT INTO approval_request (company_id, branch_id, chain_id, doc_type, doc_id, step_count, prepared_by, submit_comment)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING %s`, requestColumns)
+	var req Request
+	if err := db.GetContext(ctx, &req, q, chain.CompanyID, branchID, chain.ID, chain.DocType, docID,
+		len(chain.Steps), preparedBy, comment); err != nil {
+		return nil, err
+	}
+	for _, s := range chain.Steps {
+		if _, err := db.ExecContext(ctx, `
+INSERT INTO approval_request_step (request_id, step_no, name, approver_role, approver_user_id)
+VALUES ($1, $2, $3, $4, $5)`, req.ID, s.StepNo, s.Name, s.ApproverRole, s.ApproverUserID); err != nil {
+			return nil, err
+		}
+	}
+	return r.withRequestSteps(ctx, req)
+}
+
+// DecideStep records the actor's decision on the current step. An approval of the last step
+// closes the request as approved; a rejection closes it as rejected and skips the rest.
+func (r Repository) DecideStep(ctx context.Context, req Request, actor uuid.UUID, approve bool, comment *string) (*Request, error) {
+	db := r.dbCtx(ctx)
+	stepStatus := "rejected"
+	if approve {
+		stepStatus = "approved"
+	}
+	if _, err := db.ExecContext(ctx, `
+UPDATE approval_request_step
+SET status = $1, acted_by = $2, acted_at = now(), comment = $3
+WHERE request_id = $4 AND step_no = $5 AND status = 'waiting'`,
+		stepStatus, actor, comment, req.ID, req.CurrentStep); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case approve && req.CurrentStep < req.StepCount:
+		_, err := db.ExecContext(ctx, `UPDATE approval_request SET current_step = current_step + 1 WHERE id = $1`, req.ID)
+		if err != nil {
+			return nil, err
+		}
+	case approve:
+		if err := r.closeRequest(ctx, req.ID, "approved", actor); err != nil {
+			return nil, err
+		}
+	default:
+		if err := r.closeRequest(ctx, req.ID, "rejected", actor); err != nil {
+			return nil, err
+		}
+	}
+	return r.GetRequest(ctx, req.ID)
+}
+
+// CancelRequest withdraws an open request.
+func (r Repository) CancelRequest(ctx context.Context, id, actor uuid.UUID) error {
+	return r.closeRequest(ctx, id, "cancelled", actor)
+}
+
+func (r Repository) closeRequest(ctx context.Context, id uuid.UUID, status string, actor uuid.UUID) error {
+	db := r.dbCtx(ctx)
+	if _, err := db.ExecContext(ctx, `
+UPDATE approval_request SET status = $1, closed_at = now(), closed_by = $2
+WHERE id = $3 AND status = 'pending'`, status, actor, id); err != nil {
+		return err
+	}
+	_, err := db.ExecContext(ctx, `UPDATE approval_request_step SET status = 'skipped' WHERE request_id = $1 AND status = 'waiting'`, id)
+	return err
+}
+
+func (r Repository) GetRequest(ctx context.Context, id uuid.UUID) (*Request, error) {
+	db := r.dbCtx(ctx)
+	var req Request
+	if err := db.GetContext(ctx, &req, fmt.Sprintf(`SELECT %s FROM approval_request WHERE id = $1`, requestColumns), id); err != nil {
+		return nil, err
+	}
+	return r.withRequestSteps(ctx, req)
+}
+
+// ListDocumentRequests returns every submission of a document, latest first.
+func (r Repository) ListDocumentRequests(ctx context.Context, tenant contextx.TenantInfo, docType string, docID uuid.UUID) ([]Request, error) {
+	db := r.dbCtx(ctx)
+	q := fmt.Sprintf(`
+SELECT %s
+FROM approval_request
+WHERE company_id = $1 AND doc_type = $2 AND doc_id = $3
+ORDER BY submitted_at DESC`, requestColumns)
+	var reqs []Request
+	if err := db.SelectContext(ctx, &reqs, q, tenant.CompanyID, docType, docID); err != nil {
+		return nil, err
+	}
+	return r.attachSteps(ctx, reqs)
+}
+
+// ListInbox returns the open requests whose current step the user may decide: the step names
+// the user, or names the user's role and no particular user. The user's own submissions and
+// requests where the user already decided an earlier step are left out.
+func (r Repository) ListInbox(ctx context.Context, tenant contextx.TenantInfo, userID uuid.UUID, role, docType string) ([]Request, error) {
+	db := r.dbCtx(ctx)
+	where := []string{
+		"ar.company_id = $1",
+		"ar.status = 'pending'",
+		"ar.prepared_by <> $2",
+		"(s.approver_user_id = $2 OR (s.approver_user_id IS NULL AND s.approver_role = $3))",
+		"NOT EXISTS (SELECT 1 FROM approval_request_step d WHERE d.request_id = ar.id AND d.acted_by = $2)",
+	}
+	args := []interface{}{tenant.CompanyID, userID, role}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where = append(where, fmt.Sprintf("(ar.branch_id IS NULL OR ar.branch_id = $%d)", len(args)))
+	}
+	if docType != "" {
+		args = append(args, docType)
+		where = append(where, fmt.Sprintf("ar.doc_type = $%d", len(args)))
+	}
+	q := fmt.Sprintf(`
+SELECT ar.id, ar.company_id, ar.branch_id, ar.chain_id, ar.doc_type, ar.doc_id, ar.status, ar.current_step, ar.step_count,
+       ar.prepared_by, ar.submit_comment, ar.submitted_at, ar.closed_at, ar.closed_by
+FROM approval_request ar
+JOIN approval_request_step s ON s.request_id = ar.id AND s.step_no = ar.current_step
+WHERE %s
+ORDER BY ar.submitted_at ASC`, strings.Join(where, " AND "))
+	var reqs []Request
+	if err := db.SelectContext(ctx, &reqs, q, args...); err != nil {
+		return nil, err
+	}
+	return r.attachSteps(ctx, reqs)
+}
+
+func (r Repository) withRequestSteps(ctx context.Context, req Request) (*Request, error) {
+	reqs, err := r.attachSteps(ctx, []Request{req})
+	if err != nil {
+		return nil, err
+	}
+	return &reqs[0], nil
+}
+
+func (r Repository) attachSteps(ctx context.Context, reqs []Request) ([]Request, error) {
+	if len(reqs) == 0 {
+		return []Request{}, nil
+	}
+	db := r.dbCtx(ctx)
+	ids := make([]uuid.UUID, len(reqs))
+	for i := range reqs {
+		ids[i] = reqs[i].ID
+	}
+	var steps []RequestStep
+	if err := db.SelectContext(ctx, &steps, `
+SELECT request_id, step_no, name, approver_role, approver_user_id, status, acted_by, acted_at, comment
+FROM approval_request_step
+WHERE request_id = ANY($1)
+ORDER BY request_id, step_no`, pq.Array(ids)); err != nil {
+		return nil, err
+	}
+	byRequest := make(map[uuid.UUID][]RequestStep, len(reqs))
+	for _, s := range steps {
+		byRequest[s.RequestID] = append(byRequest[s.RequestID], s)
+	}
+	for i := range reqs {
+		reqs[i].Steps = byRequest[reqs[i].ID]
+	}
+	return reqs, nil
+}
@@ -0,0 +1,76 @@
+package approval
+
+import (
+	"hrms/modules/approval/internal/feature/cancel"
+	chaincreate "hrms/modules/approval/internal/feature/chain/create"
+	chaindelete "hrms/modules/approval/internal/feature/chain/delete"
+	chainget "hrms/modules/approval/internal/feature/chain/get"
+	chainlist "hrms/modules/approval/internal/feature/chain/list"
+	chainupdate "hrms/modules/approval/internal/feature/chain/update"
+	"hrms/modules/approval/internal/feature/decide"
+	"hrms/modules/approval/internal/feature/history"
+	"hrms/modules/approval/internal/feature/inbox"
+	"hrms/modules/approval/internal/feature/submit"
+	"hrms/modules/approval/internal/repository"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/jwt"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/middleware"
+	"hrms/shared/common/module"
+	"hrms/shared/contracts"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Module owns approval chains and the requests that move documents through them.
+// Document modules (payroll run, bonus, salary raise, debt, leave request) call it through contracts.
+type Module struct {
+	ctx      *module.ModuleContext
+	repo     repository.Repository
+	tokenSvc *jwt.TokenService
+	eb       eventbus.EventBus
+}
+
+func NewModule(ctx *module.ModuleContext, tokenSvc *jwt.TokenService) *Module {
+	return &Module{
+		ctx:      ctx,
+		repo:     repository.NewRepository(ctx.DBCtx),
+		tokenSvc: tokenSvc,
+	}
+}
+
+func (m *Module) APIVersion() string { return "v1" }
+
+func (m *Module) Init(eb eventbus.EventBus) error {
+	m.eb = eb
+	mediator.Register[*chainlist.Query, *chainlist.Response](chainlist.NewHandler(m.repo))
+	mediator.Register[*chainget.Query, *chainget.Response](chainget.NewHandler(m.repo))
+	mediator.Register[*chaincreate.Command, *chaincreate.Response](chaincreate.NewHandler(m.repo, m.ctx.Transactor, eb))
+	mediator.Register[*chainupdate.Command, *chainupdate.Response](chainupdate.NewHandler(m.repo, m.ctx.Transactor, eb))
+	mediator.Register[*chaindelete.Command, mediator.NoResponse](chaindelete.NewHandler(m.repo, eb))
+	mediator.Register[*inbox.Query, *inbox.Response](inbox.NewHandler(m.repo))
+	mediator.Register[*history.Query, *history.Response](history.NewHandler(m.repo))
+
+	// contract handlers used by document modules
+	mediator.Register[*contracts.SubmitApprovalCommand, *contracts.SubmitApprovalResponse](submit.NewHandler(m.repo))
+	mediator.Register[*contracts.DecideApprovalCommand, *contracts.DecideApprovalResponse](decide.NewHandler(m.repo))
+	mediator.Register[*contracts.CancelApprovalCommand, *contracts.CancelApprovalResponse](cancel.NewHandler(m.repo))
+	return nil
+}
+
+func (m *Module) RegisterRoutes(r fiber.Router) {
+	chains := r.Group("/approval-chains", middleware.Auth(m.tokenSvc), middleware.TenantMiddleware(), middleware.RequireRoles("admin", "hr"))
+	chainlist.NewEndpoint(chains)
+	chainget.NewEndpoint(chains)
+	// setting up who approves = admin only
+	chainAdmin := chains.Group("", middleware.RequireRoles("admin"))
+	chaincreate.NewEndpoint(chainAdmin)
+	chainupdate.NewEndpoint(chainAdmin)
+	chaindelete.NewEndpoint(chainAdmin)
+
+	approvals := r.Group("/approvals", middleware.Auth(m.tokenSvc), middleware.TenantMiddleware())
+	// inbox before history so "/inbox" is not taken as a document type; supervisors named on a
+	// leave request step see their inbox without HR access
+	inbox.NewEndpoint(approvals.Group("", middleware.RequireRoles("admin", "hr", "timekeeper")))
+	history.NewEndpoint(approvals.Group("", middleware.RequireRoles("admin", "hr")))
+}
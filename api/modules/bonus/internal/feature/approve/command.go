@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
@@ -19,17 +20,20 @@ import (
 	"hrms/shared/common/mediator"
 	"hrms/shared/common/storage/sqldb/transactor"
 	"hrms/shared/common/validator"
+	"hrms/shared/contracts"
 	"hrms/shared/events"
 )
 
 type Command struct {
-	ID     uuid.UUID `validate:"required"`
-	Status string    `validate:"required,oneof=approved rejected"`
+	ID      uuid.UUID `validate:"required"`
+	Status  string    `validate:"required,oneof=approved rejected"`
+	Comment string    `validate:"max=1000"`
 }
 
 type Response struct {
 	dto.Cycle
-	Message string `json:"message"`
+	Approval *contracts.ApprovalRequestDTO `json:"approval,omitempty"`
+	Message  string                        `json:"message"`
 }
 
 type Handler struct {
@@ -60,12 +64,54 @@ func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
 		return nil, errs.Unauthorized("missing user context")
 	}
 
-	if user.Role == "hr" {
-		return nil, errs.Forbidden("HR is not allowed to change status")
+	subject, err := h.repo.GetApprovalSubject(ctx, tenant, cmd.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("cycle not found or cannot change status")
+		}
+		logger.FromContext(ctx).Error("failed to load bonus cycle", zap.Error(err))
+		return nil, errs.Internal("BONUS_CYCLE_APPROVE_FAILED")
 	}
 
-	updated, err := h.repo.UpdateStatus(ctx, tenant, cmd.ID, cmd.Status, user.ID)
+	// A pending cycle is decided through its approval chain when one is configured; each call
+	// decides one step and the cycle status only changes once the request is closed.
+	var (
+		updated  *repository.Cycle
+		decision *contracts.DecideApprovalResponse
+	)
+	err = h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
+		if subject.Status == "pending" {
+			var err error
+			decision, err = mediator.Send[*contracts.DecideApprovalCommand, *contracts.DecideApprovalResponse](ctxTx, &contracts.DecideApprovalCommand{
+				CompanyID:  subject.CompanyID,
+				BranchID:   &subject.BranchID,
+				DocType:    contracts.ApprovalDocBonusCycle,
+				DocID:      cmd.ID,
+				PreparedBy: subject.CreatedBy,
+				ActorID:    user.ID,
+				ActorRole:  user.Role,
+				Approve:    cmd.Status == "approved",
+				Comment:    cmd.Comment,
+			})
+			if err != nil {
+				return err
+			}
+			if decision.Required && !decision.Final {
+				return nil
+			}
+		}
+		if (decision == nil || !decision.Required) && user.Role == "hr" {
+			return errs.Forbidden("HR is not allowed to change status")
+		}
+		var err error
+		updated, err = h.repo.UpdateStatus(ctxTx, tenant, cmd.ID, cmd.Status, user.ID)
+		return err
+	})
 	if err != nil {
+		var appErr *errs.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
 		if errors.Is(err, sql.ErrNoRows) {
 			logger.FromContext(ctx).Warn("bonus cycle not found or status cannot change", zap.Error(err), zap.String("status", cmd.Status))
 			return nil, errs.NotFound("cycle not found or cannot change status")
@@ -77,6 +123,9 @@ func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
 		logger.FromContext(ctx).Error("failed to update bonus cycle status", zap.Error(err), zap.String("status", cmd.Status))
 		return nil, errs.Internal("BONUS_CYCLE_APPROVE_FAILED")
 	}
+	if updated == nil {
+		return h.stepResponse(ctx, tenant, cmd, user.ID, decision.Request)
+	}
 	h.eb.Publish(events.LogEvent{
 		ActorID:    user.ID,
 		CompanyID:  &tenant.CompanyID,
@@ -90,8 +139,39 @@ func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
 		Timestamp: time.Now(),
 	})
 
-	return &Response{
+	resp := &Response{
 		Cycle:   dto.FromCycle(*updated),
 		Message: "Bonus cycle status updated.",
+	}
+	if decision != nil {
+		resp.Approval = decision.Request
+	}
+	return resp, nil
+}
+
+// stepResponse reports an approved step of a chain that still has steps left.
+func (h *Handler) stepResponse(ctx context.Context, tenant contextx.TenantInfo, cmd *Command, actor uuid.UUID, req *contracts.ApprovalRequestDTO) (*Response, error) {
+	cycle, _, err := h.repo.Get(ctx, tenant, cmd.ID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load bonus cycle", zap.Error(err))
+		return nil, errs.Internal("BONUS_CYCLE_APPROVE_FAILED")
+	}
+	h.eb.Publish(events.LogEvent{
+		ActorID:    actor,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "APPROVE_STEP",
+		EntityName: "BONUS_CYCLE",
+		EntityID:   cmd.ID.String(),
+		Details: map[string]interface{}{
+			"approval_request_id": req.ID.String(),
+			"step":                req.CurrentStep - 1,
+		},
+		Timestamp: time.Now(),
+	})
+	return &Response{
+		Cycle:    dto.FromCycle(*cycle),
+		Approval: req,
+		Message:  fmt.Sprintf("Step %d approved. Waiting for step %d of %d.", req.CurrentStep-1, req.CurrentStep, req.StepCount),
 	}, nil
 }
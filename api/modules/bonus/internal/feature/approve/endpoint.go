@@ -10,11 +10,12 @@ import (
 )
 
 type Request struct {
-	Status string `json:"status"`
+	Status  string `json:"status"`
+	Comment string `json:"comment"`
 }
 
 // @Summary Update status bonus cycle
-// @Description อนุมัติ/ปฏิเสธรอบโบนัส
+// @Description อนุมัติ/ปฏิเสธรอบโบนัส (ถ้าตั้งลำดับการอนุมัติไว้ จะตัดสินทีละขั้นตามลำดับ ผู้สร้างรอบอนุมัติเองไม่ได้)
 // @Tags Bonus
 // @Accept json
 // @Produce json
@@ -41,8 +42,9 @@ func NewEndpoint(router fiber.Router) {
 		}
 
 		resp, err := mediator.Send[*Command, *Response](c.Context(), &Command{
-			ID:     id,
-			Status: req.Status,
+			ID:      id,
+			Status:  req.Status,
+			Comment: req.Comment,
 		})
 		if err != nil {
 			return err
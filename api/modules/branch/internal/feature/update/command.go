@@ -15,11 +15,12 @@ import (
 )
 
 type Command struct {
-	ID      uuid.UUID `validate:"required"`
-	Code    string    `validate:"required"`
-	Name    string    `validate:"required"`
-	Status  string    `validate:"required"`
-	ActorID uuid.UUID `validate:"required"`
+	ID          uuid.UUID `validate:"required"`
+	Code        string    `validate:"required"`
+	Name        string    `validate:"required"`
+	Status      string    `validate:"required"`
+	SSOBranchNo *string   `validate:"omitempty,len=6,numeric"`
+	ActorID     uuid.UUID `validate:"required"`
 }
 
 type Response struct {
@@ -49,7 +50,7 @@ func (h *commandHandler) Handle(ctx context.Context, cmd *Command) (*Response, e
 		return nil, errs.BadRequest("cannot edit suspended or archived branch, use status change endpoint instead")
 	}
 
-	branch, err := h.repo.Update(ctx, cmd.ID, cmd.Code, cmd.Name, cmd.Status, cmd.ActorID)
+	branch, err := h.repo.Update(ctx, cmd.ID, cmd.Code, cmd.Name, cmd.Status, cmd.SSOBranchNo, cmd.ActorID)
 	if err != nil {
 		logger.FromContext(ctx).Error("failed to update branch", zap.Error(err))
 		return nil, errs.NotFound("branch not found")
@@ -64,10 +65,11 @@ func (h *commandHandler) Handle(ctx context.Context, cmd *Command) (*Response, e
 		EntityName: "BRANCH",
 		EntityID:   branch.ID.String(),
 		Details: map[string]interface{}{
-			"code":       branch.Code,
-			"name":       branch.Name,
-			"status":     branch.Status,
-			"is_default": branch.IsDefault,
+			"code":          branch.Code,
+			"name":          branch.Name,
+			"status":        branch.Status,
+			"is_default":    branch.IsDefault,
+			"sso_branch_no": branch.SSOBranchNo,
 		},
 		Timestamp: branch.UpdatedAt,
 	})
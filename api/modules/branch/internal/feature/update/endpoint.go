@@ -19,6 +19,8 @@ type UpdateRequest struct {
 	Code   string `json:"code" validate:"required,min=1,max=10"`
 	Name   string `json:"name" validate:"required,min=1,max=100"`
 	Status string `json:"status" validate:"required,oneof=active suspended"`
+	// SSOBranchNo เลขที่สาขาประกันสังคม 6 หลัก (000000 = สำนักงานใหญ่)
+	SSOBranchNo *string `json:"ssoBranchNo,omitempty" validate:"omitempty,len=6,numeric"`
 }
 
 // @Summary Update a branch
@@ -54,11 +56,12 @@ func NewEndpoint(router fiber.Router) {
 		}
 
 		resp, err := mediator.Send[*Command, *Response](c.Context(), &Command{
-			ID:      id,
-			Code:    req.Code,
-			Name:    req.Name,
-			Status:  req.Status,
-			ActorID: user.ID,
+			ID:          id,
+			Code:        req.Code,
+			Name:        req.Name,
+			Status:      req.Status,
+			SSOBranchNo: req.SSOBranchNo,
+			ActorID:     user.ID,
 		})
 		if err != nil {
 			return err
@@ -21,15 +21,17 @@ func NewRepository(dbCtx transactor.DBTXContext) Repository {
 
 // Branch represents a branch record
 type Branch struct {
-	ID        uuid.UUID  `db:"id" json:"id"`
-	CompanyID uuid.UUID  `db:"company_id" json:"companyId"`
-	Code      string     `db:"code" json:"code"`
-	Name      string     `db:"name" json:"name"`
-	Status    string     `db:"status" json:"status"`
-	IsDefault bool       `db:"is_default" json:"isDefault"`
-	CreatedAt time.Time  `db:"created_at" json:"createdAt"`
-	UpdatedAt time.Time  `db:"updated_at" json:"updatedAt"`
-	DeletedAt *time.Time `db:"deleted_at" json:"deletedAt,omitempty"`
+	ID        uuid.UUID `db:"id" json:"id"`
+	CompanyID uuid.UUID `db:"company_id" json:"companyId"`
+	Code      string    `db:"code" json:"code"`
+	Name      string    `db:"name" json:"name"`
+	Status    string    `db:"status" json:"status"`
+	IsDefault bool      `db:"is_default" json:"isDefault"`
+	// SSOBranchNo is the 6-digit Social Security workplace (สาขา) number; 000000 = head office.
+	SSOBranchNo string     `db:"sso_branch_no" json:"ssoBranchNo"`
+	CreatedAt   time.Time  `db:"created_at" json:"createdAt"`
+	UpdatedAt   time.Time  `db:"updated_at" json:"updatedAt"`
+	DeletedAt   *time.Time `db:"deleted_at" json:"deletedAt,omitempty"`
 }
 
 // List returns all branches for the current company
@@ -42,7 +44,7 @@ func (r Repository) List(ctx context.Context) ([]Branch, error) {
 	db := r.dbCtx(ctx)
 	var out []Branch
 	const q = `
-		SELECT id, company_id, code, name, status, is_default, created_at, updated_at, deleted_at
+		SELECT id, company_id, code, name, status, is_default, sso_branch_no, created_at, updated_at, deleted_at
 		FROM branches
 		WHERE company_id = $1 AND deleted_at IS NULL
 		ORDER BY is_default DESC, code ASC`
@@ -62,7 +64,7 @@ func (r Repository) GetByID(ctx context.Context, id uuid.UUID) (*Branch, error)
 	db := r.dbCtx(ctx)
 	var out Branch
 	const q = `
-		SELECT id, company_id, code, name, status, is_default, created_at, updated_at, deleted_at
+		SELECT id, company_id, code, name, status, is_default, sso_branch_no, created_at, updated_at, deleted_at
 		FROM branches
 		WHERE id = $1 AND company_id = $2 AND deleted_at IS NULL`
 	if err := db.GetContext(ctx, &out, q, id, tenant.CompanyID); err != nil {
@@ -82,7 +84,7 @@ func (r Repository) Create(ctx context.Context, code, name string, actor uuid.UU
 	const q = `
 		INSERT INTO branches (company_id, code, name, status, is_default, created_by, updated_by)
 		VALUES ($1, $2, $3, 'active', FALSE, $4, $4)
-		RETURNING id, company_id, code, name, status, is_default, created_at, updated_at`
+		RETURNING id, company_id, code, name, status, is_default, sso_branch_no, created_at, updated_at`
 	var out Branch
 	if err := db.GetContext(ctx, &out, q, tenant.CompanyID, code, name, actor); err != nil {
 		return nil, err
@@ -91,7 +93,8 @@ func (r Repository) Create(ctx context.Context, code, name string, actor uuid.UU
 }
 
 // Update updates a branch
-func (r Repository) Update(ctx context.Context, id uuid.UUID, code, name, status string, actor uuid.UUID) (*Branch, error) {
+// ssoBranchNo is left unchanged when nil.
+func (r Repository) Update(ctx context.Context, id uuid.UUID, code, name, status string, ssoBranchNo *string, actor uuid.UUID) (*Branch, error) {
 	tenant, ok := contextx.TenantFromContext(ctx)
 	if !ok {
 		return nil, sql.ErrNoRows
@@ -100,11 +103,12 @@ func (r Repository) Update(ctx context.Context, id uuid.UUID, code, name, status
 	db := r.dbCtx(ctx)
 	const q = `
 		UPDATE branches
-		SET code = $1, name = $2, status = $3, updated_by = $4, updated_at = now()
+		SET code = $1, name = $2, status = $3, updated_by = $4, updated_at = now(),
+		    sso_branch_no = COALESCE($7, sso_branch_no)
 		WHERE id = $5 AND company_id = $6 AND deleted_at IS NULL
-		RETURNING id, company_id, code, name, status, is_default, created_at, updated_at, deleted_at`
+		RETURNING id, company_id, code, name, status, is_default, sso_branch_no, created_at, updated_at, deleted_at`
 	var out Branch
-	if err := db.GetContext(ctx, &out, q, code, name, status, actor, id, tenant.CompanyID); err != nil {
+	if err := db.GetContext(ctx, &out, q, code, name, status, actor, id, tenant.CompanyID, ssoBranchNo); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -243,7 +247,7 @@ func (r Repository) GetUserBranchesForCompany(ctx context.Context, userID, compa
 		// Admin sees all branches
 		var out []Branch
 		const q = `
-			SELECT id, company_id, code, name, status, is_default, created_at, updated_at, deleted_at
+			SELECT id, company_id, code, name, status, is_default, sso_branch_no, created_at, updated_at, deleted_at
 			FROM branches
 			WHERE company_id = $1 AND status = 'active' AND deleted_at IS NULL
 			ORDER BY is_default DESC, code ASC`
@@ -256,7 +260,7 @@ func (r Repository) GetUserBranchesForCompany(ctx context.Context, userID, compa
 	// Non-admin sees only assigned branches
 	var out []Branch
 	const q = `
-		SELECT b.id, b.company_id, b.code, b.name, b.status, b.is_default, b.created_at, b.updated_at, b.deleted_at
+		SELECT b.id, b.company_id, b.code, b.name, b.status, b.is_default, b.sso_branch_no, b.created_at, b.updated_at, b.deleted_at
 		FROM branches b
 		JOIN user_branch_access uba ON uba.branch_id = b.id
 		WHERE uba.user_id = $1 AND b.company_id = $2 AND b.status = 'active' AND b.deleted_at IS NULL
@@ -299,7 +303,7 @@ func (r Repository) UpdateStatus(ctx context.Context, id uuid.UUID, status strin
 		UPDATE branches
 		SET status = $1, updated_by = $2, updated_at = now()
 		WHERE id = $3 AND company_id = $4 AND deleted_at IS NULL
-		RETURNING id, company_id, code, name, status, is_default, created_at, updated_at, deleted_at`
+		RETURNING id, company_id, code, name, status, is_default, sso_branch_no, created_at, updated_at, deleted_at`
 	var out Branch
 	if err := db.GetContext(ctx, &out, q, status, actor, id, tenant.CompanyID); err != nil {
 		return nil, err
@@ -13,7 +13,7 @@ import (
 
 // RegisterAttendanceSummary registers the attendance summary endpoint
 // @Summary Get attendance summary
-// @Description Get attendance statistics with optional grouping and filtering
+// @Description Get attendance statistics with optional grouping and filtering, including calendar holidays per period
 // @Tags Dashboard
 // @Produce json
 // @Param startDate query string true "Start date (YYYY-MM-DD)"
@@ -24,31 +24,49 @@ type AttendanceSummaryQuery struct {
 
 // AttendanceTotals contains totals for all entry types
 type AttendanceTotals struct {
-	LateCount        int     `json:"lateCount"`
-	LateMinutes      float64 `json:"lateMinutes"`
-	LeaveDayCount    int     `json:"leaveDayCount"`
-	LeaveDays        float64 `json:"leaveDays"`
-	LeaveHoursCount  int     `json:"leaveHoursCount"`
-	LeaveHours       float64 `json:"leaveHours"`
-	LeaveDoubleCount int     `json:"leaveDoubleCount"`
-	LeaveDoubleDays  float64 `json:"leaveDoubleDays"`
-	OtCount          int     `json:"otCount"`
-	OtHours          float64 `json:"otHours"`
+	LateCount         int     `json:"lateCount"`
+	LateMinutes       float64 `json:"lateMinutes"`
+	EarlyLeaveCount   int     `json:"earlyLeaveCount"`
+	EarlyLeaveMinutes float64 `json:"earlyLeaveMinutes"`
+	LeaveDayCount     int     `json:"leaveDayCount"`
+	LeaveDays         float64 `json:"leaveDays"`
+	LeaveHoursCount   int     `json:"leaveHoursCount"`
+	LeaveHours        float64 `json:"leaveHours"`
+	LeaveDoubleCount  int     `json:"leaveDoubleCount"`
+	LeaveDoubleDays   float64 `json:"leaveDoubleDays"`
+	LeavePaidCount    int     `json:"leavePaidCount"`
+	LeavePaidDays     float64 `json:"leavePaidDays"`
+	OtCount           int     `json:"otCount"`
+	OtHours           float64 `json:"otHours"`
+	HolidayWorkCount  int     `json:"holidayWorkCount"`
+	HolidayWorkHours  float64 `json:"holidayWorkHours"`
+	HolidayOtCount    int     `json:"holidayOtCount"`
+	HolidayOtHours    float64 `json:"holidayOtHours"`
+	HolidayCount      int     `json:"holidayCount"`
 }
 
 // AttendanceBreakdown contains breakdown by period
 type AttendanceBreakdown struct {
-	Period           string  `json:"period"`
-	LateCount        int     `json:"lateCount"`
-	LateMinutes      float64 `json:"lateMinutes"`
-	LeaveDayCount    int     `json:"leaveDayCount"`
-	LeaveDays        float64 `json:"leaveDays"`
-	LeaveHoursCount  int     `json:"leaveHoursCount"`
-	LeaveHours       float64 `json:"leaveHours"`
-	LeaveDoubleCount int     `json:"leaveDoubleCount"`
-	LeaveDoubleDays  float64 `json:"leaveDoubleDays"`
-	OtCount          int     `json:"otCount"`
-	OtHours          float64 `json:"otHours"`
+	Period            string  `json:"period"`
+	LateCount         int     `json:"lateCount"`
+	LateMinutes       float64 `json:"lateMinutes"`
+	EarlyLeaveCount   int     `json:"earlyLeaveCount"`
+	EarlyLeaveMinutes float64 `json:"earlyLeaveMinutes"`
+	LeaveDayCount     int     `json:"leaveDayCount"`
+	LeaveDays         float64 `json:"leaveDays"`
+	LeaveHoursCount   int     `json:"leaveHoursCount"`
+	LeaveHours        float64 `json:"leaveHours"`
+	LeaveDoubleCount  int     `json:"leaveDoubleCount"`
+	LeaveDoubleDays   float64 `json:"leaveDoubleDays"`
+	LeavePaidCount    int     `json:"leavePaidCount"`
+	LeavePaidDays     float64 `json:"leavePaidDays"`
+	OtCount           int     `json:"otCount"`
+	OtHours           float64 `json:"otHours"`
+	HolidayWorkCount  int     `json:"holidayWorkCount"`
+	HolidayWorkHours  float64 `json:"holidayWorkHours"`
+	HolidayOtCount    int     `json:"holidayOtCount"`
+	HolidayOtHours    float64 `json:"holidayOtHours"`
+	HolidayCount      int     `json:"holidayCount"`
 }
 
 // AttendanceSummaryResponse is the response for attendance summary
@@ -82,7 +100,26 @@ func (h *attendanceSummaryHandler) Handle(ctx context.Context, q *AttendanceSumm
 		return nil, errs.Internal("failed to get attendance summary")
 	}
 
-	// Aggregate by period
+	// Holidays from the company calendar, so periods without entries still show their days off
+	holidays, err := h.repo.GetHolidayCounts(ctx, tenant, q.StartDate, q.EndDate, q.GroupBy)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get holiday counts", zap.Error(err))
+		return nil, errs.Internal("failed to get attendance summary")
+	}
+
+	totals, breakdown := summarize(entries, holidays)
+	resp := &AttendanceSummaryResponse{
+		Totals:    totals,
+		Breakdown: breakdown,
+	}
+	resp.Period.StartDate = q.StartDate.Format("2006-01-02")
+	resp.Period.EndDate = q.EndDate.Format("2006-01-02")
+
+	return resp, nil
+}
+
+// summarize aggregates the entry totals and holiday counts by period, in period order.
+func summarize(entries []repository.AttendanceEntry, holidays []repository.HolidayCount) (AttendanceTotals, []AttendanceBreakdown) {
 	periodMap := make(map[string]*AttendanceBreakdown)
 	totals := AttendanceTotals{}
 
@@ -98,6 +135,11 @@ func (h *attendanceSummaryHandler) Handle(ctx context.Context, q *AttendanceSumm
 			bd.LateMinutes = entry.TotalQty
 			totals.LateCount += entry.TotalCount
 			totals.LateMinutes += entry.TotalQty
+		case "early_leave":
+			bd.EarlyLeaveCount = entry.TotalCount
+			bd.EarlyLeaveMinutes = entry.TotalQty
+			totals.EarlyLeaveCount += entry.TotalCount
+			totals.EarlyLeaveMinutes += entry.TotalQty
 		case "leave_day":
 			bd.LeaveDayCount = entry.TotalCount
 			bd.LeaveDays = entry.TotalQty
@@ -113,12 +155,35 @@ func (h *attendanceSummaryHandler) Handle(ctx context.Context, q *AttendanceSumm
 			bd.LeaveDoubleDays = entry.TotalQty
 			totals.LeaveDoubleCount += entry.TotalCount
 			totals.LeaveDoubleDays += entry.TotalQty
+		case "leave_paid":
+			bd.LeavePaidCount = entry.TotalCount
+			bd.LeavePaidDays = entry.TotalQty
+			totals.LeavePaidCount += entry.TotalCount
+			totals.LeavePaidDays += entry.TotalQty
 		case "ot":
 			bd.OtCount = entry.TotalCount
 			bd.OtHours = entry.TotalQty
 			totals.OtCount += entry.TotalCount
 			totals.OtHours += entry.TotalQty
+		case "holiday_work":
+			bd.HolidayWorkCount = entry.TotalCount
+			bd.HolidayWorkHours = entry.TotalQty
+			totals.HolidayWorkCount += entry.TotalCount
+			totals.HolidayWorkHours += entry.TotalQty
+		case "holiday_ot":
+			bd.HolidayOtCount = entry.TotalCount
+			bd.HolidayOtHours = entry.TotalQty
+			totals.HolidayOtCount += entry.TotalCount
+			totals.HolidayOtHours += entry.TotalQty
+		}
+	}
+
+	for _, hc := range holidays {
+		if _, ok := periodMap[hc.Period]; !ok {
+			periodMap[hc.Period] = &AttendanceBreakdown{Period: hc.Period}
 		}
+		periodMap[hc.Period].HolidayCount = hc.Count
+		totals.HolidayCount += hc.Count
 	}
 
 	// Convert map to sorted slice
@@ -135,12 +200,5 @@ func (h *attendanceSummaryHandler) Handle(ctx context.Context, q *AttendanceSumm
 		}
 	}
 
-	resp := &AttendanceSummaryResponse{
-		Totals:    totals,
-		Breakdown: breakdown,
-	}
-	resp.Period.StartDate = q.StartDate.Format("2006-01-02")
-	resp.Period.EndDate = q.EndDate.Format("2006-01-02")
-
-	return resp, nil
+	return totals, breakdown
 }
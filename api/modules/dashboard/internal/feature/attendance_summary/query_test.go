@@ -0,0 +1,43 @@
+package attendance_summary
+
+import (
+	"testing"
+
+	"hrms/modules/dashboard/internal/repository"
+)
+
+func TestSummarizeCountsEarlyLeaveApartFromLate(t *testing.T) {
+	entries := []repository.AttendanceEntry{
+		{Period: "2026-03", EntryType: "late", TotalCount: 4, TotalQty: 55},
+		{Period: "2026-03", EntryType: "early_leave", TotalCount: 2, TotalQty: 40},
+		{Period: "2026-04", EntryType: "early_leave", TotalCount: 1, TotalQty: 15},
+		{Period: "2026-04", EntryType: "ot", TotalCount: 3, TotalQty: 6},
+	}
+	holidays := []repository.HolidayCount{{Period: "2026-04", Count: 3}, {Period: "2026-05", Count: 1}}
+
+	totals, breakdown := summarize(entries, holidays)
+
+	if totals.LateCount != 4 || totals.LateMinutes != 55 {
+		t.Errorf("late totals = %d / %g, want 4 / 55", totals.LateCount, totals.LateMinutes)
+	}
+	if totals.EarlyLeaveCount != 3 || totals.EarlyLeaveMinutes != 55 {
+		t.Errorf("early leave totals = %d / %g, want 3 / 55", totals.EarlyLeaveCount, totals.EarlyLeaveMinutes)
+	}
+	if totals.HolidayCount != 4 {
+		t.Errorf("holiday total = %d, want 4", totals.HolidayCount)
+	}
+
+	want := []AttendanceBreakdown{
+		{Period: "2026-03", LateCount: 4, LateMinutes: 55, EarlyLeaveCount: 2, EarlyLeaveMinutes: 40},
+		{Period: "2026-04", EarlyLeaveCount: 1, EarlyLeaveMinutes: 15, OtCount: 3, OtHours: 6, HolidayCount: 3},
+		{Period: "2026-05", HolidayCount: 1},
+	}
+	if len(breakdown) != len(want) {
+		t.Fatalf("breakdown has %d periods, want %d", len(breakdown), len(want))
+	}
+	for i := range want {
+		if breakdown[i] != want[i] {
+			t.Errorf("breakdown[%d] = %+v, want %+v", i, breakdown[i], want[i])
+		}
+	}
+}
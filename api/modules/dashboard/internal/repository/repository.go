@@ -184,6 +184,53 @@ ORDER BY period, entry_type
 	return results, nil
 }
 
+// HolidayCount represents the number of holidays in a period
+type HolidayCount struct {
+	Period string `db:"period"`
+	Count  int    `db:"count"`
+}
+
+// GetHolidayCounts counts calendar holidays grouped by period. Without a selected branch only
+// the company-wide holidays are counted.
+func (r *Repository) GetHolidayCounts(ctx context.Context, tenant contextx.TenantInfo, startDate, endDate time.Time, groupBy string) ([]HolidayCount, error) {
+	db := r.dbCtx(ctx)
+
+	periodFormat := "YYYY-MM"
+	if groupBy == "day" {
+		periodFormat = "YYYY-MM-DD"
+	}
+
+	args := []interface{}{startDate, endDate, tenant.CompanyID}
+	where := []string{
+		"h.deleted_at IS NULL",
+		"h.holiday_date >= $1",
+		"h.holiday_date <= $2",
+		"h.company_id = $3",
+	}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where = append(where, fmt.Sprintf("(h.branch_id IS NULL OR h.branch_id = $%d)", len(args)))
+	} else {
+		where = append(where, "h.branch_id IS NULL")
+	}
+
+	query := fmt.Sprintf(`
+SELECT 
+    TO_CHAR(h.holiday_date, '%s') AS period,
+    COUNT(DISTINCT h.holiday_date) AS count
+FROM company_holiday h
+WHERE %s
+GROUP BY TO_CHAR(h.holiday_date, '%s')
+ORDER BY period
+`, periodFormat, strings.Join(where, " AND "), periodFormat)
+
+	var results []HolidayCount
+	if err := db.SelectContext(ctx, &results, query, args...); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
 // PayrollRunSummary represents payroll run statistics
 type PayrollRunSummary struct {
 	ID               uuid.UUID  `db:"id"`
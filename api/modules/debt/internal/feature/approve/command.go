@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,17 +19,21 @@ import (
 	"hrms/shared/common/mediator"
 	"hrms/shared/common/storage/sqldb/transactor"
 	"hrms/shared/common/validator"
+	"hrms/shared/contracts"
 	"hrms/shared/events"
 )
 
 type Command struct {
-	ID    uuid.UUID `validate:"required"`
-	Actor uuid.UUID `validate:"required"`
+	ID      uuid.UUID `validate:"required"`
+	Actor   uuid.UUID `validate:"required"`
+	Role    string
+	Comment string `validate:"max=1000"`
 }
 
 type Response struct {
 	dto.Item
-	Message string `json:"message"`
+	Approval *contracts.ApprovalRequestDTO `json:"approval,omitempty"`
+	Message  string                        `json:"message"`
 }
 
 type Handler struct {
@@ -71,12 +76,40 @@ func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
 		return nil, errs.BadRequest("already approved")
 	}
 
-	var updated *repository.Record
+	var (
+		updated  *repository.Record
+		decision *contracts.DecideApprovalResponse
+	)
 	if err := h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
 		var err error
+		decision, err = mediator.Send[*contracts.DecideApprovalCommand, *contracts.DecideApprovalResponse](ctxTx, &contracts.DecideApprovalCommand{
+			CompanyID:  rec.CompanyID,
+			BranchID:   &rec.BranchID,
+			DocType:    contracts.ApprovalDocDebtTxn,
+			DocID:      rec.ID,
+			PreparedBy: rec.CreatedBy,
+			ActorID:    cmd.Actor,
+			ActorRole:  cmd.Role,
+			Approve:    true,
+			Comment:    cmd.Comment,
+		})
+		if err != nil {
+			return err
+		}
+		if !decision.Required && cmd.Role != "admin" {
+			return errs.Forbidden("only admin can approve debt transaction")
+		}
+		if decision.Required && !decision.Final {
+			updated = rec
+			return nil
+		}
 		updated, err = h.repo.Approve(ctxTx, tenant, cmd.ID, cmd.Actor)
 		return err
 	}); err != nil {
+		var appErr *errs.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
 		if errors.Is(err, sql.ErrNoRows) {
 			logger.FromContext(ctx).Warn("debt transaction not found for approval", zap.Error(err))
 			return nil, errs.BadRequest("cannot approve")
@@ -92,19 +125,32 @@ func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
 		Action:     "APPROVE",
 		EntityName: "DEBT_TRANSACTION",
 		EntityID:   cmd.ID.String(),
-		Details:    map[string]interface{}{},
+		Details:    approvalDetails(decision),
 		Timestamp:  time.Now(),
 	})
 
 	message := "Transaction approved."
-	if updated.TxnType == "loan" || updated.TxnType == "other" {
+	if updated.Status == "pending" {
+		message = fmt.Sprintf("Step %d approved. Waiting for step %d of %d.",
+			decision.Request.CurrentStep-1, decision.Request.CurrentStep, decision.Request.StepCount)
+	} else if updated.TxnType == "loan" || updated.TxnType == "other" {
 		message = "Loan approved. Installments are now active."
 	} else if updated.TxnType == "repayment" {
 		message = "Repayment approved."
 	}
 
 	return &Response{
-		Item:    dto.FromRecord(*updated),
-		Message: message,
+		Item:     dto.FromRecord(*updated),
+		Approval: decision.Request,
+		Message:  message,
 	}, nil
 }
+
+func approvalDetails(decision *contracts.DecideApprovalResponse) map[string]interface{} {
+	details := map[string]interface{}{}
+	if decision.Request != nil {
+		details["approval_request_id"] = decision.Request.ID.String()
+		details["final"] = decision.Final
+	}
+	return details
+}
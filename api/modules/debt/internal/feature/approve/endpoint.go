@@ -10,11 +10,17 @@ import (
 	"hrms/shared/common/response"
 )
 
+type Request struct {
+	Comment string `json:"comment"`
+}
+
 // @Summary Approve loan
-// @Description อนุมัติเงินกู้ (admin only)
+// @Description อนุมัติเงินกู้ (admin เท่านั้น ถ้าตั้งลำดับการอนุมัติไว้ ผู้อนุมัติแต่ละขั้นตามลำดับเป็นผู้อนุมัติ และผู้สร้างรายการอนุมัติเองไม่ได้)
 // @Tags Debt
+// @Accept json
 // @Security BearerAuth
 // @Param id path string true "transaction id"
+// @Param request body Request false "payload"
 // @Success 200 {object} Response
 // @Failure 400
 // @Failure 401
@@ -34,9 +40,18 @@ func NewEndpoint(router fiber.Router) {
 			return errs.Unauthorized("missing user")
 		}
 
+		var req Request
+		if len(c.Body()) > 0 {
+			if err := c.Bind().Body(&req); err != nil {
+				return errs.BadRequest("invalid request body")
+			}
+		}
+
 		resp, err := mediator.Send[*Command, *Response](c.Context(), &Command{
-			ID:    id,
-			Actor: user.ID,
+			ID:      id,
+			Actor:   user.ID,
+			Role:    user.Role,
+			Comment: req.Comment,
 		})
 		if err != nil {
 			return err
@@ -37,6 +37,7 @@ type Record struct {
 	Reason               *string    `db:"reason" json:"reason"`
 	PayrollMonth         *time.Time `db:"payroll_month_date" json:"payroll_month_date"`
 	Status               string     `db:"status" json:"status"`
+	PayrollRunID         *uuid.UUID `db:"payroll_run_id" json:"payroll_run_id"`
 	ParentID             *uuid.UUID `db:"parent_id" json:"parent_id"`
 	CreatedAt            time.Time  `db:"created_at" json:"created_at"`
 	CreatedBy            uuid.UUID  `db:"created_by" json:"created_by"`
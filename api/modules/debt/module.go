@@ -56,7 +56,6 @@ func (m *Module) RegisterRoutes(r fiber.Router) {
 	delete.NewEndpoint(group)
 	outstanding.NewEndpoint(group)
 
-	// admin only
-	admin := group.Group("", middleware.RequireRoles("admin"))
-	approve.NewEndpoint(admin)
+	// approve: admin only unless an approval chain assigns the step to HR (checked in the handler)
+	approve.NewEndpoint(group.Group("", middleware.RequireRoles("admin", "hr")))
 }
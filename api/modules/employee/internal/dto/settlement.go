@@ -0,0 +1,88 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/employee/internal/repository"
+)
+
+type SettlementProration struct {
+	Basis      string  `json:"basis"`
+	Days       float64 `json:"days"`
+	PeriodDays float64 `json:"periodDays"`
+}
+
+// Settlement is the final settlement document of an offboarded employee. The final month
+// salary is paid by the regular run of that month; the other amounts by the settlement itself.
+type Settlement struct {
+	ID               uuid.UUID            `json:"id"`
+	EmployeeID       uuid.UUID            `json:"employeeId"`
+	TerminationDate  string               `json:"terminationDate"`
+	Reason           string               `json:"reason"`
+	NoticeDate       *string              `json:"noticeDate,omitempty"`
+	TenureDays       int                  `json:"tenureDays"`
+	BasePayAmount    float64              `json:"basePayAmount"`
+	DailyRate        float64              `json:"dailyRate"`
+	FinalMonthSalary float64              `json:"finalMonthSalary"`
+	Proration        *SettlementProration `json:"proration,omitempty"`
+	UnusedLeaveDays  float64              `json:"unusedLeaveDays"`
+	LeavePayout      float64              `json:"leavePayout"`
+	SeveranceDays    int                  `json:"severanceDays"`
+	SeveranceAmount  float64              `json:"severanceAmount"`
+	SeveranceExempt  float64              `json:"severanceTaxExempt"`
+	NoticePayDays    int                  `json:"noticePayDays"`
+	NoticePayAmount  float64              `json:"noticePayAmount"`
+	DebtRecovery     float64              `json:"debtRecovery"`
+	AdvanceRecovery  float64              `json:"advanceRecovery"`
+	DebtRecoveryTxn  *uuid.UUID           `json:"debtRecoveryTxnId,omitempty"`
+	NetAmount        float64              `json:"netAmount"`
+	PayrollRunID     *uuid.UUID           `json:"payrollRunId,omitempty"`
+	Note             *string              `json:"note,omitempty"`
+	CreatedAt        time.Time            `json:"createdAt"`
+	CreatedBy        uuid.UUID            `json:"createdBy"`
+}
+
+func FromSettlementRecord(r repository.SettlementRecord) Settlement {
+	var noticeDate *string
+	if r.NoticeDate != nil {
+		val := r.NoticeDate.Format(dateLayout)
+		noticeDate = &val
+	}
+	var proration *SettlementProration
+	if r.ProrationBasis != nil && r.ProrationDays != nil && r.ProrationPeriodDays != nil {
+		proration = &SettlementProration{
+			Basis:      *r.ProrationBasis,
+			Days:       *r.ProrationDays,
+			PeriodDays: *r.ProrationPeriodDays,
+		}
+	}
+	return Settlement{
+		ID:               r.ID,
+		EmployeeID:       r.EmployeeID,
+		TerminationDate:  r.TerminationDate.Format(dateLayout),
+		Reason:           r.Reason,
+		NoticeDate:       noticeDate,
+		TenureDays:       r.TenureDays,
+		BasePayAmount:    r.BasePayAmount,
+		DailyRate:        r.DailyRate,
+		FinalMonthSalary: r.FinalMonthSalary,
+		Proration:        proration,
+		UnusedLeaveDays:  r.UnusedLeaveDays,
+		LeavePayout:      r.LeavePayout,
+		SeveranceDays:    r.SeveranceDays,
+		SeveranceAmount:  r.SeveranceAmount,
+		SeveranceExempt:  r.SeveranceTaxExempt,
+		NoticePayDays:    r.NoticePayDays,
+		NoticePayAmount:  r.NoticePayAmount,
+		DebtRecovery:     r.DebtRecovery,
+		AdvanceRecovery:  r.AdvanceRecovery,
+		DebtRecoveryTxn:  r.DebtRecoveryTxnID,
+		NetAmount:        r.NetAmount,
+		PayrollRunID:     r.PayrollRunID,
+		Note:             r.Note,
+		CreatedAt:        r.CreatedAt,
+		CreatedBy:        r.CreatedBy,
+	}
+}
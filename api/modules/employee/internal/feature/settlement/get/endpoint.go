@@ -0,0 +1,38 @@
+package get
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// Get employee settlement
+// @Summary Get employee final settlement
+// @Description ดูเอกสารยอดจ่ายสุดท้ายของพนักงานที่พ้นสภาพ
+// @Tags Employees
+// @Produce json
+// @Param id path string true "employee id"
+// @Security BearerAuth
+// @Success 200 {object} Response
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /employees/{id}/settlement [get]
+func NewEndpoint(router fiber.Router) {
+	router.Get("/:id/settlement", func(c fiber.Ctx) error {
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return errs.BadRequest("invalid id")
+		}
+		resp, err := mediator.Send[*Query, *Response](c.Context(), &Query{EmployeeID: id})
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
@@ -0,0 +1,52 @@
+package get
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/employee/internal/dto"
+	"hrms/modules/employee/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+)
+
+type Query struct {
+	EmployeeID uuid.UUID
+}
+
+type Response struct {
+	dto.Settlement
+}
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*Query, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) Handle(ctx context.Context, q *Query) (*Response, error) {
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+
+	rec, err := h.repo.GetSettlement(ctx, tenant, q.EmployeeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("settlement not found")
+		}
+		logger.FromContext(ctx).Error("failed to load settlement", zap.Error(err))
+		return nil, errs.Internal("failed to load settlement")
+	}
+	return &Response{Settlement: dto.FromSettlementRecord(*rec)}, nil
+}
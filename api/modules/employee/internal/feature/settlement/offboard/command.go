@@ -0,0 +1,242 @@
+package offboard
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"hrms/modules/employee/internal/dto"
+	"hrms/modules/employee/internal/repository"
+	"hrms/modules/employee/internal/settlement"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/storage/sqldb/transactor"
+	"hrms/shared/common/validator"
+	"hrms/shared/contracts"
+	"hrms/shared/events"
+)
+
+type RequestBody struct {
+	TerminationDate string     `json:"terminationDate" validate:"required"`
+	Reason          string     `json:"reason" validate:"required,oneof=resignation termination termination_for_cause contract_end retirement"`
+	NoticeDate      *string    `json:"noticeDate"`
+	UnusedLeaveDays float64    `json:"unusedLeaveDays" validate:"gte=0,lte=366"`
+	PayrollRunID    *uuid.UUID `json:"payrollRunId"`
+	Note            *string    `json:"note"`
+
+	ParsedTerminationDate time.Time  `json:"-"`
+	ParsedNoticeDate      *time.Time `json:"-"`
+}
+
+type Command struct {
+	EmployeeID uuid.UUID `validate:"required"`
+	Payload    RequestBody
+}
+
+type Response struct {
+	dto.Settlement
+	PayrollItemID *uuid.UUID `json:"payrollItemId,omitempty"`
+}
+
+type Handler struct {
+	repo repository.Repository
+	tx   transactor.Transactor
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, tx transactor.Transactor, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, tx: tx, eb: eb}
+}
+
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+
+	p := cmd.Payload
+	var created *repository.SettlementRecord
+	var itemID *uuid.UUID
+	err := h.tx.WithinTransaction(ctx, func(ctxTx context.Context, hook func(transactor.PostCommitHook)) error {
+		emp, err := h.repo.Get(ctxTx, tenant, cmd.EmployeeID)
+		if err != nil {
+			return err
+		}
+		if _, err := h.repo.GetSettlement(ctxTx, tenant, emp.ID); err == nil {
+			return errs.Conflict("employee already has a final settlement")
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if p.ParsedTerminationDate.Before(emp.EmploymentStartDate) {
+			return errs.BadRequest("terminationDate must not be before employmentStartDate")
+		}
+
+		in, err := h.repo.GetSettlementInputs(ctxTx, *emp, p.ParsedTerminationDate)
+		if err != nil {
+			return err
+		}
+		rec := buildSettlement(*emp, *in, p)
+
+		if err := h.repo.SetEmploymentEndDate(ctxTx, tenant, emp.ID, p.ParsedTerminationDate, user.ID); err != nil {
+			return err
+		}
+		// the recoveries are closed by the approval of the run deducting them
+		if p.PayrollRunID != nil {
+			if rec.DebtRecovery > 0 {
+				txnID, err := h.repo.CreateDebtRecoveryRepayment(ctxTx, *emp, p.ParsedTerminationDate, rec.DebtRecovery, user.ID)
+				if err != nil {
+					return err
+				}
+				rec.DebtRecoveryTxnID = &txnID
+			}
+			if rec.AdvanceRecovery > 0 {
+				if err := h.repo.LinkRecoveredAdvances(ctxTx, *emp, p.ParsedTerminationDate, *p.PayrollRunID, user.ID); err != nil {
+					return err
+				}
+			}
+		}
+		created, err = h.repo.CreateSettlement(ctxTx, rec, user.ID)
+		if err != nil {
+			return err
+		}
+
+		if p.PayrollRunID != nil {
+			resp, err := mediator.Send[*contracts.PostSettlementCommand, *contracts.PostSettlementResponse](
+				ctxTx,
+				settlementLines(*created, *p.PayrollRunID, user.ID),
+			)
+			if err != nil {
+				return err
+			}
+			itemID = &resp.ItemID
+		}
+
+		hook(func(ctx context.Context) error {
+			h.eb.Publish(events.LogEvent{
+				ActorID:    user.ID,
+				CompanyID:  &tenant.CompanyID,
+				BranchID:   tenant.BranchIDPtr(),
+				Action:     "OFFBOARD",
+				EntityName: "EMPLOYEE",
+				EntityID:   emp.ID.String(),
+				Details: map[string]interface{}{
+					"settlementId":     created.ID,
+					"terminationDate":  p.TerminationDate,
+					"reason":           created.Reason,
+					"tenureDays":       created.TenureDays,
+					"finalMonthSalary": created.FinalMonthSalary,
+					"leavePayout":      created.LeavePayout,
+					"severanceAmount":  created.SeveranceAmount,
+					"noticePayAmount":  created.NoticePayAmount,
+					"debtRecovery":     created.DebtRecovery,
+					"advanceRecovery":  created.AdvanceRecovery,
+					"netAmount":        created.NetAmount,
+					"payrollRunId":     created.PayrollRunID,
+				},
+				Timestamp: time.Now(),
+			})
+			return nil
+		})
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("employee not found")
+		}
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return nil, errs.Conflict("employee already has a final settlement")
+		}
+		var appErr *errs.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		logger.FromContext(ctx).Error("failed to offboard employee", zap.Error(err))
+		return nil, errs.Internal("failed to offboard employee")
+	}
+
+	return &Response{Settlement: dto.FromSettlementRecord(*created), PayrollItemID: itemID}, nil
+}
+
+// buildSettlement fills the settlement document. Installments and advances of the termination
+// month are left to that month's regular run, which also pays the prorated salary.
+func buildSettlement(emp repository.DetailRecord, in repository.SettlementInputs, p RequestBody) repository.SettlementRecord {
+	daily := settlement.DailyRate(in.TypeCode, in.BasePayAmount, in.WorkHoursPerDay)
+	calc := settlement.Input{
+		Reason:          p.Reason,
+		StartDate:       emp.EmploymentStartDate,
+		TerminationDate: p.ParsedTerminationDate,
+		DailyRate:       daily,
+		UnusedLeaveDays: p.UnusedLeaveDays,
+	}
+	if p.ParsedNoticeDate != nil {
+		calc.NoticeDate = *p.ParsedNoticeDate
+	}
+	res := settlement.Compute(calc)
+
+	rec := repository.SettlementRecord{
+		CompanyID:          emp.CompanyID,
+		BranchID:           emp.BranchID,
+		EmployeeID:         emp.ID,
+		TerminationDate:    p.ParsedTerminationDate,
+		Reason:             p.Reason,
+		NoticeDate:         p.ParsedNoticeDate,
+		TenureDays:         res.TenureDays,
+		BasePayAmount:      in.BasePayAmount,
+		DailyRate:          daily,
+		UnusedLeaveDays:    p.UnusedLeaveDays,
+		LeavePayout:        res.LeavePayout,
+		SeveranceDays:      res.SeveranceDays,
+		SeveranceAmount:    res.SeveranceAmount,
+		SeveranceTaxExempt: res.SeveranceTaxExempt,
+		NoticePayDays:      res.NoticePayDays,
+		NoticePayAmount:    res.NoticePayAmount,
+		DebtRecovery:       settlement.Round2(max(in.LoanOutstanding-in.InstallmentsDue, 0)),
+		AdvanceRecovery:    in.AdvancesAfterLastDay,
+		PayrollRunID:       p.PayrollRunID,
+		Note:               p.Note,
+	}
+	// part-time pay follows the hours worked; only a monthly salary is prorated
+	if in.TypeCode != "part_time" && in.ProrationPeriodDays > 0 {
+		rec.FinalMonthSalary = settlement.Round2(in.BasePayAmount * in.ProrationDays / in.ProrationPeriodDays)
+		rec.ProrationBasis = &in.ProrationBasis
+		rec.ProrationDays = &in.ProrationDays
+		rec.ProrationPeriodDays = &in.ProrationPeriodDays
+	}
+	rec.NetAmount = settlement.Round2(rec.LeavePayout + rec.SeveranceAmount + rec.NoticePayAmount -
+		rec.DebtRecovery - rec.AdvanceRecovery)
+	return rec
+}
+
+// settlementLines turns the settlement into the lines of its off-cycle payroll item.
+func settlementLines(s repository.SettlementRecord, runID, actor uuid.UUID) *contracts.PostSettlementCommand {
+	cmd := &contracts.PostSettlementCommand{RunID: runID, EmployeeID: s.EmployeeID, ActorID: actor}
+	addLine := func(lines *[]contracts.SettlementLine, l contracts.SettlementLine) {
+		if l.Value > 0 {
+			*lines = append(*lines, l)
+		}
+	}
+	addLine(&cmd.Incomes, contracts.SettlementLine{Name: "ค่าชดเชยตามกฎหมาย", Value: s.SeveranceAmount, TaxExempt: s.SeveranceTaxExempt})
+	addLine(&cmd.Incomes, contracts.SettlementLine{Name: "ค่าจ้างแทนการบอกกล่าวล่วงหน้า", Value: s.NoticePayAmount})
+	addLine(&cmd.Incomes, contracts.SettlementLine{Name: "ค่าจ้างวันหยุดพักผ่อนที่ยังไม่ได้ใช้", Value: s.LeavePayout})
+	addLine(&cmd.Deductions, contracts.SettlementLine{Name: "หักเงินเบิกล่วงหน้าคงค้าง", Value: s.AdvanceRecovery})
+	addLine(&cmd.LoanRepayments, contracts.SettlementLine{Name: repository.DebtRecoveryReason, Value: s.DebtRecovery, TxnID: s.DebtRecoveryTxnID})
+	return cmd
+}
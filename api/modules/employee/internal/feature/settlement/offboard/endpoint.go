@@ -0,0 +1,78 @@
+package offboard
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+const dateLayout = "2006-01-02"
+
+func (p *RequestBody) ParseDates() error {
+	end, err := time.Parse(dateLayout, p.TerminationDate)
+	if err != nil {
+		return errs.BadRequest("terminationDate must be YYYY-MM-DD")
+	}
+	p.ParsedTerminationDate = end
+
+	p.ParsedNoticeDate = nil
+	if p.NoticeDate != nil && *p.NoticeDate != "" {
+		notice, err := time.Parse(dateLayout, *p.NoticeDate)
+		if err != nil {
+			return errs.BadRequest("noticeDate must be YYYY-MM-DD")
+		}
+		if notice.After(end) {
+			return errs.BadRequest("noticeDate must not be after terminationDate")
+		}
+		p.ParsedNoticeDate = &notice
+	}
+	return nil
+}
+
+// Offboard employee
+// @Summary Offboard employee with final settlement
+// @Description บันทึกพนักงานพ้นสภาพ: กำหนดวันทำงานวันสุดท้าย (employment_end_date) และคำนวณยอดจ่ายสุดท้าย ได้แก่ เงินเดือนงวดสุดท้ายตามสัดส่วน (จ่ายในงวดปกติ) ค่าจ้างวันหยุดพักผ่อนที่ยังไม่ได้ใช้ ค่าชดเชยตามอายุงาน (ม.118) ค่าจ้างแทนการบอกกล่าวล่วงหน้า (ม.17/1) หนี้คงค้าง และเงินเบิกล่วงหน้าที่ต้องเรียกคืน ระบุ payrollRunId (งวด off_cycle ที่รออนุมัติ) เพื่อลงรายการจ่ายในงวดนั้น
+// @Tags Employees
+// @Accept json
+// @Produce json
+// @Param id path string true "employee id"
+// @Param request body RequestBody true "offboarding payload"
+// @Security BearerAuth
+// @Success 201 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /employees/{id}/offboard [post]
+func NewEndpoint(router fiber.Router) {
+	router.Post("/:id/offboard", func(c fiber.Ctx) error {
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return errs.BadRequest("invalid id")
+		}
+		var req RequestBody
+		if err := c.Bind().Body(&req); err != nil {
+			return errs.BadRequest("invalid request body")
+		}
+		if err := req.ParseDates(); err != nil {
+			return err
+		}
+
+		resp, err := mediator.Send[*Command, *Response](c.Context(), &Command{
+			EmployeeID: id,
+			Payload:    req,
+		})
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusCreated, resp)
+	})
+}
@@ -0,0 +1,67 @@
+package delete
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/employee/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	EmployeeID uuid.UUID
+	TaxYear    int
+}
+
+type Handler struct {
+	repo repository.Repository
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, mediator.NoResponse] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, eb: eb}
+}
+
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (mediator.NoResponse, error) {
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return mediator.NoResponse{}, errs.Unauthorized("missing tenant context")
+	}
+
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return mediator.NoResponse{}, errs.Unauthorized("missing user context")
+	}
+
+	deleted, err := h.repo.DeleteTaxAllowance(ctx, tenant, cmd.EmployeeID, cmd.TaxYear)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to delete tax allowance", zap.Error(err))
+		return mediator.NoResponse{}, errs.Internal("failed to delete tax allowance")
+	}
+	if !deleted {
+		return mediator.NoResponse{}, errs.NotFound("tax allowance not found")
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "DELETE",
+		EntityName: "EMPLOYEE_TAX_ALLOWANCE",
+		EntityID:   cmd.EmployeeID.String(),
+		Details:    map[string]interface{}{"tax_year": cmd.TaxYear},
+		Timestamp:  time.Now(),
+	})
+
+	return mediator.NoResponse{}, nil
+}
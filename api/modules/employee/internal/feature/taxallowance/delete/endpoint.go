@@ -0,0 +1,42 @@
+package delete
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+)
+
+// @Summary Delete tax allowance
+// @Description ลบค่าลดหย่อนภาษีของพนักงานสำหรับปีภาษี ภาษีหัก ณ ที่จ่ายกลับไปใช้เฉพาะค่าลดหย่อนส่วนตัวจากการตั้งค่า
+// @Tags Employees
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "employee id"
+// @Param year path int true "tax year (CE)"
+// @Success 204 "No Content"
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /employees/{id}/tax-allowances/{year} [delete]
+func NewEndpoint(router fiber.Router) {
+	router.Delete("/:id/tax-allowances/:year", func(c fiber.Ctx) error {
+		empID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return errs.BadRequest("invalid employee id")
+		}
+		year, err := strconv.Atoi(c.Params("year"))
+		if err != nil {
+			return errs.BadRequest("invalid tax year")
+		}
+		if _, err := mediator.Send[*Command, mediator.NoResponse](c.Context(), &Command{
+			EmployeeID: empID,
+			TaxYear:    year,
+		}); err != nil {
+			return err
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+}
@@ -0,0 +1,49 @@
+package list
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/employee/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+)
+
+type Query struct {
+	EmployeeID uuid.UUID
+}
+
+type Response struct {
+	Data []repository.TaxAllowanceRecord `json:"data"`
+}
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*Query, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) Handle(ctx context.Context, q *Query) (*Response, error) {
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+
+	data, err := h.repo.ListTaxAllowances(ctx, tenant, q.EmployeeID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list tax allowances", zap.Error(err))
+		return nil, errs.Internal("failed to list tax allowances")
+	}
+	if data == nil {
+		data = make([]repository.TaxAllowanceRecord, 0)
+	}
+	return &Response{Data: data}, nil
+}
@@ -0,0 +1,109 @@
+package upsert
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/employee/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/validator"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	EmployeeID            uuid.UUID `json:"-"`
+	TaxYear               int       `json:"-" validate:"min=2000,max=2600"`
+	Spouse                bool      `json:"spouse"`
+	ChildrenCount         int       `json:"childrenCount" validate:"min=0"`
+	ChildrenBorn2018Count int       `json:"childrenBorn2018Count" validate:"min=0"`
+	ParentsCount          int       `json:"parentsCount" validate:"min=0,max=4"`
+	LifeInsurance         float64   `json:"lifeInsurance" validate:"min=0"`
+	HealthInsurance       float64   `json:"healthInsurance" validate:"min=0"`
+	SSFAmount             float64   `json:"ssfAmount" validate:"min=0"`
+	RMFAmount             float64   `json:"rmfAmount" validate:"min=0"`
+	ProvidentFundAmount   float64   `json:"providentFundAmount" validate:"min=0"`
+	HomeLoanInterest      float64   `json:"homeLoanInterest" validate:"min=0"`
+}
+
+type Response struct {
+	repository.TaxAllowanceRecord
+}
+
+type Handler struct {
+	repo repository.Repository
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, eb: eb}
+}
+
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+	// the extra allowance applies from the second child born in or after 2018
+	if cmd.ChildrenBorn2018Count > 0 && cmd.ChildrenBorn2018Count > cmd.ChildrenCount-1 {
+		return nil, errs.BadRequest("childrenBorn2018Count must be less than childrenCount")
+	}
+
+	rec := repository.TaxAllowanceRecord{
+		EmployeeID:            cmd.EmployeeID,
+		TaxYear:               cmd.TaxYear,
+		Spouse:                cmd.Spouse,
+		ChildrenCount:         cmd.ChildrenCount,
+		ChildrenBorn2018Count: cmd.ChildrenBorn2018Count,
+		ParentsCount:          cmd.ParentsCount,
+		LifeInsurance:         cmd.LifeInsurance,
+		HealthInsurance:       cmd.HealthInsurance,
+		SSFAmount:             cmd.SSFAmount,
+		RMFAmount:             cmd.RMFAmount,
+		ProvidentFundAmount:   cmd.ProvidentFundAmount,
+		HomeLoanInterest:      cmd.HomeLoanInterest,
+	}
+	out, err := h.repo.UpsertTaxAllowance(ctx, tenant, rec, user.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("employee not found")
+		}
+		logger.FromContext(ctx).Error("failed to upsert tax allowance", zap.Error(err))
+		return nil, errs.Internal("failed to upsert tax allowance")
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "UPSERT",
+		EntityName: "EMPLOYEE_TAX_ALLOWANCE",
+		EntityID:   cmd.EmployeeID.String(),
+		Details: map[string]interface{}{
+			"tax_year":            out.TaxYear,
+			"estimated_deduction": out.EstimatedDeduction,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return &Response{TaxAllowanceRecord: *out}, nil
+}
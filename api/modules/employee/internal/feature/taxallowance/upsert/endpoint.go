@@ -0,0 +1,50 @@
+package upsert
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// @Summary Upsert tax allowance
+// @Description บันทึกค่าลดหย่อนภาษีของพนักงานสำหรับปีภาษี (ค.ศ.) ใช้ประมาณภาษีหัก ณ ที่จ่ายรายเดือน งวดเงินเดือนที่ยังไม่อนุมัติในปีนั้นจะคำนวณใหม่
+// @Tags Employees
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "employee id"
+// @Param year path int true "tax year (CE)"
+// @Param request body Command true "tax allowance payload"
+// @Success 200 {object} Response
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /employees/{id}/tax-allowances/{year} [put]
+func NewEndpoint(router fiber.Router) {
+	router.Put("/:id/tax-allowances/:year", func(c fiber.Ctx) error {
+		empID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return errs.BadRequest("invalid employee id")
+		}
+		year, err := strconv.Atoi(c.Params("year"))
+		if err != nil {
+			return errs.BadRequest("invalid tax year")
+		}
+		var req Command
+		if err := c.Bind().Body(&req); err != nil {
+			return errs.BadRequest("invalid request body")
+		}
+		req.EmployeeID = empID
+		req.TaxYear = year
+
+		resp, err := mediator.Send[*Command, *Response](c.Context(), &req)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
@@ -0,0 +1,215 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/shared/common/contextx"
+)
+
+// DebtRecoveryReason names the recovered debt on the repayment and on the payroll item
+const DebtRecoveryReason = "หักหนี้คงค้างเมื่อพ้นสภาพ"
+
+type SettlementRecord struct {
+	ID                  uuid.UUID  `db:"id"`
+	CompanyID           uuid.UUID  `db:"company_id"`
+	BranchID            uuid.UUID  `db:"branch_id"`
+	EmployeeID          uuid.UUID  `db:"employee_id"`
+	TerminationDate     time.Time  `db:"termination_date"`
+	Reason              string     `db:"reason"`
+	NoticeDate          *time.Time `db:"notice_date"`
+	TenureDays          int        `db:"tenure_days"`
+	BasePayAmount       float64    `db:"base_pay_amount"`
+	DailyRate           float64    `db:"daily_rate"`
+	FinalMonthSalary    float64    `db:"final_month_salary"`
+	ProrationBasis      *string    `db:"proration_basis"`
+	ProrationDays       *float64   `db:"proration_days"`
+	ProrationPeriodDays *float64   `db:"proration_period_days"`
+	UnusedLeaveDays     float64    `db:"unused_leave_days"`
+	LeavePayout         float64    `db:"leave_payout"`
+	SeveranceDays       int        `db:"severance_days"`
+	SeveranceAmount     float64    `db:"severance_amount"`
+	SeveranceTaxExempt  float64    `db:"severance_tax_exempt"`
+	NoticePayDays       int        `db:"notice_pay_days"`
+	NoticePayAmount     float64    `db:"notice_pay_amount"`
+	DebtRecovery        float64    `db:"debt_recovery"`
+	AdvanceRecovery     float64    `db:"advance_recovery"`
+	DebtRecoveryTxnID   *uuid.UUID `db:"debt_recovery_txn_id"`
+	NetAmount           float64    `db:"net_amount"`
+	PayrollRunID        *uuid.UUID `db:"payroll_run_id"`
+	Note                *string    `db:"note"`
+	CreatedAt           time.Time  `db:"created_at"`
+	CreatedBy           uuid.UUID  `db:"created_by"`
+	UpdatedAt           time.Time  `db:"updated_at"`
+	UpdatedBy           uuid.UUID  `db:"updated_by"`
+}
+
+const settlementColumns = `id, company_id, branch_id, employee_id, termination_date, reason, notice_date, tenure_days,
+  base_pay_amount, daily_rate, final_month_salary, proration_basis, proration_days, proration_period_days,
+  unused_leave_days, leave_payout, severance_days, severance_amount, severance_tax_exempt,
+  notice_pay_days, notice_pay_amount, debt_recovery, advance_recovery, debt_recovery_txn_id,
+  net_amount, payroll_run_id, note, created_at, created_by, updated_at, updated_by`
+
+// SettlementInputs are the figures a settlement is computed from as of the termination date.
+// The final month salary is prorated like recalculate_payroll_item_regular does it; the regular
+// run of the termination month pays it, together with that month's installments and advances,
+// so those are returned separately to be left out of the recovery.
+type SettlementInputs struct {
+	TypeCode             string  `db:"type_code"`
+	BasePayAmount        float64 `db:"base_pay_amount"`
+	WorkHoursPerDay      float64 `db:"work_hours_per_day"`
+	ProrationBasis       string  `db:"proration_basis"`
+	ProrationDays        float64 `db:"proration_days"`
+	ProrationPeriodDays  float64 `db:"proration_period_days"`
+	LoanOutstanding      float64 `db:"loan_outstanding"`
+	InstallmentsDue      float64 `db:"installments_due"`
+	AdvancesAfterLastDay float64 `db:"advances_after_last_day"`
+}
+
+func (r Repository) GetSettlementInputs(ctx context.Context, emp DetailRecord, terminationDate time.Time) (*SettlementInputs, error) {
+	db := r.dbCtx(ctx)
+	const q = `
+WITH period AS (
+  SELECT date_trunc('month', $3::date)::date AS month_start,
+         (date_trunc('month', $3::date) + interval '1 month' - interval '1 day')::date AS month_end
+), cfg AS (
+  SELECT pc.work_hours_per_day, pc.proration_basis
+  FROM payroll_config pc, period p
+  WHERE pc.company_id = $2 AND pc.effective_daterange @> p.month_start
+  ORDER BY lower(pc.effective_daterange) DESC, pc.version_no DESC
+  LIMIT 1
+), basis AS (
+  SELECT COALESCE((SELECT proration_basis FROM cfg), 'thirty_day') AS proration_basis
+)
+SELECT
+  t.code AS type_code,
+  e.base_pay_amount,
+  COALESCE((SELECT work_hours_per_day FROM cfg), 8.0) AS work_hours_per_day,
+  b.proration_basis,
+  LEAST(
+    payroll_prorated_days(b.proration_basis, p.month_start, p.month_end,
+                          GREATEST(p.month_start, e.employment_start_date), $3::date),
+    CASE WHEN b.proration_basis = 'thirty_day' THEN 30
+         ELSE payroll_proration_days(b.proration_basis, p.month_start, p.month_end) END
+  ) AS proration_days,
+  CASE WHEN b.proration_basis = 'thirty_day' THEN 30
+       ELSE payroll_proration_days(b.proration_basis, p.month_start, p.month_end) END AS proration_period_days,
+  COALESCE((
+    SELECT pa.amount FROM payroll_accumulation pa
+    WHERE pa.employee_id = e.id AND pa.company_id = e.company_id AND pa.accum_type = 'loan_outstanding'
+  ), 0) AS loan_outstanding,
+  COALESCE((
+    SELECT SUM(dt.amount) FROM debt_txn dt
+    WHERE dt.employee_id = e.id AND dt.txn_type = 'installment' AND dt.status = 'pending'
+      AND dt.deleted_at IS NULL AND dt.payroll_month_date <= p.month_start
+  ), 0) AS installments_due,
+  COALESCE((
+    SELECT SUM(sa.amount) FROM salary_advance sa
+    WHERE sa.employee_id = e.id AND sa.status = 'pending'
+      AND sa.deleted_at IS NULL AND sa.payroll_month_date > p.month_start
+  ), 0) AS advances_after_last_day
+FROM employees e
+JOIN employee_type t ON t.id = e.employee_type_id
+CROSS JOIN period p
+CROSS JOIN basis b
+WHERE e.id = $1 AND e.company_id = $2 AND e.deleted_at IS NULL`
+	var in SettlementInputs
+	if err := db.GetContext(ctx, &in, q, emp.ID, emp.CompanyID, terminationDate); err != nil {
+		return nil, err
+	}
+	return &in, nil
+}
+
+// SetEmploymentEndDate ends the employment; tg_sync_payroll_emp reprices the pending runs.
+func (r Repository) SetEmploymentEndDate(ctx context.Context, tenant contextx.TenantInfo, id uuid.UUID, end time.Time, actor uuid.UUID) error {
+	db := r.dbCtx(ctx)
+	q := `UPDATE employees SET employment_end_date = $1, updated_by = $2
+WHERE id = $3 AND company_id = $4 AND deleted_at IS NULL`
+	args := []interface{}{end, actor, id, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		q += " AND branch_id = $5"
+		args = append(args, tenant.BranchID)
+	}
+	_, err := db.ExecContext(ctx, q, args...)
+	return err
+}
+
+// CreateDebtRecoveryRepayment records the debt recovered by the settlement as a pending repayment.
+// The off-cycle run references it by txn_id, so approving the run approves the repayment.
+func (r Repository) CreateDebtRecoveryRepayment(ctx context.Context, emp DetailRecord, txnDate time.Time, amount float64, actor uuid.UUID) (uuid.UUID, error) {
+	db := r.dbCtx(ctx)
+	const q = `
+INSERT INTO debt_txn (
+  employee_id, company_id, branch_id, txn_date, txn_type, amount, reason, status, created_by, updated_by
+) VALUES ($1, $2, $3, $4, 'repayment', $5, $6, 'pending', $7, $7)
+RETURNING id`
+	var id uuid.UUID
+	if err := db.GetContext(ctx, &id, q, emp.ID, emp.CompanyID, emp.BranchID, txnDate, amount, DebtRecoveryReason, actor); err != nil {
+		return uuid.Nil, err
+	}
+	return id, nil
+}
+
+// LinkRecoveredAdvances ties the pending advances of the months after the termination month
+// (the ones summed into advance_recovery) to the run deducting them; approving that run
+// processes them and no regular run picks them up again.
+func (r Repository) LinkRecoveredAdvances(ctx context.Context, emp DetailRecord, terminationDate time.Time, runID, actor uuid.UUID) error {
+	db := r.dbCtx(ctx)
+	const q = `
+UPDATE salary_advance
+SET payroll_run_id = $1, updated_by = $2
+WHERE employee_id = $3 AND company_id = $4 AND status = 'pending' AND deleted_at IS NULL
+  AND payroll_run_id IS NULL
+  AND payroll_month_date > date_trunc('month', $5::date)::date`
+	_, err := db.ExecContext(ctx, q, runID, actor, emp.ID, emp.CompanyID, terminationDate)
+	return err
+}
+
+func (r Repository) CreateSettlement(ctx context.Context, rec SettlementRecord, actor uuid.UUID) (*SettlementRecord, error) {
+	db := r.dbCtx(ctx)
+	q := `
+INSERT INTO employee_settlement (
+  company_id, branch_id, employee_id, termination_date, reason, notice_date, tenure_days,
+  base_pay_amount, daily_rate, final_month_salary, proration_basis, proration_days, proration_period_days,
+  unused_leave_days, leave_payout, severance_days, severance_amount, severance_tax_exempt,
+  notice_pay_days, notice_pay_amount, debt_recovery, advance_recovery, debt_recovery_txn_id,
+  net_amount, payroll_run_id, note, created_by, updated_by
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7,
+  $8, $9, $10, $11, $12, $13,
+  $14, $15, $16, $17, $18,
+  $19, $20, $21, $22, $23,
+  $24, $25, $26, $27, $27
+)
+RETURNING ` + settlementColumns
+	var out SettlementRecord
+	if err := db.GetContext(ctx, &out, q,
+		rec.CompanyID, rec.BranchID, rec.EmployeeID, rec.TerminationDate, rec.Reason, rec.NoticeDate, rec.TenureDays,
+		rec.BasePayAmount, rec.DailyRate, rec.FinalMonthSalary, rec.ProrationBasis, rec.ProrationDays, rec.ProrationPeriodDays,
+		rec.UnusedLeaveDays, rec.LeavePayout, rec.SeveranceDays, rec.SeveranceAmount, rec.SeveranceTaxExempt,
+		rec.NoticePayDays, rec.NoticePayAmount, rec.DebtRecovery, rec.AdvanceRecovery, rec.DebtRecoveryTxnID,
+		rec.NetAmount, rec.PayrollRunID, rec.Note, actor,
+	); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r Repository) GetSettlement(ctx context.Context, tenant contextx.TenantInfo, employeeID uuid.UUID) (*SettlementRecord, error) {
+	db := r.dbCtx(ctx)
+	q := `SELECT ` + settlementColumns + `
+FROM employee_settlement
+WHERE employee_id = $1 AND company_id = $2`
+	args := []interface{}{employeeID, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		q += " AND branch_id = $3"
+		args = append(args, tenant.BranchID)
+	}
+	var rec SettlementRecord
+	if err := db.GetContext(ctx, &rec, q, args...); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
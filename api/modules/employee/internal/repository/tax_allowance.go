@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/shared/common/contextx"
+)
+
+// TaxAllowanceRecord is the employee's tax allowance declaration for one tax year. Counts and
+// amounts are as declared; the statutory caps are applied by tax_allowance_deduction.
+type TaxAllowanceRecord struct {
+	ID                    uuid.UUID `db:"id" json:"id"`
+	EmployeeID            uuid.UUID `db:"employee_id" json:"employeeId"`
+	TaxYear               int       `db:"tax_year" json:"taxYear"`
+	Spouse                bool      `db:"spouse" json:"spouse"`
+	ChildrenCount         int       `db:"children_count" json:"childrenCount"`
+	ChildrenBorn2018Count int       `db:"children_born_2018_count" json:"childrenBorn2018Count"`
+	ParentsCount          int       `db:"parents_count" json:"parentsCount"`
+	LifeInsurance         float64   `db:"life_insurance" json:"lifeInsurance"`
+	HealthInsurance       float64   `db:"health_insurance" json:"healthInsurance"`
+	SSFAmount             float64   `db:"ssf_amount" json:"ssfAmount"`
+	RMFAmount             float64   `db:"rmf_amount" json:"rmfAmount"`
+	ProvidentFundAmount   float64   `db:"provident_fund_amount" json:"providentFundAmount"`
+	HomeLoanInterest      float64   `db:"home_loan_interest" json:"homeLoanInterest"`
+	// EstimatedDeduction is the capped total against twelve months of the current base pay
+	EstimatedDeduction float64   `db:"estimated_deduction" json:"estimatedDeduction"`
+	UpdatedAt          time.Time `db:"updated_at" json:"updatedAt"`
+	UpdatedBy          uuid.UUID `db:"updated_by" json:"updatedBy"`
+}
+
+const taxAllowanceColumns = `a.id, a.employee_id, a.tax_year, a.spouse, a.children_count, a.children_born_2018_count,
+  a.parents_count, a.life_insurance, a.health_insurance, a.ssf_amount, a.rmf_amount, a.provident_fund_amount,
+  a.home_loan_interest, a.updated_at, a.updated_by,
+  tax_allowance_deduction(a.employee_id, a.tax_year,
+    CASE WHEN et.code = 'full_time' THEN e.base_pay_amount * 12 ELSE 0 END) AS estimated_deduction`
+
+func (r Repository) ListTaxAllowances(ctx context.Context, tenant contextx.TenantInfo, employeeID uuid.UUID) ([]TaxAllowanceRecord, error) {
+	db := r.dbCtx(ctx)
+	q := `SELECT ` + taxAllowanceColumns + `
+FROM employee_tax_allowance a
+JOIN employees e ON e.id = a.employee_id
+JOIN employee_type et ON et.id = e.employee_type_id
+WHERE a.employee_id = $1 AND a.company_id = $2`
+	args := []interface{}{employeeID, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		q += " AND e.branch_id = $3"
+		args = append(args, tenant.BranchID)
+	}
+	q += " ORDER BY a.tax_year DESC"
+	var out []TaxAllowanceRecord
+	if err := db.SelectContext(ctx, &out, q, args...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// UpsertTaxAllowance saves the declaration for rec.TaxYear. It returns sql.ErrNoRows when the
+// employee is not in the tenant.
+func (r Repository) UpsertTaxAllowance(ctx context.Context, tenant contextx.TenantInfo, rec TaxAllowanceRecord, actor uuid.UUID) (*TaxAllowanceRecord, error) {
+	db := r.dbCtx(ctx)
+	where := "e.id = $1 AND e.company_id = $2 AND e.deleted_at IS NULL"
+	args := []interface{}{
+		rec.EmployeeID, tenant.CompanyID, rec.TaxYear, rec.Spouse, rec.ChildrenCount, rec.ChildrenBorn2018Count,
+		rec.ParentsCount, rec.LifeInsurance, rec.HealthInsurance, rec.SSFAmount, rec.RMFAmount,
+		rec.ProvidentFundAmount, rec.HomeLoanInterest, actor,
+	}
+	if tenant.HasBranchID() {
+		where += " AND e.branch_id = $15"
+		args = append(args, tenant.BranchID)
+	}
+	q := `
+WITH saved AS (
+  INSERT INTO employee_tax_allowance (
+    company_id, employee_id, tax_year, spouse, children_count, children_born_2018_count, parents_count,
+    life_insurance, health_insurance, ssf_amount, rmf_amount, provident_fund_amount, home_loan_interest,
+    created_by, updated_by
+  )
+  SELECT e.company_id, e.id, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $14
+  FROM employees e
+  WHERE ` + where + `
+  ON CONFLICT (employee_id, tax_year) DO UPDATE SET
+    spouse = EXCLUDED.spouse,
+    children_count = EXCLUDED.children_count,
+    children_born_2018_count = EXCLUDED.children_born_2018_count,
+    parents_count = EXCLUDED.parents_count,
+    life_insurance = EXCLUDED.life_insurance,
+    health_insurance = EXCLUDED.health_insurance,
+    ssf_amount = EXCLUDED.ssf_amount,
+    rmf_amount = EXCLUDED.rmf_amount,
+    provident_fund_amount = EXCLUDED.provident_fund_amount,
+    home_loan_interest = EXCLUDED.home_loan_interest,
+    updated_by = EXCLUDED.updated_by
+  RETURNING id
+)
+SELECT ` + taxAllowanceColumns + `
+FROM saved
+JOIN employee_tax_allowance a ON a.id = saved.id
+JOIN employees e ON e.id = a.employee_id
+JOIN employee_type et ON et.id = e.employee_type_id`
+	var out TaxAllowanceRecord
+	if err := db.GetContext(ctx, &out, q, args...); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r Repository) DeleteTaxAllowance(ctx context.Context, tenant contextx.TenantInfo, employeeID uuid.UUID, year int) (bool, error) {
+	db := r.dbCtx(ctx)
+	q := `DELETE FROM employee_tax_allowance a
+USING employees e
+WHERE e.id = a.employee_id AND a.employee_id = $1 AND a.tax_year = $2 AND a.company_id = $3`
+	args := []interface{}{employeeID, year, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		q += " AND e.branch_id = $4"
+		args = append(args, tenant.BranchID)
+	}
+	res, err := db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
@@ -0,0 +1,157 @@
+// Package settlement computes the statutory amounts owed to an employee who leaves, under the
+// Labour Protection Act B.E. 2541: severance by tenure (s.118), pay in lieu of notice (s.17/1)
+// and pay for unused annual leave (s.67), all at the last daily wage rate. It also gives the part
+// of the severance that is exempt from income tax under Revenue Code s.42(17).
+package settlement
+
+import (
+	"math"
+	"time"
+)
+
+// Reasons of the termination_reason domain
+const (
+	ReasonResignation         = "resignation"
+	ReasonTermination         = "termination"
+	ReasonTerminationForCause = "termination_for_cause"
+	ReasonContractEnd         = "contract_end"
+	ReasonRetirement          = "retirement"
+)
+
+const (
+	typePartTime = "part_time"
+	// s.17 para 2: notice never has to exceed three months
+	maxNoticePayDays = 90
+	// Revenue Code s.42(17): severance is exempt up to the wage of the last 300 days,
+	// and at most 600,000 baht
+	maxSeveranceExemptDays   = 300
+	maxSeveranceExemptAmount = 600000
+)
+
+// Input is what the settlement is computed from. A zero NoticeDate means notice was given on
+// the termination date itself.
+type Input struct {
+	Reason          string
+	StartDate       time.Time
+	TerminationDate time.Time
+	NoticeDate      time.Time
+	DailyRate       float64
+	UnusedLeaveDays float64
+}
+
+type Result struct {
+	TenureDays      int
+	LeavePayout     float64
+	SeveranceDays   int
+	SeveranceAmount float64
+	// SeveranceTaxExempt is the part of SeveranceAmount left out of the withholding tax base
+	SeveranceTaxExempt float64
+	NoticePayDays      int
+	NoticePayAmount    float64
+}
+
+// Compute returns the leave payout, severance and notice pay for the input.
+func Compute(in Input) Result {
+	notice := in.NoticeDate
+	if notice.IsZero() {
+		notice = in.TerminationDate
+	}
+	res := Result{
+		TenureDays:    TenureDays(in.StartDate, in.TerminationDate),
+		SeveranceDays: SeveranceDays(in.Reason, in.StartDate, in.TerminationDate),
+		NoticePayDays: NoticePayDays(in.Reason, notice, in.TerminationDate),
+	}
+	res.LeavePayout = Round2(in.DailyRate * in.UnusedLeaveDays)
+	res.SeveranceAmount = Round2(in.DailyRate * float64(res.SeveranceDays))
+	res.SeveranceTaxExempt = SeveranceTaxExempt(res.SeveranceAmount, in.DailyRate)
+	res.NoticePayAmount = Round2(in.DailyRate * float64(res.NoticePayDays))
+	return res
+}
+
+// DailyRate is the last daily wage: a thirtieth of the monthly salary, or the hourly rate of a
+// part-time employee times the working hours of a day.
+func DailyRate(typeCode string, basePay, workHoursPerDay float64) float64 {
+	if typeCode == typePartTime {
+		return Round2(basePay * workHoursPerDay)
+	}
+	return Round2(basePay / 30)
+}
+
+// TenureDays counts the days worked from start to the last day, both included.
+func TenureDays(start, last time.Time) int {
+	if last.Before(start) {
+		return 0
+	}
+	return int(dateOnly(last).Sub(dateOnly(start)).Hours()/24) + 1
+}
+
+// SeveranceDays is the s.118 entitlement in days of wage. Resignation and dismissal for cause
+// under s.119 carry none; the end of a fixed-term contract and retirement count as termination.
+func SeveranceDays(reason string, start, last time.Time) int {
+	switch reason {
+	case ReasonTermination, ReasonContractEnd, ReasonRetirement:
+	default:
+		return 0
+	}
+	if TenureDays(start, last) < 120 {
+		return 0
+	}
+	switch years := completedYears(start, last); {
+	case years < 1:
+		return 30
+	case years < 3:
+		return 90
+	case years < 6:
+		return 180
+	case years < 10:
+		return 240
+	case years < 20:
+		return 300
+	default:
+		return 400
+	}
+}
+
+// SeveranceTaxExempt is the tax-exempt part of a statutory severance: no more than the wage of
+// the last 300 days at the daily rate, capped at 600,000 baht.
+func SeveranceTaxExempt(severance, dailyRate float64) float64 {
+	if severance <= 0 {
+		return 0
+	}
+	return Round2(min(severance, dailyRate*maxSeveranceExemptDays, maxSeveranceExemptAmount))
+}
+
+// NoticePayDays is the pay in lieu of notice for a termination without cause. Notice given in a
+// month takes effect at the end of the following month (the pay date after the next one for a
+// monthly payroll); the employer owes the days between the last day and that date.
+func NoticePayDays(reason string, notice, last time.Time) int {
+	if reason != ReasonTermination {
+		return 0
+	}
+	n := dateOnly(notice)
+	effective := time.Date(n.Year(), n.Month()+2, 0, 0, 0, 0, 0, time.UTC)
+	days := int(effective.Sub(dateOnly(last)).Hours() / 24)
+	if days <= 0 {
+		return 0
+	}
+	return min(days, maxNoticePayDays)
+}
+
+// completedYears counts the full years of service up to and including the last day.
+func completedYears(start, last time.Time) int {
+	s, end := dateOnly(start), dateOnly(last).AddDate(0, 0, 1)
+	years := end.Year() - s.Year()
+	if s.AddDate(years, 0, 0).After(end) {
+		years--
+	}
+	return years
+}
+
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Round2 rounds to satang.
+func Round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,188 @@
+package settlement
+
+import (
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestSeveranceDays(t *testing.T) {
+	tests := []struct {
+		name   string
+		reason string
+		start  string
+		last   string
+		want   int
+	}{
+		{"119 days", ReasonTermination, "2025-01-01", "2025-04-29", 0},
+		{"120 days", ReasonTermination, "2025-01-01", "2025-04-30", 30},
+		{"one day short of 1 year", ReasonTermination, "2024-01-01", "2024-12-30", 30},
+		{"1 year", ReasonTermination, "2024-01-01", "2024-12-31", 90},
+		{"one day short of 3 years", ReasonTermination, "2020-01-01", "2022-12-30", 90},
+		{"3 years", ReasonTermination, "2020-01-01", "2022-12-31", 180},
+		{"one day short of 6 years", ReasonTermination, "2014-01-01", "2019-12-30", 180},
+		{"6 years", ReasonTermination, "2014-01-01", "2019-12-31", 240},
+		{"one day short of 10 years", ReasonTermination, "2010-01-01", "2019-12-30", 240},
+		{"10 years", ReasonTermination, "2010-01-01", "2019-12-31", 300},
+		{"one day short of 20 years", ReasonTermination, "2000-01-01", "2019-12-30", 300},
+		{"20 years", ReasonTermination, "2000-01-01", "2019-12-31", 400},
+		{"start on 29 Feb", ReasonTermination, "2020-02-29", "2021-02-28", 90},
+		{"contract end", ReasonContractEnd, "2020-01-01", "2022-12-31", 180},
+		{"retirement", ReasonRetirement, "2000-01-01", "2019-12-31", 400},
+		{"resignation", ReasonResignation, "2000-01-01", "2019-12-31", 0},
+		{"termination for cause", ReasonTerminationForCause, "2000-01-01", "2019-12-31", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SeveranceDays(tt.reason, date(tt.start), date(tt.last)); got != tt.want {
+				t.Errorf("SeveranceDays() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNoticePayDays(t *testing.T) {
+	tests := []struct {
+		name   string
+		reason string
+		notice string
+		last   string
+		want   int
+	}{
+		// notice in March takes effect on 30 April
+		{"last day at end of notice month", ReasonTermination, "2025-03-10", "2025-03-31", 30},
+		{"last day mid month", ReasonTermination, "2025-03-10", "2025-03-15", 46},
+		{"last day on effective date", ReasonTermination, "2025-03-10", "2025-04-30", 0},
+		{"last day after effective date", ReasonTermination, "2025-03-10", "2025-05-15", 0},
+		{"notice on the first of the month", ReasonTermination, "2025-07-01", "2025-07-01", 61},
+		{"february", ReasonTermination, "2024-01-31", "2024-01-31", 29},
+		{"resignation", ReasonResignation, "2025-03-10", "2025-03-15", 0},
+		{"contract end", ReasonContractEnd, "2025-03-10", "2025-03-15", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NoticePayDays(tt.reason, date(tt.notice), date(tt.last)); got != tt.want {
+				t.Errorf("NoticePayDays() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSeveranceTaxExempt(t *testing.T) {
+	tests := []struct {
+		name      string
+		severance float64
+		daily     float64
+		want      float64
+	}{
+		{"below 300 days", 90000, 1000, 90000},
+		{"capped at 300 days of wage", 400000, 1000, 300000},
+		{"capped at 600,000", 1200000, 3000, 600000},
+		{"no severance", 0, 1000, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SeveranceTaxExempt(tt.severance, tt.daily); got != tt.want {
+				t.Errorf("SeveranceTaxExempt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompute(t *testing.T) {
+	tests := []struct {
+		name string
+		in   Input
+		want Result
+	}{
+		{
+			name: "termination after 5 years with notice and leave",
+			in: Input{
+				Reason:          ReasonTermination,
+				StartDate:       date("2020-01-01"),
+				TerminationDate: date("2025-03-31"),
+				NoticeDate:      date("2025-03-10"),
+				DailyRate:       1000,
+				UnusedLeaveDays: 5.5,
+			},
+			want: Result{
+				TenureDays:         1917,
+				LeavePayout:        5500,
+				SeveranceDays:      180,
+				SeveranceAmount:    180000,
+				SeveranceTaxExempt: 180000,
+				NoticePayDays:      30,
+				NoticePayAmount:    30000,
+			},
+		},
+		{
+			name: "notice on the last day",
+			in: Input{
+				Reason:          ReasonTermination,
+				StartDate:       date("2025-01-01"),
+				TerminationDate: date("2025-04-30"),
+				DailyRate:       500,
+			},
+			want: Result{
+				TenureDays:         120,
+				SeveranceDays:      30,
+				SeveranceAmount:    15000,
+				SeveranceTaxExempt: 15000,
+				NoticePayDays:      31,
+				NoticePayAmount:    15500,
+			},
+		},
+		{
+			name: "20 years, exemption capped at 300 days",
+			in: Input{
+				Reason:          ReasonRetirement,
+				StartDate:       date("2005-06-01"),
+				TerminationDate: date("2025-05-31"),
+				DailyRate:       1666.67,
+			},
+			want: Result{
+				TenureDays:         7305,
+				SeveranceDays:      400,
+				SeveranceAmount:    666668,
+				SeveranceTaxExempt: 500001,
+			},
+		},
+		{
+			name: "resignation gets leave payout only",
+			in: Input{
+				Reason:          ReasonResignation,
+				StartDate:       date("2015-01-01"),
+				TerminationDate: date("2025-03-31"),
+				DailyRate:       800,
+				UnusedLeaveDays: 3,
+			},
+			want: Result{
+				TenureDays:  3743,
+				LeavePayout: 2400,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Compute(tt.in); got != tt.want {
+				t.Errorf("Compute() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDailyRate(t *testing.T) {
+	if got := DailyRate("full_time", 30000, 8); got != 1000 {
+		t.Errorf("full time DailyRate() = %v, want 1000", got)
+	}
+	if got := DailyRate("part_time", 60, 8); got != 480 {
+		t.Errorf("part time DailyRate() = %v, want 480", got)
+	}
+}
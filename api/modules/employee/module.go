@@ -23,6 +23,11 @@ import (
 	photodelete "hrms/modules/employee/internal/feature/photo/delete"
 	photodownload "hrms/modules/employee/internal/feature/photo/download"
 	photoupload "hrms/modules/employee/internal/feature/photo/upload"
+	settlementget "hrms/modules/employee/internal/feature/settlement/get"
+	settlementoffboard "hrms/modules/employee/internal/feature/settlement/offboard"
+	taxallowancedelete "hrms/modules/employee/internal/feature/taxallowance/delete"
+	taxallowancelist "hrms/modules/employee/internal/feature/taxallowance/list"
+	taxallowanceupsert "hrms/modules/employee/internal/feature/taxallowance/upsert"
 	"hrms/modules/employee/internal/feature/update"
 	"hrms/modules/employee/internal/repository"
 	"hrms/shared/common/eventbus"
@@ -65,6 +70,15 @@ func (m *Module) Init(eventBus eventbus.EventBus) error {
 	mediator.Register[*photodownload.Query, *photodownload.Response](photodownload.NewHandler(m.repo))
 	mediator.Register[*photodelete.Command, mediator.NoResponse](photodelete.NewHandler(m.repo, m.ctx.Transactor, eventBus))
 
+	// Offboarding / final settlement handlers
+	mediator.Register[*settlementoffboard.Command, *settlementoffboard.Response](settlementoffboard.NewHandler(m.repo, m.ctx.Transactor, eventBus))
+	mediator.Register[*settlementget.Query, *settlementget.Response](settlementget.NewHandler(m.repo))
+
+	// Tax allowance (per tax year) handlers
+	mediator.Register[*taxallowancelist.Query, *taxallowancelist.Response](taxallowancelist.NewHandler(m.repo))
+	mediator.Register[*taxallowanceupsert.Command, *taxallowanceupsert.Response](taxallowanceupsert.NewHandler(m.repo, eventBus))
+	mediator.Register[*taxallowancedelete.Command, mediator.NoResponse](taxallowancedelete.NewHandler(m.repo, eventBus))
+
 	// Document Type handlers (custom types - company admin)
 	mediator.Register[*doctypelist.Query, *doctypelist.Response](doctypelist.NewHandler(m.repo))
 	mediator.Register[*doctypecreate.Command, *doctypecreate.Response](doctypecreate.NewHandler(m.repo, eventBus))
@@ -102,6 +116,11 @@ func (m *Module) RegisterRoutes(r fiber.Router) {
 	adminOrHR := group.Group("", middleware.RequireRoles("admin", "hr"))
 	delete.NewEndpoint(adminOrHR)
 	acclist.NewEndpoint(adminOrHR)
+	settlementoffboard.NewEndpoint(adminOrHR)
+	settlementget.NewEndpoint(adminOrHR)
+	taxallowancelist.NewEndpoint(adminOrHR)
+	taxallowanceupsert.NewEndpoint(adminOrHR)
+	taxallowancedelete.NewEndpoint(adminOrHR)
 	photodownload.NewEndpoint(photos)
 	photoupload.NewEndpoint(photos.Group("", middleware.RequireRoles("admin", "hr")))
 	photodelete.NewEndpoint(group.Group("/:id/photo", middleware.RequireRoles("admin", "hr")))
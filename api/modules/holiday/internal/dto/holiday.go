@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/holiday/internal/repository"
+	"hrms/shared/contracts"
+)
+
+type Holiday struct {
+	ID        uuid.UUID  `json:"id"`
+	BranchID  *uuid.UUID `json:"branchId,omitempty"`
+	Date      string     `json:"date"`
+	Name      string     `json:"name"`
+	Kind      string     `json:"kind"`
+	SourceUID *string    `json:"sourceUid,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+func FromHoliday(h repository.Holiday) Holiday {
+	return Holiday{
+		ID:        h.ID,
+		BranchID:  h.BranchID,
+		Date:      h.HolidayDate.Format("2006-01-02"),
+		Name:      h.Name,
+		Kind:      h.Kind,
+		SourceUID: h.SourceUID,
+		CreatedAt: h.CreatedAt,
+		UpdatedAt: h.UpdatedAt,
+	}
+}
+
+func FromHolidays(hs []repository.Holiday) []Holiday {
+	out := make([]Holiday, 0, len(hs))
+	for _, h := range hs {
+		out = append(out, FromHoliday(h))
+	}
+	return out
+}
+
+func ToContract(h repository.Holiday) contracts.HolidayDTO {
+	return contracts.HolidayDTO{
+		Date:     h.HolidayDate,
+		Name:     h.Name,
+		Kind:     h.Kind,
+		BranchID: h.BranchID,
+	}
+}
@@ -0,0 +1,41 @@
+package calendar
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"hrms/modules/holiday/internal/dto"
+	"hrms/modules/holiday/internal/repository"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/contracts"
+)
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*contracts.ListHolidaysQuery, *contracts.ListHolidaysResponse] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+// Handle returns the days off a branch observes in the range, one entry per date.
+func (h *Handler) Handle(ctx context.Context, q *contracts.ListHolidaysQuery) (*contracts.ListHolidaysResponse, error) {
+	if q.To.Before(q.From) {
+		return &contracts.ListHolidaysResponse{Holidays: []contracts.HolidayDTO{}}, nil
+	}
+	rows, err := h.repo.ListObserved(ctx, q.CompanyID, q.BranchID, q.From, q.To)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load holidays", zap.Error(err))
+		return nil, errs.Internal("failed to load holidays")
+	}
+	out := make([]contracts.HolidayDTO, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, dto.ToContract(r))
+	}
+	return &contracts.ListHolidaysResponse{Holidays: out}, nil
+}
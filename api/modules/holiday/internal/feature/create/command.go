@@ -0,0 +1,109 @@
+package create
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/holiday/internal/dto"
+	"hrms/modules/holiday/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/validator"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	Date     string     `json:"date" validate:"required"`
+	Name     string     `json:"name" validate:"required,max=200"`
+	Kind     string     `json:"kind" validate:"omitempty,oneof=public company"`
+	BranchID *uuid.UUID `json:"branchId"`
+}
+
+type Response struct {
+	dto.Holiday
+}
+
+type Handler struct {
+	repo repository.Repository
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, eb: eb}
+}
+
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	cmd.Name = strings.TrimSpace(cmd.Name)
+	cmd.Kind = strings.TrimSpace(cmd.Kind)
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+	date, err := time.Parse("2006-01-02", strings.TrimSpace(cmd.Date))
+	if err != nil {
+		return nil, errs.BadRequest("date must be YYYY-MM-DD")
+	}
+	if cmd.Kind == "" {
+		cmd.Kind = "public"
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+	// a branch user can only add days off for the branch they work in
+	branchID := cmd.BranchID
+	if tenant.HasBranchID() {
+		if branchID != nil && *branchID != tenant.BranchID {
+			return nil, errs.Forbidden("branchId must be the selected branch")
+		}
+		branchID = tenant.BranchIDPtr()
+	}
+
+	created, err := h.repo.CreateHoliday(ctx, repository.Holiday{
+		CompanyID:   tenant.CompanyID,
+		BranchID:    branchID,
+		HolidayDate: date,
+		Name:        cmd.Name,
+		Kind:        cmd.Kind,
+	}, user.ID)
+	if err != nil {
+		if repository.IsUniqueViolation(err) {
+			return nil, errs.Conflict("a holiday already exists on this date")
+		}
+		if repository.IsForeignKeyViolation(err) {
+			return nil, errs.BadRequest("branch not found")
+		}
+		logger.FromContext(ctx).Error("failed to create holiday", zap.Error(err))
+		return nil, errs.Internal("failed to create holiday")
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   created.BranchID,
+		Action:     "CREATE",
+		EntityName: "COMPANY_HOLIDAY",
+		EntityID:   created.ID.String(),
+		Details: map[string]interface{}{
+			"date": created.HolidayDate.Format("2006-01-02"),
+			"name": created.Name,
+			"kind": created.Kind,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return &Response{Holiday: dto.FromHoliday(*created)}, nil
+}
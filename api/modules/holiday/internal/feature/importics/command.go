@@ -0,0 +1,175 @@
+package importics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/holiday/internal/dto"
+	"hrms/modules/holiday/internal/ical"
+	"hrms/modules/holiday/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/storage/sqldb/transactor"
+	"hrms/shared/common/validator"
+	"hrms/shared/events"
+)
+
+const maxFileSizeBytes = 2 * 1024 * 1024 // 2MB
+
+// Command imports the file's events; a non-zero Year keeps only the events in that year.
+type Command struct {
+	Data     []byte `validate:"required,min=1"`
+	Kind     string `validate:"omitempty,oneof=public company"`
+	Year     int    `validate:"omitempty,min=2000,max=2100"`
+	BranchID *uuid.UUID
+	FileName string
+}
+
+// ImportError reports a VEVENT that was not imported
+type ImportError struct {
+	Line    int    `json:"line"`
+	UID     string `json:"uid,omitempty"`
+	Message string `json:"message"`
+}
+
+type Response struct {
+	Created int           `json:"created"`
+	Updated int           `json:"updated"`
+	Skipped int           `json:"skipped"`
+	Errors  []ImportError `json:"errors"`
+	Data    []dto.Holiday `json:"data"`
+}
+
+type Handler struct {
+	repo repository.Repository
+	tx   transactor.Transactor
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, tx transactor.Transactor, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, tx: tx, eb: eb}
+}
+
+// Handle upserts one holiday per day covered by each VEVENT. A day that already has a holiday
+// for the same company/branch takes the imported name and kind. Events outside Year are skipped.
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	cmd.Kind = strings.TrimSpace(cmd.Kind)
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+	if cmd.Kind == "" {
+		cmd.Kind = "public"
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+	branchID := cmd.BranchID
+	if tenant.HasBranchID() {
+		if branchID != nil && *branchID != tenant.BranchID {
+			return nil, errs.Forbidden("branchId must be the selected branch")
+		}
+		branchID = tenant.BranchIDPtr()
+	}
+
+	parsed, err := ical.Parse(bytes.NewReader(cmd.Data))
+	if err != nil {
+		return nil, errs.BadRequest(fmt.Sprintf("invalid iCalendar file: %s", err.Error()))
+	}
+
+	resp := &Response{Errors: []ImportError{}, Data: []dto.Holiday{}}
+	var rows []repository.Holiday
+	for _, ev := range parsed {
+		if ev.Err != nil {
+			resp.Errors = append(resp.Errors, ImportError{Line: ev.Line, UID: ev.UID, Message: ev.Err.Error()})
+			continue
+		}
+		name := ev.Summary
+		if name == "" {
+			resp.Errors = append(resp.Errors, ImportError{Line: ev.Line, UID: ev.UID, Message: "missing SUMMARY"})
+			continue
+		}
+		if len([]rune(name)) > 200 {
+			name = string([]rune(name)[:200])
+		}
+		var uid *string
+		if ev.UID != "" {
+			u := ev.UID
+			uid = &u
+		}
+		for _, d := range ev.Dates() {
+			if cmd.Year != 0 && d.Year() != cmd.Year {
+				resp.Skipped++
+				continue
+			}
+			rows = append(rows, repository.Holiday{
+				CompanyID:   tenant.CompanyID,
+				BranchID:    branchID,
+				HolidayDate: d,
+				Name:        name,
+				Kind:        cmd.Kind,
+				SourceUID:   uid,
+			})
+		}
+	}
+
+	err = h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
+		for _, row := range rows {
+			saved, inserted, err := h.repo.UpsertHoliday(ctxTx, row, user.ID)
+			if err != nil {
+				return err
+			}
+			if inserted {
+				resp.Created++
+			} else {
+				resp.Updated++
+			}
+			resp.Data = append(resp.Data, dto.FromHoliday(*saved))
+		}
+		return nil
+	})
+	if err != nil {
+		if repository.IsForeignKeyViolation(err) {
+			return nil, errs.BadRequest("branch not found")
+		}
+		logger.FromContext(ctx).Error("failed to import holidays", zap.Error(err))
+		return nil, errs.Internal("failed to import holidays")
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   branchID,
+		Action:     "IMPORT",
+		EntityName: "COMPANY_HOLIDAY",
+		EntityID:   tenant.CompanyID.String(),
+		Details: map[string]interface{}{
+			"file_name": cmd.FileName,
+			"kind":      cmd.Kind,
+			"year":      cmd.Year,
+			"created":   resp.Created,
+			"updated":   resp.Updated,
+			"skipped":   resp.Skipped,
+			"errors":    len(resp.Errors),
+		},
+		Timestamp: time.Now(),
+	})
+
+	return resp, nil
+}
@@ -0,0 +1,88 @@
+package importics
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// Import holidays from iCalendar
+// @Summary Import holidays from an iCalendar file
+// @Description นำเข้าวันหยุดจากไฟล์ .ics (VEVENT ละหนึ่งวันหรือหลายวัน) วันที่มีอยู่แล้วจะถูกแทนที่ชื่อและประเภท
+// @Tags Holidays
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "iCalendar file (<=2MB)"
+// @Param kind formData string false "public|company (default public)"
+// @Param branchId formData string false "นำเข้าเป็นวันหยุดเฉพาะสาขา"
+// @Param year formData int false "นำเข้าเฉพาะปีนี้"
+// @Success 200 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /holidays/import [post]
+func NewEndpoint(router fiber.Router) {
+	router.Post("/import", func(c fiber.Ctx) error {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return errs.BadRequest("file is required")
+		}
+		if fileHeader.Size <= 0 {
+			return errs.BadRequest("file is empty")
+		}
+		if fileHeader.Size > maxFileSizeBytes {
+			return errs.BadRequest("file too large (max 2MB)")
+		}
+
+		src, err := fileHeader.Open()
+		if err != nil {
+			return errs.BadRequest("cannot read file")
+		}
+		defer src.Close()
+
+		var buf bytes.Buffer
+		if _, err := buf.ReadFrom(io.LimitReader(src, maxFileSizeBytes+1)); err != nil {
+			return errs.BadRequest("cannot read file")
+		}
+		if int64(buf.Len()) > maxFileSizeBytes {
+			return errs.BadRequest("file too large (max 2MB)")
+		}
+
+		cmd := Command{
+			Data:     buf.Bytes(),
+			FileName: strings.TrimSpace(fileHeader.Filename),
+			Kind:     c.FormValue("kind"),
+		}
+		if s := strings.TrimSpace(c.FormValue("branchId")); s != "" {
+			id, err := uuid.Parse(s)
+			if err != nil {
+				return errs.BadRequest("invalid branchId")
+			}
+			cmd.BranchID = &id
+		}
+		if s := strings.TrimSpace(c.FormValue("year")); s != "" {
+			year, err := strconv.Atoi(s)
+			if err != nil {
+				return errs.BadRequest("invalid year")
+			}
+			cmd.Year = year
+		}
+
+		resp, err := mediator.Send[*Command, *Response](c.Context(), &cmd)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
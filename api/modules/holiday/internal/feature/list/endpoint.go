@@ -0,0 +1,78 @@
+package list
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// List holidays
+// @Summary List holidays
+// @Description รายการวันหยุดของบริษัท (รวมวันหยุดเฉพาะสาขาที่เลือก) กรองตามปี ช่วงวันที่ หรือประเภทได้
+// @Tags Holidays
+// @Produce json
+// @Security BearerAuth
+// @Param year query int false "ปี ค.ศ. (ใช้แทน from/to)"
+// @Param from query string false "YYYY-MM-DD"
+// @Param to query string false "YYYY-MM-DD"
+// @Param kind query string false "public|company"
+// @Param branchId query string false "สาขา (เฉพาะเมื่อไม่ได้เลือกสาขาใน header)"
+// @Success 200 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /holidays [get]
+func NewEndpoint(router fiber.Router) {
+	router.Get("/", func(c fiber.Ctx) error {
+		q := Query{Kind: strings.TrimSpace(c.Query("kind"))}
+		if q.Kind != "" && q.Kind != "public" && q.Kind != "company" {
+			return errs.BadRequest("kind must be public or company")
+		}
+
+		if y := strings.TrimSpace(c.Query("year")); y != "" {
+			year, err := strconv.Atoi(y)
+			if err != nil || year < 2000 || year > 2100 {
+				return errs.BadRequest("invalid year")
+			}
+			from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+			to := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+			q.From, q.To = &from, &to
+		}
+		if s := strings.TrimSpace(c.Query("from")); s != "" {
+			from, err := time.Parse("2006-01-02", s)
+			if err != nil {
+				return errs.BadRequest("from must be YYYY-MM-DD")
+			}
+			q.From = &from
+		}
+		if s := strings.TrimSpace(c.Query("to")); s != "" {
+			to, err := time.Parse("2006-01-02", s)
+			if err != nil {
+				return errs.BadRequest("to must be YYYY-MM-DD")
+			}
+			q.To = &to
+		}
+		if s := strings.TrimSpace(c.Query("branchId")); s != "" {
+			id, err := uuid.Parse(s)
+			if err != nil {
+				return errs.BadRequest("invalid branchId")
+			}
+			q.BranchID = &id
+		}
+
+		resp, err := mediator.Send[*Query, *Response](c.Context(), &q)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
@@ -0,0 +1,59 @@
+package list
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/holiday/internal/dto"
+	"hrms/modules/holiday/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+)
+
+type Query struct {
+	From     *time.Time
+	To       *time.Time
+	Kind     string
+	BranchID *uuid.UUID
+}
+
+type Response struct {
+	Data []dto.Holiday `json:"data"`
+}
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*Query, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) Handle(ctx context.Context, q *Query) (*Response, error) {
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	if q.From != nil && q.To != nil && q.To.Before(*q.From) {
+		return nil, errs.BadRequest("to must not be before from")
+	}
+
+	holidays, err := h.repo.ListHolidays(ctx, tenant, repository.ListFilter{
+		From:     q.From,
+		To:       q.To,
+		Kind:     q.Kind,
+		BranchID: q.BranchID,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list holidays", zap.Error(err))
+		return nil, errs.Internal("failed to list holidays")
+	}
+	return &Response{Data: dto.FromHolidays(holidays)}, nil
+}
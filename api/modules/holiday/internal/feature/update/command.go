@@ -0,0 +1,115 @@
+package update
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/holiday/internal/dto"
+	"hrms/modules/holiday/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/validator"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	ID   uuid.UUID `json:"-"`
+	Date string    `json:"date" validate:"required"`
+	Name string    `json:"name" validate:"required,max=200"`
+	Kind string    `json:"kind" validate:"required,oneof=public company"`
+}
+
+type Response struct {
+	dto.Holiday
+}
+
+type Handler struct {
+	repo repository.Repository
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, eb: eb}
+}
+
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	cmd.Name = strings.TrimSpace(cmd.Name)
+	cmd.Kind = strings.TrimSpace(cmd.Kind)
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+	date, err := time.Parse("2006-01-02", strings.TrimSpace(cmd.Date))
+	if err != nil {
+		return nil, errs.BadRequest("date must be YYYY-MM-DD")
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+
+	current, err := h.repo.GetHoliday(ctx, tenant, cmd.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("holiday not found")
+		}
+		logger.FromContext(ctx).Error("failed to load holiday", zap.Error(err))
+		return nil, errs.Internal("failed to load holiday")
+	}
+
+	updated, err := h.repo.UpdateHoliday(ctx, tenant, repository.Holiday{
+		ID:          cmd.ID,
+		HolidayDate: date,
+		Name:        cmd.Name,
+		Kind:        cmd.Kind,
+	}, user.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("holiday not found")
+		}
+		if repository.IsUniqueViolation(err) {
+			return nil, errs.Conflict("a holiday already exists on this date")
+		}
+		logger.FromContext(ctx).Error("failed to update holiday", zap.Error(err))
+		return nil, errs.Internal("failed to update holiday")
+	}
+
+	details := map[string]interface{}{}
+	if !updated.HolidayDate.Equal(current.HolidayDate) {
+		details["date"] = updated.HolidayDate.Format("2006-01-02")
+	}
+	if updated.Name != current.Name {
+		details["name"] = updated.Name
+	}
+	if updated.Kind != current.Kind {
+		details["kind"] = updated.Kind
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   updated.BranchID,
+		Action:     "UPDATE",
+		EntityName: "COMPANY_HOLIDAY",
+		EntityID:   updated.ID.String(),
+		Details:    details,
+		Timestamp:  time.Now(),
+	})
+
+	return &Response{Holiday: dto.FromHoliday(*updated)}, nil
+}
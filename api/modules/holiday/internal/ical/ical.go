@@ -0,0 +1,185 @@
+// Package ical reads the VEVENTs of an iCalendar (RFC 5545) file, enough to import holiday
+// calendars such as the ones published by Google Calendar or the Bank of Thailand.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// MaxEventDays caps how many days a single event may span; longer events are rejected.
+const MaxEventDays = 31
+
+// Event is one VEVENT reduced to whole days. End is exclusive, as DTEND is for all-day events.
+type Event struct {
+	Line    int
+	UID     string
+	Summary string
+	Start   time.Time
+	End     time.Time
+	Err     error
+}
+
+// Dates returns every day the event covers.
+func (e Event) Dates() []time.Time {
+	var out []time.Time
+	for d := e.Start; d.Before(e.End); d = d.AddDate(0, 0, 1) {
+		out = append(out, d)
+	}
+	return out
+}
+
+// Parse returns the events in the file in order. An event whose dates cannot be read is
+// returned with Err set so the caller can report it; only a malformed file fails the parse.
+// Recurrence rules are not expanded: a recurring event yields its first occurrence.
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events  []Event
+		cur     *Event
+		sawCal  bool
+		endSeen bool
+	)
+	for _, l := range lines {
+		name, params, value := splitLine(l.text)
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VCALENDAR"):
+			sawCal = true
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			cur = &Event{Line: l.no}
+			endSeen = false
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if cur == nil {
+				return nil, fmt.Errorf("line %d: END:VEVENT without BEGIN:VEVENT", l.no)
+			}
+			if cur.Err == nil {
+				switch {
+				case cur.Start.IsZero():
+					cur.Err = fmt.Errorf("missing DTSTART")
+				case !endSeen:
+					cur.End = cur.Start.AddDate(0, 0, 1)
+				case !cur.End.After(cur.Start):
+					// DTEND on or before DTSTART still covers the start day
+					cur.End = cur.Start.AddDate(0, 0, 1)
+				case cur.End.Sub(cur.Start) > MaxEventDays*24*time.Hour:
+					cur.Err = fmt.Errorf("event spans more than %d days", MaxEventDays)
+				}
+			}
+			events = append(events, *cur)
+			cur = nil
+		case cur == nil:
+			// calendar properties and other components (VTIMEZONE, VALARM) are not needed
+		case name == "UID":
+			cur.UID = value
+		case name == "SUMMARY":
+			cur.Summary = unescape(value)
+		case name == "DTSTART":
+			d, err := parseDate(value, params)
+			if err != nil {
+				cur.Err = fmt.Errorf("DTSTART: %w", err)
+				continue
+			}
+			cur.Start = d
+		case name == "DTEND":
+			d, err := parseDate(value, params)
+			if err != nil {
+				cur.Err = fmt.Errorf("DTEND: %w", err)
+				continue
+			}
+			endSeen = true
+			cur.End = d
+			if !isDateOnly(value, params) && !atMidnight(value) {
+				// a timed event covers the day it ends on, unless it ends as that day begins
+				cur.End = d.AddDate(0, 0, 1)
+			}
+		}
+	}
+	if !sawCal {
+		return nil, fmt.Errorf("not an iCalendar file")
+	}
+	if cur != nil {
+		return nil, fmt.Errorf("line %d: VEVENT is not closed", cur.Line)
+	}
+	return events, nil
+}
+
+type line struct {
+	no   int
+	text string
+}
+
+// unfold joins continuation lines (starting with a space or tab) onto the line before them.
+func unfold(r io.Reader) ([]line, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var out []line
+	no := 0
+	for sc.Scan() {
+		no++
+		text := strings.TrimRight(sc.Text(), "\r")
+		if no == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		if (strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t")) && len(out) > 0 {
+			out[len(out)-1].text += text[1:]
+			continue
+		}
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		out = append(out, line{no: no, text: text})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// splitLine splits "NAME;PARAM=X;PARAM=Y:value" into its upper-cased name, parameters and value.
+func splitLine(s string) (string, map[string]string, string) {
+	head, value, _ := strings.Cut(s, ":")
+	parts := strings.Split(head, ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	return strings.ToUpper(parts[0]), params, value
+}
+
+func isDateOnly(value string, params map[string]string) bool {
+	return strings.EqualFold(params["VALUE"], "DATE") || len(strings.TrimSpace(value)) == 8
+}
+
+// atMidnight reports whether a DATE-TIME value is 00:00:00 on its day.
+func atMidnight(value string) bool {
+	return strings.HasPrefix(strings.TrimSpace(value)[9:], "000000")
+}
+
+// parseDate reads a DATE or DATE-TIME value and keeps the calendar day as written.
+func parseDate(value string, params map[string]string) (time.Time, error) {
+	v := strings.TrimSpace(value)
+	if len(v) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	if !isDateOnly(v, params) && (len(v) < 15 || v[8] != 'T') {
+		return time.Time{}, fmt.Errorf("invalid date-time %q", value)
+	}
+	d, err := time.Parse("20060102", v[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return d, nil
+}
+
+func unescape(s string) string {
+	r := strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`)
+	return strings.TrimSpace(r.Replace(s))
+}
@@ -0,0 +1,56 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func day(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+func parseOne(t *testing.T, props ...string) Event {
+	t.Helper()
+	src := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\n" + strings.Join(props, "\r\n") + "\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+	events, err := Parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("Parse() returned %d events, want 1", len(events))
+	}
+	if events[0].Err != nil {
+		t.Fatalf("event error = %v", events[0].Err)
+	}
+	return events[0]
+}
+
+func TestParseEventDays(t *testing.T) {
+	tests := []struct {
+		name       string
+		props      []string
+		start, end time.Time
+		days       int
+	}{
+		{"all-day", []string{"DTSTART;VALUE=DATE:20260413", "DTEND;VALUE=DATE:20260416"}, day(2026, 4, 13), day(2026, 4, 16), 3},
+		{"all-day without DTEND", []string{"DTSTART;VALUE=DATE:20260101"}, day(2026, 1, 1), day(2026, 1, 2), 1},
+		{"timed within a day", []string{"DTSTART:20260505T090000", "DTEND:20260505T170000"}, day(2026, 5, 5), day(2026, 5, 6), 1},
+		{"timed ending at midnight", []string{"DTSTART:20260505T000000", "DTEND:20260506T000000"}, day(2026, 5, 5), day(2026, 5, 6), 1},
+		{"timed over two days ending at midnight", []string{"DTSTART:20260505T080000", "DTEND:20260507T000000"}, day(2026, 5, 5), day(2026, 5, 7), 2},
+		{"timed ending after midnight", []string{"DTSTART:20260505T080000", "DTEND:20260506T000001"}, day(2026, 5, 5), day(2026, 5, 7), 2},
+		{"UTC", []string{"DTSTART:20260505T020000Z", "DTEND:20260505T100000Z"}, day(2026, 5, 5), day(2026, 5, 6), 1},
+		{"UTC ending at midnight", []string{"DTSTART:20260505T000000Z", "DTEND:20260506T000000Z"}, day(2026, 5, 5), day(2026, 5, 6), 1},
+		{"with TZID", []string{"DTSTART;TZID=Asia/Bangkok:20260505T000000", "DTEND;TZID=Asia/Bangkok:20260506T000000"}, day(2026, 5, 5), day(2026, 5, 6), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := parseOne(t, tt.props...)
+			if !e.Start.Equal(tt.start) || !e.End.Equal(tt.end) {
+				t.Errorf("event = %s..%s, want %s..%s", e.Start.Format(time.DateOnly), e.End.Format(time.DateOnly),
+					tt.start.Format(time.DateOnly), tt.end.Format(time.DateOnly))
+			}
+			if got := len(e.Dates()); got != tt.days {
+				t.Errorf("Dates() has %d days, want %d", got, tt.days)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/storage/sqldb/transactor"
+)
+
+type Repository struct {
+	dbCtx transactor.DBTXContext
+}
+
+func NewRepository(dbCtx transactor.DBTXContext) Repository {
+	return Repository{dbCtx: dbCtx}
+}
+
+type Holiday struct {
+	ID          uuid.UUID  `db:"id"`
+	CompanyID   uuid.UUID  `db:"company_id"`
+	BranchID    *uuid.UUID `db:"branch_id"`
+	HolidayDate time.Time  `db:"holiday_date"`
+	Name        string     `db:"name"`
+	Kind        string     `db:"kind"`
+	SourceUID   *string    `db:"source_uid"`
+	CreatedAt   time.Time  `db:"created_at"`
+	CreatedBy   uuid.UUID  `db:"created_by"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+	UpdatedBy   uuid.UUID  `db:"updated_by"`
+}
+
+const holidayColumns = `id, company_id, branch_id, holiday_date, name, kind, source_uid, created_at, created_by, updated_at, updated_by`
+
+// ListFilter narrows the calendar; zero values mean no filter.
+type ListFilter struct {
+	From     *time.Time
+	To       *time.Time
+	Kind     string
+	BranchID *uuid.UUID
+}
+
+// ListHolidays returns the company's calendar. A branch tenant sees the company-wide holidays
+// as well as its own.
+func (r Repository) ListHolidays(ctx context.Context, tenant contextx.TenantInfo, f ListFilter) ([]Holiday, error) {
+	db := r.dbCtx(ctx)
+	where := []string{"company_id = $1", "deleted_at IS NULL"}
+	args := []interface{}{tenant.CompanyID}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where = append(where, fmt.Sprintf("(branch_id IS NULL OR branch_id = $%d)", len(args)))
+	} else if f.BranchID != nil {
+		args = append(args, *f.BranchID)
+		where = append(where, fmt.Sprintf("(branch_id IS NULL OR branch_id = $%d)", len(args)))
+	}
+	if f.From != nil {
+		args = append(args, *f.From)
+		where = append(where, fmt.Sprintf("holiday_date >= $%d", len(args)))
+	}
+	if f.To != nil {
+		args = append(args, *f.To)
+		where = append(where, fmt.Sprintf("holiday_date <= $%d", len(args)))
+	}
+	if f.Kind != "" {
+		args = append(args, f.Kind)
+		where = append(where, fmt.Sprintf("kind = $%d", len(args)))
+	}
+	q := fmt.Sprintf(`SELECT %s FROM company_holiday WHERE %s ORDER BY holiday_date, branch_id NULLS FIRST`,
+		holidayColumns, strings.Join(where, " AND "))
+	var out []Holiday
+	if err := db.SelectContext(ctx, &out, q, args...); err != nil {
+		return nil, err
+	}
+	if out == nil {
+		out = []Holiday{}
+	}
+	return out, nil
+}
+
+// ListObserved returns the holidays a branch observes between from and to. A date that is both
+// a company and a branch holiday is returned once, with the branch's entry.
+func (r Repository) ListObserved(ctx context.Context, companyID uuid.UUID, branchID *uuid.UUID, from, to time.Time) ([]Holiday, error) {
+	db := r.dbCtx(ctx)
+	q := fmt.Sprintf(`
+SELECT DISTINCT ON (holiday_date) %s
+FROM company_holiday
+WHERE company_id = $1 AND deleted_at IS NULL
+  AND (branch_id IS NULL OR branch_id = $2)
+  AND holiday_date BETWEEN $3 AND $4
+ORDER BY holiday_date, branch_id NULLS LAST`, holidayColumns)
+	var out []Holiday
+	if err := db.SelectContext(ctx, &out, q, companyID, branchID, from, to); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r Repository) GetHoliday(ctx context.Context, tenant contextx.TenantInfo, id uuid.UUID) (*Holiday, error) {
+	db := r.dbCtx(ctx)
+	where := "id = $1 AND company_id = $2 AND deleted_at IS NULL"
+	args := []interface{}{id, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where += " AND branch_id = $3"
+	}
+	var h Holiday
+	if err := db.GetContext(ctx, &h, fmt.Sprintf(`SELECT %s FROM company_holiday WHERE %s`, holidayColumns, where), args...); err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+func (r Repository) CreateHoliday(ctx context.Context, h Holiday, actor uuid.UUID) (*Holiday, error) {
+	db := r.dbCtx(ctx)
+	q := fmt.Sprintf(`
+INSERT INTO company_holiday (company_id, branch_id, holiday_date, name, kind, source_uid, created_by, updated_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+RETURNING %s`, holidayColumns)
+	var out Holiday
+	if err := db.GetContext(ctx, &out, q, h.CompanyID, h.BranchID, h.HolidayDate, h.Name, h.Kind, h.SourceUID, actor); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpsertHoliday inserts the holiday or, when the company/branch already has one on that date,
+// replaces its name, kind and source. The returned flag reports whether a row was inserted.
+func (r Repository) UpsertHoliday(ctx context.Context, h Holiday, actor uuid.UUID) (*Holiday, bool, error) {
+	db := r.dbCtx(ctx)
+	q := fmt.Sprintf(`
+INSERT INTO company_holiday (company_id, branch_id, holiday_date, name, kind, source_uid, created_by, updated_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+ON CONFLICT (company_id, COALESCE(branch_id, '00000000-0000-0000-0000-000000000000'::uuid), holiday_date)
+  WHERE deleted_at IS NULL
+DO UPDATE SET name = EXCLUDED.name, kind = EXCLUDED.kind, source_uid = EXCLUDED.source_uid, updated_by = EXCLUDED.updated_by
+RETURNING %s, (xmax = 0) AS inserted`, holidayColumns)
+	var out struct {
+		Holiday
+		Inserted bool `db:"inserted"`
+	}
+	if err := db.GetContext(ctx, &out, q, h.CompanyID, h.BranchID, h.HolidayDate, h.Name, h.Kind, h.SourceUID, actor); err != nil {
+		return nil, false, err
+	}
+	return &out.Holiday, out.Inserted, nil
+}
+
+func (r Repository) UpdateHoliday(ctx context.Context, tenant contextx.TenantInfo, h Holiday, actor uuid.UUID) (*Holiday, error) {
+	db := r.dbCtx(ctx)
+	where := "id = $5 AND company_id = $6 AND deleted_at IS NULL"
+	args := []interface{}{h.HolidayDate, h.Name, h.Kind, actor, h.ID, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where += " AND branch_id = $7"
+	}
+	q := fmt.Sprintf(`
+UPDATE company_holiday
+SET holiday_date = $1, name = $2, kind = $3, updated_by = $4
+WHERE %s
+RETURNING %s`, where, holidayColumns)
+	var out Holiday
+	if err := db.GetContext(ctx, &out, q, args...); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SoftDeleteHoliday removes a holiday. A branch tenant can only remove its branch's own days.
+func (r Repository) SoftDeleteHoliday(ctx context.Context, tenant contextx.TenantInfo, id, actor uuid.UUID) error {
+	db := r.dbCtx(ctx)
+	q := `UPDATE company_holiday SET deleted_at = now(), deleted_by = $1, updated_by = $1
+WHERE id = $2 AND company_id = $3 AND deleted_at IS NULL`
+	args := []interface{}{actor, id, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		q += " AND branch_id = $4"
+		args = append(args, tenant.BranchID)
+	}
+	res, err := db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505"
+	}
+	return false
+}
+
+// IsForeignKeyViolation reports a branch that does not exist.
+func IsForeignKeyViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23503"
+	}
+	return false
+}
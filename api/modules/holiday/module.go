@@ -0,0 +1,61 @@
+package holiday
+
+import (
+	"hrms/modules/holiday/internal/feature/calendar"
+	"hrms/modules/holiday/internal/feature/create"
+	"hrms/modules/holiday/internal/feature/delete"
+	"hrms/modules/holiday/internal/feature/importics"
+	"hrms/modules/holiday/internal/feature/list"
+	"hrms/modules/holiday/internal/feature/update"
+	"hrms/modules/holiday/internal/repository"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/jwt"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/middleware"
+	"hrms/shared/common/module"
+	"hrms/shared/contracts"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Module owns the holiday calendar. Worklog and leave consult it through contracts.
+type Module struct {
+	ctx      *module.ModuleContext
+	repo     repository.Repository
+	tokenSvc *jwt.TokenService
+	eb       eventbus.EventBus
+}
+
+func NewModule(ctx *module.ModuleContext, tokenSvc *jwt.TokenService) *Module {
+	return &Module{
+		ctx:      ctx,
+		repo:     repository.NewRepository(ctx.DBCtx),
+		tokenSvc: tokenSvc,
+	}
+}
+
+func (m *Module) APIVersion() string { return "v1" }
+
+func (m *Module) Init(eb eventbus.EventBus) error {
+	m.eb = eb
+	mediator.Register[*list.Query, *list.Response](list.NewHandler(m.repo))
+	mediator.Register[*create.Command, *create.Response](create.NewHandler(m.repo, eb))
+	mediator.Register[*update.Command, *update.Response](update.NewHandler(m.repo, eb))
+	mediator.Register[*delete.Command, mediator.NoResponse](delete.NewHandler(m.repo, eb))
+	mediator.Register[*importics.Command, *importics.Response](importics.NewHandler(m.repo, m.ctx.Transactor, eb))
+
+	// contract handlers used by worklog and leave
+	mediator.Register[*contracts.ListHolidaysQuery, *contracts.ListHolidaysResponse](calendar.NewHandler(m.repo))
+	return nil
+}
+
+func (m *Module) RegisterRoutes(r fiber.Router) {
+	// timekeepers read the calendar when entering worklogs
+	holidays := r.Group("/holidays", middleware.Auth(m.tokenSvc), middleware.TenantMiddleware(), middleware.RequireRoles("admin", "hr", "timekeeper"))
+	list.NewEndpoint(holidays)
+	manage := holidays.Group("", middleware.RequireRoles("admin", "hr"))
+	importics.NewEndpoint(manage)
+	create.NewEndpoint(manage)
+	update.NewEndpoint(manage)
+	delete.NewEndpoint(manage)
+}
@@ -0,0 +1,178 @@
+package dto
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/leave/internal/repository"
+	"hrms/shared/common/errs"
+	"hrms/shared/contracts"
+)
+
+type LeaveType struct {
+	ID               uuid.UUID         `json:"id"`
+	Code             string            `json:"code"`
+	Name             string            `json:"name"`
+	PayType          string            `json:"payType"`
+	EntryTypes       []string          `json:"entryTypes"`
+	EnforceBalance   bool              `json:"enforceBalance"`
+	CarryOverMaxDays float64           `json:"carryOverMaxDays"`
+	IsActive         bool              `json:"isActive"`
+	HasQuota         bool              `json:"hasQuota"`
+	Rules            []EntitlementRule `json:"rules"`
+	CreatedAt        time.Time         `json:"createdAt"`
+	UpdatedAt        time.Time         `json:"updatedAt"`
+}
+
+type EntitlementRule struct {
+	MinTenureMonths int     `json:"minTenureMonths" validate:"gte=0,lte=600"`
+	DaysPerYear     float64 `json:"daysPerYear" validate:"gte=0,lte=366"`
+}
+
+func FromLeaveType(t repository.LeaveType) LeaveType {
+	rules := make([]EntitlementRule, 0, len(t.Rules))
+	for _, r := range t.Rules {
+		rules = append(rules, EntitlementRule{MinTenureMonths: r.MinTenureMonths, DaysPerYear: r.DaysPerYear})
+	}
+	return LeaveType{
+		ID:               t.ID,
+		Code:             t.Code,
+		Name:             t.Name,
+		PayType:          t.PayType,
+		EntryTypes:       EntryTypesFor(t.PayType),
+		EnforceBalance:   t.EnforceBalance,
+		CarryOverMaxDays: t.CarryOverMaxDays,
+		IsActive:         t.IsActive,
+		HasQuota:         t.HasQuota(),
+		Rules:            rules,
+		CreatedAt:        t.CreatedAt,
+		UpdatedAt:        t.UpdatedAt,
+	}
+}
+
+func ToContract(t repository.LeaveType) contracts.LeaveTypeDTO {
+	return contracts.LeaveTypeDTO{
+		ID:             t.ID,
+		Code:           t.Code,
+		Name:           t.Name,
+		PayType:        t.PayType,
+		EnforceBalance: t.EnforceBalance,
+	}
+}
+
+// EntryTypesFor lists the worklog entry types that record leave of the pay type.
+func EntryTypesFor(payType string) []string {
+	switch payType {
+	case contracts.LeavePayPaid:
+		return []string{"leave_paid"}
+	case contracts.LeavePayUnpaid:
+		return []string{"leave_day", "leave_hours"}
+	case contracts.LeavePayDeducted:
+		return []string{"leave_double"}
+	}
+	return []string{}
+}
+
+// CheckEntry validates leave recorded as entryType against the leave type and, for a type with
+// a yearly entitlement and enforced balance, against what is left of the year's balance after
+// approved and pending leave. It returns the days left before this leave (zero without a quota).
+func CheckEntry(lt repository.LeaveType, entryType string, bal repository.EntryBalance, year int) (float64, error) {
+	if !lt.IsActive {
+		return 0, errs.BadRequest(fmt.Sprintf("leave type %s is not active", lt.Code))
+	}
+	allowed := EntryTypesFor(lt.PayType)
+	if !slices.Contains(allowed, entryType) {
+		return 0, errs.BadRequest(fmt.Sprintf("leave type %s (%s) is recorded as %s", lt.Code, lt.PayType, strings.Join(allowed, " or ")))
+	}
+	if !lt.HasQuota() {
+		return 0, nil
+	}
+	remaining := bal.TotalDays - bal.UsedDays - bal.PendingDays
+	if !lt.EnforceBalance {
+		return remaining, nil
+	}
+	if !bal.HasBalance {
+		return 0, errs.BadRequest(fmt.Sprintf("%s balance for %d has not been opened", lt.Code, year))
+	}
+	if bal.EntryDays > remaining+0.0001 {
+		return 0, errs.BadRequest(fmt.Sprintf("insufficient %s balance: %.2f day(s) left, %.2f requested", lt.Code, remaining, bal.EntryDays))
+	}
+	return remaining, nil
+}
+
+// ToRules checks the rules and orders them by tenure; tenures must not repeat.
+func ToRules(in []EntitlementRule) ([]repository.EntitlementRule, error) {
+	seen := make(map[int]bool, len(in))
+	out := make([]repository.EntitlementRule, 0, len(in))
+	for i, r := range in {
+		if seen[r.MinTenureMonths] {
+			return nil, errs.BadRequest(fmt.Sprintf("rules[%d]: minTenureMonths %d is repeated", i, r.MinTenureMonths))
+		}
+		seen[r.MinTenureMonths] = true
+		out = append(out, repository.EntitlementRule{MinTenureMonths: r.MinTenureMonths, DaysPerYear: r.DaysPerYear})
+	}
+	return out, nil
+}
+
+type Balance struct {
+	ID             *uuid.UUID `json:"id,omitempty"`
+	EmployeeID     uuid.UUID  `json:"employeeId"`
+	EmployeeNumber string     `json:"employeeNumber"`
+	EmployeeName   string     `json:"employeeName"`
+	LeaveTypeID    uuid.UUID  `json:"leaveTypeId"`
+	LeaveTypeCode  string     `json:"leaveTypeCode"`
+	LeaveTypeName  string     `json:"leaveTypeName"`
+	PayType        string     `json:"payType"`
+	Year           int        `json:"year"`
+	Opened         bool       `json:"opened"`
+	EntitledDays   float64    `json:"entitledDays"`
+	CarriedDays    float64    `json:"carriedDays"`
+	AdjustmentDays float64    `json:"adjustmentDays"`
+	TotalDays      float64    `json:"totalDays"`
+	UsedDays       float64    `json:"usedDays"`
+	PendingDays    float64    `json:"pendingDays"`
+	RemainingDays  float64    `json:"remainingDays"`
+	AvailableDays  float64    `json:"availableDays"`
+	Note           *string    `json:"note,omitempty"`
+}
+
+func FromBalance(b repository.Balance) Balance {
+	return Balance{
+		ID:             b.ID,
+		EmployeeID:     b.EmployeeID,
+		EmployeeNumber: b.EmployeeNumber,
+		EmployeeName:   b.FirstName + " " + b.LastName,
+		LeaveTypeID:    b.LeaveTypeID,
+		LeaveTypeCode:  b.LeaveTypeCode,
+		LeaveTypeName:  b.LeaveTypeName,
+		PayType:        b.PayType,
+		Year:           b.BalanceYear,
+		Opened:         b.ID != nil,
+		EntitledDays:   b.EntitledDays,
+		CarriedDays:    b.CarriedDays,
+		AdjustmentDays: b.AdjustmentDays,
+		TotalDays:      round2(b.Total()),
+		UsedDays:       round2(b.UsedDays),
+		PendingDays:    round2(b.PendingDays),
+		RemainingDays:  round2(b.Remaining()),
+		AvailableDays:  round2(b.Remaining() - b.PendingDays),
+		Note:           b.Note,
+	}
+}
+
+func FromBalances(bs []repository.Balance) []Balance {
+	out := make([]Balance, 0, len(bs))
+	for _, b := range bs {
+		out = append(out, FromBalance(b))
+	}
+	return out
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
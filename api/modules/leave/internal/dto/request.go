@@ -0,0 +1,111 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/leave/internal/repository"
+	"hrms/shared/contracts"
+	"hrms/shared/events"
+)
+
+type Request struct {
+	ID              uuid.UUID  `json:"id"`
+	EmployeeID      uuid.UUID  `json:"employeeId"`
+	EmployeeNumber  string     `json:"employeeNumber"`
+	EmployeeName    string     `json:"employeeName"`
+	LeaveTypeID     uuid.UUID  `json:"leaveTypeId"`
+	LeaveTypeCode   string     `json:"leaveTypeCode"`
+	LeaveTypeName   string     `json:"leaveTypeName"`
+	EntryType       string     `json:"entryType"`
+	StartDate       string     `json:"startDate"`
+	EndDate         string     `json:"endDate"`
+	Hours           *float64   `json:"hours,omitempty"`
+	WorkDates       []string   `json:"workDates"`
+	Days            float64    `json:"days"`
+	Reason          *string    `json:"reason,omitempty"`
+	Status          string     `json:"status"`
+	DecidedAt       *time.Time `json:"decidedAt,omitempty"`
+	DecidedBy       *uuid.UUID `json:"decidedBy,omitempty"`
+	DecisionComment *string    `json:"decisionComment,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	CreatedBy       uuid.UUID  `json:"createdBy"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+}
+
+func FromRequest(r repository.Request) Request {
+	dates := []string(r.WorkDates)
+	if dates == nil {
+		dates = []string{}
+	}
+	return Request{
+		ID:              r.ID,
+		EmployeeID:      r.EmployeeID,
+		EmployeeNumber:  r.EmployeeNumber,
+		EmployeeName:    r.FirstName + " " + r.LastName,
+		LeaveTypeID:     r.LeaveTypeID,
+		LeaveTypeCode:   r.LeaveTypeCode,
+		LeaveTypeName:   r.LeaveTypeName,
+		EntryType:       r.EntryType,
+		StartDate:       r.StartDate.Format("2006-01-02"),
+		EndDate:         r.EndDate.Format("2006-01-02"),
+		Hours:           r.Hours,
+		WorkDates:       dates,
+		Days:            round2(r.Days),
+		Reason:          r.Reason,
+		Status:          r.Status,
+		DecidedAt:       r.DecidedAt,
+		DecidedBy:       r.DecidedBy,
+		DecisionComment: r.DecisionComment,
+		CreatedAt:       r.CreatedAt,
+		CreatedBy:       r.CreatedBy,
+		UpdatedAt:       r.UpdatedAt,
+	}
+}
+
+func FromRequests(rs []repository.Request) []Request {
+	out := make([]Request, 0, len(rs))
+	for _, r := range rs {
+		out = append(out, FromRequest(r))
+	}
+	return out
+}
+
+// RequestEvent builds the event published after a request is submitted or decided. approval is
+// the request's approval after the action, nil when no chain applies; a pending request without
+// one waits for admin or HR.
+func RequestEvent(action string, r repository.Request, approval *contracts.ApprovalRequestDTO, actor uuid.UUID, comment string) events.LeaveRequestEvent {
+	ev := events.LeaveRequestEvent{
+		Action:      action,
+		CompanyID:   r.CompanyID,
+		BranchID:    r.BranchID,
+		RequestID:   r.ID,
+		EmployeeID:  r.EmployeeID,
+		RequestedBy: r.CreatedBy,
+		ActorID:     actor,
+		Status:      r.Status,
+		StartDate:   r.StartDate,
+		EndDate:     r.EndDate,
+		Days:        round2(r.Days),
+		Comment:     comment,
+		Timestamp:   time.Now(),
+	}
+	if r.Status != repository.RequestPending {
+		return ev
+	}
+	if approval == nil {
+		ev.ApproverRoles = []string{"admin", "hr"}
+		return ev
+	}
+	for _, s := range approval.Steps {
+		if s.StepNo != approval.CurrentStep {
+			continue
+		}
+		if s.ApproverRole != nil {
+			ev.ApproverRoles = []string{*s.ApproverRole}
+		}
+		ev.ApproverUserID = s.ApproverUserID
+	}
+	return ev
+}
@@ -0,0 +1,97 @@
+package adjust
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/leave/internal/dto"
+	"hrms/modules/leave/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/validator"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	ID             uuid.UUID `json:"-"`
+	AdjustmentDays float64   `json:"adjustmentDays" validate:"gte=-366,lte=366"`
+	Note           string    `json:"note" validate:"max=500"`
+}
+
+type Response struct {
+	dto.Balance
+}
+
+type Handler struct {
+	repo repository.Repository
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, eb: eb}
+}
+
+// Handle sets the balance's adjustment (replacing the previous one), e.g. leave granted on top
+// of the entitlement or days taken before the system was used.
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	cmd.Note = strings.TrimSpace(cmd.Note)
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+
+	var note *string
+	if cmd.Note != "" {
+		note = &cmd.Note
+	}
+	if err := h.repo.AdjustBalance(ctx, tenant, cmd.ID, cmd.AdjustmentDays, note, user.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("leave balance not found")
+		}
+		logger.FromContext(ctx).Error("failed to adjust leave balance", zap.Error(err))
+		return nil, errs.Internal("failed to adjust leave balance")
+	}
+	balance, err := h.repo.GetBalance(ctx, tenant, cmd.ID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load leave balance", zap.Error(err))
+		return nil, errs.Internal("failed to load leave balance")
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "UPDATE",
+		EntityName: "LEAVE_BALANCE",
+		EntityID:   cmd.ID.String(),
+		Details: map[string]interface{}{
+			"employee_id":     balance.EmployeeID.String(),
+			"leave_type":      balance.LeaveTypeCode,
+			"year":            balance.BalanceYear,
+			"adjustment_days": cmd.AdjustmentDays,
+			"note":            cmd.Note,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return &Response{Balance: dto.FromBalance(*balance)}, nil
+}
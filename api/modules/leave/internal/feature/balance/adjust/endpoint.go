@@ -0,0 +1,46 @@
+package adjust
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// Adjust leave balance
+// @Summary Adjust a leave balance
+// @Description ปรับยอดสิทธิ์ลา (บวก/ลบ วัน) พร้อมหมายเหตุ ค่าใหม่แทนที่ค่าปรับเดิม
+// @Tags Leave
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "balance id"
+// @Param request body Command true "adjustment payload"
+// @Success 200 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /leave-balances/{id} [put]
+func NewEndpoint(router fiber.Router) {
+	router.Put("/:id", func(c fiber.Ctx) error {
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return errs.BadRequest("invalid id")
+		}
+		var cmd Command
+		if err := c.Bind().Body(&cmd); err != nil {
+			return errs.BadRequest("invalid request body")
+		}
+		cmd.ID = id
+		resp, err := mediator.Send[*Command, *Response](c.Context(), &cmd)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
@@ -0,0 +1,53 @@
+package employee
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// Employee leave balances
+// @Summary Get an employee's leave balances
+// @Description ยอดการลาของพนักงานหนึ่งคนแยกตามประเภท รวมประเภทที่ไม่จำกัดสิทธิ์ (แสดงเฉพาะยอดที่ใช้ไป)
+// @Tags Leave
+// @Produce json
+// @Security BearerAuth
+// @Param employeeId path string true "employee id"
+// @Param year query int false "ปี ค.ศ. (default ปีปัจจุบัน)"
+// @Success 200 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /leave-balances/employees/{employeeId} [get]
+func NewEndpoint(router fiber.Router) {
+	router.Get("/employees/:employeeId", func(c fiber.Ctx) error {
+		employeeID, err := uuid.Parse(c.Params("employeeId"))
+		if err != nil {
+			return errs.BadRequest("invalid employeeId")
+		}
+		q := Query{EmployeeID: employeeID, Year: time.Now().Year()}
+		if s := strings.TrimSpace(c.Query("year")); s != "" {
+			year, err := strconv.Atoi(s)
+			if err != nil || year < 2000 || year > 2100 {
+				return errs.BadRequest("invalid year")
+			}
+			q.Year = year
+		}
+
+		resp, err := mediator.Send[*Query, *Response](c.Context(), &q)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
@@ -0,0 +1,59 @@
+package employee
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/leave/internal/dto"
+	"hrms/modules/leave/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+)
+
+type Query struct {
+	EmployeeID uuid.UUID
+	Year       int
+}
+
+type Response struct {
+	EmployeeID uuid.UUID     `json:"employeeId"`
+	Year       int           `json:"year"`
+	Data       []dto.Balance `json:"data"`
+}
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*Query, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) Handle(ctx context.Context, q *Query) (*Response, error) {
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+
+	exists, err := h.repo.EmployeeExists(ctx, tenant, q.EmployeeID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load employee", zap.Error(err))
+		return nil, errs.Internal("failed to load leave balances")
+	}
+	if !exists {
+		return nil, errs.NotFound("employee not found")
+	}
+
+	balances, err := h.repo.EmployeeBalances(ctx, tenant, q.EmployeeID, q.Year)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load leave balances", zap.Error(err))
+		return nil, errs.Internal("failed to load leave balances")
+	}
+	return &Response{EmployeeID: q.EmployeeID, Year: q.Year, Data: dto.FromBalances(balances)}, nil
+}
@@ -0,0 +1,63 @@
+package list
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// List leave balances
+// @Summary List leave balances
+// @Description รายงานยอดสิทธิ์ลาที่เปิดปีแล้ว: สิทธิ์, ยกยอด, ปรับ, ใช้ไป (อนุมัติ), รออนุมัติ, คงเหลือ
+// @Tags Leave
+// @Produce json
+// @Security BearerAuth
+// @Param year query int false "ปี ค.ศ. (default ปีปัจจุบัน)"
+// @Param employeeId query string false "employee id"
+// @Param leaveTypeId query string false "leave type id"
+// @Success 200 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /leave-balances [get]
+func NewEndpoint(router fiber.Router) {
+	router.Get("/", func(c fiber.Ctx) error {
+		q := Query{Year: time.Now().Year()}
+		if s := strings.TrimSpace(c.Query("year")); s != "" {
+			year, err := strconv.Atoi(s)
+			if err != nil || year < 2000 || year > 2100 {
+				return errs.BadRequest("invalid year")
+			}
+			q.Year = year
+		}
+		if s := strings.TrimSpace(c.Query("employeeId")); s != "" {
+			id, err := uuid.Parse(s)
+			if err != nil {
+				return errs.BadRequest("invalid employeeId")
+			}
+			q.EmployeeID = &id
+		}
+		if s := strings.TrimSpace(c.Query("leaveTypeId")); s != "" {
+			id, err := uuid.Parse(s)
+			if err != nil {
+				return errs.BadRequest("invalid leaveTypeId")
+			}
+			q.LeaveTypeID = &id
+		}
+
+		resp, err := mediator.Send[*Query, *Response](c.Context(), &q)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
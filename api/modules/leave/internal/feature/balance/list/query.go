@@ -0,0 +1,53 @@
+package list
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/leave/internal/dto"
+	"hrms/modules/leave/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+)
+
+type Query struct {
+	Year        int
+	EmployeeID  *uuid.UUID
+	LeaveTypeID *uuid.UUID
+}
+
+type Response struct {
+	Data []dto.Balance `json:"data"`
+}
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*Query, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) Handle(ctx context.Context, q *Query) (*Response, error) {
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+
+	balances, err := h.repo.ListBalances(ctx, tenant, repository.BalanceFilter{
+		Year:        q.Year,
+		EmployeeID:  q.EmployeeID,
+		LeaveTypeID: q.LeaveTypeID,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list leave balances", zap.Error(err))
+		return nil, errs.Internal("failed to list leave balances")
+	}
+	return &Response{Data: dto.FromBalances(balances)}, nil
+}
@@ -0,0 +1,100 @@
+package open
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/leave/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/storage/sqldb/transactor"
+	"hrms/shared/common/validator"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	Year       int        `json:"year" validate:"required,min=2000,max=2100"`
+	EmployeeID *uuid.UUID `json:"employeeId"`
+}
+
+type Response struct {
+	Year     int `json:"year"`
+	Balances int `json:"balances"`
+}
+
+type Handler struct {
+	repo repository.Repository
+	tx   transactor.Transactor
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, tx transactor.Transactor, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, tx: tx, eb: eb}
+}
+
+// Handle opens (or recomputes) the year's balances. Open the previous year first so its
+// remaining days can be carried over.
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+
+	if cmd.EmployeeID != nil {
+		exists, err := h.repo.EmployeeExists(ctx, tenant, *cmd.EmployeeID)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to load employee", zap.Error(err))
+			return nil, errs.Internal("failed to open leave balances")
+		}
+		if !exists {
+			return nil, errs.NotFound("employee not found")
+		}
+	}
+
+	var count int
+	err := h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
+		var err error
+		count, err = h.repo.OpenYear(ctxTx, tenant, cmd.Year, cmd.EmployeeID, user.ID)
+		return err
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to open leave balances", zap.Error(err))
+		return nil, errs.Internal("failed to open leave balances")
+	}
+
+	details := map[string]interface{}{
+		"year":     cmd.Year,
+		"balances": count,
+	}
+	if cmd.EmployeeID != nil {
+		details["employee_id"] = cmd.EmployeeID.String()
+	}
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "OPEN",
+		EntityName: "LEAVE_BALANCE",
+		EntityID:   tenant.CompanyID.String(),
+		Details:    details,
+		Timestamp:  time.Now(),
+	})
+
+	return &Response{Year: cmd.Year, Balances: count}, nil
+}
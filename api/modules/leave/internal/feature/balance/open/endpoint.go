@@ -0,0 +1,39 @@
+package open
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// Open leave year
+// @Summary Open leave balances for a year
+// @Description เปิดยอดสิทธิ์ลาประจำปีให้พนักงานรายเดือนทุกคน (หรือระบุคน) ตามอายุงาน ณ สิ้นปี พร้อมยกยอดคงเหลือจากปีก่อน เรียกซ้ำได้ ยอดปรับโดย HR คงไว้
+// @Tags Leave
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body Command true "year payload"
+// @Success 200 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /leave-balances/open [post]
+func NewEndpoint(router fiber.Router) {
+	router.Post("/open", func(c fiber.Ctx) error {
+		var cmd Command
+		if err := c.Bind().Body(&cmd); err != nil {
+			return errs.BadRequest("invalid request body")
+		}
+		resp, err := mediator.Send[*Command, *Response](c.Context(), &cmd)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
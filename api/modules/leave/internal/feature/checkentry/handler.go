@@ -0,0 +1,55 @@
+package checkentry
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"go.uber.org/zap"
+
+	"hrms/modules/leave/internal/dto"
+	"hrms/modules/leave/internal/repository"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/contracts"
+)
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*contracts.CheckLeaveEntryQuery, *contracts.CheckLeaveEntryResponse] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+// Handle checks that the entry type records the leave type's pay type and, for a type with a
+// yearly entitlement and enforced balance, that the entry fits in what is left after the
+// approved and pending leave of that year.
+func (h *Handler) Handle(ctx context.Context, q *contracts.CheckLeaveEntryQuery) (*contracts.CheckLeaveEntryResponse, error) {
+	lt, err := h.repo.GetLeaveType(ctx, q.CompanyID, q.LeaveTypeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.BadRequest("leave type not found")
+		}
+		logger.FromContext(ctx).Error("failed to load leave type", zap.Error(err))
+		return nil, errs.Internal("failed to check leave balance")
+	}
+	bal, err := h.repo.GetEntryBalance(ctx, q.CompanyID, q.EmployeeID, q.LeaveTypeID, q.EntryType, q.WorkDate, q.Quantity, q.ExcludeEntryID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load leave balance", zap.Error(err))
+		return nil, errs.Internal("failed to check leave balance")
+	}
+	remaining, err := dto.CheckEntry(*lt, q.EntryType, *bal, q.WorkDate.Year())
+	if err != nil {
+		return nil, err
+	}
+	return &contracts.CheckLeaveEntryResponse{
+		LeaveType: dto.ToContract(*lt),
+		Days:      bal.EntryDays,
+		HasQuota:  lt.HasQuota(),
+		Remaining: remaining,
+	}, nil
+}
@@ -0,0 +1,113 @@
+package create
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"hrms/modules/leave/internal/dto"
+	"hrms/modules/leave/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/storage/sqldb/transactor"
+	"hrms/shared/common/validator"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	Code             string                `json:"code" validate:"required,max=50"`
+	Name             string                `json:"name" validate:"required,max=200"`
+	PayType          string                `json:"payType" validate:"required,oneof=paid unpaid deducted"`
+	EnforceBalance   *bool                 `json:"enforceBalance"`
+	CarryOverMaxDays float64               `json:"carryOverMaxDays" validate:"gte=0,lte=366"`
+	IsActive         *bool                 `json:"isActive"`
+	Rules            []dto.EntitlementRule `json:"rules" validate:"max=20,dive"`
+}
+
+type Response struct {
+	dto.LeaveType
+}
+
+type Handler struct {
+	repo repository.Repository
+	tx   transactor.Transactor
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, tx transactor.Transactor, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, tx: tx, eb: eb}
+}
+
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	cmd.Code = strings.TrimSpace(cmd.Code)
+	cmd.Name = strings.TrimSpace(cmd.Name)
+	cmd.PayType = strings.TrimSpace(cmd.PayType)
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+	rules, err := dto.ToRules(cmd.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+
+	lt := repository.LeaveType{
+		CompanyID:        tenant.CompanyID,
+		Code:             cmd.Code,
+		Name:             cmd.Name,
+		PayType:          cmd.PayType,
+		EnforceBalance:   cmd.EnforceBalance == nil || *cmd.EnforceBalance,
+		CarryOverMaxDays: cmd.CarryOverMaxDays,
+		IsActive:         cmd.IsActive == nil || *cmd.IsActive,
+		Rules:            rules,
+	}
+
+	var created *repository.LeaveType
+	err = h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
+		var err error
+		created, err = h.repo.CreateLeaveType(ctxTx, lt, user.ID)
+		return err
+	})
+	if err != nil {
+		if repository.IsUniqueViolation(err) {
+			return nil, errs.Conflict("leave type code already exists")
+		}
+		logger.FromContext(ctx).Error("failed to create leave type", zap.Error(err))
+		return nil, errs.Internal("failed to create leave type")
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "CREATE",
+		EntityName: "LEAVE_TYPE",
+		EntityID:   created.ID.String(),
+		Details: map[string]interface{}{
+			"code":                created.Code,
+			"name":                created.Name,
+			"pay_type":            created.PayType,
+			"enforce_balance":     created.EnforceBalance,
+			"carry_over_max_days": created.CarryOverMaxDays,
+			"rules":               len(created.Rules),
+		},
+		Timestamp: time.Now(),
+	})
+
+	return &Response{LeaveType: dto.FromLeaveType(*created)}, nil
+}
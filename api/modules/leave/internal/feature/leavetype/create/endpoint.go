@@ -0,0 +1,39 @@
+package create
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// Create leave type
+// @Summary Create leave type
+// @Description สร้างประเภทการลา (paid = ได้รับค่าจ้าง, unpaid = ไม่รับค่าจ้าง, deducted = หักแบบทวีคูณ) พร้อมสิทธิ์ต่อปีตามอายุงาน ไม่ระบุ rules = ไม่จำกัดสิทธิ์
+// @Tags Leave
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body Command true "leave type payload"
+// @Success 201 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 409
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /leave-types [post]
+func NewEndpoint(router fiber.Router) {
+	router.Post("/", func(c fiber.Ctx) error {
+		var cmd Command
+		if err := c.Bind().Body(&cmd); err != nil {
+			return errs.BadRequest("invalid request body")
+		}
+		resp, err := mediator.Send[*Command, *Response](c.Context(), &cmd)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusCreated, resp)
+	})
+}
@@ -0,0 +1,66 @@
+package delete
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/leave/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	ID uuid.UUID
+}
+
+type Handler struct {
+	repo repository.Repository
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, mediator.NoResponse] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, eb: eb}
+}
+
+// Handle removes the leave type. Worklog entries already recorded against it keep the link.
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (mediator.NoResponse, error) {
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return mediator.NoResponse{}, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return mediator.NoResponse{}, errs.Unauthorized("missing user context")
+	}
+
+	if err := h.repo.SoftDeleteLeaveType(ctx, tenant, cmd.ID, user.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return mediator.NoResponse{}, errs.NotFound("leave type not found")
+		}
+		logger.FromContext(ctx).Error("failed to delete leave type", zap.Error(err))
+		return mediator.NoResponse{}, errs.Internal("failed to delete leave type")
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "DELETE",
+		EntityName: "LEAVE_TYPE",
+		EntityID:   cmd.ID.String(),
+		Details:    map[string]interface{}{},
+		Timestamp:  time.Now(),
+	})
+	return mediator.NoResponse{}, nil
+}
@@ -0,0 +1,37 @@
+package delete
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+)
+
+// @Summary Delete leave type
+// @Description ลบประเภทการลา รายการลาที่บันทึกไว้แล้วยังอ้างอิงประเภทเดิม
+// @Tags Leave
+// @Security BearerAuth
+// @Param id path string true "leave type id"
+// @Success 204 "No Content"
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /leave-types/{id} [delete]
+func NewEndpoint(router fiber.Router) {
+	router.Delete("/:id", func(c fiber.Ctx) error {
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return errs.BadRequest("invalid id")
+		}
+		if _, err := mediator.Send[*Command, mediator.NoResponse](c.Context(), &Command{
+			ID: id,
+		}); err != nil {
+			return err
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+}
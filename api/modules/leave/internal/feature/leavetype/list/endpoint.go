@@ -0,0 +1,33 @@
+package list
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// List leave types
+// @Summary List leave types
+// @Description รายการประเภทการลาของบริษัท พร้อมสิทธิ์ต่อปีตามอายุงานและประเภท worklog ที่ใช้บันทึก
+// @Tags Leave
+// @Produce json
+// @Security BearerAuth
+// @Param activeOnly query bool false "เฉพาะที่เปิดใช้งาน"
+// @Success 200 {object} Response
+// @Failure 401
+// @Failure 403
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /leave-types [get]
+func NewEndpoint(router fiber.Router) {
+	router.Get("/", func(c fiber.Ctx) error {
+		resp, err := mediator.Send[*Query, *Response](c.Context(), &Query{
+			ActiveOnly: c.Query("activeOnly") == "true",
+		})
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
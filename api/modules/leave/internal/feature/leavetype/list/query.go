@@ -0,0 +1,50 @@
+package list
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"hrms/modules/leave/internal/dto"
+	"hrms/modules/leave/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+)
+
+type Query struct {
+	ActiveOnly bool
+}
+
+type Response struct {
+	Data []dto.LeaveType `json:"data"`
+}
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*Query, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) Handle(ctx context.Context, q *Query) (*Response, error) {
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+
+	types, err := h.repo.ListLeaveTypes(ctx, tenant, q.ActiveOnly)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list leave types", zap.Error(err))
+		return nil, errs.Internal("failed to list leave types")
+	}
+	data := make([]dto.LeaveType, 0, len(types))
+	for _, t := range types {
+		data = append(data, dto.FromLeaveType(t))
+	}
+	return &Response{Data: data}, nil
+}
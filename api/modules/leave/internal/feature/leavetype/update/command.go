@@ -0,0 +1,118 @@
+package update
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/leave/internal/dto"
+	"hrms/modules/leave/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/storage/sqldb/transactor"
+	"hrms/shared/common/validator"
+	"hrms/shared/events"
+)
+
+// Command replaces the type's settings and rules. The pay type cannot change.
+type Command struct {
+	ID               uuid.UUID             `json:"-"`
+	Code             string                `json:"code" validate:"required,max=50"`
+	Name             string                `json:"name" validate:"required,max=200"`
+	EnforceBalance   bool                  `json:"enforceBalance"`
+	CarryOverMaxDays float64               `json:"carryOverMaxDays" validate:"gte=0,lte=366"`
+	IsActive         bool                  `json:"isActive"`
+	Rules            []dto.EntitlementRule `json:"rules" validate:"max=20,dive"`
+}
+
+type Response struct {
+	dto.LeaveType
+}
+
+type Handler struct {
+	repo repository.Repository
+	tx   transactor.Transactor
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, tx transactor.Transactor, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, tx: tx, eb: eb}
+}
+
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	cmd.Code = strings.TrimSpace(cmd.Code)
+	cmd.Name = strings.TrimSpace(cmd.Name)
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+	rules, err := dto.ToRules(cmd.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+
+	lt := repository.LeaveType{
+		ID:               cmd.ID,
+		Code:             cmd.Code,
+		Name:             cmd.Name,
+		EnforceBalance:   cmd.EnforceBalance,
+		CarryOverMaxDays: cmd.CarryOverMaxDays,
+		IsActive:         cmd.IsActive,
+		Rules:            rules,
+	}
+
+	var updated *repository.LeaveType
+	err = h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
+		var err error
+		updated, err = h.repo.UpdateLeaveType(ctxTx, tenant, lt, user.ID)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("leave type not found")
+		}
+		if repository.IsUniqueViolation(err) {
+			return nil, errs.Conflict("leave type code already exists")
+		}
+		logger.FromContext(ctx).Error("failed to update leave type", zap.Error(err))
+		return nil, errs.Internal("failed to update leave type")
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "UPDATE",
+		EntityName: "LEAVE_TYPE",
+		EntityID:   updated.ID.String(),
+		Details: map[string]interface{}{
+			"code":                updated.Code,
+			"name":                updated.Name,
+			"enforce_balance":     updated.EnforceBalance,
+			"carry_over_max_days": updated.CarryOverMaxDays,
+			"is_active":           updated.IsActive,
+			"rules":               len(updated.Rules),
+		},
+		Timestamp: time.Now(),
+	})
+
+	return &Response{LeaveType: dto.FromLeaveType(*updated)}, nil
+}
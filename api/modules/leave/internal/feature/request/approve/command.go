@@ -0,0 +1,202 @@
+package approve
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/leave/internal/dto"
+	"hrms/modules/leave/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/storage/sqldb/transactor"
+	"hrms/shared/common/validator"
+	"hrms/shared/contracts"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	ID      uuid.UUID `json:"-" validate:"required"`
+	Comment string    `json:"comment" validate:"max=1000"`
+}
+
+type Response struct {
+	dto.Request
+	Approval *contracts.ApprovalRequestDTO `json:"approval,omitempty"`
+	EntryIDs []uuid.UUID                   `json:"entryIds,omitempty"`
+	Message  string                        `json:"message"`
+}
+
+type Handler struct {
+	repo repository.Repository
+	tx   transactor.Transactor
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, tx transactor.Transactor, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, tx: tx, eb: eb}
+}
+
+// Handle approves a pending leave request. With an approval chain each call approves the current
+// step; without one admin or HR approves it directly. Once approved the balance is checked again
+// and the request's days are recorded in worklog_ft as pending entries for the next payroll run.
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	cmd.Comment = strings.TrimSpace(cmd.Comment)
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+
+	req, err := h.repo.GetRequest(ctx, tenant, cmd.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("leave request not found")
+		}
+		logger.FromContext(ctx).Error("failed to load leave request", zap.Error(err))
+		return nil, errs.Internal("failed to load leave request")
+	}
+	if req.Status != repository.RequestPending {
+		return nil, errs.BadRequest(req.Status + " leave request cannot be approved")
+	}
+
+	var (
+		decision *contracts.DecideApprovalResponse
+		entries  *contracts.CreateLeaveEntriesResponse
+	)
+	err = h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
+		var err error
+		decision, err = mediator.Send[*contracts.DecideApprovalCommand, *contracts.DecideApprovalResponse](ctxTx, &contracts.DecideApprovalCommand{
+			CompanyID:  req.CompanyID,
+			BranchID:   &req.BranchID,
+			DocType:    contracts.ApprovalDocLeaveRequest,
+			DocID:      req.ID,
+			PreparedBy: req.CreatedBy,
+			ActorID:    user.ID,
+			ActorRole:  user.Role,
+			Approve:    true,
+			Comment:    cmd.Comment,
+		})
+		if err != nil {
+			return err
+		}
+		if !decision.Required && user.Role != "admin" && user.Role != "hr" {
+			return errs.Forbidden("only admin or hr can approve leave requests")
+		}
+		if decision.Required && !decision.Final {
+			return nil
+		}
+
+		lt, err := h.repo.GetLeaveType(ctxTx, req.CompanyID, req.LeaveTypeID)
+		if err != nil {
+			return err
+		}
+		quantity := float64(len(req.WorkDates))
+		if req.Hours != nil {
+			quantity = *req.Hours
+		}
+		bal, err := h.repo.GetRequestBalance(ctxTx, req.CompanyID, req.EmployeeID, req.LeaveTypeID, req.EntryType, req.StartDate, quantity, &req.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := dto.CheckEntry(*lt, req.EntryType, *bal, req.StartDate.Year()); err != nil {
+			return err
+		}
+
+		var comment *string
+		if cmd.Comment != "" {
+			comment = &cmd.Comment
+		}
+		if err := h.repo.DecideRequest(ctxTx, req.ID, repository.RequestApproved, comment, user.ID); err != nil {
+			return err
+		}
+
+		dates, err := req.Dates()
+		if err != nil {
+			return err
+		}
+		perEntry := 1.0
+		if req.Hours != nil {
+			perEntry = *req.Hours
+		}
+		entries, err = mediator.Send[*contracts.CreateLeaveEntriesCommand, *contracts.CreateLeaveEntriesResponse](ctxTx, &contracts.CreateLeaveEntriesCommand{
+			CompanyID:   req.CompanyID,
+			EmployeeID:  req.EmployeeID,
+			LeaveTypeID: req.LeaveTypeID,
+			EntryType:   req.EntryType,
+			Dates:       dates,
+			Quantity:    perEntry,
+			ActorID:     user.ID,
+		})
+		return err
+	})
+	if err != nil {
+		var appErr *errs.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.Conflict("leave request changed, reload and try again")
+		}
+		logger.FromContext(ctx).Error("failed to approve leave request", zap.Error(err))
+		return nil, errs.Internal("failed to approve leave request")
+	}
+
+	updated, err := h.repo.GetRequest(ctx, tenant, req.ID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load leave request", zap.Error(err))
+		return nil, errs.Internal("failed to load leave request")
+	}
+
+	details := map[string]interface{}{"status": updated.Status}
+	if decision.Request != nil {
+		details["approval_request_id"] = decision.Request.ID.String()
+	}
+	if cmd.Comment != "" {
+		details["comment"] = cmd.Comment
+	}
+	resp := &Response{Request: dto.FromRequest(*updated), Approval: decision.Request}
+	if entries != nil {
+		ids := make([]string, 0, len(entries.EntryIDs))
+		for _, id := range entries.EntryIDs {
+			ids = append(ids, id.String())
+		}
+		details["worklog_ids"] = ids
+		resp.EntryIDs = entries.EntryIDs
+		resp.Message = fmt.Sprintf("Leave request approved; %d worklog entries recorded.", len(entries.EntryIDs))
+	} else {
+		resp.Message = fmt.Sprintf("Step %d approved. Waiting for step %d of %d.",
+			decision.Request.CurrentStep-1, decision.Request.CurrentStep, decision.Request.StepCount)
+	}
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "APPROVE",
+		EntityName: "LEAVE_REQUEST",
+		EntityID:   updated.ID.String(),
+		Details:    details,
+		Timestamp:  time.Now(),
+	})
+	h.eb.Publish(dto.RequestEvent("APPROVE", *updated, decision.Request, user.ID, cmd.Comment))
+
+	return resp, nil
+}
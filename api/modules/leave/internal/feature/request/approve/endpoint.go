@@ -0,0 +1,49 @@
+package approve
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// @Summary Approve leave request
+// @Description อนุมัติคำขอลา: ถ้ามีลำดับการอนุมัติ ผู้อนุมัติแต่ละขั้นเรียกทีละขั้น (ผู้ยื่นอนุมัติเองไม่ได้) ถ้าไม่มี admin/hr อนุมัติได้ทันที เมื่ออนุมัติครบจะตรวจยอดคงเหลืออีกครั้งแล้วสร้าง worklog_ft สถานะ pending ทุกวันของคำขอ ให้งวดเงินเดือนถัดไปหักและปิดรายการ
+// @Tags Leave
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "leave request id"
+// @Param request body Command false "payload"
+// @Success 200 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /leave-requests/{id}/approve [post]
+func NewEndpoint(router fiber.Router) {
+	router.Post("/:id/approve", func(c fiber.Ctx) error {
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return errs.BadRequest("invalid id")
+		}
+		var req Command
+		if len(c.Body()) > 0 {
+			if err := c.Bind().Body(&req); err != nil {
+				return errs.BadRequest("invalid request body")
+			}
+		}
+		req.ID = id
+
+		resp, err := mediator.Send[*Command, *Response](c.Context(), &req)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
@@ -0,0 +1,114 @@
+package cancel
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/leave/internal/dto"
+	"hrms/modules/leave/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/storage/sqldb/transactor"
+	"hrms/shared/contracts"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	ID uuid.UUID `validate:"required"`
+}
+
+type Response struct {
+	dto.Request
+	Message string `json:"message"`
+}
+
+type Handler struct {
+	repo repository.Repository
+	tx   transactor.Transactor
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, tx transactor.Transactor, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, tx: tx, eb: eb}
+}
+
+// Handle lets whoever filed a pending leave request (or an admin) cancel it and withdraws it
+// from the approvers' inbox.
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+
+	req, err := h.repo.GetRequest(ctx, tenant, cmd.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("leave request not found")
+		}
+		logger.FromContext(ctx).Error("failed to load leave request", zap.Error(err))
+		return nil, errs.Internal("failed to load leave request")
+	}
+	if req.Status != repository.RequestPending {
+		return nil, errs.BadRequest("only pending leave request can be cancelled")
+	}
+	if req.CreatedBy != user.ID && user.Role != "admin" {
+		return nil, errs.Forbidden("only the requester or an admin can cancel this leave request")
+	}
+
+	err = h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
+		if _, err := mediator.Send[*contracts.CancelApprovalCommand, *contracts.CancelApprovalResponse](ctxTx, &contracts.CancelApprovalCommand{
+			CompanyID: req.CompanyID,
+			DocType:   contracts.ApprovalDocLeaveRequest,
+			DocID:     req.ID,
+			ActorID:   user.ID,
+			ActorRole: user.Role,
+		}); err != nil {
+			return err
+		}
+		return h.repo.DecideRequest(ctxTx, req.ID, repository.RequestCancelled, nil, user.ID)
+	})
+	if err != nil {
+		var appErr *errs.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.Conflict("leave request changed, reload and try again")
+		}
+		logger.FromContext(ctx).Error("failed to cancel leave request", zap.Error(err))
+		return nil, errs.Internal("failed to cancel leave request")
+	}
+
+	updated, err := h.repo.GetRequest(ctx, tenant, req.ID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load leave request", zap.Error(err))
+		return nil, errs.Internal("failed to load leave request")
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "CANCEL",
+		EntityName: "LEAVE_REQUEST",
+		EntityID:   updated.ID.String(),
+		Details:    map[string]interface{}{},
+		Timestamp:  time.Now(),
+	})
+
+	return &Response{Request: dto.FromRequest(*updated), Message: "Leave request cancelled."}, nil
+}
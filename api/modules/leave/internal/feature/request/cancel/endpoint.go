@@ -0,0 +1,39 @@
+package cancel
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// @Summary Cancel leave request
+// @Description ยกเลิกคำขอลาที่ยังรออนุมัติ (ผู้ยื่นหรือ admin) และถอนออกจาก inbox ผู้อนุมัติ
+// @Tags Leave
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "leave request id"
+// @Success 200 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /leave-requests/{id}/cancel [post]
+func NewEndpoint(router fiber.Router) {
+	router.Post("/:id/cancel", func(c fiber.Ctx) error {
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return errs.BadRequest("invalid id")
+		}
+		resp, err := mediator.Send[*Command, *Response](c.Context(), &Command{ID: id})
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
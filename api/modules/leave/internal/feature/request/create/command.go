@@ -0,0 +1,278 @@
+package create
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/leave/internal/dto"
+	"hrms/modules/leave/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/storage/sqldb/transactor"
+	"hrms/shared/common/validator"
+	"hrms/shared/contracts"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	EmployeeID  uuid.UUID `json:"employeeId" validate:"required"`
+	LeaveTypeID uuid.UUID `json:"leaveTypeId" validate:"required"`
+	StartDate   string    `json:"startDate" validate:"required"`
+	EndDate     string    `json:"endDate"`
+	Hours       *float64  `json:"hours" validate:"omitempty,gt=0,lte=24"`
+	Reason      string    `json:"reason" validate:"max=1000"`
+}
+
+type Response struct {
+	dto.Request
+	Approval *contracts.ApprovalRequestDTO `json:"approval,omitempty"`
+	Message  string                        `json:"message"`
+}
+
+type Handler struct {
+	repo repository.Repository
+	tx   transactor.Transactor
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, tx transactor.Transactor, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, tx: tx, eb: eb}
+}
+
+// Handle files a leave request for the working days of the range and, when the company has an
+// approval chain for leave, submits it so the first step's approvers see it in their inbox.
+// Without a chain the request waits for admin or HR to approve it directly. Either way the
+// approvers are notified through a LeaveRequestEvent.
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	cmd.Reason = strings.TrimSpace(cmd.Reason)
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+
+	start, err := time.Parse("2006-01-02", strings.TrimSpace(cmd.StartDate))
+	if err != nil {
+		return nil, errs.BadRequest("startDate must be YYYY-MM-DD")
+	}
+	end := start
+	if s := strings.TrimSpace(cmd.EndDate); s != "" {
+		if end, err = time.Parse("2006-01-02", s); err != nil {
+			return nil, errs.BadRequest("endDate must be YYYY-MM-DD")
+		}
+	}
+	if end.Before(start) {
+		return nil, errs.BadRequest("endDate must not be before startDate")
+	}
+	if end.Year() != start.Year() {
+		return nil, errs.BadRequest("a leave request cannot cross the year end; split it into two requests")
+	}
+	if cmd.Hours != nil && !end.Equal(start) {
+		return nil, errs.BadRequest("hours is only allowed on a single-day request")
+	}
+
+	emp, err := h.repo.GetRequestEmployee(ctx, tenant, cmd.EmployeeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.BadRequest("employee not found in this company")
+		}
+		logger.FromContext(ctx).Error("failed to load employee", zap.Error(err))
+		return nil, errs.Internal("failed to create leave request")
+	}
+	if !emp.FullTime {
+		return nil, errs.BadRequest("leave requests are for full-time employees")
+	}
+
+	lt, err := h.repo.GetLeaveType(ctx, tenant.CompanyID, cmd.LeaveTypeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.BadRequest("leave type not found")
+		}
+		logger.FromContext(ctx).Error("failed to load leave type", zap.Error(err))
+		return nil, errs.Internal("failed to create leave request")
+	}
+	entryType, quantity, err := entryFor(*lt, cmd.Hours)
+	if err != nil {
+		return nil, err
+	}
+
+	dates, err := workingDays(ctx, tenant.CompanyID, emp.BranchID, cmd.EmployeeID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if len(dates) == 0 {
+		return nil, errs.BadRequest("the requested dates are all rest days or holidays")
+	}
+
+	workDates := make([]string, 0, len(dates))
+	for _, d := range dates {
+		workDates = append(workDates, d.Format("2006-01-02"))
+	}
+	req := repository.Request{
+		CompanyID:   tenant.CompanyID,
+		BranchID:    emp.BranchID,
+		EmployeeID:  cmd.EmployeeID,
+		LeaveTypeID: lt.ID,
+		EntryType:   entryType,
+		StartDate:   start,
+		EndDate:     end,
+		Hours:       cmd.Hours,
+		WorkDates:   workDates,
+	}
+	if cmd.Reason != "" {
+		req.Reason = &cmd.Reason
+	}
+
+	var (
+		created   *repository.Request
+		submitted *contracts.SubmitApprovalResponse
+	)
+	err = h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
+		overlap, err := h.repo.OverlappingRequest(ctxTx, cmd.EmployeeID, start, end)
+		if err != nil {
+			return err
+		}
+		if overlap != nil {
+			return errs.Conflict(fmt.Sprintf("employee already has a leave request from %s overlapping these dates", overlap.Format("2006-01-02")))
+		}
+
+		bal, err := h.repo.GetRequestBalance(ctxTx, tenant.CompanyID, cmd.EmployeeID, lt.ID, entryType, start, quantity*float64(len(dates)), nil)
+		if err != nil {
+			return err
+		}
+		if _, err := dto.CheckEntry(*lt, entryType, *bal, start.Year()); err != nil {
+			return err
+		}
+		req.Days = bal.EntryDays
+
+		id, err := h.repo.CreateRequest(ctxTx, req, user.ID)
+		if err != nil {
+			return err
+		}
+		submitted, err = mediator.Send[*contracts.SubmitApprovalCommand, *contracts.SubmitApprovalResponse](ctxTx, &contracts.SubmitApprovalCommand{
+			CompanyID: tenant.CompanyID,
+			BranchID:  &emp.BranchID,
+			DocType:   contracts.ApprovalDocLeaveRequest,
+			DocID:     id,
+			ActorID:   user.ID,
+			Comment:   cmd.Reason,
+		})
+		if err != nil {
+			return err
+		}
+		created, err = h.repo.GetRequest(ctxTx, tenant, id)
+		return err
+	})
+	if err != nil {
+		var appErr *errs.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		logger.FromContext(ctx).Error("failed to create leave request", zap.Error(err))
+		return nil, errs.Internal("failed to create leave request")
+	}
+
+	details := map[string]interface{}{
+		"employee_id":   created.EmployeeID.String(),
+		"leave_type_id": created.LeaveTypeID.String(),
+		"start_date":    created.StartDate.Format("2006-01-02"),
+		"end_date":      created.EndDate.Format("2006-01-02"),
+		"days":          created.Days,
+	}
+	resp := &Response{Request: dto.FromRequest(*created), Approval: submitted.Request}
+	if submitted.Required {
+		details["approval_request_id"] = submitted.Request.ID.String()
+		resp.Message = "Leave request submitted for approval."
+	} else {
+		resp.Message = "Leave request created and waiting for admin or HR approval."
+	}
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "SUBMIT",
+		EntityName: "LEAVE_REQUEST",
+		EntityID:   created.ID.String(),
+		Details:    details,
+		Timestamp:  time.Now(),
+	})
+	h.eb.Publish(dto.RequestEvent("SUBMIT", *created, submitted.Request, user.ID, cmd.Reason))
+
+	return resp, nil
+}
+
+// entryFor picks the worklog entry type that records the leave type and the quantity of each
+// entry: a day, or the hours of a partial-day unpaid leave.
+func entryFor(lt repository.LeaveType, hours *float64) (string, float64, error) {
+	switch lt.PayType {
+	case contracts.LeavePayUnpaid:
+		if hours != nil {
+			return "leave_hours", *hours, nil
+		}
+		return "leave_day", 1, nil
+	case contracts.LeavePayPaid, contracts.LeavePayDeducted:
+		if hours != nil {
+			return "", 0, errs.BadRequest(fmt.Sprintf("leave type %s is taken in whole days", lt.Code))
+		}
+		return dto.EntryTypesFor(lt.PayType)[0], 1, nil
+	}
+	return "", 0, errs.BadRequest("invalid leave type")
+}
+
+// workingDays returns the days of [start, end] the employee is due at work: the work days of
+// the shift assigned on each day (Monday to Friday without one) that are not holidays of the
+// branch.
+func workingDays(ctx context.Context, companyID, branchID, employeeID uuid.UUID, start, end time.Time) ([]time.Time, error) {
+	holidays, err := mediator.Send[*contracts.ListHolidaysQuery, *contracts.ListHolidaysResponse](ctx, &contracts.ListHolidaysQuery{
+		CompanyID: companyID,
+		BranchID:  &branchID,
+		From:      start,
+		To:        end,
+	})
+	if err != nil {
+		return nil, err
+	}
+	shifts, err := mediator.Send[*contracts.ResolveShiftsQuery, *contracts.ResolveShiftsResponse](ctx, &contracts.ResolveShiftsQuery{
+		CompanyID:   companyID,
+		EmployeeIDs: []uuid.UUID{employeeID},
+		From:        start,
+		To:          end,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var out []time.Time
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		if s := shifts.For(employeeID, d); s != nil {
+			if !s.WorksOn(d) {
+				continue
+			}
+		} else if wd := d.Weekday(); wd == time.Saturday || wd == time.Sunday {
+			continue
+		}
+		if holidays.Contains(d) {
+			continue
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
@@ -0,0 +1,54 @@
+package create
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/shared/common/mediator"
+	"hrms/shared/contracts"
+)
+
+type stubHolidays struct {
+	contracts.ListHolidaysResponse
+}
+
+func (s stubHolidays) Handle(context.Context, *contracts.ListHolidaysQuery) (*contracts.ListHolidaysResponse, error) {
+	return &s.ListHolidaysResponse, nil
+}
+
+type stubShifts struct {
+	contracts.ResolveShiftsResponse
+}
+
+func (s stubShifts) Handle(context.Context, *contracts.ResolveShiftsQuery) (*contracts.ResolveShiftsResponse, error) {
+	return &s.ResolveShiftsResponse, nil
+}
+
+func TestWorkingDaysFollowShiftAndHolidays(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) } // 2 March 2026 is a Monday
+	employeeID := uuid.New()
+	mediator.Register[*contracts.ListHolidaysQuery, *contracts.ListHolidaysResponse](stubHolidays{contracts.ListHolidaysResponse{
+		Holidays: []contracts.HolidayDTO{{Date: day(11), Name: "Company day", Kind: "company"}},
+	}})
+	// Tuesday to Saturday from the second week; another employee's shift must not count
+	mediator.Register[*contracts.ResolveShiftsQuery, *contracts.ResolveShiftsResponse](stubShifts{contracts.ResolveShiftsResponse{
+		Assignments: []contracts.ShiftAssignmentDTO{
+			{EmployeeID: employeeID, StartDate: day(9), Shift: contracts.ShiftDTO{Code: "TUE-SAT", WorkDays: []int{2, 3, 4, 5, 6}}},
+			{EmployeeID: uuid.New(), StartDate: day(1), Shift: contracts.ShiftDTO{Code: "SUN", WorkDays: []int{7}}},
+		},
+	}})
+
+	got, err := workingDays(context.Background(), uuid.New(), uuid.New(), employeeID, day(2), day(15))
+	if err != nil {
+		t.Fatalf("workingDays() error = %v", err)
+	}
+	// Monday to Friday without a shift, then the shift's days less the holiday on the 11th
+	want := []time.Time{day(2), day(3), day(4), day(5), day(6), day(10), day(12), day(13), day(14)}
+	if !slices.EqualFunc(got, want, time.Time.Equal) {
+		t.Errorf("workingDays() = %v, want %v", got, want)
+	}
+}
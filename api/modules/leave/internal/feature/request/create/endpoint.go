@@ -0,0 +1,39 @@
+package create
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// Create leave request
+// @Summary Create leave request
+// @Description ยื่นคำขอลาแทนพนักงาน (หัวหน้างาน/timekeeper/HR) นับเฉพาะวันทำงานตามกะของพนักงาน (ไม่มีกะใช้จันทร์-ศุกร์) ที่ไม่ใช่วันหยุดของสาขา ตรวจยอดสิทธิ์ลาคงเหลือ ถ้ามีลำดับการอนุมัติ leave_request จะส่งเข้า inbox ผู้อนุมัติขั้นแรกทันที ถ้าไม่มี admin/hr อนุมัติได้โดยตรง ระบุ hours ได้เฉพาะลาไม่รับค่าจ้างวันเดียว
+// @Tags Leave
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body Command true "leave request payload"
+// @Success 201 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 409
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /leave-requests [post]
+func NewEndpoint(router fiber.Router) {
+	router.Post("/", func(c fiber.Ctx) error {
+		var cmd Command
+		if err := c.Bind().Body(&cmd); err != nil {
+			return errs.BadRequest("invalid request body")
+		}
+		resp, err := mediator.Send[*Command, *Response](c.Context(), &cmd)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusCreated, resp)
+	})
+}
@@ -0,0 +1,71 @@
+package list
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// List leave requests
+// @Summary List leave requests
+// @Description รายการคำขอลาที่คาบเกี่ยวช่วงวันที่ เรียงจากวันเริ่มลาล่าสุด
+// @Tags Leave
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "pending|approved|rejected|cancelled"
+// @Param employeeId query string false "employee id"
+// @Param leaveTypeId query string false "leave type id"
+// @Param from query string false "YYYY-MM-DD"
+// @Param to query string false "YYYY-MM-DD"
+// @Success 200 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /leave-requests [get]
+func NewEndpoint(router fiber.Router) {
+	router.Get("/", func(c fiber.Ctx) error {
+		q := Query{Status: strings.TrimSpace(c.Query("status"))}
+		if s := strings.TrimSpace(c.Query("employeeId")); s != "" {
+			id, err := uuid.Parse(s)
+			if err != nil {
+				return errs.BadRequest("invalid employeeId")
+			}
+			q.EmployeeID = &id
+		}
+		if s := strings.TrimSpace(c.Query("leaveTypeId")); s != "" {
+			id, err := uuid.Parse(s)
+			if err != nil {
+				return errs.BadRequest("invalid leaveTypeId")
+			}
+			q.LeaveTypeID = &id
+		}
+		if s := strings.TrimSpace(c.Query("from")); s != "" {
+			d, err := time.Parse("2006-01-02", s)
+			if err != nil {
+				return errs.BadRequest("invalid from")
+			}
+			q.From = &d
+		}
+		if s := strings.TrimSpace(c.Query("to")); s != "" {
+			d, err := time.Parse("2006-01-02", s)
+			if err != nil {
+				return errs.BadRequest("invalid to")
+			}
+			q.To = &d
+		}
+
+		resp, err := mediator.Send[*Query, *Response](c.Context(), &q)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
@@ -0,0 +1,62 @@
+package list
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/leave/internal/dto"
+	"hrms/modules/leave/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/validator"
+)
+
+type Query struct {
+	Status      string `validate:"omitempty,oneof=pending approved rejected cancelled"`
+	EmployeeID  *uuid.UUID
+	LeaveTypeID *uuid.UUID
+	From        *time.Time
+	To          *time.Time
+}
+
+type Response struct {
+	Data []dto.Request `json:"data"`
+}
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*Query, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) Handle(ctx context.Context, q *Query) (*Response, error) {
+	if err := validator.Validate(q); err != nil {
+		return nil, err
+	}
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+
+	reqs, err := h.repo.ListRequests(ctx, tenant, repository.RequestFilter{
+		Status:      q.Status,
+		EmployeeID:  q.EmployeeID,
+		LeaveTypeID: q.LeaveTypeID,
+		From:        q.From,
+		To:          q.To,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list leave requests", zap.Error(err))
+		return nil, errs.Internal("failed to list leave requests")
+	}
+	return &Response{Data: dto.FromRequests(reqs)}, nil
+}
@@ -0,0 +1,139 @@
+package reject
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/leave/internal/dto"
+	"hrms/modules/leave/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/storage/sqldb/transactor"
+	"hrms/shared/common/validator"
+	"hrms/shared/contracts"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	ID      uuid.UUID `json:"-" validate:"required"`
+	Comment string    `json:"comment" validate:"required,max=1000"`
+}
+
+type Response struct {
+	dto.Request
+	Approval *contracts.ApprovalRequestDTO `json:"approval,omitempty"`
+	Message  string                        `json:"message"`
+}
+
+type Handler struct {
+	repo repository.Repository
+	tx   transactor.Transactor
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, tx transactor.Transactor, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, tx: tx, eb: eb}
+}
+
+// Handle rejects a pending leave request: the current approver of its chain, or admin or HR
+// when leave requests have no chain. The days go back to the balance.
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	cmd.Comment = strings.TrimSpace(cmd.Comment)
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+
+	req, err := h.repo.GetRequest(ctx, tenant, cmd.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("leave request not found")
+		}
+		logger.FromContext(ctx).Error("failed to load leave request", zap.Error(err))
+		return nil, errs.Internal("failed to load leave request")
+	}
+	if req.Status != repository.RequestPending {
+		return nil, errs.BadRequest(req.Status + " leave request cannot be rejected")
+	}
+
+	var decision *contracts.DecideApprovalResponse
+	err = h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
+		var err error
+		decision, err = mediator.Send[*contracts.DecideApprovalCommand, *contracts.DecideApprovalResponse](ctxTx, &contracts.DecideApprovalCommand{
+			CompanyID:  req.CompanyID,
+			BranchID:   &req.BranchID,
+			DocType:    contracts.ApprovalDocLeaveRequest,
+			DocID:      req.ID,
+			PreparedBy: req.CreatedBy,
+			ActorID:    user.ID,
+			ActorRole:  user.Role,
+			Approve:    false,
+			Comment:    cmd.Comment,
+		})
+		if err != nil {
+			return err
+		}
+		if !decision.Required && user.Role != "admin" && user.Role != "hr" {
+			return errs.Forbidden("only admin or hr can reject leave requests")
+		}
+		return h.repo.DecideRequest(ctxTx, req.ID, repository.RequestRejected, &cmd.Comment, user.ID)
+	})
+	if err != nil {
+		var appErr *errs.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.Conflict("leave request changed, reload and try again")
+		}
+		logger.FromContext(ctx).Error("failed to reject leave request", zap.Error(err))
+		return nil, errs.Internal("failed to reject leave request")
+	}
+
+	updated, err := h.repo.GetRequest(ctx, tenant, req.ID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load leave request", zap.Error(err))
+		return nil, errs.Internal("failed to load leave request")
+	}
+
+	details := map[string]interface{}{"comment": cmd.Comment}
+	if decision.Request != nil {
+		details["approval_request_id"] = decision.Request.ID.String()
+	}
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "REJECT",
+		EntityName: "LEAVE_REQUEST",
+		EntityID:   updated.ID.String(),
+		Details:    details,
+		Timestamp:  time.Now(),
+	})
+	h.eb.Publish(dto.RequestEvent("REJECT", *updated, decision.Request, user.ID, cmd.Comment))
+
+	return &Response{
+		Request:  dto.FromRequest(*updated),
+		Approval: decision.Request,
+		Message:  "Leave request rejected.",
+	}, nil
+}
@@ -0,0 +1,46 @@
+package reject
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// @Summary Reject leave request
+// @Description ไม่อนุมัติคำขอลา (ต้องระบุ comment): ผู้อนุมัติขั้นปัจจุบัน หรือ admin/hr เมื่อไม่มีลำดับการอนุมัติ วันลาที่รออนุมัติคืนกลับเข้ายอดคงเหลือ
+// @Tags Leave
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "leave request id"
+// @Param request body Command true "payload"
+// @Success 200 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /leave-requests/{id}/reject [post]
+func NewEndpoint(router fiber.Router) {
+	router.Post("/:id/reject", func(c fiber.Ctx) error {
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return errs.BadRequest("invalid id")
+		}
+		var req Command
+		if err := c.Bind().Body(&req); err != nil {
+			return errs.BadRequest("invalid request body")
+		}
+		req.ID = id
+
+		resp, err := mediator.Send[*Command, *Response](c.Context(), &req)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
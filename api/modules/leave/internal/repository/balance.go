@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/shared/common/contextx"
+)
+
+// Balance is an employee's leave for one type and year. Used and pending days come from the
+// worklog entries recorded against the type, pending also from leave requests awaiting approval.
+// ID is nil when the year has no balance row: the type is unlimited or the year has not been
+// opened yet.
+type Balance struct {
+	ID             *uuid.UUID `db:"id"`
+	EmployeeID     uuid.UUID  `db:"employee_id"`
+	EmployeeNumber string     `db:"employee_number"`
+	FirstName      string     `db:"first_name"`
+	LastName       string     `db:"last_name"`
+	LeaveTypeID    uuid.UUID  `db:"leave_type_id"`
+	LeaveTypeCode  string     `db:"leave_type_code"`
+	LeaveTypeName  string     `db:"leave_type_name"`
+	PayType        string     `db:"pay_type"`
+	BalanceYear    int        `db:"balance_year"`
+	EntitledDays   float64    `db:"entitled_days"`
+	CarriedDays    float64    `db:"carried_days"`
+	AdjustmentDays float64    `db:"adjustment_days"`
+	UsedDays       float64    `db:"used_days"`
+	PendingDays    float64    `db:"pending_days"`
+	Note           *string    `db:"note"`
+	UpdatedAt      *time.Time `db:"updated_at"`
+}
+
+// Total is the days the employee may take in the year.
+func (b Balance) Total() float64 {
+	return b.EntitledDays + b.CarriedDays + b.AdjustmentDays
+}
+
+// Remaining is the total less the approved leave; pending leave is not taken off yet.
+func (b Balance) Remaining() float64 {
+	return b.Total() - b.UsedDays
+}
+
+const balanceSelect = `
+SELECT b.id, e.id AS employee_id, e.employee_number, e.first_name, e.last_name,
+       lt.id AS leave_type_id, lt.code AS leave_type_code, lt.name AS leave_type_name, lt.pay_type,
+       %[1]s AS balance_year,
+       COALESCE(b.entitled_days, 0) AS entitled_days,
+       COALESCE(b.carried_days, 0) AS carried_days,
+       COALESCE(b.adjustment_days, 0) AS adjustment_days,
+       u.used_days, u.pending_days, b.note, b.updated_at`
+
+// OpenYear sets every full-time employee's entitlement for the year on each active type that
+// has entitlement rules: the rule for the employee's tenure at year end, plus what is left of
+// last year's balance up to the type's carry-over limit. Running it again recomputes both and
+// keeps HR adjustments. Returns the number of balances written.
+func (r Repository) OpenYear(ctx context.Context, tenant contextx.TenantInfo, year int, employeeID *uuid.UUID, actor uuid.UUID) (int, error) {
+	db := r.dbCtx(ctx)
+	args := []interface{}{tenant.CompanyID, year, actor}
+	where := []string{
+		"e.company_id = $1",
+		"e.deleted_at IS NULL",
+		"e.employment_start_date <= make_date($2, 12, 31)",
+		"(e.employment_end_date IS NULL OR e.employment_end_date >= make_date($2, 1, 1))",
+	}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where = append(where, fmt.Sprintf("e.branch_id = $%d", len(args)))
+	}
+	if employeeID != nil {
+		args = append(args, *employeeID)
+		where = append(where, fmt.Sprintf("e.id = $%d", len(args)))
+	}
+	q := fmt.Sprintf(`
+INSERT INTO leave_balance (company_id, employee_id, leave_type_id, balance_year, entitled_days, carried_days, created_by, updated_by)
+SELECT e.company_id, e.id, lt.id, $2,
+       COALESCE(rule.days_per_year, 0),
+       CASE WHEN prev.id IS NULL OR lt.carry_over_max_days = 0 THEN 0
+            ELSE LEAST(lt.carry_over_max_days,
+                       GREATEST(prev.entitled_days + prev.carried_days + prev.adjustment_days - prev_use.used_days, 0))
+       END,
+       $3, $3
+FROM employees e
+JOIN employee_type et ON et.id = e.employee_type_id AND et.code = 'full_time'
+JOIN leave_type lt ON lt.company_id = e.company_id AND lt.is_active AND lt.deleted_at IS NULL
+LEFT JOIN LATERAL (
+  SELECT r.days_per_year
+  FROM leave_entitlement_rule r
+  WHERE r.leave_type_id = lt.id
+    AND r.min_tenure_months <= tenure_months(e.employment_start_date, make_date($2, 12, 31))
+  ORDER BY r.min_tenure_months DESC
+  LIMIT 1
+) rule ON TRUE
+LEFT JOIN leave_balance prev ON prev.employee_id = e.id AND prev.leave_type_id = lt.id AND prev.balance_year = $2 - 1
+LEFT JOIN LATERAL leave_usage(e.id, lt.id, $2 - 1) prev_use ON TRUE
+WHERE %s
+  AND EXISTS (SELECT 1 FROM leave_entitlement_rule x WHERE x.leave_type_id = lt.id)
+ON CONFLICT (employee_id, leave_type_id, balance_year) DO UPDATE
+SET entitled_days = EXCLUDED.entitled_days,
+    carried_days = EXCLUDED.carried_days,
+    updated_by = EXCLUDED.updated_by`, strings.Join(where, " AND "))
+	res, err := db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// BalanceFilter narrows the balance report; nil fields mean all.
+type BalanceFilter struct {
+	Year        int
+	EmployeeID  *uuid.UUID
+	LeaveTypeID *uuid.UUID
+}
+
+// ListBalances returns the opened balances of the year.
+func (r Repository) ListBalances(ctx context.Context, tenant contextx.TenantInfo, f BalanceFilter) ([]Balance, error) {
+	db := r.dbCtx(ctx)
+	args := []interface{}{tenant.CompanyID, f.Year}
+	where := []string{"b.company_id = $1", "b.balance_year = $2", "e.deleted_at IS NULL"}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where = append(where, fmt.Sprintf("e.branch_id = $%d", len(args)))
+	}
+	if f.EmployeeID != nil {
+		args = append(args, *f.EmployeeID)
+		where = append(where, fmt.Sprintf("b.employee_id = $%d", len(args)))
+	}
+	if f.LeaveTypeID != nil {
+		args = append(args, *f.LeaveTypeID)
+		where = append(where, fmt.Sprintf("b.leave_type_id = $%d", len(args)))
+	}
+	q := fmt.Sprintf(balanceSelect, "b.balance_year") + fmt.Sprintf(`
+FROM leave_balance b
+JOIN employees e ON e.id = b.employee_id
+JOIN leave_type lt ON lt.id = b.leave_type_id AND lt.deleted_at IS NULL
+CROSS JOIN LATERAL leave_usage(b.employee_id, b.leave_type_id, b.balance_year) u
+WHERE %s
+ORDER BY e.employee_number, lt.code`, strings.Join(where, " AND "))
+	var out []Balance
+	if err := db.SelectContext(ctx, &out, q, args...); err != nil {
+		return nil, err
+	}
+	if out == nil {
+		out = []Balance{}
+	}
+	return out, nil
+}
+
+// EmployeeBalances returns one row per leave type for the employee in the year, including
+// unlimited types and types taken without an opened balance.
+func (r Repository) EmployeeBalances(ctx context.Context, tenant contextx.TenantInfo, employeeID uuid.UUID, year int) ([]Balance, error) {
+	db := r.dbCtx(ctx)
+	args := []interface{}{employeeID, tenant.CompanyID, year}
+	empWhere := "e.id = $1 AND e.company_id = $2"
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		empWhere += " AND e.branch_id = $4"
+	}
+	q := fmt.Sprintf(balanceSelect, "$3::int") + fmt.Sprintf(`
+FROM employees e
+JOIN leave_type lt ON lt.company_id = e.company_id AND lt.deleted_at IS NULL
+LEFT JOIN leave_balance b ON b.employee_id = e.id AND b.leave_type_id = lt.id AND b.balance_year = $3
+CROSS JOIN LATERAL leave_usage(e.id, lt.id, $3) u
+WHERE %s
+  AND (lt.is_active OR b.id IS NOT NULL OR u.used_days > 0 OR u.pending_days > 0)
+ORDER BY lt.code`, empWhere)
+	var out []Balance
+	if err := db.SelectContext(ctx, &out, q, args...); err != nil {
+		return nil, err
+	}
+	if out == nil {
+		out = []Balance{}
+	}
+	return out, nil
+}
+
+// EmployeeExists reports whether the employee belongs to the tenant.
+func (r Repository) EmployeeExists(ctx context.Context, tenant contextx.TenantInfo, employeeID uuid.UUID) (bool, error) {
+	db := r.dbCtx(ctx)
+	q := `SELECT EXISTS (SELECT 1 FROM employees WHERE id = $1 AND company_id = $2 AND deleted_at IS NULL`
+	args := []interface{}{employeeID, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		q += " AND branch_id = $3"
+		args = append(args, tenant.BranchID)
+	}
+	q += ")"
+	var ok bool
+	if err := db.GetContext(ctx, &ok, q, args...); err != nil {
+		return false, err
+	}
+	return ok, nil
+}
+
+// GetBalance returns one opened balance, or sql.ErrNoRows.
+func (r Repository) GetBalance(ctx context.Context, tenant contextx.TenantInfo, id uuid.UUID) (*Balance, error) {
+	db := r.dbCtx(ctx)
+	args := []interface{}{id, tenant.CompanyID}
+	where := "b.id = $1 AND b.company_id = $2"
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where += " AND e.branch_id = $3"
+	}
+	q := fmt.Sprintf(balanceSelect, "b.balance_year") + fmt.Sprintf(`
+FROM leave_balance b
+JOIN employees e ON e.id = b.employee_id
+JOIN leave_type lt ON lt.id = b.leave_type_id
+CROSS JOIN LATERAL leave_usage(b.employee_id, b.leave_type_id, b.balance_year) u
+WHERE %s`, where)
+	var out Balance
+	if err := db.GetContext(ctx, &out, q, args...); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AdjustBalance sets HR's adjustment on an opened balance. Returns sql.ErrNoRows when not found.
+func (r Repository) AdjustBalance(ctx context.Context, tenant contextx.TenantInfo, id uuid.UUID, adjustment float64, note *string, actor uuid.UUID) error {
+	db := r.dbCtx(ctx)
+	q := `
+UPDATE leave_balance b
+SET adjustment_days = $1, note = $2, updated_by = $3
+FROM employees e
+WHERE b.id = $4 AND b.company_id = $5 AND e.id = b.employee_id`
+	args := []interface{}{adjustment, note, actor, id, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		q += " AND e.branch_id = $6"
+		args = append(args, tenant.BranchID)
+	}
+	var out uuid.UUID
+	return db.GetContext(ctx, &out, q+" RETURNING b.id", args...)
+}
+
+// EntryBalance is the balance an entry is checked against.
+type EntryBalance struct {
+	HasBalance  bool    `db:"has_balance"`
+	TotalDays   float64 `db:"total_days"`
+	UsedDays    float64 `db:"used_days"`
+	PendingDays float64 `db:"pending_days"`
+	EntryDays   float64 `db:"entry_days"`
+}
+
+// GetEntryBalance returns the employee's balance for the type and year of workDate, the leave
+// already recorded (other than excludeID) and the entry's quantity converted to days.
+func (r Repository) GetEntryBalance(ctx context.Context, companyID, employeeID, leaveTypeID uuid.UUID, entryType string, workDate time.Time, quantity float64, excludeID *uuid.UUID) (*EntryBalance, error) {
+	return r.entryBalance(ctx, companyID, employeeID, leaveTypeID, entryType, workDate, quantity, excludeID, nil)
+}
+
+// GetRequestBalance is GetEntryBalance for a leave request starting on startDate: quantity is
+// the request's total and excludeRequestID leaves out the request itself while it is pending.
+func (r Repository) GetRequestBalance(ctx context.Context, companyID, employeeID, leaveTypeID uuid.UUID, entryType string, startDate time.Time, quantity float64, excludeRequestID *uuid.UUID) (*EntryBalance, error) {
+	return r.entryBalance(ctx, companyID, employeeID, leaveTypeID, entryType, startDate, quantity, nil, excludeRequestID)
+}
+
+func (r Repository) entryBalance(ctx context.Context, companyID, employeeID, leaveTypeID uuid.UUID, entryType string, workDate time.Time, quantity float64, excludeID, excludeRequestID *uuid.UUID) (*EntryBalance, error) {
+	db := r.dbCtx(ctx)
+	q := `
+SELECT b.id IS NOT NULL AS has_balance,
+       COALESCE(b.entitled_days + b.carried_days + b.adjustment_days, 0) AS total_days,
+       u.used_days, u.pending_days,
+       leave_entry_days($4, $5, $1, $6) AS entry_days
+FROM leave_usage($2, $3, EXTRACT(YEAR FROM $6::date)::int, $7, $8) u
+LEFT JOIN leave_balance b
+  ON b.company_id = $1 AND b.employee_id = $2 AND b.leave_type_id = $3
+ AND b.balance_year = EXTRACT(YEAR FROM $6::date)::int`
+	var out EntryBalance
+	if err := db.GetContext(ctx, &out, q, companyID, employeeID, leaveTypeID, entryType, quantity, workDate, excludeID, excludeRequestID); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/storage/sqldb/transactor"
+)
+
+type Repository struct {
+	dbCtx transactor.DBTXContext
+}
+
+func NewRepository(dbCtx transactor.DBTXContext) Repository {
+	return Repository{dbCtx: dbCtx}
+}
+
+type LeaveType struct {
+	ID               uuid.UUID         `db:"id"`
+	CompanyID        uuid.UUID         `db:"company_id"`
+	Code             string            `db:"code"`
+	Name             string            `db:"name"`
+	PayType          string            `db:"pay_type"`
+	EnforceBalance   bool              `db:"enforce_balance"`
+	CarryOverMaxDays float64           `db:"carry_over_max_days"`
+	IsActive         bool              `db:"is_active"`
+	CreatedAt        time.Time         `db:"created_at"`
+	CreatedBy        uuid.UUID         `db:"created_by"`
+	UpdatedAt        time.Time         `db:"updated_at"`
+	UpdatedBy        uuid.UUID         `db:"updated_by"`
+	Rules            []EntitlementRule `db:"-"`
+}
+
+// EntitlementRule grants DaysPerYear once the employee's tenure at year end reaches MinTenureMonths.
+type EntitlementRule struct {
+	LeaveTypeID     uuid.UUID `db:"leave_type_id"`
+	MinTenureMonths int       `db:"min_tenure_months"`
+	DaysPerYear     float64   `db:"days_per_year"`
+}
+
+// HasQuota reports whether the type has a yearly entitlement; without rules leave is unlimited.
+func (t LeaveType) HasQuota() bool {
+	return len(t.Rules) > 0
+}
+
+const leaveTypeColumns = `id, company_id, code, name, pay_type, enforce_balance, carry_over_max_days, is_active,
+       created_at, created_by, updated_at, updated_by`
+
+func (r Repository) ListLeaveTypes(ctx context.Context, tenant contextx.TenantInfo, activeOnly bool) ([]LeaveType, error) {
+	db := r.dbCtx(ctx)
+	where := "company_id = $1 AND deleted_at IS NULL"
+	if activeOnly {
+		where += " AND is_active"
+	}
+	q := fmt.Sprintf(`SELECT %s FROM leave_type WHERE %s ORDER BY code`, leaveTypeColumns, where)
+	var types []LeaveType
+	if err := db.SelectContext(ctx, &types, q, tenant.CompanyID); err != nil {
+		return nil, err
+	}
+	return r.attachRules(ctx, types)
+}
+
+// GetLeaveType returns the company's leave type with its rules, or sql.ErrNoRows.
+func (r Repository) GetLeaveType(ctx context.Context, companyID, id uuid.UUID) (*LeaveType, error) {
+	db := r.dbCtx(ctx)
+	var t LeaveType
+	q := fmt.Sprintf(`SELECT %s FROM leave_type WHERE id = $1 AND company_id = $2 AND deleted_at IS NULL`, leaveTypeColumns)
+	if err := db.GetContext(ctx, &t, q, id, companyID); err != nil {
+		return nil, err
+	}
+	types, err := r.attachRules(ctx, []LeaveType{t})
+	if err != nil {
+		return nil, err
+	}
+	return &types[0], nil
+}
+
+func (r Repository) CreateLeaveType(ctx context.Context, t LeaveType, actor uuid.UUID) (*LeaveType, error) {
+	db := r.dbCtx(ctx)
+	q := fmt.Sprintf(`
+INSERT INTO leave_type (company_id, code, name, pay_type, enforce_balance, carry_over_max_days, is_active, created_by, updated_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+RETURNING %s`, leaveTypeColumns)
+	var created LeaveType
+	if err := db.GetContext(ctx, &created, q, t.CompanyID, t.Code, t.Name, t.PayType, t.EnforceBalance,
+		t.CarryOverMaxDays, t.IsActive, actor); err != nil {
+		return nil, err
+	}
+	if err := r.replaceRules(ctx, created.ID, t.Rules); err != nil {
+		return nil, err
+	}
+	return r.GetLeaveType(ctx, created.CompanyID, created.ID)
+}
+
+// UpdateLeaveType replaces the type's settings and rules. The pay type is fixed once created
+// because worklog entries already recorded against it depend on it.
+func (r Repository) UpdateLeaveType(ctx context.Context, tenant contextx.TenantInfo, t LeaveType, actor uuid.UUID) (*LeaveType, error) {
+	db := r.dbCtx(ctx)
+	q := fmt.Sprintf(`
+UPDATE leave_type
+SET code = $1, name = $2, enforce_balance = $3, carry_over_max_days = $4, is_active = $5, updated_by = $6
+WHERE id = $7 AND company_id = $8 AND deleted_at IS NULL
+RETURNING %s`, leaveTypeColumns)
+	var updated LeaveType
+	if err := db.GetContext(ctx, &updated, q, t.Code, t.Name, t.EnforceBalance, t.CarryOverMaxDays, t.IsActive,
+		actor, t.ID, tenant.CompanyID); err != nil {
+		return nil, err
+	}
+	if err := r.replaceRules(ctx, updated.ID, t.Rules); err != nil {
+		return nil, err
+	}
+	return r.GetLeaveType(ctx, updated.CompanyID, updated.ID)
+}
+
+func (r Repository) SoftDeleteLeaveType(ctx context.Context, tenant contextx.TenantInfo, id, actor uuid.UUID) error {
+	db := r.dbCtx(ctx)
+	res, err := db.ExecContext(ctx, `
+UPDATE leave_type SET deleted_at = now(), deleted_by = $1, is_active = FALSE, updated_by = $1
+WHERE id = $2 AND company_id = $3 AND deleted_at IS NULL`, actor, id, tenant.CompanyID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r Repository) replaceRules(ctx context.Context, leaveTypeID uuid.UUID, rules []EntitlementRule) error {
+	db := r.dbCtx(ctx)
+	if _, err := db.ExecContext(ctx, `DELETE FROM leave_entitlement_rule WHERE leave_type_id = $1`, leaveTypeID); err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if _, err := db.ExecContext(ctx, `
+INSERT INTO leave_entitlement_rule (leave_type_id, min_tenure_months, days_per_year)
+VALUES ($1, $2, $3)`, leaveTypeID, rule.MinTenureMonths, rule.DaysPerYear); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r Repository) attachRules(ctx context.Context, types []LeaveType) ([]LeaveType, error) {
+	if len(types) == 0 {
+		return []LeaveType{}, nil
+	}
+	db := r.dbCtx(ctx)
+	ids := make([]uuid.UUID, len(types))
+	for i := range types {
+		ids[i] = types[i].ID
+	}
+	var rules []EntitlementRule
+	if err := db.SelectContext(ctx, &rules, `
+SELECT leave_type_id, min_tenure_months, days_per_year
+FROM leave_entitlement_rule
+WHERE leave_type_id = ANY($1)
+ORDER BY leave_type_id, min_tenure_months`, pq.Array(ids)); err != nil {
+		return nil, err
+	}
+	byType := make(map[uuid.UUID][]EntitlementRule, len(types))
+	for _, rule := range rules {
+		byType[rule.LeaveTypeID] = append(byType[rule.LeaveTypeID], rule)
+	}
+	for i := range types {
+		types[i].Rules = byType[types[i].ID]
+	}
+	return types, nil
+}
+
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505"
+	}
+	return false
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"hrms/shared/common/contextx"
+)
+
+// Leave request statuses
+const (
+	RequestPending   = "pending"
+	RequestApproved  = "approved"
+	RequestRejected  = "rejected"
+	RequestCancelled = "cancelled"
+)
+
+// Request is a leave request for a range of dates. WorkDates are the days it records (the range
+// without rest days and holidays) and Days what it takes from the balance.
+type Request struct {
+	ID              uuid.UUID      `db:"id"`
+	CompanyID       uuid.UUID      `db:"company_id"`
+	BranchID        uuid.UUID      `db:"branch_id"`
+	EmployeeID      uuid.UUID      `db:"employee_id"`
+	EmployeeNumber  string         `db:"employee_number"`
+	FirstName       string         `db:"first_name"`
+	LastName        string         `db:"last_name"`
+	LeaveTypeID     uuid.UUID      `db:"leave_type_id"`
+	LeaveTypeCode   string         `db:"leave_type_code"`
+	LeaveTypeName   string         `db:"leave_type_name"`
+	EntryType       string         `db:"entry_type"`
+	StartDate       time.Time      `db:"start_date"`
+	EndDate         time.Time      `db:"end_date"`
+	Hours           *float64       `db:"hours"`
+	WorkDates       pq.StringArray `db:"work_dates"`
+	Days            float64        `db:"days"`
+	Reason          *string        `db:"reason"`
+	Status          string         `db:"status"`
+	DecidedAt       *time.Time     `db:"decided_at"`
+	DecidedBy       *uuid.UUID     `db:"decided_by"`
+	DecisionComment *string        `db:"decision_comment"`
+	CreatedAt       time.Time      `db:"created_at"`
+	CreatedBy       uuid.UUID      `db:"created_by"`
+	UpdatedAt       time.Time      `db:"updated_at"`
+}
+
+// Dates parses WorkDates.
+func (r Request) Dates() ([]time.Time, error) {
+	out := make([]time.Time, 0, len(r.WorkDates))
+	for _, s := range r.WorkDates {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+const requestSelect = `
+SELECT lr.id, lr.company_id, lr.branch_id, lr.employee_id, e.employee_number, e.first_name, e.last_name,
+       lr.leave_type_id, lt.code AS leave_type_code, lt.name AS leave_type_name,
+       lr.entry_type, lr.start_date, lr.end_date, lr.hours, lr.work_dates::text[] AS work_dates, lr.days,
+       lr.reason, lr.status, lr.decided_at, lr.decided_by, lr.decision_comment,
+       lr.created_at, lr.created_by, lr.updated_at
+FROM leave_request lr
+JOIN employees e ON e.id = lr.employee_id
+JOIN leave_type lt ON lt.id = lr.leave_type_id`
+
+// RequestFilter narrows the request list; zero values mean all.
+type RequestFilter struct {
+	Status      string
+	EmployeeID  *uuid.UUID
+	LeaveTypeID *uuid.UUID
+	From        *time.Time
+	To          *time.Time
+}
+
+// ListRequests returns the requests overlapping [From, To], latest start first.
+func (r Repository) ListRequests(ctx context.Context, tenant contextx.TenantInfo, f RequestFilter) ([]Request, error) {
+	db := r.dbCtx(ctx)
+	args := []interface{}{tenant.CompanyID}
+	where := []string{"lr.company_id = $1"}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where = append(where, fmt.Sprintf("lr.branch_id = $%d", len(args)))
+	}
+	if f.Status != "" {
+		args = append(args, f.Status)
+		where = append(where, fmt.Sprintf("lr.status = $%d", len(args)))
+	}
+	if f.EmployeeID != nil {
+		args = append(args, *f.EmployeeID)
+		where = append(where, fmt.Sprintf("lr.employee_id = $%d", len(args)))
+	}
+	if f.LeaveTypeID != nil {
+		args = append(args, *f.LeaveTypeID)
+		where = append(where, fmt.Sprintf("lr.leave_type_id = $%d", len(args)))
+	}
+	if f.From != nil {
+		args = append(args, *f.From)
+		where = append(where, fmt.Sprintf("lr.end_date >= $%d", len(args)))
+	}
+	if f.To != nil {
+		args = append(args, *f.To)
+		where = append(where, fmt.Sprintf("lr.start_date <= $%d", len(args)))
+	}
+	q := requestSelect + fmt.Sprintf(`
+WHERE %s
+ORDER BY lr.start_date DESC, lr.created_at DESC`, strings.Join(where, " AND "))
+	var out []Request
+	if err := db.SelectContext(ctx, &out, q, args...); err != nil {
+		return nil, err
+	}
+	if out == nil {
+		out = []Request{}
+	}
+	return out, nil
+}
+
+func (r Repository) GetRequest(ctx context.Context, tenant contextx.TenantInfo, id uuid.UUID) (*Request, error) {
+	db := r.dbCtx(ctx)
+	args := []interface{}{id, tenant.CompanyID}
+	where := "lr.id = $1 AND lr.company_id = $2"
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where += " AND lr.branch_id = $3"
+	}
+	var out Request
+	if err := db.GetContext(ctx, &out, requestSelect+" WHERE "+where, args...); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RequestEmployee is what a leave request needs to know about the employee.
+type RequestEmployee struct {
+	BranchID uuid.UUID `db:"branch_id"`
+	FullTime bool      `db:"full_time"`
+}
+
+// GetRequestEmployee returns the employee in the tenant, or sql.ErrNoRows.
+func (r Repository) GetRequestEmployee(ctx context.Context, tenant contextx.TenantInfo, employeeID uuid.UUID) (*RequestEmployee, error) {
+	db := r.dbCtx(ctx)
+	q := `
+SELECT e.branch_id, COALESCE(et.code = 'full_time', FALSE) AS full_time
+FROM employees e
+LEFT JOIN employee_type et ON et.id = e.employee_type_id
+WHERE e.id = $1 AND e.company_id = $2 AND e.deleted_at IS NULL`
+	args := []interface{}{employeeID, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		q += " AND e.branch_id = $3"
+		args = append(args, tenant.BranchID)
+	}
+	var out RequestEmployee
+	if err := db.GetContext(ctx, &out, q, args...); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// OverlappingRequest returns the start date of another pending or approved request of the
+// employee that shares a day with [from, to], or nil.
+func (r Repository) OverlappingRequest(ctx context.Context, employeeID uuid.UUID, from, to time.Time) (*time.Time, error) {
+	db := r.dbCtx(ctx)
+	q := `
+SELECT start_date FROM leave_request
+WHERE employee_id = $1 AND status IN ('pending','approved')
+  AND start_date <= $3 AND end_date >= $2
+ORDER BY start_date
+LIMIT 1`
+	var out []time.Time
+	if err := db.SelectContext(ctx, &out, q, employeeID, from, to); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return &out[0], nil
+}
+
+func (r Repository) CreateRequest(ctx context.Context, req Request, actor uuid.UUID) (uuid.UUID, error) {
+	db := r.dbCtx(ctx)
+	q := `
+INSERT INTO leave_request (company_id, branch_id, employee_id, leave_type_id, entry_type, start_date, end_date,
+                           hours, work_dates, days, reason, created_by, updated_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9::date[], $10, $11, $12, $12)
+RETURNING id`
+	var id uuid.UUID
+	err := db.GetContext(ctx, &id, q, req.CompanyID, req.BranchID, req.EmployeeID, req.LeaveTypeID, req.EntryType,
+		req.StartDate, req.EndDate, req.Hours, req.WorkDates, req.Days, req.Reason, actor)
+	return id, err
+}
+
+// DecideRequest closes a pending request as approved, rejected or cancelled. It returns
+// sql.ErrNoRows when the request is no longer pending.
+func (r Repository) DecideRequest(ctx context.Context, id uuid.UUID, status string, comment *string, actor uuid.UUID) error {
+	db := r.dbCtx(ctx)
+	q := `
+UPDATE leave_request
+SET status = $1, decision_comment = $2, decided_at = now(), decided_by = $3, updated_by = $3
+WHERE id = $4 AND status = 'pending'
+RETURNING id`
+	var out uuid.UUID
+	return db.GetContext(ctx, &out, q, status, comment, actor, id)
+}
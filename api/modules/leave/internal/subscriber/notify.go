@@ -0,0 +1,94 @@
+package subscriber
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/leave/internal/repository"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/events"
+)
+
+// Notification is a message about a leave request for the company's users holding one of Roles
+// and for the users in UserIDs.
+type Notification struct {
+	CompanyID uuid.UUID
+	BranchID  uuid.UUID
+	RequestID uuid.UUID
+	Roles     []string
+	UserIDs   []uuid.UUID
+	Message   string
+}
+
+// Notifier delivers notifications.
+type Notifier interface {
+	Notify(n Notification) error
+}
+
+// LogNotifier writes notifications to the application log. It is the notifier until a delivery
+// channel such as mail is configured.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(n Notification) error {
+	userIDs := make([]string, 0, len(n.UserIDs))
+	for _, id := range n.UserIDs {
+		userIDs = append(userIDs, id.String())
+	}
+	logger.Log().Info("leave request notification",
+		zap.String("company_id", n.CompanyID.String()),
+		zap.String("branch_id", n.BranchID.String()),
+		zap.String("leave_request_id", n.RequestID.String()),
+		zap.Strings("roles", n.Roles),
+		zap.Strings("user_ids", userIDs),
+		zap.String("message", n.Message))
+	return nil
+}
+
+type NotifySubscriber struct {
+	notifier Notifier
+}
+
+func NewNotifySubscriber(notifier Notifier) *NotifySubscriber {
+	return &NotifySubscriber{notifier: notifier}
+}
+
+// HandleLeaveRequest tells the approvers of a pending request that it waits for them, and the
+// user who filed a request that it was approved or rejected.
+func (s *NotifySubscriber) HandleLeaveRequest(ev eventbus.Event) {
+	e, ok := ev.(events.LeaveRequestEvent)
+	if !ok {
+		return
+	}
+	n, ok := notificationFor(e)
+	if !ok {
+		return
+	}
+	if err := s.notifier.Notify(n); err != nil {
+		logger.Log().Error("failed to send leave request notification", zap.String("leave_request_id", e.RequestID.String()), zap.Error(err))
+	}
+}
+
+func notificationFor(e events.LeaveRequestEvent) (Notification, bool) {
+	n := Notification{CompanyID: e.CompanyID, BranchID: e.BranchID, RequestID: e.RequestID}
+	period := e.StartDate.Format("2006-01-02")
+	if !e.EndDate.Equal(e.StartDate) {
+		period += " to " + e.EndDate.Format("2006-01-02")
+	}
+	if e.Status == repository.RequestPending {
+		n.Roles = e.ApproverRoles
+		if e.ApproverUserID != nil {
+			n.UserIDs = []uuid.UUID{*e.ApproverUserID}
+		}
+		n.Message = fmt.Sprintf("Leave request for %s (%g days) is waiting for your approval.", period, e.Days)
+	} else {
+		n.UserIDs = []uuid.UUID{e.RequestedBy}
+		n.Message = fmt.Sprintf("Your leave request for %s was %s.", period, e.Status)
+		if e.Comment != "" {
+			n.Message += " Comment: " + e.Comment
+		}
+	}
+	return n, len(n.Roles) > 0 || len(n.UserIDs) > 0
+}
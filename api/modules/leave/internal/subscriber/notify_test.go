@@ -0,0 +1,109 @@
+package subscriber
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/leave/internal/dto"
+	"hrms/modules/leave/internal/repository"
+	"hrms/shared/contracts"
+)
+
+type recorder struct{ got []Notification }
+
+func (r *recorder) Notify(n Notification) error {
+	r.got = append(r.got, n)
+	return nil
+}
+
+func TestHandleLeaveRequest(t *testing.T) {
+	preparer, actor, approver := uuid.New(), uuid.New(), uuid.New()
+	hr := "hr"
+	req := repository.Request{
+		ID:        uuid.New(),
+		CompanyID: uuid.New(),
+		BranchID:  uuid.New(),
+		StartDate: time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC),
+		Days:      3,
+		Status:    repository.RequestPending,
+		CreatedBy: preparer,
+	}
+	chain := &contracts.ApprovalRequestDTO{
+		CurrentStep: 2,
+		Steps: []contracts.ApprovalStepDTO{
+			{StepNo: 1, ApproverRole: &hr},
+			{StepNo: 2, ApproverUserID: &approver},
+		},
+	}
+	with := func(status string) repository.Request {
+		r := req
+		r.Status = status
+		return r
+	}
+
+	tests := []struct {
+		name      string
+		action    string
+		req       repository.Request
+		approval  *contracts.ApprovalRequestDTO
+		comment   string
+		wantRoles []string
+		wantUsers []uuid.UUID
+		wantMsg   string
+	}{
+		{
+			name:      "submitted without a chain goes to admin and hr",
+			action:    "SUBMIT",
+			req:       req,
+			wantRoles: []string{"admin", "hr"},
+			wantMsg:   "Leave request for 2026-03-02 to 2026-03-04 (3 days) is waiting for your approval.",
+		},
+		{
+			name:      "step approved goes to the next step's approver",
+			action:    "APPROVE",
+			req:       req,
+			approval:  chain,
+			wantUsers: []uuid.UUID{approver},
+			wantMsg:   "Leave request for 2026-03-02 to 2026-03-04 (3 days) is waiting for your approval.",
+		},
+		{
+			name:      "approved goes to the requester",
+			action:    "APPROVE",
+			req:       with(repository.RequestApproved),
+			approval:  chain,
+			wantUsers: []uuid.UUID{preparer},
+			wantMsg:   "Your leave request for 2026-03-02 to 2026-03-04 was approved.",
+		},
+		{
+			name:      "rejected with a comment",
+			action:    "REJECT",
+			req:       with(repository.RequestRejected),
+			comment:   "busy week",
+			wantUsers: []uuid.UUID{preparer},
+			wantMsg:   "Your leave request for 2026-03-02 to 2026-03-04 was rejected. Comment: busy week",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{}
+			NewNotifySubscriber(r).HandleLeaveRequest(dto.RequestEvent(tt.action, tt.req, tt.approval, actor, tt.comment))
+			if len(r.got) != 1 {
+				t.Fatalf("sent %d notifications, want 1", len(r.got))
+			}
+			n := r.got[0]
+			if n.RequestID != req.ID || n.CompanyID != req.CompanyID {
+				t.Errorf("notification is for request %s of company %s", n.RequestID, n.CompanyID)
+			}
+			if !reflect.DeepEqual(n.Roles, tt.wantRoles) || !reflect.DeepEqual(n.UserIDs, tt.wantUsers) {
+				t.Errorf("recipients = %v %v, want %v %v", n.Roles, n.UserIDs, tt.wantRoles, tt.wantUsers)
+			}
+			if n.Message != tt.wantMsg {
+				t.Errorf("message = %q, want %q", n.Message, tt.wantMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package leave
+
+import (
+	"hrms/modules/leave/internal/feature/balance/adjust"
+	balanceemployee "hrms/modules/leave/internal/feature/balance/employee"
+	balancelist "hrms/modules/leave/internal/feature/balance/list"
+	"hrms/modules/leave/internal/feature/balance/open"
+	"hrms/modules/leave/internal/feature/checkentry"
+	typecreate "hrms/modules/leave/internal/feature/leavetype/create"
+	typedelete "hrms/modules/leave/internal/feature/leavetype/delete"
+	typelist "hrms/modules/leave/internal/feature/leavetype/list"
+	typeupdate "hrms/modules/leave/internal/feature/leavetype/update"
+	requestapprove "hrms/modules/leave/internal/feature/request/approve"
+	requestcancel "hrms/modules/leave/internal/feature/request/cancel"
+	requestcreate "hrms/modules/leave/internal/feature/request/create"
+	requestget "hrms/modules/leave/internal/feature/request/get"
+	requestlist "hrms/modules/leave/internal/feature/request/list"
+	requestreject "hrms/modules/leave/internal/feature/request/reject"
+	"hrms/modules/leave/internal/repository"
+	"hrms/modules/leave/internal/subscriber"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/jwt"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/middleware"
+	"hrms/shared/common/module"
+	"hrms/shared/contracts"
+	"hrms/shared/events"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Module owns leave types, yearly entitlements, balances and leave requests. Leave itself is
+// recorded in worklog_ft: worklog checks entries against the balance through contracts, and an
+// approved request asks worklog to record its days.
+type Module struct {
+	ctx      *module.ModuleContext
+	repo     repository.Repository
+	tokenSvc *jwt.TokenService
+	eb       eventbus.EventBus
+}
+
+func NewModule(ctx *module.ModuleContext, tokenSvc *jwt.TokenService) *Module {
+	return &Module{
+		ctx:      ctx,
+		repo:     repository.NewRepository(ctx.DBCtx),
+		tokenSvc: tokenSvc,
+	}
+}
+
+func (m *Module) APIVersion() string { return "v1" }
+
+func (m *Module) Init(eb eventbus.EventBus) error {
+	m.eb = eb
+	mediator.Register[*typelist.Query, *typelist.Response](typelist.NewHandler(m.repo))
+	mediator.Register[*typecreate.Command, *typecreate.Response](typecreate.NewHandler(m.repo, m.ctx.Transactor, eb))
+	mediator.Register[*typeupdate.Command, *typeupdate.Response](typeupdate.NewHandler(m.repo, m.ctx.Transactor, eb))
+	mediator.Register[*typedelete.Command, mediator.NoResponse](typedelete.NewHandler(m.repo, eb))
+	mediator.Register[*open.Command, *open.Response](open.NewHandler(m.repo, m.ctx.Transactor, eb))
+	mediator.Register[*balancelist.Query, *balancelist.Response](balancelist.NewHandler(m.repo))
+	mediator.Register[*balanceemployee.Query, *balanceemployee.Response](balanceemployee.NewHandler(m.repo))
+	mediator.Register[*adjust.Command, *adjust.Response](adjust.NewHandler(m.repo, eb))
+	mediator.Register[*requestlist.Query, *requestlist.Response](requestlist.NewHandler(m.repo))
+	mediator.Register[*requestget.Query, *requestget.Response](requestget.NewHandler(m.repo))
+	mediator.Register[*requestcreate.Command, *requestcreate.Response](requestcreate.NewHandler(m.repo, m.ctx.Transactor, eb))
+	mediator.Register[*requestapprove.Command, *requestapprove.Response](requestapprove.NewHandler(m.repo, m.ctx.Transactor, eb))
+	mediator.Register[*requestreject.Command, *requestreject.Response](requestreject.NewHandler(m.repo, m.ctx.Transactor, eb))
+	mediator.Register[*requestcancel.Command, *requestcancel.Response](requestcancel.NewHandler(m.repo, m.ctx.Transactor, eb))
+
+	// contract handlers used by worklog
+	mediator.Register[*contracts.CheckLeaveEntryQuery, *contracts.CheckLeaveEntryResponse](checkentry.NewHandler(m.repo))
+
+	// approvers hear of submitted requests and requesters of the decision
+	notify := subscriber.NewNotifySubscriber(subscriber.LogNotifier{})
+	eb.Subscribe(events.LeaveRequestEvent{}.Name(), notify.HandleLeaveRequest)
+	return nil
+}
+
+func (m *Module) RegisterRoutes(r fiber.Router) {
+	// timekeepers pick the leave type and see the balance when entering leave
+	types := r.Group("/leave-types", middleware.Auth(m.tokenSvc), middleware.TenantMiddleware(), middleware.RequireRoles("admin", "hr", "timekeeper"))
+	typelist.NewEndpoint(types)
+	typeAdmin := types.Group("", middleware.RequireRoles("admin", "hr"))
+	typecreate.NewEndpoint(typeAdmin)
+	typeupdate.NewEndpoint(typeAdmin)
+	typedelete.NewEndpoint(typeAdmin)
+
+	balances := r.Group("/leave-balances", middleware.Auth(m.tokenSvc), middleware.TenantMiddleware(), middleware.RequireRoles("admin", "hr", "timekeeper"))
+	balancelist.NewEndpoint(balances)
+	balanceemployee.NewEndpoint(balances)
+	balanceAdmin := balances.Group("", middleware.RequireRoles("admin", "hr"))
+	open.NewEndpoint(balanceAdmin)
+	adjust.NewEndpoint(balanceAdmin)
+
+	// supervisors (timekeeper) file requests and decide the steps assigned to them; without an
+	// approval chain the approve/reject handlers allow admin and hr only
+	requests := r.Group("/leave-requests", middleware.Auth(m.tokenSvc), middleware.TenantMiddleware(), middleware.RequireRoles("admin", "hr", "timekeeper"))
+	requestlist.NewEndpoint(requests)
+	requestget.NewEndpoint(requests)
+	requestcreate.NewEndpoint(requests)
+	requestapprove.NewEndpoint(requests)
+	requestreject.NewEndpoint(requests)
+	requestcancel.NewEndpoint(requests)
+}
@@ -35,6 +35,10 @@ type Config struct {
 	WorkHoursPerDay            float64                `json:"workHoursPerDay"`
 	LateRatePerMinute          float64                `json:"lateRatePerMinute"`
 	LateGraceMinutes           int                    `json:"lateGraceMinutes"`
+	ProrationBasis             string                 `json:"prorationBasis"`
+	OtWeekdayMultiplier        *float64               `json:"otWeekdayMultiplier,omitempty"`
+	HolidayWorkMultiplier      float64                `json:"holidayWorkMultiplier"`
+	HolidayOtMultiplier        float64                `json:"holidayOtMultiplier"`
 	Note                       *string                `json:"note,omitempty"`
 	CreatedAt                  time.Time              `json:"createdAt"`
 	UpdatedAt                  time.Time              `json:"updatedAt"`
@@ -82,6 +86,10 @@ func FromRecord(r repository.Record) Config {
 		WorkHoursPerDay:            r.WorkHoursPerDay,
 		LateRatePerMinute:          r.LateRatePerMinute,
 		LateGraceMinutes:           r.LateGraceMinutes,
+		ProrationBasis:             r.ProrationBasis,
+		OtWeekdayMultiplier:        r.OtWeekdayMultiplier,
+		HolidayWorkMultiplier:      r.HolidayWorkMultiplier,
+		HolidayOtMultiplier:        r.HolidayOtMultiplier,
 		Note:                       r.Note,
 		CreatedAt:                  r.CreatedAt,
 		UpdatedAt:                  r.UpdatedAt,
@@ -105,6 +105,10 @@ func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
 			"work_hours_per_day":            created.WorkHoursPerDay,
 			"late_rate_per_minute":          created.LateRatePerMinute,
 			"late_grace_minutes":            created.LateGraceMinutes,
+			"proration_basis":               created.ProrationBasis,
+			"ot_weekday_multiplier":         created.OtWeekdayMultiplier,
+			"holiday_work_multiplier":       created.HolidayWorkMultiplier,
+			"holiday_ot_multiplier":         created.HolidayOtMultiplier,
 		},
 		Timestamp: time.Now(),
 	})
@@ -186,6 +190,9 @@ const (
 	defaultWorkHoursPerDay            = 8.0
 	defaultLateRatePerMinute          = 5.0
 	defaultLateGraceMinutes           = 15
+	defaultProrationBasis             = "thirty_day"
+	defaultHolidayWorkMultiplier      = 1.0
+	defaultHolidayOtMultiplier        = 3.0
 )
 
 func applyDefaults(p *RequestBody) {
@@ -231,6 +238,17 @@ func applyDefaults(p *RequestBody) {
 		v := defaultLateGraceMinutes
 		p.LateGraceMinutes = &v
 	}
+	if p.ProrationBasis == "" {
+		p.ProrationBasis = defaultProrationBasis
+	}
+	if p.HolidayWorkMultiplier == nil || *p.HolidayWorkMultiplier <= 0 {
+		v := defaultHolidayWorkMultiplier
+		p.HolidayWorkMultiplier = &v
+	}
+	if p.HolidayOtMultiplier == nil || *p.HolidayOtMultiplier <= 0 {
+		v := defaultHolidayOtMultiplier
+		p.HolidayOtMultiplier = &v
+	}
 }
 
 func floatPtr(v float64) *float64 {
@@ -36,6 +36,10 @@ type RequestBody struct {
 	WorkHoursPerDay            *float64               `json:"workHoursPerDay" validate:"omitempty,gt=0"`
 	LateRatePerMinute          *float64               `json:"lateRatePerMinute" validate:"omitempty,gte=0"`
 	LateGraceMinutes           *int                   `json:"lateGraceMinutes" validate:"omitempty,gte=0"`
+	ProrationBasis             string                 `json:"prorationBasis" validate:"omitempty,oneof=thirty_day calendar_days working_days"`
+	OtWeekdayMultiplier        *float64               `json:"otWeekdayMultiplier" validate:"omitempty,gt=0"`
+	HolidayWorkMultiplier      *float64               `json:"holidayWorkMultiplier" validate:"omitempty,gt=0"`
+	HolidayOtMultiplier        *float64               `json:"holidayOtMultiplier" validate:"omitempty,gt=0"`
 	Note                       *string                `json:"note"`
 
 	ParsedStartDate time.Time `json:"-"`
@@ -65,13 +69,18 @@ func (p RequestBody) ToRecord() repository.Record {
 		WorkHoursPerDay:            floatValue(p.WorkHoursPerDay),
 		LateRatePerMinute:          floatValue(p.LateRatePerMinute),
 		LateGraceMinutes:           intValue(p.LateGraceMinutes),
+		ProrationBasis:             p.ProrationBasis,
+		OtWeekdayMultiplier:        p.OtWeekdayMultiplier,
+		HolidayWorkMultiplier:      floatValue(p.HolidayWorkMultiplier),
+		HolidayOtMultiplier:        floatValue(p.HolidayOtMultiplier),
 		Note:                       p.Note,
 	}
 }
 
 // Create payroll config
 // @Summary Create payroll config
-// @Description สร้างเวอร์ชัน config ใหม่
+// @Description สร้างเวอร์ชัน config ใหม่ (prorationBasis = เกณฑ์คิดเงินเดือนตามสัดส่วนเมื่อเข้างาน/ออกระหว่างงวด: thirty_day = 30 วันลบวันที่ไม่ได้ทำงานในงวด, calendar_days, working_days)
+// @Description ตัวคูณ OT คูณกับค่าจ้างต่อชั่วโมง (เงินเดือน / 30 / workHoursPerDay): otWeekdayMultiplier = ล่วงเวลาวันทำงาน (ไม่ระบุ = ใช้ otHourlyRate คงที่), holidayWorkMultiplier = ทำงานวันหยุด (ค่าเริ่มต้น 1), holidayOtMultiplier = ล่วงเวลาวันหยุด (ค่าเริ่มต้น 3)
 // @Tags Payroll Config
 // @Accept json
 // @Produce json
@@ -85,6 +85,9 @@ func buildDefaultPayrollConfig(startDate time.Time) repository.Record {
 		WorkHoursPerDay:            8.00,
 		LateRatePerMinute:          5.00,
 		LateGraceMinutes:           15,
+		ProrationBasis:             "thirty_day",
+		HolidayWorkMultiplier:      1.00,
+		HolidayOtMultiplier:        3.00,
 	}
 }
 
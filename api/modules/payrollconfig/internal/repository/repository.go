@@ -45,6 +45,10 @@ type Record struct {
 	WorkHoursPerDay            float64     `db:"work_hours_per_day"`
 	LateRatePerMinute          float64     `db:"late_rate_per_minute"`
 	LateGraceMinutes           int         `db:"late_grace_minutes"`
+	ProrationBasis             string      `db:"proration_basis"`
+	OtWeekdayMultiplier        *float64    `db:"ot_weekday_multiplier"`
+	HolidayWorkMultiplier      float64     `db:"holiday_work_multiplier"`
+	HolidayOtMultiplier        float64     `db:"holiday_ot_multiplier"`
 	Note                       *string     `db:"note"`
 	CompanyID                  uuid.UUID   `db:"company_id"`
 	CreatedAt                  time.Time   `db:"created_at"`
@@ -91,6 +95,10 @@ SELECT
   work_hours_per_day,
   late_rate_per_minute,
   late_grace_minutes,
+  proration_basis,
+  ot_weekday_multiplier,
+  holiday_work_multiplier,
+  holiday_ot_multiplier,
   note,
   company_id,
   created_at,
@@ -154,6 +162,10 @@ SELECT
   work_hours_per_day,
   late_rate_per_minute,
   late_grace_minutes,
+  proration_basis,
+  ot_weekday_multiplier,
+  holiday_work_multiplier,
+  holiday_ot_multiplier,
   note,
   created_at,
   updated_at
@@ -202,6 +214,10 @@ INSERT INTO payroll_config (
   work_hours_per_day,
   late_rate_per_minute,
   late_grace_minutes,
+  proration_basis,
+  ot_weekday_multiplier,
+  holiday_work_multiplier,
+  holiday_ot_multiplier,
   note,
   company_id,
   created_by,
@@ -210,7 +226,7 @@ INSERT INTO payroll_config (
 SELECT
   daterange($1, NULL, '[)'),
   next_version.version_no,
-  $2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$23,$24,$25,$25
+  $2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,$17,$18,$19,$20,$21,$22,$26,$27,$28,$29,$23,$24,$25,$25
 FROM next_version
 RETURNING
   id,
@@ -239,6 +255,10 @@ RETURNING
   work_hours_per_day,
   late_rate_per_minute,
   late_grace_minutes,
+  proration_basis,
+  ot_weekday_multiplier,
+  holiday_work_multiplier,
+  holiday_ot_multiplier,
   note,
   company_id,
   created_at,
@@ -271,6 +291,10 @@ RETURNING
 		payload.Note,
 		companyID,
 		actor,
+		payload.ProrationBasis,
+		payload.OtWeekdayMultiplier,
+		payload.HolidayWorkMultiplier,
+		payload.HolidayOtMultiplier,
 	)
 	if err != nil {
 		return nil, err
@@ -25,6 +25,7 @@ type Profile struct {
 	PhoneAlt       *string    `json:"phoneAlt,omitempty"`
 	Email          *string    `json:"email,omitempty"`
 	TaxID          *string    `json:"taxId,omitempty"`
+	SSOAccountNo   *string    `json:"ssoEmployerAccountNo,omitempty"`
 	SlipFooterNote *string    `json:"slipFooterNote,omitempty"`
 	LogoID         *uuid.UUID `json:"logoId,omitempty"`
 	CreatedAt      time.Time  `json:"createdAt"`
@@ -65,6 +66,7 @@ func FromRecord(r repository.Record) Profile {
 		PhoneAlt:       r.PhoneAlt,
 		Email:          r.Email,
 		TaxID:          r.TaxID,
+		SSOAccountNo:   r.SSOAccountNo,
 		SlipFooterNote: r.SlipFooterNote,
 		LogoID:         r.LogoID,
 		CreatedAt:      r.CreatedAt,
@@ -92,6 +92,7 @@ func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
 			"phone_alt":        created.PhoneAlt,
 			"email":            created.Email,
 			"tax_id":           created.TaxID,
+			"sso_account_no":   created.SSOAccountNo,
 			"slip_footer_note": created.SlipFooterNote,
 			"logo_id":          created.LogoID,
 			"start_date":       created.StartDate,
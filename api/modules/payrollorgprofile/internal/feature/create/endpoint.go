@@ -28,6 +28,7 @@ type RequestBody struct {
 	PhoneAlt       *string    `json:"phoneAlt,omitempty"`
 	Email          *string    `json:"email,omitempty" validate:"omitempty,email"`
 	TaxID          *string    `json:"taxId,omitempty"`
+	SSOAccountNo   *string    `json:"ssoEmployerAccountNo,omitempty" validate:"omitempty,len=10,numeric"`
 	SlipFooterNote *string    `json:"slipFooterNote,omitempty"`
 	LogoID         *uuid.UUID `json:"logoId,omitempty"`
 	Status         *string    `json:"status,omitempty" validate:"omitempty,oneof=active retired"`
@@ -49,6 +50,7 @@ func (p RequestBody) ToPayload() repository.UpsertPayload {
 		PhoneAlt:       normalizeStr(p.PhoneAlt),
 		Email:          normalizeStr(p.Email),
 		TaxID:          normalizeStr(p.TaxID),
+		SSOAccountNo:   normalizeStr(p.SSOAccountNo),
 		SlipFooterNote: normalizeStr(p.SlipFooterNote),
 		LogoID:         p.LogoID,
 		Status:         normalizeStr(p.Status),
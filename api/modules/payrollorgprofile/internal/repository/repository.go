@@ -37,6 +37,7 @@ type Record struct {
 	PhoneAlt        *string    `db:"phone_alt"`
 	Email           *string    `db:"email"`
 	TaxID           *string    `db:"tax_id"`
+	SSOAccountNo    *string    `db:"sso_employer_account_no"`
 	SlipFooterNote  *string    `db:"slip_footer_note"`
 	LogoID          *uuid.UUID `db:"logo_id"`
 	CreatedAt       time.Time  `db:"created_at"`
@@ -94,6 +95,7 @@ SELECT
   phone_alt,
   email,
   tax_id,
+  sso_employer_account_no,
   slip_footer_note,
   logo_id,
   created_at,
@@ -156,6 +158,7 @@ SELECT
   phone_alt,
   email,
   tax_id,
+  sso_employer_account_no,
   slip_footer_note,
   logo_id,
   created_at,
@@ -199,6 +202,7 @@ SELECT
   phone_alt,
   email,
   tax_id,
+  sso_employer_account_no,
   slip_footer_note,
   logo_id,
   created_at,
@@ -232,6 +236,7 @@ type UpsertPayload struct {
 	PhoneAlt       *string
 	Email          *string
 	TaxID          *string
+	SSOAccountNo   *string
 	SlipFooterNote *string
 	LogoID         *uuid.UUID
 	Status         *string
@@ -271,6 +276,7 @@ INSERT INTO payroll_org_profile (
   phone_alt,
   email,
   tax_id,
+  sso_employer_account_no,
   slip_footer_note,
   logo_id,
   status,
@@ -281,7 +287,7 @@ SELECT
   daterange($1::date, NULL, '[)'),
   $2::uuid,
   next_version.version_no,
-  $3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,COALESCE($16::config_status, 'active'::config_status),$17,$17
+  $3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14,$15,$16,COALESCE($17::config_status, 'active'::config_status),$18,$18
 FROM next_version
 RETURNING
   id,
@@ -301,6 +307,7 @@ RETURNING
   phone_alt,
   email,
   tax_id,
+  sso_employer_account_no,
   slip_footer_note,
   logo_id,
   created_at,
@@ -324,6 +331,7 @@ RETURNING
 		payload.PhoneAlt,
 		payload.Email,
 		payload.TaxID,
+		payload.SSOAccountNo,
 		payload.SlipFooterNote,
 		payload.LogoID,
 		payload.Status,
@@ -356,12 +364,13 @@ SET
   phone_alt = $10,
   email = $11,
   tax_id = $12,
-  slip_footer_note = $13,
-  logo_id = $14,
-  status = COALESCE($15::config_status, status),
-  updated_by = $16,
+  sso_employer_account_no = $13,
+  slip_footer_note = $14,
+  logo_id = $15,
+  status = COALESCE($16::config_status, status),
+  updated_by = $17,
   updated_at = now()
-WHERE id = $17 AND company_id = $18
+WHERE id = $18 AND company_id = $19
 RETURNING
   id,
   company_id,
@@ -380,6 +389,7 @@ RETURNING
   phone_alt,
   email,
   tax_id,
+  sso_employer_account_no,
   slip_footer_note,
   logo_id,
   created_at,
@@ -402,6 +412,7 @@ RETURNING
 		payload.PhoneAlt,
 		payload.Email,
 		payload.TaxID,
+		payload.SSOAccountNo,
 		payload.SlipFooterNote,
 		payload.LogoID,
 		payload.Status,
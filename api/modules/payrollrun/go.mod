@@ -9,9 +9,12 @@ replace hrms/shared/events v0.0.0 => ../../shared/events
 require (
 	github.com/gofiber/fiber/v3 v3.0.0-rc.3
 	github.com/google/uuid v1.6.0
+	github.com/jung-kurt/gofpdf v1.16.2
 	github.com/lib/pq v1.10.9
 	go.uber.org/zap v1.27.1
+	golang.org/x/text v0.32.0
 	hrms/shared/common v0.0.0
+	hrms/shared/contracts v0.0.0
 	hrms/shared/events v0.0.0
 )
 
@@ -37,8 +40,6 @@ require (
 	golang.org/x/crypto v0.46.0 // indirect
 	golang.org/x/net v0.48.0 // indirect
 	golang.org/x/sys v0.39.0 // indirect
-	golang.org/x/text v0.32.0 // indirect
-	hrms/shared/contracts v0.0.0 // indirect
 )
 
 replace hrms/shared/contracts v0.0.0 => ../../shared/contracts
@@ -0,0 +1,201 @@
+// Package bankfile builds bank payroll (bulk credit) upload files from approved payroll runs.
+//
+// Each bank layout implements Format and registers itself from an init func, so adding a
+// bank is a matter of dropping in one file.
+package bankfile
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Transfer is one credit line (employee net pay).
+type Transfer struct {
+	Reference   string // employee number
+	Name        string
+	BankCode    string // banks.code, e.g. KTB
+	AccountNo   string
+	Amount      float64
+	Description string
+}
+
+// Batch is the whole upload: one debit account, many credits.
+type Batch struct {
+	CompanyName      string
+	DebitBankCode    string
+	DebitAccountNo   string
+	DebitAccountName string
+	EffectiveDate    time.Time
+	Transfers        []Transfer
+}
+
+// TotalSatang is the batch total in satang, summed per line to avoid float drift.
+func (b Batch) TotalSatang() int64 {
+	var total int64
+	for _, t := range b.Transfers {
+		total += Satang(t.Amount)
+	}
+	return total
+}
+
+type Format interface {
+	// Code is the banks.code this layout belongs to (KTB, SCB, KBANK, BBL).
+	Code() string
+	FileExtension() string
+	ContentType() string
+	// AccountWidths are the account number field widths of the layout.
+	AccountWidths() AccountWidths
+	Write(w io.Writer, b Batch) error
+}
+
+// AccountWidths limits the digits of the debit and credit account numbers; 0 means no limit
+// (delimited layouts). Longer numbers are rejected rather than cut.
+type AccountWidths struct {
+	Debit  int
+	Credit int
+}
+
+// Fits reports whether an account number fits a field of width digits.
+func Fits(accountNo string, width int) bool {
+	return width <= 0 || len(Digits(accountNo)) <= width
+}
+
+var formats = map[string]Format{}
+
+// Register makes a layout available to Lookup; called from each layout's init.
+func Register(f Format) {
+	formats[strings.ToUpper(f.Code())] = f
+}
+
+func Lookup(code string) (Format, bool) {
+	f, ok := formats[strings.ToUpper(strings.TrimSpace(code))]
+	return f, ok
+}
+
+// Codes lists the supported bank codes, sorted.
+func Codes() []string {
+	out := make([]string, 0, len(formats))
+	for code := range formats {
+		out = append(out, code)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// botCodes maps banks.code to the Bank of Thailand 3-digit institution code used in bulk files.
+var botCodes = map[string]string{
+	"BBL":   "002",
+	"KBANK": "004",
+	"KTB":   "006",
+	"TTB":   "011",
+	"SCB":   "014",
+	"CIMBT": "022",
+	"UOB":   "024",
+	"BAY":   "025",
+	"GSB":   "030",
+	"GHB":   "033",
+	"BAAC":  "034",
+	"TISCO": "067",
+	"KKP":   "069",
+	"LHFG":  "073",
+}
+
+// BOTCode returns the Bank of Thailand code for a banks.code, or "" when unknown.
+func BOTCode(bankCode string) string {
+	return botCodes[strings.ToUpper(strings.TrimSpace(bankCode))]
+}
+
+// Satang converts baht to satang, rounded half away from zero.
+func Satang(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// Digits strips everything but 0-9 (account numbers are stored with dashes).
+func Digits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Validate checks the fields every layout needs, and that the account numbers fit the layout.
+func Validate(b Batch, widths AccountWidths) error {
+	if Digits(b.DebitAccountNo) == "" {
+		return fmt.Errorf("debit account number is required")
+	}
+	if !Fits(b.DebitAccountNo, widths.Debit) {
+		return fmt.Errorf("debit account number %s is longer than %d digits", b.DebitAccountNo, widths.Debit)
+	}
+	if len(b.Transfers) == 0 {
+		return fmt.Errorf("no transfers to export")
+	}
+	for _, t := range b.Transfers {
+		if Digits(t.AccountNo) == "" {
+			return fmt.Errorf("missing account number for %s", t.Reference)
+		}
+		if !Fits(t.AccountNo, widths.Credit) {
+			return fmt.Errorf("account number of %s is longer than %d digits", t.Reference, widths.Credit)
+		}
+		if BOTCode(t.BankCode) == "" {
+			return fmt.Errorf("unknown receiving bank %q for %s", t.BankCode, t.Reference)
+		}
+	}
+	return nil
+}
+
+// padRight left-aligns s in a field of width runes, truncating when longer.
+func padRight(s string, width int) string {
+	r := []rune(s)
+	if len(r) > width {
+		r = r[:width]
+	}
+	return string(r) + strings.Repeat(" ", width-len(r))
+}
+
+// padLeft right-aligns s in a field of width, filling with fill. A longer s is an error:
+// cutting an account number or an amount would pay the wrong account or the wrong sum.
+func padLeft(s string, width int, fill byte) (string, error) {
+	if len(s) > width {
+		return "", fmt.Errorf("%q does not fit in %d characters", s, width)
+	}
+	return strings.Repeat(string(fill), width-len(s)) + s, nil
+}
+
+func zeroNum(v int64, width int) (string, error) {
+	return padLeft(fmt.Sprintf("%d", v), width, '0')
+}
+
+// fields keeps the first overflow while a fixed-width record is put together, so the writers
+// can concatenate fields and check once.
+type fields struct {
+	err error
+}
+
+func (f *fields) padLeft(name, s string, width int, fill byte) string {
+	out, err := padLeft(s, width, fill)
+	if err != nil && f.err == nil {
+		f.err = fmt.Errorf("%s: %w", name, err)
+	}
+	return out
+}
+
+func (f *fields) zeroNum(name string, v int64, width int) string {
+	return f.padLeft(name, fmt.Sprintf("%d", v), width, '0')
+}
+
+// writeLines writes CRLF-terminated records.
+func writeLines(w io.Writer, lines []string) error {
+	for _, l := range lines {
+		if _, err := io.WriteString(w, l+"\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
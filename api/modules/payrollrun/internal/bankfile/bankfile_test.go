@@ -0,0 +1,139 @@
+package bankfile
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testBatch() Batch {
+	return Batch{
+		CompanyName:    "ACME CO",
+		DebitBankCode:  "KTB",
+		DebitAccountNo: "123-4-56789-0",
+		EffectiveDate:  time.Date(2026, 1, 30, 0, 0, 0, 0, time.UTC),
+		Transfers: []Transfer{
+			{Reference: "E001", Name: "Somchai Jaidee", BankCode: "KTB", AccountNo: "987-6-54321-0", Amount: 15000.50, Description: "Salary 01/2026"},
+			{Reference: "E002", Name: "Malee Sukjai", BankCode: "SCB", AccountNo: "111-2-33333-4", Amount: 1234.56, Description: "Salary 01/2026"},
+		},
+	}
+}
+
+func write(t *testing.T, code string, b Batch) ([]string, error) {
+	t.Helper()
+	f, ok := Lookup(code)
+	if !ok {
+		t.Fatalf("format %s is not registered", code)
+	}
+	var buf bytes.Buffer
+	if err := f.Write(&buf, b); err != nil {
+		return nil, err
+	}
+	out := buf.String()
+	if !strings.HasSuffix(out, "\r\n") {
+		t.Fatalf("%s file does not end with CRLF: %q", code, out)
+	}
+	return strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n"), nil
+}
+
+func TestWriteGolden(t *testing.T) {
+	tests := []struct {
+		code string
+		want []string
+	}{
+		{"KBANK", []string{
+			"H1234567890260130000020000001623506ACME CO                                 ",
+			"D98765432100000001500050Somchai Jaidee                          E001            006",
+			"D11123333340000000123456Malee Sukjai                            E002            014",
+		}},
+		{"KTB", []string{
+			"H0000010061234567890ACME CO                                 30012026000002000000001623506",
+			"D00000200600000987654321000000000150005030012026Somchai Jaidee                                              E001                ",
+			"D00000301400000111233333400000000012345630012026Malee Sukjai                                                E002                ",
+			"T000004000002000000001623506",
+		}},
+		{"SCB", []string{
+			"H,1234567890,ACME CO,30/01/2026,2,16235.06",
+			"D,1,006,9876543210,Somchai Jaidee,15000.50,E001,Salary 01/2026",
+			"D,2,014,1112333334,Malee Sukjai,1234.56,E002,Salary 01/2026",
+			"T,2,16235.06",
+		}},
+		{"BBL", []string{
+			"No,Debit Account,Value Date,Receiving Bank Code,Receiving Account,Receiver Name,Amount,Reference",
+			"1,1234567890,30/01/2026,006,9876543210,Somchai Jaidee,15000.50,E001",
+			"2,1234567890,30/01/2026,014,1112333334,Malee Sukjai,1234.56,E002",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			got, err := write(t, tt.code, testBatch())
+			if err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Write() wrote %d lines, want %d:\n%s", len(got), len(tt.want), strings.Join(got, "\n"))
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("line %d\n got: %q\nwant: %q", i+1, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWriteRejectsLongAccounts(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		debit   string
+		credit  string
+		wantErr string
+	}{
+		{"kbank credit 11 digits", "KBANK", "1234567890", "12345678901", "longer than 10 digits"},
+		{"kbank debit 11 digits", "KBANK", "12345678901", "1234567890", "debit account number"},
+		{"ktb credit 11 digits fits", "KTB", "1234567890", "12345678901", ""},
+		{"ktb credit 12 digits", "KTB", "1234567890", "123456789012", "longer than 11 digits"},
+		{"ktb debit 11 digits", "KTB", "12345678901", "1234567890", "debit account number"},
+		{"scb has no width", "SCB", "123456789012345", "123456789012345", ""},
+		{"bbl has no width", "BBL", "123456789012345", "123456789012345", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := testBatch()
+			b.DebitAccountNo = tt.debit
+			b.Transfers[1].AccountNo = tt.credit
+			_, err := write(t, tt.code, b)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Write() error = %v, want none", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Write() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWriteRejectsAmountOverflow(t *testing.T) {
+	b := testBatch()
+	b.Transfers[0].Amount = 100_000_000_000 // 13 digits of baht, 15 in satang
+	if _, err := write(t, "KBANK", b); err == nil || !strings.Contains(err.Error(), "does not fit in 13") {
+		t.Fatalf("Write() error = %v, want amount overflow", err)
+	}
+}
+
+func TestPadLeft(t *testing.T) {
+	if got, err := padLeft("123", 5, '0'); err != nil || got != "00123" {
+		t.Errorf("padLeft() = %q, %v; want 00123", got, err)
+	}
+	if got, err := padLeft("12345", 5, '0'); err != nil || got != "12345" {
+		t.Errorf("padLeft() = %q, %v; want 12345", got, err)
+	}
+	if _, err := padLeft("123456", 5, '0'); err == nil {
+		t.Error("padLeft() of a longer value returned no error")
+	}
+}
@@ -0,0 +1,42 @@
+package bankfile
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// bbl is the Bualuang iCash payroll CSV: a column header row followed by one row per credit.
+// The debit account and value date are repeated on each row as the upload expects.
+type bbl struct{}
+
+func init() { Register(bbl{}) }
+
+func (bbl) Code() string          { return "BBL" }
+func (bbl) FileExtension() string { return "csv" }
+func (bbl) ContentType() string   { return "text/csv; charset=utf-8" }
+
+func (bbl) AccountWidths() AccountWidths { return AccountWidths{} }
+
+func (bbl) Write(w io.Writer, b Batch) error {
+	if err := Validate(b, bbl{}.AccountWidths()); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	cw.UseCRLF = true
+	rows := [][]string{{
+		"No", "Debit Account", "Value Date", "Receiving Bank Code",
+		"Receiving Account", "Receiver Name", "Amount", "Reference",
+	}}
+	date := b.EffectiveDate.Format("02/01/2006")
+	for i, t := range b.Transfers {
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", i+1), Digits(b.DebitAccountNo), date, BOTCode(t.BankCode),
+			Digits(t.AccountNo), t.Name, baht(Satang(t.Amount)), t.Reference,
+		})
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
@@ -0,0 +1,47 @@
+package bankfile
+
+import (
+	"io"
+
+	"hrms/modules/payrollrun/internal/thaienc"
+)
+
+// kbank is the K-Cash Connect payroll layout: fixed-width TIS-620, header + details, no trailer.
+//
+//	H: type(1) debit account(10) effective YYMMDD(6) count(5) total(13) company name(40)
+//	D: type(1) account(10) amount(13) name(40) reference(16) bank(3)
+type kbank struct{}
+
+func init() { Register(kbank{}) }
+
+func (kbank) Code() string          { return "KBANK" }
+func (kbank) FileExtension() string { return "txt" }
+func (kbank) ContentType() string   { return "text/plain; charset=windows-874" }
+
+func (kbank) AccountWidths() AccountWidths { return AccountWidths{Debit: 10, Credit: 10} }
+
+func (kbank) Write(w io.Writer, b Batch) error {
+	if err := Validate(b, kbank{}.AccountWidths()); err != nil {
+		return err
+	}
+	var f fields
+	lines := make([]string, 0, len(b.Transfers)+1)
+	lines = append(lines, "H"+
+		f.padLeft("debit account", Digits(b.DebitAccountNo), 10, '0')+
+		b.EffectiveDate.Format("060102")+
+		f.zeroNum("transfer count", int64(len(b.Transfers)), 5)+
+		f.zeroNum("total", b.TotalSatang(), 13)+
+		thaienc.TIS620(padRight(b.CompanyName, 40)))
+	for _, t := range b.Transfers {
+		lines = append(lines, "D"+
+			f.padLeft("account of "+t.Reference, Digits(t.AccountNo), 10, '0')+
+			f.zeroNum("amount of "+t.Reference, Satang(t.Amount), 13)+
+			thaienc.TIS620(padRight(t.Name, 40))+
+			padRight(t.Reference, 16)+
+			BOTCode(t.BankCode))
+	}
+	if f.err != nil {
+		return f.err
+	}
+	return writeLines(w, lines)
+}
@@ -0,0 +1,52 @@
+package bankfile
+
+import (
+	"io"
+
+	"hrms/modules/payrollrun/internal/thaienc"
+)
+
+// ktb is the Krungthai Corporate Online payroll (direct credit) layout:
+// fixed-width TIS-620 records, header/detail/trailer, amounts in satang.
+//
+//	H: type(1) seq(6) bank(3) debit account(10) company name(40) effective DDMMYYYY(8) count(6) total(15)
+//	D: type(1) seq(6) bank(3) branch(4) account(11) amount(15) effective DDMMYYYY(8) name(60) reference(20)
+//	T: type(1) seq(6) count(6) total(15)
+type ktb struct{}
+
+func init() { Register(ktb{}) }
+
+func (ktb) Code() string          { return "KTB" }
+func (ktb) FileExtension() string { return "txt" }
+func (ktb) ContentType() string   { return "text/plain; charset=windows-874" }
+
+func (ktb) AccountWidths() AccountWidths { return AccountWidths{Debit: 10, Credit: 11} }
+
+func (ktb) Write(w io.Writer, b Batch) error {
+	if err := Validate(b, ktb{}.AccountWidths()); err != nil {
+		return err
+	}
+	date := b.EffectiveDate.Format("02012006")
+	count := int64(len(b.Transfers))
+	total := b.TotalSatang()
+
+	var f fields
+	lines := make([]string, 0, len(b.Transfers)+2)
+	lines = append(lines, "H"+f.zeroNum("sequence", 1, 6)+"006"+
+		f.padLeft("debit account", Digits(b.DebitAccountNo), 10, '0')+
+		thaienc.TIS620(padRight(b.CompanyName, 40))+
+		date+f.zeroNum("transfer count", count, 6)+f.zeroNum("total", total, 15))
+	for i, t := range b.Transfers {
+		lines = append(lines, "D"+f.zeroNum("sequence", int64(i+2), 6)+
+			BOTCode(t.BankCode)+"0000"+
+			f.padLeft("account of "+t.Reference, Digits(t.AccountNo), 11, '0')+
+			f.zeroNum("amount of "+t.Reference, Satang(t.Amount), 15)+date+
+			thaienc.TIS620(padRight(t.Name, 60))+
+			padRight(t.Reference, 20))
+	}
+	lines = append(lines, "T"+f.zeroNum("sequence", count+2, 6)+f.zeroNum("transfer count", count, 6)+f.zeroNum("total", total, 15))
+	if f.err != nil {
+		return f.err
+	}
+	return writeLines(w, lines)
+}
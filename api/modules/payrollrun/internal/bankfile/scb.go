@@ -0,0 +1,49 @@
+package bankfile
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+)
+
+// scb is the SCB Business Net payroll CSV: H row (debit side), D rows, T row.
+//
+//	H,<debit account>,<company name>,<effective dd/mm/yyyy>,<count>,<total>
+//	D,<seq>,<bank code>,<account>,<name>,<amount>,<reference>,<description>
+//	T,<count>,<total>
+type scb struct{}
+
+func init() { Register(scb{}) }
+
+func (scb) Code() string          { return "SCB" }
+func (scb) FileExtension() string { return "csv" }
+func (scb) ContentType() string   { return "text/csv; charset=utf-8" }
+
+func (scb) AccountWidths() AccountWidths { return AccountWidths{} }
+
+func (scb) Write(w io.Writer, b Batch) error {
+	if err := Validate(b, scb{}.AccountWidths()); err != nil {
+		return err
+	}
+	total := baht(b.TotalSatang())
+	count := fmt.Sprintf("%d", len(b.Transfers))
+
+	cw := csv.NewWriter(w)
+	cw.UseCRLF = true
+	rows := [][]string{{"H", Digits(b.DebitAccountNo), b.CompanyName, b.EffectiveDate.Format("02/01/2006"), count, total}}
+	for i, t := range b.Transfers {
+		rows = append(rows, []string{
+			"D", fmt.Sprintf("%d", i+1), BOTCode(t.BankCode), Digits(t.AccountNo),
+			t.Name, baht(Satang(t.Amount)), t.Reference, t.Description,
+		})
+	}
+	rows = append(rows, []string{"T", count, total})
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+func baht(satang int64) string {
+	return fmt.Sprintf("%d.%02d", satang/100, satang%100)
+}
@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/payrollrun/internal/repository"
+)
+
+type GLAccount struct {
+	ID             uuid.UUID  `json:"id"`
+	AccountKey     string     `json:"accountKey"`
+	DepartmentID   *uuid.UUID `json:"departmentId,omitempty"`
+	DepartmentCode *string    `json:"departmentCode,omitempty"`
+	DepartmentName *string    `json:"departmentName,omitempty"`
+	AccountCode    string     `json:"accountCode"`
+	AccountName    string     `json:"accountName"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+func FromGLAccounts(rows []repository.GLAccount) []GLAccount {
+	out := make([]GLAccount, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, GLAccount{
+			ID:             r.ID,
+			AccountKey:     r.AccountKey,
+			DepartmentID:   r.DepartmentID,
+			DepartmentCode: r.DepartmentCode,
+			DepartmentName: r.DepartmentName,
+			AccountCode:    r.AccountCode,
+			AccountName:    r.AccountName,
+			UpdatedAt:      r.UpdatedAt,
+		})
+	}
+	return out
+}
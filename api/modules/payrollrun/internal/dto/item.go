@@ -26,6 +26,7 @@ type Item struct {
 	PTHourlyRate            float64   `json:"ptHourlyRate"`  // part-time only
 	OtHours                 float64   `json:"otHours"`
 	OtAmount                float64   `json:"otAmount"`
+	OtBreakdown             OtBreakdown `json:"otBreakdown"`
 	BonusAmount             float64   `json:"bonusAmount"`
 	LeaveCompensationAmount float64   `json:"leaveCompensationAmount"`
 	IncomeTotal             float64   `json:"incomeTotal"`
@@ -49,6 +50,43 @@ type Item struct {
 	AllowElectric           bool      `json:"allowElectric"`
 	AllowInternet           bool      `json:"allowInternet"`
 	AllowDoctorFee          bool      `json:"allowDoctorFee"`
+	Proration               *Proration `json:"proration,omitempty"`
+}
+
+// Proration is set when a full-time employee joined or left during the period and the salary
+// was paid for Days out of PeriodDays under Basis (thirty_day, calendar_days, working_days).
+type Proration struct {
+	Basis      string  `json:"basis"`
+	Days       float64 `json:"days"`
+	PeriodDays float64 `json:"periodDays"`
+}
+
+// OtBreakdown splits OtHours/OtAmount into weekday OT, holiday work and holiday OT.
+type OtBreakdown struct {
+	WeekdayHours      float64 `json:"weekdayHours"`
+	WeekdayAmount     float64 `json:"weekdayAmount"`
+	HolidayWorkHours  float64 `json:"holidayWorkHours"`
+	HolidayWorkAmount float64 `json:"holidayWorkAmount"`
+	HolidayOtHours    float64 `json:"holidayOtHours"`
+	HolidayOtAmount   float64 `json:"holidayOtAmount"`
+}
+
+func otBreakdownFrom(r repository.Item) OtBreakdown {
+	return OtBreakdown{
+		WeekdayHours:      r.OtWeekdayHours,
+		WeekdayAmount:     r.OtWeekdayAmount,
+		HolidayWorkHours:  r.HolidayWorkHours,
+		HolidayWorkAmount: r.HolidayWorkAmount,
+		HolidayOtHours:    r.HolidayOtHours,
+		HolidayOtAmount:   r.HolidayOtAmount,
+	}
+}
+
+func prorationFrom(r repository.Item) *Proration {
+	if r.ProrationBasis == nil || r.ProrationDays == nil || r.ProrationPeriodDays == nil {
+		return nil
+	}
+	return &Proration{Basis: *r.ProrationBasis, Days: *r.ProrationDays, PeriodDays: *r.ProrationPeriodDays}
 }
 
 func FromItem(r repository.Item) Item {
@@ -70,6 +108,7 @@ func FromItem(r repository.Item) Item {
 		PTHourlyRate:            r.PTHourlyRate,
 		OtHours:                 r.OtHours,
 		OtAmount:                r.OtAmount,
+		OtBreakdown:             otBreakdownFrom(r),
 		BonusAmount:             r.BonusAmount,
 		LeaveCompensationAmount: r.LeaveCompensationAmount,
 		IncomeTotal:             r.IncomeTotal,
@@ -93,6 +132,7 @@ func FromItem(r repository.Item) Item {
 		AllowElectric:           r.AllowElectric,
 		AllowInternet:           r.AllowInternet,
 		AllowDoctorFee:          r.AllowDoctorFee,
+		Proration:               prorationFrom(r),
 	}
 }
 
@@ -115,6 +155,7 @@ func FromItemDetail(r repository.ItemDetail) ItemDetail {
 			PTHourlyRate:            r.PTHourlyRate,
 			OtHours:                 r.OtHours,
 			OtAmount:                r.OtAmount,
+			OtBreakdown:             otBreakdownFrom(r.Item),
 			BonusAmount:             r.BonusAmount,
 			LeaveCompensationAmount: r.LeaveCompensationAmount,
 			IncomeTotal:             r.IncomeTotal,
@@ -138,6 +179,7 @@ func FromItemDetail(r repository.ItemDetail) ItemDetail {
 			AllowElectric:           r.AllowElectric,
 			AllowInternet:           r.AllowInternet,
 			AllowDoctorFee:          r.AllowDoctorFee,
+			Proration:               prorationFrom(r.Item),
 		},
 		HousingAllowance:        r.HousingAllowance,
 		AttendanceBonusNoLate:   r.AttendanceBonusNoLate,
@@ -15,8 +15,13 @@ type Run struct {
 	PeriodStart     string                 `json:"periodStartDate"`
 	PayDate         string                 `json:"payDate"`
 	Status          string                 `json:"status"`
+	RunType         string                 `json:"runType"`
+	Note            *string                `json:"note,omitempty"`
 	ApprovedAt      *time.Time             `json:"approvedAt,omitempty"`
 	ApprovedBy      *uuid.UUID             `json:"approvedBy,omitempty"`
+	ReversedAt      *time.Time             `json:"reversedAt,omitempty"`
+	ReversedBy      *uuid.UUID             `json:"reversedBy,omitempty"`
+	ReversalReason  *string                `json:"reversalReason,omitempty"`
 	SSORateEmp      float64                `json:"socialSecurityRateEmployee"`
 	SSORateEmployer float64                `json:"socialSecurityRateEmployer"`
 	OrgProfile      map[string]interface{} `json:"orgProfileSnapshot,omitempty"`
@@ -69,8 +74,13 @@ func FromRun(r repository.Run) Run {
 		PeriodStart:     dateOnly(r.PeriodStart),
 		PayDate:         dateOnly(r.PayDate),
 		Status:          r.Status,
+		RunType:         r.RunType,
+		Note:            r.Note,
 		ApprovedAt:      r.ApprovedAt,
 		ApprovedBy:      r.ApprovedBy,
+		ReversedAt:      r.ReversedAt,
+		ReversedBy:      r.ReversedBy,
+		ReversalReason:  r.ReversalReason,
 		SSORateEmp:      r.SSORateEmp,
 		SSORateEmployer: r.SSORateEmployer,
 		OrgProfile:      snapshot,
@@ -0,0 +1,463 @@
+// Package engine reproduces the per-employee payroll item computation of the database
+// (recalculate_payroll_item_regular and the payroll_run_item_compute_totals trigger) in Go,
+// so a month can be previewed without writing a run.
+//
+// Amounts follow the SQL rounding: every value the SQL keeps in a NUMERIC(14,2) variable is
+// rounded half away from zero to satang at the same step.
+package engine
+
+import (
+	"encoding/json"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TypeFullTime = "full_time"
+	TypePartTime = "part_time"
+)
+
+// Proration bases of payroll_config.proration_basis
+const (
+	ProrationThirtyDay    = "thirty_day"
+	ProrationCalendarDays = "calendar_days"
+	ProrationWorkingDays  = "working_days"
+)
+
+// Config is the effective payroll_config plus the employee SSO rate and the period of the run.
+// A zero PeriodStart leaves the salary unprorated, as in a simulation of a full month.
+// A nil OtWeekdayMultiplier keeps weekday OT on the flat OtHourlyRate; the multipliers apply to
+// the hourly wage (BasePay / 30 / WorkHoursPerDay).
+type Config struct {
+	OtHourlyRate           float64
+	OtWeekdayMultiplier    *float64
+	HolidayWorkMultiplier  float64
+	HolidayOtMultiplier    float64
+	LateGraceMinutes       int
+	LateRatePerMinute      float64
+	WorkHoursPerDay        float64
+	HousingAllowance       float64
+	AttendanceBonusNoLate  float64
+	AttendanceBonusNoLeave float64
+	WaterRatePerUnit       float64
+	ElectricityRatePerUnit float64
+	InternetFeeMonthly     float64
+	SSORateEmployee        float64
+	SSOWageCap             float64
+	ProrationBasis         string
+	PeriodStart            time.Time
+	PeriodEnd              time.Time
+	Tax                    TaxConfig
+}
+
+type Employee struct {
+	ID                          uuid.UUID
+	TypeCode                    string
+	BasePay                     float64
+	SSOContribute               bool
+	SSODeclaredWage             float64
+	PFContribute                bool
+	PFRateEmployee              float64
+	WithholdTax                 bool
+	AllowHousing                bool
+	AllowInternet               bool
+	AllowDoctorFee              bool
+	AllowAttendanceBonusNoLate  bool
+	AllowAttendanceBonusNoLeave bool
+	EmploymentStart             time.Time
+	EmploymentEnd               *time.Time
+	TaxAllowance                TaxAllowance
+}
+
+// Line is one {name, value} entry of others_income, others_deduction or loan_repayments.
+type Line struct {
+	TxnID *uuid.UUID `json:"txn_id,omitempty"`
+	Name  string     `json:"name"`
+	Value float64    `json:"value"`
+}
+
+type Accumulations struct {
+	SSO             float64
+	Tax             float64
+	Income          float64
+	PF              float64
+	LoanOutstanding float64
+}
+
+// Current is what a recalculation keeps from the item already in the run: lines entered by hand,
+// the doctor fee, manual overrides and the amounts only ever typed in (utilities, advance repay).
+// The zero value is a fresh item.
+type Current struct {
+	OthersIncome            []Line
+	OthersDeduction         []Line
+	ManualRepayments        []Line
+	DoctorFee               float64
+	LeaveCompensationAmount float64
+	ManualTax               *float64
+	ManualPF                *float64
+	ManualInternet          *float64
+	ManualWaterRate         *float64
+	ManualElectricRate      *float64
+	WaterAmount             float64
+	ElectricAmount          float64
+	AdvanceRepayAmount      float64
+}
+
+// Inputs are the pending worklogs, advances, installments and accumulations for the month.
+type Inputs struct {
+	OtHours         float64
+	LateMinutes     int
+	LeaveDays       float64
+	LeaveDoubleDays float64
+	LeaveHours      float64
+	PTHours         float64
+	Bonus           float64
+	Advance         float64
+	Installments    []Line
+	// OtHours is weekday OT (entry type ot); holiday work and holiday OT are priced apart.
+	HolidayWorkHours float64
+	HolidayOtHours   float64
+	// SSOOtherRuns is the SSO already withheld by approved off-cycle/correction runs of the month.
+	SSOOtherRuns float64
+	Accum        Accumulations
+	Current      Current
+}
+
+// Proration is the share of the period a full-time employee who joined or left mid-period is paid.
+type Proration struct {
+	Basis      string  `json:"basis"`
+	Days       float64 `json:"days"`
+	PeriodDays float64 `json:"periodDays"`
+}
+
+type Item struct {
+	SalaryAmount           float64    `json:"salaryAmount"`
+	PTHoursWorked          float64    `json:"ptHoursWorked"`
+	PTHourlyRate           float64    `json:"ptHourlyRate"`
+	OtHours                float64    `json:"otHours"`
+	OtAmount               float64    `json:"otAmount"`
+	HousingAllowance       float64    `json:"housingAllowance"`
+	AttendanceBonusNoLate  float64    `json:"attendanceBonusNoLate"`
+	AttendanceBonusNoLeave float64    `json:"attendanceBonusNoLeave"`
+	BonusAmount            float64    `json:"bonusAmount"`
+	LeaveCompensation      float64    `json:"leaveCompensationAmount"`
+	DoctorFee              float64    `json:"doctorFee"`
+	OthersIncome           []Line     `json:"othersIncome"`
+	IncomeTotal            float64    `json:"incomeTotal"`
+	LateMinutesQty         int        `json:"lateMinutesQty"`
+	LateMinutesDeduction   float64    `json:"lateMinutesDeduction"`
+	LeaveDaysQty           float64    `json:"leaveDaysQty"`
+	LeaveDaysDeduction     float64    `json:"leaveDaysDeduction"`
+	LeaveDoubleQty         float64    `json:"leaveDoubleQty"`
+	LeaveDoubleDeduction   float64    `json:"leaveDoubleDeduction"`
+	LeaveHoursQty          float64    `json:"leaveHoursQty"`
+	LeaveHoursDeduction    float64    `json:"leaveHoursDeduction"`
+	SSODeclaredWage        float64    `json:"ssoDeclaredWage"`
+	SSOMonthAmount         float64    `json:"ssoMonthAmount"`
+	TaxMonthAmount         float64    `json:"taxMonthAmount"`
+	PFMonthAmount          float64    `json:"pfMonthAmount"`
+	WaterRatePerUnit       float64    `json:"waterRatePerUnit"`
+	ElectricityRatePerUnit float64    `json:"electricityRatePerUnit"`
+	WaterAmount            float64    `json:"waterAmount"`
+	ElectricAmount         float64    `json:"electricAmount"`
+	InternetAmount         float64    `json:"internetAmount"`
+	AdvanceAmount          float64    `json:"advanceAmount"`
+	AdvanceRepayAmount     float64    `json:"advanceRepayAmount"`
+	LoanRepayments         []Line     `json:"loanRepayments"`
+	OthersDeduction        []Line     `json:"othersDeduction"`
+	SSOAccumTotal          float64    `json:"ssoAccumTotal"`
+	TaxAccumTotal          float64    `json:"taxAccumTotal"`
+	IncomeAccumTotal       float64    `json:"incomeAccumTotal"`
+	PFAccumTotal           float64    `json:"pfAccumTotal"`
+	LoanOutstandingTotal   float64    `json:"loanOutstandingTotal"`
+	DeductionTotal         float64    `json:"deductionTotal"`
+	NetPay                 float64    `json:"netPay"`
+	Proration              *Proration `json:"proration,omitempty"`
+
+	OtBreakdown OtBreakdown `json:"otBreakdown"`
+}
+
+// OtBreakdown splits OtHours/OtAmount by entry type.
+type OtBreakdown struct {
+	WeekdayHours      float64 `json:"weekdayHours"`
+	WeekdayAmount     float64 `json:"weekdayAmount"`
+	HolidayWorkHours  float64 `json:"holidayWorkHours"`
+	HolidayWorkAmount float64 `json:"holidayWorkAmount"`
+	HolidayOtHours    float64 `json:"holidayOtHours"`
+	HolidayOtAmount   float64 `json:"holidayOtAmount"`
+}
+
+// Calculate computes one regular-run item.
+func Calculate(c Config, e Employee, in Inputs) Item {
+	cur := in.Current
+	it := Item{
+		BonusAmount:        Round2(in.Bonus),
+		LeaveCompensation:  cur.LeaveCompensationAmount,
+		OthersIncome:       nonNil(cur.OthersIncome),
+		OthersDeduction:    nonNil(cur.OthersDeduction),
+		AdvanceAmount:      Round2(in.Advance),
+		AdvanceRepayAmount: cur.AdvanceRepayAmount,
+		WaterAmount:        cur.WaterAmount,
+		ElectricAmount:     cur.ElectricAmount,
+		LoanRepayments:     append(append([]Line{}, in.Installments...), cur.ManualRepayments...),
+	}
+
+	switch e.TypeCode {
+	case TypeFullTime:
+		it.SalaryAmount = e.BasePay
+		if p := Prorate(c, e); p != nil {
+			it.Proration = p
+			it.SalaryAmount = 0
+			if p.PeriodDays > 0 {
+				it.SalaryAmount = Round2(e.BasePay * p.Days / p.PeriodDays)
+			}
+		}
+		it.OtBreakdown = Overtime(c, e.BasePay, in)
+		it.OtHours = Round2(in.OtHours + in.HolidayWorkHours + in.HolidayOtHours)
+		it.OtAmount = Round2(it.OtBreakdown.WeekdayAmount + it.OtBreakdown.HolidayWorkAmount + it.OtBreakdown.HolidayOtAmount)
+		it.LateMinutesQty = in.LateMinutes
+		if in.LateMinutes > c.LateGraceMinutes {
+			it.LateMinutesDeduction = Round2(float64(in.LateMinutes) * c.LateRatePerMinute)
+		}
+		daily := e.BasePay / 30.0
+		it.LeaveDaysQty = in.LeaveDays
+		it.LeaveDaysDeduction = Round2(daily * in.LeaveDays)
+		it.LeaveDoubleQty = in.LeaveDoubleDays
+		it.LeaveDoubleDeduction = Round2(daily * 2 * in.LeaveDoubleDays)
+		it.LeaveHoursQty = in.LeaveHours
+		it.LeaveHoursDeduction = Round2(daily / c.WorkHoursPerDay * in.LeaveHours)
+	case TypePartTime:
+		it.PTHoursWorked = in.PTHours
+		it.PTHourlyRate = e.BasePay
+		it.SalaryAmount = Round2(in.PTHours * e.BasePay)
+	}
+
+	if e.SSOContribute {
+		base := e.SSODeclaredWage
+		if e.TypeCode != TypeFullTime {
+			base = math.Min(it.SalaryAmount, c.SSOWageCap)
+		} else if it.Proration != nil {
+			base = math.Min(base, it.SalaryAmount)
+		}
+		it.SSODeclaredWage = Round2(math.Min(base, c.SSOWageCap))
+		amount := Round2(it.SSODeclaredWage * c.SSORateEmployee)
+		remaining := math.Max(Round2(c.SSOWageCap*c.SSORateEmployee)-in.SSOOtherRuns, 0)
+		it.SSOMonthAmount = math.Min(amount, remaining)
+	}
+
+	switch {
+	case cur.ManualPF != nil:
+		it.PFMonthAmount = *cur.ManualPF
+	case e.PFContribute:
+		it.PFMonthAmount = Round2(it.SalaryAmount * e.PFRateEmployee)
+	}
+
+	if e.AllowDoctorFee {
+		it.DoctorFee = cur.DoctorFee
+	}
+	it.WaterRatePerUnit = c.WaterRatePerUnit
+	if cur.ManualWaterRate != nil {
+		it.WaterRatePerUnit = *cur.ManualWaterRate
+	}
+	it.ElectricityRatePerUnit = c.ElectricityRatePerUnit
+	if cur.ManualElectricRate != nil {
+		it.ElectricityRatePerUnit = *cur.ManualElectricRate
+	}
+	switch {
+	case cur.ManualInternet != nil:
+		it.InternetAmount = *cur.ManualInternet
+	case e.AllowInternet:
+		it.InternetAmount = c.InternetFeeMonthly
+	}
+
+	if e.TypeCode == TypeFullTime {
+		if e.AllowHousing {
+			it.HousingAllowance = c.HousingAllowance
+		}
+		if it.SalaryAmount > 0 && it.LateMinutesQty == 0 && e.AllowAttendanceBonusNoLate {
+			it.AttendanceBonusNoLate = c.AttendanceBonusNoLate
+		}
+		if it.SalaryAmount > 0 && it.LeaveDaysDeduction == 0 && it.LeaveDoubleDeduction == 0 &&
+			it.LeaveHoursDeduction == 0 && e.AllowAttendanceBonusNoLeave {
+			it.AttendanceBonusNoLeave = c.AttendanceBonusNoLeave
+		}
+	}
+
+	// The withholding base leaves out leave compensation, as the SQL does; the stored
+	// income_total (trigger) includes it.
+	taxIncome := Round2(it.SalaryAmount + it.OtAmount + it.HousingAllowance + it.AttendanceBonusNoLate +
+		it.AttendanceBonusNoLeave + it.BonusAmount + it.DoctorFee + SumLines(it.OthersIncome))
+	if cur.ManualTax != nil {
+		it.TaxMonthAmount = *cur.ManualTax
+	} else {
+		it.TaxMonthAmount = WithholdingTax(c.Tax, taxIncome, e.WithholdTax, e.SSOContribute,
+			c.SSORateEmployee, c.SSOWageCap, it.SSODeclaredWage, e.TaxAllowance.Deduction(taxIncome*12))
+	}
+
+	it.IncomeTotal = Round2(taxIncome + it.LeaveCompensation)
+	it.IncomeAccumTotal = Round2(in.Accum.Income + it.IncomeTotal)
+	it.SSOAccumTotal = Round2(in.Accum.SSO + it.SSOMonthAmount)
+	it.TaxAccumTotal = Round2(in.Accum.Tax + it.TaxMonthAmount)
+	it.PFAccumTotal = Round2(in.Accum.PF + it.PFMonthAmount)
+	loanPaid := SumLines(it.LoanRepayments)
+	it.LoanOutstandingTotal = Round2(in.Accum.LoanOutstanding + (it.AdvanceAmount - it.AdvanceRepayAmount) - loanPaid)
+
+	it.DeductionTotal = Round2(it.LateMinutesDeduction + it.LeaveDaysDeduction + it.LeaveDoubleDeduction +
+		it.LeaveHoursDeduction + it.SSOMonthAmount + it.TaxMonthAmount + it.PFMonthAmount +
+		it.WaterAmount + it.ElectricAmount + it.InternetAmount + it.AdvanceRepayAmount +
+		SumLines(it.OthersDeduction) + loanPaid)
+	it.NetPay = Round2(it.IncomeTotal - it.DeductionTotal)
+	return it
+}
+
+// Overtime prices each OT type on the hourly wage times its multiplier, weekday OT on the flat
+// rate when no weekday multiplier is configured.
+func Overtime(c Config, basePay float64, in Inputs) OtBreakdown {
+	hourly := basePay / 30.0 / c.WorkHoursPerDay
+	b := OtBreakdown{
+		WeekdayHours:      in.OtHours,
+		HolidayWorkHours:  in.HolidayWorkHours,
+		HolidayWorkAmount: Round2(in.HolidayWorkHours * hourly * c.HolidayWorkMultiplier),
+		HolidayOtHours:    in.HolidayOtHours,
+		HolidayOtAmount:   Round2(in.HolidayOtHours * hourly * c.HolidayOtMultiplier),
+	}
+	if c.OtWeekdayMultiplier == nil {
+		b.WeekdayAmount = Round2(in.OtHours * c.OtHourlyRate)
+	} else {
+		b.WeekdayAmount = Round2(in.OtHours * hourly * *c.OtWeekdayMultiplier)
+	}
+	return b
+}
+
+// Prorate returns the days the employee is paid for when employment starts after the period
+// start or ends before the period end, or nil for a full period.
+func Prorate(c Config, e Employee) *Proration {
+	if c.PeriodStart.IsZero() {
+		return nil
+	}
+	from, to := c.PeriodStart, c.PeriodEnd
+	if e.EmploymentStart.After(from) {
+		from = e.EmploymentStart
+	}
+	if e.EmploymentEnd != nil && e.EmploymentEnd.Before(to) {
+		to = *e.EmploymentEnd
+	}
+	if from.Equal(c.PeriodStart) && to.Equal(c.PeriodEnd) {
+		return nil
+	}
+	basis := c.ProrationBasis
+	if basis == "" {
+		basis = ProrationThirtyDay
+	}
+	period := 30.0
+	if basis != ProrationThirtyDay {
+		period = ProrationDays(basis, c.PeriodStart, c.PeriodEnd)
+	}
+	return &Proration{
+		Basis:      basis,
+		Days:       math.Min(ProratedDays(basis, c.PeriodStart, c.PeriodEnd, from, to), period),
+		PeriodDays: period,
+	}
+}
+
+// ProratedDays is the days paid in the period [periodStart, periodEnd] for employment over
+// [from, to], as payroll_prorated_days does. The 30-day basis pays 30 less the days of the
+// period not employed, and at least one day, so February and 31-day months prorate like a
+// 30-day month; the other bases count the employed days.
+func ProratedDays(basis string, periodStart, periodEnd, from, to time.Time) float64 {
+	if from.After(to) {
+		return 0
+	}
+	if basis != ProrationThirtyDay {
+		return ProrationDays(basis, from, to)
+	}
+	missed := math.Round(from.Sub(periodStart).Hours()/24) + math.Round(periodEnd.Sub(to).Hours()/24)
+	return math.Min(math.Max(30-missed, 1), 30)
+}
+
+// ProrationDays counts the days of [from, to] under the basis, as payroll_proration_days does:
+// working days are Monday to Friday; the 30-day basis counts calendar days up to 30.
+func ProrationDays(basis string, from, to time.Time) float64 {
+	if from.After(to) {
+		return 0
+	}
+	days := math.Round(to.Sub(from).Hours()/24) + 1
+	switch basis {
+	case ProrationWorkingDays:
+		var n float64
+		for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+			if wd := d.Weekday(); wd != time.Saturday && wd != time.Sunday {
+				n++
+			}
+		}
+		return n
+	case ProrationThirtyDay:
+		return math.Min(days, 30)
+	}
+	return days
+}
+
+// SumLines is jsonb_sum_value over decoded lines.
+func SumLines(lines []Line) float64 {
+	var s float64
+	for _, l := range lines {
+		s += l.Value
+	}
+	return s
+}
+
+var numericValue = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// ParseLines decodes a JSONB [{name, value}] column. Like jsonb_sum_value, entries whose value
+// is not a plain number are dropped.
+func ParseLines(raw []byte) []Line {
+	var elems []map[string]interface{}
+	if len(raw) == 0 || json.Unmarshal(raw, &elems) != nil {
+		return []Line{}
+	}
+	out := make([]Line, 0, len(elems))
+	for _, el := range elems {
+		var s string
+		switch v := el["value"].(type) {
+		case float64:
+			s = strconv.FormatFloat(v, 'f', -1, 64)
+		case string:
+			s = v
+		default:
+			continue
+		}
+		if !numericValue.MatchString(s) {
+			continue
+		}
+		value, _ := strconv.ParseFloat(s, 64)
+		l := Line{Value: value}
+		if name, ok := el["name"].(string); ok {
+			l.Name = name
+		}
+		if txn, ok := el["txn_id"].(string); ok && txn != "" {
+			if id, err := uuid.Parse(txn); err == nil {
+				l.TxnID = &id
+			}
+		}
+		out = append(out, l)
+	}
+	return out
+}
+
+// Round2 rounds half away from zero to two decimals, as NUMERIC(14,2) assignment does.
+// The value is first snapped to 6 decimals so binary noise (1.005 → 1.00499…) does not
+// flip the result.
+func Round2(v float64) float64 {
+	return math.Round(math.Round(v*1e6)/1e4) / 100
+}
+
+func nonNil(lines []Line) []Line {
+	if lines == nil {
+		return []Line{}
+	}
+	return lines
+}
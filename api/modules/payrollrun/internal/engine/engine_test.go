@@ -0,0 +1,326 @@
+package engine
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func day(s string) time.Time {
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// seededConfig is the payroll_config every company is seeded with, for a full month.
+func seededConfig() Config {
+	return Config{
+		OtHourlyRate:           60,
+		HolidayWorkMultiplier:  1,
+		HolidayOtMultiplier:    3,
+		LateGraceMinutes:       15,
+		LateRatePerMinute:      5,
+		WorkHoursPerDay:        8,
+		HousingAllowance:       1000,
+		AttendanceBonusNoLate:  500,
+		AttendanceBonusNoLeave: 1000,
+		WaterRatePerUnit:       10,
+		ElectricityRatePerUnit: 6,
+		InternetFeeMonthly:     80,
+		SSORateEmployee:        0.05,
+		SSOWageCap:             17500,
+		Tax:                    seededTax(),
+	}
+}
+
+func fullTimer() Employee {
+	return Employee{
+		TypeCode:                    TypeFullTime,
+		BasePay:                     30000,
+		SSOContribute:               true,
+		SSODeclaredWage:             15000,
+		PFContribute:                true,
+		PFRateEmployee:              0.03,
+		WithholdTax:                 true,
+		AllowHousing:                true,
+		AllowInternet:               true,
+		AllowAttendanceBonusNoLate:  true,
+		AllowAttendanceBonusNoLeave: true,
+		EmploymentStart:             day("2020-01-01"),
+	}
+}
+
+func TestCalculateFullTime(t *testing.T) {
+	it := Calculate(seededConfig(), fullTimer(), Inputs{OtHours: 10, LateMinutes: 20})
+
+	// income: 30,000 salary + 600 OT + 1,000 housing + 1,000 no-leave bonus (late, so no
+	// no-late bonus); tax on 391,200 - 100,000 - 60,000 - 9,000 = 222,200 taxable
+	want := map[string]float64{
+		"SalaryAmount":           30000,
+		"OtAmount":               600,
+		"HousingAllowance":       1000,
+		"AttendanceBonusNoLate":  0,
+		"AttendanceBonusNoLeave": 1000,
+		"IncomeTotal":            32600,
+		"LateMinutesDeduction":   100,
+		"SSODeclaredWage":        15000,
+		"SSOMonthAmount":         750,
+		"PFMonthAmount":          900,
+		"TaxMonthAmount":         300.83,
+		"InternetAmount":         80,
+		"DeductionTotal":         2130.83,
+		"NetPay":                 30469.17,
+		"IncomeAccumTotal":       32600,
+		"TaxAccumTotal":          300.83,
+	}
+	checkItem(t, it, want)
+	if it.Proration != nil {
+		t.Errorf("Proration = %+v, want nil", it.Proration)
+	}
+}
+
+func TestCalculateAttendance(t *testing.T) {
+	tests := []struct {
+		name                string
+		in                  Inputs
+		noLate, noLeave     float64
+		lateDeduct, leaveDe float64
+	}{
+		{"perfect attendance", Inputs{}, 500, 1000, 0, 0},
+		{"late within grace still loses the no-late bonus", Inputs{LateMinutes: 10}, 0, 1000, 0, 0},
+		{"leave day", Inputs{LeaveDays: 1}, 500, 0, 0, 1000},
+		{"leave double", Inputs{LeaveDoubleDays: 1}, 500, 0, 0, 2000},
+		{"leave hours", Inputs{LeaveHours: 4}, 500, 0, 0, 500},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			it := Calculate(seededConfig(), fullTimer(), tt.in)
+			leave := it.LeaveDaysDeduction + it.LeaveDoubleDeduction + it.LeaveHoursDeduction
+			if it.AttendanceBonusNoLate != tt.noLate || it.AttendanceBonusNoLeave != tt.noLeave ||
+				it.LateMinutesDeduction != tt.lateDeduct || leave != tt.leaveDe {
+				t.Errorf("bonus %v/%v, late %v, leave %v; want %v/%v, %v, %v",
+					it.AttendanceBonusNoLate, it.AttendanceBonusNoLeave, it.LateMinutesDeduction, leave,
+					tt.noLate, tt.noLeave, tt.lateDeduct, tt.leaveDe)
+			}
+		})
+	}
+}
+
+func TestCalculatePartTime(t *testing.T) {
+	e := Employee{TypeCode: TypePartTime, BasePay: 60, SSOContribute: true, WithholdTax: true,
+		AllowHousing: true, AllowAttendanceBonusNoLate: true}
+	it := Calculate(seededConfig(), e, Inputs{PTHours: 100})
+	checkItem(t, it, map[string]float64{
+		"SalaryAmount":          6000,
+		"PTHoursWorked":         100,
+		"PTHourlyRate":          60,
+		"HousingAllowance":      0,
+		"AttendanceBonusNoLate": 0,
+		"SSODeclaredWage":       6000,
+		"SSOMonthAmount":        300,
+		"TaxMonthAmount":        0,
+		"NetPay":                5700,
+	})
+}
+
+func TestCalculateSSO(t *testing.T) {
+	tests := []struct {
+		name     string
+		declared float64
+		other    float64
+		want     float64
+	}{
+		{"declared below the cap", 15000, 0, 750},
+		{"declared over the cap", 30000, 0, 875},
+		{"off-cycle runs took part of the cap", 17500, 500, 375},
+		{"off-cycle runs took the whole cap", 17500, 900, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := fullTimer()
+			e.SSODeclaredWage = tt.declared
+			if got := Calculate(seededConfig(), e, Inputs{SSOOtherRuns: tt.other}).SSOMonthAmount; got != tt.want {
+				t.Errorf("SSOMonthAmount = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculateCurrent(t *testing.T) {
+	manualTax, manualPF := 1234.5, 0.0
+	in := Inputs{
+		Advance:      2000,
+		Installments: []Line{{Name: "loan", Value: 1500}},
+		Accum:        Accumulations{Income: 100000, Tax: 1000, LoanOutstanding: 10000},
+		Current: Current{
+			OthersIncome:       []Line{{Name: "commission", Value: 2500}},
+			OthersDeduction:    []Line{{Name: "uniform", Value: 300}},
+			ManualRepayments:   []Line{{Name: "manual", Value: 500}},
+			DoctorFee:          999, // not allowed for the employee
+			ManualTax:          &manualTax,
+			ManualPF:           &manualPF,
+			WaterAmount:        120,
+			ElectricAmount:     300,
+			AdvanceRepayAmount: 2000,
+		},
+	}
+	it := Calculate(seededConfig(), fullTimer(), in)
+	// income: 30,000 salary + 1,000 housing + 1,500 bonuses + 2,500 commission
+	checkItem(t, it, map[string]float64{
+		"DoctorFee":            0,
+		"TaxMonthAmount":       1234.5,
+		"PFMonthAmount":        0,
+		"IncomeTotal":          35000,
+		"IncomeAccumTotal":     135000,
+		"TaxAccumTotal":        2234.5,
+		"AdvanceAmount":        2000,
+		"LoanOutstandingTotal": 8000,
+		// 750 SSO + 1,234.50 tax + 120 water + 300 electricity + 80 internet + 2,000 advance
+		// + 300 other + 2,000 repayments
+		"DeductionTotal": 6784.5,
+		"NetPay":         28215.5,
+	})
+	if len(it.LoanRepayments) != 2 {
+		t.Errorf("LoanRepayments = %+v, want the installment and the manual line", it.LoanRepayments)
+	}
+}
+
+func TestCalculateProrated(t *testing.T) {
+	c := seededConfig()
+	c.ProrationBasis = ProrationThirtyDay
+	c.PeriodStart, c.PeriodEnd = day("2026-04-01"), day("2026-04-30")
+	e := fullTimer()
+	e.EmploymentStart = day("2026-04-16")
+
+	it := Calculate(c, e, Inputs{})
+	if it.Proration == nil || it.Proration.Days != 15 || it.Proration.PeriodDays != 30 {
+		t.Fatalf("Proration = %+v, want 15 of 30 days", it.Proration)
+	}
+	checkItem(t, it, map[string]float64{
+		"SalaryAmount":    15000,
+		"SSODeclaredWage": 15000,
+		"PFMonthAmount":   450,
+	})
+
+	e.SSODeclaredWage = 17500
+	if got := Calculate(c, e, Inputs{}).SSODeclaredWage; got != 15000 {
+		t.Errorf("prorated SSODeclaredWage = %v, want the prorated salary 15000", got)
+	}
+}
+
+func TestOvertime(t *testing.T) {
+	c := seededConfig()
+	in := Inputs{OtHours: 2, HolidayWorkHours: 8, HolidayOtHours: 2}
+
+	// 24,000 / 30 / 8 = 100 an hour
+	flat := Overtime(c, 24000, in)
+	want := OtBreakdown{WeekdayHours: 2, WeekdayAmount: 120, HolidayWorkHours: 8, HolidayWorkAmount: 800, HolidayOtHours: 2, HolidayOtAmount: 600}
+	if flat != want {
+		t.Errorf("Overtime() = %+v, want %+v", flat, want)
+	}
+
+	c.OtWeekdayMultiplier = ptr(1.5)
+	want.WeekdayAmount = 300
+	if got := Overtime(c, 24000, in); got != want {
+		t.Errorf("Overtime() with a weekday multiplier = %+v, want %+v", got, want)
+	}
+
+	it := Calculate(c, Employee{TypeCode: TypeFullTime, BasePay: 24000}, in)
+	if it.OtHours != 12 || it.OtAmount != 1700 {
+		t.Errorf("OtHours, OtAmount = %v, %v; want 12, 1700", it.OtHours, it.OtAmount)
+	}
+}
+
+func TestProrate(t *testing.T) {
+	end := func(s string) *time.Time { d := day(s); return &d }
+	tests := []struct {
+		name       string
+		basis      string
+		start, end string
+		joined     string
+		left       *time.Time
+		want       *Proration
+	}{
+		{"full month", ProrationThirtyDay, "2026-04-01", "2026-04-30", "2020-01-01", nil, nil},
+		{"joined on the first", ProrationCalendarDays, "2026-01-01", "2026-01-31", "2026-01-01", nil, nil},
+		{"joined mid month, 30-day", ProrationThirtyDay, "2026-04-01", "2026-04-30", "2026-04-16", nil,
+			&Proration{Basis: ProrationThirtyDay, Days: 15, PeriodDays: 30}},
+		{"left mid month, 30-day", ProrationThirtyDay, "2026-04-01", "2026-04-30", "2020-01-01", end("2026-04-10"),
+			&Proration{Basis: ProrationThirtyDay, Days: 10, PeriodDays: 30}},
+		{"default basis is 30-day", "", "2026-04-01", "2026-04-30", "2026-04-16", nil,
+			&Proration{Basis: ProrationThirtyDay, Days: 15, PeriodDays: 30}},
+		// 30-day basis in February and 31-day months: 30 less the days not employed
+		{"joined mid February, 30-day", ProrationThirtyDay, "2026-02-01", "2026-02-28", "2026-02-15", nil,
+			&Proration{Basis: ProrationThirtyDay, Days: 16, PeriodDays: 30}},
+		{"left mid February, 30-day", ProrationThirtyDay, "2026-02-01", "2026-02-28", "2020-01-01", end("2026-02-14"),
+			&Proration{Basis: ProrationThirtyDay, Days: 16, PeriodDays: 30}},
+		{"joined mid February of a leap year, 30-day", ProrationThirtyDay, "2028-02-01", "2028-02-29", "2028-02-15", nil,
+			&Proration{Basis: ProrationThirtyDay, Days: 16, PeriodDays: 30}},
+		{"joined and left in February, 30-day", ProrationThirtyDay, "2026-02-01", "2026-02-28", "2026-02-10", end("2026-02-20"),
+			&Proration{Basis: ProrationThirtyDay, Days: 13, PeriodDays: 30}},
+		{"joined mid January, 30-day", ProrationThirtyDay, "2026-01-01", "2026-01-31", "2026-01-16", nil,
+			&Proration{Basis: ProrationThirtyDay, Days: 15, PeriodDays: 30}},
+		{"joined on the second of January, 30-day", ProrationThirtyDay, "2026-01-01", "2026-01-31", "2026-01-02", nil,
+			&Proration{Basis: ProrationThirtyDay, Days: 29, PeriodDays: 30}},
+		{"joined on the 31st, 30-day", ProrationThirtyDay, "2026-01-01", "2026-01-31", "2026-01-31", nil,
+			&Proration{Basis: ProrationThirtyDay, Days: 1, PeriodDays: 30}},
+		{"joined after the period, 30-day", ProrationThirtyDay, "2026-01-01", "2026-01-31", "2026-02-02", nil,
+			&Proration{Basis: ProrationThirtyDay, Days: 0, PeriodDays: 30}},
+		{"joined mid month, calendar days", ProrationCalendarDays, "2026-01-01", "2026-01-31", "2026-01-16", nil,
+			&Proration{Basis: ProrationCalendarDays, Days: 16, PeriodDays: 31}},
+		{"joined mid month, working days", ProrationWorkingDays, "2026-01-01", "2026-01-31", "2026-01-16", nil,
+			&Proration{Basis: ProrationWorkingDays, Days: 11, PeriodDays: 22}},
+		{"joined and left in the month", ProrationCalendarDays, "2026-01-01", "2026-01-31", "2026-01-05", end("2026-01-09"),
+			&Proration{Basis: ProrationCalendarDays, Days: 5, PeriodDays: 31}},
+		{"joined after the period", ProrationCalendarDays, "2026-01-01", "2026-01-31", "2026-02-02", nil,
+			&Proration{Basis: ProrationCalendarDays, Days: 0, PeriodDays: 31}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := seededConfig()
+			c.ProrationBasis = tt.basis
+			c.PeriodStart, c.PeriodEnd = day(tt.start), day(tt.end)
+			e := fullTimer()
+			e.EmploymentStart, e.EmploymentEnd = day(tt.joined), tt.left
+			if got := Prorate(c, e); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Prorate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if got := Prorate(seededConfig(), fullTimer()); got != nil {
+		t.Errorf("Prorate() without a period = %+v, want nil", got)
+	}
+}
+
+func TestParseLines(t *testing.T) {
+	got := ParseLines([]byte(`[{"name":"a","value":10.5},{"name":"b","value":"20"},{"name":"c","value":"x"},{"name":"d"}]`))
+	want := []Line{{Name: "a", Value: 10.5}, {Name: "b", Value: 20}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseLines() = %+v, want %+v", got, want)
+	}
+	if got := ParseLines(nil); len(got) != 0 {
+		t.Errorf("ParseLines(nil) = %+v, want empty", got)
+	}
+}
+
+func TestRound2(t *testing.T) {
+	for in, want := range map[float64]float64{1.005: 1.01, 2.675: 2.68, -1.005: -1.01, 170.8333: 170.83} {
+		if got := Round2(in); got != want {
+			t.Errorf("Round2(%v) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+// checkItem compares the named float fields of the item.
+func checkItem(t *testing.T, it Item, want map[string]float64) {
+	t.Helper()
+	v := reflect.ValueOf(it)
+	for name, w := range want {
+		if got := v.FieldByName(name).Float(); got != w {
+			t.Errorf("%s = %v, want %v", name, got, w)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"math"
+	"sort"
+)
+
+// Bracket is one band of tax_progressive_brackets; a nil Max is open-ended.
+type Bracket struct {
+	Min  *float64 `json:"min"`
+	Max  *float64 `json:"max"`
+	Rate float64  `json:"rate"`
+}
+
+type TaxConfig struct {
+	ApplyStandardExpense   bool
+	StandardExpenseRate    float64
+	StandardExpenseCap     *float64
+	ApplyPersonalAllowance bool
+	PersonalAllowance      float64
+	Brackets               []Bracket
+	ServiceRate            float64
+}
+
+// ProgressiveTax mirrors calculate_progressive_tax.
+func ProgressiveTax(taxable float64, brackets []Bracket) float64 {
+	if taxable <= 0 || len(brackets) == 0 {
+		return 0
+	}
+	sorted := append([]Bracket(nil), brackets...)
+	sort.SliceStable(sorted, func(i, j int) bool { return deref(sorted[i].Min) < deref(sorted[j].Min) })
+	var tax float64
+	for _, b := range sorted {
+		lo := deref(b.Min)
+		if taxable <= lo {
+			continue
+		}
+		hi := taxable
+		if b.Max != nil {
+			hi = math.Min(taxable, *b.Max)
+		}
+		if slice := hi - lo; slice > 0 {
+			tax += slice * b.Rate
+		}
+	}
+	return Round2(tax)
+}
+
+// TaxAllowance is the employee's declaration for the tax year (employee_tax_allowance).
+type TaxAllowance struct {
+	Spouse                bool
+	ChildrenCount         int
+	ChildrenBorn2018Count int
+	ParentsCount          int
+	LifeInsurance         float64
+	HealthInsurance       float64
+	SSFAmount             float64
+	RMFAmount             float64
+	ProvidentFundAmount   float64
+	HomeLoanInterest      float64
+}
+
+// Deduction mirrors tax_allowance_deduction: each item capped by law, the retirement savings
+// (SSF, RMF, provident fund) together capped at 500,000.
+func (a TaxAllowance) Deduction(annualIncome float64) float64 {
+	income := math.Max(annualIncome, 0)
+	var d float64
+	if a.Spouse {
+		d += 60000
+	}
+	d += 30000 * float64(a.ChildrenCount)
+	d += 30000 * float64(min(a.ChildrenBorn2018Count, max(a.ChildrenCount-1, 0)))
+	d += 30000 * float64(min(a.ParentsCount, 4))
+	d += math.Min(math.Min(a.LifeInsurance, 100000)+math.Min(a.HealthInsurance, 25000), 100000)
+	retirement := math.Min(a.SSFAmount, math.Min(income*0.30, 200000)) +
+		math.Min(a.RMFAmount, math.Min(income*0.30, 500000)) +
+		math.Min(a.ProvidentFundAmount, 10000) +
+		math.Min(math.Max(a.ProvidentFundAmount-10000, 0), math.Min(income*0.15, 490000))
+	d += math.Min(retirement, 500000)
+	d += math.Min(a.HomeLoanInterest, 100000)
+	return d
+}
+
+// WithholdingTax mirrors calculate_withholding_tax: employees outside social security are taxed
+// at the flat service rate, everyone else on twelve times the month less expense, allowances
+// and SSO, spread back over twelve months. extraAllowance is the employee's own annual deduction.
+func WithholdingTax(t TaxConfig, monthlyIncome float64, withhold, ssoContribute bool, ssoRate, ssoCap, ssoBase, extraAllowance float64) float64 {
+	if !withhold {
+		return 0
+	}
+	if !ssoContribute {
+		return Round2(monthlyIncome * t.ServiceRate)
+	}
+	ssoMonth := math.Min(ssoBase, ssoCap) * ssoRate
+	annual := monthlyIncome * 12
+	var expense, allowance float64
+	if t.ApplyStandardExpense {
+		expense = annual * t.StandardExpenseRate
+		if t.StandardExpenseCap != nil {
+			expense = math.Min(expense, *t.StandardExpenseCap)
+		}
+	}
+	if t.ApplyPersonalAllowance {
+		allowance = t.PersonalAllowance
+	}
+	allowance += math.Max(extraAllowance, 0)
+	taxable := math.Max(annual-expense-allowance-ssoMonth*12, 0)
+	return Round2(ProgressiveTax(taxable, t.Brackets) / 12.0)
+}
+
+func deref(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
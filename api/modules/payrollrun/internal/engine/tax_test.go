@@ -0,0 +1,162 @@
+package engine
+
+import (
+	"testing"
+)
+
+func ptr(v float64) *float64 { return &v }
+
+// seededBrackets are the tax_progressive_brackets every company is seeded with.
+func seededBrackets() []Bracket {
+	return []Bracket{
+		{Min: ptr(0), Max: ptr(150000), Rate: 0},
+		{Min: ptr(150000), Max: ptr(300000), Rate: 0.05},
+		{Min: ptr(300000), Max: ptr(500000), Rate: 0.10},
+		{Min: ptr(500000), Max: ptr(750000), Rate: 0.15},
+		{Min: ptr(750000), Max: ptr(1000000), Rate: 0.20},
+		{Min: ptr(1000000), Max: ptr(2000000), Rate: 0.25},
+		{Min: ptr(2000000), Max: ptr(5000000), Rate: 0.30},
+		{Min: ptr(5000000), Max: nil, Rate: 0.35},
+	}
+}
+
+// seededTax is the tax part of the seeded payroll_config.
+func seededTax() TaxConfig {
+	return TaxConfig{
+		ApplyStandardExpense:   true,
+		StandardExpenseRate:    0.50,
+		StandardExpenseCap:     ptr(100000),
+		ApplyPersonalAllowance: true,
+		PersonalAllowance:      60000,
+		Brackets:               seededBrackets(),
+		ServiceRate:            0.03,
+	}
+}
+
+func TestProgressiveTax(t *testing.T) {
+	tests := []struct {
+		taxable float64
+		want    float64
+	}{
+		{-1000, 0},
+		{0, 0},
+		{150000, 0},
+		{150001, 0.05},
+		{300000, 7500},
+		{500000, 27500},
+		{750000, 65000},
+		{1000000, 115000},
+		{2000000, 365000},
+		{5000000, 1265000},
+		{6000000, 1615000},
+	}
+	for _, tt := range tests {
+		if got := ProgressiveTax(tt.taxable, seededBrackets()); got != tt.want {
+			t.Errorf("ProgressiveTax(%v) = %v, want %v", tt.taxable, got, tt.want)
+		}
+	}
+
+	reversed := seededBrackets()
+	for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+		reversed[i], reversed[j] = reversed[j], reversed[i]
+	}
+	if got := ProgressiveTax(1000000, reversed); got != 115000 {
+		t.Errorf("ProgressiveTax() with unordered brackets = %v, want 115000", got)
+	}
+	if got := ProgressiveTax(1000000, nil); got != 0 {
+		t.Errorf("ProgressiveTax() without brackets = %v, want 0", got)
+	}
+}
+
+func TestTaxAllowanceDeduction(t *testing.T) {
+	tests := []struct {
+		name   string
+		a      TaxAllowance
+		income float64
+		want   float64
+	}{
+		{"none", TaxAllowance{}, 600000, 0},
+		{
+			name: "family, insurance, savings and home loan",
+			a: TaxAllowance{
+				Spouse:                true,
+				ChildrenCount:         2,
+				ChildrenBorn2018Count: 2, // only from the second child
+				ParentsCount:          2,
+				LifeInsurance:         120000,
+				HealthInsurance:       30000,
+				SSFAmount:             250000,
+				ProvidentFundAmount:   50000,
+				HomeLoanInterest:      120000,
+			},
+			income: 1000000,
+			// 60,000 + 60,000 + 30,000 + 60,000 + 100,000 + (200,000 + 10,000 + 40,000) + 100,000
+			want: 660000,
+		},
+		{"parents capped at 4", TaxAllowance{ParentsCount: 6}, 600000, 120000},
+		{"health insurance capped at 25,000", TaxAllowance{HealthInsurance: 40000}, 600000, 25000},
+		{"SSF capped at 30% of income", TaxAllowance{SSFAmount: 100000}, 200000, 60000},
+		{"retirement savings capped at 500,000", TaxAllowance{SSFAmount: 200000, RMFAmount: 500000}, 2000000, 500000},
+		{"provident fund over 10,000 capped at 15% of income", TaxAllowance{ProvidentFundAmount: 100000}, 400000, 70000},
+		{"negative income", TaxAllowance{SSFAmount: 100000, Spouse: true}, -5000, 60000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.a.Deduction(tt.income); got != tt.want {
+				t.Errorf("Deduction() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithholdingTax(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     TaxConfig
+		income  float64
+		hold    bool
+		sso     bool
+		ssoBase float64
+		extra   float64
+		want    float64
+	}{
+		// 360,000 - 100,000 expense - 60,000 personal - 9,000 SSO = 191,000 taxable
+		{"30,000 a month", seededTax(), 30000, true, true, 15000, 0, 170.83},
+		// 600,000 - 100,000 - 60,000 - 10,500 = 429,500 taxable
+		{"50,000 a month, SSO at the cap", seededTax(), 50000, true, true, 17500, 0, 1704.17},
+		{"SSO base over the cap", seededTax(), 50000, true, true, 30000, 0, 1704.17},
+		{"employee allowances", seededTax(), 50000, true, true, 17500, 100000, 870.83},
+		{"negative allowance ignored", seededTax(), 50000, true, true, 17500, -100000, 1704.17},
+		{"below the taxable threshold", seededTax(), 20000, true, true, 15000, 0, 0},
+		{"outside social security at the service rate", seededTax(), 20000, true, false, 0, 0, 600},
+		{"not withheld", seededTax(), 50000, false, true, 17500, 0, 0},
+		{
+			name:    "no standard expense or personal allowance",
+			cfg:     TaxConfig{Brackets: seededBrackets()},
+			income:  30000,
+			hold:    true,
+			sso:     true,
+			ssoBase: 15000,
+			// 360,000 - 9,000 = 351,000 taxable: 7,500 + 5,100
+			want: 1050,
+		},
+		{
+			name:    "uncapped standard expense",
+			cfg:     TaxConfig{ApplyStandardExpense: true, StandardExpenseRate: 0.5, Brackets: seededBrackets()},
+			income:  100000,
+			hold:    true,
+			sso:     true,
+			ssoBase: 17500,
+			// 1,200,000 - 600,000 - 10,500 = 589,500 taxable: 27,500 + 13,425
+			want: 3410.42,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WithholdingTax(tt.cfg, tt.income, tt.hold, tt.sso, 0.05, 17500, tt.ssoBase, tt.extra)
+			if got != tt.want {
+				t.Errorf("WithholdingTax() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package approve
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/payrollrun/internal/dto"
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/storage/sqldb/transactor"
+	"hrms/shared/common/validator"
+	"hrms/shared/contracts"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	ID      uuid.UUID `json:"-" validate:"required"`
+	Comment string    `json:"comment" validate:"max=1000"`
+}
+
+type Response struct {
+	dto.Run
+	Approval *contracts.ApprovalRequestDTO `json:"approval,omitempty"`
+	Message  string                        `json:"message"`
+}
+
+type Handler struct {
+	repo repository.Repository
+	tx   transactor.Transactor
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, tx transactor.Transactor, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, tx: tx, eb: eb}
+}
+
+// Handle approves a run. Without an approval chain an admin approves a pending run directly, as
+// before. With a chain the run must be submitted first; each call approves the current step and
+// the run itself is approved when the last step is. Either way whoever created the run or edited
+// its items cannot approve it.
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	cmd.Comment = strings.TrimSpace(cmd.Comment)
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+
+	run, err := h.repo.Get(ctx, tenant, cmd.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("payroll run not found")
+		}
+		logger.FromContext(ctx).Error("failed to load payroll run", zap.Error(err))
+		return nil, errs.Internal("failed to load payroll run")
+	}
+	if run.Status != repository.StatusPending && run.Status != repository.StatusSubmitted {
+		return nil, errs.BadRequest(run.Status + " run cannot be approved")
+	}
+
+	preparers, err := h.repo.ListPreparers(ctx, run.ID)
+	if err != nil || len(preparers) == 0 {
+		logger.FromContext(ctx).Error("failed to load payroll run preparers", zap.Error(err))
+		return nil, errs.Internal("failed to load payroll run")
+	}
+
+	var (
+		updated  *repository.Run
+		decision *contracts.DecideApprovalResponse
+	)
+	err = h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
+		var err error
+		decision, err = mediator.Send[*contracts.DecideApprovalCommand, *contracts.DecideApprovalResponse](ctxTx, &contracts.DecideApprovalCommand{
+			CompanyID:        run.CompanyID,
+			BranchID:         &run.BranchID,
+			DocType:          contracts.ApprovalDocPayrollRun,
+			DocID:            run.ID,
+			PreparedBy:       preparers[0],
+			Preparers:        preparers,
+			ActorID:          user.ID,
+			ActorRole:        user.Role,
+			Approve:          true,
+			Comment:          cmd.Comment,
+			RequireSubmitted: true,
+		})
+		if err != nil {
+			return err
+		}
+		if !decision.Required && user.Role != "admin" {
+			return errs.Forbidden("only admin can approve payroll run")
+		}
+		if decision.Required && !decision.Final {
+			updated = run
+			return nil
+		}
+		updated, err = h.repo.Approve(ctxTx, tenant, run.ID, user.ID)
+		return err
+	})
+	if err != nil {
+		var appErr *errs.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.BadRequest("cannot approve payroll run")
+		}
+		logger.FromContext(ctx).Error("failed to approve payroll run", zap.Error(err))
+		return nil, errs.Internal("failed to approve payroll run")
+	}
+
+	details := map[string]interface{}{"status": updated.Status}
+	if decision.Request != nil {
+		details["approval_request_id"] = decision.Request.ID.String()
+		details["step"] = stepNo(decision.Request)
+	}
+	if cmd.Comment != "" {
+		details["comment"] = cmd.Comment
+	}
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "APPROVE",
+		EntityName: "PAYROLL_RUN",
+		EntityID:   updated.ID.String(),
+		Details:    details,
+		Timestamp:  time.Now(),
+	})
+
+	resp := &Response{Run: dto.FromRun(*updated), Approval: decision.Request}
+	if updated.Status == "approved" {
+		resp.Message = "Payroll approved successfully. All related records updated."
+	} else {
+		resp.Message = fmt.Sprintf("Step %d approved. Waiting for step %d of %d.",
+			decision.Request.CurrentStep-1, decision.Request.CurrentStep, decision.Request.StepCount)
+	}
+	return resp, nil
+}
+
+// stepNo is the step this call decided: the last one acted on.
+func stepNo(req *contracts.ApprovalRequestDTO) int {
+	n := 0
+	for _, s := range req.Steps {
+		if s.ActedAt != nil {
+			n = s.StepNo
+		}
+	}
+	return n
+}
@@ -0,0 +1,68 @@
+package bankexport
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+)
+
+// @Summary Export bank transfer file
+// @Description สร้างไฟล์โอนเงินเดือนเข้าบัญชีพนักงาน (KTB, SCB, KBANK, BBL) จากงวดที่อนุมัติแล้ว บัญชีต้นทางใช้บัญชีสาขาก่อน แล้วจึงใช้บัญชีกลาง
+// @Tags Payroll Run
+// @Produce text/plain
+// @Produce text/csv
+// @Security BearerAuth
+// @Param id path string true "run id"
+// @Param bank query string true "bank format: KTB, SCB, KBANK, BBL"
+// @Param accountId query string false "company bank account id (overrides automatic selection)"
+// @Param effectiveDate query string false "transfer date (YYYY-MM-DD), defaults to run pay date"
+// @Success 200 {file} binary
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 422
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /payroll-runs/{id}/bank-file [get]
+func NewEndpoint(router fiber.Router) {
+	router.Get("/:id/bank-file", func(c fiber.Ctx) error {
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return errs.BadRequest("invalid id")
+		}
+		q := &Query{RunID: id, Bank: c.Query("bank")}
+		if raw := c.Query("accountId"); raw != "" {
+			accountID, err := uuid.Parse(raw)
+			if err != nil {
+				return errs.BadRequest("invalid accountId")
+			}
+			q.AccountID = &accountID
+		}
+		if raw := c.Query("effectiveDate"); raw != "" {
+			d, err := time.Parse("2006-01-02", raw)
+			if err != nil {
+				return errs.BadRequest("effectiveDate must be YYYY-MM-DD")
+			}
+			q.EffectiveDate = &d
+		}
+		resp, err := mediator.Send[*Query, *Response](c.Context(), q)
+		if err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, resp.ContentType)
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"%s\"", resp.FileName))
+		c.Set(fiber.HeaderContentLength, strconv.Itoa(len(resp.Data)))
+		c.Set(fiber.HeaderCacheControl, "private, no-store")
+		c.Set("X-Transfer-Count", strconv.Itoa(resp.TransferCount))
+		c.Set("X-Transfer-Total", strconv.FormatFloat(resp.TotalAmount, 'f', 2, 64))
+		c.Set("X-Debit-Account", resp.DebitAccount)
+		return c.Send(resp.Data)
+	})
+}
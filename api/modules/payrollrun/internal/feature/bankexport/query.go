@@ -0,0 +1,164 @@
+package bankexport
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/payrollrun/internal/bankfile"
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/validator"
+)
+
+type Query struct {
+	RunID         uuid.UUID
+	Bank          string `validate:"required"`
+	AccountID     *uuid.UUID
+	EffectiveDate *time.Time
+}
+
+type Response struct {
+	FileName      string
+	ContentType   string
+	Data          []byte
+	TransferCount int
+	TotalAmount   float64
+	DebitAccount  string
+}
+
+// invalidRow is reported back when an item cannot be paid by transfer.
+type invalidRow struct {
+	EmployeeNumber string `json:"employeeNumber"`
+	EmployeeName   string `json:"employeeName"`
+	Reason         string `json:"reason"`
+}
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*Query, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) Handle(ctx context.Context, q *Query) (*Response, error) {
+	if err := validator.Validate(q); err != nil {
+		return nil, err
+	}
+	format, ok := bankfile.Lookup(q.Bank)
+	if !ok {
+		return nil, errs.BadRequest("unsupported bank format", map[string]interface{}{"supported": bankfile.Codes()})
+	}
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+
+	run, err := h.repo.Get(ctx, tenant, q.RunID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("payroll run not found")
+		}
+		logger.FromContext(ctx).Error("failed to load payroll run", zap.Error(err))
+		return nil, errs.Internal("failed to load payroll run")
+	}
+	if run.Status != "approved" {
+		return nil, errs.Unprocessable("payroll run must be approved before exporting bank file")
+	}
+
+	account, err := h.repo.FindDebitAccount(ctx, run.CompanyID, run.BranchID, format.Code(), q.AccountID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.Unprocessable(fmt.Sprintf("no active company bank account for %s", format.Code()))
+		}
+		logger.FromContext(ctx).Error("failed to load company bank account", zap.Error(err))
+		return nil, errs.Internal("failed to load company bank account")
+	}
+	if !strings.EqualFold(account.BankCode, format.Code()) {
+		return nil, errs.BadRequest(fmt.Sprintf("company bank account is not a %s account", format.Code()))
+	}
+	widths := format.AccountWidths()
+	if !bankfile.Fits(account.AccountNumber, widths.Debit) {
+		return nil, errs.Unprocessable(fmt.Sprintf("company bank account number is longer than the %d digits of the %s file", widths.Debit, format.Code()))
+	}
+
+	rows, err := h.repo.ListTransfers(ctx, tenant, run.ID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load payroll transfers", zap.Error(err))
+		return nil, errs.Internal("failed to load payroll transfers")
+	}
+
+	effective := run.PayDate
+	if q.EffectiveDate != nil {
+		effective = *q.EffectiveDate
+	}
+	batch := bankfile.Batch{
+		CompanyName:      run.OrgProfile().CompanyName,
+		DebitBankCode:    account.BankCode,
+		DebitAccountNo:   account.AccountNumber,
+		DebitAccountName: account.AccountName,
+		EffectiveDate:    effective,
+	}
+	if batch.CompanyName == "" {
+		batch.CompanyName = account.AccountName
+	}
+
+	var invalid []invalidRow
+	for _, row := range rows {
+		if row.NetPay <= 0 {
+			continue
+		}
+		bankCode := repository.Deref(row.BankCode)
+		switch {
+		case bankfile.Digits(repository.Deref(row.BankAccountNo)) == "":
+			invalid = append(invalid, invalidRow{row.EmployeeNumber, row.EmployeeName, "missing bank account number"})
+			continue
+		case bankfile.BOTCode(bankCode) == "":
+			invalid = append(invalid, invalidRow{row.EmployeeNumber, row.EmployeeName, "unknown bank " + repository.Deref(row.BankName)})
+			continue
+		case !bankfile.Fits(repository.Deref(row.BankAccountNo), widths.Credit):
+			invalid = append(invalid, invalidRow{row.EmployeeNumber, row.EmployeeName, fmt.Sprintf("bank account number is longer than %d digits", widths.Credit)})
+			continue
+		}
+		batch.Transfers = append(batch.Transfers, bankfile.Transfer{
+			Reference:   row.EmployeeNumber,
+			Name:        row.EmployeeName,
+			BankCode:    bankCode,
+			AccountNo:   repository.Deref(row.BankAccountNo),
+			Amount:      row.NetPay,
+			Description: "Salary " + run.PayrollMonth.Format("01/2006"),
+		})
+	}
+	if len(invalid) > 0 {
+		return nil, errs.Unprocessable("some employees cannot be paid by bank transfer", invalid)
+	}
+	if len(batch.Transfers) == 0 {
+		return nil, errs.Unprocessable("payroll run has no net pay to transfer")
+	}
+
+	var buf bytes.Buffer
+	if err := format.Write(&buf, batch); err != nil {
+		return nil, errs.Unprocessable(err.Error())
+	}
+	return &Response{
+		FileName:      fmt.Sprintf("payroll-%s-%s.%s", strings.ToLower(format.Code()), run.PayrollMonth.Format("2006-01"), format.FileExtension()),
+		ContentType:   format.ContentType(),
+		Data:          buf.Bytes(),
+		TransferCount: len(batch.Transfers),
+		TotalAmount:   float64(batch.TotalSatang()) / 100,
+		DebitAccount:  account.AccountNumber,
+	}, nil
+}
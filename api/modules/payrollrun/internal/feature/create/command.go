@@ -6,6 +6,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"go.uber.org/zap"
 
@@ -27,6 +28,11 @@ type Command struct {
 	PayDateRaw      string  `json:"payDate" validate:"required"`
 	SSORateEmp      float64 `json:"socialSecurityRateEmployee" validate:"gte=0"`
 	SSORateEmployer float64 `json:"socialSecurityRateEmployer" validate:"gte=0"`
+	// RunType defaults to regular. off_cycle and correction runs start with the employees listed
+	// in EmployeeIDs; bonus_only picks up everyone with an approved bonus for the month.
+	RunType     string      `json:"runType" validate:"omitempty,oneof=regular off_cycle bonus_only correction"`
+	Note        *string     `json:"note" validate:"omitempty,max=500"`
+	EmployeeIDs []uuid.UUID `json:"employeeIds" validate:"omitempty,max=500,dive,required"`
 }
 
 type Response struct {
@@ -81,24 +87,66 @@ func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
 		return nil, errs.BadRequest("sso rates must be positive")
 	}
 
+	runType := cmd.RunType
+	if runType == "" {
+		runType = repository.RunTypeRegular
+	}
+	manualItems := runType == repository.RunTypeOffCycle || runType == repository.RunTypeCorrection
+	if len(cmd.EmployeeIDs) > 0 && !manualItems {
+		return nil, errs.BadRequest("employeeIds can only be given for off_cycle or correction runs")
+	}
+	var note *string
+	if cmd.Note != nil {
+		if v := strings.TrimSpace(*cmd.Note); v != "" {
+			note = &v
+		}
+	}
+
 	run := repository.Run{
 		PayrollMonth:    payrollMonth,
 		PeriodStart:     periodStart,
 		PayDate:         payDate,
 		SSORateEmp:      cmd.SSORateEmp,
 		SSORateEmployer: cmd.SSORateEmployer,
+		RunType:         runType,
+		Note:            note,
 	}
 
 	var created *repository.Run
 	if err := h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
 		var err error
 		created, err = h.repo.Create(ctxTx, run, tenant.CompanyID, tenant.BranchID, user.ID)
-		return err
+		if err != nil {
+			return err
+		}
+		for _, employeeID := range cmd.EmployeeIDs {
+			added, err := h.repo.AddItem(ctxTx, *created, employeeID, user.ID)
+			if err != nil {
+				return err
+			}
+			if !added {
+				return errs.BadRequest("employee " + employeeID.String() + " is not in this branch or is listed twice")
+			}
+		}
+		if runType == repository.RunTypeBonusOnly {
+			n, err := h.repo.CountItems(ctxTx, created.ID)
+			if err != nil {
+				return err
+			}
+			if n == 0 {
+				return errs.Unprocessable("no approved bonus left to pay for this month")
+			}
+		}
+		return nil
 	}); err != nil {
+		var appErr *errs.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
 		logger.FromContext(ctx).Error("failed to create payroll run", zap.Error(err))
 		var pqErr *pq.Error
 		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
-			return nil, errs.Conflict("payroll run for this branch and month already exists")
+			return nil, errs.Conflict("regular payroll run for this branch and month already exists")
 		}
 		return nil, errs.Internal("failed to create payroll run")
 	}
@@ -112,6 +160,8 @@ func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
 		EntityID:   created.ID.String(),
 		Details: map[string]interface{}{
 			"payroll_month": created.PayrollMonth.Format("2006-01-02"),
+			"run_type":      created.RunType,
+			"employees":     len(cmd.EmployeeIDs),
 		},
 		Timestamp: time.Now(),
 	})
@@ -0,0 +1,240 @@
+package create
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/storage/sqldb/dbtest"
+	"hrms/shared/common/storage/sqldb/transactor"
+)
+
+// runFixture is a branch of the test company with a create handler acting as the admin.
+type runFixture struct {
+	d        *dbtest.DB
+	ctx      context.Context
+	tenant   contextx.TenantInfo
+	repo     repository.Repository
+	h        *Handler
+	branchID uuid.UUID
+}
+
+func newRunFixture(ctx context.Context, t *testing.T, d *dbtest.DB, db transactor.DBTX, code string) runFixture {
+	t.Helper()
+	branchID := d.InsertBranch(ctx, t, db, code)
+	ctx = contextx.WithUser(d.BranchContext(ctx, branchID), contextx.UserInfo{ID: d.AdminID, Username: "admin", Role: "admin"})
+	tenant, _ := contextx.TenantFromContext(ctx)
+	repo := repository.NewRepository(d.DBTX)
+	return runFixture{d: d, ctx: ctx, tenant: tenant, repo: repo, h: NewHandler(repo, d.Tx, eventbus.NewInMemory()), branchID: branchID}
+}
+
+// create opens a run of the given type for dbtest.Month.
+func (f runFixture) create(runType string, employeeIDs ...uuid.UUID) (uuid.UUID, error) {
+	resp, err := f.h.Handle(f.ctx, &Command{
+		PayrollMonthRaw: "2099-01-01",
+		PeriodStartRaw:  "2099-01-01",
+		PayDateRaw:      "2099-01-31",
+		SSORateEmp:      0.05,
+		SSORateEmployer: 0.05,
+		RunType:         runType,
+		EmployeeIDs:     employeeIDs,
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return resp.ID, nil
+}
+
+func (f runFixture) mustCreate(t *testing.T, runType string, employeeIDs ...uuid.UUID) uuid.UUID {
+	t.Helper()
+	id, err := f.create(runType, employeeIDs...)
+	if err != nil {
+		t.Fatalf("create %s run: %v", runType, err)
+	}
+	return id
+}
+
+type runItem struct {
+	Salary     float64 `db:"salary_amount"`
+	Bonus      float64 `db:"bonus_amount"`
+	Income     float64 `db:"income_total"`
+	SSO        float64 `db:"sso_month_amount"`
+	Tax        float64 `db:"tax_month_amount"`
+	SSOPrev    float64 `db:"sso_accum_prev"`
+	TaxPrev    float64 `db:"tax_accum_prev"`
+	IncomePrev float64 `db:"income_accum_prev"`
+}
+
+// items returns the run's items by employee.
+func (f runFixture) items(t *testing.T, db transactor.DBTX, runID uuid.UUID) map[uuid.UUID]runItem {
+	t.Helper()
+	var rows []struct {
+		EmployeeID uuid.UUID `db:"employee_id"`
+		runItem
+	}
+	if err := db.SelectContext(f.ctx, &rows, `
+SELECT employee_id, salary_amount, bonus_amount, income_total, sso_month_amount, tax_month_amount,
+       sso_accum_prev, tax_accum_prev, income_accum_prev
+FROM payroll_run_item
+WHERE run_id = $1`, runID); err != nil {
+		t.Fatalf("load run items: %v", err)
+	}
+	out := make(map[uuid.UUID]runItem, len(rows))
+	for _, r := range rows {
+		out[r.EmployeeID] = r.runItem
+	}
+	return out
+}
+
+// TestCreateGeneratesItemsByRunType checks which employees each run type starts with: a
+// regular run takes the whole branch, a bonus-only run takes those with an approved bonus and
+// moves the bonus off the regular run, and off-cycle runs take only the listed employees. It
+// needs a migrated database in TEST_DB_DSN; the fixtures are rolled back.
+func TestCreateGeneratesItemsByRunType(t *testing.T) {
+	d := dbtest.Open(t)
+	d.Rollback(t, func(ctx context.Context, db transactor.DBTX) {
+		f := newRunFixture(ctx, t, d, db, "RUNTYPE-TEST")
+		withBonus := d.InsertEmployee(f.ctx, t, db, f.branchID, dbtest.Employee{Number: "RUNTYPE-TEST-001", BasePay: 30000, SSOWage: 15000})
+		noBonus := d.InsertEmployee(f.ctx, t, db, f.branchID, dbtest.Employee{Number: "RUNTYPE-TEST-002", BasePay: 25000, SSOWage: 15000})
+
+		// the cycle starts with an item per full-timer of the branch; only one gets a bonus
+		var cycleID uuid.UUID
+		if err := db.GetContext(f.ctx, &cycleID, `
+INSERT INTO bonus_cycle (payroll_month_date, period_start_date, period_end_date, bonus_year, company_id, branch_id, created_by, updated_by)
+VALUES ($1, '2098-01-01', '2098-12-31', 2098, $2, $3, $4, $4)
+RETURNING id`, dbtest.Month, d.CompanyID, f.branchID, d.AdminID); err != nil {
+			t.Fatalf("insert bonus cycle: %v", err)
+		}
+		if _, err := db.ExecContext(f.ctx, `UPDATE bonus_item SET bonus_amount = 20000 WHERE cycle_id = $1 AND employee_id = $2`, cycleID, withBonus); err != nil {
+			t.Fatalf("set bonus: %v", err)
+		}
+		if _, err := db.ExecContext(f.ctx, `UPDATE bonus_cycle SET status = 'approved' WHERE id = $1`, cycleID); err != nil {
+			t.Fatalf("approve bonus cycle: %v", err)
+		}
+
+		regularID := f.mustCreate(t, repository.RunTypeRegular)
+		regular := f.items(t, db, regularID)
+		if len(regular) != 2 || regular[withBonus].Bonus != 20000 || regular[noBonus].Salary != 25000 {
+			t.Fatalf("regular items = %+v, want both employees with the bonus on the first", regular)
+		}
+		if _, err := f.create(repository.RunTypeRegular); !isCode(err, errs.CodeConflict) {
+			t.Errorf("second regular run: error = %v, want conflict", err)
+		}
+
+		bonusID := f.mustCreate(t, repository.RunTypeBonusOnly)
+		bonus := f.items(t, db, bonusID)
+		if got, ok := bonus[withBonus]; len(bonus) != 1 || !ok || got.Bonus != 20000 || got.Salary != 0 || got.SSO != 0 {
+			t.Errorf("bonus-only items = %+v, want the bonus alone without salary or SSO", bonus)
+		}
+		if got := f.items(t, db, regularID)[withBonus]; got.Bonus != 0 || got.Salary != 30000 {
+			t.Errorf("regular item after the bonus-only run = %+v, want the salary without the bonus", got)
+		}
+
+		offCycleID := f.mustCreate(t, repository.RunTypeOffCycle, noBonus)
+		if offCycle := f.items(t, db, offCycleID); len(offCycle) != 1 || offCycle[noBonus] != (runItem{}) {
+			t.Errorf("off-cycle items = %+v, want an empty item for the listed employee only", offCycle)
+		}
+		if _, err := f.create(repository.RunTypeCorrection, noBonus, uuid.New()); !isCode(err, errs.CodeBadRequest) {
+			t.Errorf("correction run with an unknown employee: error = %v, want bad request", err)
+		}
+		var runs int
+		if err := db.GetContext(f.ctx, &runs, `SELECT count(*) FROM payroll_run WHERE branch_id = $1 AND deleted_at IS NULL`, f.branchID); err != nil {
+			t.Fatalf("count runs: %v", err)
+		}
+		if runs != 3 {
+			t.Errorf("branch has %d runs, want 3 (the failed ones rolled back)", runs)
+		}
+	})
+}
+
+// TestSupplementaryRunSharesMonthlyCapsAndAccumulation pays an off-cycle amount next to the
+// regular run and checks that the two runs stay within one month's SSO ceiling and that
+// approving both adds each run's SSO, tax and income to the year's accumulation once. It needs
+// a migrated database in TEST_DB_DSN; the fixtures are rolled back.
+func TestSupplementaryRunSharesMonthlyCapsAndAccumulation(t *testing.T) {
+	d := dbtest.Open(t)
+	d.Rollback(t, func(ctx context.Context, db transactor.DBTX) {
+		f := newRunFixture(ctx, t, d, db, "OFFCYCLE-TEST")
+		employeeID := d.InsertEmployee(f.ctx, t, db, f.branchID, dbtest.Employee{
+			Number: "OFFCYCLE-TEST-001", BasePay: 30000, SSOWage: 10000, WithholdTax: true,
+		})
+		var wageCap float64
+		if err := db.GetContext(f.ctx, &wageCap, `
+SELECT LEAST(COALESCE(social_security_wage_cap, 17500), 17500)
+FROM payroll_config
+WHERE company_id = $1 AND effective_daterange @> $2::date
+ORDER BY lower(effective_daterange) DESC, version_no DESC
+LIMIT 1`, d.CompanyID, dbtest.Month); err != nil {
+			t.Fatalf("load SSO wage cap: %v", err)
+		}
+		monthCap := math.Round(wageCap*0.05*100) / 100
+
+		regularID := f.mustCreate(t, repository.RunTypeRegular)
+		offCycleID := f.mustCreate(t, repository.RunTypeOffCycle, employeeID)
+		if _, err := db.ExecContext(f.ctx, `UPDATE payroll_run_item SET salary_amount = 20000 WHERE run_id = $1 AND employee_id = $2`, offCycleID, employeeID); err != nil {
+			t.Fatalf("enter off-cycle salary: %v", err)
+		}
+		if err := f.repo.Recalculate(f.ctx, offCycleID, employeeID); err != nil {
+			t.Fatalf("recalculate off-cycle item: %v", err)
+		}
+
+		regular := f.items(t, db, regularID)[employeeID]
+		offCycle := f.items(t, db, offCycleID)[employeeID]
+		if regular.SSO != 500 {
+			t.Fatalf("regular SSO = %v, want 500 on a declared wage of 10000", regular.SSO)
+		}
+		// the off-cycle salary alone reaches the ceiling; it only takes what the regular run left
+		if want := monthCap - 500; offCycle.SSO != want {
+			t.Errorf("off-cycle SSO = %v, want %v", offCycle.SSO, want)
+		}
+		if offCycle.Income != 20000 || offCycle.Tax <= 0 {
+			t.Errorf("off-cycle item = %+v, want income 20000 with tax withheld", offCycle)
+		}
+
+		approve := func(runID uuid.UUID) {
+			t.Helper()
+			if _, err := f.repo.Approve(f.ctx, f.tenant, runID, d.AdminID); err != nil {
+				t.Fatalf("approve run: %v", err)
+			}
+		}
+		approve(offCycleID)
+
+		// the pending regular run is recalculated on top of the off-cycle postings
+		regular = f.items(t, db, regularID)[employeeID]
+		if regular.SSO != 500 || regular.SSOPrev != offCycle.SSO || regular.TaxPrev != offCycle.Tax || regular.IncomePrev != offCycle.Income {
+			t.Errorf("regular item after the off-cycle approval = %+v, want SSO 500 and the off-cycle amounts carried", regular)
+		}
+		approve(regularID)
+
+		year := dbtest.Month.Year()
+		want := map[string]float64{
+			"sso":    monthCap,
+			"tax":    offCycle.Tax + regular.Tax,
+			"income": offCycle.Income + regular.Income,
+		}
+		for accumType, amount := range want {
+			var got float64
+			if err := db.GetContext(f.ctx, &got, `
+SELECT COALESCE(SUM(amount), 0)
+FROM payroll_accumulation
+WHERE employee_id = $1 AND accum_type = $2 AND accum_year = $3`, employeeID, accumType, year); err != nil {
+				t.Fatalf("load %s accumulation: %v", accumType, err)
+			}
+			if math.Abs(got-amount) > 0.001 {
+				t.Errorf("%s accumulation = %v, want %v", accumType, got, amount)
+			}
+		}
+	})
+}
+
+func isCode(err error, code errs.ErrorCode) bool {
+	var appErr *errs.AppError
+	return errors.As(err, &appErr) && appErr.Code == code
+}
@@ -9,7 +9,7 @@ import (
 )
 
 // @Summary Create payroll run
-// @Description สร้างงวดจ่ายเงินเดือน (มีได้ 1 งวดต่อสาขาต่อเดือน)
+// @Description สร้างงวดจ่ายเงินเดือน งวดปกติ (regular) มีได้ 1 งวดต่อสาขาต่อเดือน ส่วนงวดเสริม (off_cycle, bonus_only, correction) สร้างเพิ่มในเดือนเดียวกันได้
 // @Tags Payroll Run
 // @Accept json
 // @Produce json
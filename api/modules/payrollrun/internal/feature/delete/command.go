@@ -56,8 +56,8 @@ func (h *Handler) Handle(ctx context.Context, cmd *Command) (mediator.NoResponse
 		logger.FromContext(ctx).Error("failed to load payroll run", zap.Error(err))
 		return mediator.NoResponse{}, errs.Internal("failed to load payroll run")
 	}
-	if run.Status == "approved" {
-		return mediator.NoResponse{}, errs.BadRequest("cannot delete approved run")
+	if run.Status != "pending" {
+		return mediator.NoResponse{}, errs.BadRequest("cannot delete " + run.Status + " run")
 	}
 	if err := h.repo.SoftDelete(ctx, tenant, cmd.ID, user.ID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
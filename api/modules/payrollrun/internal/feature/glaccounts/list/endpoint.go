@@ -0,0 +1,29 @@
+package glaccountslist
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// @Summary Get GL account mapping
+// @Description ผังบัญชีที่ใช้สร้างสมุดรายวันเงินเดือน (ค่าเริ่มต้นของบริษัท และรหัสบัญชีเฉพาะแผนก) พร้อมรายการบัญชีที่ต้องตั้ง
+// @Tags Payroll Run
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response
+// @Failure 401
+// @Failure 403
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /gl-accounts [get]
+func NewEndpoint(router fiber.Router) {
+	router.Get("/", func(c fiber.Ctx) error {
+		resp, err := mediator.Send[*Query, *Response](c.Context(), &Query{})
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
@@ -0,0 +1,63 @@
+package glaccountslist
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"hrms/modules/payrollrun/internal/dto"
+	gl "hrms/modules/payrollrun/internal/journal"
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+)
+
+type Query struct{}
+
+// AccountKey describes one journal account and the side it is posted on.
+type AccountKey struct {
+	Key  string `json:"key"`
+	Side string `json:"side"`
+}
+
+type Response struct {
+	Keys     []AccountKey    `json:"keys"`
+	Mappings []dto.GLAccount `json:"mappings"`
+}
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*Query, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) Handle(ctx context.Context, _ *Query) (*Response, error) {
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	rows, err := h.repo.ListGLAccounts(ctx, tenant.CompanyID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load GL account mapping", zap.Error(err))
+		return nil, errs.Internal("failed to load GL account mapping")
+	}
+	return &Response{Keys: accountKeys(), Mappings: dto.FromGLAccounts(rows)}, nil
+}
+
+func accountKeys() []AccountKey {
+	keys := make([]AccountKey, 0, len(gl.Keys))
+	for _, k := range gl.Keys {
+		side := "credit"
+		if gl.IsDebit(k) {
+			side = "debit"
+		}
+		keys = append(keys, AccountKey{Key: k, Side: side})
+	}
+	return keys
+}
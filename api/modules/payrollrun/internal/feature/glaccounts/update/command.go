@@ -0,0 +1,128 @@
+package glaccountsupdate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/payrollrun/internal/dto"
+	gl "hrms/modules/payrollrun/internal/journal"
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/storage/sqldb/transactor"
+	"hrms/shared/common/validator"
+	"hrms/shared/events"
+)
+
+type Mapping struct {
+	AccountKey   string     `json:"accountKey" validate:"required"`
+	DepartmentID *uuid.UUID `json:"departmentId"`
+	AccountCode  string     `json:"accountCode" validate:"required,max=50"`
+	AccountName  string     `json:"accountName" validate:"max=200"`
+}
+
+type Command struct {
+	Mappings []Mapping `json:"mappings" validate:"max=500,dive"`
+}
+
+type Response struct {
+	Mappings []dto.GLAccount `json:"mappings"`
+}
+
+type Handler struct {
+	repo repository.Repository
+	tx   transactor.Transactor
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, tx transactor.Transactor, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, tx: tx, eb: eb}
+}
+
+// Handle replaces the company's whole chart-of-accounts mapping.
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	for i := range cmd.Mappings {
+		m := &cmd.Mappings[i]
+		m.AccountKey = strings.TrimSpace(m.AccountKey)
+		m.AccountCode = strings.TrimSpace(m.AccountCode)
+		m.AccountName = strings.TrimSpace(m.AccountName)
+	}
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+
+	seen := map[string]bool{}
+	accounts := make([]repository.GLAccount, 0, len(cmd.Mappings))
+	for _, m := range cmd.Mappings {
+		if !gl.IsKey(m.AccountKey) {
+			return nil, errs.BadRequest("unknown accountKey "+m.AccountKey, map[string]interface{}{"supported": gl.Keys})
+		}
+		if m.DepartmentID != nil && !gl.IsDebit(m.AccountKey) {
+			return nil, errs.BadRequest(m.AccountKey + " is posted per company and cannot be mapped per department")
+		}
+		id := m.AccountKey
+		if m.DepartmentID != nil {
+			id += "|" + m.DepartmentID.String()
+		}
+		if seen[id] {
+			return nil, errs.BadRequest(fmt.Sprintf("duplicate mapping for %s", m.AccountKey))
+		}
+		seen[id] = true
+		accounts = append(accounts, repository.GLAccount{
+			AccountKey:   m.AccountKey,
+			DepartmentID: m.DepartmentID,
+			AccountCode:  m.AccountCode,
+			AccountName:  m.AccountName,
+		})
+	}
+
+	var rows []repository.GLAccount
+	err := h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
+		if err := h.repo.ReplaceGLAccounts(ctxTx, tenant.CompanyID, accounts, user.ID); err != nil {
+			return err
+		}
+		var err error
+		rows, err = h.repo.ListGLAccounts(ctxTx, tenant.CompanyID)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrUnknownDepartment) {
+			return nil, errs.BadRequest("departmentId not found")
+		}
+		logger.FromContext(ctx).Error("failed to save GL account mapping", zap.Error(err))
+		return nil, errs.Internal("failed to save GL account mapping")
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "UPDATE",
+		EntityName: "GL_ACCOUNT_MAPPING",
+		EntityID:   tenant.CompanyID.String(),
+		Details:    map[string]interface{}{"mappings": len(accounts)},
+		Timestamp:  time.Now(),
+	})
+
+	return &Response{Mappings: dto.FromGLAccounts(rows)}, nil
+}
@@ -0,0 +1,37 @@
+package glaccountsupdate
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// @Summary Replace GL account mapping
+// @Description ตั้งผังบัญชีทั้งชุดของบริษัท (แทนที่ของเดิม) บัญชีค่าใช้จ่ายระบุรหัสเฉพาะแผนกได้ (admin only)
+// @Tags Payroll Run
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body Command true "payload"
+// @Success 200 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /gl-accounts [put]
+func NewEndpoint(router fiber.Router) {
+	router.Put("/", func(c fiber.Ctx) error {
+		var req Command
+		if err := c.Bind().Body(&req); err != nil {
+			return errs.BadRequest("invalid request body")
+		}
+		resp, err := mediator.Send[*Command, *Response](c.Context(), &req)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
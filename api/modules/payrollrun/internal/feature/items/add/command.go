@@ -0,0 +1,115 @@
+package itemsadd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/payrollrun/internal/dto"
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/storage/sqldb/transactor"
+	"hrms/shared/common/validator"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	RunID       uuid.UUID   `json:"-" validate:"required"`
+	EmployeeIDs []uuid.UUID `json:"employeeIds" validate:"required,min=1,max=500,dive,required"`
+}
+
+type Response struct {
+	dto.Run
+	Added int `json:"added"`
+}
+
+type Handler struct {
+	repo repository.Repository
+	tx   transactor.Transactor
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, tx transactor.Transactor, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, tx: tx, eb: eb}
+}
+
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+
+	run, err := h.repo.Get(ctx, tenant, cmd.RunID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("payroll run not found")
+		}
+		logger.FromContext(ctx).Error("failed to load payroll run", zap.Error(err))
+		return nil, errs.Internal("failed to load payroll run")
+	}
+	if run.Status != "pending" {
+		return nil, errs.BadRequest("only pending run can be adjusted")
+	}
+	// regular and bonus_only runs pick their employees themselves
+	if run.RunType != repository.RunTypeOffCycle && run.RunType != repository.RunTypeCorrection {
+		return nil, errs.BadRequest("employees can only be added to or removed from off_cycle and correction runs")
+	}
+
+	added := 0
+	if err := h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
+		for _, employeeID := range cmd.EmployeeIDs {
+			ok, err := h.repo.AddItem(ctxTx, *run, employeeID, user.ID)
+			if err != nil {
+				return err
+			}
+			if ok {
+				added++
+			}
+		}
+		return nil
+	}); err != nil {
+		logger.FromContext(ctx).Error("failed to add payroll run items", zap.Error(err))
+		return nil, errs.Internal("failed to add employees to payroll run")
+	}
+
+	updated, err := h.repo.Get(ctx, tenant, run.ID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to reload payroll run", zap.Error(err))
+		return nil, errs.Internal("failed to load payroll run")
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "ADD_ITEMS",
+		EntityName: "PAYROLL_RUN",
+		EntityID:   run.ID.String(),
+		Details: map[string]interface{}{
+			"run_type":     run.RunType,
+			"employee_ids": cmd.EmployeeIDs,
+			"added":        added,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return &Response{Run: dto.FromRun(*updated), Added: added}, nil
+}
@@ -0,0 +1,57 @@
+package itemsexport
+
+import (
+	"bufio"
+	"fmt"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+)
+
+// @Summary Export payroll run items as a spreadsheet
+// @Description ส่งออกรายการเงินเดือนทุกคนในงวดเป็นไฟล์ XLSX หรือ CSV ครบทุกคอลัมน์รายได้/รายการหัก แตกรายได้อื่น รายการหักอื่น และเงินกู้เป็นคอลัมน์ตามชื่อ พร้อมยอดรวมรายแผนก รายสาขา และยอดรวมทั้งงวด
+// @Tags Payroll Run
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce text/csv
+// @Security BearerAuth
+// @Param id path string true "run id"
+// @Param format query string false "xlsx (default) or csv"
+// @Success 200 {file} binary
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /payroll-runs/{id}/items/export [get]
+func NewEndpoint(router fiber.Router) {
+	router.Get("/:id/items/export", func(c fiber.Ctx) error {
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return errs.BadRequest("invalid id")
+		}
+		resp, err := mediator.Send[*Query, *Response](c.Context(), &Query{RunID: id, Format: c.Query("format", FormatXLSX)})
+		if err != nil {
+			return err
+		}
+		ctx := c.Context()
+		c.Set(fiber.HeaderContentType, resp.ContentType)
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"%s\"", resp.FileName))
+		c.Set(fiber.HeaderCacheControl, "private, no-store")
+		// rows are streamed straight from the cursor, so the length is unknown up front
+		return c.SendStreamWriter(func(w *bufio.Writer) {
+			if err := resp.Write(w); err != nil {
+				logger.FromContext(ctx).Error("payroll item export aborted", zap.Error(err))
+				return
+			}
+			if err := w.Flush(); err != nil {
+				logger.FromContext(ctx).Warn("failed to flush payroll item export", zap.Error(err))
+			}
+		})
+	})
+}
@@ -0,0 +1,98 @@
+package itemsexport
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/modules/payrollrun/internal/xlsx"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/validator"
+)
+
+const (
+	FormatXLSX = "xlsx"
+	FormatCSV  = "csv"
+)
+
+type Query struct {
+	RunID  uuid.UUID `validate:"required"`
+	Format string    `validate:"required,oneof=xlsx csv"`
+}
+
+// Response carries the file metadata; Write streams the rows and is called by the endpoint
+// once the headers are sent.
+type Response struct {
+	FileName    string
+	ContentType string
+	Write       func(w io.Writer) error
+}
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*Query, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) Handle(ctx context.Context, q *Query) (*Response, error) {
+	q.Format = strings.ToLower(strings.TrimSpace(q.Format))
+	if err := validator.Validate(q); err != nil {
+		return nil, err
+	}
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+
+	run, err := h.repo.Get(ctx, tenant, q.RunID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("payroll run not found")
+		}
+		logger.FromContext(ctx).Error("failed to load payroll run", zap.Error(err))
+		return nil, errs.Internal("failed to load payroll run")
+	}
+	extra, err := h.repo.ListExportColumns(ctx, tenant, run.ID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load payroll item columns", zap.Error(err))
+		return nil, errs.Internal("failed to load payroll items")
+	}
+
+	sheet := "Payroll " + run.PayrollMonth.Format("2006-01")
+	contentType := xlsx.ContentType
+	if q.Format == FormatCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+	return &Response{
+		FileName:    fmt.Sprintf("payroll-items-%s-%s.%s", run.PayrollMonth.Format("2006-01"), run.RunType, q.Format),
+		ContentType: contentType,
+		Write: func(w io.Writer) error {
+			out, err := newSheetWriter(w, q.Format, sheet)
+			if err != nil {
+				return err
+			}
+			stream := func(fn func(repository.ExportItem) error) error {
+				return h.repo.StreamExportItems(ctx, tenant, run.ID, fn)
+			}
+			if err := writeTable(stream, newLayout(extra), out); err != nil {
+				logger.FromContext(ctx).Error("failed to export payroll items", zap.Error(err))
+				return err
+			}
+			return out.Close()
+		},
+	}, nil
+}
@@ -0,0 +1,276 @@
+package itemsexport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/modules/payrollrun/internal/xlsx"
+)
+
+// column is one numeric column. Rates are not summed on subtotal rows.
+type column struct {
+	header string
+	value  func(it repository.ExportItem, ex expanded) float64
+	count  bool // whole number (minutes)
+	noSum  bool
+}
+
+// expanded holds an item's JSON arrays summed per name.
+type expanded struct {
+	income    map[string]float64
+	deduction map[string]float64
+	loan      map[string]float64
+}
+
+var labelHeaders = []string{
+	"Branch", "Department", "Employee No", "Employee Name", "Employee Type", "Position", "Bank", "Account No",
+}
+
+func newLayout(extra repository.ExportColumns) []column {
+	f := func(header string, v func(it repository.ExportItem) float64) column {
+		return column{header: header, value: func(it repository.ExportItem, _ expanded) float64 { return v(it) }}
+	}
+	cols := []column{
+		f("Salary", func(it repository.ExportItem) float64 { return it.SalaryAmount }),
+		f("PT Hours", func(it repository.ExportItem) float64 { return it.PTHoursWorked }),
+		{header: "PT Hourly Rate", noSum: true, value: func(it repository.ExportItem, _ expanded) float64 { return it.PTHourlyRate }},
+		f("OT Hours", func(it repository.ExportItem) float64 { h, _ := it.WeekdayOt(); return h }),
+		f("OT Amount", func(it repository.ExportItem) float64 { _, a := it.WeekdayOt(); return a }),
+		f("Holiday Work Hours", func(it repository.ExportItem) float64 { return it.HolidayWorkHours }),
+		f("Holiday Work Amount", func(it repository.ExportItem) float64 { return it.HolidayWorkAmount }),
+		f("Holiday OT Hours", func(it repository.ExportItem) float64 { return it.HolidayOtHours }),
+		f("Holiday OT Amount", func(it repository.ExportItem) float64 { return it.HolidayOtAmount }),
+		f("Housing Allowance", func(it repository.ExportItem) float64 { return it.HousingAllowance }),
+		f("Attendance Bonus (No Late)", func(it repository.ExportItem) float64 { return it.AttendanceBonusNoLate }),
+		f("Attendance Bonus (No Leave)", func(it repository.ExportItem) float64 { return it.AttendanceBonusNoLeave }),
+		f("Bonus", func(it repository.ExportItem) float64 { return it.BonusAmount }),
+		f("Leave Compensation", func(it repository.ExportItem) float64 { return it.LeaveCompensationAmount }),
+		f("Doctor Fee", func(it repository.ExportItem) float64 { return it.DoctorFee }),
+	}
+	for _, name := range extra.OthersIncome {
+		name := name
+		cols = append(cols, column{header: "Other Income: " + name, value: func(_ repository.ExportItem, ex expanded) float64 { return ex.income[name] }})
+	}
+	cols = append(cols,
+		f("Income Total", func(it repository.ExportItem) float64 { return it.IncomeTotal }),
+		f("Leave Days", func(it repository.ExportItem) float64 { return it.LeaveDaysQty }),
+		f("Leave Days Deduction", func(it repository.ExportItem) float64 { return it.LeaveDaysDeduction }),
+		f("Leave Double Days", func(it repository.ExportItem) float64 { return it.LeaveDoubleQty }),
+		f("Leave Double Deduction", func(it repository.ExportItem) float64 { return it.LeaveDoubleDeduction }),
+		f("Leave Hours", func(it repository.ExportItem) float64 { return it.LeaveHoursQty }),
+		f("Leave Hours Deduction", func(it repository.ExportItem) float64 { return it.LeaveHoursDeduction }),
+		column{header: "Late Minutes", count: true, value: func(it repository.ExportItem, _ expanded) float64 { return float64(it.LateMinutesQty) }},
+		f("Late Deduction", func(it repository.ExportItem) float64 { return it.LateMinutesDeduction }),
+		f("Social Security", func(it repository.ExportItem) float64 { return it.SsoMonthAmount }),
+		f("Withholding Tax", func(it repository.ExportItem) float64 { return it.TaxMonthAmount }),
+		f("Provident Fund", func(it repository.ExportItem) float64 { return it.PfMonthAmount }),
+		f("Water", func(it repository.ExportItem) float64 { return it.WaterAmount }),
+		f("Electricity", func(it repository.ExportItem) float64 { return it.ElectricAmount }),
+		f("Internet", func(it repository.ExportItem) float64 { return it.InternetAmount }),
+		f("Advance Repayment", func(it repository.ExportItem) float64 { return it.AdvanceRepayAmount }),
+	)
+	for _, name := range extra.OthersDeduction {
+		name := name
+		cols = append(cols, column{header: "Other Deduction: " + name, value: func(_ repository.ExportItem, ex expanded) float64 { return ex.deduction[name] }})
+	}
+	for _, name := range extra.LoanRepayments {
+		name := name
+		cols = append(cols, column{header: "Loan Repayment: " + name, value: func(_ repository.ExportItem, ex expanded) float64 { return ex.loan[name] }})
+	}
+	return append(cols,
+		f("Deduction Total", func(it repository.ExportItem) float64 { return it.DeductionTotal }),
+		f("Net Pay", func(it repository.ExportItem) float64 { return it.NetPay }),
+	)
+}
+
+// subtotal accumulates a department, branch or the whole run.
+type subtotal struct {
+	label     string
+	branch    string
+	dept      string
+	employees int
+	sums      []float64
+}
+
+func newSubtotal(label, branch, dept string, n int) *subtotal {
+	return &subtotal{label: label, branch: branch, dept: dept, sums: make([]float64, n)}
+}
+
+func (s *subtotal) add(values []float64) {
+	s.employees++
+	for i, v := range values {
+		s.sums[i] += v
+	}
+}
+
+// itemStream calls fn for each item of the run, ordered by branch and department.
+type itemStream func(fn func(repository.ExportItem) error) error
+
+// writeTable streams the items with a subtotal row after each department and branch and a
+// grand total at the end.
+func writeTable(stream itemStream, cols []column, out sheetWriter) error {
+	header := make([]interface{}, 0, len(labelHeaders)+len(cols))
+	for _, h := range labelHeaders {
+		header = append(header, h)
+	}
+	for _, c := range cols {
+		header = append(header, c.header)
+	}
+	if err := out.WriteRow(true, header...); err != nil {
+		return err
+	}
+
+	var (
+		branchID uuid.UUID
+		branch   *subtotal
+		dept     *subtotal
+		grand    = newSubtotal("Grand Total", "", "", len(cols))
+	)
+	flush := func(s *subtotal) error {
+		if s == nil {
+			return nil
+		}
+		return out.WriteRow(true, totalRow(s, cols)...)
+	}
+
+	err := stream(func(it repository.ExportItem) error {
+		if branch == nil || it.BranchID != branchID {
+			if err := flush(dept); err != nil {
+				return err
+			}
+			if err := flush(branch); err != nil {
+				return err
+			}
+			branchID = it.BranchID
+			branch = newSubtotal("Branch Total", it.BranchName, "", len(cols))
+			dept = newSubtotal("Department Total", it.BranchName, it.DepartmentName, len(cols))
+		} else if it.DepartmentName != dept.dept {
+			if err := flush(dept); err != nil {
+				return err
+			}
+			dept = newSubtotal("Department Total", it.BranchName, it.DepartmentName, len(cols))
+		}
+
+		ex := expanded{income: sumByName(it.OthersIncome), deduction: sumByName(it.OthersDeduction), loan: sumByName(it.LoanRepayments)}
+		values := make([]float64, len(cols))
+		for i, c := range cols {
+			values[i] = c.value(it, ex)
+		}
+		dept.add(values)
+		branch.add(values)
+		grand.add(values)
+
+		row := []interface{}{
+			it.BranchName, it.DepartmentName, it.EmployeeNumber, it.EmployeeName,
+			it.EmployeeTypeName, it.PositionName, it.BankName, it.BankAccountNo,
+		}
+		for i, c := range cols {
+			row = append(row, cell(c, values[i]))
+		}
+		return out.WriteRow(false, row...)
+	})
+	if err != nil {
+		return err
+	}
+	if err := flush(dept); err != nil {
+		return err
+	}
+	if err := flush(branch); err != nil {
+		return err
+	}
+	return flush(grand)
+}
+
+func totalRow(s *subtotal, cols []column) []interface{} {
+	row := []interface{}{s.branch, s.dept, "", fmt.Sprintf("%s (%d employees)", s.label, s.employees), "", "", "", ""}
+	for i, c := range cols {
+		if c.noSum {
+			row = append(row, nil)
+			continue
+		}
+		row = append(row, cell(c, s.sums[i]))
+	}
+	return row
+}
+
+func cell(c column, v float64) interface{} {
+	if c.count {
+		return int(v)
+	}
+	return v
+}
+
+// sumByName reads a [{name,value}] array; value may be a number or a numeric string.
+func sumByName(raw []byte) map[string]float64 {
+	out := map[string]float64{}
+	if len(raw) == 0 {
+		return out
+	}
+	var entries []struct {
+		Name  string          `json:"name"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return out
+	}
+	for _, e := range entries {
+		name := strings.TrimSpace(e.Name)
+		if name == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(strings.Trim(string(bytes.TrimSpace(e.Value)), `"`), 64)
+		if err != nil {
+			continue
+		}
+		out[name] += v
+	}
+	return out
+}
+
+type sheetWriter interface {
+	WriteRow(bold bool, cells ...interface{}) error
+	Close() error
+}
+
+func newSheetWriter(w io.Writer, format, sheetName string) (sheetWriter, error) {
+	if format == FormatCSV {
+		// BOM so Excel opens the Thai names as UTF-8
+		if _, err := io.WriteString(w, "\uFEFF"); err != nil {
+			return nil, err
+		}
+		return &csvWriter{cw: csv.NewWriter(w)}, nil
+	}
+	return xlsx.NewWriter(w, sheetName)
+}
+
+type csvWriter struct {
+	cw *csv.Writer
+}
+
+func (c *csvWriter) WriteRow(_ bool, cells ...interface{}) error {
+	record := make([]string, len(cells))
+	for i, v := range cells {
+		switch v := v.(type) {
+		case string:
+			record[i] = v
+		case float64:
+			record[i] = strconv.FormatFloat(v, 'f', 2, 64)
+		case int:
+			record[i] = strconv.Itoa(v)
+		}
+	}
+	return c.cw.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.cw.Flush()
+	return c.cw.Error()
+}
@@ -0,0 +1,97 @@
+package itemsexport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/payrollrun/internal/repository"
+)
+
+func TestWriteTableCSVSubtotals(t *testing.T) {
+	north, south := uuid.New(), uuid.New()
+	item := func(branchID uuid.UUID, branch, dept, number string, salary, rate float64, late int, tips string) repository.ExportItem {
+		it := repository.ExportItem{
+			BranchID: branchID, BranchName: branch, DepartmentName: dept, EmployeeNumber: number, EmployeeName: "Employee " + number,
+			SalaryAmount: salary, PTHourlyRate: rate, LateMinutesQty: late, NetPay: salary,
+		}
+		if tips != "" {
+			it.OthersIncome = []byte(`[{"name":"Tips","value":` + tips + `}]`)
+		}
+		return it
+	}
+	items := []repository.ExportItem{
+		item(north, "North", "Kitchen", "E001", 15000, 0, 10, `"150.50"`), // value as a numeric string
+		item(north, "North", "Kitchen", "E002", 12000, 0, 5, `49.5`),
+		item(north, "North", "Service", "E003", 9000, 60, 0, ""),
+		item(south, "South", "", "E004", 20000, 0, 0, `100`),
+	}
+	stream := func(fn func(repository.ExportItem) error) error {
+		for _, it := range items {
+			if err := fn(it); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	out, err := newSheetWriter(&buf, FormatCSV, "Payroll")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeTable(stream, newLayout(repository.ExportColumns{OthersIncome: []string{"Tips"}}), out); err != nil {
+		t.Fatalf("writeTable() error = %v", err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	raw := buf.String()
+	if !strings.HasPrefix(raw, "\ufeff") {
+		t.Error("CSV does not start with a UTF-8 BOM")
+	}
+	rows, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(raw, "\ufeff"))).ReadAll()
+	if err != nil {
+		t.Fatalf("read CSV: %v", err)
+	}
+	col := func(name string) int {
+		i := slices.Index(rows[0], name)
+		if i < 0 {
+			t.Fatalf("no %q column in %v", name, rows[0])
+		}
+		return i
+	}
+	name, salary, rate, late, tips, net := col("Employee Name"), col("Salary"), col("PT Hourly Rate"), col("Late Minutes"), col("Other Income: Tips"), col("Net Pay")
+
+	type want struct{ name, salary, rate, late, tips, net string }
+	wants := []want{
+		{"Employee E001", "15000.00", "0.00", "10", "150.50", "15000.00"},
+		{"Employee E002", "12000.00", "0.00", "5", "49.50", "12000.00"},
+		{"Department Total (2 employees)", "27000.00", "", "15", "200.00", "27000.00"},
+		{"Employee E003", "9000.00", "60.00", "0", "0.00", "9000.00"},
+		{"Department Total (1 employees)", "9000.00", "", "0", "0.00", "9000.00"},
+		{"Branch Total (3 employees)", "36000.00", "", "15", "200.00", "36000.00"},
+		{"Employee E004", "20000.00", "0.00", "0", "100.00", "20000.00"},
+		{"Department Total (1 employees)", "20000.00", "", "0", "100.00", "20000.00"},
+		{"Branch Total (1 employees)", "20000.00", "", "0", "100.00", "20000.00"},
+		{"Grand Total (4 employees)", "56000.00", "", "15", "300.00", "56000.00"},
+	}
+	if len(rows) != len(wants)+1 {
+		t.Fatalf("CSV has %d rows, want a header and %d rows", len(rows), len(wants))
+	}
+	for i, w := range wants {
+		r := rows[i+1]
+		got := want{r[name], r[salary], r[rate], r[late], r[tips], r[net]}
+		if got != w {
+			t.Errorf("row %d = %+v, want %+v", i+1, got, w)
+		}
+	}
+	if rows[3][0] != "North" || rows[3][1] != "Kitchen" {
+		t.Errorf("department total is labelled %q / %q, want North / Kitchen", rows[3][0], rows[3][1])
+	}
+}
@@ -0,0 +1,91 @@
+package itemsremove
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/validator"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	RunID      uuid.UUID `validate:"required"`
+	EmployeeID uuid.UUID `validate:"required"`
+}
+
+type Handler struct {
+	repo repository.Repository
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, mediator.NoResponse] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, eb: eb}
+}
+
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (mediator.NoResponse, error) {
+	if err := validator.Validate(cmd); err != nil {
+		return mediator.NoResponse{}, err
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return mediator.NoResponse{}, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return mediator.NoResponse{}, errs.Unauthorized("missing user context")
+	}
+
+	run, err := h.repo.Get(ctx, tenant, cmd.RunID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return mediator.NoResponse{}, errs.NotFound("payroll run not found")
+		}
+		logger.FromContext(ctx).Error("failed to load payroll run", zap.Error(err))
+		return mediator.NoResponse{}, errs.Internal("failed to load payroll run")
+	}
+	if run.Status != "pending" {
+		return mediator.NoResponse{}, errs.BadRequest("only pending run can be adjusted")
+	}
+	if run.RunType != repository.RunTypeOffCycle && run.RunType != repository.RunTypeCorrection {
+		return mediator.NoResponse{}, errs.BadRequest("employees can only be added to or removed from off_cycle and correction runs")
+	}
+
+	removed, err := h.repo.RemoveItem(ctx, *run, cmd.EmployeeID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to remove payroll run item", zap.Error(err))
+		return mediator.NoResponse{}, errs.Internal("failed to remove employee from payroll run")
+	}
+	if !removed {
+		return mediator.NoResponse{}, errs.NotFound("employee is not on this payroll run")
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "REMOVE_ITEM",
+		EntityName: "PAYROLL_RUN",
+		EntityID:   run.ID.String(),
+		Details: map[string]interface{}{
+			"run_type":    run.RunType,
+			"employee_id": cmd.EmployeeID.String(),
+		},
+		Timestamp: time.Now(),
+	})
+
+	return mediator.NoResponse{}, nil
+}
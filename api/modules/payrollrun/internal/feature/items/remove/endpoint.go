@@ -0,0 +1,43 @@
+package itemsremove
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+)
+
+// @Summary Remove an employee from an off-cycle run
+// @Description เอาพนักงานออกจากงวดเสริม (off_cycle / correction) ที่ยังรออนุมัติ
+// @Tags Payroll Run
+// @Security BearerAuth
+// @Param id path string true "run id"
+// @Param employeeId path string true "employee id"
+// @Success 204 "No Content"
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /payroll-runs/{id}/items/{employeeId} [delete]
+func NewEndpoint(router fiber.Router) {
+	router.Delete("/:id/items/:employeeId", func(c fiber.Ctx) error {
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return errs.BadRequest("invalid id")
+		}
+		employeeID, err := uuid.Parse(c.Params("employeeId"))
+		if err != nil {
+			return errs.BadRequest("invalid employeeId")
+		}
+		if _, err := mediator.Send[*Command, mediator.NoResponse](c.Context(), &Command{
+			RunID:      id,
+			EmployeeID: employeeID,
+		}); err != nil {
+			return err
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+}
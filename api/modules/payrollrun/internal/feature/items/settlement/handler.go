@@ -0,0 +1,95 @@
+package itemssettlement
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/contracts"
+)
+
+// Handler posts an offboarded employee's final settlement to a pending off-cycle run.
+// It runs inside the caller's transaction, so a failure undoes the offboarding as well.
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*contracts.PostSettlementCommand, *contracts.PostSettlementResponse] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+type line struct {
+	Name      string     `json:"name"`
+	Value     float64    `json:"value"`
+	TxnID     *uuid.UUID `json:"txn_id,omitempty"`
+	TaxExempt float64    `json:"tax_exempt,omitempty"`
+}
+
+func (h *Handler) Handle(ctx context.Context, cmd *contracts.PostSettlementCommand) (*contracts.PostSettlementResponse, error) {
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+
+	run, err := h.repo.Get(ctx, tenant, cmd.RunID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("payroll run not found")
+		}
+		return nil, err
+	}
+	if run.Status != "pending" {
+		return nil, errs.BadRequest("only pending run can be adjusted")
+	}
+	if run.RunType != repository.RunTypeOffCycle {
+		return nil, errs.BadRequest("final settlement can only be posted to an off_cycle run")
+	}
+
+	added, err := h.repo.AddItem(ctx, *run, cmd.EmployeeID, cmd.ActorID)
+	if err != nil {
+		return nil, err
+	}
+	if !added {
+		return nil, errs.Conflict("employee is already on the payroll run or not in its branch")
+	}
+	itemID, err := h.repo.FindItemID(ctx, run.ID, cmd.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := map[string]interface{}{
+		"others_income":    marshalLines(cmd.Incomes),
+		"others_deduction": marshalLines(cmd.Deductions),
+		"loan_repayments":  marshalLines(cmd.LoanRepayments),
+	}
+	if _, err := h.repo.UpdateItem(ctx, tenant, itemID, cmd.ActorID, fields); err != nil {
+		return nil, err
+	}
+	// SSO, PF and tax follow the settlement amounts
+	if err := h.repo.Recalculate(ctx, run.ID, cmd.EmployeeID); err != nil {
+		return nil, err
+	}
+	item, err := h.repo.GetItem(ctx, tenant, itemID)
+	if err != nil {
+		return nil, err
+	}
+	return &contracts.PostSettlementResponse{ItemID: item.ID, NetPay: item.NetPay}, nil
+}
+
+func marshalLines(lines []contracts.SettlementLine) []byte {
+	out := make([]line, 0, len(lines))
+	for _, l := range lines {
+		out = append(out, line{Name: l.Name, Value: l.Value, TxnID: l.TxnID, TaxExempt: l.TaxExempt})
+	}
+	b, _ := json.Marshal(out)
+	return b
+}
@@ -96,6 +96,17 @@ func (h *updateHandler) Handle(ctx context.Context, cmd *UpdateCommand) (*Update
 		return nil, errs.BadRequest("only pending run can be adjusted")
 	}
 
+	// Supplementary runs carry no worklog, advance or utility lines; the recalculation would reset them.
+	if run.RunType != repository.RunTypeRegular {
+		p := cmd.Payload
+		if p.LateMinutesQty != nil || p.LateMinutesDeduction != nil || p.AdvanceRepayAmount != nil ||
+			p.WaterMeterPrev != nil || p.WaterMeterCurr != nil || p.WaterAmount != nil ||
+			p.ElectricMeterPrev != nil || p.ElectricMeterCurr != nil || p.ElectricAmount != nil ||
+			p.InternetAmount != nil {
+			return nil, errs.BadRequest("late, advance and utility amounts belong to the regular run")
+		}
+	}
+
 	// Validate employee settings to prevent overriding disabled benefits/deductions.
 	if !item.AllowWater && (cmd.Payload.WaterMeterPrev != nil || cmd.Payload.WaterMeterCurr != nil || cmd.Payload.WaterAmount != nil) {
 		return nil, errs.Conflict("water charges are not allowed for this employee")
@@ -226,6 +237,14 @@ func (h *updateHandler) Handle(ctx context.Context, cmd *UpdateCommand) (*Update
 	if err := h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
 		var err error
 		updated, err = h.repo.UpdateItem(ctxTx, tenant, cmd.ID, user.ID, fields)
+		if err != nil || run.RunType == repository.RunTypeRegular {
+			return err
+		}
+		// supplementary runs derive SSO, PF and tax from the amounts just entered
+		if err := h.repo.Recalculate(ctxTx, run.ID, item.EmployeeID); err != nil {
+			return err
+		}
+		updated, err = h.repo.GetItem(ctxTx, tenant, cmd.ID)
 		return err
 	}); err != nil {
 		logger.FromContext(ctx).Error("failed to update payroll item", zap.Error(err))
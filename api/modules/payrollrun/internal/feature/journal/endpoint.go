@@ -0,0 +1,59 @@
+package journal
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+)
+
+// @Summary Export payroll GL journal
+// @Description สร้างสมุดรายวันบันทึกบัญชีเงินเดือน (เดบิตค่าใช้จ่าย/เครดิตหนี้สิน) ของงวดที่อนุมัติแล้ว ตามผังบัญชีที่ตั้งไว้ เป็นไฟล์ CSV หรือ JSON งวดที่ถูกยกเลิก (reversed) จะได้รายการกลับบัญชี
+// @Tags Payroll Run
+// @Produce text/csv
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "run id"
+// @Param format query string false "csv (default) or json"
+// @Param splitByDepartment query bool false "แยกบรรทัดค่าใช้จ่ายตามแผนก"
+// @Success 200 {file} binary
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 422
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /payroll-runs/{id}/journal [get]
+func NewEndpoint(router fiber.Router) {
+	router.Get("/:id/journal", func(c fiber.Ctx) error {
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return errs.BadRequest("invalid id")
+		}
+		q := &Query{RunID: id, Format: c.Query("format", FormatCSV)}
+		if raw := c.Query("splitByDepartment"); raw != "" {
+			split, err := strconv.ParseBool(raw)
+			if err != nil {
+				return errs.BadRequest("splitByDepartment must be true or false")
+			}
+			q.SplitByDepartment = split
+		}
+		resp, err := mediator.Send[*Query, *Response](c.Context(), q)
+		if err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, resp.ContentType)
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"%s\"", resp.FileName))
+		c.Set(fiber.HeaderContentLength, strconv.Itoa(len(resp.Data)))
+		c.Set(fiber.HeaderCacheControl, "private, no-store")
+		c.Set("X-Journal-Reference", resp.Reference)
+		c.Set("X-Journal-Lines", strconv.Itoa(resp.LineCount))
+		c.Set("X-Journal-Total", strconv.FormatFloat(resp.TotalDebit, 'f', 2, 64))
+		return c.Send(resp.Data)
+	})
+}
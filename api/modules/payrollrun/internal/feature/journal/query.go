@@ -0,0 +1,181 @@
+package journal
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	gl "hrms/modules/payrollrun/internal/journal"
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/modules/payrollrun/internal/ssofile"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/validator"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+type Query struct {
+	RunID             uuid.UUID `validate:"required"`
+	Format            string    `validate:"required,oneof=csv json"`
+	SplitByDepartment bool
+}
+
+type Response struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+	Reference   string
+	LineCount   int
+	TotalDebit  float64
+}
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*Query, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+// Handle builds the GL journal of an approved run. A reversed run gives the reversing entry,
+// dated when it was reversed, so it can be posted against the original.
+func (h *Handler) Handle(ctx context.Context, q *Query) (*Response, error) {
+	q.Format = strings.ToLower(strings.TrimSpace(q.Format))
+	if err := validator.Validate(q); err != nil {
+		return nil, err
+	}
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+
+	run, err := h.repo.Get(ctx, tenant, q.RunID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("payroll run not found")
+		}
+		logger.FromContext(ctx).Error("failed to load payroll run", zap.Error(err))
+		return nil, errs.Internal("failed to load payroll run")
+	}
+	if run.Status != "approved" && run.Status != "reversed" {
+		return nil, errs.Unprocessable("payroll run must be approved before exporting journal")
+	}
+
+	settings, err := h.repo.GetSSOSettings(ctx, *run)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load SSO settings", zap.Error(err))
+		return nil, errs.Internal("failed to load SSO settings")
+	}
+	items, err := h.repo.ListJournalItems(ctx, tenant, run.ID, settings.WageCap)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load journal items", zap.Error(err))
+		return nil, errs.Internal("failed to load payroll items")
+	}
+	if len(items) == 0 {
+		return nil, errs.Unprocessable("payroll run has no items")
+	}
+	accounts, err := h.repo.ListGLAccounts(ctx, run.CompanyID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load GL account mapping", zap.Error(err))
+		return nil, errs.Internal("failed to load GL account mapping")
+	}
+
+	chart := gl.NewChart()
+	for _, a := range accounts {
+		chart.Set(a.AccountKey, a.DepartmentID, gl.Account{Code: a.AccountCode, Name: a.AccountName})
+	}
+
+	header := gl.Header{
+		Reference:   fmt.Sprintf("PAY-%s-%s", run.PayrollMonth.Format("200601"), strings.ToUpper(run.ID.String()[:8])),
+		Date:        run.PayDate,
+		Description: fmt.Sprintf("Payroll %s (%s)", run.PayrollMonth.Format("January 2006"), run.RunType),
+	}
+	if run.Status == "reversed" {
+		header.Reverse = true
+		header.Reference += "-REV"
+		header.Description = "Reversal of " + header.Description
+		if run.ReversedAt != nil {
+			header.Date = *run.ReversedAt
+		}
+	}
+
+	entry, err := gl.Build(header, departments(items, run.SSORateEmployer), chart, q.SplitByDepartment)
+	if err != nil {
+		var missing *gl.MissingAccountsError
+		if errors.As(err, &missing) {
+			return nil, errs.Unprocessable("GL account mapping is incomplete", map[string]interface{}{"missingAccounts": missing.Keys})
+		}
+		logger.FromContext(ctx).Error("failed to build payroll journal", zap.Error(err))
+		return nil, errs.Internal("failed to build payroll journal")
+	}
+
+	var buf bytes.Buffer
+	contentType := "text/csv; charset=utf-8"
+	if q.Format == FormatJSON {
+		contentType = "application/json"
+		err = gl.WriteJSON(&buf, entry)
+	} else {
+		err = gl.WriteCSV(&buf, entry)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to write payroll journal", zap.Error(err))
+		return nil, errs.Internal("failed to generate payroll journal")
+	}
+
+	debit, _ := entry.Totals()
+	return &Response{
+		FileName:    fmt.Sprintf("journal-%s.%s", strings.ToLower(header.Reference), q.Format),
+		ContentType: contentType,
+		Data:        buf.Bytes(),
+		Reference:   header.Reference,
+		LineCount:   len(entry.Lines),
+		TotalDebit:  float64(debit) / 100,
+	}, nil
+}
+
+// departments sums the items per department; the employer SSO share is rounded per employee
+// exactly as on the SSO filing.
+func departments(items []repository.JournalItem, ssoRateEmployer float64) []gl.Department {
+	var out []gl.Department
+	index := map[string]int{}
+	for _, it := range items {
+		key := ""
+		if it.DepartmentID != nil {
+			key = it.DepartmentID.String()
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(out)
+			index[key] = i
+			out = append(out, gl.Department{ID: it.DepartmentID, Code: it.DepartmentCode, Name: it.DepartmentName})
+		}
+		out[i].Add(gl.Amounts{
+			Salary:      it.Salary,
+			OT:          it.OT,
+			SSOEmployee: it.SSOEmployee,
+			SSOEmployer: ssofile.EmployerAmount(it.SSOWage, ssoRateEmployer),
+			PFEmployee:  it.PFEmployee,
+			PFEmployer:  it.PFEmployer,
+			Tax:         it.Tax,
+			Loan:        it.Loan,
+			Advance:     it.Advance,
+			Other:       it.Other,
+			NetPay:      it.NetPay,
+		})
+	}
+	return out
+}
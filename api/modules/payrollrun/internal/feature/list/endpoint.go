@@ -18,7 +18,8 @@ import (
 // @Produce json
 // @Param page query int false "page"
 // @Param limit query int false "limit"
-// @Param status query string false "pending|approved|all"
+// @Param status query string false "pending|submitted|approved|reversed|all"
+// @Param runType query string false "regular|off_cycle|bonus_only|correction|all"
 // @Param year query int false "filter by year of payrollMonthDate"
 // @Param monthDate query string false "YYYY-MM-DD (will use month & year from this date to filter payroll_month_date)"
 // @Security BearerAuth
@@ -31,6 +32,7 @@ func NewEndpoint(router fiber.Router) {
 		page, _ := strconv.Atoi(c.Query("page", "1"))
 		limit, _ := strconv.Atoi(c.Query("limit", "20"))
 		status := c.Query("status", "all")
+		runType := c.Query("runType", "all")
 		var year *int
 		if v := c.Query("year"); v != "" {
 			if n, err := strconv.Atoi(v); err == nil {
@@ -48,11 +50,12 @@ func NewEndpoint(router fiber.Router) {
 		}
 
 		resp, err := mediator.Send[*Query, *Response](c.Context(), &Query{
-			Page:   page,
-			Limit:  limit,
-			Status: status,
-			Year:   year,
-			Month:  month,
+			Page:    page,
+			Limit:   limit,
+			Status:  status,
+			RunType: runType,
+			Year:    year,
+			Month:   month,
 		})
 		if err != nil {
 			return err
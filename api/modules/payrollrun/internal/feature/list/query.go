@@ -16,11 +16,12 @@ import (
 )
 
 type Query struct {
-	Page   int
-	Limit  int
-	Status string
-	Year   *int
-	Month  *time.Time
+	Page    int
+	Limit   int
+	Status  string
+	RunType string
+	Year    *int
+	Month   *time.Time
 }
 
 type Response struct {
@@ -51,7 +52,7 @@ func (h *Handler) Handle(ctx context.Context, q *Query) (*Response, error) {
 		return nil, errs.Unauthorized("missing tenant context")
 	}
 
-	res, err := h.repo.List(ctx, tenant, q.Page, q.Limit, q.Status, q.Year, q.Month)
+	res, err := h.repo.List(ctx, tenant, q.Page, q.Limit, q.Status, q.RunType, q.Year, q.Month)
 	if err != nil {
 		logger.FromContext(ctx).Error("failed to list payroll runs", zap.Error(err))
 		return nil, errs.Internal("failed to list payroll runs")
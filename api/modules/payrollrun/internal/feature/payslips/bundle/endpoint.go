@@ -0,0 +1,49 @@
+package payslipsbundle
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+)
+
+// @Summary Download payslips for a payroll run
+// @Description ดาวน์โหลดสลิปเงินเดือนทั้งงวด เป็นไฟล์ zip (แยกรายคน) หรือ PDF รวมไฟล์เดียว
+// @Tags Payroll Run
+// @Produce application/zip
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path string true "run id"
+// @Param format query string false "zip (default) or pdf"
+// @Success 200 {file} binary
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /payroll-runs/{id}/payslips [get]
+func NewEndpoint(router fiber.Router) {
+	router.Get("/:id/payslips", func(c fiber.Ctx) error {
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return errs.BadRequest("invalid id")
+		}
+		resp, err := mediator.Send[*Query, *Response](c.Context(), &Query{
+			RunID:  id,
+			Format: c.Query("format", FormatZip),
+		})
+		if err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, resp.ContentType)
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"%s\"", resp.FileName))
+		c.Set(fiber.HeaderContentLength, strconv.Itoa(len(resp.Data)))
+		c.Set(fiber.HeaderCacheControl, "private, no-store")
+		return c.Send(resp.Data)
+	})
+}
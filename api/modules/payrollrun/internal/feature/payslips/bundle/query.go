@@ -0,0 +1,120 @@
+package payslipsbundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/payrollrun/internal/payslip"
+	"hrms/modules/payrollrun/internal/pdfdoc"
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/validator"
+)
+
+const (
+	FormatZip = "zip"
+	FormatPDF = "pdf"
+)
+
+type Query struct {
+	RunID  uuid.UUID
+	Format string `validate:"omitempty,oneof=zip pdf"`
+}
+
+type Response struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+type Handler struct {
+	repo  repository.Repository
+	fonts *pdfdoc.Fonts
+}
+
+var _ mediator.RequestHandler[*Query, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, fonts *pdfdoc.Fonts) *Handler {
+	return &Handler{repo: repo, fonts: fonts}
+}
+
+func (h *Handler) Handle(ctx context.Context, q *Query) (*Response, error) {
+	if err := validator.Validate(q); err != nil {
+		return nil, err
+	}
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	run, err := h.repo.Get(ctx, tenant, q.RunID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("payroll run not found")
+		}
+		logger.FromContext(ctx).Error("failed to load payroll run", zap.Error(err))
+		return nil, errs.Internal("failed to load payroll run")
+	}
+	items, err := h.repo.ListItemDetails(ctx, tenant, run.ID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load payroll items", zap.Error(err))
+		return nil, errs.Internal("failed to load payroll items")
+	}
+	if len(items) == 0 {
+		return nil, errs.NotFound("payroll run has no items")
+	}
+
+	doc := payslip.NewDocument(ctx, h.repo, tenant.CompanyID, *run)
+	base := fmt.Sprintf("payslips-%s", run.PayrollMonth.Format("2006-01"))
+	if q.Format == FormatPDF {
+		data, err := h.render(doc, items)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to render payslips", zap.Error(err))
+			return nil, errs.Internal("failed to generate payslips")
+		}
+		return &Response{FileName: base + ".pdf", ContentType: "application/pdf", Data: data}, nil
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, it := range items {
+		data, err := h.render(doc, []repository.ItemDetail{it})
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to render payslip", zap.Error(err), zap.String("itemId", it.ID.String()))
+			return nil, errs.Internal("failed to generate payslips")
+		}
+		w, err := zw.Create(payslip.FileName(*run, it))
+		if err == nil {
+			_, err = w.Write(data)
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to write payslip archive", zap.Error(err))
+			return nil, errs.Internal("failed to generate payslips")
+		}
+	}
+	if err := zw.Close(); err != nil {
+		logger.FromContext(ctx).Error("failed to write payslip archive", zap.Error(err))
+		return nil, errs.Internal("failed to generate payslips")
+	}
+	return &Response{FileName: base + ".zip", ContentType: "application/zip", Data: buf.Bytes()}, nil
+}
+
+func (h *Handler) render(doc payslip.Document, items []repository.ItemDetail) ([]byte, error) {
+	pdf, err := h.fonts.New()
+	if err != nil {
+		return nil, err
+	}
+	if err := payslip.Render(pdf, doc, items); err != nil {
+		return nil, err
+	}
+	return pdfdoc.Output(pdf)
+}
@@ -0,0 +1,44 @@
+package payslipsitem
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+)
+
+// @Summary Download payslip PDF
+// @Description ดาวน์โหลดสลิปเงินเดือนรายบุคคล (PDF)
+// @Tags Payroll Run
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param itemId path string true "item id"
+// @Success 200 {file} binary
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /payroll-items/{itemId}/payslip [get]
+func NewEndpoint(router fiber.Router) {
+	router.Get("/:itemId/payslip", func(c fiber.Ctx) error {
+		itemID, err := uuid.Parse(c.Params("itemId"))
+		if err != nil {
+			return errs.BadRequest("invalid item id")
+		}
+		resp, err := mediator.Send[*Query, *Response](c.Context(), &Query{ItemID: itemID})
+		if err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, "application/pdf")
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=\"%s\"", resp.FileName))
+		c.Set(fiber.HeaderContentLength, strconv.Itoa(len(resp.Data)))
+		c.Set(fiber.HeaderCacheControl, "private, no-store")
+		return c.Send(resp.Data)
+	})
+}
@@ -0,0 +1,78 @@
+package payslipsitem
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/payrollrun/internal/payslip"
+	"hrms/modules/payrollrun/internal/pdfdoc"
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+)
+
+type Query struct {
+	ItemID uuid.UUID
+}
+
+type Response struct {
+	FileName string
+	Data     []byte
+}
+
+type Handler struct {
+	repo  repository.Repository
+	fonts *pdfdoc.Fonts
+}
+
+var _ mediator.RequestHandler[*Query, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, fonts *pdfdoc.Fonts) *Handler {
+	return &Handler{repo: repo, fonts: fonts}
+}
+
+func (h *Handler) Handle(ctx context.Context, q *Query) (*Response, error) {
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	item, err := h.repo.GetItemDetail(ctx, tenant, q.ItemID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("payroll item not found")
+		}
+		logger.FromContext(ctx).Error("failed to load payroll item", zap.Error(err))
+		return nil, errs.Internal("failed to load payroll item")
+	}
+	run, err := h.repo.Get(ctx, tenant, item.RunID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("payroll run not found")
+		}
+		logger.FromContext(ctx).Error("failed to load payroll run", zap.Error(err))
+		return nil, errs.Internal("failed to load payroll run")
+	}
+
+	pdf, err := h.fonts.New()
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to init payslip pdf", zap.Error(err))
+		return nil, errs.Internal("failed to generate payslip")
+	}
+	doc := payslip.NewDocument(ctx, h.repo, tenant.CompanyID, *run)
+	if err := payslip.Render(pdf, doc, []repository.ItemDetail{*item}); err != nil {
+		logger.FromContext(ctx).Error("failed to render payslip", zap.Error(err))
+		return nil, errs.Internal("failed to generate payslip")
+	}
+	data, err := pdfdoc.Output(pdf)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to write payslip pdf", zap.Error(err))
+		return nil, errs.Internal("failed to generate payslip")
+	}
+	return &Response{FileName: payslip.FileName(*run, *item), Data: data}, nil
+}
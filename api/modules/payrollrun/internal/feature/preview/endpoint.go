@@ -0,0 +1,59 @@
+package preview
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// @Summary Preview payroll for a month
+// @Description คำนวณเงินเดือนของเดือนที่ระบุด้วย payroll engine ฝั่ง Go โดยไม่บันทึกข้อมูล (ใช้ worklog เงินเบิก ค่างวดหนี้ โบนัส และยอดสะสมที่ยังรอดำเนินการ) ถ้ามีงวดปกติสถานะ pending ของเดือนนั้นอยู่แล้ว จะใช้วันเริ่มงวด อัตราประกันสังคม และ config ของงวด พร้อมเทียบผลกับรายการที่ฐานข้อมูลคำนวณไว้
+// @Tags Payroll Run
+// @Produce json
+// @Security BearerAuth
+// @Param monthDate query string true "payroll month (YYYY-MM-01)"
+// @Param periodStartDate query string false "period start (YYYY-MM-DD, default: monthDate)"
+// @Param ssoRateEmployee query number false "employee SSO rate (default: payroll config)"
+// @Success 200 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 422
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /payroll-runs/preview [get]
+func NewEndpoint(router fiber.Router) {
+	router.Get("/preview", func(c fiber.Ctx) error {
+		month, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(c.Query("monthDate")), time.UTC)
+		if err != nil {
+			return errs.BadRequest("monthDate must be YYYY-MM-DD")
+		}
+		q := Query{Month: month}
+		if v := strings.TrimSpace(c.Query("periodStartDate")); v != "" {
+			start, err := time.ParseInLocation("2006-01-02", v, time.UTC)
+			if err != nil {
+				return errs.BadRequest("periodStartDate must be YYYY-MM-DD")
+			}
+			q.PeriodStart = &start
+		}
+		if v := strings.TrimSpace(c.Query("ssoRateEmployee")); v != "" {
+			rate, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return errs.BadRequest("invalid ssoRateEmployee")
+			}
+			q.SSORateEmployee = &rate
+		}
+
+		resp, err := mediator.Send[*Query, *Response](c.Context(), &q)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
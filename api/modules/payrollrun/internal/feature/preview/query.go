@@ -0,0 +1,292 @@
+package preview
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/payrollrun/internal/engine"
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+)
+
+type Query struct {
+	Month           time.Time
+	PeriodStart     *time.Time
+	SSORateEmployee *float64
+}
+
+type RunRef struct {
+	ID          uuid.UUID `json:"id"`
+	Status      string    `json:"status"`
+	PeriodStart time.Time `json:"periodStartDate"`
+}
+
+type ConfigRef struct {
+	ID        uuid.UUID `json:"id"`
+	VersionNo int64     `json:"versionNo"`
+}
+
+// Mismatch is a field where the engine and the stored item disagree by a satang or more.
+type Mismatch struct {
+	Field  string  `json:"field"`
+	Engine float64 `json:"engine"`
+	Stored float64 `json:"stored"`
+}
+
+type Line struct {
+	EmployeeID       uuid.UUID   `json:"employeeId"`
+	EmployeeNumber   string      `json:"employeeNumber"`
+	EmployeeName     string      `json:"employeeName"`
+	EmployeeTypeCode string      `json:"employeeTypeCode"`
+	DepartmentName   *string     `json:"departmentName"`
+	Item             engine.Item `json:"item"`
+	Mismatches       []Mismatch  `json:"mismatches"`
+}
+
+type Totals struct {
+	Employees int     `json:"employees"`
+	Income    float64 `json:"income"`
+	Deduction float64 `json:"deduction"`
+	SSO       float64 `json:"sso"`
+	Tax       float64 `json:"tax"`
+	PF        float64 `json:"pf"`
+	NetPay    float64 `json:"netPay"`
+}
+
+// Response is the computed month. When a pending regular run exists, each line is also checked
+// against the run's stored item and MismatchCount counts the lines that differ.
+type Response struct {
+	Month           time.Time `json:"monthDate"`
+	PeriodStart     time.Time `json:"periodStartDate"`
+	SSORateEmployee float64   `json:"ssoRateEmployee"`
+	Config          ConfigRef `json:"config"`
+	Run             *RunRef   `json:"run"`
+	Totals          Totals    `json:"totals"`
+	MismatchCount   int       `json:"mismatchCount"`
+	Lines           []Line    `json:"lines"`
+}
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*Query, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) Handle(ctx context.Context, q *Query) (*Response, error) {
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	if !tenant.HasBranchID() {
+		return nil, errs.BadRequest("branch is required to preview payroll")
+	}
+	if q.Month.Day() != 1 {
+		return nil, errs.BadRequest("monthDate must be first day of month (YYYY-MM-01)")
+	}
+	if q.SSORateEmployee != nil && *q.SSORateEmployee < 0 {
+		return nil, errs.BadRequest("ssoRateEmployee must be positive")
+	}
+
+	period := repository.PreviewPeriod{Month: q.Month, PeriodStart: q.Month}
+	if q.PeriodStart != nil {
+		period.PeriodStart = *q.PeriodStart
+	}
+	var configID *uuid.UUID
+	ssoRate := q.SSORateEmployee
+
+	run, err := h.repo.FindRegularRun(ctx, tenant, q.Month)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		run = nil
+	case err != nil:
+		logger.FromContext(ctx).Error("failed to load payroll run", zap.Error(err))
+		return nil, errs.Internal("failed to load payroll run")
+	case run.Status != "pending" && run.Status != "submitted":
+		return nil, errs.BadRequest("payroll for this month is already " + run.Status)
+	default:
+		// A pending or submitted run fixes the period, rate and config, so the preview reproduces its items.
+		period.PeriodStart = run.PeriodStart
+		period.RunID = &run.ID
+		configID = run.PayrollConfigID
+		ssoRate = &run.SSORateEmp
+	}
+
+	cfg, err := h.repo.GetPayrollConfig(ctx, tenant.CompanyID, q.Month, configID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.Unprocessable("no payroll config is effective for this month")
+		}
+		logger.FromContext(ctx).Error("failed to load payroll config", zap.Error(err))
+		return nil, errs.Internal("failed to load payroll config")
+	}
+	if ssoRate == nil {
+		ssoRate = &cfg.SocialSecurityRateEmployee
+	}
+
+	inputs, err := h.repo.ListPreviewInputs(ctx, tenant, period)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load payroll inputs", zap.Error(err))
+		return nil, errs.Internal("failed to load payroll inputs")
+	}
+
+	engineCfg := cfg.EngineConfig(*ssoRate)
+	engineCfg.PeriodStart, engineCfg.PeriodEnd = period.PeriodStart, q.Month.AddDate(0, 1, -1)
+	resp := &Response{
+		Month:           q.Month,
+		PeriodStart:     period.PeriodStart,
+		SSORateEmployee: *ssoRate,
+		Config:          ConfigRef{ID: cfg.ID, VersionNo: cfg.VersionNo},
+		Lines:           make([]Line, 0, len(inputs)),
+	}
+	if run != nil {
+		resp.Run = &RunRef{ID: run.ID, Status: run.Status, PeriodStart: run.PeriodStart}
+	}
+	for _, in := range inputs {
+		item := engine.Calculate(engineCfg, toEngineEmployee(in), toEngineInputs(in))
+		line := Line{
+			EmployeeID:       in.EmployeeID,
+			EmployeeNumber:   in.EmployeeNumber,
+			EmployeeName:     in.EmployeeName,
+			EmployeeTypeCode: in.EmployeeTypeCode,
+			DepartmentName:   in.DepartmentName,
+			Item:             item,
+			Mismatches:       []Mismatch{},
+		}
+		if in.ItemID != nil {
+			line.Mismatches = mismatches(item, in.PreviewStored)
+			if len(line.Mismatches) > 0 {
+				resp.MismatchCount++
+			}
+		}
+		resp.Totals.Employees++
+		resp.Totals.Income += item.IncomeTotal
+		resp.Totals.Deduction += item.DeductionTotal
+		resp.Totals.SSO += item.SSOMonthAmount
+		resp.Totals.Tax += item.TaxMonthAmount
+		resp.Totals.PF += item.PFMonthAmount
+		resp.Totals.NetPay += item.NetPay
+		resp.Lines = append(resp.Lines, line)
+	}
+	t := &resp.Totals
+	t.Income, t.Deduction, t.SSO = engine.Round2(t.Income), engine.Round2(t.Deduction), engine.Round2(t.SSO)
+	t.Tax, t.PF, t.NetPay = engine.Round2(t.Tax), engine.Round2(t.PF), engine.Round2(t.NetPay)
+	return resp, nil
+}
+
+func toEngineEmployee(in repository.PreviewInput) engine.Employee {
+	return engine.Employee{
+		ID:                          in.EmployeeID,
+		TypeCode:                    in.EmployeeTypeCode,
+		BasePay:                     in.BasePayAmount,
+		SSOContribute:               in.SSOContribute,
+		SSODeclaredWage:             in.SSODeclaredWage,
+		PFContribute:                in.PFContribute,
+		PFRateEmployee:              in.PFRateEmployee,
+		WithholdTax:                 in.WithholdTax,
+		AllowHousing:                in.AllowHousing,
+		AllowInternet:               in.AllowInternet,
+		AllowDoctorFee:              in.AllowDoctorFee,
+		AllowAttendanceBonusNoLate:  in.AllowAttendanceBonusNoLate,
+		AllowAttendanceBonusNoLeave: in.AllowAttendanceBonusNoLeave,
+		EmploymentStart:             in.EmploymentStartDate,
+		EmploymentEnd:               in.EmploymentEndDate,
+		TaxAllowance:                in.TaxAllowanceInput.Engine(),
+	}
+}
+
+func toEngineInputs(in repository.PreviewInput) engine.Inputs {
+	// Repayments without a txn_id were typed in on the item and survive a recalculation.
+	var manual []engine.Line
+	for _, l := range engine.ParseLines(in.LoanRepayments) {
+		if l.TxnID == nil {
+			manual = append(manual, l)
+		}
+	}
+	return engine.Inputs{
+		OtHours:          in.OtHours,
+		HolidayWorkHours: in.HolidayWorkHours,
+		HolidayOtHours:   in.HolidayOtHours,
+		LateMinutes:      in.LateMinutes,
+		LeaveDays:        in.LeaveDays,
+		LeaveDoubleDays:  in.LeaveDoubleDays,
+		LeaveHours:       in.LeaveHours,
+		PTHours:          in.PTHours,
+		Bonus:            in.Bonus,
+		Advance:          in.Advance,
+		Installments:     engine.ParseLines(in.Installments),
+		SSOOtherRuns:     in.SSOOtherRuns,
+		Accum: engine.Accumulations{
+			SSO:             in.AccumSSO,
+			Tax:             in.AccumTax,
+			Income:          in.AccumIncome,
+			PF:              in.AccumPF,
+			LoanOutstanding: in.AccumLoan,
+		},
+		Current: engine.Current{
+			OthersIncome:            engine.ParseLines(in.OthersIncome),
+			OthersDeduction:         engine.ParseLines(in.OthersDeduction),
+			ManualRepayments:        manual,
+			DoctorFee:               in.DoctorFee,
+			LeaveCompensationAmount: in.LeaveCompensationAmount,
+			ManualTax:               in.ManualTax,
+			ManualPF:                in.ManualPF,
+			ManualInternet:          in.ManualInternet,
+			ManualWaterRate:         in.ManualWaterRate,
+			ManualElectricRate:      in.ManualElectricRate,
+			WaterAmount:             in.WaterAmount,
+			ElectricAmount:          in.ElectricAmount,
+			AdvanceRepayAmount:      in.AdvanceRepayAmount,
+		},
+	}
+}
+
+func mismatches(it engine.Item, s repository.PreviewStored) []Mismatch {
+	checks := []struct {
+		field  string
+		engine float64
+		stored *float64
+	}{
+		{"salaryAmount", it.SalaryAmount, s.SalaryAmount},
+		{"otAmount", it.OtAmount, s.OtAmount},
+		{"housingAllowance", it.HousingAllowance, s.HousingAllowance},
+		{"attendanceBonusNoLate", it.AttendanceBonusNoLate, s.AttendanceBonusNoLate},
+		{"attendanceBonusNoLeave", it.AttendanceBonusNoLeave, s.AttendanceBonusNoLeave},
+		{"bonusAmount", it.BonusAmount, s.BonusAmount},
+		{"lateMinutesDeduction", it.LateMinutesDeduction, s.LateMinutesDeduction},
+		{"leaveDaysDeduction", it.LeaveDaysDeduction, s.LeaveDaysDeduction},
+		{"leaveDoubleDeduction", it.LeaveDoubleDeduction, s.LeaveDoubleDeduction},
+		{"leaveHoursDeduction", it.LeaveHoursDeduction, s.LeaveHoursDeduction},
+		{"ssoMonthAmount", it.SSOMonthAmount, s.SSOMonthAmount},
+		{"taxMonthAmount", it.TaxMonthAmount, s.TaxMonthAmount},
+		{"pfMonthAmount", it.PFMonthAmount, s.PFMonthAmount},
+		{"internetAmount", it.InternetAmount, s.InternetAmount},
+		{"advanceAmount", it.AdvanceAmount, s.AdvanceAmount},
+		{"incomeTotal", it.IncomeTotal, s.IncomeTotal},
+		{"loanOutstandingTotal", it.LoanOutstandingTotal, s.LoanOutstandingTotal},
+		{"netPay", it.NetPay, s.NetPay},
+	}
+	out := []Mismatch{}
+	for _, c := range checks {
+		stored := 0.0
+		if c.stored != nil {
+			stored = *c.stored
+		}
+		if math.Abs(c.engine-stored) >= 0.005 {
+			out = append(out, Mismatch{Field: c.field, Engine: c.engine, Stored: stored})
+		}
+	}
+	return out
+}
@@ -0,0 +1,219 @@
+package preview
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/payrollrun/internal/engine"
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/shared/common/storage/sqldb/dbtest"
+	"hrms/shared/common/storage/sqldb/transactor"
+)
+
+// parityEmployee is one fixture employee, the worklogs of the month and whether it has a tax
+// allowance record for the year.
+type parityEmployee struct {
+	dbtest.Employee
+	worklogs  map[string]float64
+	allowance bool
+}
+
+var parityEmployees = []parityEmployee{
+	// full month with every kind of OT, late minutes and tax allowances
+	{Employee: dbtest.Employee{Number: "PARITY-001", BasePay: 45000, SSOWage: 15000, PFRate: 0.03, WithholdTax: true, Allowances: true},
+		worklogs: map[string]float64{"ot": 6, "holiday_work": 8, "holiday_ot": 2, "late": 25}, allowance: true},
+	// joins mid-month
+	{Employee: dbtest.Employee{Number: "PARITY-002", BasePay: 30000, SSOWage: 15000, WithholdTax: true, Allowances: true, Start: "2099-01-11"},
+		worklogs: map[string]float64{"leave_day": 1, "ot": 3}},
+	// leaves mid-month
+	{Employee: dbtest.Employee{Number: "PARITY-003", BasePay: 36000, SSOWage: 17500, Allowances: true, End: "2099-01-20"},
+		worklogs: map[string]float64{"leave_hours": 4, "holiday_ot": 1.5}},
+	// high earner on the upper brackets with the default allowances only
+	{Employee: dbtest.Employee{Number: "PARITY-004", BasePay: 120000, SSOWage: 17500, PFRate: 0.05, WithholdTax: true, Allowances: true},
+		worklogs: map[string]float64{"ot": 3, "leave_double": 1}},
+}
+
+// TestPreviewMatchesRegularRun seeds a branch month, lets recalculate_payroll_item_regular compute
+// the run's items and checks the engine (through the preview) reproduces them field by field,
+// for each proration basis. It needs a migrated database in TEST_DB_DSN; the fixtures are rolled back.
+func TestPreviewMatchesRegularRun(t *testing.T) {
+	d := dbtest.Open(t)
+	for _, basis := range []string{engine.ProrationThirtyDay, engine.ProrationCalendarDays, engine.ProrationWorkingDays} {
+		t.Run(basis, func(t *testing.T) {
+			d.Rollback(t, func(ctx context.Context, db transactor.DBTX) {
+				branchID := seedParity(ctx, t, d, db, basis)
+				checkParity(d.BranchContext(ctx, branchID), t, d.DBTX, dbtest.Month)
+			})
+		})
+	}
+}
+
+// seedParity copies the seeded config into a version for the fixture month with OT multipliers
+// and the given proration basis, adds the fixture employees to a new branch and creates the
+// pending regular run.
+func seedParity(ctx context.Context, t *testing.T, d *dbtest.DB, db transactor.DBTX, basis string) uuid.UUID {
+	t.Helper()
+	month := dbtest.Month
+	if _, err := db.ExecContext(ctx, `
+INSERT INTO payroll_config (
+  effective_daterange, hourly_rate, ot_hourly_rate,
+  ot_weekday_multiplier, holiday_work_multiplier, holiday_ot_multiplier, proration_basis,
+  attendance_bonus_no_late, attendance_bonus_no_leave, housing_allowance,
+  water_rate_per_unit, electricity_rate_per_unit, internet_fee_monthly,
+  social_security_rate_employee, social_security_rate_employer, social_security_wage_cap,
+  tax_apply_standard_expense, tax_standard_expense_rate, tax_standard_expense_cap,
+  tax_apply_personal_allowance, tax_personal_allowance_amount, tax_progressive_brackets,
+  withholding_tax_rate_service, work_hours_per_day, late_rate_per_minute, late_grace_minutes,
+  company_id, created_by, updated_by
+)
+SELECT daterange($2::date, NULL, '[)'), hourly_rate, ot_hourly_rate,
+       1.5, 2.0, 3.0, $3,
+       attendance_bonus_no_late, attendance_bonus_no_leave, housing_allowance,
+       water_rate_per_unit, electricity_rate_per_unit, internet_fee_monthly,
+       social_security_rate_employee, social_security_rate_employer, social_security_wage_cap,
+       tax_apply_standard_expense, tax_standard_expense_rate, tax_standard_expense_cap,
+       tax_apply_personal_allowance, tax_personal_allowance_amount, tax_progressive_brackets,
+       withholding_tax_rate_service, work_hours_per_day, late_rate_per_minute, late_grace_minutes,
+       company_id, $4, $4
+FROM payroll_config
+WHERE company_id = $1 AND effective_daterange @> $2::date
+ORDER BY version_no DESC
+LIMIT 1`, d.CompanyID, month, basis, d.AdminID); err != nil {
+		t.Fatalf("insert payroll config: %v", err)
+	}
+
+	branchID := d.InsertBranch(ctx, t, db, "PARITY")
+	for _, pe := range parityEmployees {
+		employeeID := d.InsertEmployee(ctx, t, db, branchID, pe.Employee)
+		workDate := month.AddDate(0, 0, 19) // inside every fixture's employment
+		for entryType, qty := range pe.worklogs {
+			d.InsertWorklog(ctx, t, db, branchID, employeeID, entryType, workDate, qty)
+		}
+		if pe.allowance {
+			if _, err := db.ExecContext(ctx, `
+INSERT INTO employee_tax_allowance (
+  company_id, employee_id, tax_year, spouse, children_count, children_born_2018_count, parents_count,
+  life_insurance, health_insurance, ssf_amount, rmf_amount, provident_fund_amount, home_loan_interest,
+  created_by, updated_by
+) VALUES ($1, $2, $3, TRUE, 2, 1, 2, 30000, 15000, 50000, 20000, 16200, 60000, $4, $4)`,
+				d.CompanyID, employeeID, month.Year(), d.AdminID); err != nil {
+				t.Fatalf("insert tax allowance of %s: %v", pe.Number, err)
+			}
+		}
+	}
+	d.InsertRegularRun(ctx, t, db, branchID, month)
+	return branchID
+}
+
+// storedItem holds the item columns the preview mismatch check does not cover.
+type storedItem struct {
+	EmployeeID        uuid.UUID `db:"employee_id"`
+	OtHours           float64   `db:"ot_hours"`
+	OtWeekdayHours    float64   `db:"ot_weekday_hours"`
+	OtWeekdayAmount   float64   `db:"ot_weekday_amount"`
+	HolidayWorkHours  float64   `db:"holiday_work_hours"`
+	HolidayWorkAmount float64   `db:"holiday_work_amount"`
+	HolidayOtHours    float64   `db:"holiday_ot_hours"`
+	HolidayOtAmount   float64   `db:"holiday_ot_amount"`
+	LateMinutesQty    float64   `db:"late_minutes_qty"`
+	LeaveDaysQty      float64   `db:"leave_days_qty"`
+	LeaveDoubleQty    float64   `db:"leave_double_qty"`
+	LeaveHoursQty     float64   `db:"leave_hours_qty"`
+	SSODeclaredWage   float64   `db:"sso_declared_wage"`
+	WaterAmount       float64   `db:"water_amount"`
+	ElectricAmount    float64   `db:"electric_amount"`
+	SSOAccumTotal     float64   `db:"sso_accum_total"`
+	TaxAccumTotal     float64   `db:"tax_accum_total"`
+	IncomeAccumTotal  float64   `db:"income_accum_total"`
+	PFAccumTotal      float64   `db:"pf_accum_total"`
+	ProrationDays     *float64  `db:"proration_days"`
+	PeriodDays        *float64  `db:"proration_period_days"`
+}
+
+func checkParity(ctx context.Context, t *testing.T, dbtx transactor.DBTXContext, month time.Time) {
+	t.Helper()
+	resp, err := NewHandler(repository.NewRepository(dbtx)).Handle(ctx, &Query{Month: month})
+	if err != nil {
+		t.Fatalf("preview: %v", err)
+	}
+	if resp.Run == nil {
+		t.Fatal("preview did not pick up the pending regular run")
+	}
+	if len(resp.Lines) != len(parityEmployees) {
+		t.Fatalf("preview has %d lines, want %d", len(resp.Lines), len(parityEmployees))
+	}
+
+	var rows []storedItem
+	if err := dbtx(ctx).SelectContext(ctx, &rows, `
+SELECT employee_id, ot_hours, ot_weekday_hours, ot_weekday_amount,
+       holiday_work_hours, holiday_work_amount, holiday_ot_hours, holiday_ot_amount,
+       late_minutes_qty, leave_days_qty, leave_double_qty, leave_hours_qty,
+       sso_declared_wage, water_amount, electric_amount,
+       sso_accum_total, tax_accum_total, income_accum_total, pf_accum_total,
+       proration_days, proration_period_days
+FROM payroll_run_item
+WHERE run_id = $1`, resp.Run.ID); err != nil {
+		t.Fatalf("load payroll items: %v", err)
+	}
+	stored := make(map[uuid.UUID]storedItem, len(rows))
+	for _, r := range rows {
+		stored[r.EmployeeID] = r
+	}
+
+	for _, line := range resp.Lines {
+		for _, m := range line.Mismatches {
+			t.Errorf("%s %s: engine %.2f, SQL %.2f", line.EmployeeNumber, m.Field, m.Engine, m.Stored)
+		}
+		s, ok := stored[line.EmployeeID]
+		if !ok {
+			t.Errorf("%s has no payroll item", line.EmployeeNumber)
+			continue
+		}
+		it := line.Item
+		var days, periodDays float64
+		if it.Proration != nil {
+			days, periodDays = it.Proration.Days, it.Proration.PeriodDays
+		}
+		checks := []struct {
+			field       string
+			eng, stored float64
+		}{
+			{"otHours", it.OtHours, s.OtHours},
+			{"otWeekdayHours", it.OtBreakdown.WeekdayHours, s.OtWeekdayHours},
+			{"otWeekdayAmount", it.OtBreakdown.WeekdayAmount, s.OtWeekdayAmount},
+			{"holidayWorkHours", it.OtBreakdown.HolidayWorkHours, s.HolidayWorkHours},
+			{"holidayWorkAmount", it.OtBreakdown.HolidayWorkAmount, s.HolidayWorkAmount},
+			{"holidayOtHours", it.OtBreakdown.HolidayOtHours, s.HolidayOtHours},
+			{"holidayOtAmount", it.OtBreakdown.HolidayOtAmount, s.HolidayOtAmount},
+			{"lateMinutesQty", float64(it.LateMinutesQty), s.LateMinutesQty},
+			{"leaveDaysQty", it.LeaveDaysQty, s.LeaveDaysQty},
+			{"leaveDoubleQty", it.LeaveDoubleQty, s.LeaveDoubleQty},
+			{"leaveHoursQty", it.LeaveHoursQty, s.LeaveHoursQty},
+			{"ssoDeclaredWage", it.SSODeclaredWage, s.SSODeclaredWage},
+			{"waterAmount", it.WaterAmount, s.WaterAmount},
+			{"electricAmount", it.ElectricAmount, s.ElectricAmount},
+			{"ssoAccumTotal", it.SSOAccumTotal, s.SSOAccumTotal},
+			{"taxAccumTotal", it.TaxAccumTotal, s.TaxAccumTotal},
+			{"incomeAccumTotal", it.IncomeAccumTotal, s.IncomeAccumTotal},
+			{"pfAccumTotal", it.PFAccumTotal, s.PFAccumTotal},
+			{"prorationDays", days, deref(s.ProrationDays)},
+			{"prorationPeriodDays", periodDays, deref(s.PeriodDays)},
+		}
+		for _, c := range checks {
+			if math.Abs(c.eng-c.stored) >= 0.005 {
+				t.Errorf("%s %s: engine %.2f, SQL %.2f", line.EmployeeNumber, c.field, c.eng, c.stored)
+			}
+		}
+	}
+}
+
+func deref(v *float64) float64 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
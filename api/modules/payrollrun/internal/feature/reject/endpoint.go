@@ -0,0 +1,46 @@
+package reject
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// @Summary Reject payroll run
+// @Description ตีกลับงวดเงินเดือนที่ส่งอนุมัติ (ผู้อนุมัติขั้นปัจจุบัน ต้องระบุความเห็น) งวดกลับเป็น pending ให้ผู้จัดทำแก้ไขแล้วส่งใหม่
+// @Tags Payroll Run
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "run id"
+// @Param request body Command true "payload"
+// @Success 200 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /payroll-runs/{id}/reject [post]
+func NewEndpoint(router fiber.Router) {
+	router.Post("/:id/reject", func(c fiber.Ctx) error {
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return errs.BadRequest("invalid id")
+		}
+		var req Command
+		if err := c.Bind().Body(&req); err != nil {
+			return errs.BadRequest("invalid request body")
+		}
+		req.ID = id
+
+		resp, err := mediator.Send[*Command, *Response](c.Context(), &req)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
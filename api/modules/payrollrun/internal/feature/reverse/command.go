@@ -0,0 +1,113 @@
+package reverse
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+
+	"hrms/modules/payrollrun/internal/dto"
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/storage/sqldb/transactor"
+	"hrms/shared/common/validator"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	ID     uuid.UUID `json:"-" validate:"required"`
+	Reason string    `json:"reason" validate:"required,max=500"`
+}
+
+type Response struct {
+	dto.Run
+	Message string `json:"message"`
+}
+
+type Handler struct {
+	repo repository.Repository
+	tx   transactor.Transactor
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, tx transactor.Transactor, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, tx: tx, eb: eb}
+}
+
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	cmd.Reason = strings.TrimSpace(cmd.Reason)
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+	if user.Role != "admin" {
+		return nil, errs.Forbidden("only admin can reverse payroll run")
+	}
+
+	run, err := h.repo.Get(ctx, tenant, cmd.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("payroll run not found")
+		}
+		logger.FromContext(ctx).Error("failed to load payroll run", zap.Error(err))
+		return nil, errs.Internal("failed to load payroll run")
+	}
+	if run.Status != "approved" {
+		return nil, errs.BadRequest("only approved run can be reversed")
+	}
+
+	var reversed *repository.Run
+	if err := h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
+		var err error
+		reversed, err = h.repo.Reverse(ctxTx, tenant, cmd.ID, user.ID, cmd.Reason)
+		return err
+	}); err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Hint == repository.ReverseHintLaterRun {
+			return nil, errs.Conflict("a later approved payroll run covers the same employees; reverse it first")
+		}
+		logger.FromContext(ctx).Error("failed to reverse payroll run", zap.Error(err))
+		return nil, errs.Internal("failed to reverse payroll run")
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "REVERSE",
+		EntityName: "PAYROLL_RUN",
+		EntityID:   reversed.ID.String(),
+		Details: map[string]interface{}{
+			"payroll_month": reversed.PayrollMonth.Format("2006-01-02"),
+			"run_type":      reversed.RunType,
+			"reason":        cmd.Reason,
+			"employees":     reversed.TotalEmployees,
+			"total_net_pay": reversed.TotalNetPay,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return &Response{
+		Run:     dto.FromRun(*reversed),
+		Message: "Payroll run reversed. Accumulations, advances, debt installments and worklogs were rolled back.",
+	}, nil
+}
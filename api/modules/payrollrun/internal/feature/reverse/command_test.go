@@ -0,0 +1,239 @@
+package reverse
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/storage/sqldb/dbtest"
+	"hrms/shared/common/storage/sqldb/transactor"
+)
+
+// reverseFixture is a branch with one insured, provident-fund, taxed employee.
+type reverseFixture struct {
+	d          *dbtest.DB
+	ctx        context.Context
+	tenant     contextx.TenantInfo
+	repo       repository.Repository
+	branchID   uuid.UUID
+	employeeID uuid.UUID
+}
+
+func newReverseFixture(ctx context.Context, t *testing.T, d *dbtest.DB, db transactor.DBTX) reverseFixture {
+	t.Helper()
+	branchID := d.InsertBranch(ctx, t, db, "REVERSE-TEST")
+	ctx = contextx.WithUser(d.BranchContext(ctx, branchID), contextx.UserInfo{ID: d.AdminID, Username: "admin", Role: "admin"})
+	tenant, _ := contextx.TenantFromContext(ctx)
+	return reverseFixture{
+		d:        d,
+		ctx:      ctx,
+		tenant:   tenant,
+		repo:     repository.NewRepository(d.DBTX),
+		branchID: branchID,
+		employeeID: d.InsertEmployee(ctx, t, db, branchID, dbtest.Employee{
+			Number: "REVERSE-TEST-001", BasePay: 40000, SSOWage: 15000, PFRate: 0.03, WithholdTax: true,
+		}),
+	}
+}
+
+// insertDebt records a debt transaction of the employee; month is only set for installments.
+func (f reverseFixture) insertDebt(t *testing.T, db transactor.DBTX, txnType, status string, amount float64, month *time.Time, parentID *uuid.UUID) uuid.UUID {
+	t.Helper()
+	var id uuid.UUID
+	if err := db.GetContext(f.ctx, &id, `
+INSERT INTO debt_txn (employee_id, company_id, branch_id, txn_date, txn_type, amount, payroll_month_date, parent_id, status, created_by, updated_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)
+RETURNING id`, f.employeeID, f.d.CompanyID, f.branchID, dbtest.Month, txnType, amount, month, parentID, status, f.d.AdminID); err != nil {
+		t.Fatalf("insert %s: %v", txnType, err)
+	}
+	return id
+}
+
+// approve approves the run the way the approve feature does when no chain governs it.
+func (f reverseFixture) approve(t *testing.T, runID uuid.UUID) {
+	t.Helper()
+	if _, err := f.repo.Approve(f.ctx, f.tenant, runID, f.d.AdminID); err != nil {
+		t.Fatalf("approve run: %v", err)
+	}
+}
+
+func (f reverseFixture) accumulation(t *testing.T, db transactor.DBTX, accumType string, year *int) float64 {
+	t.Helper()
+	var amount float64
+	if err := db.GetContext(f.ctx, &amount, `
+SELECT COALESCE(SUM(amount), 0)
+FROM payroll_accumulation
+WHERE employee_id = $1 AND accum_type = $2 AND COALESCE(accum_year, -1) = COALESCE($3::int, -1)`,
+		f.employeeID, accumType, year); err != nil {
+		t.Fatalf("load %s accumulation: %v", accumType, err)
+	}
+	return amount
+}
+
+// TestReverseRestoresApprovedRun approves a regular run that deducts an advance and a loan
+// installment and closes the month's worklogs, records a repayment after the approval, reverses
+// the run and checks that the postings are undone while the later repayment is kept. It needs
+// a migrated database in TEST_DB_DSN; the fixtures are rolled back.
+func TestReverseRestoresApprovedRun(t *testing.T) {
+	d := dbtest.Open(t)
+	d.Rollback(t, func(ctx context.Context, db transactor.DBTX) {
+		f := newReverseFixture(ctx, t, d, db)
+		month := dbtest.Month
+		year := month.Year()
+
+		// balances carried from earlier runs of the year
+		seeded := []struct {
+			accumType string
+			year      *int
+			amount    float64
+		}{
+			{"sso", &year, 750},
+			{"tax", &year, 1200},
+			{"income", &year, 40000},
+			{"pf", nil, 5000},
+		}
+		for _, s := range seeded {
+			if _, err := db.ExecContext(f.ctx, `
+INSERT INTO payroll_accumulation (employee_id, company_id, accum_type, accum_year, amount, updated_by)
+VALUES ($1, $2, $3, $4, $5, $6)`, f.employeeID, d.CompanyID, s.accumType, s.year, s.amount, d.AdminID); err != nil {
+				t.Fatalf("seed %s accumulation: %v", s.accumType, err)
+			}
+		}
+
+		loanID := f.insertDebt(t, db, "loan", "pending", 10000, nil, nil)
+		installmentID := f.insertDebt(t, db, "installment", "pending", 1000, &month, &loanID)
+		if _, err := db.ExecContext(f.ctx, `UPDATE debt_txn SET status = 'approved' WHERE id = $1`, loanID); err != nil {
+			t.Fatalf("approve loan: %v", err)
+		}
+		var advanceID uuid.UUID
+		if err := db.GetContext(f.ctx, &advanceID, `
+INSERT INTO salary_advance (employee_id, company_id, branch_id, payroll_month_date, advance_date, amount, created_by, updated_by)
+VALUES ($1, $2, $3, $4, $5, 2000, $6, $6)
+RETURNING id`, f.employeeID, d.CompanyID, f.branchID, month, month.AddDate(0, 0, 14), d.AdminID); err != nil {
+			t.Fatalf("insert salary advance: %v", err)
+		}
+		worklogIDs := []uuid.UUID{
+			d.InsertWorklog(f.ctx, t, db, f.branchID, f.employeeID, "ot", month.AddDate(0, 0, 9), 4),
+			d.InsertWorklog(f.ctx, t, db, f.branchID, f.employeeID, "late", month.AddDate(0, 0, 10), 15),
+		}
+
+		runID := d.InsertRegularRun(f.ctx, t, db, f.branchID, month)
+		var item struct {
+			SSO            float64 `db:"sso_month_amount"`
+			Tax            float64 `db:"tax_month_amount"`
+			Income         float64 `db:"income_total"`
+			PF             float64 `db:"pf_month_amount"`
+			Advance        float64 `db:"advance_amount"`
+			LoanPrev       float64 `db:"loan_outstanding_prev"`
+			LoanTotal      float64 `db:"loan_outstanding_total"`
+			LoanRepayments int     `db:"loan_repayments"`
+		}
+		if err := db.GetContext(f.ctx, &item, `
+SELECT sso_month_amount, tax_month_amount, income_total, pf_month_amount, advance_amount,
+       COALESCE(loan_outstanding_prev, 0) AS loan_outstanding_prev,
+       COALESCE(loan_outstanding_total, 0) AS loan_outstanding_total,
+       jsonb_array_length(loan_repayments) AS loan_repayments
+FROM payroll_run_item
+WHERE run_id = $1 AND employee_id = $2`, runID, f.employeeID); err != nil {
+			t.Fatalf("load payroll item: %v", err)
+		}
+		if item.SSO <= 0 || item.Tax <= 0 || item.PF <= 0 || item.Advance != 2000 || item.LoanRepayments != 1 || item.LoanPrev != 10000 {
+			t.Fatalf("item = %+v, want SSO, tax and PF, the 2,000 advance and the installment against 10,000 outstanding", item)
+		}
+
+		f.approve(t, runID)
+		if got, want := f.accumulation(t, db, "income", &year), 40000+item.Income; math.Abs(got-want) >= 0.005 {
+			t.Fatalf("income accumulation after approval = %.2f, want %.2f", got, want)
+		}
+		if got := f.accumulation(t, db, "loan_outstanding", nil); math.Abs(got-item.LoanTotal) >= 0.005 {
+			t.Fatalf("loan outstanding after approval = %.2f, want %.2f", got, item.LoanTotal)
+		}
+
+		// paid back over the counter after the run was approved; the reversal must keep it
+		repaymentID := f.insertDebt(t, db, "repayment", "approved", 500, nil, nil)
+
+		h := NewHandler(f.repo, d.Tx, eventbus.NewInMemory())
+		resp, err := h.Handle(f.ctx, &Command{ID: runID, Reason: "OT keyed against the wrong month"})
+		if err != nil {
+			t.Fatalf("reverse: %v", err)
+		}
+		if resp.Status != "reversed" {
+			t.Errorf("run status = %s, want reversed", resp.Status)
+		}
+
+		for _, s := range seeded {
+			if got := f.accumulation(t, db, s.accumType, s.year); math.Abs(got-s.amount) >= 0.005 {
+				t.Errorf("%s accumulation = %.2f, want %.2f", s.accumType, got, s.amount)
+			}
+		}
+		if got, want := f.accumulation(t, db, "loan_outstanding", nil), item.LoanPrev-500; math.Abs(got-want) >= 0.005 {
+			t.Errorf("loan outstanding = %.2f, want %.2f (balance before the run less the later repayment)", got, want)
+		}
+
+		type row struct {
+			Status string     `db:"status"`
+			RunID  *uuid.UUID `db:"payroll_run_id"`
+		}
+		rows := []struct {
+			name, query string
+			id          uuid.UUID
+			status      string
+		}{
+			{"installment", `SELECT status, payroll_run_id FROM debt_txn WHERE id = $1`, installmentID, "pending"},
+			{"repayment", `SELECT status, payroll_run_id FROM debt_txn WHERE id = $1`, repaymentID, "approved"},
+			{"loan", `SELECT status, payroll_run_id FROM debt_txn WHERE id = $1`, loanID, "approved"},
+			{"advance", `SELECT status, payroll_run_id FROM salary_advance WHERE id = $1`, advanceID, "pending"},
+			{"ot worklog", `SELECT status, payroll_run_id FROM worklog_ft WHERE id = $1`, worklogIDs[0], "pending"},
+			{"late worklog", `SELECT status, payroll_run_id FROM worklog_ft WHERE id = $1`, worklogIDs[1], "pending"},
+		}
+		for _, r := range rows {
+			var got row
+			if err := db.GetContext(f.ctx, &got, r.query, r.id); err != nil {
+				t.Fatalf("load %s: %v", r.name, err)
+			}
+			if got.Status != r.status || got.RunID != nil {
+				t.Errorf("%s = %s (run %v), want %s and unlinked", r.name, got.Status, got.RunID, r.status)
+			}
+		}
+	})
+}
+
+// TestReverseRefusedWhenLaterRunApproved checks that a run cannot be reversed while a later
+// approved run covers the same employee.
+func TestReverseRefusedWhenLaterRunApproved(t *testing.T) {
+	d := dbtest.Open(t)
+	d.Rollback(t, func(ctx context.Context, db transactor.DBTX) {
+		f := newReverseFixture(ctx, t, d, db)
+
+		janID := d.InsertRegularRun(f.ctx, t, db, f.branchID, dbtest.Month)
+		// both approvals share the transaction's now(); date January's back so February is later
+		if _, err := db.ExecContext(f.ctx, `UPDATE payroll_run SET approved_at = now() - interval '1 day' WHERE id = $1`, janID); err != nil {
+			t.Fatalf("backdate approval: %v", err)
+		}
+		f.approve(t, janID)
+		febID := d.InsertRegularRun(f.ctx, t, db, f.branchID, dbtest.Month.AddDate(0, 1, 0))
+		f.approve(t, febID)
+
+		h := NewHandler(f.repo, d.Tx, eventbus.NewInMemory())
+		_, err := h.Handle(f.ctx, &Command{ID: janID, Reason: "wrong month"})
+		var appErr *errs.AppError
+		if !errors.As(err, &appErr) || appErr.Code != errs.CodeConflict {
+			t.Fatalf("reverse January: err = %v, want conflict", err)
+		}
+		run, err := f.repo.Get(f.ctx, f.tenant, janID)
+		if err != nil {
+			t.Fatalf("load run: %v", err)
+		}
+		if run.Status != "approved" {
+			t.Errorf("January status = %s, want approved", run.Status)
+		}
+	})
+}
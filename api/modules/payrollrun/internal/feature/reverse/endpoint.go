@@ -0,0 +1,47 @@
+package reverse
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// @Summary Reverse approved payroll run
+// @Description กลับรายการงวดเงินเดือนที่อนุมัติแล้ว: คืนยอดสะสมภาษี/ประกันสังคม/รายได้/กองทุน ยอดหนี้คงค้าง และคืนสถานะเงินเบิก ค่างวดหนี้ และ worklog เฉพาะรายการที่งวดนี้ปิดตอนอนุมัติ เป็น pending (admin เท่านั้น ต้องระบุเหตุผล)
+// @Tags Payroll Run
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "run id"
+// @Param request body Command true "payload"
+// @Success 200 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /payroll-runs/{id}/reverse [post]
+func NewEndpoint(router fiber.Router) {
+	router.Post("/:id/reverse", func(c fiber.Ctx) error {
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return errs.BadRequest("invalid id")
+		}
+		var req Command
+		if err := c.Bind().Body(&req); err != nil {
+			return errs.BadRequest("invalid request body")
+		}
+		req.ID = id
+
+		resp, err := mediator.Send[*Command, *Response](c.Context(), &req)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
@@ -0,0 +1,264 @@
+package simulate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/payrollrun/internal/engine"
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/validator"
+)
+
+// ConfigOverride replaces fields of the effective payroll config; nil fields keep their value.
+type ConfigOverride struct {
+	OtHourlyRate               *float64         `json:"otHourlyRate" validate:"omitempty,gte=0"`
+	OtWeekdayMultiplier        *float64         `json:"otWeekdayMultiplier" validate:"omitempty,gt=0"`
+	HolidayWorkMultiplier      *float64         `json:"holidayWorkMultiplier" validate:"omitempty,gt=0"`
+	HolidayOtMultiplier        *float64         `json:"holidayOtMultiplier" validate:"omitempty,gt=0"`
+	HousingAllowance           *float64         `json:"housingAllowance" validate:"omitempty,gte=0"`
+	AttendanceBonusNoLate      *float64         `json:"attendanceBonusNoLate" validate:"omitempty,gte=0"`
+	AttendanceBonusNoLeave     *float64         `json:"attendanceBonusNoLeave" validate:"omitempty,gte=0"`
+	InternetFeeMonthly         *float64         `json:"internetFeeMonthly" validate:"omitempty,gte=0"`
+	SocialSecurityRateEmployee *float64         `json:"socialSecurityRateEmployee" validate:"omitempty,gte=0,lte=1"`
+	SocialSecurityRateEmployer *float64         `json:"socialSecurityRateEmployer" validate:"omitempty,gte=0,lte=1"`
+	SocialSecurityWageCap      *float64         `json:"socialSecurityWageCap" validate:"omitempty,gte=0"`
+	TaxApplyStandardExpense    *bool            `json:"taxApplyStandardExpense"`
+	TaxStandardExpenseRate     *float64         `json:"taxStandardExpenseRate" validate:"omitempty,gte=0,lte=1"`
+	TaxStandardExpenseCap      *float64         `json:"taxStandardExpenseCap" validate:"omitempty,gte=0"`
+	TaxApplyPersonalAllowance  *bool            `json:"taxApplyPersonalAllowance"`
+	TaxPersonalAllowanceAmount *float64         `json:"taxPersonalAllowanceAmount" validate:"omitempty,gte=0"`
+	TaxProgressiveBrackets     []engine.Bracket `json:"taxProgressiveBrackets"`
+	WithholdingTaxRateService  *float64         `json:"withholdingTaxRateService" validate:"omitempty,gte=0,lte=1"`
+}
+
+type Command struct {
+	MonthDateRaw       string          `json:"monthDate"`
+	Config             *ConfigOverride `json:"config"`
+	SalaryRaiseCycleID *uuid.UUID      `json:"salaryRaiseCycleId"`
+}
+
+type ConfigRef struct {
+	ID        uuid.UUID `json:"id"`
+	VersionNo int64     `json:"versionNo"`
+}
+
+type Response struct {
+	Month              time.Time      `json:"monthDate"`
+	CurrentConfig      ConfigRef      `json:"currentConfig"`
+	ConfigOverridden   bool           `json:"configOverridden"`
+	SalaryRaiseCycleID *uuid.UUID     `json:"salaryRaiseCycleId"`
+	Employees          int            `json:"employees"`
+	Total              Projection     `json:"total"`
+	Branches           []GroupLine    `json:"branches"`
+	Departments        []GroupLine    `json:"departments"`
+	Lines              []EmployeeLine `json:"lines"`
+}
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	if cmd.Config != nil {
+		if err := validator.Validate(cmd.Config); err != nil {
+			return nil, err
+		}
+		if cmd.Config.TaxProgressiveBrackets != nil {
+			if err := validateBrackets(cmd.Config.TaxProgressiveBrackets); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if cmd.Config == nil && cmd.SalaryRaiseCycleID == nil {
+		return nil, errs.BadRequest("config or salaryRaiseCycleId is required")
+	}
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+
+	now := time.Now().UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if cmd.MonthDateRaw != "" {
+		md, err := time.ParseInLocation("2006-01-02", cmd.MonthDateRaw, time.UTC)
+		if err != nil {
+			return nil, errs.BadRequest("monthDate must be YYYY-MM-DD")
+		}
+		month = time.Date(md.Year(), md.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	if cmd.SalaryRaiseCycleID != nil {
+		cycle, err := h.repo.GetSalaryRaiseCycle(ctx, tenant, *cmd.SalaryRaiseCycleID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, errs.NotFound("salary raise cycle not found")
+			}
+			logger.FromContext(ctx).Error("failed to load salary raise cycle", zap.Error(err))
+			return nil, errs.Internal("failed to load salary raise cycle")
+		}
+		if cycle.Status != "pending" {
+			return nil, errs.BadRequest("only pending salary raise cycle can be simulated")
+		}
+	}
+
+	cfg, err := h.repo.GetPayrollConfig(ctx, tenant.CompanyID, month, nil)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.Unprocessable("no payroll config is effective for this month")
+		}
+		logger.FromContext(ctx).Error("failed to load payroll config", zap.Error(err))
+		return nil, errs.Internal("failed to load payroll config")
+	}
+	employees, err := h.repo.ListSimulationEmployees(ctx, tenant, month, cmd.SalaryRaiseCycleID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load employees", zap.Error(err))
+		return nil, errs.Internal("failed to load employees")
+	}
+
+	current := scenario{
+		cfg:          cfg.EngineConfig(cfg.SocialSecurityRateEmployee),
+		employerRate: cfg.SocialSecurityRateEmployer,
+	}
+	simulated := current
+	simulated.applyRaise = cmd.SalaryRaiseCycleID != nil
+	if cmd.Config != nil {
+		simulated = cmd.Config.apply(simulated)
+	}
+
+	resp := &Response{
+		Month:              month,
+		CurrentConfig:      ConfigRef{ID: cfg.ID, VersionNo: cfg.VersionNo},
+		ConfigOverridden:   cmd.Config != nil,
+		SalaryRaiseCycleID: cmd.SalaryRaiseCycleID,
+		Employees:          len(employees),
+		Lines:              make([]EmployeeLine, 0, len(employees)),
+	}
+	var curTotal, simTotal Cost
+	branches, departments := newGroups(), newGroups()
+	for _, e := range employees {
+		cur, sim := current.monthCost(e), simulated.monthCost(e)
+		curTotal.add(cur)
+		simTotal.add(sim)
+		branchID := e.BranchID
+		branches.add(&branchID, e.BranchName, cur, sim)
+		departments.add(e.DepartmentID, deref(e.DepartmentName, "-"), cur, sim)
+
+		line := EmployeeLine{
+			EmployeeID:       e.EmployeeID,
+			EmployeeNumber:   e.EmployeeNumber,
+			EmployeeName:     e.EmployeeName,
+			EmployeeTypeCode: e.EmployeeTypeCode,
+			DepartmentName:   e.DepartmentName,
+			BranchName:       e.BranchName,
+			CurrentSalary:    e.BasePayAmount,
+			SimulatedSalary:  e.BasePayAmount,
+			Projection:       newProjection(cur, sim),
+		}
+		if simulated.applyRaise && e.NewSalary != nil {
+			line.SimulatedSalary = *e.NewSalary
+		}
+		resp.Lines = append(resp.Lines, line)
+	}
+	resp.Total = newProjection(curTotal, simTotal)
+	resp.Branches = branches.lines()
+	resp.Departments = departments.lines()
+	return resp, nil
+}
+
+func (o ConfigOverride) apply(s scenario) scenario {
+	set := func(dst *float64, v *float64) {
+		if v != nil {
+			*dst = *v
+		}
+	}
+	c := &s.cfg
+	set(&c.OtHourlyRate, o.OtHourlyRate)
+	if o.OtWeekdayMultiplier != nil {
+		v := *o.OtWeekdayMultiplier
+		c.OtWeekdayMultiplier = &v
+	}
+	set(&c.HolidayWorkMultiplier, o.HolidayWorkMultiplier)
+	set(&c.HolidayOtMultiplier, o.HolidayOtMultiplier)
+	set(&c.HousingAllowance, o.HousingAllowance)
+	set(&c.AttendanceBonusNoLate, o.AttendanceBonusNoLate)
+	set(&c.AttendanceBonusNoLeave, o.AttendanceBonusNoLeave)
+	set(&c.InternetFeeMonthly, o.InternetFeeMonthly)
+	set(&c.SSORateEmployee, o.SocialSecurityRateEmployee)
+	set(&s.employerRate, o.SocialSecurityRateEmployer)
+	// The database clamps the wage cap to 17,500; a simulation may try a higher legal cap.
+	set(&c.SSOWageCap, o.SocialSecurityWageCap)
+	if o.TaxApplyStandardExpense != nil {
+		c.Tax.ApplyStandardExpense = *o.TaxApplyStandardExpense
+	}
+	set(&c.Tax.StandardExpenseRate, o.TaxStandardExpenseRate)
+	if o.TaxStandardExpenseCap != nil {
+		v := *o.TaxStandardExpenseCap
+		c.Tax.StandardExpenseCap = &v
+	}
+	if o.TaxApplyPersonalAllowance != nil {
+		c.Tax.ApplyPersonalAllowance = *o.TaxApplyPersonalAllowance
+	}
+	set(&c.Tax.PersonalAllowance, o.TaxPersonalAllowanceAmount)
+	if o.TaxProgressiveBrackets != nil {
+		c.Tax.Brackets = o.TaxProgressiveBrackets
+	}
+	set(&c.Tax.ServiceRate, o.WithholdingTaxRateService)
+	return s
+}
+
+// validateBrackets checks replacement tax brackets the way a payroll config's are checked, and
+// that they are ordered by min without overlapping: each starts at or above the previous max,
+// and only the last may be open-ended.
+func validateBrackets(bs []engine.Bracket) error {
+	if len(bs) == 0 {
+		return errs.BadRequest("taxProgressiveBrackets must be a non-empty array")
+	}
+	for i, b := range bs {
+		if b.Min == nil {
+			return errs.BadRequest(fmt.Sprintf("taxProgressiveBrackets[%d].min is required", i))
+		}
+		if *b.Min < 0 {
+			return errs.BadRequest(fmt.Sprintf("taxProgressiveBrackets[%d].min must be zero or positive", i))
+		}
+		if b.Max != nil && *b.Max <= *b.Min {
+			return errs.BadRequest(fmt.Sprintf("taxProgressiveBrackets[%d].max must be greater than min", i))
+		}
+		if b.Rate < 0 || b.Rate > 1 {
+			return errs.BadRequest(fmt.Sprintf("taxProgressiveBrackets[%d].rate must be between 0 and 1", i))
+		}
+		if i == 0 {
+			continue
+		}
+		prev := bs[i-1]
+		if *b.Min < *prev.Min {
+			return errs.BadRequest(fmt.Sprintf("taxProgressiveBrackets[%d] must not start below the bracket before it; order the brackets by min", i))
+		}
+		if prev.Max == nil || *b.Min < *prev.Max {
+			return errs.BadRequest(fmt.Sprintf("taxProgressiveBrackets[%d] overlaps the bracket before it", i))
+		}
+	}
+	return nil
+}
+
+func deref(s *string, fallback string) string {
+	if s == nil || *s == "" {
+		return fallback
+	}
+	return *s
+}
@@ -0,0 +1,59 @@
+package simulate
+
+import (
+	"strings"
+	"testing"
+
+	"hrms/modules/payrollrun/internal/engine"
+)
+
+func ptr(v float64) *float64 { return &v }
+
+func TestValidateBrackets(t *testing.T) {
+	tests := []struct {
+		name    string
+		bs      []engine.Bracket
+		wantErr string
+	}{
+		{"ordered", []engine.Bracket{
+			{Min: ptr(0), Max: ptr(150000), Rate: 0},
+			{Min: ptr(150000), Max: ptr(300000), Rate: 0.05},
+			{Min: ptr(300000), Rate: 0.10},
+		}, ""},
+		{"gap between brackets", []engine.Bracket{
+			{Min: ptr(0), Max: ptr(100000), Rate: 0},
+			{Min: ptr(150000), Rate: 0.05},
+		}, ""},
+		{"empty", []engine.Bracket{}, "non-empty"},
+		{"missing min", []engine.Bracket{{Max: ptr(100), Rate: 0}}, "[0].min is required"},
+		{"negative min", []engine.Bracket{{Min: ptr(-1), Max: ptr(100), Rate: 0}}, "[0].min must be zero or positive"},
+		{"max below min", []engine.Bracket{{Min: ptr(100), Max: ptr(50), Rate: 0}}, "[0].max must be greater than min"},
+		{"negative rate", []engine.Bracket{{Min: ptr(0), Rate: -0.05}}, "[0].rate must be between 0 and 1"},
+		{"unordered", []engine.Bracket{
+			{Min: ptr(150000), Max: ptr(300000), Rate: 0.05},
+			{Min: ptr(0), Max: ptr(150000), Rate: 0},
+		}, "[1] must not start below"},
+		{"overlapping", []engine.Bracket{
+			{Min: ptr(0), Max: ptr(150000), Rate: 0},
+			{Min: ptr(100000), Max: ptr(300000), Rate: 0.05},
+		}, "[1] overlaps"},
+		{"open-ended bracket not last", []engine.Bracket{
+			{Min: ptr(0), Rate: 0},
+			{Min: ptr(150000), Max: ptr(300000), Rate: 0.05},
+		}, "[1] overlaps"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateBrackets(tt.bs)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validateBrackets() error = %v, want none", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validateBrackets() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package simulate
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// @Summary Simulate payroll cost
+// @Description จำลองต้นทุนเงินเดือนรายเดือนและรายปี เทียบ config ที่มีผลอยู่ กับ config สมมติ (เช่น อัตราประกันสังคม/เพดานค่าจ้าง/ขั้นภาษีใหม่) และ/หรือรอบขึ้นเงินเดือนที่ยัง pending สรุปรายพนักงาน แผนก และสาขา (เดือนปกติ: OT/ชั่วโมง part-time เฉลี่ย 3 งวดล่าสุด ไม่มีสาย/ลา) ไม่บันทึกข้อมูล
+// @Tags Payroll Run
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body Command true "scenario"
+// @Success 200 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 422
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /payroll-runs/simulate [post]
+func NewEndpoint(router fiber.Router) {
+	router.Post("/simulate", func(c fiber.Ctx) error {
+		var req Command
+		if err := c.Bind().Body(&req); err != nil {
+			return errs.BadRequest("invalid request body")
+		}
+		resp, err := mediator.Send[*Command, *Response](c.Context(), &req)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
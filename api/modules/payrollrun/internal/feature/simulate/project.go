@@ -0,0 +1,186 @@
+package simulate
+
+import (
+	"github.com/google/uuid"
+
+	"hrms/modules/payrollrun/internal/engine"
+	"hrms/modules/payrollrun/internal/repository"
+)
+
+// Cost is one employee-month, or a sum of them.
+type Cost struct {
+	Gross       float64 `json:"gross"`
+	EmployeeSSO float64 `json:"employeeSso"`
+	Tax         float64 `json:"tax"`
+	EmployeePF  float64 `json:"employeePf"`
+	NetPay      float64 `json:"netPay"`
+	EmployerSSO float64 `json:"employerSso"`
+	EmployerPF  float64 `json:"employerPf"`
+	// TotalCost is what the employer pays: gross plus its own SSO and PF contributions.
+	TotalCost float64 `json:"totalCost"`
+}
+
+func (c *Cost) add(o Cost) {
+	c.Gross += o.Gross
+	c.EmployeeSSO += o.EmployeeSSO
+	c.Tax += o.Tax
+	c.EmployeePF += o.EmployeePF
+	c.NetPay += o.NetPay
+	c.EmployerSSO += o.EmployerSSO
+	c.EmployerPF += o.EmployerPF
+	c.TotalCost += o.TotalCost
+}
+
+func (c Cost) rounded() Cost {
+	return Cost{
+		Gross:       engine.Round2(c.Gross),
+		EmployeeSSO: engine.Round2(c.EmployeeSSO),
+		Tax:         engine.Round2(c.Tax),
+		EmployeePF:  engine.Round2(c.EmployeePF),
+		NetPay:      engine.Round2(c.NetPay),
+		EmployerSSO: engine.Round2(c.EmployerSSO),
+		EmployerPF:  engine.Round2(c.EmployerPF),
+		TotalCost:   engine.Round2(c.TotalCost),
+	}
+}
+
+// Projection compares the current and simulated cost. Annual figures are twelve steady months.
+type Projection struct {
+	Current         Cost    `json:"current"`
+	Simulated       Cost    `json:"simulated"`
+	MonthlyDiff     float64 `json:"monthlyDiff"`
+	AnnualCurrent   float64 `json:"annualCurrent"`
+	AnnualSimulated float64 `json:"annualSimulated"`
+	AnnualDiff      float64 `json:"annualDiff"`
+}
+
+func newProjection(cur, sim Cost) Projection {
+	cur, sim = cur.rounded(), sim.rounded()
+	return Projection{
+		Current:         cur,
+		Simulated:       sim,
+		MonthlyDiff:     engine.Round2(sim.TotalCost - cur.TotalCost),
+		AnnualCurrent:   engine.Round2(cur.TotalCost * 12),
+		AnnualSimulated: engine.Round2(sim.TotalCost * 12),
+		AnnualDiff:      engine.Round2((sim.TotalCost - cur.TotalCost) * 12),
+	}
+}
+
+// scenario is one side of the comparison.
+type scenario struct {
+	cfg          engine.Config
+	employerRate float64
+	applyRaise   bool
+}
+
+// monthCost runs the engine for a typical month: average OT and part-time hours, no late or
+// leave (so attendance bonuses are earned), no bonus, advances or installments.
+func (s scenario) monthCost(e repository.SimulationEmployee) Cost {
+	emp := engine.Employee{
+		ID:                          e.EmployeeID,
+		TypeCode:                    e.EmployeeTypeCode,
+		BasePay:                     e.BasePayAmount,
+		SSOContribute:               e.SSOContribute,
+		SSODeclaredWage:             e.SSODeclaredWage,
+		PFContribute:                e.PFContribute,
+		PFRateEmployee:              e.PFRateEmployee,
+		WithholdTax:                 e.WithholdTax,
+		AllowHousing:                e.AllowHousing,
+		AllowInternet:               e.AllowInternet,
+		AllowAttendanceBonusNoLate:  e.AllowAttendanceBonusNoLate,
+		AllowAttendanceBonusNoLeave: e.AllowAttendanceBonusNoLeave,
+		TaxAllowance:                e.TaxAllowanceInput.Engine(),
+	}
+	if s.applyRaise {
+		if e.NewSalary != nil {
+			emp.BasePay = *e.NewSalary
+		}
+		if e.NewSSOWage != nil {
+			emp.SSODeclaredWage = *e.NewSSOWage
+		}
+	}
+	in := engine.Inputs{}
+	switch e.EmployeeTypeCode {
+	case engine.TypeFullTime:
+		in.OtHours = engine.Round2(e.AvgOtHours)
+		in.HolidayWorkHours = engine.Round2(e.AvgHolidayWorkHours)
+		in.HolidayOtHours = engine.Round2(e.AvgHolidayOtHours)
+	case engine.TypePartTime:
+		in.PTHours = engine.Round2(e.AvgPTHours)
+	}
+	it := engine.Calculate(s.cfg, emp, in)
+
+	c := Cost{
+		Gross:       it.IncomeTotal,
+		EmployeeSSO: it.SSOMonthAmount,
+		Tax:         it.TaxMonthAmount,
+		EmployeePF:  it.PFMonthAmount,
+		NetPay:      engine.Round2(it.IncomeTotal - it.SSOMonthAmount - it.TaxMonthAmount - it.PFMonthAmount),
+	}
+	if emp.SSOContribute {
+		c.EmployerSSO = engine.Round2(it.SSODeclaredWage * s.employerRate)
+	}
+	if emp.PFContribute {
+		c.EmployerPF = engine.Round2(it.SalaryAmount * e.PFRateEmployer)
+	}
+	c.TotalCost = engine.Round2(c.Gross + c.EmployerSSO + c.EmployerPF)
+	return c
+}
+
+type EmployeeLine struct {
+	EmployeeID       uuid.UUID  `json:"employeeId"`
+	EmployeeNumber   string     `json:"employeeNumber"`
+	EmployeeName     string     `json:"employeeName"`
+	EmployeeTypeCode string     `json:"employeeTypeCode"`
+	DepartmentName   *string    `json:"departmentName"`
+	BranchName       string     `json:"branchName"`
+	CurrentSalary    float64    `json:"currentSalary"`
+	SimulatedSalary  float64    `json:"simulatedSalary"`
+	Projection       Projection `json:"projection"`
+}
+
+type GroupLine struct {
+	ID         *uuid.UUID `json:"id"`
+	Name       string     `json:"name"`
+	Employees  int        `json:"employees"`
+	Projection Projection `json:"projection"`
+}
+
+type group struct {
+	line     GroupLine
+	cur, sim Cost
+}
+
+// groups keeps first-seen order, which follows the branch/department ordering of the rows.
+type groups struct {
+	order []string
+	byKey map[string]*group
+}
+
+func newGroups() *groups { return &groups{byKey: map[string]*group{}} }
+
+func (g *groups) add(id *uuid.UUID, name string, cur, sim Cost) {
+	key := ""
+	if id != nil {
+		key = id.String()
+	}
+	grp, ok := g.byKey[key]
+	if !ok {
+		grp = &group{line: GroupLine{ID: id, Name: name}}
+		g.byKey[key] = grp
+		g.order = append(g.order, key)
+	}
+	grp.line.Employees++
+	grp.cur.add(cur)
+	grp.sim.add(sim)
+}
+
+func (g *groups) lines() []GroupLine {
+	out := make([]GroupLine, 0, len(g.order))
+	for _, key := range g.order {
+		grp := g.byKey[key]
+		grp.line.Projection = newProjection(grp.cur, grp.sim)
+		out = append(out, grp.line)
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+package ssoexport
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// @Summary Export SSO contribution file
+// @Description สร้างไฟล์ข้อความ สปส.1-10 (ส่วนที่ 2) สำหรับยื่นเงินสมทบประกันสังคมผ่าน e-Service ของสาขาและเดือนของงวดปกติที่อนุมัติแล้ว รวมงวดเสริมที่อนุมัติในเดือนเดียวกัน (TIS-620)
+// @Tags Payroll Run
+// @Produce text/plain
+// @Security BearerAuth
+// @Param id path string true "run id"
+// @Success 200 {file} binary
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 422
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /payroll-runs/{id}/sso-file [get]
+func NewEndpoint(router fiber.Router) {
+	router.Get("/:id/sso-file", func(c fiber.Ctx) error {
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return errs.BadRequest("invalid id")
+		}
+		resp, err := mediator.Send[*Query, *Response](c.Context(), &Query{RunID: id})
+		if err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, "text/plain; charset=TIS-620")
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"%s\"", resp.FileName))
+		c.Set(fiber.HeaderContentLength, strconv.Itoa(len(resp.Data)))
+		c.Set(fiber.HeaderCacheControl, "private, no-store")
+		c.Set("X-Employee-Count", strconv.Itoa(resp.EmployeeCount))
+		c.Set("X-Total-Wage", strconv.FormatFloat(resp.TotalWage, 'f', 2, 64))
+		return c.Send(resp.Data)
+	})
+}
+
+// @Summary SSO contribution summary
+// @Description สรุปเงินสมทบประกันสังคมรายเดือน (สปส.1-10) ของสาขาและเดือนของงวด รวมทุกงวดที่อนุมัติ พร้อมรายการที่ยังไม่พร้อมยื่น
+// @Tags Payroll Run
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "run id"
+// @Success 200 {object} SummaryResponse
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /payroll-runs/{id}/sso-summary [get]
+func NewSummaryEndpoint(router fiber.Router) {
+	router.Get("/:id/sso-summary", func(c fiber.Ctx) error {
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return errs.BadRequest("invalid id")
+		}
+		resp, err := mediator.Send[*SummaryQuery, *SummaryResponse](c.Context(), &SummaryQuery{RunID: id})
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
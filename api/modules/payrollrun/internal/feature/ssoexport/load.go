@@ -0,0 +1,65 @@
+package ssoexport
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/modules/payrollrun/internal/ssofile"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+)
+
+// loadFiling builds the SSO filing of a run's branch and month: the header comes from the run's
+// org profile snapshot and branch, the lines from every approved run of that month.
+func loadFiling(ctx context.Context, repo repository.Repository, runID uuid.UUID) (*repository.Run, ssofile.Filing, error) {
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, ssofile.Filing{}, errs.Unauthorized("missing tenant context")
+	}
+	run, err := repo.Get(ctx, tenant, runID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ssofile.Filing{}, errs.NotFound("payroll run not found")
+		}
+		logger.FromContext(ctx).Error("failed to load payroll run", zap.Error(err))
+		return nil, ssofile.Filing{}, errs.Internal("failed to load payroll run")
+	}
+	settings, err := repo.GetSSOSettings(ctx, *run)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load SSO settings", zap.Error(err))
+		return nil, ssofile.Filing{}, errs.Internal("failed to load SSO settings")
+	}
+	rows, err := repo.ListSSOContributions(ctx, tenant, *run, settings.WageCap, run.SSORateEmp)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load SSO contributions", zap.Error(err))
+		return nil, ssofile.Filing{}, errs.Internal("failed to load SSO contributions")
+	}
+
+	org := run.OrgProfile()
+	filing := ssofile.Filing{
+		EmployerAccountNo: repository.Deref(org.SSOAccountNo),
+		BranchNo:          settings.BranchNo,
+		CompanyName:       org.CompanyName,
+		PayDate:           run.PayDate,
+		PeriodMonth:       run.PayrollMonth,
+		EmployeeRate:      run.SSORateEmp,
+		EmployerRate:      run.SSORateEmployer,
+	}
+	for _, row := range rows {
+		filing.Contributions = append(filing.Contributions, ssofile.Contribution{
+			CitizenID:      row.CitizenID,
+			TitleCode:      row.TitleCode,
+			FirstName:      row.FirstName,
+			LastName:       row.LastName,
+			Wage:           row.Wage,
+			EmployeeAmount: row.EmployeeAmount,
+		})
+	}
+	return run, filing, nil
+}
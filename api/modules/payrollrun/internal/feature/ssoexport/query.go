@@ -0,0 +1,67 @@
+package ssoexport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/modules/payrollrun/internal/ssofile"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+)
+
+// errNotRegular: supplementary runs are already included in the month's filing, which is
+// exported once from the regular run.
+const errNotRegular = "SSO file is filed once per branch and month; export it from the month's regular run"
+
+type Query struct {
+	RunID uuid.UUID
+}
+
+type Response struct {
+	FileName      string
+	Data          []byte
+	EmployeeCount int
+	TotalWage     float64
+}
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*Query, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) Handle(ctx context.Context, q *Query) (*Response, error) {
+	run, filing, err := loadFiling(ctx, h.repo, q.RunID)
+	if err != nil {
+		return nil, err
+	}
+	if run.RunType != repository.RunTypeRegular {
+		return nil, errs.Unprocessable(errNotRegular)
+	}
+	if run.Status != "approved" {
+		return nil, errs.Unprocessable("payroll run must be approved before exporting SSO file")
+	}
+	if err := ssofile.Validate(filing); err != nil {
+		return nil, errs.Unprocessable(err.Error())
+	}
+
+	var buf bytes.Buffer
+	if err := ssofile.Write(&buf, filing); err != nil {
+		return nil, errs.Unprocessable(err.Error())
+	}
+	totals := filing.Totals()
+	return &Response{
+		FileName:      fmt.Sprintf("sso-%s-%s.txt", filing.BranchNo, run.PayrollMonth.Format("2006-01")),
+		Data:          buf.Bytes(),
+		EmployeeCount: totals.Employees,
+		TotalWage:     float64(totals.WageSatang) / 100,
+	}, nil
+}
@@ -0,0 +1,102 @@
+package ssoexport
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/modules/payrollrun/internal/ssofile"
+	"hrms/shared/common/mediator"
+)
+
+type SummaryQuery struct {
+	RunID uuid.UUID
+}
+
+type SummaryLine struct {
+	CitizenID      string  `json:"citizenId"`
+	FirstName      string  `json:"firstName"`
+	LastName       string  `json:"lastName"`
+	Wage           float64 `json:"wage"`
+	EmployeeAmount float64 `json:"employeeAmount"`
+	EmployerAmount float64 `json:"employerAmount"`
+}
+
+// SummaryResponse is the on-screen counterpart of the สปส.1-10 form; Problems lists what
+// would stop the text file from being generated.
+type SummaryResponse struct {
+	RunID             uuid.UUID     `json:"runId"`
+	Status            string        `json:"status"`
+	PeriodMonth       time.Time     `json:"periodMonth"`
+	PayDate           time.Time     `json:"payDate"`
+	EmployerAccountNo string        `json:"employerAccountNo"`
+	BranchNo          string        `json:"branchNo"`
+	CompanyName       string        `json:"companyName"`
+	EmployeeRate      float64       `json:"employeeRate"`
+	EmployerRate      float64       `json:"employerRate"`
+	EmployeeCount     int           `json:"employeeCount"`
+	TotalWage         float64       `json:"totalWage"`
+	TotalEmployee     float64       `json:"totalEmployee"`
+	TotalEmployer     float64       `json:"totalEmployer"`
+	TotalContribution float64       `json:"totalContribution"`
+	Lines             []SummaryLine `json:"lines"`
+	Problems          []string      `json:"problems"`
+}
+
+type SummaryHandler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*SummaryQuery, *SummaryResponse] = (*SummaryHandler)(nil)
+
+func NewSummaryHandler(repo repository.Repository) *SummaryHandler {
+	return &SummaryHandler{repo: repo}
+}
+
+func (h *SummaryHandler) Handle(ctx context.Context, q *SummaryQuery) (*SummaryResponse, error) {
+	run, filing, err := loadFiling(ctx, h.repo, q.RunID)
+	if err != nil {
+		return nil, err
+	}
+	totals := filing.Totals()
+	resp := &SummaryResponse{
+		RunID:             run.ID,
+		Status:            run.Status,
+		PeriodMonth:       run.PayrollMonth,
+		PayDate:           run.PayDate,
+		EmployerAccountNo: filing.EmployerAccountNo,
+		BranchNo:          filing.BranchNo,
+		CompanyName:       filing.CompanyName,
+		EmployeeRate:      filing.EmployeeRate,
+		EmployerRate:      filing.EmployerRate,
+		EmployeeCount:     totals.Employees,
+		TotalWage:         float64(totals.WageSatang) / 100,
+		TotalEmployee:     float64(totals.EmployeeSatang) / 100,
+		TotalEmployer:     float64(totals.EmployerSatang) / 100,
+		TotalContribution: float64(totals.ContributionSatang()) / 100,
+		Lines:             make([]SummaryLine, 0, len(filing.Contributions)),
+		Problems:          []string{},
+	}
+	for _, c := range filing.Contributions {
+		resp.Lines = append(resp.Lines, SummaryLine{
+			CitizenID:      c.CitizenID,
+			FirstName:      c.FirstName,
+			LastName:       c.LastName,
+			Wage:           c.Wage,
+			EmployeeAmount: c.EmployeeAmount,
+			EmployerAmount: ssofile.EmployerAmount(c.Wage, filing.EmployerRate),
+		})
+	}
+	if err := ssofile.Validate(filing); err != nil {
+		resp.Problems = append(resp.Problems, err.Error())
+	}
+	if run.RunType != repository.RunTypeRegular {
+		resp.Problems = append(resp.Problems, errNotRegular)
+	}
+	if run.Status != "approved" {
+		resp.Problems = append(resp.Problems, "payroll run is not approved")
+	}
+	return resp, nil
+}
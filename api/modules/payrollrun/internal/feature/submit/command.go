@@ -0,0 +1,142 @@
+package submit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/payrollrun/internal/dto"
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/storage/sqldb/transactor"
+	"hrms/shared/common/validator"
+	"hrms/shared/contracts"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	ID      uuid.UUID `json:"-" validate:"required"`
+	Comment string    `json:"comment" validate:"max=1000"`
+}
+
+type Response struct {
+	dto.Run
+	Approval *contracts.ApprovalRequestDTO `json:"approval"`
+	Message  string                        `json:"message"`
+}
+
+type Handler struct {
+	repo repository.Repository
+	tx   transactor.Transactor
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, tx transactor.Transactor, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, tx: tx, eb: eb}
+}
+
+// Handle sends a pending run into its approval chain and freezes it as submitted.
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	cmd.Comment = strings.TrimSpace(cmd.Comment)
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+
+	run, err := h.repo.Get(ctx, tenant, cmd.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("payroll run not found")
+		}
+		logger.FromContext(ctx).Error("failed to load payroll run", zap.Error(err))
+		return nil, errs.Internal("failed to load payroll run")
+	}
+	if run.Status != repository.StatusPending {
+		return nil, errs.BadRequest(run.Status + " run cannot be submitted")
+	}
+	if run.TotalEmployees == 0 {
+		return nil, errs.BadRequest("payroll run has no employees")
+	}
+
+	preparers, err := h.repo.ListPreparers(ctx, run.ID)
+	if err != nil || len(preparers) == 0 {
+		logger.FromContext(ctx).Error("failed to load payroll run preparers", zap.Error(err))
+		return nil, errs.Internal("failed to load payroll run")
+	}
+
+	var (
+		updated   *repository.Run
+		submitted *contracts.SubmitApprovalResponse
+	)
+	err = h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
+		var err error
+		submitted, err = mediator.Send[*contracts.SubmitApprovalCommand, *contracts.SubmitApprovalResponse](ctxTx, &contracts.SubmitApprovalCommand{
+			CompanyID:  run.CompanyID,
+			BranchID:   &run.BranchID,
+			DocType:    contracts.ApprovalDocPayrollRun,
+			DocID:      run.ID,
+			ActorID:    user.ID,
+			PreparedBy: preparers[0],
+			Comment:    cmd.Comment,
+		})
+		if err != nil {
+			return err
+		}
+		if !submitted.Required {
+			return errs.BadRequest("no approval chain is set up for payroll runs; approve the run directly")
+		}
+		updated, err = h.repo.SetStatus(ctxTx, tenant, run.ID, repository.StatusPending, repository.StatusSubmitted, user.ID)
+		return err
+	})
+	if err != nil {
+		var appErr *errs.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.Conflict("payroll run changed, reload and try again")
+		}
+		logger.FromContext(ctx).Error("failed to submit payroll run", zap.Error(err))
+		return nil, errs.Internal("failed to submit payroll run")
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "SUBMIT",
+		EntityName: "PAYROLL_RUN",
+		EntityID:   updated.ID.String(),
+		Details: map[string]interface{}{
+			"approval_request_id": submitted.Request.ID.String(),
+			"steps":               submitted.Request.StepCount,
+			"comment":             cmd.Comment,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return &Response{
+		Run:      dto.FromRun(*updated),
+		Approval: submitted.Request,
+		Message:  "Payroll run submitted for approval.",
+	}, nil
+}
@@ -0,0 +1,49 @@
+package submit
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// @Summary Submit payroll run for approval
+// @Description ส่งงวดเงินเดือน pending เข้าลำดับการอนุมัติ งวดเปลี่ยนเป็น submitted และแก้ไขรายการไม่ได้จนกว่าจะอนุมัติหรือถูกตีกลับ
+// @Tags Payroll Run
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "run id"
+// @Param request body Command false "payload"
+// @Success 200 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 409
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /payroll-runs/{id}/submit [post]
+func NewEndpoint(router fiber.Router) {
+	router.Post("/:id/submit", func(c fiber.Ctx) error {
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return errs.BadRequest("invalid id")
+		}
+		var req Command
+		if len(c.Body()) > 0 {
+			if err := c.Bind().Body(&req); err != nil {
+				return errs.BadRequest("invalid request body")
+			}
+		}
+		req.ID = id
+
+		resp, err := mediator.Send[*Command, *Response](c.Context(), &req)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
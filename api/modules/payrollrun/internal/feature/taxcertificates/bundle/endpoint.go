@@ -0,0 +1,49 @@
+package taxcertbundle
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+)
+
+// @Summary Download withholding tax certificates for a year
+// @Description ดาวน์โหลดหนังสือรับรองการหักภาษี ณ ที่จ่าย (50 ทวิ) ของพนักงานทุกคนในบริษัท/สาขา เป็นไฟล์ zip (แยกรายคน) หรือ PDF รวมไฟล์เดียว
+// @Tags Tax Report
+// @Produce application/zip
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param year query int true "tax year (Gregorian, e.g. 2026)"
+// @Param format query string false "zip (default) or pdf"
+// @Success 200 {file} binary
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 422
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /tax-reports/certificates [get]
+func NewEndpoint(router fiber.Router) {
+	router.Get("/certificates", func(c fiber.Ctx) error {
+		year, err := strconv.Atoi(c.Query("year"))
+		if err != nil {
+			return errs.BadRequest("year must be a number")
+		}
+		resp, err := mediator.Send[*Query, *Response](c.Context(), &Query{
+			Year:   year,
+			Format: c.Query("format", FormatZip),
+		})
+		if err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, resp.ContentType)
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"%s\"", resp.FileName))
+		c.Set(fiber.HeaderContentLength, strconv.Itoa(len(resp.Data)))
+		c.Set(fiber.HeaderCacheControl, "private, no-store")
+		return c.Send(resp.Data)
+	})
+}
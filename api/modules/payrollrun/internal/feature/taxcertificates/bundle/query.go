@@ -0,0 +1,120 @@
+package taxcertbundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"hrms/modules/payrollrun/internal/pdfdoc"
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/modules/payrollrun/internal/taxcert"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/validator"
+)
+
+const (
+	FormatZip = "zip"
+	FormatPDF = "pdf"
+)
+
+type Query struct {
+	Year   int    `validate:"required,min=2000,max=2200"`
+	Format string `validate:"omitempty,oneof=zip pdf"`
+}
+
+type Response struct {
+	FileName    string
+	ContentType string
+	Data        []byte
+}
+
+type Handler struct {
+	repo  repository.Repository
+	fonts *pdfdoc.Fonts
+}
+
+var _ mediator.RequestHandler[*Query, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, fonts *pdfdoc.Fonts) *Handler {
+	return &Handler{repo: repo, fonts: fonts}
+}
+
+func (h *Handler) Handle(ctx context.Context, q *Query) (*Response, error) {
+	if err := validator.Validate(q); err != nil {
+		return nil, err
+	}
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	org, err := h.repo.GetEffectiveOrgProfile(ctx, tenant.CompanyID, time.Date(q.Year, time.December, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.Unprocessable("no payroll org profile is effective for this year")
+		}
+		logger.FromContext(ctx).Error("failed to load org profile", zap.Error(err))
+		return nil, errs.Internal("failed to load org profile")
+	}
+	rows, err := h.repo.ListCertificates(ctx, tenant, q.Year, nil)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load certificate totals", zap.Error(err))
+		return nil, errs.Internal("failed to load certificate totals")
+	}
+	if len(rows) == 0 {
+		return nil, errs.NotFound("no income recorded for this year")
+	}
+
+	doc := taxcert.Document{Org: *org, Year: q.Year, IssueDate: time.Now()}
+	base := fmt.Sprintf("50tawi-%d", q.Year)
+	if q.Format == FormatPDF {
+		data, err := h.render(doc, rows)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to render tax certificates", zap.Error(err))
+			return nil, errs.Internal("failed to generate tax certificates")
+		}
+		return &Response{FileName: base + ".pdf", ContentType: "application/pdf", Data: data}, nil
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, row := range rows {
+		data, err := h.render(doc, []repository.CertificateRow{row})
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to render tax certificate", zap.Error(err), zap.String("employeeId", row.EmployeeID.String()))
+			return nil, errs.Internal("failed to generate tax certificates")
+		}
+		w, err := zw.Create(taxcert.FileName(q.Year, row))
+		if err == nil {
+			_, err = w.Write(data)
+		}
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to write tax certificate archive", zap.Error(err))
+			return nil, errs.Internal("failed to generate tax certificates")
+		}
+	}
+	if err := zw.Close(); err != nil {
+		logger.FromContext(ctx).Error("failed to write tax certificate archive", zap.Error(err))
+		return nil, errs.Internal("failed to generate tax certificates")
+	}
+	return &Response{FileName: base + ".zip", ContentType: "application/zip", Data: buf.Bytes()}, nil
+}
+
+func (h *Handler) render(doc taxcert.Document, rows []repository.CertificateRow) ([]byte, error) {
+	pdf, err := h.fonts.New()
+	if err != nil {
+		return nil, err
+	}
+	if err := taxcert.Render(pdf, doc, rows); err != nil {
+		return nil, err
+	}
+	return pdfdoc.Output(pdf)
+}
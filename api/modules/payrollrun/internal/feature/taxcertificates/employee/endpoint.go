@@ -0,0 +1,50 @@
+package taxcertemployee
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+)
+
+// @Summary Download an employee's withholding tax certificate
+// @Description ดาวน์โหลดหนังสือรับรองการหักภาษี ณ ที่จ่าย (50 ทวิ) รายบุคคล (PDF)
+// @Tags Tax Report
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param employeeId path string true "employee id"
+// @Param year query int true "tax year (Gregorian, e.g. 2026)"
+// @Success 200 {file} binary
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Failure 422
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /tax-reports/certificates/{employeeId} [get]
+func NewEndpoint(router fiber.Router) {
+	router.Get("/certificates/:employeeId", func(c fiber.Ctx) error {
+		employeeID, err := uuid.Parse(c.Params("employeeId"))
+		if err != nil {
+			return errs.BadRequest("invalid employee id")
+		}
+		year, err := strconv.Atoi(c.Query("year"))
+		if err != nil {
+			return errs.BadRequest("year must be a number")
+		}
+		resp, err := mediator.Send[*Query, *Response](c.Context(), &Query{EmployeeID: employeeID, Year: year})
+		if err != nil {
+			return err
+		}
+		c.Set(fiber.HeaderContentType, "application/pdf")
+		c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=\"%s\"", resp.FileName))
+		c.Set(fiber.HeaderContentLength, strconv.Itoa(len(resp.Data)))
+		c.Set(fiber.HeaderCacheControl, "private, no-store")
+		return c.Send(resp.Data)
+	})
+}
@@ -0,0 +1,81 @@
+package taxcertemployee
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/payrollrun/internal/pdfdoc"
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/modules/payrollrun/internal/taxcert"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/validator"
+)
+
+type Query struct {
+	EmployeeID uuid.UUID
+	Year       int `validate:"required,min=2000,max=2200"`
+}
+
+type Response struct {
+	FileName string
+	Data     []byte
+}
+
+type Handler struct {
+	repo  repository.Repository
+	fonts *pdfdoc.Fonts
+}
+
+var _ mediator.RequestHandler[*Query, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, fonts *pdfdoc.Fonts) *Handler {
+	return &Handler{repo: repo, fonts: fonts}
+}
+
+func (h *Handler) Handle(ctx context.Context, q *Query) (*Response, error) {
+	if err := validator.Validate(q); err != nil {
+		return nil, err
+	}
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	rows, err := h.repo.ListCertificates(ctx, tenant, q.Year, &q.EmployeeID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load certificate totals", zap.Error(err))
+		return nil, errs.Internal("failed to load certificate totals")
+	}
+	if len(rows) == 0 {
+		return nil, errs.NotFound("no income recorded for this employee and year")
+	}
+	org, err := h.repo.GetEffectiveOrgProfile(ctx, tenant.CompanyID, time.Date(q.Year, time.December, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.Unprocessable("no payroll org profile is effective for this year")
+		}
+		logger.FromContext(ctx).Error("failed to load org profile", zap.Error(err))
+		return nil, errs.Internal("failed to load org profile")
+	}
+
+	pdf, err := h.fonts.New()
+	if err == nil {
+		err = taxcert.Render(pdf, taxcert.Document{Org: *org, Year: q.Year, IssueDate: time.Now()}, rows)
+	}
+	var data []byte
+	if err == nil {
+		data, err = pdfdoc.Output(pdf)
+	}
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to render tax certificate", zap.Error(err))
+		return nil, errs.Internal("failed to generate tax certificate")
+	}
+	return &Response{FileName: taxcert.FileName(q.Year, rows[0]), Data: data}, nil
+}
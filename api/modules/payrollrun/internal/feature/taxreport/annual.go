@@ -0,0 +1,63 @@
+package taxreport
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/modules/payrollrun/internal/taxfile"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/validator"
+)
+
+// AnnualQuery builds ภ.ง.ด.1ก from the year's income and tax accumulations.
+type AnnualQuery struct {
+	Year   int    `validate:"required,min=2000,max=2200"`
+	Format string `validate:"omitempty,oneof=txt json"`
+}
+
+type AnnualHandler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*AnnualQuery, *Response] = (*AnnualHandler)(nil)
+
+func NewAnnualHandler(repo repository.Repository) *AnnualHandler {
+	return &AnnualHandler{repo: repo}
+}
+
+func (h *AnnualHandler) Handle(ctx context.Context, q *AnnualQuery) (*Response, error) {
+	if err := validator.Validate(q); err != nil {
+		return nil, err
+	}
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+
+	// the agent is whoever is on the profile at year end
+	agent, err := loadAgent(ctx, h.repo, tenant.CompanyID, time.Date(q.Year, time.December, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return nil, err
+	}
+	rows, err := h.repo.ListAnnualTaxPayees(ctx, tenant, q.Year)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load annual tax payees", zap.Error(err))
+		return nil, errs.Internal("failed to load tax payees")
+	}
+
+	ret := taxfile.Return{
+		Form:      taxfile.FormPND1Kor,
+		TaxID:     repository.Deref(agent.TaxID),
+		AgentName: agent.CompanyName,
+		TaxYear:   q.Year,
+		Payees:    payees(rows),
+	}
+	return respond(ctx, ret, rows, q.Format, fileName(ret.Form, strconv.Itoa(q.Year)))
+}
@@ -0,0 +1,92 @@
+package taxreport
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// @Summary PND1 monthly withholding tax
+// @Description ภ.ง.ด.1 รายเดือน จากงวดเงินเดือนที่อนุมัติแล้วทั้งหมดของเดือน (ไฟล์ข้อความคั่นด้วย | สำหรับนำเข้า RD Prep หรือ JSON สรุป)
+// @Tags Tax Report
+// @Produce text/plain
+// @Produce json
+// @Security BearerAuth
+// @Param month query string true "payroll month (YYYY-MM)"
+// @Param format query string false "txt (default) or json"
+// @Param includeAll query bool false "include employees with no tax withheld"
+// @Success 200 {object} Summary
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 422
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /tax-reports/pnd1 [get]
+func NewMonthlyEndpoint(router fiber.Router) {
+	router.Get("/pnd1", func(c fiber.Ctx) error {
+		month, err := time.Parse("2006-01", c.Query("month"))
+		if err != nil {
+			return errs.BadRequest("month must be YYYY-MM")
+		}
+		resp, err := mediator.Send[*MonthlyQuery, *Response](c.Context(), &MonthlyQuery{
+			Month:      month,
+			Format:     c.Query("format", FormatTXT),
+			IncludeAll: c.Query("includeAll") == "true",
+		})
+		if err != nil {
+			return err
+		}
+		return send(c, resp)
+	})
+}
+
+// @Summary PND1Kor annual withholding tax summary
+// @Description ภ.ง.ด.1ก สรุปเงินได้และภาษีหัก ณ ที่จ่ายทั้งปี จากยอดสะสม (payroll_accumulation)
+// @Tags Tax Report
+// @Produce text/plain
+// @Produce json
+// @Security BearerAuth
+// @Param year query int true "tax year (Gregorian, e.g. 2026)"
+// @Param format query string false "txt (default) or json"
+// @Success 200 {object} Summary
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 422
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /tax-reports/pnd1kor [get]
+func NewAnnualEndpoint(router fiber.Router) {
+	router.Get("/pnd1kor", func(c fiber.Ctx) error {
+		year, err := strconv.Atoi(c.Query("year"))
+		if err != nil {
+			return errs.BadRequest("year must be a number")
+		}
+		resp, err := mediator.Send[*AnnualQuery, *Response](c.Context(), &AnnualQuery{
+			Year:   year,
+			Format: c.Query("format", FormatTXT),
+		})
+		if err != nil {
+			return err
+		}
+		return send(c, resp)
+	})
+}
+
+func send(c fiber.Ctx, resp *Response) error {
+	if resp.Summary != nil {
+		return response.JSON(c, fiber.StatusOK, resp.Summary)
+	}
+	c.Set(fiber.HeaderContentType, "text/plain; charset=TIS-620")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"%s\"", resp.FileName))
+	c.Set(fiber.HeaderContentLength, strconv.Itoa(len(resp.Data)))
+	c.Set(fiber.HeaderCacheControl, "private, no-store")
+	return c.Send(resp.Data)
+}
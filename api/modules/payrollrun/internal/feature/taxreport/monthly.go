@@ -0,0 +1,63 @@
+package taxreport
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/modules/payrollrun/internal/taxfile"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/validator"
+)
+
+// MonthlyQuery builds ภ.ง.ด.1 from every approved run of the month.
+type MonthlyQuery struct {
+	Month      time.Time
+	Format     string `validate:"omitempty,oneof=txt json"`
+	IncludeAll bool
+}
+
+type MonthlyHandler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*MonthlyQuery, *Response] = (*MonthlyHandler)(nil)
+
+func NewMonthlyHandler(repo repository.Repository) *MonthlyHandler {
+	return &MonthlyHandler{repo: repo}
+}
+
+func (h *MonthlyHandler) Handle(ctx context.Context, q *MonthlyQuery) (*Response, error) {
+	if err := validator.Validate(q); err != nil {
+		return nil, err
+	}
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	month := time.Date(q.Month.Year(), q.Month.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	agent, err := loadAgent(ctx, h.repo, tenant.CompanyID, month)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := h.repo.ListMonthlyTaxPayees(ctx, tenant, month, !q.IncludeAll)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load monthly tax payees", zap.Error(err))
+		return nil, errs.Internal("failed to load tax payees")
+	}
+
+	ret := taxfile.Return{
+		Form:        taxfile.FormPND1,
+		TaxID:       repository.Deref(agent.TaxID),
+		AgentName:   agent.CompanyName,
+		PeriodMonth: month,
+		Payees:      payees(rows),
+	}
+	return respond(ctx, ret, rows, q.Format, fileName(ret.Form, month.Format("2006-01")))
+}
@@ -0,0 +1,142 @@
+package taxreport
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/modules/payrollrun/internal/taxfile"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+)
+
+const (
+	FormatTXT  = "txt"
+	FormatJSON = "json"
+)
+
+type Line struct {
+	EmployeeID     uuid.UUID  `json:"employeeId"`
+	EmployeeNumber string     `json:"employeeNumber"`
+	CitizenID      string     `json:"citizenId"`
+	TitleName      string     `json:"titleName"`
+	FirstName      string     `json:"firstName"`
+	LastName       string     `json:"lastName"`
+	PayDate        *time.Time `json:"payDate,omitempty"`
+	Income         float64    `json:"income"`
+	Tax            float64    `json:"tax"`
+}
+
+// Summary is the JSON form of a return; Problems lists what would stop the file from being generated.
+type Summary struct {
+	Form        string     `json:"form"`
+	TaxID       string     `json:"taxId"`
+	AgentName   string     `json:"agentName"`
+	PeriodMonth *time.Time `json:"periodMonth,omitempty"`
+	TaxYear     *int       `json:"taxYear,omitempty"`
+	PayeeCount  int        `json:"payeeCount"`
+	TotalIncome float64    `json:"totalIncome"`
+	TotalTax    float64    `json:"totalTax"`
+	Lines       []Line     `json:"lines"`
+	Problems    []string   `json:"problems"`
+}
+
+type Response struct {
+	Summary  *Summary
+	FileName string
+	Data     []byte
+}
+
+// loadAgent resolves the withholding agent from the org profile effective on the given date.
+func loadAgent(ctx context.Context, repo repository.Repository, companyID uuid.UUID, on time.Time) (*repository.OrgProfile, error) {
+	agent, err := repo.GetEffectiveOrgProfile(ctx, companyID, on)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.Unprocessable("no payroll org profile is effective for this period")
+		}
+		logger.FromContext(ctx).Error("failed to load org profile", zap.Error(err))
+		return nil, errs.Internal("failed to load org profile")
+	}
+	return agent, nil
+}
+
+// respond turns a return into either its JSON summary or the RD upload file.
+func respond(ctx context.Context, ret taxfile.Return, rows []repository.TaxPayee, format, fileName string) (*Response, error) {
+	if format == FormatJSON {
+		t := ret.Totals()
+		s := &Summary{
+			Form:        ret.Form,
+			TaxID:       ret.TaxID,
+			AgentName:   ret.AgentName,
+			PayeeCount:  t.Payees,
+			TotalIncome: t.Income,
+			TotalTax:    t.Tax,
+			Lines:       make([]Line, 0, len(rows)),
+			Problems:    []string{},
+		}
+		if ret.Form == taxfile.FormPND1 {
+			s.PeriodMonth = &ret.PeriodMonth
+		} else {
+			s.TaxYear = &ret.TaxYear
+		}
+		for _, row := range rows {
+			line := Line{
+				EmployeeID:     row.EmployeeID,
+				EmployeeNumber: row.EmployeeNumber,
+				CitizenID:      row.CitizenID,
+				TitleName:      row.TitleName,
+				FirstName:      row.FirstName,
+				LastName:       row.LastName,
+				Income:         row.Income,
+				Tax:            row.Tax,
+			}
+			if ret.Form == taxfile.FormPND1 {
+				payDate := row.PayDate
+				line.PayDate = &payDate
+			}
+			s.Lines = append(s.Lines, line)
+		}
+		if err := taxfile.Validate(ret); err != nil {
+			s.Problems = append(s.Problems, err.Error())
+		}
+		return &Response{Summary: s}, nil
+	}
+
+	if err := taxfile.Validate(ret); err != nil {
+		return nil, errs.Unprocessable(err.Error())
+	}
+	var buf bytes.Buffer
+	if err := taxfile.Write(&buf, ret); err != nil {
+		logger.FromContext(ctx).Error("failed to write tax file", zap.Error(err))
+		return nil, errs.Internal("failed to generate tax file")
+	}
+	return &Response{FileName: fileName, Data: buf.Bytes()}, nil
+}
+
+func payees(rows []repository.TaxPayee) []taxfile.Payee {
+	out := make([]taxfile.Payee, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, taxfile.Payee{
+			CitizenID: row.CitizenID,
+			TitleName: row.TitleName,
+			FirstName: row.FirstName,
+			LastName:  row.LastName,
+			PayDate:   row.PayDate,
+			Income:    row.Income,
+			Tax:       row.Tax,
+		})
+	}
+	return out
+}
+
+func fileName(form, period string) string {
+	return fmt.Sprintf("%s-%s.txt", strings.ToLower(form), period)
+}
@@ -11,6 +11,7 @@ import (
 	"go.uber.org/zap"
 
 	"hrms/modules/payrollrun/internal/dto"
+	"hrms/modules/payrollrun/internal/feature/approve"
 	"hrms/modules/payrollrun/internal/repository"
 	"hrms/shared/common/contextx"
 	"hrms/shared/common/errs"
@@ -51,6 +52,15 @@ func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
 		return nil, err
 	}
 
+	// status=approved is kept for older clients; it goes through the approval workflow like POST /:id/approve
+	if cmd.Status == "approved" {
+		approved, err := mediator.Send[*approve.Command, *approve.Response](ctx, &approve.Command{ID: cmd.ID})
+		if err != nil {
+			return nil, err
+		}
+		return &Response{Run: approved.Run, Message: approved.Message}, nil
+	}
+
 	tenant, ok := contextx.TenantFromContext(ctx)
 	if !ok {
 		return nil, errs.Unauthorized("missing tenant context")
@@ -82,24 +92,15 @@ func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
 		logger.FromContext(ctx).Error("failed to load payroll run", zap.Error(err))
 		return nil, errs.Internal("failed to load payroll run")
 	}
-	if run.Status == "approved" {
-		return nil, errs.BadRequest("approved run cannot be modified")
+	if run.Status != "pending" {
+		return nil, errs.BadRequest(run.Status + " run cannot be modified")
 	}
 
-	if cmd.Status == "approved" && user.Role != "admin" {
-		return nil, errs.Forbidden("only admin can approve payroll run")
-	}
-
-	var updated *repository.Run
-	if cmd.Status == "approved" {
-		updated, err = h.repo.Approve(ctx, tenant, cmd.ID, user.ID)
-	} else {
-		newStatus := run.Status
-		if cmd.Status != "" {
-			newStatus = cmd.Status
-		}
-		updated, err = h.repo.UpdateStatus(ctx, tenant, cmd.ID, newStatus, payDate, user.ID)
+	newStatus := run.Status
+	if cmd.Status != "" {
+		newStatus = cmd.Status
 	}
+	updated, err := h.repo.UpdateStatus(ctx, tenant, cmd.ID, newStatus, payDate, user.ID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, errs.BadRequest("cannot update payroll run")
@@ -129,11 +130,7 @@ func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
 		})
 	}
 
-	resp := &Response{Run: dto.FromRun(*updated)}
-	if cmd.Status == "approved" {
-		resp.Message = "Payroll approved successfully. All related records updated."
-	}
-	return resp, nil
+	return &Response{Run: dto.FromRun(*updated)}, nil
 }
 
 func parseDate(raw, field string) (time.Time, error) {
@@ -10,7 +10,7 @@ import (
 )
 
 // @Summary Update/approve payroll run
-// @Description แก้ payDate หรืออนุมัติ run
+// @Description แก้ payDate หรืออนุมัติ run (status=approved ทำงานเหมือน POST /payroll-runs/{id}/approve)
 // @Tags Payroll Run
 // @Accept json
 // @Produce json
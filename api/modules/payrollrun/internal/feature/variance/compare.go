@@ -0,0 +1,189 @@
+package variance
+
+import (
+	"math"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/payrollrun/internal/repository"
+)
+
+const (
+	StatusNewHire   = "new_hire"
+	StatusLeaver    = "leaver"
+	StatusChanged   = "changed"
+	StatusUnchanged = "unchanged"
+)
+
+// Thresholds decide when a field change is flagged: the absolute difference must reach Amount
+// and the relative change must reach Percent. A zero value disables that half of the test.
+type Thresholds struct {
+	Percent float64 `json:"percent"`
+	Amount  float64 `json:"amount"`
+}
+
+type FieldDiff struct {
+	Field    string   `json:"field"`
+	Previous float64  `json:"previous"`
+	Current  float64  `json:"current"`
+	Diff     float64  `json:"diff"`
+	Percent  *float64 `json:"percent"`
+	Flagged  bool     `json:"flagged"`
+}
+
+type Line struct {
+	EmployeeID     uuid.UUID   `json:"employeeId"`
+	EmployeeNumber string      `json:"employeeNumber"`
+	EmployeeName   string      `json:"employeeName"`
+	DepartmentName *string     `json:"departmentName"`
+	Status         string      `json:"status"`
+	Flagged        bool        `json:"flagged"`
+	Fields         []FieldDiff `json:"fields"`
+}
+
+type Summary struct {
+	Employees         int         `json:"employees"`
+	PreviousEmployees int         `json:"previousEmployees"`
+	NewHires          int         `json:"newHires"`
+	Leavers           int         `json:"leavers"`
+	Changed           int         `json:"changed"`
+	Flagged           int         `json:"flagged"`
+	Totals            []FieldDiff `json:"totals"`
+}
+
+type field struct {
+	name string
+	get  func(repository.VarianceLine) float64
+}
+
+// fields are compared in this order on every line and in the totals.
+var fields = []field{
+	{"salary", func(l repository.VarianceLine) float64 { return l.SalaryAmount }},
+	{"ot", func(l repository.VarianceLine) float64 { return l.OtAmount }},
+	{"bonus", func(l repository.VarianceLine) float64 { return l.BonusAmount }},
+	{"income", func(l repository.VarianceLine) float64 { return l.IncomeTotal }},
+	{"deduction", func(l repository.VarianceLine) float64 { return l.DeductionTotal }},
+	{"tax", func(l repository.VarianceLine) float64 { return l.TaxMonthAmount }},
+	{"sso", func(l repository.VarianceLine) float64 { return l.SsoMonthAmount }},
+	{"pf", func(l repository.VarianceLine) float64 { return l.PfMonthAmount }},
+	{"netPay", func(l repository.VarianceLine) float64 { return l.NetPay }},
+}
+
+// compare matches the two runs by employee. New hires and leavers are always flagged;
+// matched employees are flagged when any field crosses the thresholds.
+// Flagged lines sort first, then by employee number.
+func compare(current, previous []repository.VarianceLine, t Thresholds) ([]Line, Summary) {
+	prevByID := make(map[uuid.UUID]repository.VarianceLine, len(previous))
+	for _, p := range previous {
+		prevByID[p.EmployeeID] = p
+	}
+	seen := make(map[uuid.UUID]bool, len(current))
+	curTotal := make([]float64, len(fields))
+	prevTotal := make([]float64, len(fields))
+
+	summary := Summary{Employees: len(current), PreviousEmployees: len(previous)}
+	lines := make([]Line, 0, len(current)+len(previous))
+	for _, c := range current {
+		seen[c.EmployeeID] = true
+		p, matched := prevByID[c.EmployeeID]
+		line := newLine(c)
+		for i, f := range fields {
+			cur := f.get(c)
+			prev := 0.0
+			if matched {
+				prev = f.get(p)
+			}
+			curTotal[i] += cur
+			prevTotal[i] += prev
+			d := diff(f.name, prev, cur, t)
+			if matched && d.Flagged {
+				line.Flagged = true
+			}
+			line.Fields = append(line.Fields, d)
+		}
+		switch {
+		case !matched:
+			line.Status = StatusNewHire
+			line.Flagged = true
+			summary.NewHires++
+		case hasChange(line.Fields):
+			line.Status = StatusChanged
+			summary.Changed++
+		default:
+			line.Status = StatusUnchanged
+		}
+		lines = append(lines, line)
+	}
+	for _, p := range previous {
+		if seen[p.EmployeeID] {
+			continue
+		}
+		line := newLine(p)
+		line.Status = StatusLeaver
+		line.Flagged = true
+		for i, f := range fields {
+			prev := f.get(p)
+			prevTotal[i] += prev
+			line.Fields = append(line.Fields, diff(f.name, prev, 0, t))
+		}
+		summary.Leavers++
+		lines = append(lines, line)
+	}
+
+	for _, l := range lines {
+		if l.Flagged {
+			summary.Flagged++
+		}
+	}
+	summary.Totals = make([]FieldDiff, 0, len(fields))
+	for i, f := range fields {
+		summary.Totals = append(summary.Totals, diff(f.name, round2(prevTotal[i]), round2(curTotal[i]), t))
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		if lines[i].Flagged != lines[j].Flagged {
+			return lines[i].Flagged
+		}
+		return lines[i].EmployeeNumber < lines[j].EmployeeNumber
+	})
+	return lines, summary
+}
+
+func newLine(v repository.VarianceLine) Line {
+	return Line{
+		EmployeeID:     v.EmployeeID,
+		EmployeeNumber: v.EmployeeNumber,
+		EmployeeName:   v.EmployeeName,
+		DepartmentName: v.DepartmentName,
+		Fields:         make([]FieldDiff, 0, len(fields)),
+	}
+}
+
+func diff(name string, prev, cur float64, t Thresholds) FieldDiff {
+	d := FieldDiff{Field: name, Previous: prev, Current: cur, Diff: round2(cur - prev)}
+	abs := math.Abs(d.Diff)
+	if prev != 0 {
+		pct := round2(d.Diff / math.Abs(prev) * 100)
+		d.Percent = &pct
+	}
+	if abs < 0.01 {
+		return d
+	}
+	amountHit := abs >= t.Amount
+	percentHit := t.Percent <= 0 || d.Percent == nil || math.Abs(*d.Percent) >= t.Percent
+	d.Flagged = amountHit && percentHit
+	return d
+}
+
+func hasChange(ds []FieldDiff) bool {
+	for _, d := range ds {
+		if d.Diff != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,109 @@
+package variance
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/payrollrun/internal/repository"
+)
+
+func TestCompare(t *testing.T) {
+	ids := map[string]uuid.UUID{}
+	line := func(number string, salary float64) repository.VarianceLine {
+		if _, ok := ids[number]; !ok {
+			ids[number] = uuid.New()
+		}
+		return repository.VarianceLine{EmployeeID: ids[number], EmployeeNumber: number, SalaryAmount: salary, NetPay: salary}
+	}
+	previous := []repository.VarianceLine{
+		line("E001", 30000), line("E002", 30000), line("E003", 30000), line("E005", 25000),
+	}
+	current := []repository.VarianceLine{
+		line("E004", 20000), line("E003", 40000), line("E002", 30100), line("E001", 30000),
+	}
+
+	lines, summary := compare(current, previous, Thresholds{Percent: 5, Amount: 500})
+
+	want := []struct {
+		number  string
+		status  string
+		flagged bool
+	}{
+		{"E003", StatusChanged, true},
+		{"E004", StatusNewHire, true},
+		{"E005", StatusLeaver, true},
+		{"E001", StatusUnchanged, false},
+		{"E002", StatusChanged, false},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("compare() returned %d lines, want %d", len(lines), len(want))
+	}
+	for i, w := range want {
+		l := lines[i]
+		if l.EmployeeNumber != w.number || l.Status != w.status || l.Flagged != w.flagged {
+			t.Errorf("line %d = %s %s flagged=%v, want %s %s flagged=%v",
+				i, l.EmployeeNumber, l.Status, l.Flagged, w.number, w.status, w.flagged)
+		}
+		if len(l.Fields) != len(fields) {
+			t.Errorf("line %d has %d fields, want %d", i, len(l.Fields), len(fields))
+		}
+	}
+	if leaver := lines[2].Fields[0]; leaver.Previous != 25000 || leaver.Current != 0 || leaver.Diff != -25000 {
+		t.Errorf("leaver salary = %+v, want 25000 -> 0", leaver)
+	}
+
+	wantSummary := Summary{Employees: 4, PreviousEmployees: 4, NewHires: 1, Leavers: 1, Changed: 2, Flagged: 3}
+	got := summary
+	got.Totals = nil
+	if !reflect.DeepEqual(got, wantSummary) {
+		t.Errorf("compare() summary = %+v, want %+v", got, wantSummary)
+	}
+	salary := summary.Totals[0]
+	if salary.Field != "salary" || salary.Previous != 115000 || salary.Current != 120100 || salary.Diff != 5100 {
+		t.Errorf("salary total = %+v, want 115000 -> 120100", salary)
+	}
+	if salary.Percent == nil || *salary.Percent != 4.43 || salary.Flagged {
+		t.Errorf("salary total percent = %v flagged=%v, want 4.43 not flagged", salary.Percent, salary.Flagged)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name        string
+		prev, cur   float64
+		t           Thresholds
+		wantPercent *float64
+		wantFlagged bool
+	}{
+		{"both thresholds reached", 1000, 1200, Thresholds{Percent: 10, Amount: 100}, ptr(20), true},
+		{"amount below threshold", 1000, 1050, Thresholds{Percent: 1, Amount: 100}, ptr(5), false},
+		{"percent below threshold", 100000, 100500, Thresholds{Percent: 1, Amount: 100}, ptr(0.5), false},
+		{"zero percent disables the percent test", 100000, 100500, Thresholds{Amount: 100}, ptr(0.5), true},
+		{"zero thresholds flag any change", 100, 100.01, Thresholds{}, ptr(0.01), true},
+		{"no previous value skips the percent test", 0, 500, Thresholds{Percent: 50, Amount: 100}, nil, true},
+		{"sub-satang drift is not a change", 100, 100.004, Thresholds{}, ptr(0), false},
+		{"decrease", 1000, 500, Thresholds{Percent: 10, Amount: 100}, ptr(-50), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := diff("salary", tt.prev, tt.cur, tt.t)
+			if (d.Percent == nil) != (tt.wantPercent == nil) || (d.Percent != nil && *d.Percent != *tt.wantPercent) {
+				t.Errorf("diff() percent = %v, want %v", deref(d.Percent), deref(tt.wantPercent))
+			}
+			if d.Flagged != tt.wantFlagged {
+				t.Errorf("diff() flagged = %v, want %v", d.Flagged, tt.wantFlagged)
+			}
+		})
+	}
+}
+
+func ptr(v float64) *float64 { return &v }
+
+func deref(p *float64) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
@@ -0,0 +1,67 @@
+package variance
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// @Summary Payroll run variance
+// @Description เปรียบเทียบงวดเงินเดือนกับงวดที่อนุมัติก่อนหน้า (ประเภทเดียวกัน สาขาเดียวกัน) รายพนักงานและรายช่อง: เงินเดือน OT โบนัส รายได้รวม รายการหัก ภาษี ประกันสังคม กองทุนสำรองเลี้ยงชีพ และเงินได้สุทธิ พร้อมระบุพนักงานใหม่ พนักงานที่ออก และรายการที่เปลี่ยนแปลงเกินเกณฑ์
+// @Tags Payroll Run
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "run id"
+// @Param against query string false "run id to compare with (default: previous approved run)"
+// @Param thresholdPercent query number false "flag when change is at least this percent (default 10, 0 = any)"
+// @Param thresholdAmount query number false "flag when change is at least this amount in baht (default 500, 0 = any)"
+// @Param flaggedOnly query bool false "return flagged lines only"
+// @Success 200 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /payroll-runs/{id}/variance [get]
+func NewEndpoint(router fiber.Router) {
+	router.Get("/:id/variance", func(c fiber.Ctx) error {
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return errs.BadRequest("invalid id")
+		}
+		q := Query{
+			RunID:       id,
+			Thresholds:  Thresholds{Percent: DefaultThresholdPercent, Amount: DefaultThresholdAmount},
+			FlaggedOnly: c.Query("flaggedOnly") == "true",
+		}
+		if s := c.Query("against"); s != "" {
+			againstID, err := uuid.Parse(s)
+			if err != nil {
+				return errs.BadRequest("invalid against")
+			}
+			q.AgainstID = &againstID
+		}
+		if s := c.Query("thresholdPercent"); s != "" {
+			if q.Thresholds.Percent, err = strconv.ParseFloat(s, 64); err != nil {
+				return errs.BadRequest("invalid thresholdPercent")
+			}
+		}
+		if s := c.Query("thresholdAmount"); s != "" {
+			if q.Thresholds.Amount, err = strconv.ParseFloat(s, 64); err != nil {
+				return errs.BadRequest("invalid thresholdAmount")
+			}
+		}
+
+		resp, err := mediator.Send[*Query, *Response](c.Context(), &q)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
@@ -0,0 +1,136 @@
+package variance
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+)
+
+const (
+	DefaultThresholdPercent = 10.0
+	DefaultThresholdAmount  = 500.0
+)
+
+type Query struct {
+	RunID       uuid.UUID
+	AgainstID   *uuid.UUID
+	Thresholds  Thresholds
+	FlaggedOnly bool
+}
+
+type RunRef struct {
+	ID           uuid.UUID `json:"id"`
+	PayrollMonth time.Time `json:"payrollMonth"`
+	RunType      string    `json:"runType"`
+	Status       string    `json:"status"`
+}
+
+// Response.Previous is nil when the branch has no earlier approved run of the type; every line is then a new hire.
+type Response struct {
+	Run        RunRef     `json:"run"`
+	Previous   *RunRef    `json:"previous"`
+	Thresholds Thresholds `json:"thresholds"`
+	Summary    Summary    `json:"summary"`
+	Lines      []Line     `json:"lines"`
+}
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*Query, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) Handle(ctx context.Context, q *Query) (*Response, error) {
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	if q.Thresholds.Percent < 0 || q.Thresholds.Amount < 0 {
+		return nil, errs.BadRequest("thresholds must not be negative")
+	}
+
+	run, err := h.repo.Get(ctx, tenant, q.RunID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("payroll run not found")
+		}
+		logger.FromContext(ctx).Error("failed to load payroll run", zap.Error(err))
+		return nil, errs.Internal("failed to load payroll run")
+	}
+
+	var prev *repository.Run
+	if q.AgainstID != nil {
+		if *q.AgainstID == run.ID {
+			return nil, errs.BadRequest("cannot compare a run with itself")
+		}
+		prev, err = h.repo.Get(ctx, tenant, *q.AgainstID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, errs.NotFound("comparison payroll run not found")
+			}
+			logger.FromContext(ctx).Error("failed to load comparison payroll run", zap.Error(err))
+			return nil, errs.Internal("failed to load comparison payroll run")
+		}
+	} else {
+		prev, err = h.repo.PreviousApprovedRun(ctx, tenant, *run)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			logger.FromContext(ctx).Error("failed to find previous payroll run", zap.Error(err))
+			return nil, errs.Internal("failed to find previous payroll run")
+		}
+	}
+
+	current, err := h.repo.ListVarianceLines(ctx, tenant, run.ID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load payroll items", zap.Error(err))
+		return nil, errs.Internal("failed to load payroll items")
+	}
+	var previous []repository.VarianceLine
+	if prev != nil {
+		previous, err = h.repo.ListVarianceLines(ctx, tenant, prev.ID)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to load previous payroll items", zap.Error(err))
+			return nil, errs.Internal("failed to load previous payroll items")
+		}
+	}
+
+	lines, summary := compare(current, previous, q.Thresholds)
+	if q.FlaggedOnly {
+		kept := lines[:0]
+		for _, l := range lines {
+			if l.Flagged {
+				kept = append(kept, l)
+			}
+		}
+		lines = kept
+	}
+
+	resp := &Response{
+		Run:        refOf(*run),
+		Thresholds: q.Thresholds,
+		Summary:    summary,
+		Lines:      lines,
+	}
+	if prev != nil {
+		ref := refOf(*prev)
+		resp.Previous = &ref
+	}
+	return resp, nil
+}
+
+func refOf(r repository.Run) RunRef {
+	return RunRef{ID: r.ID, PayrollMonth: r.PayrollMonth, RunType: r.RunType, Status: r.Status}
+}
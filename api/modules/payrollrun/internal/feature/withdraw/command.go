@@ -0,0 +1,107 @@
+package withdraw
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/payrollrun/internal/dto"
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/storage/sqldb/transactor"
+	"hrms/shared/contracts"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	ID uuid.UUID
+}
+
+type Response struct {
+	dto.Run
+	Message string `json:"message"`
+}
+
+type Handler struct {
+	repo repository.Repository
+	tx   transactor.Transactor
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, tx transactor.Transactor, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, tx: tx, eb: eb}
+}
+
+// Handle lets the preparer (or an admin) pull a submitted run back to draft.
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+
+	run, err := h.repo.Get(ctx, tenant, cmd.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("payroll run not found")
+		}
+		logger.FromContext(ctx).Error("failed to load payroll run", zap.Error(err))
+		return nil, errs.Internal("failed to load payroll run")
+	}
+	if run.Status != repository.StatusSubmitted {
+		return nil, errs.BadRequest("only submitted run can be withdrawn")
+	}
+
+	var updated *repository.Run
+	err = h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
+		if _, err := mediator.Send[*contracts.CancelApprovalCommand, *contracts.CancelApprovalResponse](ctxTx, &contracts.CancelApprovalCommand{
+			CompanyID: run.CompanyID,
+			DocType:   contracts.ApprovalDocPayrollRun,
+			DocID:     run.ID,
+			ActorID:   user.ID,
+			ActorRole: user.Role,
+		}); err != nil {
+			return err
+		}
+		var err error
+		updated, err = h.repo.SetStatus(ctxTx, tenant, run.ID, repository.StatusSubmitted, repository.StatusPending, user.ID)
+		return err
+	})
+	if err != nil {
+		var appErr *errs.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.Conflict("payroll run changed, reload and try again")
+		}
+		logger.FromContext(ctx).Error("failed to withdraw payroll run", zap.Error(err))
+		return nil, errs.Internal("failed to withdraw payroll run")
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "WITHDRAW",
+		EntityName: "PAYROLL_RUN",
+		EntityID:   updated.ID.String(),
+		Details:    map[string]interface{}{},
+		Timestamp:  time.Now(),
+	})
+
+	return &Response{Run: dto.FromRun(*updated), Message: "Payroll run withdrawn from approval."}, nil
+}
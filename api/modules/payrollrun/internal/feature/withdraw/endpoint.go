@@ -0,0 +1,38 @@
+package withdraw
+
+import (
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// @Summary Withdraw payroll run from approval
+// @Description ผู้จัดทำ (หรือ admin) ดึงงวดที่ส่งอนุมัติกลับมาเป็น pending เพื่อแก้ไข
+// @Tags Payroll Run
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "run id"
+// @Success 200 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /payroll-runs/{id}/withdraw [post]
+func NewEndpoint(router fiber.Router) {
+	router.Post("/:id/withdraw", func(c fiber.Ctx) error {
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return errs.BadRequest("invalid id")
+		}
+		resp, err := mediator.Send[*Command, *Response](c.Context(), &Command{ID: id})
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
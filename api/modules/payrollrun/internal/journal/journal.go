@@ -0,0 +1,299 @@
+// Package journal turns a payroll run into a balanced general ledger entry for the
+// accounting system.
+//
+// Amounts are carried in satang so debits and credits tie out exactly. Expense lines can be
+// split per department (cost centre); payables and receivables are posted once per run.
+package journal
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Account keys, mirrored by the gl_account_key domain.
+const (
+	SalaryExpense      = "salary_expense"
+	OTExpense          = "ot_expense"
+	SSOEmployerExpense = "sso_employer_expense"
+	PFEmployerExpense  = "pf_employer_expense"
+	TaxPayable         = "tax_payable"
+	SSOPayable         = "sso_payable"
+	PFPayable          = "pf_payable"
+	LoanReceivable     = "loan_receivable"
+	AdvanceReceivable  = "advance_receivable"
+	OtherDeduction     = "other_deduction"
+	NetPayPayable      = "net_pay_payable"
+)
+
+// Keys lists the accounts in posting order: debits first, then credits.
+var Keys = []string{
+	SalaryExpense, OTExpense, SSOEmployerExpense, PFEmployerExpense,
+	TaxPayable, SSOPayable, PFPayable, LoanReceivable, AdvanceReceivable, OtherDeduction, NetPayPayable,
+}
+
+var debitKeys = map[string]bool{
+	SalaryExpense: true, OTExpense: true, SSOEmployerExpense: true, PFEmployerExpense: true,
+}
+
+// IsDebit reports whether the account is posted on the debit side.
+func IsDebit(key string) bool { return debitKeys[key] }
+
+// IsKey reports whether key is a known account key.
+func IsKey(key string) bool {
+	for _, k := range Keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Amounts is one employee's (or one department's) share of the run.
+type Amounts struct {
+	Salary      float64 // earnings other than OT, net of leave/late deductions
+	OT          float64
+	SSOEmployee float64
+	SSOEmployer float64
+	PFEmployee  float64
+	PFEmployer  float64
+	Tax         float64
+	Loan        float64
+	Advance     float64
+	Other       float64 // utilities and other deductions kept by the company
+	NetPay      float64
+}
+
+func (a *Amounts) Add(b Amounts) {
+	a.Salary += b.Salary
+	a.OT += b.OT
+	a.SSOEmployee += b.SSOEmployee
+	a.SSOEmployer += b.SSOEmployer
+	a.PFEmployee += b.PFEmployee
+	a.PFEmployer += b.PFEmployer
+	a.Tax += b.Tax
+	a.Loan += b.Loan
+	a.Advance += b.Advance
+	a.Other += b.Other
+	a.NetPay += b.NetPay
+}
+
+func (a Amounts) amount(key string) float64 {
+	switch key {
+	case SalaryExpense:
+		return a.Salary
+	case OTExpense:
+		return a.OT
+	case SSOEmployerExpense:
+		return a.SSOEmployer
+	case PFEmployerExpense:
+		return a.PFEmployer
+	case TaxPayable:
+		return a.Tax
+	case SSOPayable:
+		return a.SSOEmployee + a.SSOEmployer
+	case PFPayable:
+		return a.PFEmployee + a.PFEmployer
+	case LoanReceivable:
+		return a.Loan
+	case AdvanceReceivable:
+		return a.Advance
+	case OtherDeduction:
+		return a.Other
+	case NetPayPayable:
+		return a.NetPay
+	}
+	return 0
+}
+
+// Department is a cost centre's share of the run. ID is nil for employees without a department.
+type Department struct {
+	ID   *uuid.UUID
+	Code string
+	Name string
+	Amounts
+}
+
+type Account struct {
+	Code string
+	Name string
+}
+
+// Chart is a company's chart-of-accounts mapping. A department mapping takes precedence over
+// the company default for that department's expense lines.
+type Chart struct {
+	defaults     map[string]Account
+	byDepartment map[uuid.UUID]map[string]Account
+}
+
+func NewChart() Chart {
+	return Chart{defaults: map[string]Account{}, byDepartment: map[uuid.UUID]map[string]Account{}}
+}
+
+func (c Chart) Set(key string, departmentID *uuid.UUID, acc Account) {
+	if departmentID == nil {
+		c.defaults[key] = acc
+		return
+	}
+	if c.byDepartment[*departmentID] == nil {
+		c.byDepartment[*departmentID] = map[string]Account{}
+	}
+	c.byDepartment[*departmentID][key] = acc
+}
+
+func (c Chart) Lookup(key string, departmentID *uuid.UUID) (Account, bool) {
+	if departmentID != nil {
+		if acc, ok := c.byDepartment[*departmentID][key]; ok {
+			return acc, true
+		}
+	}
+	acc, ok := c.defaults[key]
+	return acc, ok
+}
+
+// Header describes the entry as a whole.
+type Header struct {
+	Reference   string
+	Date        time.Time
+	Description string
+	// Reverse swaps debits and credits, for the entry that cancels a reversed run.
+	Reverse bool
+}
+
+type Line struct {
+	AccountKey     string
+	AccountCode    string
+	AccountName    string
+	DepartmentCode string
+	DepartmentName string
+	DebitSatang    int64
+	CreditSatang   int64
+}
+
+type Entry struct {
+	Header
+	Lines []Line
+}
+
+func (e Entry) Totals() (debit, credit int64) {
+	for _, l := range e.Lines {
+		debit += l.DebitSatang
+		credit += l.CreditSatang
+	}
+	return debit, credit
+}
+
+// MissingAccountsError lists the account keys with an amount but no mapping.
+type MissingAccountsError struct {
+	Keys []string
+}
+
+func (e *MissingAccountsError) Error() string {
+	return fmt.Sprintf("no GL account mapped for %v", e.Keys)
+}
+
+// Build posts the departments' amounts to the chart. With splitByDepartment each expense line
+// carries its department; otherwise lines on the same account are merged. Zero lines are left out.
+func Build(h Header, departments []Department, chart Chart, splitByDepartment bool) (Entry, error) {
+	depts := append([]Department(nil), departments...)
+	sort.SliceStable(depts, func(i, j int) bool { return depts[i].Code < depts[j].Code })
+
+	var total Amounts
+	for _, d := range depts {
+		total.Add(d.Amounts)
+	}
+
+	entry := Entry{Header: h}
+	missing := map[string]bool{}
+	for _, key := range Keys {
+		if !IsDebit(key) {
+			amt := Satang(total.amount(key))
+			if amt == 0 {
+				continue
+			}
+			acc, ok := chart.Lookup(key, nil)
+			if !ok {
+				missing[key] = true
+				continue
+			}
+			entry.Lines = append(entry.Lines, newLine(key, acc, Department{}, amt, h.Reverse))
+			continue
+		}
+
+		// expense: one line per account (and department when split), in department order
+		index := map[string]int{}
+		for _, d := range depts {
+			amt := Satang(d.amount(key))
+			if amt == 0 {
+				continue
+			}
+			acc, ok := chart.Lookup(key, d.ID)
+			if !ok {
+				missing[key] = true
+				continue
+			}
+			dept := Department{}
+			if splitByDepartment {
+				dept = d
+			}
+			id := acc.Code + "|" + dept.Code + "|" + dept.Name
+			if i, ok := index[id]; ok {
+				addAmount(&entry.Lines[i], amt, h.Reverse)
+				continue
+			}
+			index[id] = len(entry.Lines)
+			entry.Lines = append(entry.Lines, newLine(key, acc, dept, amt, h.Reverse))
+		}
+	}
+
+	if len(missing) > 0 {
+		keys := make([]string, 0, len(missing))
+		for _, k := range Keys {
+			if missing[k] {
+				keys = append(keys, k)
+			}
+		}
+		return Entry{}, &MissingAccountsError{Keys: keys}
+	}
+	if debit, credit := entry.Totals(); debit != credit {
+		return Entry{}, fmt.Errorf("journal does not balance: debit %s, credit %s", Baht(debit), Baht(credit))
+	}
+	return entry, nil
+}
+
+func newLine(key string, acc Account, d Department, amt int64, reverse bool) Line {
+	l := Line{
+		AccountKey:     key,
+		AccountCode:    acc.Code,
+		AccountName:    acc.Name,
+		DepartmentCode: d.Code,
+		DepartmentName: d.Name,
+	}
+	addAmount(&l, amt, reverse)
+	return l
+}
+
+func addAmount(l *Line, amt int64, reverse bool) {
+	if IsDebit(l.AccountKey) != reverse {
+		l.DebitSatang += amt
+	} else {
+		l.CreditSatang += amt
+	}
+}
+
+func Satang(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// Baht formats satang as a plain decimal amount (no thousands separator).
+func Baht(satang int64) string {
+	sign := ""
+	if satang < 0 {
+		sign = "-"
+		satang = -satang
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, satang/100, satang%100)
+}
@@ -0,0 +1,157 @@
+package journal
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// line is the part of a Line the tests compare.
+type line struct {
+	key, code, dept string
+	debit, credit   int64
+}
+
+func lines(e Entry) []line {
+	out := make([]line, 0, len(e.Lines))
+	for _, l := range e.Lines {
+		out = append(out, line{l.AccountKey, l.AccountCode, l.DepartmentCode, l.DebitSatang, l.CreditSatang})
+	}
+	return out
+}
+
+func TestBuild(t *testing.T) {
+	acc, ops := uuid.New(), uuid.New()
+	// each department nets out: NetPay = Salary + OT - employee SSO/PF - tax - loan - advance - other
+	departments := []Department{
+		{ID: &ops, Code: "OPS", Name: "Operations", Amounts: Amounts{
+			Salary: 20000, SSOEmployee: 750, SSOEmployer: 750, Advance: 2000, NetPay: 17250,
+		}},
+		{ID: &acc, Code: "ACC", Name: "Accounting", Amounts: Amounts{
+			Salary: 30000, OT: 1500.50, SSOEmployee: 750, SSOEmployer: 750, PFEmployee: 900, PFEmployer: 900,
+			Tax: 1200, Loan: 1000, Other: 100, NetPay: 27550.50,
+		}},
+		{Amounts: Amounts{Salary: 10000, SSOEmployee: 500, SSOEmployer: 500, NetPay: 9500}}, // no department
+	}
+	chart := func(skip ...string) Chart {
+		c := NewChart()
+		codes := map[string]string{
+			SalaryExpense: "5100", OTExpense: "5200", SSOEmployerExpense: "5300", PFEmployerExpense: "5400",
+			TaxPayable: "2100", SSOPayable: "2200", PFPayable: "2250", LoanReceivable: "1300",
+			AdvanceReceivable: "1400", OtherDeduction: "4900", NetPayPayable: "2300",
+		}
+		for _, k := range Keys {
+			if !slices.Contains(skip, k) {
+				c.Set(k, nil, Account{Code: codes[k], Name: k})
+			}
+		}
+		c.Set(SalaryExpense, &ops, Account{Code: "5110", Name: "Salary - operations"})
+		return c
+	}
+	credits := []line{
+		{TaxPayable, "2100", "", 0, 120000},
+		{SSOPayable, "2200", "", 0, 400000},
+		{PFPayable, "2250", "", 0, 180000},
+		{LoanReceivable, "1300", "", 0, 100000},
+		{AdvanceReceivable, "1400", "", 0, 200000},
+		{OtherDeduction, "4900", "", 0, 10000},
+		{NetPayPayable, "2300", "", 0, 5430050},
+	}
+
+	tests := []struct {
+		name    string
+		split   bool
+		reverse bool
+		chart   Chart
+		want    []line
+		missing []string
+	}{
+		{
+			name:  "merged departments",
+			chart: chart(),
+			want: append([]line{
+				{SalaryExpense, "5100", "", 4000000, 0}, // ACC and no department share the default account
+				{SalaryExpense, "5110", "", 2000000, 0},
+				{OTExpense, "5200", "", 150050, 0},
+				{SSOEmployerExpense, "5300", "", 200000, 0},
+				{PFEmployerExpense, "5400", "", 90000, 0},
+			}, credits...),
+		},
+		{
+			name:  "split by department",
+			split: true,
+			chart: chart(),
+			want: append([]line{
+				{SalaryExpense, "5100", "", 1000000, 0},
+				{SalaryExpense, "5100", "ACC", 3000000, 0},
+				{SalaryExpense, "5110", "OPS", 2000000, 0},
+				{OTExpense, "5200", "ACC", 150050, 0},
+				{SSOEmployerExpense, "5300", "", 50000, 0},
+				{SSOEmployerExpense, "5300", "ACC", 75000, 0},
+				{SSOEmployerExpense, "5300", "OPS", 75000, 0},
+				{PFEmployerExpense, "5400", "ACC", 90000, 0},
+			}, credits...),
+		},
+		{
+			name:    "reverse swaps sides",
+			reverse: true,
+			chart:   chart(),
+			want: []line{
+				{SalaryExpense, "5100", "", 0, 4000000},
+				{SalaryExpense, "5110", "", 0, 2000000},
+				{OTExpense, "5200", "", 0, 150050},
+				{SSOEmployerExpense, "5300", "", 0, 200000},
+				{PFEmployerExpense, "5400", "", 0, 90000},
+				{TaxPayable, "2100", "", 120000, 0},
+				{SSOPayable, "2200", "", 400000, 0},
+				{PFPayable, "2250", "", 180000, 0},
+				{LoanReceivable, "1300", "", 100000, 0},
+				{AdvanceReceivable, "1400", "", 200000, 0},
+				{OtherDeduction, "4900", "", 10000, 0},
+				{NetPayPayable, "2300", "", 5430050, 0},
+			},
+		},
+		{
+			name:    "missing accounts",
+			chart:   chart(TaxPayable, OTExpense),
+			missing: []string{OTExpense, TaxPayable},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := Header{Reference: "PR-2026-03", Date: time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC), Reverse: tt.reverse}
+			e, err := Build(h, departments, tt.chart, tt.split)
+			if tt.missing != nil {
+				var me *MissingAccountsError
+				if !errors.As(err, &me) || !slices.Equal(me.Keys, tt.missing) {
+					t.Fatalf("Build() error = %v, want missing %v", err, tt.missing)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Build() error = %v", err)
+			}
+			if got := lines(e); !slices.Equal(got, tt.want) {
+				t.Errorf("lines =\n%v\nwant\n%v", got, tt.want)
+			}
+			if debit, credit := e.Totals(); debit != credit || debit != 6440050 {
+				t.Errorf("totals = %s / %s, want 64400.50 on both sides", Baht(debit), Baht(credit))
+			}
+		})
+	}
+}
+
+func TestBuildRejectsUnbalancedAmounts(t *testing.T) {
+	c := NewChart()
+	for _, k := range Keys {
+		c.Set(k, nil, Account{Code: k})
+	}
+	// net pay a satang short of salary less tax
+	dept := Department{Amounts: Amounts{Salary: 1000, Tax: 50, NetPay: 949.99}}
+	if _, err := Build(Header{}, []Department{dept}, c, false); err == nil {
+		t.Fatal("Build() accepted an entry that does not balance")
+	}
+}
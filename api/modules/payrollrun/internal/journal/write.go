@@ -0,0 +1,78 @@
+package journal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+)
+
+// WriteCSV writes one row per line with the entry's date and reference repeated, the layout
+// most accounting imports accept.
+func WriteCSV(w io.Writer, e Entry) error {
+	cw := csv.NewWriter(w)
+	rows := [][]string{{
+		"journal_date", "reference", "line_no", "account_code", "account_name",
+		"department_code", "department_name", "description", "debit", "credit",
+	}}
+	for i, l := range e.Lines {
+		rows = append(rows, []string{
+			e.Date.Format("2006-01-02"), e.Reference, strconv.Itoa(i + 1), l.AccountCode, l.AccountName,
+			l.DepartmentCode, l.DepartmentName, e.Description, Baht(l.DebitSatang), Baht(l.CreditSatang),
+		})
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+type jsonLine struct {
+	LineNo         int     `json:"lineNo"`
+	AccountKey     string  `json:"accountKey"`
+	AccountCode    string  `json:"accountCode"`
+	AccountName    string  `json:"accountName"`
+	DepartmentCode string  `json:"departmentCode,omitempty"`
+	DepartmentName string  `json:"departmentName,omitempty"`
+	Debit          float64 `json:"debit"`
+	Credit         float64 `json:"credit"`
+}
+
+type jsonEntry struct {
+	Reference   string     `json:"reference"`
+	Date        string     `json:"date"`
+	Description string     `json:"description"`
+	Currency    string     `json:"currency"`
+	TotalDebit  float64    `json:"totalDebit"`
+	TotalCredit float64    `json:"totalCredit"`
+	Lines       []jsonLine `json:"lines"`
+}
+
+// WriteJSON writes the entry as a generic journal document (amounts in baht, THB).
+func WriteJSON(w io.Writer, e Entry) error {
+	debit, credit := e.Totals()
+	doc := jsonEntry{
+		Reference:   e.Reference,
+		Date:        e.Date.Format("2006-01-02"),
+		Description: e.Description,
+		Currency:    "THB",
+		TotalDebit:  float64(debit) / 100,
+		TotalCredit: float64(credit) / 100,
+		Lines:       make([]jsonLine, 0, len(e.Lines)),
+	}
+	for i, l := range e.Lines {
+		doc.Lines = append(doc.Lines, jsonLine{
+			LineNo:         i + 1,
+			AccountKey:     l.AccountKey,
+			AccountCode:    l.AccountCode,
+			AccountName:    l.AccountName,
+			DepartmentCode: l.DepartmentCode,
+			DepartmentName: l.DepartmentName,
+			Debit:          float64(l.DebitSatang) / 100,
+			Credit:         float64(l.CreditSatang) / 100,
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
@@ -0,0 +1,440 @@
+// Package payslip renders payroll_run_item rows as A4 payslip pages.
+package payslip
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jung-kurt/gofpdf"
+	"go.uber.org/zap"
+
+	"hrms/modules/payrollrun/internal/pdfdoc"
+	"hrms/modules/payrollrun/internal/repository"
+	"hrms/shared/common/logger"
+)
+
+const (
+	marginX   = 12.0
+	pageWidth = 210.0
+	contentW  = pageWidth - 2*marginX
+	colGap    = 4.0
+	colW      = (contentW - colGap) / 2
+	rowH      = 5.5
+	logoName  = "org-logo"
+)
+
+// Document is the run-level context shared by every slip in a bundle.
+type Document struct {
+	Run  repository.Run
+	Org  repository.OrgProfile
+	Logo *repository.Logo
+}
+
+// NewDocument builds the run context, loading the snapshot logo when one is set.
+// A missing or unreadable logo is logged and the slip is rendered without it.
+func NewDocument(ctx context.Context, repo repository.Repository, companyID uuid.UUID, run repository.Run) Document {
+	doc := Document{Run: run, Org: run.OrgProfile()}
+	if doc.Org.LogoID != nil {
+		logo, err := repo.GetLogo(ctx, companyID, *doc.Org.LogoID)
+		if err != nil {
+			logger.FromContext(ctx).Warn("failed to load payslip logo", zap.Error(err), zap.String("logoId", doc.Org.LogoID.String()))
+		} else {
+			doc.Logo = logo
+		}
+	}
+	return doc
+}
+
+type line struct {
+	label  string
+	qty    string
+	amount float64
+}
+
+// Render adds one page per item to pdf.
+func Render(pdf *gofpdf.Fpdf, doc Document, items []repository.ItemDetail) error {
+	hasLogo := doc.Logo != nil && pdfdoc.RegisterLogo(pdf, logoName, doc.Logo.Data)
+	for i := range items {
+		renderPage(pdf, doc, hasLogo, items[i])
+		if err := pdf.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FileName is the download name for a single slip, e.g. payslip-2026-01-EMP001.pdf.
+func FileName(run repository.Run, item repository.ItemDetail) string {
+	return fmt.Sprintf("payslip-%s-%s.pdf", run.PayrollMonth.Format("2006-01"), sanitize(item.EmployeeNumber))
+}
+
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+func renderPage(pdf *gofpdf.Fpdf, doc Document, hasLogo bool, it repository.ItemDetail) {
+	pdf.AddPage()
+	if doc.Run.Status != "approved" {
+		watermark(pdf, "รออนุมัติ / PENDING")
+	}
+
+	y := header(pdf, doc, hasLogo)
+	y = employeeInfo(pdf, doc, it, y+3)
+	y = breakdown(pdf, it, y+4)
+	y = summary(pdf, it, y+3)
+	footer(pdf, doc, y+6)
+}
+
+func header(pdf *gofpdf.Fpdf, doc Document, hasLogo bool) float64 {
+	top := 12.0
+	textX := marginX
+	if hasLogo {
+		pdf.ImageOptions(logoName, marginX, top, 0, 20, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+		textX = marginX + 24
+	}
+	infoW := 140 - textX
+
+	pdf.SetXY(textX, top)
+	pdf.SetFont(pdfdoc.Family, "B", 14)
+	name := doc.Org.CompanyName
+	if name == "" {
+		name = "-"
+	}
+	pdf.CellFormat(infoW, 7, name, "", 2, "L", false, 0, "")
+	pdf.SetFont(pdfdoc.Family, "", 9)
+	if addr := doc.Org.Address(); addr != "" {
+		pdf.SetX(textX)
+		pdf.MultiCell(infoW, 4.5, addr, "", "L", false)
+	}
+	if taxID := repository.Deref(doc.Org.TaxID); taxID != "" {
+		pdf.SetX(textX)
+		pdf.CellFormat(infoW, 4.5, "เลขประจำตัวผู้เสียภาษี "+taxID, "", 2, "L", false, 0, "")
+	}
+	var contacts []string
+	for _, s := range []*string{doc.Org.PhoneMain, doc.Org.PhoneAlt} {
+		if v := repository.Deref(s); v != "" {
+			contacts = append(contacts, v)
+		}
+	}
+	if len(contacts) > 0 {
+		pdf.SetX(textX)
+		pdf.CellFormat(infoW, 4.5, "โทร. "+strings.Join(contacts, ", "), "", 2, "L", false, 0, "")
+	}
+	bottom := pdf.GetY()
+
+	boxX := pageWidth - marginX - 50
+	pdf.SetDrawColor(220, 38, 38)
+	pdf.SetTextColor(220, 38, 38)
+	pdf.SetLineWidth(0.5)
+	pdf.SetFont(pdfdoc.Family, "B", 14)
+	pdf.SetXY(boxX, top)
+	pdf.CellFormat(50, 10, "สลิปเงินเดือน / PAYSLIP", "1", 2, "C", false, 0, "")
+	pdf.SetLineWidth(0.2)
+	pdf.SetDrawColor(156, 163, 175)
+	pdf.SetTextColor(37, 99, 235)
+	pdf.SetFont(pdfdoc.Family, "", 10)
+	pdf.SetX(boxX)
+	pdf.CellFormat(50, 6, "ประจำเดือน "+pdfdoc.ThaiMonthYear(doc.Run.PayrollMonth), "", 2, "C", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+
+	if hasLogo && bottom < top+20 {
+		bottom = top + 20
+	}
+	if y := pdf.GetY(); y > bottom {
+		bottom = y
+	}
+	bottom += 2
+	pdf.Line(marginX, bottom, pageWidth-marginX, bottom)
+	return bottom
+}
+
+func employeeInfo(pdf *gofpdf.Fpdf, doc Document, it repository.ItemDetail, y float64) float64 {
+	periodEnd := pdfdoc.MonthEnd(doc.Run.PayrollMonth)
+	typeName := repository.Deref(it.EmployeeTypeName)
+	if typeName == "" {
+		typeName = it.EmployeeTypeCode
+	}
+	rows := [][][2]string{
+		{
+			{"รหัสพนักงาน", it.EmployeeNumber},
+			{"ชื่อ-นามสกุล", it.EmployeeName},
+			{"แผนก", orDash(repository.Deref(it.DepartmentName))},
+			{"ตำแหน่ง", orDash(repository.Deref(it.PositionName))},
+		},
+		{
+			{"ประเภทพนักงาน", orDash(typeName)},
+			{"โอนเข้าบัญชีธนาคาร", orDash(repository.Deref(it.BankName))},
+			{"เลขที่บัญชี", orDash(repository.Deref(it.BankAccount))},
+			{"งวดวันที่", pdfdoc.ThaiDate(doc.Run.PeriodStart) + " - " + pdfdoc.ThaiDate(periodEnd)},
+		},
+	}
+	widths := []float64{32, 70, 42, 42}
+	for _, row := range rows {
+		x := marginX
+		for i, cell := range row {
+			pdf.SetXY(x, y)
+			pdf.SetFont(pdfdoc.Family, "", 8)
+			pdf.SetTextColor(107, 114, 128)
+			pdf.CellFormat(widths[i], 4, cell[0], "", 2, "L", false, 0, "")
+			pdf.SetX(x)
+			pdf.SetFont(pdfdoc.Family, "", 10)
+			pdf.SetTextColor(0, 0, 0)
+			pdf.CellFormat(widths[i], 5, fit(pdf, cell[1], widths[i]-1), "", 0, "L", false, 0, "")
+			x += widths[i]
+		}
+		y += 10
+	}
+	pdf.SetXY(marginX, y)
+	pdf.SetFont(pdfdoc.Family, "", 9)
+	pdf.CellFormat(contentW, 5, "วันที่จ่าย "+pdfdoc.ThaiDate(doc.Run.PayDate), "", 0, "L", false, 0, "")
+	y += 6
+	pdf.Line(marginX, y, pageWidth-marginX, y)
+	return y
+}
+
+func incomeLines(it repository.ItemDetail) []line {
+	salary := line{label: "เงินเดือน", amount: it.SalaryAmount}
+	if it.EmployeeTypeCode == "part_time" {
+		salary = line{
+			label:  "ค่าจ้างรายชั่วโมง (" + pdfdoc.Money(it.PTHourlyRate) + "/ชม.)",
+			qty:    qtyUnit(it.PTHoursWorked, "ชม."),
+			amount: it.SalaryAmount,
+		}
+	}
+	lines := append([]line{salary}, otLines(it)...)
+	lines = append(lines, []line{
+		{label: "ค่าห้องพัก", amount: it.HousingAllowance},
+		{label: "เบี้ยขยัน (ไม่สาย)", amount: it.AttendanceBonusNoLate},
+		{label: "เบี้ยขยัน (ไม่ลา)", amount: it.AttendanceBonusNoLeave},
+		{label: "ชดเชยวันลา", amount: it.LeaveCompensationAmount},
+		{label: "โบนัส", amount: it.BonusAmount},
+		{label: "ค่าธรรมเนียมแพทย์", amount: it.DoctorFee},
+	}...)
+	return append(lines, namedLines(it.OthersIncome)...)
+}
+
+// otLines keeps the single OT line unless holiday work or holiday OT was paid.
+func otLines(it repository.ItemDetail) []line {
+	if it.HolidayWorkAmount == 0 && it.HolidayOtAmount == 0 {
+		return []line{{label: "ค่าล่วงเวลา", qty: qtyUnit(it.OtHours, "ชม."), amount: it.OtAmount}}
+	}
+	return []line{
+		{label: "ค่าล่วงเวลา (วันทำงาน)", qty: qtyUnit(it.OtWeekdayHours, "ชม."), amount: it.OtWeekdayAmount},
+		{label: "ค่าทำงานในวันหยุด", qty: qtyUnit(it.HolidayWorkHours, "ชม."), amount: it.HolidayWorkAmount},
+		{label: "ค่าล่วงเวลาในวันหยุด", qty: qtyUnit(it.HolidayOtHours, "ชม."), amount: it.HolidayOtAmount},
+	}
+}
+
+func deductionLines(it repository.ItemDetail) []line {
+	lines := []line{
+		{label: "มาสาย", qty: qtyUnit(float64(it.LateMinutesQty), "นาที"), amount: it.LateMinutesDeduction},
+		{label: "หักลาหยุด", qty: qtyUnit(it.LeaveDaysQty, "วัน"), amount: it.LeaveDaysDeduction},
+		{label: "หักลาหยุด (2 เท่า)", qty: qtyUnit(it.LeaveDoubleQty, "วัน"), amount: it.LeaveDoubleDeduction},
+		{label: "ลาชั่วโมง", qty: qtyUnit(it.LeaveHoursQty, "ชม."), amount: it.LeaveHoursDeduction},
+		{label: "ภาษีหัก ณ ที่จ่าย", amount: it.TaxMonthAmount},
+		{label: "ประกันสังคม", amount: it.SsoMonthAmount},
+		{label: "กองทุนสำรองเลี้ยงชีพ", amount: it.PFMonthAmount},
+		{label: "ค่าน้ำ", amount: it.WaterAmount},
+		{label: "ค่าไฟ", amount: it.ElectricAmount},
+		{label: "ค่าอินเทอร์เน็ต", amount: it.InternetAmount},
+		{label: "เบิกล่วงหน้า", amount: it.AdvanceRepayAmount},
+	}
+	lines = append(lines, namedLines(it.LoanRepayments)...)
+	return append(lines, namedLines(it.OthersDeduction)...)
+}
+
+// namedLines expands a {name, value} JSONB array, skipping zero entries.
+func namedLines(raw []byte) []line {
+	if len(raw) == 0 {
+		return nil
+	}
+	var entries []map[string]interface{}
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil
+	}
+	var out []line
+	for _, e := range entries {
+		v := numberValue(e["value"])
+		if v == 0 {
+			continue
+		}
+		name, _ := e["name"].(string)
+		if strings.TrimSpace(name) == "" {
+			name = "อื่นๆ"
+		}
+		out = append(out, line{label: name, amount: v})
+	}
+	return out
+}
+
+func numberValue(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case string:
+		f, _ := strconv.ParseFloat(n, 64)
+		return f
+	}
+	return 0
+}
+
+func breakdown(pdf *gofpdf.Fpdf, it repository.ItemDetail, y float64) float64 {
+	income := incomeLines(it)
+	deduction := deductionLines(it)
+	n := len(income)
+	if len(deduction) > n {
+		n = len(deduction)
+	}
+	left := marginX
+	right := marginX + colW + colGap
+	table(pdf, left, y, "รายได้", income, n)
+	table(pdf, right, y, "รายการหัก", deduction, n)
+	y += 7 + 5 + float64(n)*rowH
+
+	pdf.SetFont(pdfdoc.Family, "B", 10)
+	pdf.SetXY(left, y)
+	pdf.CellFormat(colW-28, 7, "รวมรายได้", "T", 0, "L", false, 0, "")
+	pdf.SetTextColor(22, 163, 74)
+	pdf.CellFormat(28, 7, pdfdoc.Money(it.IncomeTotal), "T", 0, "R", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetXY(right, y)
+	pdf.CellFormat(colW-28, 7, "รวมรายการหัก", "T", 0, "L", false, 0, "")
+	pdf.SetTextColor(220, 38, 38)
+	pdf.CellFormat(28, 7, pdfdoc.Money(it.DeductionTotal), "T", 0, "R", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+	return y + 7
+}
+
+func table(pdf *gofpdf.Fpdf, x, y float64, title string, lines []line, rows int) {
+	labelW, qtyW, amtW := colW-48, 20.0, 28.0
+	pdf.SetFillColor(243, 244, 246)
+	pdf.SetFont(pdfdoc.Family, "B", 11)
+	pdf.SetXY(x, y)
+	pdf.CellFormat(colW, 7, title, "1", 2, "C", true, 0, "")
+	pdf.SetFont(pdfdoc.Family, "", 8)
+	pdf.SetTextColor(107, 114, 128)
+	pdf.CellFormat(labelW, 5, "รายการ", "B", 0, "L", false, 0, "")
+	pdf.CellFormat(qtyW, 5, "จำนวน", "B", 0, "C", false, 0, "")
+	pdf.CellFormat(amtW, 5, "จำนวนเงิน", "B", 2, "R", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont(pdfdoc.Family, "", 9.5)
+	for i := 0; i < rows; i++ {
+		pdf.SetX(x)
+		if i >= len(lines) {
+			pdf.CellFormat(colW, rowH, "", "", 2, "L", false, 0, "")
+			continue
+		}
+		l := lines[i]
+		qty := l.qty
+		if qty == "" {
+			qty = "-"
+		}
+		pdf.CellFormat(labelW, rowH, fit(pdf, l.label, labelW-1), "", 0, "L", false, 0, "")
+		pdf.CellFormat(qtyW, rowH, qty, "", 0, "C", false, 0, "")
+		pdf.CellFormat(amtW, rowH, pdfdoc.Money(l.amount), "", 2, "R", false, 0, "")
+	}
+}
+
+func summary(pdf *gofpdf.Fpdf, it repository.ItemDetail, y float64) float64 {
+	accum := [][2]string{
+		{"เงินได้สะสม", pdfdoc.Money(it.IncomeAccumTotal)},
+		{"ภาษีสะสม", pdfdoc.Money(it.TaxAccumTotal)},
+		{"ประกันสังคมสะสม", pdfdoc.Money(it.SsoAccumTotal)},
+		{"กองทุนสำรองสะสม", pdfdoc.Money(it.PFAccumTotal)},
+		{"เงินกู้คงเหลือ", pdfdoc.Money(it.LoanOutstandingTotal)},
+	}
+	cellW := contentW / float64(len(accum))
+	pdf.SetFillColor(249, 250, 251)
+	pdf.Rect(marginX, y, contentW, 12, "FD")
+	for i, a := range accum {
+		x := marginX + float64(i)*cellW
+		pdf.SetXY(x, y+1)
+		pdf.SetFont(pdfdoc.Family, "", 8)
+		pdf.SetTextColor(107, 114, 128)
+		pdf.CellFormat(cellW, 4.5, a[0], "", 2, "C", false, 0, "")
+		pdf.SetX(x)
+		pdf.SetFont(pdfdoc.Family, "B", 9.5)
+		pdf.SetTextColor(0, 0, 0)
+		pdf.CellFormat(cellW, 5.5, a[1], "", 0, "C", false, 0, "")
+	}
+	y += 15
+
+	pdf.SetLineWidth(0.5)
+	pdf.Line(marginX, y, pageWidth-marginX, y)
+	pdf.SetLineWidth(0.2)
+	pdf.SetXY(marginX, y+2)
+	pdf.SetFont(pdfdoc.Family, "B", 13)
+	pdf.CellFormat(contentW/2, 9, "เงินได้สุทธิ (Net Pay)", "", 0, "L", false, 0, "")
+	pdf.SetTextColor(22, 163, 74)
+	pdf.SetFont(pdfdoc.Family, "B", 16)
+	pdf.CellFormat(contentW/2, 9, pdfdoc.Money(it.NetPay)+" บาท", "", 0, "R", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+	return y + 11
+}
+
+func footer(pdf *gofpdf.Fpdf, doc Document, y float64) {
+	if note := strings.TrimSpace(repository.Deref(doc.Org.SlipFooterNote)); note != "" {
+		pdf.SetXY(marginX, y)
+		pdf.SetFont(pdfdoc.Family, "", 8.5)
+		pdf.SetTextColor(107, 114, 128)
+		pdf.MultiCell(contentW, 4.5, note, "", "L", false)
+		pdf.SetTextColor(0, 0, 0)
+		y = pdf.GetY() + 4
+	}
+	sigY := y + 14
+	for i, label := range []string{"ผู้จ่ายเงิน", "ผู้รับเงิน"} {
+		x := marginX + 20 + float64(i)*(contentW/2)
+		pdf.Line(x, sigY, x+50, sigY)
+		pdf.SetXY(x, sigY+1)
+		pdf.SetFont(pdfdoc.Family, "", 9)
+		pdf.CellFormat(50, 5, label, "", 0, "C", false, 0, "")
+	}
+}
+
+func watermark(pdf *gofpdf.Fpdf, text string) {
+	pdf.SetFont(pdfdoc.Family, "B", 48)
+	pdf.SetTextColor(239, 68, 68)
+	pdf.SetAlpha(0.15, "Normal")
+	pdf.TransformBegin()
+	pdf.TransformRotate(45, pageWidth/2, 148)
+	w := pdf.GetStringWidth(text)
+	pdf.Text(pageWidth/2-w/2, 148, text)
+	pdf.TransformEnd()
+	pdf.SetAlpha(1, "Normal")
+	pdf.SetTextColor(0, 0, 0)
+}
+
+func qtyUnit(v float64, unit string) string {
+	if v == 0 {
+		return ""
+	}
+	return pdfdoc.Quantity(v) + " " + unit
+}
+
+func orDash(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "-"
+	}
+	return s
+}
+
+// fit truncates s with an ellipsis so it stays within w millimetres at the current font.
+func fit(pdf *gofpdf.Fpdf, s string, w float64) string {
+	if pdf.GetStringWidth(s) <= w {
+		return s
+	}
+	r := []rune(s)
+	for len(r) > 0 && pdf.GetStringWidth(string(r)+"…") > w {
+		r = r[:len(r)-1]
+	}
+	return string(r) + "…"
+}
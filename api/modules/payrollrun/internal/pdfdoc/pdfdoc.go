@@ -0,0 +1,201 @@
+// Package pdfdoc holds the shared plumbing for server-rendered payroll documents
+// (payslips, tax certificates): Thai fonts, number/date formatting and logo handling.
+package pdfdoc
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Family is the font family name registered on every document.
+const Family = "sarabun"
+
+const (
+	regularFontFile = "Sarabun-Regular.ttf"
+	boldFontFile    = "Sarabun-Bold.ttf"
+)
+
+// Fonts lazily loads the Thai TrueType fonts from a directory (PDF_FONT_DIR).
+type Fonts struct {
+	dir string
+
+	once    sync.Once
+	regular []byte
+	bold    []byte
+	err     error
+}
+
+func NewFonts(dir string) *Fonts {
+	return &Fonts{dir: dir}
+}
+
+func (f *Fonts) load() error {
+	f.once.Do(func() {
+		f.regular, f.err = os.ReadFile(filepath.Join(f.dir, regularFontFile))
+		if f.err != nil {
+			return
+		}
+		f.bold, f.err = os.ReadFile(filepath.Join(f.dir, boldFontFile))
+	})
+	return f.err
+}
+
+// New returns an empty A4 portrait document (millimetre units) with the Thai family registered.
+func (f *Fonts) New() (*gofpdf.Fpdf, error) {
+	if err := f.load(); err != nil {
+		return nil, fmt.Errorf("load pdf fonts from %q: %w", f.dir, err)
+	}
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes(Family, "", f.regular)
+	pdf.AddUTF8FontFromBytes(Family, "B", f.bold)
+	pdf.SetFont(Family, "", 10)
+	pdf.SetAutoPageBreak(false, 0)
+	return pdf, pdf.Error()
+}
+
+// Output renders the document into a byte slice.
+func Output(pdf *gofpdf.Fpdf) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// RegisterLogo normalises an uploaded logo to PNG and registers it under name.
+// Returns false when the image cannot be decoded so callers can skip drawing it.
+func RegisterLogo(pdf *gofpdf.Fpdf, name string, data []byte) bool {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return false
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return false
+	}
+	pdf.RegisterImageOptionsReader(name, gofpdf.ImageOptions{ImageType: "PNG"}, &buf)
+	return pdf.Ok()
+}
+
+// Money formats an amount as 1,234.56.
+func Money(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 2, 64)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	intPart, frac, _ := strings.Cut(s, ".")
+	var b strings.Builder
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	out := b.String() + "." + frac
+	if neg {
+		return "-" + out
+	}
+	return out
+}
+
+// Quantity formats a count without trailing zeros (e.g. 1.5, 2).
+func Quantity(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+var thaiMonths = [...]string{
+	"มกราคม", "กุมภาพันธ์", "มีนาคม", "เมษายน", "พฤษภาคม", "มิถุนายน",
+	"กรกฎาคม", "สิงหาคม", "กันยายน", "ตุลาคม", "พฤศจิกายน", "ธันวาคม",
+}
+
+// ThaiMonthYear formats a date as "มกราคม 2569" (Buddhist era).
+func ThaiMonthYear(t time.Time) string {
+	return fmt.Sprintf("%s %d", thaiMonths[t.Month()-1], t.Year()+543)
+}
+
+// ThaiDate formats a date as dd/mm/yyyy in the Buddhist era.
+func ThaiDate(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return fmt.Sprintf("%02d/%02d/%d", t.Day(), int(t.Month()), t.Year()+543)
+}
+
+// MonthEnd returns the last day of t's month.
+func MonthEnd(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location())
+}
+
+var (
+	thaiDigits    = [...]string{"ศูนย์", "หนึ่ง", "สอง", "สาม", "สี่", "ห้า", "หก", "เจ็ด", "แปด", "เก้า"}
+	thaiPositions = [...]string{"", "สิบ", "ร้อย", "พัน", "หมื่น", "แสน"}
+)
+
+// BahtText spells an amount in Thai words as written on tax documents,
+// e.g. 1,521.25 → "หนึ่งพันห้าร้อยยี่สิบเอ็ดบาทยี่สิบห้าสตางค์".
+func BahtText(v float64) string {
+	satang := int64(math.Round(math.Abs(v) * 100))
+	baht, frac := satang/100, satang%100
+	var b strings.Builder
+	if v < 0 && satang > 0 {
+		b.WriteString("ลบ")
+	}
+	switch {
+	case baht == 0 && frac == 0:
+		return "ศูนย์บาทถ้วน"
+	case baht > 0:
+		b.WriteString(thaiNumber(baht))
+		b.WriteString("บาท")
+	}
+	if frac == 0 {
+		b.WriteString("ถ้วน")
+	} else {
+		b.WriteString(thaiNumber(frac))
+		b.WriteString("สตางค์")
+	}
+	return b.String()
+}
+
+// thaiNumber reads a positive integer, grouping by ล้าน.
+func thaiNumber(n int64) string {
+	if n >= 1_000_000 {
+		return thaiNumber(n/1_000_000) + "ล้าน" + thaiGroup(n%1_000_000, true)
+	}
+	return thaiGroup(n, false)
+}
+
+// thaiGroup reads 0-999,999; hasHigher marks that a ล้าน part precedes it (for เอ็ด).
+func thaiGroup(n int64, hasHigher bool) string {
+	var b strings.Builder
+	s := strconv.FormatInt(n, 10)
+	for i, c := range s {
+		d := int(c - '0')
+		pos := len(s) - 1 - i
+		if d == 0 {
+			continue
+		}
+		switch {
+		case pos == 0 && d == 1 && (n > 9 || hasHigher):
+			b.WriteString("เอ็ด")
+		case pos == 1 && d == 1:
+			b.WriteString("สิบ")
+		case pos == 1 && d == 2:
+			b.WriteString("ยี่สิบ")
+		default:
+			b.WriteString(thaiDigits[d] + thaiPositions[pos])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,33 @@
+package pdfdoc
+
+import "testing"
+
+func TestBahtText(t *testing.T) {
+	tests := []struct {
+		v    float64
+		want string
+	}{
+		{0, "ศูนย์บาทถ้วน"},
+		{1, "หนึ่งบาทถ้วน"},
+		{10, "สิบบาทถ้วน"},
+		{11, "สิบเอ็ดบาทถ้วน"},
+		{21, "ยี่สิบเอ็ดบาทถ้วน"},
+		{101, "หนึ่งร้อยเอ็ดบาทถ้วน"},
+		{1521.25, "หนึ่งพันห้าร้อยยี่สิบเอ็ดบาทยี่สิบห้าสตางค์"},
+		{250000, "สองแสนห้าหมื่นบาทถ้วน"},
+		{1_000_000, "หนึ่งล้านบาทถ้วน"},
+		{1_000_001, "หนึ่งล้านเอ็ดบาทถ้วน"},
+		{11_000_000, "สิบเอ็ดล้านบาทถ้วน"},
+		{21_500_000.5, "ยี่สิบเอ็ดล้านห้าแสนบาทห้าสิบสตางค์"},
+		{0.01, "หนึ่งสตางค์"},
+		{0.21, "ยี่สิบเอ็ดสตางค์"},
+		{99.999, "หนึ่งร้อยบาทถ้วน"},
+		{-5, "ลบห้าบาทถ้วน"},
+		{-0.001, "ศูนย์บาทถ้วน"},
+	}
+	for _, tt := range tests {
+		if got := BahtText(tt.v); got != tt.want {
+			t.Errorf("BahtText(%v) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
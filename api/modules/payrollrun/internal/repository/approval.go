@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"hrms/shared/common/contextx"
+)
+
+// Run statuses around the approval workflow: a pending run is a draft, submitted is frozen
+// while its approval chain runs, and rejection or withdrawal puts it back to pending.
+const (
+	StatusPending   = "pending"
+	StatusSubmitted = "submitted"
+)
+
+// SetStatus moves a run between draft and submitted. It only applies when the run is still in
+// status from, so a concurrent change yields sql.ErrNoRows.
+func (r Repository) SetStatus(ctx context.Context, tenant contextx.TenantInfo, id uuid.UUID, from, to string, actor uuid.UUID) (*Run, error) {
+	db := r.dbCtx(ctx)
+	where := "id=$3 AND deleted_at IS NULL AND status=$4 AND company_id=$5"
+	args := []interface{}{to, actor, id, from, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where += fmt.Sprintf(" AND branch_id=$%d", len(args))
+	}
+	q := fmt.Sprintf(`
+UPDATE payroll_run
+SET status=$1, updated_by=$2
+WHERE %s
+RETURNING id, company_id, branch_id, payroll_month_date, period_start_date, pay_date, status, run_type, note,
+          created_at, updated_at, deleted_at, approved_at, approved_by, reversed_at, reversed_by, reversal_reason,
+          social_security_rate_employee, social_security_rate_employer,
+          COALESCE((SELECT COUNT(1) FROM payroll_run_item pri WHERE pri.run_id = payroll_run.id),0) AS total_employees,
+          COALESCE((SELECT SUM(%s) FROM payroll_run_item pri WHERE pri.run_id = payroll_run.id),0) AS total_net_pay,
+          COALESCE((SELECT SUM(income_total) FROM payroll_run_item pri WHERE pri.run_id = payroll_run.id),0) AS total_income,
+          COALESCE((SELECT SUM(%s) FROM payroll_run_item pri WHERE pri.run_id = payroll_run.id),0) AS total_deduction,
+          COALESCE((SELECT SUM(tax_month_amount) FROM payroll_run_item pri WHERE pri.run_id = payroll_run.id),0) AS total_tax,
+          COALESCE((SELECT SUM(sso_month_amount) FROM payroll_run_item pri WHERE pri.run_id = payroll_run.id),0) AS total_sso,
+          COALESCE((SELECT SUM(pf_month_amount) FROM payroll_run_item pri WHERE pri.run_id = payroll_run.id),0) AS total_provident_fund`, where, netPayExpr, deductionExpr)
+	var run Run
+	if err := db.GetContext(ctx, &run, q, args...); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+// ListPreparers returns the users who prepared a run, its creator first: whoever created the
+// run and whoever added or edited its items. Status changes (submit, reject, withdraw) are not
+// edits and do not make a user a preparer.
+func (r Repository) ListPreparers(ctx context.Context, runID uuid.UUID) ([]uuid.UUID, error) {
+	db := r.dbCtx(ctx)
+	const q = `
+SELECT user_id
+FROM (
+  SELECT created_by AS user_id, 0 AS ord FROM payroll_run WHERE id = $1
+  UNION ALL
+  SELECT created_by, 1 FROM payroll_run_item WHERE run_id = $1
+  UNION ALL
+  SELECT updated_by, 1 FROM payroll_run_item WHERE run_id = $1
+) p
+GROUP BY user_id
+ORDER BY MIN(ord), user_id`
+	var ids []uuid.UUID
+	if err := db.SelectContext(ctx, &ids, q, runID); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
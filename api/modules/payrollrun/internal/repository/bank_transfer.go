@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"hrms/shared/common/contextx"
+)
+
+type TransferRow struct {
+	ItemID         uuid.UUID `db:"item_id"`
+	EmployeeNumber string    `db:"employee_number"`
+	EmployeeName   string    `db:"employee_name"`
+	BankCode       *string   `db:"bank_code"`
+	BankName       *string   `db:"bank_name"`
+	BankAccountNo  *string   `db:"bank_account_no"`
+	NetPay         float64   `db:"net_pay"`
+}
+
+// ListTransfers returns net pay per item with the payee bank details captured on the item.
+// The bank code is resolved from the item's bank_name, falling back to the employee's bank.
+func (r Repository) ListTransfers(ctx context.Context, tenant contextx.TenantInfo, runID uuid.UUID) ([]TransferRow, error) {
+	db := r.dbCtx(ctx)
+	where := "pri.run_id = $1 AND pri.company_id = $2"
+	args := []interface{}{runID, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where += " AND pri.branch_id = $3"
+	}
+	q := fmt.Sprintf(`
+SELECT pri.id AS item_id, e.employee_number,
+       (e.first_name || ' ' || e.last_name) AS employee_name,
+       COALESCE(
+         (SELECT b.code FROM banks b
+           WHERE b.name_th = pri.bank_name AND b.deleted_at IS NULL
+             AND (b.company_id IS NULL OR b.company_id = pri.company_id)
+           ORDER BY b.company_id NULLS LAST LIMIT 1),
+         eb.code
+       ) AS bank_code,
+       pri.bank_name, pri.bank_account_no,
+       (%s) AS net_pay
+FROM payroll_run_item pri
+JOIN employees e ON e.id = pri.employee_id
+LEFT JOIN banks eb ON eb.id = e.bank_id
+WHERE %s
+ORDER BY e.employee_number ASC`, netPayExpr, where)
+	var rows []TransferRow
+	if err := db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+type DebitAccount struct {
+	ID            uuid.UUID  `db:"id"`
+	BranchID      *uuid.UUID `db:"branch_id"`
+	BankCode      string     `db:"bank_code"`
+	BankName      string     `db:"bank_name"`
+	AccountNumber string     `db:"account_number"`
+	AccountName   string     `db:"account_name"`
+}
+
+// FindDebitAccount picks the company account at the given bank to pay from:
+// the run branch's own account first, then the central (branch_id IS NULL) one.
+// When accountID is set that exact account is used, provided it is visible to the branch.
+func (r Repository) FindDebitAccount(ctx context.Context, companyID, branchID uuid.UUID, bankCode string, accountID *uuid.UUID) (*DebitAccount, error) {
+	db := r.dbCtx(ctx)
+	where := "cba.company_id = $1 AND (cba.branch_id = $2 OR cba.branch_id IS NULL) AND cba.is_active AND cba.deleted_at IS NULL"
+	args := []interface{}{companyID, branchID}
+	if accountID != nil {
+		args = append(args, *accountID)
+		where += fmt.Sprintf(" AND cba.id = $%d", len(args))
+	} else {
+		args = append(args, bankCode)
+		where += fmt.Sprintf(" AND upper(b.code) = upper($%d)", len(args))
+	}
+	q := fmt.Sprintf(`
+SELECT cba.id, cba.branch_id, b.code AS bank_code, b.name_th AS bank_name,
+       cba.account_number, cba.account_name
+FROM company_bank_accounts cba
+JOIN banks b ON b.id = cba.bank_id
+WHERE %s
+ORDER BY cba.branch_id NULLS LAST, cba.created_at ASC
+LIMIT 1`, where)
+	var acc DebitAccount
+	if err := db.GetContext(ctx, &acc, q, args...); err != nil {
+		return nil, err
+	}
+	return &acc, nil
+}
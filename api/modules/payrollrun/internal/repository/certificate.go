@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"hrms/shared/common/contextx"
+)
+
+type CertificateRow struct {
+	EmployeeID     uuid.UUID `db:"employee_id"`
+	EmployeeNumber string    `db:"employee_number"`
+	CitizenID      string    `db:"citizen_id"`
+	TitleName      string    `db:"title_name"`
+	FirstName      string    `db:"first_name"`
+	LastName       string    `db:"last_name"`
+	Income         float64   `db:"income"`
+	Tax            float64   `db:"tax"`
+	SSO            float64   `db:"sso"`
+	PF             float64   `db:"pf"`
+}
+
+// ListCertificates returns the yearly totals printed on the 50 ทวิ certificate.
+// Income, tax and SSO come from the year's accumulations; the pf accumulation is lifetime,
+// so the year's provident fund is summed from approved run items instead.
+func (r Repository) ListCertificates(ctx context.Context, tenant contextx.TenantInfo, year int, employeeID *uuid.UUID) ([]CertificateRow, error) {
+	db := r.dbCtx(ctx)
+	where := "e.company_id = $2"
+	args := []interface{}{year, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where += fmt.Sprintf(" AND e.branch_id = $%d", len(args))
+	}
+	if employeeID != nil {
+		args = append(args, *employeeID)
+		where += fmt.Sprintf(" AND e.id = $%d", len(args))
+	}
+	q := fmt.Sprintf(`
+WITH accum AS (
+  SELECT pa.employee_id,
+         SUM(pa.amount) FILTER (WHERE pa.accum_type = 'income') AS income,
+         SUM(pa.amount) FILTER (WHERE pa.accum_type = 'tax') AS tax,
+         SUM(pa.amount) FILTER (WHERE pa.accum_type = 'sso') AS sso
+  FROM payroll_accumulation pa
+  WHERE pa.accum_year = $1 AND pa.company_id = $2
+    AND pa.accum_type IN ('income','tax','sso')
+  GROUP BY pa.employee_id
+), pf AS (
+  SELECT pri.employee_id, SUM(COALESCE(pri.pf_month_amount,0)) AS pf
+  FROM payroll_run pr
+  JOIN payroll_run_item pri ON pri.run_id = pr.id
+  WHERE pr.company_id = $2 AND pr.status = 'approved' AND pr.deleted_at IS NULL
+    AND EXTRACT(YEAR FROM pr.payroll_month_date) = $1
+  GROUP BY pri.employee_id
+)
+SELECT e.id AS employee_id, e.employee_number,
+       e.id_document_number AS citizen_id,
+       pt.name_th AS title_name, e.first_name, e.last_name,
+       COALESCE(a.income,0) AS income,
+       COALESCE(a.tax,0) AS tax,
+       COALESCE(a.sso,0) AS sso,
+       COALESCE(pf.pf,0) AS pf
+FROM accum a
+JOIN employees e ON e.id = a.employee_id
+JOIN person_title pt ON pt.id = e.title_id
+LEFT JOIN pf ON pf.employee_id = e.id
+WHERE %s AND COALESCE(a.income,0) > 0
+ORDER BY e.employee_number ASC`, where)
+	var rows []CertificateRow
+	if err := db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"hrms/shared/common/contextx"
+)
+
+// ExportItem is one full item row for the spreadsheet export. The JSON columns are raw
+// [{name,value}] arrays, expanded by the caller.
+type ExportItem struct {
+	BranchID                uuid.UUID `db:"branch_id"`
+	BranchName              string    `db:"branch_name"`
+	DepartmentName          string    `db:"department_name"`
+	EmployeeNumber          string    `db:"employee_number"`
+	EmployeeName            string    `db:"employee_name"`
+	EmployeeTypeName        string    `db:"employee_type_name"`
+	PositionName            string    `db:"position_name"`
+	BankName                string    `db:"bank_name"`
+	BankAccountNo           string    `db:"bank_account_no"`
+	SalaryAmount            float64   `db:"salary_amount"`
+	PTHoursWorked           float64   `db:"pt_hours_worked"`
+	PTHourlyRate            float64   `db:"pt_hourly_rate"`
+	OtHours                 float64   `db:"ot_hours"`
+	OtAmount                float64   `db:"ot_amount"`
+	OtWeekdayHours          float64   `db:"ot_weekday_hours"`
+	OtWeekdayAmount         float64   `db:"ot_weekday_amount"`
+	HolidayWorkHours        float64   `db:"holiday_work_hours"`
+	HolidayWorkAmount       float64   `db:"holiday_work_amount"`
+	HolidayOtHours          float64   `db:"holiday_ot_hours"`
+	HolidayOtAmount         float64   `db:"holiday_ot_amount"`
+	HousingAllowance        float64   `db:"housing_allowance"`
+	AttendanceBonusNoLate   float64   `db:"attendance_bonus_nolate"`
+	AttendanceBonusNoLeave  float64   `db:"attendance_bonus_noleave"`
+	BonusAmount             float64   `db:"bonus_amount"`
+	LeaveCompensationAmount float64   `db:"leave_compensation_amount"`
+	DoctorFee               float64   `db:"doctor_fee"`
+	OthersIncome            []byte    `db:"others_income"`
+	IncomeTotal             float64   `db:"income_total"`
+	LeaveDaysQty            float64   `db:"leave_days_qty"`
+	LeaveDaysDeduction      float64   `db:"leave_days_deduction"`
+	LeaveDoubleQty          float64   `db:"leave_double_qty"`
+	LeaveDoubleDeduction    float64   `db:"leave_double_deduction"`
+	LeaveHoursQty           float64   `db:"leave_hours_qty"`
+	LeaveHoursDeduction     float64   `db:"leave_hours_deduction"`
+	LateMinutesQty          int       `db:"late_minutes_qty"`
+	LateMinutesDeduction    float64   `db:"late_minutes_deduction"`
+	SsoMonthAmount          float64   `db:"sso_month_amount"`
+	TaxMonthAmount          float64   `db:"tax_month_amount"`
+	PfMonthAmount           float64   `db:"pf_month_amount"`
+	WaterAmount             float64   `db:"water_amount"`
+	ElectricAmount          float64   `db:"electric_amount"`
+	InternetAmount          float64   `db:"internet_amount"`
+	AdvanceRepayAmount      float64   `db:"advance_repay_amount"`
+	OthersDeduction         []byte    `db:"others_deduction"`
+	LoanRepayments          []byte    `db:"loan_repayments"`
+	DeductionTotal          float64   `db:"deduction_total"`
+	NetPay                  float64   `db:"net_pay"`
+}
+
+// WeekdayOt is the OT paid at the weekday rate. ot_hours/ot_amount also count holiday work
+// and holiday OT, which have their own columns; an item without holiday pay keeps ot_hours and
+// ot_amount as they are (like the payslip), so OT keyed in on an off-cycle item still shows.
+func (it ExportItem) WeekdayOt() (hours, amount float64) {
+	if it.HolidayWorkAmount == 0 && it.HolidayOtAmount == 0 {
+		return it.OtHours, it.OtAmount
+	}
+	return it.OtWeekdayHours, it.OtWeekdayAmount
+}
+
+// ExportColumns is the set of names used in the run's JSON income/deduction arrays, so every
+// exported row gets the same expanded columns.
+type ExportColumns struct {
+	OthersIncome    []string
+	OthersDeduction []string
+	LoanRepayments  []string
+}
+
+func exportWhere(tenant contextx.TenantInfo, runID uuid.UUID) (string, []interface{}) {
+	where := "pri.run_id = $1 AND pri.company_id = $2"
+	args := []interface{}{runID, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where += " AND pri.branch_id = $3"
+	}
+	return where, args
+}
+
+func (r Repository) ListExportColumns(ctx context.Context, tenant contextx.TenantInfo, runID uuid.UUID) (ExportColumns, error) {
+	db := r.dbCtx(ctx)
+	where, args := exportWhere(tenant, runID)
+	q := fmt.Sprintf(`
+SELECT DISTINCT x.src, btrim(x.elem->>'name') AS name
+FROM payroll_run_item pri
+CROSS JOIN LATERAL (
+  SELECT 'others_income' AS src, e AS elem FROM jsonb_array_elements(CASE WHEN jsonb_typeof(pri.others_income) = 'array' THEN pri.others_income ELSE '[]'::jsonb END) e
+  UNION ALL
+  SELECT 'others_deduction', e FROM jsonb_array_elements(CASE WHEN jsonb_typeof(pri.others_deduction) = 'array' THEN pri.others_deduction ELSE '[]'::jsonb END) e
+  UNION ALL
+  SELECT 'loan_repayments', e FROM jsonb_array_elements(CASE WHEN jsonb_typeof(pri.loan_repayments) = 'array' THEN pri.loan_repayments ELSE '[]'::jsonb END) e
+) x
+WHERE %s AND COALESCE(btrim(x.elem->>'name'), '') <> ''
+ORDER BY 1, 2`, where)
+	var rows []struct {
+		Src  string `db:"src"`
+		Name string `db:"name"`
+	}
+	if err := db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return ExportColumns{}, err
+	}
+	var cols ExportColumns
+	for _, row := range rows {
+		switch row.Src {
+		case "others_income":
+			cols.OthersIncome = append(cols.OthersIncome, row.Name)
+		case "others_deduction":
+			cols.OthersDeduction = append(cols.OthersDeduction, row.Name)
+		case "loan_repayments":
+			cols.LoanRepayments = append(cols.LoanRepayments, row.Name)
+		}
+	}
+	return cols, nil
+}
+
+// StreamExportItems calls fn for each item of the run, ordered by branch, department and
+// employee number, without loading the run into memory.
+func (r Repository) StreamExportItems(ctx context.Context, tenant contextx.TenantInfo, runID uuid.UUID, fn func(ExportItem) error) error {
+	db := r.dbCtx(ctx)
+	where, args := exportWhere(tenant, runID)
+	q := fmt.Sprintf(`
+SELECT pri.branch_id, COALESCE(b.name, '') AS branch_name,
+       COALESCE(pri.department_name, '') AS department_name,
+       e.employee_number,
+       (COALESCE(pt.name_th, '') || e.first_name || ' ' || e.last_name) AS employee_name,
+       COALESCE(pri.employee_type_name, '') AS employee_type_name,
+       COALESCE(pri.position_name, '') AS position_name,
+       COALESCE(pri.bank_name, '') AS bank_name,
+       COALESCE(pri.bank_account_no, '') AS bank_account_no,
+       pri.salary_amount, pri.pt_hours_worked, pri.pt_hourly_rate, pri.ot_hours, pri.ot_amount,
+       pri.ot_weekday_hours, pri.ot_weekday_amount,
+       pri.holiday_work_hours, pri.holiday_work_amount, pri.holiday_ot_hours, pri.holiday_ot_amount,
+       pri.housing_allowance, pri.attendance_bonus_nolate, pri.attendance_bonus_noleave,
+       pri.bonus_amount, pri.leave_compensation_amount, pri.doctor_fee,
+       pri.others_income, pri.income_total,
+       pri.leave_days_qty, pri.leave_days_deduction, pri.leave_double_qty, pri.leave_double_deduction,
+       pri.leave_hours_qty, pri.leave_hours_deduction, pri.late_minutes_qty, pri.late_minutes_deduction,
+       pri.sso_month_amount, pri.tax_month_amount, pri.pf_month_amount,
+       pri.water_amount, pri.electric_amount, pri.internet_amount, pri.advance_repay_amount,
+       pri.others_deduction, pri.loan_repayments,
+       (%s) AS deduction_total,
+       (%s) AS net_pay
+FROM payroll_run_item pri
+JOIN employees e ON e.id = pri.employee_id
+LEFT JOIN person_title pt ON pt.id = e.title_id
+LEFT JOIN branches b ON b.id = pri.branch_id
+WHERE %s
+ORDER BY branch_name, pri.branch_id, department_name, e.employee_number`, deductionExpr, netPayExpr, where)
+	rows, err := db.QueryxContext(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var it ExportItem
+		if err := rows.StructScan(&it); err != nil {
+			return err
+		}
+		if err := fn(it); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
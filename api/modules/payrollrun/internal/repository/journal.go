@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/shared/common/contextx"
+)
+
+// JournalItem is one item's postings for the GL journal. SSOWage is the capped wage of an
+// employee who contributes (0 otherwise) so the employer share can be worked out the same way
+// as the SSO filing.
+type JournalItem struct {
+	DepartmentID   *uuid.UUID `db:"department_id"`
+	DepartmentCode string     `db:"department_code"`
+	DepartmentName string     `db:"department_name"`
+	Salary         float64    `db:"salary"`
+	OT             float64    `db:"ot"`
+	SSOWage        float64    `db:"sso_wage"`
+	SSOEmployee    float64    `db:"sso_employee"`
+	PFEmployee     float64    `db:"pf_employee"`
+	PFEmployer     float64    `db:"pf_employer"`
+	Tax            float64    `db:"tax"`
+	Loan           float64    `db:"loan"`
+	Advance        float64    `db:"advance"`
+	Other          float64    `db:"other"`
+	NetPay         float64    `db:"net_pay"`
+}
+
+// ListJournalItems returns the run's items with their department. Salary is income other than
+// OT less leave and late deductions, so debits and credits balance against net pay. The
+// department and the employer's provident fund rate come from the item's settings snapshot,
+// taken when the item was calculated, so a later transfer or rate change does not move an
+// approved run; items calculated before the snapshot held them fall back to the employee's
+// current values. The employer share is on the same base as the employee share.
+func (r Repository) ListJournalItems(ctx context.Context, tenant contextx.TenantInfo, runID uuid.UUID, wageCap float64) ([]JournalItem, error) {
+	db := r.dbCtx(ctx)
+	where := "pri.run_id = $1 AND pri.company_id = $2"
+	args := []interface{}{runID, tenant.CompanyID, wageCap}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where += " AND pri.branch_id = $4"
+	}
+	q := fmt.Sprintf(`
+WITH items AS (
+  SELECT pri.*,
+         CASE WHEN pri.employee_settings_snapshot ? 'department_id'
+              THEN (pri.employee_settings_snapshot->>'department_id')::uuid
+              ELSE e.department_id END AS item_department_id,
+         CASE WHEN pri.employee_settings_snapshot ? 'provident_fund_rate_employer'
+              THEN (pri.employee_settings_snapshot->>'provident_fund_rate_employer')::numeric
+              ELSE e.provident_fund_rate_employer END AS pf_rate_employer,
+         e.employee_number
+  FROM payroll_run_item pri
+  JOIN employees e ON e.id = pri.employee_id
+  WHERE %s
+)
+SELECT d.id AS department_id,
+       COALESCE(d.code, '') AS department_code,
+       COALESCE(d.name_th, pri.department_name, '') AS department_name,
+       COALESCE(pri.income_total,0) - COALESCE(pri.ot_amount,0)
+         - COALESCE(pri.late_minutes_deduction,0) - COALESCE(pri.leave_days_deduction,0)
+         - COALESCE(pri.leave_double_deduction,0) - COALESCE(pri.leave_hours_deduction,0) AS salary,
+       COALESCE(pri.ot_amount,0) AS ot,
+       CASE WHEN COALESCE((pri.employee_settings_snapshot->>'sso_contribute')::boolean, pri.sso_month_amount > 0)
+            THEN LEAST(COALESCE(pri.sso_declared_wage,0), $3) ELSE 0 END AS sso_wage,
+       COALESCE(pri.sso_month_amount,0) AS sso_employee,
+       COALESCE(pri.pf_month_amount,0) AS pf_employee,
+       CASE WHEN COALESCE(pri.pf_month_amount,0) > 0
+            THEN ROUND(COALESCE(pri.salary_amount,0) * COALESCE(pri.pf_rate_employer,0), 2) ELSE 0 END AS pf_employer,
+       COALESCE(pri.tax_month_amount,0) AS tax,
+       COALESCE(jsonb_sum_value(pri.loan_repayments),0) AS loan,
+       COALESCE(pri.advance_repay_amount,0) AS advance,
+       COALESCE(pri.water_amount,0) + COALESCE(pri.electric_amount,0) + COALESCE(pri.internet_amount,0)
+         + COALESCE(jsonb_sum_value(pri.others_deduction),0) AS other,
+       (%s) AS net_pay
+FROM items pri
+LEFT JOIN department d ON d.id = pri.item_department_id
+ORDER BY department_code, pri.employee_number`, where, netPayExpr)
+	var rows []JournalItem
+	if err := db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+type GLAccount struct {
+	ID             uuid.UUID  `db:"id"`
+	AccountKey     string     `db:"account_key"`
+	DepartmentID   *uuid.UUID `db:"department_id"`
+	DepartmentCode *string    `db:"department_code"`
+	DepartmentName *string    `db:"department_name"`
+	AccountCode    string     `db:"account_code"`
+	AccountName    string     `db:"account_name"`
+	UpdatedAt      time.Time  `db:"updated_at"`
+}
+
+// ListGLAccounts returns the company's chart-of-accounts mapping, company defaults first.
+func (r Repository) ListGLAccounts(ctx context.Context, companyID uuid.UUID) ([]GLAccount, error) {
+	db := r.dbCtx(ctx)
+	const q = `
+SELECT m.id, m.account_key, m.department_id, d.code AS department_code, d.name_th AS department_name,
+       m.account_code, m.account_name, m.updated_at
+FROM gl_account_mapping m
+LEFT JOIN department d ON d.id = m.department_id
+WHERE m.company_id = $1
+ORDER BY m.department_id NULLS FIRST, d.code, m.account_key`
+	var rows []GLAccount
+	if err := db.SelectContext(ctx, &rows, q, companyID); err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		rows = []GLAccount{}
+	}
+	return rows, nil
+}
+
+// ErrUnknownDepartment is returned when a mapping names a department outside the company.
+var ErrUnknownDepartment = errors.New("department not found")
+
+// ReplaceGLAccounts swaps the company's whole mapping for accounts.
+func (r Repository) ReplaceGLAccounts(ctx context.Context, companyID uuid.UUID, accounts []GLAccount, actor uuid.UUID) error {
+	db := r.dbCtx(ctx)
+	if _, err := db.ExecContext(ctx, `DELETE FROM gl_account_mapping WHERE company_id = $1`, companyID); err != nil {
+		return err
+	}
+	for _, a := range accounts {
+		res, err := db.ExecContext(ctx, `
+INSERT INTO gl_account_mapping (company_id, account_key, department_id, account_code, account_name, created_by, updated_by)
+SELECT $1, $2, $3, $4, $5, $6, $6
+WHERE $3::uuid IS NULL
+   OR EXISTS (SELECT 1 FROM department d WHERE d.id = $3 AND d.company_id = $1 AND d.deleted_at IS NULL)`,
+			companyID, a.AccountKey, a.DepartmentID, a.AccountCode, a.AccountName, actor)
+		if err != nil {
+			return err
+		}
+		if rows, _ := res.RowsAffected(); rows == 0 {
+			return ErrUnknownDepartment
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrgProfile mirrors the keys written into payroll_run.org_profile_snapshot; the db tags
+// let the same shape be read straight from payroll_org_profile.
+type OrgProfile struct {
+	ProfileID      *uuid.UUID `json:"profile_id" db:"id"`
+	VersionNo      int        `json:"version_no" db:"version_no"`
+	CompanyName    string     `json:"company_name" db:"company_name"`
+	AddressLine1   string     `json:"address_line1" db:"address_line1"`
+	AddressLine2   *string    `json:"address_line2" db:"address_line2"`
+	Subdistrict    *string    `json:"subdistrict" db:"subdistrict"`
+	District       *string    `json:"district" db:"district"`
+	Province       *string    `json:"province" db:"province"`
+	PostalCode     *string    `json:"postal_code" db:"postal_code"`
+	PhoneMain      *string    `json:"phone_main" db:"phone_main"`
+	PhoneAlt       *string    `json:"phone_alt" db:"phone_alt"`
+	Email          *string    `json:"email" db:"email"`
+	TaxID          *string    `json:"tax_id" db:"tax_id"`
+	SSOAccountNo   *string    `json:"sso_employer_account_no" db:"sso_employer_account_no"`
+	SlipFooterNote *string    `json:"slip_footer_note" db:"slip_footer_note"`
+	LogoID         *uuid.UUID `json:"logo_id" db:"logo_id"`
+}
+
+// OrgProfile decodes the run's org profile snapshot; an empty snapshot yields a zero profile.
+func (r Run) OrgProfile() OrgProfile {
+	var p OrgProfile
+	if len(r.OrgProfileSnapshot) > 0 {
+		_ = json.Unmarshal(r.OrgProfileSnapshot, &p)
+	}
+	return p
+}
+
+// GetEffectiveOrgProfile reads the company's org profile effective on the given date,
+// for documents that are not tied to a single run.
+func (r Repository) GetEffectiveOrgProfile(ctx context.Context, companyID uuid.UUID, on time.Time) (*OrgProfile, error) {
+	db := r.dbCtx(ctx)
+	const q = `
+SELECT id, COALESCE(version_no, 0) AS version_no, company_name, address_line1, address_line2,
+       subdistrict, district, province, postal_code, phone_main, phone_alt, email,
+       tax_id, sso_employer_account_no, slip_footer_note, logo_id
+FROM get_effective_org_profile($1::date, $2::uuid)
+WHERE id IS NOT NULL`
+	var p OrgProfile
+	if err := db.GetContext(ctx, &p, q, on, companyID); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Address joins the non-empty address parts into a single line.
+func (p OrgProfile) Address() string {
+	parts := []string{p.AddressLine1}
+	for _, s := range []*string{p.AddressLine2, p.Subdistrict, p.District, p.Province, p.PostalCode} {
+		if s != nil {
+			parts = append(parts, *s)
+		}
+	}
+	out := make([]string, 0, len(parts))
+	for _, s := range parts {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+func Deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
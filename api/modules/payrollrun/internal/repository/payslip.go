@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"hrms/shared/common/contextx"
+)
+
+type Logo struct {
+	Data        []byte `db:"data"`
+	ContentType string `db:"content_type"`
+}
+
+// ListItemDetails returns every item of a run in payslip order (full-time first, then by employee number).
+func (r Repository) ListItemDetails(ctx context.Context, tenant contextx.TenantInfo, runID uuid.UUID) ([]ItemDetail, error) {
+	db := r.dbCtx(ctx)
+	where := "pri.run_id = $1 AND pri.company_id = $2"
+	args := []interface{}{runID, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where += " AND pri.branch_id = $3"
+	}
+
+	q := fmt.Sprintf(itemDetailSelect+`WHERE %s
+ORDER BY CASE et.code WHEN 'full_time' THEN 0 WHEN 'part_time' THEN 1 ELSE 2 END,
+         e.employee_number ASC`, netPayExpr, deductionExpr, where)
+	rows, err := db.QueryxContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var list []ItemDetail
+	for rows.Next() {
+		var it ItemDetail
+		if err := rows.StructScan(&it); err != nil {
+			return nil, err
+		}
+		list = append(list, it)
+	}
+	return list, rows.Err()
+}
+
+// GetLogo loads the org logo referenced by a run's org_profile_snapshot.
+func (r Repository) GetLogo(ctx context.Context, companyID, logoID uuid.UUID) (*Logo, error) {
+	db := r.dbCtx(ctx)
+	const q = `SELECT data, content_type FROM payroll_org_logo WHERE id = $1 AND company_id = $2`
+	var logo Logo
+	if err := db.GetContext(ctx, &logo, q, logoID, companyID); err != nil {
+		return nil, err
+	}
+	return &logo, nil
+}
@@ -0,0 +1,443 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/payrollrun/internal/engine"
+	"hrms/shared/common/contextx"
+)
+
+// FindRegularRun returns the live (not reversed) regular run of a branch month, or sql.ErrNoRows.
+func (r Repository) FindRegularRun(ctx context.Context, tenant contextx.TenantInfo, month time.Time) (*Run, error) {
+	db := r.dbCtx(ctx)
+	const q = `
+SELECT id
+FROM payroll_run
+WHERE company_id = $1 AND branch_id = $2 AND payroll_month_date = $3
+  AND run_type = 'regular' AND status <> 'reversed' AND deleted_at IS NULL
+LIMIT 1`
+	var id uuid.UUID
+	if err := db.GetContext(ctx, &id, q, tenant.CompanyID, tenant.BranchID, month); err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, tenant, id)
+}
+
+type PayrollConfig struct {
+	ID                         uuid.UUID `db:"id"`
+	VersionNo                  int64     `db:"version_no"`
+	OtHourlyRate               float64   `db:"ot_hourly_rate"`
+	OtWeekdayMultiplier        *float64  `db:"ot_weekday_multiplier"`
+	HolidayWorkMultiplier      float64   `db:"holiday_work_multiplier"`
+	HolidayOtMultiplier        float64   `db:"holiday_ot_multiplier"`
+	LateGraceMinutes           int       `db:"late_grace_minutes"`
+	LateRatePerMinute          float64   `db:"late_rate_per_minute"`
+	WorkHoursPerDay            float64   `db:"work_hours_per_day"`
+	HousingAllowance           float64   `db:"housing_allowance"`
+	AttendanceBonusNoLate      float64   `db:"attendance_bonus_no_late"`
+	AttendanceBonusNoLeave     float64   `db:"attendance_bonus_no_leave"`
+	WaterRatePerUnit           float64   `db:"water_rate_per_unit"`
+	ElectricityRatePerUnit     float64   `db:"electricity_rate_per_unit"`
+	InternetFeeMonthly         float64   `db:"internet_fee_monthly"`
+	SocialSecurityRateEmployee float64   `db:"social_security_rate_employee"`
+	SocialSecurityRateEmployer float64   `db:"social_security_rate_employer"`
+	SocialSecurityWageCap      float64   `db:"social_security_wage_cap"`
+	TaxApplyStandardExpense    bool      `db:"tax_apply_standard_expense"`
+	TaxStandardExpenseRate     float64   `db:"tax_standard_expense_rate"`
+	TaxStandardExpenseCap      *float64  `db:"tax_standard_expense_cap"`
+	TaxApplyPersonalAllowance  bool      `db:"tax_apply_personal_allowance"`
+	TaxPersonalAllowanceAmount float64   `db:"tax_personal_allowance_amount"`
+	TaxProgressiveBrackets     []byte    `db:"tax_progressive_brackets"`
+	WithholdingTaxRateService  float64   `db:"withholding_tax_rate_service"`
+	ProrationBasis             string    `db:"proration_basis"`
+}
+
+// EngineConfig maps the config to the engine with the employee SSO rate of the run.
+func (c PayrollConfig) EngineConfig(ssoRate float64) engine.Config {
+	var brackets []engine.Bracket
+	_ = json.Unmarshal(c.TaxProgressiveBrackets, &brackets)
+	return engine.Config{
+		OtHourlyRate:           c.OtHourlyRate,
+		OtWeekdayMultiplier:    c.OtWeekdayMultiplier,
+		HolidayWorkMultiplier:  c.HolidayWorkMultiplier,
+		HolidayOtMultiplier:    c.HolidayOtMultiplier,
+		LateGraceMinutes:       c.LateGraceMinutes,
+		LateRatePerMinute:      c.LateRatePerMinute,
+		WorkHoursPerDay:        c.WorkHoursPerDay,
+		HousingAllowance:       c.HousingAllowance,
+		AttendanceBonusNoLate:  c.AttendanceBonusNoLate,
+		AttendanceBonusNoLeave: c.AttendanceBonusNoLeave,
+		WaterRatePerUnit:       c.WaterRatePerUnit,
+		ElectricityRatePerUnit: c.ElectricityRatePerUnit,
+		InternetFeeMonthly:     c.InternetFeeMonthly,
+		SSORateEmployee:        ssoRate,
+		SSOWageCap:             c.SocialSecurityWageCap,
+		ProrationBasis:         c.ProrationBasis,
+		Tax: engine.TaxConfig{
+			ApplyStandardExpense:   c.TaxApplyStandardExpense,
+			StandardExpenseRate:    c.TaxStandardExpenseRate,
+			StandardExpenseCap:     c.TaxStandardExpenseCap,
+			ApplyPersonalAllowance: c.TaxApplyPersonalAllowance,
+			PersonalAllowance:      c.TaxPersonalAllowanceAmount,
+			Brackets:               brackets,
+			ServiceRate:            c.WithholdingTaxRateService,
+		},
+	}
+}
+
+const payrollConfigColumns = `id, COALESCE(version_no, 0) AS version_no,
+       ot_hourly_rate, ot_weekday_multiplier,
+       COALESCE(holiday_work_multiplier, 1.0) AS holiday_work_multiplier,
+       COALESCE(holiday_ot_multiplier, 3.0) AS holiday_ot_multiplier,
+       COALESCE(late_grace_minutes, 15) AS late_grace_minutes,
+       COALESCE(late_rate_per_minute, 5) AS late_rate_per_minute,
+       COALESCE(work_hours_per_day, 8.0) AS work_hours_per_day,
+       housing_allowance, attendance_bonus_no_late, attendance_bonus_no_leave,
+       water_rate_per_unit, electricity_rate_per_unit, internet_fee_monthly,
+       social_security_rate_employee, social_security_rate_employer,
+       LEAST(COALESCE(social_security_wage_cap, 17500.00), 17500.00) AS social_security_wage_cap,
+       tax_apply_standard_expense, tax_standard_expense_rate, tax_standard_expense_cap,
+       tax_apply_personal_allowance, tax_personal_allowance_amount,
+       COALESCE(tax_progressive_brackets, '[]'::jsonb) AS tax_progressive_brackets,
+       withholding_tax_rate_service, proration_basis`
+
+// GetPayrollConfig loads configID when given, otherwise the company config effective on month,
+// the same way payroll_run_generate_items picks it. Returns sql.ErrNoRows when none applies.
+func (r Repository) GetPayrollConfig(ctx context.Context, companyID uuid.UUID, month time.Time, configID *uuid.UUID) (*PayrollConfig, error) {
+	db := r.dbCtx(ctx)
+	var (
+		q    string
+		args []interface{}
+	)
+	if configID != nil {
+		q = `SELECT ` + payrollConfigColumns + ` FROM payroll_config WHERE id = $1 AND company_id = $2`
+		args = []interface{}{*configID, companyID}
+	} else {
+		q = `SELECT ` + payrollConfigColumns + `
+FROM payroll_config pc
+WHERE pc.company_id = $1 AND pc.effective_daterange @> $2::date
+ORDER BY lower(pc.effective_daterange) DESC, pc.version_no DESC
+LIMIT 1`
+		args = []interface{}{companyID, month}
+	}
+	var cfg PayrollConfig
+	if err := db.GetContext(ctx, &cfg, q, args...); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// PreviewStored holds the amounts the database last computed for an item; all nil when the
+// employee has no item in the run.
+type PreviewStored struct {
+	ItemID                 *uuid.UUID `db:"item_id"`
+	SalaryAmount           *float64   `db:"stored_salary_amount"`
+	OtAmount               *float64   `db:"stored_ot_amount"`
+	HousingAllowance       *float64   `db:"stored_housing_allowance"`
+	AttendanceBonusNoLate  *float64   `db:"stored_attendance_bonus_nolate"`
+	AttendanceBonusNoLeave *float64   `db:"stored_attendance_bonus_noleave"`
+	BonusAmount            *float64   `db:"stored_bonus_amount"`
+	LateMinutesDeduction   *float64   `db:"stored_late_minutes_deduction"`
+	LeaveDaysDeduction     *float64   `db:"stored_leave_days_deduction"`
+	LeaveDoubleDeduction   *float64   `db:"stored_leave_double_deduction"`
+	LeaveHoursDeduction    *float64   `db:"stored_leave_hours_deduction"`
+	SSOMonthAmount         *float64   `db:"stored_sso_month_amount"`
+	TaxMonthAmount         *float64   `db:"stored_tax_month_amount"`
+	PFMonthAmount          *float64   `db:"stored_pf_month_amount"`
+	InternetAmount         *float64   `db:"stored_internet_amount"`
+	AdvanceAmount          *float64   `db:"stored_advance_amount"`
+	IncomeTotal            *float64   `db:"stored_income_total"`
+	LoanOutstandingTotal   *float64   `db:"stored_loan_outstanding_total"`
+	NetPay                 *float64   `db:"stored_net_pay"`
+}
+
+// PreviewInput is everything recalculate_payroll_item_regular reads for one employee.
+type PreviewInput struct {
+	EmployeeID                  uuid.UUID  `db:"employee_id"`
+	EmployeeNumber              string     `db:"employee_number"`
+	EmployeeName                string     `db:"employee_name"`
+	EmployeeTypeCode            string     `db:"employee_type_code"`
+	DepartmentName              *string    `db:"department_name"`
+	BasePayAmount               float64    `db:"base_pay_amount"`
+	SSOContribute               bool       `db:"sso_contribute"`
+	SSODeclaredWage             float64    `db:"sso_declared_wage"`
+	PFContribute                bool       `db:"provident_fund_contribute"`
+	PFRateEmployee              float64    `db:"provident_fund_rate_employee"`
+	WithholdTax                 bool       `db:"withhold_tax"`
+	AllowHousing                bool       `db:"allow_housing"`
+	AllowInternet               bool       `db:"allow_internet"`
+	AllowDoctorFee              bool       `db:"allow_doctor_fee"`
+	AllowAttendanceBonusNoLate  bool       `db:"allow_attendance_bonus_nolate"`
+	AllowAttendanceBonusNoLeave bool       `db:"allow_attendance_bonus_noleave"`
+	EmploymentStartDate         time.Time  `db:"employment_start_date"`
+	EmploymentEndDate           *time.Time `db:"employment_end_date"`
+
+	OtHours          float64 `db:"ot_hours"`
+	HolidayWorkHours float64 `db:"holiday_work_hours"`
+	HolidayOtHours   float64 `db:"holiday_ot_hours"`
+	LateMinutes      int     `db:"late_minutes"`
+	LeaveDays        float64 `db:"leave_days"`
+	LeaveDoubleDays  float64 `db:"leave_double_days"`
+	LeaveHours       float64 `db:"leave_hours"`
+	PTHours          float64 `db:"pt_hours"`
+	Advance          float64 `db:"advance"`
+	Installments     []byte  `db:"installments"`
+	Bonus            float64 `db:"bonus"`
+	SSOOtherRuns     float64 `db:"sso_other_runs"`
+
+	AccumSSO    float64 `db:"accum_sso"`
+	AccumTax    float64 `db:"accum_tax"`
+	AccumIncome float64 `db:"accum_income"`
+	AccumPF     float64 `db:"accum_pf"`
+	AccumLoan   float64 `db:"accum_loan"`
+
+	OthersIncome            []byte   `db:"others_income"`
+	OthersDeduction         []byte   `db:"others_deduction"`
+	LoanRepayments          []byte   `db:"loan_repayments"`
+	DoctorFee               float64  `db:"doctor_fee"`
+	LeaveCompensationAmount float64  `db:"leave_compensation_amount"`
+	WaterAmount             float64  `db:"water_amount"`
+	ElectricAmount          float64  `db:"electric_amount"`
+	AdvanceRepayAmount      float64  `db:"advance_repay_amount"`
+	ManualTax               *float64 `db:"manual_tax"`
+	ManualPF                *float64 `db:"manual_pf"`
+	ManualInternet          *float64 `db:"manual_internet"`
+	ManualWaterRate         *float64 `db:"manual_water_rate"`
+	ManualElectricRate      *float64 `db:"manual_electric_rate"`
+
+	TaxAllowanceInput
+	PreviewStored
+}
+
+// TaxAllowanceInput is the employee's tax allowance declaration for the month's year; all zero
+// when nothing was declared.
+type TaxAllowanceInput struct {
+	TASpouse                bool    `db:"ta_spouse"`
+	TAChildrenCount         int     `db:"ta_children_count"`
+	TAChildrenBorn2018Count int     `db:"ta_children_born_2018_count"`
+	TAParentsCount          int     `db:"ta_parents_count"`
+	TALifeInsurance         float64 `db:"ta_life_insurance"`
+	TAHealthInsurance       float64 `db:"ta_health_insurance"`
+	TASSFAmount             float64 `db:"ta_ssf_amount"`
+	TARMFAmount             float64 `db:"ta_rmf_amount"`
+	TAProvidentFundAmount   float64 `db:"ta_provident_fund_amount"`
+	TAHomeLoanInterest      float64 `db:"ta_home_loan_interest"`
+}
+
+func (a TaxAllowanceInput) Engine() engine.TaxAllowance {
+	return engine.TaxAllowance{
+		Spouse:                a.TASpouse,
+		ChildrenCount:         a.TAChildrenCount,
+		ChildrenBorn2018Count: a.TAChildrenBorn2018Count,
+		ParentsCount:          a.TAParentsCount,
+		LifeInsurance:         a.TALifeInsurance,
+		HealthInsurance:       a.TAHealthInsurance,
+		SSFAmount:             a.TASSFAmount,
+		RMFAmount:             a.TARMFAmount,
+		ProvidentFundAmount:   a.TAProvidentFundAmount,
+		HomeLoanInterest:      a.TAHomeLoanInterest,
+	}
+}
+
+// taxAllowanceSelect reads TaxAllowanceInput from a join aliased ta.
+const taxAllowanceSelect = `COALESCE(ta.spouse,false) AS ta_spouse,
+       COALESCE(ta.children_count,0) AS ta_children_count,
+       COALESCE(ta.children_born_2018_count,0) AS ta_children_born_2018_count,
+       COALESCE(ta.parents_count,0) AS ta_parents_count,
+       COALESCE(ta.life_insurance,0) AS ta_life_insurance,
+       COALESCE(ta.health_insurance,0) AS ta_health_insurance,
+       COALESCE(ta.ssf_amount,0) AS ta_ssf_amount,
+       COALESCE(ta.rmf_amount,0) AS ta_rmf_amount,
+       COALESCE(ta.provident_fund_amount,0) AS ta_provident_fund_amount,
+       COALESCE(ta.home_loan_interest,0) AS ta_home_loan_interest`
+
+// PreviewPeriod is the month being previewed; RunID is the pending regular run whose items
+// (manual entries and stored results) are read back, if there is one.
+type PreviewPeriod struct {
+	Month       time.Time
+	PeriodStart time.Time
+	RunID       *uuid.UUID
+}
+
+// ListPreviewInputs reads the calculation inputs of every employee the month would pay: the
+// run's items when a run exists, otherwise the employees payroll_run_generate_items would add.
+// Only pending worklogs, advances and installments count, as in the SQL recalculation.
+func (r Repository) ListPreviewInputs(ctx context.Context, tenant contextx.TenantInfo, p PreviewPeriod) ([]PreviewInput, error) {
+	db := r.dbCtx(ctx)
+	end := p.Month.AddDate(0, 1, -1)
+	args := []interface{}{tenant.CompanyID, tenant.BranchID, p.Month, p.PeriodStart, end, p.RunID}
+	q := fmt.Sprintf(`
+WITH emps AS (
+  SELECT e.id
+  FROM employees e
+  WHERE $6::uuid IS NULL
+    AND e.deleted_at IS NULL
+    AND (e.employment_end_date IS NULL OR e.employment_end_date >= $4)
+    AND e.company_id = $1 AND e.branch_id = $2
+  UNION
+  SELECT pri.employee_id
+  FROM payroll_run_item pri
+  WHERE pri.run_id = $6::uuid
+)
+SELECT e.id AS employee_id, e.employee_number,
+       (COALESCE(pt.name_th, '') || e.first_name || ' ' || e.last_name) AS employee_name,
+       et.code AS employee_type_code, d.name_th AS department_name,
+       COALESCE(e.base_pay_amount,0) AS base_pay_amount,
+       COALESCE(e.sso_contribute,false) AS sso_contribute,
+       COALESCE(e.sso_declared_wage,0) AS sso_declared_wage,
+       COALESCE(e.provident_fund_contribute,false) AS provident_fund_contribute,
+       COALESCE(e.provident_fund_rate_employee,0) AS provident_fund_rate_employee,
+       COALESCE(e.withhold_tax,false) AS withhold_tax,
+       COALESCE(e.allow_housing,false) AS allow_housing,
+       COALESCE(e.allow_internet,false) AS allow_internet,
+       COALESCE(e.allow_doctor_fee,false) AS allow_doctor_fee,
+       COALESCE(e.allow_attendance_bonus_nolate,false) AS allow_attendance_bonus_nolate,
+       COALESCE(e.allow_attendance_bonus_noleave,false) AS allow_attendance_bonus_noleave,
+       e.employment_start_date, e.employment_end_date,
+
+       COALESCE(ft.ot_hours,0) AS ot_hours,
+       COALESCE(ft.holiday_work_hours,0) AS holiday_work_hours,
+       COALESCE(ft.holiday_ot_hours,0) AS holiday_ot_hours,
+       COALESCE(ft.late_minutes,0)::int AS late_minutes,
+       COALESCE(ft.leave_days,0) AS leave_days,
+       COALESCE(ft.leave_double_days,0) AS leave_double_days,
+       COALESCE(ft.leave_hours,0) AS leave_hours,
+       COALESCE(ptw.hours,0) AS pt_hours,
+       COALESCE(adv.amount,0) AS advance,
+       COALESCE(inst.lines,'[]'::jsonb) AS installments,
+       COALESCE(bon.amount,0) AS bonus,
+       COALESCE(sso_other.amount,0) AS sso_other_runs,
+
+       COALESCE(acc.sso,0) AS accum_sso,
+       COALESCE(acc.tax,0) AS accum_tax,
+       COALESCE(acc.income,0) AS accum_income,
+       COALESCE(acc.pf,0) AS accum_pf,
+       COALESCE(acc.loan,0) AS accum_loan,
+
+       COALESCE(pri.others_income,'[]'::jsonb) AS others_income,
+       COALESCE(pri.others_deduction,'[]'::jsonb) AS others_deduction,
+       COALESCE(pri.loan_repayments,'[]'::jsonb) AS loan_repayments,
+       COALESCE(pri.doctor_fee,0) AS doctor_fee,
+       COALESCE(pri.leave_compensation_amount,0) AS leave_compensation_amount,
+       COALESCE(pri.water_amount,0) AS water_amount,
+       COALESCE(pri.electric_amount,0) AS electric_amount,
+       COALESCE(pri.advance_repay_amount,0) AS advance_repay_amount,
+       CASE WHEN pri.is_manual_tax THEN pri.tax_month_amount END AS manual_tax,
+       CASE WHEN pri.is_manual_pf THEN pri.pf_month_amount END AS manual_pf,
+       CASE WHEN pri.is_manual_internet THEN pri.internet_amount END AS manual_internet,
+       CASE WHEN pri.is_manual_water THEN pri.water_rate_per_unit END AS manual_water_rate,
+       CASE WHEN pri.is_manual_electric THEN pri.electricity_rate_per_unit END AS manual_electric_rate,
+
+       %s,
+
+       pri.id AS item_id,
+       pri.salary_amount AS stored_salary_amount,
+       pri.ot_amount AS stored_ot_amount,
+       pri.housing_allowance AS stored_housing_allowance,
+       pri.attendance_bonus_nolate AS stored_attendance_bonus_nolate,
+       pri.attendance_bonus_noleave AS stored_attendance_bonus_noleave,
+       pri.bonus_amount AS stored_bonus_amount,
+       pri.late_minutes_deduction AS stored_late_minutes_deduction,
+       pri.leave_days_deduction AS stored_leave_days_deduction,
+       pri.leave_double_deduction AS stored_leave_double_deduction,
+       pri.leave_hours_deduction AS stored_leave_hours_deduction,
+       pri.sso_month_amount AS stored_sso_month_amount,
+       pri.tax_month_amount AS stored_tax_month_amount,
+       pri.pf_month_amount AS stored_pf_month_amount,
+       pri.internet_amount AS stored_internet_amount,
+       pri.advance_amount AS stored_advance_amount,
+       pri.income_total AS stored_income_total,
+       pri.loan_outstanding_total AS stored_loan_outstanding_total,
+       (SELECT %s FROM payroll_run_item x WHERE x.id = pri.id) AS stored_net_pay
+FROM emps
+JOIN employees e ON e.id = emps.id
+JOIN employee_type et ON et.id = e.employee_type_id
+LEFT JOIN person_title pt ON pt.id = e.title_id
+LEFT JOIN department d ON d.id = e.department_id
+LEFT JOIN payroll_run_item pri ON pri.run_id = $6::uuid AND pri.employee_id = e.id
+LEFT JOIN employee_tax_allowance ta ON ta.employee_id = e.id AND ta.tax_year = EXTRACT(YEAR FROM $3::date)::int
+LEFT JOIN LATERAL (
+  SELECT SUM(w.quantity) FILTER (WHERE w.entry_type = 'ot') AS ot_hours,
+         SUM(w.quantity) FILTER (WHERE w.entry_type = 'holiday_work') AS holiday_work_hours,
+         SUM(w.quantity) FILTER (WHERE w.entry_type = 'holiday_ot') AS holiday_ot_hours,
+         SUM(w.quantity) FILTER (WHERE w.entry_type IN ('late', 'early_leave')) AS late_minutes,
+         SUM(w.quantity) FILTER (WHERE w.entry_type = 'leave_day') AS leave_days,
+         SUM(w.quantity) FILTER (WHERE w.entry_type = 'leave_double') AS leave_double_days,
+         SUM(w.quantity) FILTER (WHERE w.entry_type = 'leave_hours') AS leave_hours
+  FROM worklog_ft w
+  WHERE w.employee_id = e.id AND w.work_date BETWEEN $4 AND $5
+    AND w.status = 'pending' AND w.deleted_at IS NULL
+) ft ON TRUE
+LEFT JOIN LATERAL (
+  SELECT SUM(w.total_hours) AS hours
+  FROM worklog_pt w
+  WHERE w.employee_id = e.id AND w.work_date BETWEEN $4 AND $5
+    AND w.status = 'pending' AND w.deleted_at IS NULL
+    AND NOT EXISTS (
+      SELECT 1
+      FROM payout_pt_item pi
+      JOIN payout_pt po ON po.id = pi.payout_id
+      WHERE pi.worklog_id = w.id AND pi.deleted_at IS NULL
+        AND po.deleted_at IS NULL AND po.status = 'paid'
+    )
+) ptw ON TRUE
+LEFT JOIN LATERAL (
+  SELECT SUM(sa.amount) AS amount
+  FROM salary_advance sa
+  WHERE sa.employee_id = e.id AND sa.payroll_month_date = $3
+    AND sa.status = 'pending' AND sa.deleted_at IS NULL
+) adv ON TRUE
+LEFT JOIN LATERAL (
+  SELECT jsonb_agg(jsonb_build_object('txn_id', dt.id, 'value', dt.amount,
+                   'name', 'ผ่อนชำระงวด ' || TO_CHAR(dt.payroll_month_date, 'MM/YYYY'))) AS lines
+  FROM debt_txn dt
+  WHERE dt.employee_id = e.id AND dt.txn_type = 'installment'
+    AND dt.payroll_month_date = $3 AND dt.status = 'pending' AND dt.deleted_at IS NULL
+) inst ON TRUE
+LEFT JOIN LATERAL (
+  SELECT SUM(bi.bonus_amount) AS amount
+  FROM bonus_item bi
+  JOIN bonus_cycle bc ON bc.id = bi.cycle_id
+  WHERE bi.employee_id = e.id AND bc.payroll_month_date = $3
+    AND bc.status = 'approved' AND bc.deleted_at IS NULL
+    AND NOT EXISTS (
+      SELECT 1
+      FROM payroll_run_item bx
+      JOIN payroll_run br ON br.id = bx.run_id
+      WHERE bx.employee_id = e.id AND br.run_type = 'bonus_only'
+        AND br.company_id = $1 AND br.branch_id = $2
+        AND br.payroll_month_date = $3
+        AND br.status <> 'reversed' AND br.deleted_at IS NULL
+    )
+) bon ON TRUE
+LEFT JOIN LATERAL (
+  SELECT SUM(x.sso_month_amount) AS amount
+  FROM payroll_run_item x
+  JOIN payroll_run pr ON pr.id = x.run_id
+  WHERE x.employee_id = e.id AND pr.id IS DISTINCT FROM $6::uuid
+    AND pr.company_id = $1 AND pr.payroll_month_date = $3
+    AND pr.run_type <> 'regular' AND pr.status = 'approved' AND pr.deleted_at IS NULL
+) sso_other ON TRUE
+LEFT JOIN LATERAL (
+  SELECT SUM(pa.amount) FILTER (WHERE pa.accum_type = 'sso' AND pa.accum_year = EXTRACT(YEAR FROM $3::date)) AS sso,
+         SUM(pa.amount) FILTER (WHERE pa.accum_type = 'tax' AND pa.accum_year = EXTRACT(YEAR FROM $3::date)) AS tax,
+         SUM(pa.amount) FILTER (WHERE pa.accum_type = 'income' AND pa.accum_year = EXTRACT(YEAR FROM $3::date)) AS income,
+         SUM(pa.amount) FILTER (WHERE pa.accum_type = 'pf' AND pa.accum_year IS NULL) AS pf,
+         SUM(pa.amount) FILTER (WHERE pa.accum_type = 'loan_outstanding' AND pa.company_id = $1) AS loan
+  FROM payroll_accumulation pa
+  WHERE pa.employee_id = e.id
+) acc ON TRUE
+WHERE e.company_id = $1 AND e.branch_id = $2
+ORDER BY CASE et.code WHEN 'full_time' THEN 0 WHEN 'part_time' THEN 1 ELSE 2 END,
+         e.employee_number ASC`, taxAllowanceSelect, netPayExpr)
+	var rows []PreviewInput
+	if err := db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -25,17 +25,25 @@ func NewRepository(dbCtx transactor.DBTXContext) Repository {
 
 type Run struct {
 	ID                 uuid.UUID  `db:"id"`
+	CompanyID          uuid.UUID  `db:"company_id"`
+	BranchID           uuid.UUID  `db:"branch_id"`
 	PayrollMonth       time.Time  `db:"payroll_month_date"`
 	PeriodStart        time.Time  `db:"period_start_date"`
 	PayDate            time.Time  `db:"pay_date"`
 	Status             string     `db:"status"`
+	RunType            string     `db:"run_type"`
+	Note               *string    `db:"note"`
 	CreatedAt          time.Time  `db:"created_at"`
 	UpdatedAt          time.Time  `db:"updated_at"`
 	DeletedAt          *time.Time `db:"deleted_at"`
 	ApprovedAt         *time.Time `db:"approved_at"`
 	ApprovedBy         *uuid.UUID `db:"approved_by"`
+	ReversedAt         *time.Time `db:"reversed_at"`
+	ReversedBy         *uuid.UUID `db:"reversed_by"`
+	ReversalReason     *string    `db:"reversal_reason"`
 	SSORateEmp         float64    `db:"social_security_rate_employee"`
 	SSORateEmployer    float64    `db:"social_security_rate_employer"`
+	PayrollConfigID    *uuid.UUID `db:"payroll_config_id"`
 	OrgProfileSnapshot []byte     `db:"org_profile_snapshot"`
 	BonusYear          *int       `db:"bonus_year"`
 	TotalEmployees     int        `db:"total_employees"`
@@ -47,6 +55,15 @@ type Run struct {
 	TotalProvidentFund float64    `db:"total_provident_fund"`
 }
 
+// Run types. Only one regular run may exist per branch and month; the others are
+// supplementary runs paid alongside it.
+const (
+	RunTypeRegular    = "regular"
+	RunTypeOffCycle   = "off_cycle"
+	RunTypeBonusOnly  = "bonus_only"
+	RunTypeCorrection = "correction"
+)
+
 type RunListResult struct {
 	Rows  []Run
 	Total int
@@ -71,7 +88,7 @@ COALESCE(income_total,0)
 
 const deductionExpr = `(COALESCE(income_total,0) - (` + netPayExpr + `))`
 
-func (r Repository) List(ctx context.Context, tenant contextx.TenantInfo, page, limit int, status string, year *int, month *time.Time) (RunListResult, error) {
+func (r Repository) List(ctx context.Context, tenant contextx.TenantInfo, page, limit int, status, runType string, year *int, month *time.Time) (RunListResult, error) {
 	db := r.dbCtx(ctx)
 	offset := (page - 1) * limit
 	var where []string
@@ -93,6 +110,10 @@ func (r Repository) List(ctx context.Context, tenant contextx.TenantInfo, page,
 		args = append(args, s)
 		where = append(where, fmt.Sprintf("status = $%d", len(args)))
 	}
+	if t := strings.TrimSpace(runType); t != "" && t != "all" {
+		args = append(args, t)
+		where = append(where, fmt.Sprintf("run_type = $%d", len(args)))
+	}
 	if year != nil {
 		args = append(args, *year)
 		where = append(where, fmt.Sprintf("EXTRACT(YEAR FROM payroll_month_date) = $%d", len(args)))
@@ -105,8 +126,8 @@ func (r Repository) List(ctx context.Context, tenant contextx.TenantInfo, page,
 	whereClause := strings.Join(where, " AND ")
 	args = append(args, limit, offset)
 	q := fmt.Sprintf(`
-SELECT id, payroll_month_date, period_start_date, pay_date, status,
-       created_at, updated_at, deleted_at, approved_at, approved_by,
+SELECT id, payroll_month_date, period_start_date, pay_date, status, run_type, note,
+       created_at, updated_at, deleted_at, approved_at, approved_by, reversed_at, reversed_by, reversal_reason,
        social_security_rate_employee, social_security_rate_employer,
        COALESCE((SELECT COUNT(1) FROM payroll_run_item pri WHERE pri.run_id = payroll_run.id),0) AS total_employees,
        COALESCE((SELECT SUM(%s) FROM payroll_run_item pri WHERE pri.run_id = payroll_run.id),0) AS total_net_pay,
@@ -117,7 +138,7 @@ SELECT id, payroll_month_date, period_start_date, pay_date, status,
        COALESCE((SELECT SUM(pf_month_amount) FROM payroll_run_item pri WHERE pri.run_id = payroll_run.id),0) AS total_provident_fund
 FROM payroll_run
 WHERE %s
-ORDER BY payroll_month_date DESC
+ORDER BY payroll_month_date DESC, created_at DESC
 LIMIT $%d OFFSET $%d`, netPayExpr, deductionExpr, whereClause, len(args)-1, len(args))
 	rows, err := db.QueryxContext(ctx, q, args...)
 	if err != nil {
@@ -151,10 +172,10 @@ func (r Repository) Get(ctx context.Context, tenant contextx.TenantInfo, id uuid
 	}
 
 	q := fmt.Sprintf(`
-SELECT id, payroll_month_date, period_start_date, pay_date, status,
-       created_at, updated_at, deleted_at, approved_at, approved_by,
+SELECT id, company_id, branch_id, payroll_month_date, period_start_date, pay_date, status, run_type, note,
+       created_at, updated_at, deleted_at, approved_at, approved_by, reversed_at, reversed_by, reversal_reason,
        social_security_rate_employee, social_security_rate_employer,
-       org_profile_snapshot,
+       payroll_config_id, org_profile_snapshot,
        (
          SELECT bc.bonus_year
          FROM bonus_cycle bc
@@ -187,10 +208,11 @@ func (r Repository) Create(ctx context.Context, run Run, companyID, branchID, ac
 INSERT INTO payroll_run (
   payroll_month_date, period_start_date, pay_date,
   social_security_rate_employee, social_security_rate_employer,
+  run_type, note,
   status, company_id, branch_id, created_by, updated_by
-) VALUES ($1,$2,$3,$4,$5,'pending',$6,$7,$8,$8)
-RETURNING id, payroll_month_date, period_start_date, pay_date, status,
-          created_at, updated_at, deleted_at, approved_at, approved_by,
+) VALUES ($1,$2,$3,$4,$5,$6,$7,'pending',$8,$9,$10,$10)
+RETURNING id, company_id, branch_id, payroll_month_date, period_start_date, pay_date, status, run_type, note,
+          created_at, updated_at, deleted_at, approved_at, approved_by, reversed_at, reversed_by, reversal_reason,
           social_security_rate_employee, social_security_rate_employer,
           0 as total_employees, 0 as total_net_pay, 0 as total_income, 0 as total_deduction,
           0 as total_tax, 0 as total_sso, 0 as total_provident_fund`
@@ -198,6 +220,7 @@ RETURNING id, payroll_month_date, period_start_date, pay_date, status,
 	if err := db.GetContext(ctx, &out, q,
 		run.PayrollMonth, run.PeriodStart, run.PayDate,
 		run.SSORateEmp, run.SSORateEmployer,
+		run.RunType, run.Note,
 		companyID, branchID, actor,
 	); err != nil {
 		return nil, err
@@ -223,8 +246,8 @@ func (r Repository) UpdateStatus(ctx context.Context, tenant contextx.TenantInfo
 UPDATE payroll_run
 SET status=$1, updated_by=$2%s
 WHERE %s
-RETURNING id, payroll_month_date, period_start_date, pay_date, status,
-          created_at, updated_at, deleted_at, approved_at, approved_by,
+RETURNING id, payroll_month_date, period_start_date, pay_date, status, run_type, note,
+          created_at, updated_at, deleted_at, approved_at, approved_by, reversed_at, reversed_by, reversal_reason,
           social_security_rate_employee, social_security_rate_employer,
           COALESCE((SELECT COUNT(1) FROM payroll_run_item pri WHERE pri.run_id = payroll_run.id),0) AS total_employees,
           COALESCE((SELECT SUM(%s) FROM payroll_run_item pri WHERE pri.run_id = payroll_run.id),0) AS total_net_pay,
@@ -242,9 +265,10 @@ RETURNING id, payroll_month_date, period_start_date, pay_date, status,
 	return &run, nil
 }
 
+// Approve takes a pending run (no approval chain) or a submitted run whose chain is complete.
 func (r Repository) Approve(ctx context.Context, tenant contextx.TenantInfo, id uuid.UUID, actor uuid.UUID) (*Run, error) {
 	db := r.dbCtx(ctx)
-	where := "id=$2 AND deleted_at IS NULL AND status <> 'approved' AND company_id=$3"
+	where := "id=$2 AND deleted_at IS NULL AND status IN ('pending','submitted') AND company_id=$3"
 	args := []interface{}{actor, id, tenant.CompanyID}
 	if tenant.HasBranchID() {
 		args = append(args, tenant.BranchID)
@@ -255,8 +279,8 @@ func (r Repository) Approve(ctx context.Context, tenant contextx.TenantInfo, id
 UPDATE payroll_run
 SET status='approved', approved_by=$1, approved_at=COALESCE(approved_at, now()), updated_by=$1
 WHERE %s
-RETURNING id, payroll_month_date, period_start_date, pay_date, status,
-          created_at, updated_at, deleted_at, approved_at, approved_by,
+RETURNING id, payroll_month_date, period_start_date, pay_date, status, run_type, note,
+          created_at, updated_at, deleted_at, approved_at, approved_by, reversed_at, reversed_by, reversal_reason,
           social_security_rate_employee, social_security_rate_employer,
           COALESCE((SELECT COUNT(1) FROM payroll_run_item pri WHERE pri.run_id = payroll_run.id),0) AS total_employees,
           COALESCE((SELECT SUM(%s) FROM payroll_run_item pri WHERE pri.run_id = payroll_run.id),0) AS total_net_pay,
@@ -305,10 +329,19 @@ type Item struct {
 	BankName                *string    `db:"bank_name"`
 	BankAccount             *string    `db:"bank_account_no"`
 	SalaryAmount            float64    `db:"salary_amount"`
+	ProrationBasis          *string    `db:"proration_basis"`
+	ProrationDays           *float64   `db:"proration_days"`
+	ProrationPeriodDays     *float64   `db:"proration_period_days"`
 	PTHoursWorked           float64    `db:"pt_hours_worked"`
 	PTHourlyRate            float64    `db:"pt_hourly_rate"`
 	OtHours                 float64    `db:"ot_hours"`
 	OtAmount                float64    `db:"ot_amount"`
+	OtWeekdayHours          float64    `db:"ot_weekday_hours"`
+	OtWeekdayAmount         float64    `db:"ot_weekday_amount"`
+	HolidayWorkHours        float64    `db:"holiday_work_hours"`
+	HolidayWorkAmount       float64    `db:"holiday_work_amount"`
+	HolidayOtHours          float64    `db:"holiday_ot_hours"`
+	HolidayOtAmount         float64    `db:"holiday_ot_amount"`
 	BonusAmount             float64    `db:"bonus_amount"`
 	LeaveCompensationAmount float64    `db:"leave_compensation_amount"`
 	IncomeTotal             float64    `db:"income_total"`
@@ -385,6 +418,9 @@ SELECT pri.id, pri.run_id, pri.employee_id, %s AS employee_name, e.employee_numb
        e.photo_id,
        pri.employee_type_name, pri.department_name, pri.position_name, pri.bank_name, pri.bank_account_no,
        pri.salary_amount, pri.pt_hours_worked, pri.pt_hourly_rate, pri.ot_hours, pri.ot_amount, pri.bonus_amount,
+       pri.ot_weekday_hours, pri.ot_weekday_amount, pri.holiday_work_hours, pri.holiday_work_amount,
+       pri.holiday_ot_hours, pri.holiday_ot_amount,
+       pri.proration_basis, pri.proration_days, pri.proration_period_days,
        pri.income_total, pri.income_accum_prev, pri.income_accum_total,
        pri.leave_compensation_amount, pri.leave_days_qty, pri.leave_days_deduction, pri.late_minutes_qty, pri.late_minutes_deduction,
        pri.sso_month_amount, pri.tax_month_amount, (%s) AS net_pay, 'pending' as status,
@@ -473,6 +509,9 @@ func (r Repository) UpdateItem(ctx context.Context, tenant contextx.TenantInfo,
        (SELECT et.code FROM employees e JOIN employee_type et ON et.id = e.employee_type_id WHERE e.id = payroll_run_item.employee_id) AS employee_type_code,
        employee_type_name, department_name, position_name, bank_name, bank_account_no,
        salary_amount, pt_hours_worked, pt_hourly_rate, ot_hours, ot_amount, bonus_amount,
+       ot_weekday_hours, ot_weekday_amount, holiday_work_hours, holiday_work_amount,
+       holiday_ot_hours, holiday_ot_amount,
+       proration_basis, proration_days, proration_period_days,
        income_total, income_accum_prev, income_accum_total,
        COALESCE(leave_compensation_amount,0) AS leave_compensation_amount, leave_days_qty, leave_days_deduction, late_minutes_qty, late_minutes_deduction,
        sso_month_amount, tax_month_amount, (%s) AS net_pay, 'pending' as status,
@@ -511,6 +550,9 @@ func (r Repository) GetItem(ctx context.Context, tenant contextx.TenantInfo, id
        e.photo_id,
        pri.employee_type_name, pri.department_name, pri.position_name, pri.bank_name, pri.bank_account_no,
        pri.salary_amount, pri.pt_hours_worked, pri.pt_hourly_rate, pri.ot_hours, pri.ot_amount, pri.bonus_amount,
+       pri.ot_weekday_hours, pri.ot_weekday_amount, pri.holiday_work_hours, pri.holiday_work_amount,
+       pri.holiday_ot_hours, pri.holiday_ot_amount,
+       pri.proration_basis, pri.proration_days, pri.proration_period_days,
        pri.income_total, pri.income_accum_prev, pri.income_accum_total,
        COALESCE(pri.leave_compensation_amount,0) AS leave_compensation_amount, pri.leave_days_qty, pri.leave_days_deduction, pri.late_minutes_qty, pri.late_minutes_deduction,
        pri.sso_month_amount, pri.tax_month_amount, (%s) AS net_pay, 'pending' as status,
@@ -580,20 +622,15 @@ type ItemDetail struct {
 	ElectricityRatePerUnit  float64   `db:"electricity_rate_per_unit"`
 }
 
-func (r Repository) GetItemDetail(ctx context.Context, tenant contextx.TenantInfo, id uuid.UUID) (*ItemDetail, error) {
-	db := r.dbCtx(ctx)
-	where := "pri.id = $1 AND pri.company_id = $2"
-	args := []interface{}{id, tenant.CompanyID}
-	if tenant.HasBranchID() {
-		args = append(args, tenant.BranchID)
-		where += " AND pri.branch_id = $3"
-	}
-
-	q := fmt.Sprintf(`SELECT pri.id, pri.run_id, pri.employee_id,
+// itemDetailSelect is formatted with netPayExpr and deductionExpr; callers append the WHERE clause.
+const itemDetailSelect = `SELECT pri.id, pri.run_id, pri.employee_id,
        (pt.name_th || e.first_name || ' ' || e.last_name || COALESCE(' (' || NULLIF(e.nickname, '') || ')', '')) AS employee_name, e.employee_number, et.code AS employee_type_code,
        e.photo_id,
        pri.employee_type_name, pri.department_name, pri.position_name, pri.bank_name, pri.bank_account_no,
        pri.salary_amount, pri.pt_hours_worked, pri.pt_hourly_rate, pri.ot_hours, pri.ot_amount, pri.bonus_amount,
+       pri.ot_weekday_hours, pri.ot_weekday_amount, pri.holiday_work_hours, pri.holiday_work_amount,
+       pri.holiday_ot_hours, pri.holiday_ot_amount,
+       pri.proration_basis, pri.proration_days, pri.proration_period_days,
        pri.income_total, pri.income_accum_prev, pri.income_accum_total,
        COALESCE(pri.leave_compensation_amount,0) AS leave_compensation_amount, pri.leave_days_qty, pri.leave_days_deduction, pri.late_minutes_qty, pri.late_minutes_deduction,
        pri.sso_month_amount, pri.tax_month_amount, (%s) AS net_pay, 'pending' AS status,
@@ -629,7 +666,18 @@ FROM payroll_run_item pri
 JOIN employees e ON e.id = pri.employee_id
 LEFT JOIN person_title pt ON pt.id = e.title_id
 JOIN employee_type et ON et.id = e.employee_type_id
-WHERE %s`, netPayExpr, deductionExpr, where)
+`
+
+func (r Repository) GetItemDetail(ctx context.Context, tenant contextx.TenantInfo, id uuid.UUID) (*ItemDetail, error) {
+	db := r.dbCtx(ctx)
+	where := "pri.id = $1 AND pri.company_id = $2"
+	args := []interface{}{id, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where += " AND pri.branch_id = $3"
+	}
+
+	q := fmt.Sprintf(itemDetailSelect+`WHERE %s`, netPayExpr, deductionExpr, where)
 	var it ItemDetail
 	if err := db.GetContext(ctx, &it, q, args...); err != nil {
 		return nil, err
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"hrms/shared/common/contextx"
+)
+
+// ReverseHintLaterRun is the pq hint payroll_run_reverse raises when a later approved run
+// covers the same employees.
+const ReverseHintLaterRun = "later_run_approved"
+
+// Reverse undoes an approved run's postings through payroll_run_reverse and returns the reversed run.
+func (r Repository) Reverse(ctx context.Context, tenant contextx.TenantInfo, id, actor uuid.UUID, reason string) (*Run, error) {
+	db := r.dbCtx(ctx)
+	if _, err := db.ExecContext(ctx, `SELECT payroll_run_reverse($1, $2, $3)`, id, actor, reason); err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, tenant, id)
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// AddItem puts an employee on a supplementary run and lets recalculate_payroll_item fill the figures.
+// It reports false when the employee is not in the run's company and branch or is already on the run.
+func (r Repository) AddItem(ctx context.Context, run Run, employeeID, actor uuid.UUID) (bool, error) {
+	db := r.dbCtx(ctx)
+	const q = `
+INSERT INTO payroll_run_item (
+  run_id, employee_id, company_id, branch_id, employee_type_id, created_by, updated_by
+)
+SELECT $1, e.id, $3, $4, e.employee_type_id, $5, $5
+FROM employees e
+WHERE e.id = $2 AND e.company_id = $3 AND e.branch_id = $4 AND e.deleted_at IS NULL
+ON CONFLICT (run_id, employee_id) DO NOTHING`
+	res, err := db.ExecContext(ctx, q, run.ID, employeeID, run.CompanyID, run.BranchID, actor)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+	if err := r.Recalculate(ctx, run.ID, employeeID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r Repository) RemoveItem(ctx context.Context, run Run, employeeID uuid.UUID) (bool, error) {
+	db := r.dbCtx(ctx)
+	res, err := db.ExecContext(ctx, `DELETE FROM payroll_run_item WHERE run_id = $1 AND employee_id = $2`, run.ID, employeeID)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
+
+func (r Repository) CountItems(ctx context.Context, runID uuid.UUID) (int, error) {
+	db := r.dbCtx(ctx)
+	var n int
+	if err := db.GetContext(ctx, &n, `SELECT COUNT(1) FROM payroll_run_item WHERE run_id = $1`, runID); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Recalculate reruns recalculate_payroll_item for one employee on a run.
+func (r Repository) Recalculate(ctx context.Context, runID, employeeID uuid.UUID) error {
+	db := r.dbCtx(ctx)
+	_, err := db.ExecContext(ctx, `SELECT recalculate_payroll_item($1, $2)`, runID, employeeID)
+	return err
+}
+
+// FindItemID returns the id of the employee's item on a run, or sql.ErrNoRows.
+func (r Repository) FindItemID(ctx context.Context, runID, employeeID uuid.UUID) (uuid.UUID, error) {
+	db := r.dbCtx(ctx)
+	var id uuid.UUID
+	err := db.GetContext(ctx, &id, `SELECT id FROM payroll_run_item WHERE run_id = $1 AND employee_id = $2`, runID, employeeID)
+	return id, err
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/shared/common/contextx"
+)
+
+type SalaryRaiseCycle struct {
+	ID       uuid.UUID `db:"id"`
+	Status   string    `db:"status"`
+	BranchID uuid.UUID `db:"branch_id"`
+}
+
+// GetSalaryRaiseCycle loads a live raise cycle of the tenant, or sql.ErrNoRows.
+func (r Repository) GetSalaryRaiseCycle(ctx context.Context, tenant contextx.TenantInfo, id uuid.UUID) (*SalaryRaiseCycle, error) {
+	db := r.dbCtx(ctx)
+	where := "id = $1 AND company_id = $2 AND deleted_at IS NULL"
+	args := []interface{}{id, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where += fmt.Sprintf(" AND branch_id = $%d", len(args))
+	}
+	var c SalaryRaiseCycle
+	if err := db.GetContext(ctx, &c, `SELECT id, status, branch_id FROM salary_raise_cycle WHERE `+where, args...); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// SimulationEmployee is an active employee with the recurring inputs of a typical month:
+// OT and part-time hours are averaged over the last three approved regular runs.
+// NewSalary and NewSSOWage are set when the employee is in the simulated raise cycle.
+type SimulationEmployee struct {
+	EmployeeID                  uuid.UUID  `db:"employee_id"`
+	EmployeeNumber              string     `db:"employee_number"`
+	EmployeeName                string     `db:"employee_name"`
+	EmployeeTypeCode            string     `db:"employee_type_code"`
+	DepartmentID                *uuid.UUID `db:"department_id"`
+	DepartmentName              *string    `db:"department_name"`
+	BranchID                    uuid.UUID  `db:"branch_id"`
+	BranchName                  string     `db:"branch_name"`
+	BasePayAmount               float64    `db:"base_pay_amount"`
+	SSOContribute               bool       `db:"sso_contribute"`
+	SSODeclaredWage             float64    `db:"sso_declared_wage"`
+	PFContribute                bool       `db:"provident_fund_contribute"`
+	PFRateEmployee              float64    `db:"provident_fund_rate_employee"`
+	PFRateEmployer              float64    `db:"provident_fund_rate_employer"`
+	WithholdTax                 bool       `db:"withhold_tax"`
+	AllowHousing                bool       `db:"allow_housing"`
+	AllowInternet               bool       `db:"allow_internet"`
+	AllowAttendanceBonusNoLate  bool       `db:"allow_attendance_bonus_nolate"`
+	AllowAttendanceBonusNoLeave bool       `db:"allow_attendance_bonus_noleave"`
+	AvgOtHours                  float64    `db:"avg_ot_hours"`
+	AvgHolidayWorkHours         float64    `db:"avg_holiday_work_hours"`
+	AvgHolidayOtHours           float64    `db:"avg_holiday_ot_hours"`
+	AvgPTHours                  float64    `db:"avg_pt_hours"`
+	NewSalary                   *float64   `db:"new_salary"`
+	NewSSOWage                  *float64   `db:"new_sso_wage"`
+
+	TaxAllowanceInput
+}
+
+// ListSimulationEmployees returns the employees on payroll in month: employed on at least one
+// of its days, so neither joiners after the month nor leavers before it. It is scoped to the
+// tenant's branch when one is selected.
+func (r Repository) ListSimulationEmployees(ctx context.Context, tenant contextx.TenantInfo, month time.Time, raiseCycleID *uuid.UUID) ([]SimulationEmployee, error) {
+	db := r.dbCtx(ctx)
+	where := `e.company_id = $1 AND e.deleted_at IS NULL
+  AND e.employment_start_date <= ($2::date + INTERVAL '1 month' - INTERVAL '1 day')::date
+  AND (e.employment_end_date IS NULL OR e.employment_end_date >= $2)`
+	args := []interface{}{tenant.CompanyID, month, raiseCycleID}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where += fmt.Sprintf(" AND e.branch_id = $%d", len(args))
+	}
+	q := fmt.Sprintf(`
+SELECT e.id AS employee_id, e.employee_number,
+       (COALESCE(pt.name_th, '') || e.first_name || ' ' || e.last_name) AS employee_name,
+       et.code AS employee_type_code,
+       e.department_id, d.name_th AS department_name,
+       e.branch_id, b.name AS branch_name,
+       COALESCE(e.base_pay_amount,0) AS base_pay_amount,
+       COALESCE(e.sso_contribute,false) AS sso_contribute,
+       COALESCE(e.sso_declared_wage,0) AS sso_declared_wage,
+       COALESCE(e.provident_fund_contribute,false) AS provident_fund_contribute,
+       COALESCE(e.provident_fund_rate_employee,0) AS provident_fund_rate_employee,
+       COALESCE(e.provident_fund_rate_employer,0) AS provident_fund_rate_employer,
+       COALESCE(e.withhold_tax,false) AS withhold_tax,
+       COALESCE(e.allow_housing,false) AS allow_housing,
+       COALESCE(e.allow_internet,false) AS allow_internet,
+       COALESCE(e.allow_attendance_bonus_nolate,false) AS allow_attendance_bonus_nolate,
+       COALESCE(e.allow_attendance_bonus_noleave,false) AS allow_attendance_bonus_noleave,
+       COALESCE(hist.ot_hours,0) AS avg_ot_hours,
+       COALESCE(hist.holiday_work_hours,0) AS avg_holiday_work_hours,
+       COALESCE(hist.holiday_ot_hours,0) AS avg_holiday_ot_hours,
+       COALESCE(hist.pt_hours,0) AS avg_pt_hours,
+       sri.new_salary,
+       sri.new_sso_wage,
+       %s
+FROM employees e
+JOIN employee_type et ON et.id = e.employee_type_id
+JOIN branches b ON b.id = e.branch_id
+LEFT JOIN person_title pt ON pt.id = e.title_id
+LEFT JOIN department d ON d.id = e.department_id
+LEFT JOIN salary_raise_item sri ON sri.cycle_id = $3::uuid AND sri.employee_id = e.id
+LEFT JOIN employee_tax_allowance ta ON ta.employee_id = e.id AND ta.tax_year = EXTRACT(YEAR FROM $2::date)::int
+LEFT JOIN LATERAL (
+  SELECT AVG(h.ot_weekday_hours) AS ot_hours, AVG(h.holiday_work_hours) AS holiday_work_hours,
+         AVG(h.holiday_ot_hours) AS holiday_ot_hours, AVG(h.pt_hours_worked) AS pt_hours
+  FROM (
+    SELECT pri.ot_weekday_hours, pri.holiday_work_hours, pri.holiday_ot_hours, pri.pt_hours_worked
+    FROM payroll_run_item pri
+    JOIN payroll_run pr ON pr.id = pri.run_id
+    WHERE pri.employee_id = e.id AND pr.run_type = 'regular'
+      AND pr.status = 'approved' AND pr.deleted_at IS NULL
+      AND pr.payroll_month_date < $2
+    ORDER BY pr.payroll_month_date DESC
+    LIMIT 3
+  ) h
+) hist ON TRUE
+WHERE %s
+ORDER BY b.name ASC, d.name_th ASC NULLS LAST, e.employee_number ASC`, taxAllowanceSelect, where)
+	var rows []SimulationEmployee
+	if err := db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"hrms/shared/common/contextx"
+)
+
+type SSOContribution struct {
+	EmployeeID     uuid.UUID `db:"employee_id"`
+	EmployeeNumber string    `db:"employee_number"`
+	CitizenID      string    `db:"citizen_id"`
+	TitleCode      string    `db:"title_code"`
+	TitleName      string    `db:"title_name"`
+	FirstName      string    `db:"first_name"`
+	LastName       string    `db:"last_name"`
+	Wage           float64   `db:"wage"`
+	EmployeeAmount float64   `db:"employee_amount"`
+}
+
+// ListSSOContributions returns one line per insured employee for the สปส.1-10 filing of the
+// run's branch and month. The office takes a single filing per branch and month, so wages and
+// contributions are summed over every approved run of that month (a reversed run no longer
+// counts) and the wage cap and its contribution are applied to the totals. Contribution is read
+// from each item's settings snapshot so that an employee switched off after the run still
+// appears in that month's filing.
+func (r Repository) ListSSOContributions(ctx context.Context, tenant contextx.TenantInfo, run Run, wageCap, employeeRate float64) ([]SSOContribution, error) {
+	db := r.dbCtx(ctx)
+	where := `pr.company_id = $1 AND pr.branch_id = $2 AND pr.payroll_month_date = $3
+  AND pr.status = 'approved' AND pr.deleted_at IS NULL`
+	args := []interface{}{run.CompanyID, run.BranchID, run.PayrollMonth, wageCap, employeeRate}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where += fmt.Sprintf(" AND pri.branch_id = $%d", len(args))
+	}
+	q := fmt.Sprintf(`
+SELECT e.id AS employee_id, e.employee_number,
+       e.id_document_number AS citizen_id,
+       pt.code AS title_code, pt.name_th AS title_name,
+       e.first_name, e.last_name,
+       LEAST(SUM(COALESCE(pri.sso_declared_wage,0)), $4) AS wage,
+       LEAST(SUM(COALESCE(pri.sso_month_amount,0)), ROUND($4::numeric * $5::numeric, 2)) AS employee_amount
+FROM payroll_run pr
+JOIN payroll_run_item pri ON pri.run_id = pr.id
+JOIN employees e ON e.id = pri.employee_id
+JOIN person_title pt ON pt.id = e.title_id
+WHERE %s
+  AND COALESCE((pri.employee_settings_snapshot->>'sso_contribute')::boolean, pri.sso_month_amount > 0)
+GROUP BY e.id, e.employee_number, e.id_document_number, pt.code, pt.name_th, e.first_name, e.last_name
+HAVING SUM(COALESCE(pri.sso_declared_wage,0)) > 0
+ORDER BY e.employee_number ASC`, where)
+	var rows []SSOContribution
+	if err := db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+type SSOSettings struct {
+	BranchNo string  `db:"sso_branch_no"`
+	WageCap  float64 `db:"wage_cap"`
+}
+
+// GetSSOSettings reads the run branch's SSO branch number and the wage cap of the config
+// the run was calculated with (falling back to the config effective for the run month).
+func (r Repository) GetSSOSettings(ctx context.Context, run Run) (*SSOSettings, error) {
+	db := r.dbCtx(ctx)
+	const q = `
+SELECT b.sso_branch_no,
+       LEAST(COALESCE(pc.social_security_wage_cap, 17500.00), 17500.00) AS wage_cap
+FROM payroll_run pr
+JOIN branches b ON b.id = pr.branch_id
+LEFT JOIN LATERAL (
+  SELECT c.social_security_wage_cap
+  FROM payroll_config c
+  WHERE (pr.payroll_config_id IS NOT NULL AND c.id = pr.payroll_config_id)
+     OR (pr.payroll_config_id IS NULL AND c.company_id = pr.company_id
+         AND c.effective_daterange @> pr.payroll_month_date)
+  ORDER BY lower(c.effective_daterange) DESC, c.version_no DESC
+  LIMIT 1
+) pc ON TRUE
+WHERE pr.id = $1 AND pr.company_id = $2`
+	var s SSOSettings
+	if err := db.GetContext(ctx, &s, q, run.ID, run.CompanyID); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
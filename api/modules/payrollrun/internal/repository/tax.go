@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/shared/common/contextx"
+)
+
+type TaxPayee struct {
+	EmployeeID     uuid.UUID `db:"employee_id"`
+	EmployeeNumber string    `db:"employee_number"`
+	CitizenID      string    `db:"citizen_id"`
+	TitleName      string    `db:"title_name"`
+	FirstName      string    `db:"first_name"`
+	LastName       string    `db:"last_name"`
+	PayDate        time.Time `db:"pay_date"`
+	Income         float64   `db:"income"`
+	Tax            float64   `db:"tax"`
+}
+
+// ListMonthlyTaxPayees sums income and withholding per employee over the approved runs of a month.
+// When withheldOnly is set, employees with no tax withheld are left out, as the monthly form allows.
+func (r Repository) ListMonthlyTaxPayees(ctx context.Context, tenant contextx.TenantInfo, month time.Time, withheldOnly bool) ([]TaxPayee, error) {
+	db := r.dbCtx(ctx)
+	where := "pr.payroll_month_date = $1 AND pr.company_id = $2 AND pr.status = 'approved' AND pr.deleted_at IS NULL"
+	args := []interface{}{month, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where += fmt.Sprintf(" AND pr.branch_id = $%d", len(args))
+	}
+	having := "SUM(COALESCE(pri.income_total,0)) > 0"
+	if withheldOnly {
+		having = "SUM(COALESCE(pri.tax_month_amount,0)) > 0"
+	}
+	q := fmt.Sprintf(`
+SELECT e.id AS employee_id, e.employee_number,
+       e.id_document_number AS citizen_id,
+       pt.name_th AS title_name, e.first_name, e.last_name,
+       MAX(pr.pay_date) AS pay_date,
+       SUM(COALESCE(pri.income_total,0)) AS income,
+       SUM(COALESCE(pri.tax_month_amount,0)) AS tax
+FROM payroll_run pr
+JOIN payroll_run_item pri ON pri.run_id = pr.id
+JOIN employees e ON e.id = pri.employee_id
+JOIN person_title pt ON pt.id = e.title_id
+WHERE %s
+GROUP BY e.id, e.employee_number, e.id_document_number, pt.name_th, e.first_name, e.last_name
+HAVING %s
+ORDER BY e.employee_number ASC`, where, having)
+	var rows []TaxPayee
+	if err := db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// ListAnnualTaxPayees reads the year's income and tax accumulations; pay_date is left zero.
+// Branch scoping follows the employee's current branch.
+func (r Repository) ListAnnualTaxPayees(ctx context.Context, tenant contextx.TenantInfo, year int) ([]TaxPayee, error) {
+	db := r.dbCtx(ctx)
+	where := "pa.accum_year = $1 AND pa.company_id = $2 AND pa.accum_type IN ('income','tax')"
+	args := []interface{}{year, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where += fmt.Sprintf(" AND e.branch_id = $%d", len(args))
+	}
+	q := fmt.Sprintf(`
+SELECT e.id AS employee_id, e.employee_number,
+       e.id_document_number AS citizen_id,
+       pt.name_th AS title_name, e.first_name, e.last_name,
+       make_date($1, 12, 31) AS pay_date,
+       COALESCE(SUM(pa.amount) FILTER (WHERE pa.accum_type = 'income'), 0) AS income,
+       COALESCE(SUM(pa.amount) FILTER (WHERE pa.accum_type = 'tax'), 0) AS tax
+FROM payroll_accumulation pa
+JOIN employees e ON e.id = pa.employee_id
+JOIN person_title pt ON pt.id = e.title_id
+WHERE %s
+GROUP BY e.id, e.employee_number, e.id_document_number, pt.name_th, e.first_name, e.last_name
+HAVING COALESCE(SUM(pa.amount) FILTER (WHERE pa.accum_type = 'income'), 0) > 0
+ORDER BY e.employee_number ASC`, where)
+	var rows []TaxPayee
+	if err := db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/shared/common/contextx"
+)
+
+type VarianceLine struct {
+	EmployeeID          uuid.UUID  `db:"employee_id"`
+	EmployeeNumber      string     `db:"employee_number"`
+	EmployeeName        string     `db:"employee_name"`
+	DepartmentName      *string    `db:"department_name"`
+	EmploymentStartDate time.Time  `db:"employment_start_date"`
+	EmploymentEndDate   *time.Time `db:"employment_end_date"`
+	SalaryAmount        float64    `db:"salary_amount"`
+	OtAmount            float64    `db:"ot_amount"`
+	BonusAmount         float64    `db:"bonus_amount"`
+	IncomeTotal         float64    `db:"income_total"`
+	DeductionTotal      float64    `db:"deduction_total"`
+	TaxMonthAmount      float64    `db:"tax_month_amount"`
+	SsoMonthAmount      float64    `db:"sso_month_amount"`
+	PfMonthAmount       float64    `db:"pf_month_amount"`
+	NetPay              float64    `db:"net_pay"`
+}
+
+// PreviousApprovedRun finds the approved run of the same branch and run type that precedes run,
+// by payroll month and then creation time. Returns sql.ErrNoRows when there is none.
+func (r Repository) PreviousApprovedRun(ctx context.Context, tenant contextx.TenantInfo, run Run) (*Run, error) {
+	db := r.dbCtx(ctx)
+	const q = `
+SELECT id
+FROM payroll_run
+WHERE company_id = $1 AND branch_id = $2 AND run_type = $3
+  AND status = 'approved' AND deleted_at IS NULL AND id <> $4
+  AND (payroll_month_date, created_at) < ($5, $6)
+ORDER BY payroll_month_date DESC, created_at DESC
+LIMIT 1`
+	var id uuid.UUID
+	if err := db.GetContext(ctx, &id, q, tenant.CompanyID, run.BranchID, run.RunType, run.ID, run.PayrollMonth, run.CreatedAt); err != nil {
+		return nil, err
+	}
+	return r.Get(ctx, tenant, id)
+}
+
+// ListVarianceLines returns the compared amounts of every item in a run, one line per employee.
+func (r Repository) ListVarianceLines(ctx context.Context, tenant contextx.TenantInfo, runID uuid.UUID) ([]VarianceLine, error) {
+	db := r.dbCtx(ctx)
+	where := "pri.run_id = $1 AND e.company_id = $2"
+	args := []interface{}{runID, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where += fmt.Sprintf(" AND e.branch_id = $%d", len(args))
+	}
+	q := fmt.Sprintf(`
+SELECT pri.employee_id, e.employee_number,
+       (COALESCE(pt.name_th, '') || e.first_name || ' ' || e.last_name) AS employee_name,
+       pri.department_name, e.employment_start_date, e.employment_end_date,
+       COALESCE(pri.salary_amount,0) AS salary_amount,
+       COALESCE(pri.ot_amount,0) AS ot_amount,
+       COALESCE(pri.bonus_amount,0) AS bonus_amount,
+       COALESCE(pri.income_total,0) AS income_total,
+       (%s) AS deduction_total,
+       COALESCE(pri.tax_month_amount,0) AS tax_month_amount,
+       COALESCE(pri.sso_month_amount,0) AS sso_month_amount,
+       COALESCE(pri.pf_month_amount,0) AS pf_month_amount,
+       (%s) AS net_pay
+FROM payroll_run_item pri
+JOIN employees e ON e.id = pri.employee_id
+LEFT JOIN person_title pt ON pt.id = e.title_id
+WHERE %s
+ORDER BY e.employee_number ASC`, deductionExpr, netPayExpr, where)
+	var rows []VarianceLine
+	if err := db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,190 @@
+// Package ssofile writes the Social Security Office monthly contribution file (สปส.1-10 ส่วนที่ 2)
+// in the fixed-width text layout accepted by the SSO e-Service upload.
+//
+// The file is TIS-620 encoded with CRLF line endings: one header record (type 1) followed by
+// one detail record (type 2) per insured employee. Amounts carry two implied decimals.
+package ssofile
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"hrms/modules/payrollrun/internal/thaienc"
+)
+
+// Contribution is one insured employee's line.
+type Contribution struct {
+	CitizenID      string
+	TitleCode      string // person_title.code: mr, mrs, ms
+	FirstName      string
+	LastName       string
+	Wage           float64
+	EmployeeAmount float64
+}
+
+// Filing is the whole monthly submission for one employer account and branch.
+type Filing struct {
+	EmployerAccountNo string
+	BranchNo          string
+	CompanyName       string
+	PayDate           time.Time
+	PeriodMonth       time.Time
+	EmployeeRate      float64 // e.g. 0.05
+	EmployerRate      float64
+	Contributions     []Contribution
+}
+
+// Totals are summed per line in satang so the header matches the detail records exactly.
+type Totals struct {
+	Employees      int
+	WageSatang     int64
+	EmployeeSatang int64
+	EmployerSatang int64
+}
+
+func (t Totals) ContributionSatang() int64 { return t.EmployeeSatang + t.EmployerSatang }
+
+// EmployerAmount is the employer's share for a wage; it mirrors the employee rounding.
+func EmployerAmount(wage, rate float64) float64 {
+	return float64(satang(wage*rate)) / 100
+}
+
+func (f Filing) Totals() Totals {
+	t := Totals{Employees: len(f.Contributions)}
+	for _, c := range f.Contributions {
+		t.WageSatang += satang(c.Wage)
+		t.EmployeeSatang += satang(c.EmployeeAmount)
+		t.EmployerSatang += satang(c.Wage * f.EmployerRate)
+	}
+	return t
+}
+
+// titleCodes maps person_title.code to the SSO prefix code.
+var titleCodes = map[string]string{
+	"mr":  "003",
+	"ms":  "004",
+	"mrs": "005",
+}
+
+// Validate checks what the SSO upload rejects outright.
+func Validate(f Filing) error {
+	if len(digits(f.EmployerAccountNo)) != 10 {
+		return fmt.Errorf("employer SSO account number must be 10 digits")
+	}
+	if len(digits(f.BranchNo)) != 6 {
+		return fmt.Errorf("SSO branch number must be 6 digits")
+	}
+	if len(f.Contributions) == 0 {
+		return fmt.Errorf("no insured employees in this month")
+	}
+	for _, c := range f.Contributions {
+		if len(digits(c.CitizenID)) != 13 {
+			return fmt.Errorf("citizen id of %s %s must be 13 digits", c.FirstName, c.LastName)
+		}
+	}
+	return nil
+}
+
+// Write renders the filing. Callers should Validate first. A number too long for its field is
+// an error rather than being cut to its last digits.
+func Write(w io.Writer, f Filing) error {
+	t := f.Totals()
+	var fl fields
+	header := "1" +
+		fl.padLeft("employer account", digits(f.EmployerAccountNo), 10, '0') +
+		fl.padLeft("branch", digits(f.BranchNo), 6, '0') +
+		buddhistDate(f.PayDate) +
+		buddhistPeriod(f.PeriodMonth) +
+		padRight(thaienc.TIS620(strings.TrimSpace(f.CompanyName)), 45) +
+		fl.zeroNum("employee rate", satang(f.EmployeeRate*100), 4) +
+		fl.zeroNum("employee count", int64(t.Employees), 6) +
+		fl.zeroNum("total wage", t.WageSatang, 15) +
+		fl.zeroNum("total contribution", t.ContributionSatang(), 14) +
+		fl.zeroNum("total employee contribution", t.EmployeeSatang, 12) +
+		fl.zeroNum("total employer contribution", t.EmployerSatang, 12)
+	lines := []string{header}
+	for _, c := range f.Contributions {
+		title := titleCodes[strings.ToLower(strings.TrimSpace(c.TitleCode))]
+		if title == "" {
+			title = "000"
+		}
+		who := strings.TrimSpace(c.FirstName + " " + c.LastName)
+		lines = append(lines, "2"+
+			fl.padLeft("citizen id of "+who, digits(c.CitizenID), 13, '0')+
+			title+
+			padRight(thaienc.TIS620(strings.TrimSpace(c.FirstName)), 30)+
+			padRight(thaienc.TIS620(strings.TrimSpace(c.LastName)), 35)+
+			fl.zeroNum("wage of "+who, satang(c.Wage), 14)+
+			fl.zeroNum("contribution of "+who, satang(c.EmployeeAmount), 12)+
+			strings.Repeat(" ", 24))
+	}
+	if fl.err != nil {
+		return fl.err
+	}
+	for _, l := range lines {
+		if _, err := io.WriteString(w, l+"\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func satang(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// buddhistDate is DDMMYY with a two-digit Buddhist-era year.
+func buddhistDate(d time.Time) string {
+	return fmt.Sprintf("%02d%02d%02d", d.Day(), int(d.Month()), (d.Year()+543)%100)
+}
+
+// buddhistPeriod is MMYY with a two-digit Buddhist-era year.
+func buddhistPeriod(d time.Time) string {
+	return fmt.Sprintf("%02d%02d", int(d.Month()), (d.Year()+543)%100)
+}
+
+func digits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// padRight pads an already-encoded single-byte string to width bytes, truncating when longer.
+func padRight(s string, width int) string {
+	if len(s) > width {
+		return s[:width]
+	}
+	return s + strings.Repeat(" ", width-len(s))
+}
+
+func padLeft(s string, width int, fill byte) (string, error) {
+	if len(s) > width {
+		return "", fmt.Errorf("%q does not fit in %d characters", s, width)
+	}
+	return strings.Repeat(string(fill), width-len(s)) + s, nil
+}
+
+// fields keeps the first overflow while a record is put together, so Write can concatenate
+// fields and check once.
+type fields struct {
+	err error
+}
+
+func (f *fields) padLeft(name, s string, width int, fill byte) string {
+	out, err := padLeft(s, width, fill)
+	if err != nil && f.err == nil {
+		f.err = fmt.Errorf("%s: %w", name, err)
+	}
+	return out
+}
+
+func (f *fields) zeroNum(name string, v int64, width int) string {
+	return f.padLeft(name, fmt.Sprintf("%d", v), width, '0')
+}
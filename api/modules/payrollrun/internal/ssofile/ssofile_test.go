@@ -0,0 +1,155 @@
+package ssofile
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testFiling() Filing {
+	return Filing{
+		EmployerAccountNo: "12-3456789-0",
+		BranchNo:          "000001",
+		CompanyName:       "ACME CO",
+		PayDate:           time.Date(2026, 2, 5, 0, 0, 0, 0, time.UTC),
+		PeriodMonth:       time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		EmployeeRate:      0.05,
+		EmployerRate:      0.05,
+		Contributions: []Contribution{
+			{CitizenID: "1-1017-00123-45-6", TitleCode: "mr", FirstName: "Somchai", LastName: "Jaidee", Wage: 15000, EmployeeAmount: 750},
+			{CitizenID: "3100600456789", TitleCode: "MRS", FirstName: "Malee", LastName: "Sukjai", Wage: 17500, EmployeeAmount: 875},
+		},
+	}
+}
+
+func write(t *testing.T, f Filing) ([]string, error) {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf, f); err != nil {
+		return nil, err
+	}
+	out := buf.String()
+	if !strings.HasSuffix(out, "\r\n") {
+		t.Fatalf("file does not end with CRLF: %q", out)
+	}
+	return strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n"), nil
+}
+
+func pad(s string, width int) string { return s + strings.Repeat(" ", width-len(s)) }
+
+func TestWriteGolden(t *testing.T) {
+	want := []string{
+		"1" + "1234567890" + "000001" + "050269" + "0169" + pad("ACME CO", 45) +
+			"0500" + "000002" + "000000003250000" + "00000000325000" + "000000162500" + "000000162500",
+		"2" + "1101700123456" + "003" + pad("Somchai", 30) + pad("Jaidee", 35) +
+			"00000001500000" + "000000075000" + strings.Repeat(" ", 24),
+		"2" + "3100600456789" + "005" + pad("Malee", 30) + pad("Sukjai", 35) +
+			"00000001750000" + "000000087500" + strings.Repeat(" ", 24),
+	}
+	got, err := write(t, testFiling())
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Write() wrote %d lines, want %d:\n%s", len(got), len(want), strings.Join(got, "\n"))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("line %d\n got: %q\nwant: %q", i+1, got[i], want[i])
+		}
+	}
+	if len(got[0]) != 135 || len(got[1]) != 132 {
+		t.Errorf("record lengths = %d, %d; want 135, 132", len(got[0]), len(got[1]))
+	}
+}
+
+func TestWriteThaiNames(t *testing.T) {
+	f := testFiling()
+	f.Contributions = f.Contributions[:1]
+	f.Contributions[0].TitleCode = "dr"
+	f.Contributions[0].FirstName = "สมชาย"
+	f.Contributions[0].LastName = "ใจดีมากมากมากมากมากมากมากมากมากมากมากมาก" // 40 characters
+	got, err := write(t, f)
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	d := got[1]
+	if len(d) != 132 {
+		t.Fatalf("detail record is %d bytes, want 132", len(d))
+	}
+	if title := d[14:17]; title != "000" {
+		t.Errorf("unknown title code = %q, want 000", title)
+	}
+	if first := strings.TrimRight(d[17:47], " "); len(first) != 5 {
+		t.Errorf("first name is %d bytes, want 5 in TIS-620", len(first))
+	}
+	if last := d[47:82]; strings.HasSuffix(last, " ") {
+		t.Errorf("long last name was not cut to 35 bytes: %q", last)
+	}
+}
+
+func TestWriteRejectsOverflow(t *testing.T) {
+	f := testFiling()
+	f.EmployerRate = 0                          // keep the header totals within their fields
+	f.Contributions[0].Wage = 1_000_000_000_000 // 15 digits in satang, the field has 14
+	if _, err := write(t, f); err == nil || !strings.Contains(err.Error(), "wage of Somchai Jaidee") {
+		t.Fatalf("Write() error = %v, want wage overflow", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(*Filing)
+		wantErr string
+	}{
+		{"valid", func(*Filing) {}, ""},
+		{"short employer account", func(f *Filing) { f.EmployerAccountNo = "123456789" }, "10 digits"},
+		{"long branch", func(f *Filing) { f.BranchNo = "0000001" }, "6 digits"},
+		{"no contributions", func(f *Filing) { f.Contributions = nil }, "no insured employees"},
+		{"short citizen id", func(f *Filing) { f.Contributions[1].CitizenID = "310060045678" }, "Malee Sukjai"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := testFiling()
+			tt.change(&f)
+			err := Validate(f)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want none", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTotals(t *testing.T) {
+	f := testFiling()
+	f.Contributions[0].Wage = 15123
+	f.Contributions[0].EmployeeAmount = 756.15
+	got := f.Totals()
+	want := Totals{Employees: 2, WageSatang: 3262300, EmployeeSatang: 163115, EmployerSatang: 163115}
+	if got != want {
+		t.Errorf("Totals() = %+v, want %+v", got, want)
+	}
+	if got.ContributionSatang() != 326230 {
+		t.Errorf("ContributionSatang() = %d, want 326230", got.ContributionSatang())
+	}
+	if got := EmployerAmount(15123, 0.05); got != 756.15 {
+		t.Errorf("EmployerAmount() = %v, want 756.15", got)
+	}
+}
+
+func TestPadLeft(t *testing.T) {
+	if got, err := padLeft("123", 5, '0'); err != nil || got != "00123" {
+		t.Errorf("padLeft() = %q, %v; want 00123", got, err)
+	}
+	if _, err := padLeft("123456", 5, '0'); err == nil {
+		t.Error("padLeft() of a longer value returned no error")
+	}
+}
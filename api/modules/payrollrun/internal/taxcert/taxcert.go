@@ -0,0 +1,180 @@
+// Package taxcert renders the withholding tax certificate (หนังสือรับรองการหักภาษี ณ ที่จ่าย
+// ตามมาตรา 50 ทวิ) for salary income, one A4 page per employee per tax year.
+package taxcert
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+
+	"hrms/modules/payrollrun/internal/pdfdoc"
+	"hrms/modules/payrollrun/internal/repository"
+)
+
+const (
+	marginX   = 12.0
+	pageWidth = 210.0
+	contentW  = pageWidth - 2*marginX
+	rowH      = 6.0
+)
+
+// Document is the payer-level context shared by every certificate in a batch.
+type Document struct {
+	Org       repository.OrgProfile
+	Year      int
+	IssueDate time.Time
+}
+
+// Render adds one page per employee to pdf.
+func Render(pdf *gofpdf.Fpdf, doc Document, rows []repository.CertificateRow) error {
+	for i := range rows {
+		renderPage(pdf, doc, rows[i])
+		if err := pdf.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FileName is the download name for one certificate, e.g. 50tawi-2026-EMP001.pdf.
+func FileName(year int, row repository.CertificateRow) string {
+	return fmt.Sprintf("50tawi-%d-%s.pdf", year, sanitize(row.EmployeeNumber))
+}
+
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ' ' {
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+func renderPage(pdf *gofpdf.Fpdf, doc Document, row repository.CertificateRow) {
+	pdf.AddPage()
+	y := 12.0
+	pdf.SetXY(marginX, y)
+	pdf.SetFont(pdfdoc.Family, "B", 14)
+	pdf.CellFormat(contentW, 7, "หนังสือรับรองการหักภาษี ณ ที่จ่าย", "", 2, "C", false, 0, "")
+	pdf.SetFont(pdfdoc.Family, "", 10)
+	pdf.CellFormat(contentW, 5, "ตามมาตรา 50 ทวิ แห่งประมวลรัษฎากร", "", 2, "C", false, 0, "")
+	y = pdf.GetY() + 3
+
+	orgName := doc.Org.CompanyName
+	if orgName == "" {
+		orgName = "-"
+	}
+	y = party(pdf, y, "ผู้มีหน้าที่หักภาษี ณ ที่จ่าย", orgName, repository.Deref(doc.Org.TaxID), doc.Org.Address())
+	name := strings.TrimSpace(row.TitleName + row.FirstName + " " + row.LastName)
+	y = party(pdf, y+2, "ผู้ถูกหักภาษี ณ ที่จ่าย", name, row.CitizenID, "รหัสพนักงาน "+row.EmployeeNumber)
+
+	pdf.SetXY(marginX, y+2)
+	pdf.SetFont(pdfdoc.Family, "", 10)
+	pdf.CellFormat(contentW, rowH, "ลำดับที่ในแบบ  [X] ภ.ง.ด.1ก   [ ] ภ.ง.ด.1ก พิเศษ   [ ] ภ.ง.ด.2   [ ] ภ.ง.ด.3   [ ] ภ.ง.ด.53", "", 2, "L", false, 0, "")
+	y = incomeTable(pdf, doc, row, pdf.GetY()+2)
+
+	pdf.SetXY(marginX, y+2)
+	pdf.SetFont(pdfdoc.Family, "", 10)
+	pdf.CellFormat(40, rowH, "ภาษีที่หักนำส่ง (ตัวอักษร)", "", 0, "L", false, 0, "")
+	pdf.SetFont(pdfdoc.Family, "B", 10)
+	pdf.CellFormat(contentW-40, rowH, pdfdoc.BahtText(row.Tax), "B", 2, "L", false, 0, "")
+
+	pdf.SetFont(pdfdoc.Family, "", 10)
+	pdf.SetX(marginX)
+	pdf.CellFormat(contentW, rowH+1, fmt.Sprintf("เงินที่จ่ายเข้า  กองทุนสำรองเลี้ยงชีพ %s บาท    กองทุนประกันสังคม %s บาท",
+		pdfdoc.Money(row.PF), pdfdoc.Money(row.SSO)), "", 2, "L", false, 0, "")
+	pdf.SetX(marginX)
+	pdf.CellFormat(contentW, rowH, "ผู้จ่ายเงิน  [X] (1) หัก ณ ที่จ่าย   [ ] (2) ออกให้ตลอดไป   [ ] (3) ออกให้ครั้งเดียว   [ ] (4) อื่นๆ", "", 2, "L", false, 0, "")
+
+	signature(pdf, doc, pdf.GetY()+8)
+}
+
+// party draws a boxed payer/payee block with name, 13-digit id and a detail line.
+func party(pdf *gofpdf.Fpdf, y float64, title, name, taxID, detail string) float64 {
+	h := 22.0
+	pdf.Rect(marginX, y, contentW, h, "D")
+	pdf.SetXY(marginX+2, y+1)
+	pdf.SetFont(pdfdoc.Family, "B", 10)
+	pdf.CellFormat(contentW-70, 5, title, "", 0, "L", false, 0, "")
+	pdf.SetFont(pdfdoc.Family, "", 9)
+	pdf.CellFormat(28, 5, "เลขประจำตัวผู้เสียภาษี", "", 0, "R", false, 0, "")
+	pdf.SetFont(pdfdoc.Family, "B", 11)
+	pdf.CellFormat(38, 5, spacedID(taxID), "", 2, "R", false, 0, "")
+	pdf.SetXY(marginX+2, y+7)
+	pdf.SetFont(pdfdoc.Family, "", 10)
+	pdf.CellFormat(12, 6, "ชื่อ", "", 0, "L", false, 0, "")
+	pdf.CellFormat(contentW-16, 6, name, "B", 2, "L", false, 0, "")
+	pdf.SetXY(marginX+2, y+14)
+	pdf.CellFormat(12, 6, "ที่อยู่", "", 0, "L", false, 0, "")
+	pdf.CellFormat(contentW-16, 6, orDash(detail), "B", 2, "L", false, 0, "")
+	return y + h
+}
+
+func incomeTable(pdf *gofpdf.Fpdf, doc Document, row repository.CertificateRow, y float64) float64 {
+	typeW, yearW, amtW := contentW-26-34-34, 26.0, 34.0
+	pdf.SetFillColor(243, 244, 246)
+	pdf.SetFont(pdfdoc.Family, "B", 10)
+	pdf.SetXY(marginX, y)
+	pdf.CellFormat(typeW, 8, "ประเภทเงินได้พึงประเมินที่จ่าย", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(yearW, 8, "ปีภาษีที่จ่าย", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(amtW, 8, "จำนวนเงินที่จ่าย", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(amtW, 8, "ภาษีที่หักและนำส่งไว้", "1", 2, "C", true, 0, "")
+
+	pdf.SetFont(pdfdoc.Family, "", 10)
+	pdf.SetX(marginX)
+	pdf.CellFormat(typeW, 8, "1. เงินเดือน ค่าจ้าง เบี้ยเลี้ยง โบนัส ฯลฯ ตามมาตรา 40 (1)", "LR", 0, "L", false, 0, "")
+	pdf.CellFormat(yearW, 8, fmt.Sprintf("%d", doc.Year+543), "LR", 0, "C", false, 0, "")
+	pdf.CellFormat(amtW, 8, pdfdoc.Money(row.Income), "LR", 0, "R", false, 0, "")
+	pdf.CellFormat(amtW, 8, pdfdoc.Money(row.Tax), "LR", 2, "R", false, 0, "")
+	for _, label := range []string{
+		"2. ค่าธรรมเนียม ค่านายหน้า ฯลฯ ตามมาตรา 40 (2)",
+		"3. ค่าแห่งลิขสิทธิ์ ฯลฯ ตามมาตรา 40 (3)",
+		"4. ดอกเบี้ย เงินปันผล ฯลฯ ตามมาตรา 40 (4)",
+		"5. อื่นๆ",
+	} {
+		pdf.SetX(marginX)
+		pdf.SetTextColor(156, 163, 175)
+		pdf.CellFormat(typeW, rowH, label, "LR", 0, "L", false, 0, "")
+		pdf.CellFormat(yearW, rowH, "", "LR", 0, "C", false, 0, "")
+		pdf.CellFormat(amtW, rowH, "", "LR", 0, "R", false, 0, "")
+		pdf.CellFormat(amtW, rowH, "", "LR", 2, "R", false, 0, "")
+	}
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont(pdfdoc.Family, "B", 10)
+	pdf.SetX(marginX)
+	pdf.CellFormat(typeW+yearW, 8, "รวมเงินที่จ่ายและภาษีที่หักนำส่ง", "1", 0, "R", false, 0, "")
+	pdf.CellFormat(amtW, 8, pdfdoc.Money(row.Income), "1", 0, "R", false, 0, "")
+	pdf.CellFormat(amtW, 8, pdfdoc.Money(row.Tax), "1", 2, "R", false, 0, "")
+	return pdf.GetY()
+}
+
+func signature(pdf *gofpdf.Fpdf, doc Document, y float64) {
+	pdf.SetFont(pdfdoc.Family, "", 9.5)
+	pdf.SetXY(marginX, y)
+	pdf.MultiCell(contentW, 5, "ขอรับรองว่าข้อความและตัวเลขดังกล่าวข้างต้นถูกต้องตรงกับความจริงทุกประการ", "", "C", false)
+	x := pageWidth/2 + 10
+	sigY := pdf.GetY() + 14
+	pdf.Line(x, sigY, x+60, sigY)
+	pdf.SetXY(x, sigY+1)
+	pdf.CellFormat(60, 5, "ผู้มีหน้าที่หักภาษี ณ ที่จ่าย", "", 2, "C", false, 0, "")
+	pdf.SetX(x)
+	pdf.CellFormat(60, 5, "วันที่ออกหนังสือรับรอง "+pdfdoc.ThaiDate(doc.IssueDate), "", 2, "C", false, 0, "")
+}
+
+// spacedID formats a 13-digit id as X-XXXX-XXXXX-XX-X; anything else is printed as given.
+func spacedID(id string) string {
+	d := strings.TrimSpace(id)
+	if len(d) != 13 {
+		return orDash(d)
+	}
+	return d[0:1] + "-" + d[1:5] + "-" + d[5:10] + "-" + d[10:12] + "-" + d[12:13]
+}
+
+func orDash(s string) string {
+	if strings.TrimSpace(s) == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,149 @@
+// Package taxfile writes the Revenue Department withholding-tax returns for salaries:
+// ภ.ง.ด.1 (monthly) and ภ.ง.ด.1ก (annual summary).
+//
+// Files are pipe-delimited TIS-620 text with CRLF line endings, in the column order of the
+// RD Prep import: one H record for the withholding agent, then one D record per payee.
+// Amounts are baht with two decimals, dates are dd/mm/yyyy in the Buddhist era.
+package taxfile
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"hrms/modules/payrollrun/internal/thaienc"
+)
+
+const (
+	FormPND1    = "PND1"
+	FormPND1Kor = "PND1K"
+
+	// incomeType401 is section 40(1) income: salary, wages, bonus.
+	incomeType401 = "1"
+	// conditionWithheld means tax was deducted from the payee (หัก ณ ที่จ่าย).
+	conditionWithheld = "1"
+	// headOffice is the RD branch number used when the agent files for the whole company.
+	headOffice = "00000"
+)
+
+type Payee struct {
+	CitizenID string
+	TitleName string
+	FirstName string
+	LastName  string
+	PayDate   time.Time // PND1 only
+	Income    float64
+	Tax       float64
+}
+
+// Return is one filing: a month (PND1) or a tax year (PND1Kor).
+type Return struct {
+	Form        string
+	TaxID       string
+	BranchNo    string
+	AgentName   string
+	PeriodMonth time.Time // PND1: first day of the month
+	TaxYear     int       // PND1Kor: Gregorian year
+	Payees      []Payee
+}
+
+type Totals struct {
+	Payees int
+	Income float64
+	Tax    float64
+}
+
+// Totals sums in satang to avoid float drift on long payee lists.
+func (r Return) Totals() Totals {
+	var income, tax int64
+	for _, p := range r.Payees {
+		income += satang(p.Income)
+		tax += satang(p.Tax)
+	}
+	return Totals{Payees: len(r.Payees), Income: float64(income) / 100, Tax: float64(tax) / 100}
+}
+
+// Validate checks what the RD import rejects outright.
+func Validate(r Return) error {
+	if len(digits(r.TaxID)) != 13 {
+		return fmt.Errorf("withholding agent tax id must be 13 digits (set it on the payroll org profile)")
+	}
+	if len(r.Payees) == 0 {
+		return fmt.Errorf("no payees to report")
+	}
+	for _, p := range r.Payees {
+		if len(digits(p.CitizenID)) != 13 {
+			return fmt.Errorf("citizen id of %s %s must be 13 digits", p.FirstName, p.LastName)
+		}
+	}
+	return nil
+}
+
+// Write renders the return. Callers should Validate first.
+func Write(w io.Writer, r Return) error {
+	t := r.Totals()
+	branch := digits(r.BranchNo)
+	if branch == "" {
+		branch = headOffice
+	}
+	period := ""
+	switch r.Form {
+	case FormPND1:
+		period = fmt.Sprintf("%02d/%04d", int(r.PeriodMonth.Month()), r.PeriodMonth.Year()+543)
+	case FormPND1Kor:
+		period = fmt.Sprintf("%04d", r.TaxYear+543)
+	default:
+		return fmt.Errorf("unknown form %q", r.Form)
+	}
+
+	lines := []string{join("H", r.Form, digits(r.TaxID), branch, text(r.AgentName), period,
+		fmt.Sprintf("%d", t.Payees), money(t.Income), money(t.Tax))}
+	for i, p := range r.Payees {
+		fields := []string{"D", fmt.Sprintf("%d", i+1), digits(p.CitizenID),
+			text(p.TitleName), text(p.FirstName), text(p.LastName)}
+		if r.Form == FormPND1 {
+			fields = append(fields, buddhistDate(p.PayDate))
+		}
+		fields = append(fields, incomeType401, money(p.Income), money(p.Tax), conditionWithheld)
+		lines = append(lines, join(fields...))
+	}
+	for _, l := range lines {
+		if _, err := io.WriteString(w, thaienc.TIS620(l)+"\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func join(fields ...string) string {
+	return strings.Join(fields, "|")
+}
+
+// text strips the delimiter and line breaks out of free text.
+func text(s string) string {
+	return strings.TrimSpace(strings.NewReplacer("|", " ", "\r", " ", "\n", " ").Replace(s))
+}
+
+func money(v float64) string {
+	return fmt.Sprintf("%.2f", float64(satang(v))/100)
+}
+
+func satang(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func buddhistDate(d time.Time) string {
+	return fmt.Sprintf("%02d/%02d/%04d", d.Day(), int(d.Month()), d.Year()+543)
+}
+
+func digits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
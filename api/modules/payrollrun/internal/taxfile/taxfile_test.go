@@ -0,0 +1,145 @@
+package taxfile
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testReturn(form string) Return {
+	return Return{
+		Form:        form,
+		TaxID:       "0-1055-12345-67-8",
+		AgentName:   "ACME CO",
+		PeriodMonth: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		TaxYear:     2025,
+		Payees: []Payee{
+			{CitizenID: "1-1017-00123-45-6", TitleName: "Mr", FirstName: "Somchai", LastName: "Jaidee",
+				PayDate: time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC), Income: 50000, Tax: 1250.5},
+			{CitizenID: "3100600456789", TitleName: "Mrs", FirstName: "Malee", LastName: "Sukjai",
+				PayDate: time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC), Income: 32500.256, Tax: 0},
+		},
+	}
+}
+
+func write(t *testing.T, r Return) []string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf, r); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	out := buf.String()
+	if !strings.HasSuffix(out, "\r\n") {
+		t.Fatalf("file does not end with CRLF: %q", out)
+	}
+	return strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+}
+
+func TestWriteGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		r    Return
+		want []string
+	}{
+		{
+			name: "PND1",
+			r:    testReturn(FormPND1),
+			want: []string{
+				"H|PND1|0105512345678|00000|ACME CO|01/2569|2|82500.26|1250.50",
+				"D|1|1101700123456|Mr|Somchai|Jaidee|31/01/2569|1|50000.00|1250.50|1",
+				"D|2|3100600456789|Mrs|Malee|Sukjai|31/01/2569|1|32500.26|0.00|1",
+			},
+		},
+		{
+			name: "PND1K",
+			r: func() Return {
+				r := testReturn(FormPND1Kor)
+				r.BranchNo = "00001"
+				return r
+			}(),
+			want: []string{
+				"H|PND1K|0105512345678|00001|ACME CO|2568|2|82500.26|1250.50",
+				"D|1|1101700123456|Mr|Somchai|Jaidee|1|50000.00|1250.50|1",
+				"D|2|3100600456789|Mrs|Malee|Sukjai|1|32500.26|0.00|1",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := write(t, tt.r)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Write() wrote %d lines, want %d:\n%s", len(got), len(tt.want), strings.Join(got, "\n"))
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("line %d\n got: %q\nwant: %q", i+1, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWriteSanitisesText(t *testing.T) {
+	r := testReturn(FormPND1)
+	r.AgentName = " ACME | CO\r\n"
+	r.Payees = r.Payees[:1]
+	r.Payees[0].LastName = "Jai|dee\n"
+	got := write(t, r)
+	if h := strings.Split(got[0], "|"); len(h) != 9 || h[4] != "ACME   CO" {
+		t.Errorf("header = %q, want 9 fields with agent %q", got[0], "ACME   CO")
+	}
+	if d := strings.Split(got[1], "|"); len(d) != 11 || d[5] != "Jai dee" {
+		t.Errorf("detail = %q, want 11 fields with last name %q", got[1], "Jai dee")
+	}
+}
+
+func TestWriteUnknownForm(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, testReturn("PND3")); err == nil || !strings.Contains(err.Error(), "unknown form") {
+		t.Fatalf("Write() error = %v, want unknown form", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Write() wrote %q for an unknown form", buf.String())
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(*Return)
+		wantErr string
+	}{
+		{"valid", func(*Return) {}, ""},
+		{"missing tax id", func(r *Return) { r.TaxID = "" }, "tax id must be 13 digits"},
+		{"no payees", func(r *Return) { r.Payees = nil }, "no payees"},
+		{"short citizen id", func(r *Return) { r.Payees[1].CitizenID = "310060045678" }, "Malee Sukjai"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := testReturn(FormPND1)
+			tt.change(&r)
+			err := Validate(r)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v, want none", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTotals(t *testing.T) {
+	r := testReturn(FormPND1)
+	for i := 0; i < 1000; i++ {
+		r.Payees = append(r.Payees, Payee{CitizenID: "3100600456789", Income: 0.1, Tax: 0.01})
+	}
+	want := Totals{Payees: 1002, Income: 82600.26, Tax: 1260.5}
+	if got := r.Totals(); got != want {
+		t.Errorf("Totals() = %+v, want %+v", got, want)
+	}
+}
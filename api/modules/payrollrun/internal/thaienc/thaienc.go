@@ -0,0 +1,26 @@
+// Package thaienc converts text for the government and bank upload files, which expect
+// TIS-620 (Windows-874) rather than UTF-8.
+package thaienc
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// TIS620 encodes s one byte per character, which is what keeps fixed-width columns aligned.
+// Unmappable runes become '?'.
+func TIS620(s string) string {
+	enc := charmap.Windows874.NewEncoder()
+	var b strings.Builder
+	for _, r := range s {
+		out, err := enc.String(string(r))
+		if err != nil || !utf8.ValidRune(r) {
+			b.WriteByte('?')
+			continue
+		}
+		b.WriteString(out)
+	}
+	return b.String()
+}
@@ -0,0 +1,94 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+)
+
+func TestColumn(t *testing.T) {
+	for i, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 51: "AZ", 52: "BA", 701: "ZZ", 702: "AAA"} {
+		if got := column(i); got != want {
+			t.Errorf("column(%d) = %q, want %q", i, got, want)
+		}
+	}
+}
+
+func TestWriterProducesReadableSheet(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, "Payroll <March>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow(true, "Name", "Net Pay"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow(false, "Tom & Jerry", 1234.5, nil, 7); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteRow(false, struct{}{}); err == nil {
+		t.Error("WriteRow() accepted an unsupported cell type")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("output is not a zip: %v", err)
+	}
+	var sheet struct {
+		Rows []struct {
+			R     int `xml:"r,attr"`
+			Cells []struct {
+				Ref    string `xml:"r,attr"`
+				Style  int    `xml:"s,attr"`
+				Value  string `xml:"v"`
+				Inline string `xml:"is>t"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// every part must be well-formed, including the escaped sheet name
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			err = xml.Unmarshal(body, &sheet)
+		} else {
+			err = xml.Unmarshal(body, new(struct{}))
+		}
+		if err != nil {
+			t.Errorf("%s is not valid XML: %v", f.Name, err)
+		}
+	}
+
+	// the rejected row is not written
+	if len(sheet.Rows) != 2 {
+		t.Fatalf("sheet has %d rows, want 2", len(sheet.Rows))
+	}
+	header, row := sheet.Rows[0], sheet.Rows[1]
+	if len(header.Cells) != 2 || header.Cells[0].Style != styleBold || header.Cells[1].Inline != "Net Pay" {
+		t.Errorf("header = %+v", header)
+	}
+	if len(row.Cells) != 3 {
+		t.Fatalf("row 2 has %d cells, want 3 (the nil cell is skipped)", len(row.Cells))
+	}
+	if c := row.Cells[0]; c.Ref != "A2" || c.Inline != "Tom & Jerry" {
+		t.Errorf("A2 = %+v, want the unescaped text back", c)
+	}
+	if c := row.Cells[1]; c.Ref != "B2" || c.Value != "1234.5" || c.Style != styleNumber {
+		t.Errorf("B2 = %+v, want number 1234.5", c)
+	}
+	if c := row.Cells[2]; c.Ref != "D2" || c.Value != "7" {
+		t.Errorf("D2 = %+v, want 7", c)
+	}
+}
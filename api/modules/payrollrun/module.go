@@ -1,20 +1,44 @@
 package payrollrun
 
 import (
+	"hrms/modules/payrollrun/internal/feature/approve"
+	"hrms/modules/payrollrun/internal/feature/bankexport"
 	"hrms/modules/payrollrun/internal/feature/create"
 	"hrms/modules/payrollrun/internal/feature/delete"
 	"hrms/modules/payrollrun/internal/feature/get"
+	glaccountslist "hrms/modules/payrollrun/internal/feature/glaccounts/list"
+	glaccountsupdate "hrms/modules/payrollrun/internal/feature/glaccounts/update"
+	itemsadd "hrms/modules/payrollrun/internal/feature/items/add"
+	itemsexport "hrms/modules/payrollrun/internal/feature/items/export"
 	itemsget "hrms/modules/payrollrun/internal/feature/items/get"
 	itemslist "hrms/modules/payrollrun/internal/feature/items/list"
+	itemsremove "hrms/modules/payrollrun/internal/feature/items/remove"
+	itemssettlement "hrms/modules/payrollrun/internal/feature/items/settlement"
 	itemsupdate "hrms/modules/payrollrun/internal/feature/items/update"
+	"hrms/modules/payrollrun/internal/feature/journal"
 	"hrms/modules/payrollrun/internal/feature/list"
+	payslipsbundle "hrms/modules/payrollrun/internal/feature/payslips/bundle"
+	payslipsitem "hrms/modules/payrollrun/internal/feature/payslips/item"
+	"hrms/modules/payrollrun/internal/feature/preview"
+	"hrms/modules/payrollrun/internal/feature/reject"
+	"hrms/modules/payrollrun/internal/feature/reverse"
+	"hrms/modules/payrollrun/internal/feature/simulate"
+	"hrms/modules/payrollrun/internal/feature/ssoexport"
+	"hrms/modules/payrollrun/internal/feature/submit"
+	taxcertbundle "hrms/modules/payrollrun/internal/feature/taxcertificates/bundle"
+	taxcertemployee "hrms/modules/payrollrun/internal/feature/taxcertificates/employee"
+	"hrms/modules/payrollrun/internal/feature/taxreport"
 	"hrms/modules/payrollrun/internal/feature/update"
+	"hrms/modules/payrollrun/internal/feature/variance"
+	"hrms/modules/payrollrun/internal/feature/withdraw"
+	"hrms/modules/payrollrun/internal/pdfdoc"
 	"hrms/modules/payrollrun/internal/repository"
 	"hrms/shared/common/eventbus"
 	"hrms/shared/common/jwt"
 	"hrms/shared/common/mediator"
 	"hrms/shared/common/middleware"
 	"hrms/shared/common/module"
+	"hrms/shared/contracts"
 
 	"github.com/gofiber/fiber/v3"
 )
@@ -24,14 +48,17 @@ type Module struct {
 	repo     repository.Repository
 	tokenSvc *jwt.TokenService
 	eb       eventbus.EventBus
+	fonts    *pdfdoc.Fonts
 }
 
-func NewModule(ctx *module.ModuleContext, tokenSvc *jwt.TokenService) *Module {
+// NewModule wires the payroll run module; pdfFontDir holds the Thai fonts used for payslip and tax certificate PDFs.
+func NewModule(ctx *module.ModuleContext, tokenSvc *jwt.TokenService, pdfFontDir string) *Module {
 	repo := repository.NewRepository(ctx.DBCtx)
 	return &Module{
 		ctx:      ctx,
 		repo:     repo,
 		tokenSvc: tokenSvc,
+		fonts:    pdfdoc.NewFonts(pdfFontDir),
 	}
 }
 
@@ -44,23 +71,74 @@ func (m *Module) Init(eb eventbus.EventBus) error {
 	mediator.Register[*create.Command, *create.Response](create.NewHandler(m.repo, m.ctx.Transactor, m.eb))
 	mediator.Register[*update.Command, *update.Response](update.NewHandler(m.repo, m.ctx.Transactor, m.eb))
 	mediator.Register[*delete.Command, mediator.NoResponse](delete.NewHandler(m.repo, m.eb))
+	mediator.Register[*submit.Command, *submit.Response](submit.NewHandler(m.repo, m.ctx.Transactor, m.eb))
+	mediator.Register[*approve.Command, *approve.Response](approve.NewHandler(m.repo, m.ctx.Transactor, m.eb))
+	mediator.Register[*reject.Command, *reject.Response](reject.NewHandler(m.repo, m.ctx.Transactor, m.eb))
+	mediator.Register[*withdraw.Command, *withdraw.Response](withdraw.NewHandler(m.repo, m.ctx.Transactor, m.eb))
+	mediator.Register[*reverse.Command, *reverse.Response](reverse.NewHandler(m.repo, m.ctx.Transactor, m.eb))
+	mediator.Register[*preview.Query, *preview.Response](preview.NewHandler(m.repo))
+	mediator.Register[*simulate.Command, *simulate.Response](simulate.NewHandler(m.repo))
+	mediator.Register[*variance.Query, *variance.Response](variance.NewHandler(m.repo))
 	mediator.Register[*itemslist.ListQuery, *itemslist.ListResponse](itemslist.NewListHandler(m.repo))
 	mediator.Register[*itemsupdate.UpdateCommand, *itemsupdate.UpdateResponse](itemsupdate.NewUpdateHandler(m.repo, m.ctx.Transactor, m.eb))
+	mediator.Register[*itemsadd.Command, *itemsadd.Response](itemsadd.NewHandler(m.repo, m.ctx.Transactor, m.eb))
+	mediator.Register[*itemsremove.Command, mediator.NoResponse](itemsremove.NewHandler(m.repo, m.eb))
+	mediator.Register[*contracts.PostSettlementCommand, *contracts.PostSettlementResponse](itemssettlement.NewHandler(m.repo))
 	mediator.Register[*itemsget.GetQuery, *itemsget.GetResponse](itemsget.NewGetHandler(m.repo))
+	mediator.Register[*itemsexport.Query, *itemsexport.Response](itemsexport.NewHandler(m.repo))
+	mediator.Register[*payslipsitem.Query, *payslipsitem.Response](payslipsitem.NewHandler(m.repo, m.fonts))
+	mediator.Register[*bankexport.Query, *bankexport.Response](bankexport.NewHandler(m.repo))
+	mediator.Register[*payslipsbundle.Query, *payslipsbundle.Response](payslipsbundle.NewHandler(m.repo, m.fonts))
+	mediator.Register[*journal.Query, *journal.Response](journal.NewHandler(m.repo))
+	mediator.Register[*glaccountslist.Query, *glaccountslist.Response](glaccountslist.NewHandler(m.repo))
+	mediator.Register[*glaccountsupdate.Command, *glaccountsupdate.Response](glaccountsupdate.NewHandler(m.repo, m.ctx.Transactor, m.eb))
+	mediator.Register[*ssoexport.Query, *ssoexport.Response](ssoexport.NewHandler(m.repo))
+	mediator.Register[*ssoexport.SummaryQuery, *ssoexport.SummaryResponse](ssoexport.NewSummaryHandler(m.repo))
+	mediator.Register[*taxreport.MonthlyQuery, *taxreport.Response](taxreport.NewMonthlyHandler(m.repo))
+	mediator.Register[*taxreport.AnnualQuery, *taxreport.Response](taxreport.NewAnnualHandler(m.repo))
+	mediator.Register[*taxcertbundle.Query, *taxcertbundle.Response](taxcertbundle.NewHandler(m.repo, m.fonts))
+	mediator.Register[*taxcertemployee.Query, *taxcertemployee.Response](taxcertemployee.NewHandler(m.repo, m.fonts))
 	return nil
 }
 
 func (m *Module) RegisterRoutes(r fiber.Router) {
 	runGroup := r.Group("/payroll-runs", middleware.Auth(m.tokenSvc), middleware.TenantMiddleware(), middleware.RequireRoles("admin", "hr"))
 	list.NewEndpoint(runGroup)
+	// preview before get so "/preview" is not taken as a run id
+	preview.NewEndpoint(runGroup)
 	create.NewEndpoint(runGroup)
+	simulate.NewEndpoint(runGroup)
 	get.NewEndpoint(runGroup)
 	update.NewEndpoint(runGroup)
+	variance.NewEndpoint(runGroup)
+	// approval workflow: who may decide is checked against the run's approval chain
+	submit.NewEndpoint(runGroup)
+	approve.NewEndpoint(runGroup)
+	reject.NewEndpoint(runGroup)
+	withdraw.NewEndpoint(runGroup)
 	// delete run = admin only
 	delete.NewEndpoint(runGroup.Group("", middleware.RequireRoles("admin")))
+	reverse.NewEndpoint(runGroup.Group("", middleware.RequireRoles("admin")))
 
 	itemslist.NewEndpoint(runGroup)
+	itemsexport.NewEndpoint(runGroup)
+	itemsadd.NewEndpoint(runGroup)
+	itemsremove.NewEndpoint(runGroup)
+	payslipsbundle.NewEndpoint(runGroup)
+	bankexport.NewEndpoint(runGroup)
+	ssoexport.NewEndpoint(runGroup)
+	ssoexport.NewSummaryEndpoint(runGroup)
+	journal.NewEndpoint(runGroup)
 	itemGroup := r.Group("/payroll-items", middleware.Auth(m.tokenSvc), middleware.TenantMiddleware(), middleware.RequireRoles("admin", "hr"))
 	itemsget.NewEndpoint(itemGroup)
 	itemsupdate.NewEndpoint(itemGroup)
+	payslipsitem.NewEndpoint(itemGroup)
+	glGroup := r.Group("/gl-accounts", middleware.Auth(m.tokenSvc), middleware.TenantMiddleware(), middleware.RequireRoles("admin", "hr"))
+	glaccountslist.NewEndpoint(glGroup)
+	glaccountsupdate.NewEndpoint(glGroup.Group("", middleware.RequireRoles("admin")))
+	taxGroup := r.Group("/tax-reports", middleware.Auth(m.tokenSvc), middleware.TenantMiddleware(), middleware.RequireRoles("admin", "hr"))
+	taxreport.NewMonthlyEndpoint(taxGroup)
+	taxreport.NewAnnualEndpoint(taxGroup)
+	taxcertbundle.NewEndpoint(taxGroup)
+	taxcertemployee.NewEndpoint(taxGroup)
 }
@@ -9,16 +9,17 @@ import (
 )
 
 type Item struct {
-	ID           uuid.UUID `json:"id"`
-	EmployeeID   uuid.UUID `json:"employeeId"`
-	EmployeeName string    `json:"employeeName"`
-	EmployeeCode string    `json:"employeeCode"`
-	Amount       float64   `json:"amount"`
-	AdvanceDate  time.Time `json:"advanceDate"`
-	PayrollMonth time.Time `json:"payrollMonthDate"`
-	Status       string    `json:"status"`
-	CreatedAt    time.Time `json:"createdAt"`
-	UpdatedAt    time.Time `json:"updatedAt"`
+	ID           uuid.UUID  `json:"id"`
+	EmployeeID   uuid.UUID  `json:"employeeId"`
+	EmployeeName string     `json:"employeeName"`
+	EmployeeCode string     `json:"employeeCode"`
+	Amount       float64    `json:"amount"`
+	AdvanceDate  time.Time  `json:"advanceDate"`
+	PayrollMonth time.Time  `json:"payrollMonthDate"`
+	Status       string     `json:"status"`
+	PayrollRunID *uuid.UUID `json:"payrollRunId,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
 }
 
 type Meta struct {
@@ -37,6 +38,7 @@ func FromRecord(r repository.Record) Item {
 		AdvanceDate:  r.AdvanceDate,
 		PayrollMonth: r.PayrollMonth,
 		Status:       r.Status,
+		PayrollRunID: r.PayrollRunID,
 		CreatedAt:    r.CreatedAt,
 		UpdatedAt:    r.UpdatedAt,
 	}
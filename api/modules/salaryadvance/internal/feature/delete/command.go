@@ -60,6 +60,9 @@ func (h *Handler) Handle(ctx context.Context, cmd *Command) (mediator.NoResponse
 	if rec.Status != "pending" {
 		return mediator.NoResponse{}, errs.BadRequest("cannot delete processed salary advance")
 	}
+	if rec.PayrollRunID != nil {
+		return mediator.NoResponse{}, errs.BadRequest("cannot delete salary advance recovered by a final settlement")
+	}
 	if err := h.repo.SoftDelete(ctx, tenant, cmd.ID, user.ID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return mediator.NoResponse{}, errs.NotFound("salary advance not found")
@@ -84,6 +84,9 @@ func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
 	if curr.Status != "pending" {
 		return nil, errs.BadRequest("cannot update processed salary advance")
 	}
+	if curr.PayrollRunID != nil {
+		return nil, errs.BadRequest("cannot update salary advance recovered by a final settlement")
+	}
 
 	rec := repository.Record{
 		AdvanceDate:  advDate,
@@ -32,6 +32,7 @@ type Record struct {
 	AdvanceDate  time.Time  `db:"advance_date"`
 	Amount       float64    `db:"amount"`
 	Status       string     `db:"status"`
+	PayrollRunID *uuid.UUID `db:"payroll_run_id"`
 	CreatedAt    time.Time  `db:"created_at"`
 	CreatedBy    uuid.UUID  `db:"created_by"`
 	UpdatedAt    time.Time  `db:"updated_at"`
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
@@ -17,7 +18,9 @@ import (
 	"hrms/shared/common/eventbus"
 	"hrms/shared/common/logger"
 	"hrms/shared/common/mediator"
+	"hrms/shared/common/storage/sqldb/transactor"
 	"hrms/shared/common/validator"
+	"hrms/shared/contracts"
 	"hrms/shared/events"
 )
 
@@ -28,21 +31,25 @@ type Command struct {
 	StartDate *time.Time
 	EndDate   *time.Time
 	Status    *string `validate:"omitempty,oneof=approved rejected"`
+	Comment   string  `validate:"max=1000"`
 }
 
 type Response struct {
 	dto.Cycle
+	Approval *contracts.ApprovalRequestDTO `json:"approval,omitempty"`
+	Message  string                        `json:"message,omitempty"`
 }
 
 type Handler struct {
 	repo repository.Repository
+	tx   transactor.Transactor
 	eb   eventbus.EventBus
 }
 
 var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
 
-func NewHandler(repo repository.Repository, eb eventbus.EventBus) *Handler {
-	return &Handler{repo: repo, eb: eb}
+func NewHandler(repo repository.Repository, tx transactor.Transactor, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, tx: tx, eb: eb}
 }
 
 func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
@@ -65,9 +72,6 @@ func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
 	}
 
 	if cmd.Status != nil {
-		if user.Role == "hr" {
-			return nil, errs.Forbidden("HR is not allowed to change status")
-		}
 		status := strings.TrimSpace(strings.ToLower(*cmd.Status))
 		cmd.Status = &status
 	}
@@ -76,8 +80,38 @@ func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
 		return nil, errs.BadRequest("periodEndDate must be on or after periodStartDate")
 	}
 
-	updated, err := h.repo.UpdateCycle(ctx, tenant, cmd.ID, cmd.StartDate, cmd.EndDate, cmd.Status, user.ID)
+	var (
+		updated  *repository.Cycle
+		decision *contracts.DecideApprovalResponse
+	)
+	err := h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
+		var err error
+		if cmd.Status != nil {
+			decision, err = h.decide(ctxTx, tenant, user, cmd)
+			if err != nil {
+				return err
+			}
+			if decision == nil || !decision.Required {
+				if user.Role == "hr" {
+					return errs.Forbidden("HR is not allowed to change status")
+				}
+			} else if !decision.Final {
+				// step approved, the cycle stays pending until the last step
+				cmd.Status = nil
+			}
+		}
+		if cmd.StartDate == nil && cmd.EndDate == nil && cmd.Status == nil {
+			updated, _, err = h.repo.Get(ctxTx, tenant, cmd.ID)
+			return err
+		}
+		updated, err = h.repo.UpdateCycle(ctxTx, tenant, cmd.ID, cmd.StartDate, cmd.EndDate, cmd.Status, user.ID)
+		return err
+	})
 	if err != nil {
+		var appErr *errs.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, errs.NotFound("cycle not found")
 		}
@@ -99,6 +133,12 @@ func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
 	if cmd.EndDate != nil {
 		details["end_date"] = cmd.EndDate.Format("2006-01-02")
 	}
+	if decision != nil && decision.Request != nil {
+		details["approval_request_id"] = decision.Request.ID.String()
+		if !decision.Final {
+			details["approved_step"] = decision.Request.CurrentStep - 1
+		}
+	}
 
 	h.eb.Publish(events.LogEvent{
 		ActorID:    user.ID,
@@ -111,7 +151,38 @@ func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
 		Timestamp:  time.Now(),
 	})
 
-	return &Response{Cycle: dto.FromCycle(*updated)}, nil
+	resp := &Response{Cycle: dto.FromCycle(*updated)}
+	if decision != nil && decision.Required {
+		resp.Approval = decision.Request
+		if !decision.Final {
+			resp.Message = fmt.Sprintf("Step %d approved. Waiting for step %d of %d.",
+				decision.Request.CurrentStep-1, decision.Request.CurrentStep, decision.Request.StepCount)
+		}
+	}
+	return resp, nil
+}
+
+// decide hands an approve/reject of a pending cycle to its approval chain. It returns nil when
+// the cycle is no longer pending; the status change is then left to the cycle's own guard.
+func (h *Handler) decide(ctx context.Context, tenant contextx.TenantInfo, user contextx.UserInfo, cmd *Command) (*contracts.DecideApprovalResponse, error) {
+	subject, err := h.repo.GetApprovalSubject(ctx, tenant, cmd.ID)
+	if err != nil {
+		return nil, err
+	}
+	if subject.Status != "pending" {
+		return nil, nil
+	}
+	return mediator.Send[*contracts.DecideApprovalCommand, *contracts.DecideApprovalResponse](ctx, &contracts.DecideApprovalCommand{
+		CompanyID:  subject.CompanyID,
+		BranchID:   &subject.BranchID,
+		DocType:    contracts.ApprovalDocSalaryRaiseCycle,
+		DocID:      cmd.ID,
+		PreparedBy: subject.CreatedBy,
+		ActorID:    user.ID,
+		ActorRole:  user.Role,
+		Approve:    *cmd.Status == "approved",
+		Comment:    cmd.Comment,
+	})
 }
 
 func parseDatePtr(in *string) (*time.Time, error) {
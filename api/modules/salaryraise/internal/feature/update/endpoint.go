@@ -13,10 +13,11 @@ type RequestBody struct {
 	PeriodStart *string `json:"periodStartDate"`
 	PeriodEnd   *string `json:"periodEndDate"`
 	Status      *string `json:"status"`
+	Comment     string  `json:"comment"`
 }
 
 // @Summary Update salary raise cycle
-// @Description แก้ไขช่วงเวลา หรือเปลี่ยนสถานะ (Approve/Reject)
+// @Description แก้ไขช่วงเวลา หรือเปลี่ยนสถานะ (Approve/Reject) ถ้าตั้งลำดับการอนุมัติไว้ จะตัดสินทีละขั้นตามลำดับ
 // @Tags Salary Raise
 // @Accept json
 // @Produce json
@@ -56,6 +57,7 @@ func NewEndpoint(router fiber.Router) {
 			StartDate: start,
 			EndDate:   end,
 			Status:    req.Status,
+			Comment:   req.Comment,
 		})
 		if err != nil {
 			return err
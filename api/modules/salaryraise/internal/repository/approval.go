@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"hrms/shared/common/contextx"
+)
+
+// ApprovalSubject is what the approval workflow needs to know about a cycle.
+type ApprovalSubject struct {
+	CompanyID uuid.UUID `db:"company_id"`
+	BranchID  uuid.UUID `db:"branch_id"`
+	Status    string    `db:"status"`
+	CreatedBy uuid.UUID `db:"created_by"`
+}
+
+func (r Repository) GetApprovalSubject(ctx context.Context, tenant contextx.TenantInfo, id uuid.UUID) (*ApprovalSubject, error) {
+	db := r.dbCtx(ctx)
+	q := `SELECT company_id, branch_id, status, created_by FROM salary_raise_cycle WHERE id=$1 AND company_id=$2 AND deleted_at IS NULL`
+	args := []interface{}{id, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		q += ` AND branch_id=$3`
+		args = append(args, tenant.BranchID)
+	}
+	var s ApprovalSubject
+	if err := db.GetContext(ctx, &s, q, args...); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -43,7 +43,7 @@ func (m *Module) Init(eb eventbus.EventBus) error {
 	mediator.Register[*list.Query, *list.Response](list.NewHandler(m.repo))
 	mediator.Register[*get.Query, *get.Response](get.NewHandler(m.repo))
 	mediator.Register[*create.Command, *create.Response](create.NewHandler(m.repo, m.ctx.Transactor, eb))
-	mediator.Register[*update.Command, *update.Response](update.NewHandler(m.repo, m.eb))
+	mediator.Register[*update.Command, *update.Response](update.NewHandler(m.repo, m.ctx.Transactor, m.eb))
 	mediator.Register[*itemslist.Query, *itemslist.Response](itemslist.NewHandler(m.repo))
 	mediator.Register[*itemsupdate.Command, *itemsupdate.Response](itemsupdate.NewHandler(m.repo, eb))
 	mediator.Register[*delete.Command, mediator.NoResponse](delete.NewHandler(m.repo, eb))
@@ -0,0 +1,227 @@
+package dto
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/shift/internal/repository"
+	"hrms/shared/common/errs"
+	"hrms/shared/contracts"
+)
+
+type Shift struct {
+	ID                uuid.UUID `json:"id"`
+	Code              string    `json:"code"`
+	Name              string    `json:"name"`
+	StartTime         string    `json:"startTime"`
+	EndTime           string    `json:"endTime"`
+	Overnight         bool      `json:"overnight"`
+	BreakMinutes      int       `json:"breakMinutes"`
+	WorkMinutes       int       `json:"workMinutes"`
+	LateGraceMinutes  int       `json:"lateGraceMinutes"`
+	EarlyGraceMinutes int       `json:"earlyGraceMinutes"`
+	MinOTMinutes      int       `json:"minOtMinutes"`
+	WorkDays          []int     `json:"workDays"`
+	IsActive          bool      `json:"isActive"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+}
+
+func FromShift(s repository.Shift) Shift {
+	start, end := minuteOfDay(s.StartTime), minuteOfDay(s.EndTime)
+	span := end - start
+	if span <= 0 {
+		span += 24 * 60
+	}
+	return Shift{
+		ID:                s.ID,
+		Code:              s.Code,
+		Name:              s.Name,
+		StartTime:         s.StartTime,
+		EndTime:           s.EndTime,
+		Overnight:         end <= start,
+		BreakMinutes:      s.BreakMinutes,
+		WorkMinutes:       span - s.BreakMinutes,
+		LateGraceMinutes:  s.LateGraceMinutes,
+		EarlyGraceMinutes: s.EarlyGraceMinutes,
+		MinOTMinutes:      s.MinOTMinutes,
+		WorkDays:          workDays(s.WorkDays),
+		IsActive:          s.IsActive,
+		CreatedAt:         s.CreatedAt,
+		UpdatedAt:         s.UpdatedAt,
+	}
+}
+
+func FromShifts(ss []repository.Shift) []Shift {
+	out := make([]Shift, 0, len(ss))
+	for _, s := range ss {
+		out = append(out, FromShift(s))
+	}
+	return out
+}
+
+func ToContract(s repository.Shift) contracts.ShiftDTO {
+	return contracts.ShiftDTO{
+		ID:                s.ID,
+		Code:              s.Code,
+		Name:              s.Name,
+		StartMinute:       minuteOfDay(s.StartTime),
+		EndMinute:         minuteOfDay(s.EndTime),
+		BreakMinutes:      s.BreakMinutes,
+		LateGraceMinutes:  s.LateGraceMinutes,
+		EarlyGraceMinutes: s.EarlyGraceMinutes,
+		MinOTMinutes:      s.MinOTMinutes,
+		WorkDays:          workDays(s.WorkDays),
+	}
+}
+
+// ShiftInput is the schedule part of the create and update payloads.
+type ShiftInput struct {
+	Code              string `json:"code" validate:"required,max=50"`
+	Name              string `json:"name" validate:"required,max=200"`
+	StartTime         string `json:"startTime" validate:"required"`
+	EndTime           string `json:"endTime" validate:"required"`
+	BreakMinutes      *int   `json:"breakMinutes" validate:"omitempty,gte=0,lte=720"`
+	LateGraceMinutes  int    `json:"lateGraceMinutes" validate:"gte=0,lte=240"`
+	EarlyGraceMinutes int    `json:"earlyGraceMinutes" validate:"gte=0,lte=240"`
+	MinOTMinutes      *int   `json:"minOtMinutes" validate:"omitempty,gte=0,lte=720"`
+	WorkDays          []int  `json:"workDays" validate:"max=7,dive,gte=1,lte=7"`
+	IsActive          *bool  `json:"isActive"`
+}
+
+// ToShift checks the times and the break and fills the defaults: a 60 minute break, OT from 30
+// minutes and Monday to Friday.
+func (in ShiftInput) ToShift() (repository.Shift, error) {
+	start, err := time.Parse("15:04", strings.TrimSpace(in.StartTime))
+	if err != nil {
+		return repository.Shift{}, errs.BadRequest("startTime must be HH:MM")
+	}
+	end, err := time.Parse("15:04", strings.TrimSpace(in.EndTime))
+	if err != nil {
+		return repository.Shift{}, errs.BadRequest("endTime must be HH:MM")
+	}
+	if start.Equal(end) {
+		return repository.Shift{}, errs.BadRequest("endTime must differ from startTime")
+	}
+	span := int(end.Sub(start).Minutes())
+	if span <= 0 {
+		span += 24 * 60
+	}
+
+	s := repository.Shift{
+		Code:              strings.TrimSpace(in.Code),
+		Name:              strings.TrimSpace(in.Name),
+		StartTime:         start.Format("15:04"),
+		EndTime:           end.Format("15:04"),
+		BreakMinutes:      60,
+		LateGraceMinutes:  in.LateGraceMinutes,
+		EarlyGraceMinutes: in.EarlyGraceMinutes,
+		MinOTMinutes:      30,
+		WorkDays:          []int64{1, 2, 3, 4, 5},
+		IsActive:          in.IsActive == nil || *in.IsActive,
+	}
+	if in.BreakMinutes != nil {
+		s.BreakMinutes = *in.BreakMinutes
+	}
+	if in.MinOTMinutes != nil {
+		s.MinOTMinutes = *in.MinOTMinutes
+	}
+	if s.BreakMinutes >= span {
+		return repository.Shift{}, errs.BadRequest(fmt.Sprintf("breakMinutes must be shorter than the shift (%d minutes)", span))
+	}
+	if len(in.WorkDays) > 0 {
+		days := map[int]bool{}
+		s.WorkDays = s.WorkDays[:0]
+		for _, d := range in.WorkDays {
+			if !days[d] {
+				days[d] = true
+				s.WorkDays = append(s.WorkDays, int64(d))
+			}
+		}
+		sort.Slice(s.WorkDays, func(i, j int) bool { return s.WorkDays[i] < s.WorkDays[j] })
+	}
+	return s, nil
+}
+
+type Assignment struct {
+	ID             uuid.UUID `json:"id"`
+	EmployeeID     uuid.UUID `json:"employeeId"`
+	EmployeeNumber string    `json:"employeeNumber"`
+	FirstName      string    `json:"firstName"`
+	LastName       string    `json:"lastName"`
+	ShiftID        uuid.UUID `json:"shiftId"`
+	ShiftCode      string    `json:"shiftCode"`
+	ShiftName      string    `json:"shiftName"`
+	StartDate      string    `json:"startDate"`
+	EndDate        *string   `json:"endDate,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+func FromAssignment(a repository.Assignment) Assignment {
+	out := Assignment{
+		ID:             a.ID,
+		EmployeeID:     a.EmployeeID,
+		EmployeeNumber: a.EmployeeNumber,
+		FirstName:      a.FirstName,
+		LastName:       a.LastName,
+		ShiftID:        a.ShiftID,
+		ShiftCode:      a.ShiftCode,
+		ShiftName:      a.ShiftName,
+		StartDate:      a.StartDate.Format("2006-01-02"),
+		CreatedAt:      a.CreatedAt,
+		UpdatedAt:      a.UpdatedAt,
+	}
+	if a.EndDate != nil {
+		d := a.EndDate.Format("2006-01-02")
+		out.EndDate = &d
+	}
+	return out
+}
+
+func FromAssignments(as []repository.Assignment) []Assignment {
+	out := make([]Assignment, 0, len(as))
+	for _, a := range as {
+		out = append(out, FromAssignment(a))
+	}
+	return out
+}
+
+// ParseRange reads an assignment's start and optional end date.
+func ParseRange(startDate string, endDate *string) (time.Time, *time.Time, error) {
+	start, err := time.Parse("2006-01-02", strings.TrimSpace(startDate))
+	if err != nil {
+		return time.Time{}, nil, errs.BadRequest("startDate must be YYYY-MM-DD")
+	}
+	if endDate == nil || strings.TrimSpace(*endDate) == "" {
+		return start, nil, nil
+	}
+	end, err := time.Parse("2006-01-02", strings.TrimSpace(*endDate))
+	if err != nil {
+		return time.Time{}, nil, errs.BadRequest("endDate must be YYYY-MM-DD")
+	}
+	if end.Before(start) {
+		return time.Time{}, nil, errs.BadRequest("endDate must be on or after startDate")
+	}
+	return start, &end, nil
+}
+
+func minuteOfDay(hhmm string) int {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		return 0
+	}
+	return t.Hour()*60 + t.Minute()
+}
+
+func workDays(days []int64) []int {
+	out := make([]int, 0, len(days))
+	for _, d := range days {
+		out = append(out, int(d))
+	}
+	return out
+}
@@ -0,0 +1,140 @@
+package create
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/shift/internal/dto"
+	"hrms/modules/shift/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/storage/sqldb/transactor"
+	"hrms/shared/common/validator"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	EmployeeID uuid.UUID `json:"employeeId" validate:"required"`
+	ShiftID    uuid.UUID `json:"shiftId" validate:"required"`
+	StartDate  string    `json:"startDate" validate:"required"`
+	EndDate    *string   `json:"endDate"`
+}
+
+type Response struct {
+	dto.Assignment
+}
+
+type Handler struct {
+	repo repository.Repository
+	tx   transactor.Transactor
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, tx transactor.Transactor, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, tx: tx, eb: eb}
+}
+
+// Handle assigns the shift from StartDate. An open-ended assignment that started earlier is
+// ended the day before, so changing an employee's shift takes a single call.
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+	start, end, err := dto.ParseRange(cmd.StartDate, cmd.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+
+	if _, err := h.repo.EmployeeBranchID(ctx, tenant, cmd.EmployeeID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.BadRequest("employee not found in this company")
+		}
+		logger.FromContext(ctx).Error("failed to load employee", zap.Error(err))
+		return nil, errs.Internal("failed to assign shift")
+	}
+	s, err := h.repo.GetShift(ctx, tenant.CompanyID, cmd.ShiftID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.BadRequest("shift not found")
+		}
+		logger.FromContext(ctx).Error("failed to load shift", zap.Error(err))
+		return nil, errs.Internal("failed to assign shift")
+	}
+	if !s.IsActive {
+		return nil, errs.BadRequest("shift is not active")
+	}
+
+	var (
+		id     uuid.UUID
+		closed bool
+	)
+	err = h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
+		var err error
+		closed, err = h.repo.CloseOpenAssignment(ctxTx, cmd.EmployeeID, start, user.ID)
+		if err != nil {
+			return err
+		}
+		id, err = h.repo.CreateAssignment(ctxTx, repository.Assignment{
+			CompanyID:  tenant.CompanyID,
+			EmployeeID: cmd.EmployeeID,
+			ShiftID:    cmd.ShiftID,
+			StartDate:  start,
+			EndDate:    end,
+		}, user.ID)
+		return err
+	})
+	if err != nil {
+		if repository.IsOverlapViolation(err) {
+			return nil, errs.Conflict("employee already has a shift in this date range")
+		}
+		logger.FromContext(ctx).Error("failed to assign shift", zap.Error(err))
+		return nil, errs.Internal("failed to assign shift")
+	}
+
+	created, err := h.repo.GetAssignment(ctx, tenant, id)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load shift assignment", zap.Error(err))
+		return nil, errs.Internal("failed to load shift assignment")
+	}
+
+	details := map[string]interface{}{
+		"employee_id":     cmd.EmployeeID.String(),
+		"shift_code":      created.ShiftCode,
+		"start_date":      start.Format("2006-01-02"),
+		"closed_previous": closed,
+	}
+	if end != nil {
+		details["end_date"] = end.Format("2006-01-02")
+	}
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   &created.BranchID,
+		Action:     "CREATE",
+		EntityName: "EMPLOYEE_SHIFT",
+		EntityID:   id.String(),
+		Details:    details,
+		Timestamp:  time.Now(),
+	})
+
+	return &Response{Assignment: dto.FromAssignment(*created)}, nil
+}
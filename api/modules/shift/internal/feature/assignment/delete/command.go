@@ -0,0 +1,79 @@
+package delete
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/shift/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	ID uuid.UUID
+}
+
+type Handler struct {
+	repo repository.Repository
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, mediator.NoResponse] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, eb: eb}
+}
+
+// Handle removes an assignment entered by mistake. To stop using a shift, set its endDate instead
+// so the days already worked keep their shift.
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (mediator.NoResponse, error) {
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return mediator.NoResponse{}, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return mediator.NoResponse{}, errs.Unauthorized("missing user context")
+	}
+
+	current, err := h.repo.GetAssignment(ctx, tenant, cmd.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return mediator.NoResponse{}, errs.NotFound("shift assignment not found")
+		}
+		logger.FromContext(ctx).Error("failed to load shift assignment", zap.Error(err))
+		return mediator.NoResponse{}, errs.Internal("failed to delete shift assignment")
+	}
+	if err := h.repo.DeleteAssignment(ctx, tenant.CompanyID, cmd.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return mediator.NoResponse{}, errs.NotFound("shift assignment not found")
+		}
+		logger.FromContext(ctx).Error("failed to delete shift assignment", zap.Error(err))
+		return mediator.NoResponse{}, errs.Internal("failed to delete shift assignment")
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   &current.BranchID,
+		Action:     "DELETE",
+		EntityName: "EMPLOYEE_SHIFT",
+		EntityID:   cmd.ID.String(),
+		Details: map[string]interface{}{
+			"employee_id": current.EmployeeID.String(),
+			"shift_code":  current.ShiftCode,
+			"start_date":  current.StartDate.Format("2006-01-02"),
+		},
+		Timestamp: time.Now(),
+	})
+	return mediator.NoResponse{}, nil
+}
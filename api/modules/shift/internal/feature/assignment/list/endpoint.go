@@ -0,0 +1,70 @@
+package list
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// List shift assignments
+// @Summary List shift assignments
+// @Description รายการกะของพนักงานตามช่วงวันที่ กรองตามพนักงาน กะ หรือช่วงวันที่ที่ทับกันได้
+// @Tags Shifts
+// @Produce json
+// @Security BearerAuth
+// @Param employeeId query string false "employee id"
+// @Param shiftId query string false "shift id"
+// @Param from query string false "YYYY-MM-DD"
+// @Param to query string false "YYYY-MM-DD"
+// @Success 200 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /shift-assignments [get]
+func NewEndpoint(router fiber.Router) {
+	router.Get("/", func(c fiber.Ctx) error {
+		var q Query
+		if s := strings.TrimSpace(c.Query("employeeId")); s != "" {
+			id, err := uuid.Parse(s)
+			if err != nil {
+				return errs.BadRequest("invalid employeeId")
+			}
+			q.EmployeeID = &id
+		}
+		if s := strings.TrimSpace(c.Query("shiftId")); s != "" {
+			id, err := uuid.Parse(s)
+			if err != nil {
+				return errs.BadRequest("invalid shiftId")
+			}
+			q.ShiftID = &id
+		}
+		if s := strings.TrimSpace(c.Query("from")); s != "" {
+			from, err := time.Parse("2006-01-02", s)
+			if err != nil {
+				return errs.BadRequest("from must be YYYY-MM-DD")
+			}
+			q.From = &from
+		}
+		if s := strings.TrimSpace(c.Query("to")); s != "" {
+			to, err := time.Parse("2006-01-02", s)
+			if err != nil {
+				return errs.BadRequest("to must be YYYY-MM-DD")
+			}
+			q.To = &to
+		}
+
+		resp, err := mediator.Send[*Query, *Response](c.Context(), &q)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
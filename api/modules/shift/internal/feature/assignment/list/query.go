@@ -0,0 +1,56 @@
+package list
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/shift/internal/dto"
+	"hrms/modules/shift/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+)
+
+type Query struct {
+	EmployeeID *uuid.UUID
+	ShiftID    *uuid.UUID
+	From       *time.Time
+	To         *time.Time
+}
+
+type Response struct {
+	Data []dto.Assignment `json:"data"`
+}
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*Query, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) Handle(ctx context.Context, q *Query) (*Response, error) {
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+
+	rows, err := h.repo.ListAssignments(ctx, tenant, repository.AssignmentFilter{
+		EmployeeID: q.EmployeeID,
+		ShiftID:    q.ShiftID,
+		From:       q.From,
+		To:         q.To,
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list shift assignments", zap.Error(err))
+		return nil, errs.Internal("failed to list shift assignments")
+	}
+	return &Response{Data: dto.FromAssignments(rows)}, nil
+}
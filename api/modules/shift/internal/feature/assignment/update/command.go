@@ -0,0 +1,133 @@
+package update
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/shift/internal/dto"
+	"hrms/modules/shift/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/validator"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	ID        uuid.UUID  `json:"-"`
+	ShiftID   *uuid.UUID `json:"shiftId"`
+	StartDate string     `json:"startDate" validate:"required"`
+	EndDate   *string    `json:"endDate"`
+}
+
+type Response struct {
+	dto.Assignment
+}
+
+type Handler struct {
+	repo repository.Repository
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, eb: eb}
+}
+
+// Handle changes the shift or the dates of an assignment; setting endDate ends it.
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+	start, end, err := dto.ParseRange(cmd.StartDate, cmd.EndDate)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+
+	current, err := h.repo.GetAssignment(ctx, tenant, cmd.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("shift assignment not found")
+		}
+		logger.FromContext(ctx).Error("failed to load shift assignment", zap.Error(err))
+		return nil, errs.Internal("failed to load shift assignment")
+	}
+	shiftID := current.ShiftID
+	if cmd.ShiftID != nil && *cmd.ShiftID != current.ShiftID {
+		s, err := h.repo.GetShift(ctx, tenant.CompanyID, *cmd.ShiftID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, errs.BadRequest("shift not found")
+			}
+			logger.FromContext(ctx).Error("failed to load shift", zap.Error(err))
+			return nil, errs.Internal("failed to update shift assignment")
+		}
+		if !s.IsActive {
+			return nil, errs.BadRequest("shift is not active")
+		}
+		shiftID = s.ID
+	}
+
+	if err := h.repo.UpdateAssignment(ctx, repository.Assignment{
+		ID:        cmd.ID,
+		CompanyID: tenant.CompanyID,
+		ShiftID:   shiftID,
+		StartDate: start,
+		EndDate:   end,
+	}, user.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("shift assignment not found")
+		}
+		if repository.IsOverlapViolation(err) {
+			return nil, errs.Conflict("employee already has a shift in this date range")
+		}
+		logger.FromContext(ctx).Error("failed to update shift assignment", zap.Error(err))
+		return nil, errs.Internal("failed to update shift assignment")
+	}
+
+	updated, err := h.repo.GetAssignment(ctx, tenant, cmd.ID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load shift assignment", zap.Error(err))
+		return nil, errs.Internal("failed to load shift assignment")
+	}
+
+	details := map[string]interface{}{}
+	if updated.ShiftID != current.ShiftID {
+		details["shift_code"] = updated.ShiftCode
+	}
+	if !updated.StartDate.Equal(current.StartDate) {
+		details["start_date"] = updated.StartDate.Format("2006-01-02")
+	}
+	if (updated.EndDate == nil) != (current.EndDate == nil) || (updated.EndDate != nil && !updated.EndDate.Equal(*current.EndDate)) {
+		details["end_date"] = dto.FromAssignment(*updated).EndDate
+	}
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   &updated.BranchID,
+		Action:     "UPDATE",
+		EntityName: "EMPLOYEE_SHIFT",
+		EntityID:   updated.ID.String(),
+		Details:    details,
+		Timestamp:  time.Now(),
+	})
+
+	return &Response{Assignment: dto.FromAssignment(*updated)}, nil
+}
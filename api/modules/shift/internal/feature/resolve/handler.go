@@ -0,0 +1,42 @@
+package resolve
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"hrms/modules/shift/internal/dto"
+	"hrms/modules/shift/internal/repository"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/contracts"
+)
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*contracts.ResolveShiftsQuery, *contracts.ResolveShiftsResponse] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) Handle(ctx context.Context, q *contracts.ResolveShiftsQuery) (*contracts.ResolveShiftsResponse, error) {
+	rows, err := h.repo.ResolveAssignments(ctx, q.CompanyID, q.EmployeeIDs, q.From, q.To)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to resolve shifts", zap.Error(err))
+		return nil, errs.Internal("failed to resolve shifts")
+	}
+	out := make([]contracts.ShiftAssignmentDTO, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, contracts.ShiftAssignmentDTO{
+			EmployeeID: r.EmployeeID,
+			StartDate:  r.StartDate,
+			EndDate:    r.EndDate,
+			Shift:      dto.ToContract(r.Shift),
+		})
+	}
+	return &contracts.ResolveShiftsResponse{Assignments: out}, nil
+}
@@ -0,0 +1,84 @@
+package create
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"hrms/modules/shift/internal/dto"
+	"hrms/modules/shift/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/validator"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	dto.ShiftInput
+}
+
+type Response struct {
+	dto.Shift
+}
+
+type Handler struct {
+	repo repository.Repository
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, eb: eb}
+}
+
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+	s, err := cmd.ToShift()
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+
+	s.CompanyID = tenant.CompanyID
+	created, err := h.repo.CreateShift(ctx, s, user.ID)
+	if err != nil {
+		if repository.IsUniqueViolation(err) {
+			return nil, errs.Conflict("shift code already exists")
+		}
+		logger.FromContext(ctx).Error("failed to create shift", zap.Error(err))
+		return nil, errs.Internal("failed to create shift")
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "CREATE",
+		EntityName: "WORK_SHIFT",
+		EntityID:   created.ID.String(),
+		Details: map[string]interface{}{
+			"code":       created.Code,
+			"name":       created.Name,
+			"start_time": created.StartTime,
+			"end_time":   created.EndTime,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return &Response{Shift: dto.FromShift(*created)}, nil
+}
@@ -0,0 +1,39 @@
+package create
+
+import (
+	"github.com/gofiber/fiber/v3"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// Create shift
+// @Summary Create work shift
+// @Description เพิ่มกะการทำงาน เวลาเลิกงานน้อยกว่าหรือเท่ากับเวลาเข้างาน = กะข้ามวัน ค่าเริ่มต้น: พัก 60 นาที, OT ขั้นต่ำ 30 นาที, ทำงานจันทร์-ศุกร์ (workDays 1 = จันทร์ ... 7 = อาทิตย์)
+// @Tags Shifts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body Command true "shift payload"
+// @Success 201 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 409
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /shifts [post]
+func NewEndpoint(router fiber.Router) {
+	router.Post("/", func(c fiber.Ctx) error {
+		var cmd Command
+		if err := c.Bind().Body(&cmd); err != nil {
+			return errs.BadRequest("invalid request body")
+		}
+		resp, err := mediator.Send[*Command, *Response](c.Context(), &cmd)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusCreated, resp)
+	})
+}
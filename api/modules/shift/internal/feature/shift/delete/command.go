@@ -0,0 +1,83 @@
+package delete
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/shift/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	ID uuid.UUID
+}
+
+type Handler struct {
+	repo repository.Repository
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, mediator.NoResponse] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, eb: eb}
+}
+
+// Handle removes a shift that no current or future assignment uses. Past assignments keep
+// pointing at it so earlier clock records can still be processed.
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (mediator.NoResponse, error) {
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return mediator.NoResponse{}, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return mediator.NoResponse{}, errs.Unauthorized("missing user context")
+	}
+
+	if _, err := h.repo.GetShift(ctx, tenant.CompanyID, cmd.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return mediator.NoResponse{}, errs.NotFound("shift not found")
+		}
+		logger.FromContext(ctx).Error("failed to load shift", zap.Error(err))
+		return mediator.NoResponse{}, errs.Internal("failed to delete shift")
+	}
+	used, err := h.repo.ShiftInUse(ctx, cmd.ID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to check shift assignments", zap.Error(err))
+		return mediator.NoResponse{}, errs.Internal("failed to delete shift")
+	}
+	if used {
+		return mediator.NoResponse{}, errs.Conflict("shift is assigned to employees; end the assignments first")
+	}
+
+	if err := h.repo.SoftDeleteShift(ctx, tenant, cmd.ID, user.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return mediator.NoResponse{}, errs.NotFound("shift not found")
+		}
+		logger.FromContext(ctx).Error("failed to delete shift", zap.Error(err))
+		return mediator.NoResponse{}, errs.Internal("failed to delete shift")
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "DELETE",
+		EntityName: "WORK_SHIFT",
+		EntityID:   cmd.ID.String(),
+		Details:    map[string]interface{}{},
+		Timestamp:  time.Now(),
+	})
+	return mediator.NoResponse{}, nil
+}
@@ -0,0 +1,46 @@
+package list
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"hrms/modules/shift/internal/dto"
+	"hrms/modules/shift/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+)
+
+type Query struct {
+	ActiveOnly bool
+}
+
+type Response struct {
+	Data []dto.Shift `json:"data"`
+}
+
+type Handler struct {
+	repo repository.Repository
+}
+
+var _ mediator.RequestHandler[*Query, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) Handle(ctx context.Context, q *Query) (*Response, error) {
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+
+	shifts, err := h.repo.ListShifts(ctx, tenant, q.ActiveOnly)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list shifts", zap.Error(err))
+		return nil, errs.Internal("failed to list shifts")
+	}
+	return &Response{Data: dto.FromShifts(shifts)}, nil
+}
@@ -0,0 +1,122 @@
+package update
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/shift/internal/dto"
+	"hrms/modules/shift/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/validator"
+	"hrms/shared/events"
+)
+
+type Command struct {
+	ID uuid.UUID `json:"-"`
+	dto.ShiftInput
+}
+
+type Response struct {
+	dto.Shift
+}
+
+type Handler struct {
+	repo repository.Repository
+	eb   eventbus.EventBus
+}
+
+var _ mediator.RequestHandler[*Command, *Response] = (*Handler)(nil)
+
+func NewHandler(repo repository.Repository, eb eventbus.EventBus) *Handler {
+	return &Handler{repo: repo, eb: eb}
+}
+
+// Handle replaces the shift's schedule. Clock records already processed keep their minutes
+// until worklog generation is run again for their dates.
+func (h *Handler) Handle(ctx context.Context, cmd *Command) (*Response, error) {
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+	s, err := cmd.ToShift()
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+
+	current, err := h.repo.GetShift(ctx, tenant.CompanyID, cmd.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("shift not found")
+		}
+		logger.FromContext(ctx).Error("failed to load shift", zap.Error(err))
+		return nil, errs.Internal("failed to load shift")
+	}
+
+	s.ID = cmd.ID
+	updated, err := h.repo.UpdateShift(ctx, tenant, s, user.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("shift not found")
+		}
+		if repository.IsUniqueViolation(err) {
+			return nil, errs.Conflict("shift code already exists")
+		}
+		logger.FromContext(ctx).Error("failed to update shift", zap.Error(err))
+		return nil, errs.Internal("failed to update shift")
+	}
+
+	details := map[string]interface{}{}
+	if updated.Code != current.Code {
+		details["code"] = updated.Code
+	}
+	if updated.Name != current.Name {
+		details["name"] = updated.Name
+	}
+	if updated.StartTime != current.StartTime || updated.EndTime != current.EndTime {
+		details["start_time"] = updated.StartTime
+		details["end_time"] = updated.EndTime
+	}
+	if updated.BreakMinutes != current.BreakMinutes {
+		details["break_minutes"] = updated.BreakMinutes
+	}
+	if updated.LateGraceMinutes != current.LateGraceMinutes || updated.EarlyGraceMinutes != current.EarlyGraceMinutes {
+		details["late_grace_minutes"] = updated.LateGraceMinutes
+		details["early_grace_minutes"] = updated.EarlyGraceMinutes
+	}
+	if updated.MinOTMinutes != current.MinOTMinutes {
+		details["min_ot_minutes"] = updated.MinOTMinutes
+	}
+	if updated.IsActive != current.IsActive {
+		details["is_active"] = updated.IsActive
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "UPDATE",
+		EntityName: "WORK_SHIFT",
+		EntityID:   updated.ID.String(),
+		Details:    details,
+		Timestamp:  time.Now(),
+	})
+
+	return &Response{Shift: dto.FromShift(*updated)}, nil
+}
@@ -0,0 +1,342 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/storage/sqldb/transactor"
+)
+
+type Repository struct {
+	dbCtx transactor.DBTXContext
+}
+
+func NewRepository(dbCtx transactor.DBTXContext) Repository {
+	return Repository{dbCtx: dbCtx}
+}
+
+// Shift is a work shift. StartTime and EndTime are HH:MM; an end on or before the start is an
+// overnight shift.
+type Shift struct {
+	ID                uuid.UUID     `db:"id"`
+	CompanyID         uuid.UUID     `db:"company_id"`
+	Code              string        `db:"code"`
+	Name              string        `db:"name"`
+	StartTime         string        `db:"start_time"`
+	EndTime           string        `db:"end_time"`
+	BreakMinutes      int           `db:"break_minutes"`
+	LateGraceMinutes  int           `db:"late_grace_minutes"`
+	EarlyGraceMinutes int           `db:"early_grace_minutes"`
+	MinOTMinutes      int           `db:"min_ot_minutes"`
+	WorkDays          pq.Int64Array `db:"work_days"`
+	IsActive          bool          `db:"is_active"`
+	CreatedAt         time.Time     `db:"created_at"`
+	CreatedBy         uuid.UUID     `db:"created_by"`
+	UpdatedAt         time.Time     `db:"updated_at"`
+	UpdatedBy         uuid.UUID     `db:"updated_by"`
+}
+
+const shiftColumns = `id, company_id, code, name,
+       to_char(start_time, 'HH24:MI') AS start_time, to_char(end_time, 'HH24:MI') AS end_time,
+       break_minutes, late_grace_minutes, early_grace_minutes, min_ot_minutes, work_days, is_active,
+       created_at, created_by, updated_at, updated_by`
+
+func (r Repository) ListShifts(ctx context.Context, tenant contextx.TenantInfo, activeOnly bool) ([]Shift, error) {
+	db := r.dbCtx(ctx)
+	where := "company_id = $1 AND deleted_at IS NULL"
+	if activeOnly {
+		where += " AND is_active"
+	}
+	q := fmt.Sprintf(`SELECT %s FROM work_shift WHERE %s ORDER BY start_time, code`, shiftColumns, where)
+	var out []Shift
+	if err := db.SelectContext(ctx, &out, q, tenant.CompanyID); err != nil {
+		return nil, err
+	}
+	if out == nil {
+		out = []Shift{}
+	}
+	return out, nil
+}
+
+// GetShift returns the company's shift, or sql.ErrNoRows.
+func (r Repository) GetShift(ctx context.Context, companyID, id uuid.UUID) (*Shift, error) {
+	db := r.dbCtx(ctx)
+	var s Shift
+	q := fmt.Sprintf(`SELECT %s FROM work_shift WHERE id = $1 AND company_id = $2 AND deleted_at IS NULL`, shiftColumns)
+	if err := db.GetContext(ctx, &s, q, id, companyID); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r Repository) CreateShift(ctx context.Context, s Shift, actor uuid.UUID) (*Shift, error) {
+	db := r.dbCtx(ctx)
+	q := fmt.Sprintf(`
+INSERT INTO work_shift (company_id, code, name, start_time, end_time, break_minutes, late_grace_minutes,
+                        early_grace_minutes, min_ot_minutes, work_days, is_active, created_by, updated_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $12)
+RETURNING %s`, shiftColumns)
+	var out Shift
+	if err := db.GetContext(ctx, &out, q, s.CompanyID, s.Code, s.Name, s.StartTime, s.EndTime, s.BreakMinutes,
+		s.LateGraceMinutes, s.EarlyGraceMinutes, s.MinOTMinutes, s.WorkDays, s.IsActive, actor); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateShift replaces the shift's schedule. Clock records already processed keep the minutes
+// they were given; they change only when generated again.
+func (r Repository) UpdateShift(ctx context.Context, tenant contextx.TenantInfo, s Shift, actor uuid.UUID) (*Shift, error) {
+	db := r.dbCtx(ctx)
+	q := fmt.Sprintf(`
+UPDATE work_shift
+SET code = $1, name = $2, start_time = $3, end_time = $4, break_minutes = $5, late_grace_minutes = $6,
+    early_grace_minutes = $7, min_ot_minutes = $8, work_days = $9, is_active = $10, updated_by = $11
+WHERE id = $12 AND company_id = $13 AND deleted_at IS NULL
+RETURNING %s`, shiftColumns)
+	var out Shift
+	if err := db.GetContext(ctx, &out, q, s.Code, s.Name, s.StartTime, s.EndTime, s.BreakMinutes, s.LateGraceMinutes,
+		s.EarlyGraceMinutes, s.MinOTMinutes, s.WorkDays, s.IsActive, actor, s.ID, tenant.CompanyID); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ShiftInUse reports whether an assignment still covers today or a later day.
+func (r Repository) ShiftInUse(ctx context.Context, id uuid.UUID) (bool, error) {
+	db := r.dbCtx(ctx)
+	var used bool
+	err := db.GetContext(ctx, &used, `
+SELECT EXISTS (
+  SELECT 1 FROM employee_shift WHERE shift_id = $1 AND (end_date IS NULL OR end_date >= CURRENT_DATE)
+)`, id)
+	return used, err
+}
+
+func (r Repository) SoftDeleteShift(ctx context.Context, tenant contextx.TenantInfo, id, actor uuid.UUID) error {
+	db := r.dbCtx(ctx)
+	res, err := db.ExecContext(ctx, `
+UPDATE work_shift SET deleted_at = now(), deleted_by = $1, is_active = FALSE, updated_by = $1
+WHERE id = $2 AND company_id = $3 AND deleted_at IS NULL`, actor, id, tenant.CompanyID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Assignment is the shift an employee works from StartDate to EndDate (nil = open-ended).
+type Assignment struct {
+	ID             uuid.UUID  `db:"id"`
+	CompanyID      uuid.UUID  `db:"company_id"`
+	EmployeeID     uuid.UUID  `db:"employee_id"`
+	EmployeeNumber string     `db:"employee_number"`
+	FirstName      string     `db:"first_name"`
+	LastName       string     `db:"last_name"`
+	BranchID       uuid.UUID  `db:"branch_id"`
+	ShiftID        uuid.UUID  `db:"shift_id"`
+	ShiftCode      string     `db:"shift_code"`
+	ShiftName      string     `db:"shift_name"`
+	StartDate      time.Time  `db:"start_date"`
+	EndDate        *time.Time `db:"end_date"`
+	CreatedAt      time.Time  `db:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at"`
+}
+
+const assignmentSelect = `
+SELECT es.id, es.company_id, es.employee_id, e.employee_number, e.first_name, e.last_name, e.branch_id,
+       es.shift_id, s.code AS shift_code, s.name AS shift_name, es.start_date, es.end_date,
+       es.created_at, es.updated_at
+FROM employee_shift es
+JOIN employees e ON e.id = es.employee_id
+JOIN work_shift s ON s.id = es.shift_id`
+
+// AssignmentFilter narrows the roster; zero values mean no filter.
+type AssignmentFilter struct {
+	EmployeeID *uuid.UUID
+	ShiftID    *uuid.UUID
+	From       *time.Time
+	To         *time.Time
+}
+
+// ListAssignments returns the assignments that overlap From..To. A branch tenant sees its own
+// employees only.
+func (r Repository) ListAssignments(ctx context.Context, tenant contextx.TenantInfo, f AssignmentFilter) ([]Assignment, error) {
+	db := r.dbCtx(ctx)
+	where := []string{"es.company_id = $1"}
+	args := []interface{}{tenant.CompanyID}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where = append(where, fmt.Sprintf("e.branch_id = $%d", len(args)))
+	}
+	if f.EmployeeID != nil {
+		args = append(args, *f.EmployeeID)
+		where = append(where, fmt.Sprintf("es.employee_id = $%d", len(args)))
+	}
+	if f.ShiftID != nil {
+		args = append(args, *f.ShiftID)
+		where = append(where, fmt.Sprintf("es.shift_id = $%d", len(args)))
+	}
+	if f.From != nil {
+		args = append(args, *f.From)
+		where = append(where, fmt.Sprintf("(es.end_date IS NULL OR es.end_date >= $%d)", len(args)))
+	}
+	if f.To != nil {
+		args = append(args, *f.To)
+		where = append(where, fmt.Sprintf("es.start_date <= $%d", len(args)))
+	}
+	q := fmt.Sprintf(`%s WHERE %s ORDER BY e.employee_number, es.start_date`, assignmentSelect, strings.Join(where, " AND "))
+	var out []Assignment
+	if err := db.SelectContext(ctx, &out, q, args...); err != nil {
+		return nil, err
+	}
+	if out == nil {
+		out = []Assignment{}
+	}
+	return out, nil
+}
+
+// GetAssignment returns the assignment in the tenant, or sql.ErrNoRows.
+func (r Repository) GetAssignment(ctx context.Context, tenant contextx.TenantInfo, id uuid.UUID) (*Assignment, error) {
+	db := r.dbCtx(ctx)
+	where := "es.id = $1 AND es.company_id = $2"
+	args := []interface{}{id, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		where += " AND e.branch_id = $3"
+		args = append(args, tenant.BranchID)
+	}
+	var out Assignment
+	if err := db.GetContext(ctx, &out, assignmentSelect+" WHERE "+where, args...); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// EmployeeBranchID returns the branch of the employee in the tenant, or sql.ErrNoRows.
+func (r Repository) EmployeeBranchID(ctx context.Context, tenant contextx.TenantInfo, employeeID uuid.UUID) (uuid.UUID, error) {
+	db := r.dbCtx(ctx)
+	q := "SELECT branch_id FROM employees WHERE id = $1 AND company_id = $2 AND deleted_at IS NULL"
+	args := []interface{}{employeeID, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		q += " AND branch_id = $3"
+		args = append(args, tenant.BranchID)
+	}
+	var branchID uuid.UUID
+	if err := db.GetContext(ctx, &branchID, q, args...); err != nil {
+		return uuid.Nil, err
+	}
+	return branchID, nil
+}
+
+// CloseOpenAssignment ends the employee's open-ended assignment that started before startDate
+// on the day before it, so a new shift can take over. It returns whether one was closed.
+func (r Repository) CloseOpenAssignment(ctx context.Context, employeeID uuid.UUID, startDate time.Time, actor uuid.UUID) (bool, error) {
+	db := r.dbCtx(ctx)
+	res, err := db.ExecContext(ctx, `
+UPDATE employee_shift SET end_date = $2::date - 1, updated_by = $3
+WHERE employee_id = $1 AND end_date IS NULL AND start_date < $2`, employeeID, startDate, actor)
+	if err != nil {
+		return false, err
+	}
+	rows, _ := res.RowsAffected()
+	return rows > 0, nil
+}
+
+func (r Repository) CreateAssignment(ctx context.Context, a Assignment, actor uuid.UUID) (uuid.UUID, error) {
+	db := r.dbCtx(ctx)
+	var id uuid.UUID
+	err := db.GetContext(ctx, &id, `
+INSERT INTO employee_shift (company_id, employee_id, shift_id, start_date, end_date, created_by, updated_by)
+VALUES ($1, $2, $3, $4, $5, $6, $6)
+RETURNING id`, a.CompanyID, a.EmployeeID, a.ShiftID, a.StartDate, a.EndDate, actor)
+	return id, err
+}
+
+// UpdateAssignment changes the shift and the dates of an assignment.
+func (r Repository) UpdateAssignment(ctx context.Context, a Assignment, actor uuid.UUID) error {
+	db := r.dbCtx(ctx)
+	res, err := db.ExecContext(ctx, `
+UPDATE employee_shift SET shift_id = $1, start_date = $2, end_date = $3, updated_by = $4
+WHERE id = $5 AND company_id = $6`, a.ShiftID, a.StartDate, a.EndDate, actor, a.ID, a.CompanyID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r Repository) DeleteAssignment(ctx context.Context, companyID, id uuid.UUID) error {
+	db := r.dbCtx(ctx)
+	res, err := db.ExecContext(ctx, `DELETE FROM employee_shift WHERE id = $1 AND company_id = $2`, id, companyID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ResolvedAssignment joins an assignment with its shift for the resolve contract.
+type ResolvedAssignment struct {
+	EmployeeID uuid.UUID  `db:"employee_id"`
+	StartDate  time.Time  `db:"start_date"`
+	EndDate    *time.Time `db:"end_date"`
+	Shift
+}
+
+// ResolveAssignments returns the assignments of the employees (all when empty) that overlap
+// from..to, with their shift, ordered by employee and start date.
+func (r Repository) ResolveAssignments(ctx context.Context, companyID uuid.UUID, employeeIDs []uuid.UUID, from, to time.Time) ([]ResolvedAssignment, error) {
+	db := r.dbCtx(ctx)
+	where := "es.company_id = $1 AND (es.end_date IS NULL OR es.end_date >= $2) AND es.start_date <= $3"
+	args := []interface{}{companyID, from, to}
+	if len(employeeIDs) > 0 {
+		where += " AND es.employee_id = ANY($4)"
+		args = append(args, pq.Array(employeeIDs))
+	}
+	q := fmt.Sprintf(`
+SELECT es.employee_id, es.start_date, es.end_date,
+       s.id, s.company_id, s.code, s.name,
+       to_char(s.start_time, 'HH24:MI') AS start_time, to_char(s.end_time, 'HH24:MI') AS end_time,
+       s.break_minutes, s.late_grace_minutes, s.early_grace_minutes, s.min_ot_minutes, s.work_days, s.is_active,
+       s.created_at, s.created_by, s.updated_at, s.updated_by
+FROM employee_shift es
+JOIN work_shift s ON s.id = es.shift_id
+WHERE %s
+ORDER BY es.employee_id, es.start_date`, where)
+	var out []ResolvedAssignment
+	if err := db.SelectContext(ctx, &out, q, args...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505"
+	}
+	return false
+}
+
+// IsOverlapViolation reports an assignment that shares days with another of the same employee.
+func IsOverlapViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23P01"
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package shift
+
+import (
+	assigncreate "hrms/modules/shift/internal/feature/assignment/create"
+	assigndelete "hrms/modules/shift/internal/feature/assignment/delete"
+	assignlist "hrms/modules/shift/internal/feature/assignment/list"
+	assignupdate "hrms/modules/shift/internal/feature/assignment/update"
+	"hrms/modules/shift/internal/feature/resolve"
+	shiftcreate "hrms/modules/shift/internal/feature/shift/create"
+	shiftdelete "hrms/modules/shift/internal/feature/shift/delete"
+	shiftlist "hrms/modules/shift/internal/feature/shift/list"
+	shiftupdate "hrms/modules/shift/internal/feature/shift/update"
+	"hrms/modules/shift/internal/repository"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/jwt"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/middleware"
+	"hrms/shared/common/module"
+	"hrms/shared/contracts"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+// Module owns work shifts and which shift each employee works over a date range. Worklog
+// resolves shifts through contracts to turn clock records into late, early-leave and OT entries.
+type Module struct {
+	ctx      *module.ModuleContext
+	repo     repository.Repository
+	tokenSvc *jwt.TokenService
+	eb       eventbus.EventBus
+}
+
+func NewModule(ctx *module.ModuleContext, tokenSvc *jwt.TokenService) *Module {
+	return &Module{
+		ctx:      ctx,
+		repo:     repository.NewRepository(ctx.DBCtx),
+		tokenSvc: tokenSvc,
+	}
+}
+
+func (m *Module) APIVersion() string { return "v1" }
+
+func (m *Module) Init(eb eventbus.EventBus) error {
+	m.eb = eb
+	mediator.Register[*shiftlist.Query, *shiftlist.Response](shiftlist.NewHandler(m.repo))
+	mediator.Register[*shiftcreate.Command, *shiftcreate.Response](shiftcreate.NewHandler(m.repo, eb))
+	mediator.Register[*shiftupdate.Command, *shiftupdate.Response](shiftupdate.NewHandler(m.repo, eb))
+	mediator.Register[*shiftdelete.Command, mediator.NoResponse](shiftdelete.NewHandler(m.repo, eb))
+	mediator.Register[*assignlist.Query, *assignlist.Response](assignlist.NewHandler(m.repo))
+	mediator.Register[*assigncreate.Command, *assigncreate.Response](assigncreate.NewHandler(m.repo, m.ctx.Transactor, eb))
+	mediator.Register[*assignupdate.Command, *assignupdate.Response](assignupdate.NewHandler(m.repo, eb))
+	mediator.Register[*assigndelete.Command, mediator.NoResponse](assigndelete.NewHandler(m.repo, eb))
+
+	// contract handlers used by worklog
+	mediator.Register[*contracts.ResolveShiftsQuery, *contracts.ResolveShiftsResponse](resolve.NewHandler(m.repo))
+	return nil
+}
+
+func (m *Module) RegisterRoutes(r fiber.Router) {
+	shifts := r.Group("/shifts", middleware.Auth(m.tokenSvc), middleware.TenantMiddleware(), middleware.RequireRoles("admin", "hr", "timekeeper"))
+	shiftlist.NewEndpoint(shifts)
+	shiftAdmin := shifts.Group("", middleware.RequireRoles("admin", "hr"))
+	shiftcreate.NewEndpoint(shiftAdmin)
+	shiftupdate.NewEndpoint(shiftAdmin)
+	shiftdelete.NewEndpoint(shiftAdmin)
+
+	// timekeepers keep the roster of their branch
+	assignments := r.Group("/shift-assignments", middleware.Auth(m.tokenSvc), middleware.TenantMiddleware(), middleware.RequireRoles("admin", "hr", "timekeeper"))
+	assignlist.NewEndpoint(assignments)
+	assigncreate.NewEndpoint(assignments)
+	assignupdate.NewEndpoint(assignments)
+	assigndelete.NewEndpoint(assignments)
+}
@@ -12,6 +12,7 @@ require (
 	github.com/lib/pq v1.10.9
 	go.uber.org/zap v1.27.1
 	hrms/shared/common v0.0.0
+	hrms/shared/contracts v0.0.0
 	hrms/shared/events v0.0.0
 )
 
@@ -38,7 +39,6 @@ require (
 	golang.org/x/net v0.48.0 // indirect
 	golang.org/x/sys v0.39.0 // indirect
 	golang.org/x/text v0.32.0 // indirect
-	hrms/shared/contracts v0.0.0 // indirect
 )
 
 replace hrms/shared/contracts v0.0.0 => ../../shared/contracts
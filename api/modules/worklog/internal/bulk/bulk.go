@@ -0,0 +1,69 @@
+// Package bulk runs the rows of a bulk worklog request in one transaction.
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/storage/sqldb/transactor"
+)
+
+// MaxItems caps the rows of one bulk request.
+const MaxItems = 500
+
+// errRowsFailed rolls back a run in which a row failed.
+var errRowsFailed = errors.New("bulk rows failed")
+
+// Error explains why one row failed.
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Summary counts the rows of a bulk request.
+type Summary struct {
+	Total     int `json:"total"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+}
+
+// Run calls row for each of the n rows in one transaction, each under its own savepoint so a
+// failed row does not stop the others from being checked. A row fails by returning an
+// *errs.AppError; any other error aborts the run. Nothing is saved unless every row succeeds.
+// The returned slice holds each row's error, nil for the rows that succeeded.
+func Run(ctx context.Context, tx transactor.Transactor, n int, row func(ctx context.Context, i int) error) ([]*Error, Summary, error) {
+	rowErrs := make([]*Error, n)
+	summary := Summary{Total: n}
+	err := tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
+		for i := 0; i < n; i++ {
+			err := tx.WithinTransaction(ctxTx, func(ctxRow context.Context, _ func(transactor.PostCommitHook)) error {
+				return row(ctxRow, i)
+			})
+			if err == nil {
+				summary.Succeeded++
+				continue
+			}
+			var appErr *errs.AppError
+			if !errors.As(err, &appErr) {
+				return err
+			}
+			rowErrs[i] = &Error{Code: string(appErr.Code), Message: appErr.Message}
+			summary.Failed++
+		}
+		if summary.Failed > 0 {
+			return errRowsFailed
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errRowsFailed) {
+		return nil, summary, err
+	}
+	return rowErrs, summary, nil
+}
+
+// FailedError reports a run in which some rows failed; detail carries the per-row results.
+func FailedError(summary Summary, detail interface{}) error {
+	return errs.Unprocessable(fmt.Sprintf("%d of %d rows failed; nothing was saved", summary.Failed, summary.Total), detail)
+}
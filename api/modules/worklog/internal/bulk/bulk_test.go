@@ -0,0 +1,92 @@
+package bulk
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/storage/sqldb/transactor"
+)
+
+// fakeTx runs nested transactions in place and logs how each one ended, outermost last.
+type fakeTx struct {
+	depth int
+	log   []string
+}
+
+func (f *fakeTx) WithinTransaction(ctx context.Context, fn func(context.Context, func(transactor.PostCommitHook)) error) error {
+	f.depth++
+	err := fn(ctx, func(transactor.PostCommitHook) {})
+	f.depth--
+	outcome := "commit"
+	if err != nil {
+		outcome = "rollback"
+	}
+	if f.depth == 0 {
+		outcome += " all"
+	}
+	f.log = append(f.log, outcome)
+	return err
+}
+
+func TestRunChecksEveryRowAndRollsBackOnFailure(t *testing.T) {
+	tx := &fakeTx{}
+	var ran []int
+	rowErrs, summary, err := Run(context.Background(), tx, 3, func(_ context.Context, i int) error {
+		ran = append(ran, i)
+		if i == 1 {
+			return errs.BadRequest("workDate is a holiday")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if !slices.Equal(ran, []int{0, 1, 2}) {
+		t.Errorf("ran rows %v, want all three", ran)
+	}
+	if summary != (Summary{Total: 3, Succeeded: 2, Failed: 1}) {
+		t.Errorf("summary = %+v", summary)
+	}
+	if rowErrs[0] != nil || rowErrs[2] != nil || rowErrs[1] == nil || *rowErrs[1] != (Error{Code: "bad_request", Message: "workDate is a holiday"}) {
+		t.Errorf("row errors = %v, %v, %v", rowErrs[0], rowErrs[1], rowErrs[2])
+	}
+	if want := []string{"commit", "rollback", "commit", "rollback all"}; !slices.Equal(tx.log, want) {
+		t.Errorf("transactions %v, want %v", tx.log, want)
+	}
+}
+
+func TestRunCommitsWhenEveryRowSucceeds(t *testing.T) {
+	tx := &fakeTx{}
+	rowErrs, summary, err := Run(context.Background(), tx, 2, func(context.Context, int) error { return nil })
+	if err != nil || summary.Failed != 0 || summary.Succeeded != 2 || rowErrs[0] != nil || rowErrs[1] != nil {
+		t.Fatalf("Run() = %v, %+v, %v", rowErrs, summary, err)
+	}
+	if want := []string{"commit", "commit", "commit all"}; !slices.Equal(tx.log, want) {
+		t.Errorf("transactions %v, want %v", tx.log, want)
+	}
+}
+
+func TestRunAbortsOnUnexpectedError(t *testing.T) {
+	tx := &fakeTx{}
+	dbErr := errors.New("connection reset")
+	var ran []int
+	_, _, err := Run(context.Background(), tx, 3, func(_ context.Context, i int) error {
+		ran = append(ran, i)
+		if i == 1 {
+			return dbErr
+		}
+		return nil
+	})
+	if !errors.Is(err, dbErr) {
+		t.Fatalf("Run() error = %v, want %v", err, dbErr)
+	}
+	if !slices.Equal(ran, []int{0, 1}) {
+		t.Errorf("ran rows %v, want to stop after row 1", ran)
+	}
+	if tx.log[len(tx.log)-1] != "rollback all" {
+		t.Errorf("transactions %v, want the outer one rolled back", tx.log)
+	}
+}
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/worklog/internal/repository"
+)
+
+type ClockItem struct {
+	ID                 uuid.UUID  `json:"id"`
+	EmployeeID         uuid.UUID  `json:"employeeId"`
+	BranchID           uuid.UUID  `json:"branchId"`
+	WorkDate           string     `json:"workDate"`
+	ClockIn            string     `json:"clockIn"`
+	ClockOut           *string    `json:"clockOut,omitempty"`
+	Source             string     `json:"source"`
+	Note               *string    `json:"note,omitempty"`
+	ShiftID            *uuid.UUID `json:"shiftId,omitempty"`
+	LateMinutes        *int       `json:"lateMinutes,omitempty"`
+	EarlyMinutes       *int       `json:"earlyMinutes,omitempty"`
+	OTMinutes          *int       `json:"otMinutes,omitempty"`
+	HolidayWorkMinutes *int       `json:"holidayWorkMinutes,omitempty"`
+	ProcessedAt        *time.Time `json:"processedAt,omitempty"`
+	CreatedAt          time.Time  `json:"createdAt"`
+	UpdatedAt          time.Time  `json:"updatedAt"`
+}
+
+// ClockLayout is how clock times are written in requests and responses (local wall-clock time).
+const ClockLayout = "2006-01-02 15:04"
+
+func FromClock(rec repository.ClockRecord) ClockItem {
+	item := ClockItem{
+		ID:                 rec.ID,
+		EmployeeID:         rec.EmployeeID,
+		BranchID:           rec.BranchID,
+		WorkDate:           rec.WorkDate.Format("2006-01-02"),
+		ClockIn:            rec.ClockIn.Format(ClockLayout),
+		Source:             rec.Source,
+		Note:               rec.Note,
+		ShiftID:            rec.ShiftID,
+		LateMinutes:        rec.LateMinutes,
+		EarlyMinutes:       rec.EarlyMinutes,
+		OTMinutes:          rec.OTMinutes,
+		HolidayWorkMinutes: rec.HolidayWorkMinutes,
+		ProcessedAt:        rec.ProcessedAt,
+		CreatedAt:          rec.CreatedAt,
+		UpdatedAt:          rec.UpdatedAt,
+	}
+	if rec.ClockOut != nil {
+		out := rec.ClockOut.Format(ClockLayout)
+		item.ClockOut = &out
+	}
+	return item
+}
@@ -9,25 +9,29 @@ import (
 )
 
 type FTItem struct {
-	ID         uuid.UUID `json:"id"`
-	EmployeeID uuid.UUID `json:"employeeId"`
-	EntryType  string    `json:"entryType"`
-	WorkDate   time.Time `json:"workDate"`
-	Quantity   float64   `json:"quantity"`
-	Status     string    `json:"status"`
-	CreatedAt  time.Time `json:"createdAt"`
-	UpdatedAt  time.Time `json:"updatedAt"`
+	ID           uuid.UUID  `json:"id"`
+	EmployeeID   uuid.UUID  `json:"employeeId"`
+	EntryType    string     `json:"entryType"`
+	WorkDate     time.Time  `json:"workDate"`
+	Quantity     float64    `json:"quantity"`
+	Status       string     `json:"status"`
+	PayrollRunID *uuid.UUID `json:"payrollRunId,omitempty"`
+	LeaveTypeID  *uuid.UUID `json:"leaveTypeId,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
 }
 
 func FromFT(rec repository.FTRecord) FTItem {
 	return FTItem{
-		ID:         rec.ID,
-		EmployeeID: rec.EmployeeID,
-		EntryType:  rec.EntryType,
-		WorkDate:   rec.WorkDate,
-		Quantity:   rec.Quantity,
-		Status:     rec.Status,
-		CreatedAt:  rec.CreatedAt,
-		UpdatedAt:  rec.UpdatedAt,
+		ID:           rec.ID,
+		EmployeeID:   rec.EmployeeID,
+		EntryType:    rec.EntryType,
+		WorkDate:     rec.WorkDate,
+		Quantity:     rec.Quantity,
+		Status:       rec.Status,
+		PayrollRunID: rec.PayrollRunID,
+		LeaveTypeID:  rec.LeaveTypeID,
+		CreatedAt:    rec.CreatedAt,
+		UpdatedAt:    rec.UpdatedAt,
 	}
 }
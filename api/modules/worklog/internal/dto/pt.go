@@ -10,20 +10,21 @@ import (
 )
 
 type PTItem struct {
-	ID             uuid.UUID `json:"id"`
-	EmployeeID     uuid.UUID `json:"employeeId"`
-	WorkDate       string    `json:"workDate"`
-	MorningIn      *string   `json:"morningIn,omitempty"`
-	MorningOut     *string   `json:"morningOut,omitempty"`
-	MorningMinutes int       `json:"morningMinutes"`
-	EveningIn      *string   `json:"eveningIn,omitempty"`
-	EveningOut     *string   `json:"eveningOut,omitempty"`
-	EveningMinutes int       `json:"eveningMinutes"`
-	TotalMinutes   int       `json:"totalMinutes"`
-	TotalHours     float64   `json:"totalHours"`
-	Status         string    `json:"status"`
-	CreatedAt      time.Time `json:"createdAt"`
-	UpdatedAt      time.Time `json:"updatedAt"`
+	ID             uuid.UUID  `json:"id"`
+	EmployeeID     uuid.UUID  `json:"employeeId"`
+	WorkDate       string     `json:"workDate"`
+	MorningIn      *string    `json:"morningIn,omitempty"`
+	MorningOut     *string    `json:"morningOut,omitempty"`
+	MorningMinutes int        `json:"morningMinutes"`
+	EveningIn      *string    `json:"eveningIn,omitempty"`
+	EveningOut     *string    `json:"eveningOut,omitempty"`
+	EveningMinutes int        `json:"eveningMinutes"`
+	TotalMinutes   int        `json:"totalMinutes"`
+	TotalHours     float64    `json:"totalHours"`
+	Status         string     `json:"status"`
+	PayrollRunID   *uuid.UUID `json:"payrollRunId,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
 }
 
 func FromPT(rec repository.PTRecord) PTItem {
@@ -63,6 +64,7 @@ func FromPT(rec repository.PTRecord) PTItem {
 		TotalMinutes:   rec.TotalMinutes,
 		TotalHours:     rec.TotalHours,
 		Status:         rec.Status,
+		PayrollRunID:   rec.PayrollRunID,
 		CreatedAt:      rec.CreatedAt,
 		UpdatedAt:      rec.UpdatedAt,
 	}
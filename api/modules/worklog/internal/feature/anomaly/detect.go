@@ -0,0 +1,392 @@
+package anomaly
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/worklog/internal/dto"
+	"hrms/modules/worklog/internal/repository"
+	"hrms/shared/contracts"
+)
+
+// Anomaly types
+const (
+	TypeLeaveNextToDayOff   = "leave_next_to_day_off"
+	TypeLateStreak          = "late_streak"
+	TypePTOverlap           = "pt_overlap"
+	TypePTOverDailyLimit    = "pt_over_daily_limit"
+	TypeOTWithoutAttendance = "ot_without_attendance"
+	TypeAfterEmploymentEnd  = "after_employment_end"
+)
+
+// Types lists every anomaly type in report order.
+var Types = []string{
+	TypeLeaveNextToDayOff,
+	TypeLateStreak,
+	TypePTOverlap,
+	TypePTOverDailyLimit,
+	TypeOTWithoutAttendance,
+	TypeAfterEmploymentEnd,
+}
+
+// Default thresholds
+const (
+	DefaultLateStreak = 3 // working days in a row with a late entry
+	DefaultPTMaxHours = 8 // hours a part-timer may work in a day
+)
+
+// Anomaly is one exception found in the worklogs. StartDate and EndDate are equal unless the
+// anomaly spans days (a late streak, an overlap across midnight, entries after leaving).
+type Anomaly struct {
+	Type           string      `json:"type"`
+	EmployeeID     uuid.UUID   `json:"employeeId"`
+	EmployeeNumber string      `json:"employeeNumber"`
+	FullName       string      `json:"fullName"`
+	StartDate      string      `json:"startDate"`
+	EndDate        string      `json:"endDate"`
+	WorklogIDs     []uuid.UUID `json:"worklogIds"`
+	Message        string      `json:"message"`
+}
+
+type options struct {
+	from         time.Time
+	to           time.Time
+	types        map[string]bool
+	lateStreak   int
+	ptMaxMinutes int
+}
+
+// employeeData is what the checks look at for one employee.
+type employeeData struct {
+	emp    repository.TimesheetEmployee
+	ft     []repository.FTRecord
+	pt     []repository.PTRecord
+	clocks map[string]bool
+}
+
+type calendar struct {
+	holidays *contracts.ListHolidaysResponse
+	shifts   *contracts.ResolveShiftsResponse
+}
+
+// dayOff tells why d is not a working day for the employee, or "" when it is one. Without a
+// shift, Saturday and Sunday are the weekend.
+func (c calendar) dayOff(employeeID uuid.UUID, d time.Time) string {
+	if h := c.holidays.Find(d); h != nil {
+		return "holiday " + h.Name
+	}
+	if s := c.shifts.For(employeeID, d); s != nil {
+		if !s.WorksOn(d) {
+			return "rest day of shift " + s.Code
+		}
+		return ""
+	}
+	if wd := d.Weekday(); wd == time.Saturday || wd == time.Sunday {
+		return "weekend"
+	}
+	return ""
+}
+
+// detect runs the requested checks over one employee's worklogs.
+func detect(opts options, data employeeData, cal calendar) []Anomaly {
+	var out []Anomaly
+	if opts.types[TypeLeaveNextToDayOff] {
+		out = append(out, leaveNextToDayOff(data, cal)...)
+	}
+	if opts.types[TypeLateStreak] {
+		out = append(out, lateStreaks(opts, data, cal)...)
+	}
+	if opts.types[TypePTOverlap] {
+		out = append(out, ptOverlaps(data)...)
+	}
+	if opts.types[TypePTOverDailyLimit] {
+		out = append(out, ptOverLimit(opts, data)...)
+	}
+	if opts.types[TypeOTWithoutAttendance] {
+		out = append(out, otWithoutAttendance(data)...)
+	}
+	if opts.types[TypeAfterEmploymentEnd] {
+		out = append(out, afterEmploymentEnd(data)...)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].StartDate < out[j].StartDate })
+	return out
+}
+
+func newAnomaly(typ string, emp repository.TimesheetEmployee, start, end time.Time, ids []uuid.UUID, msg string) Anomaly {
+	return Anomaly{
+		Type:           typ,
+		EmployeeID:     emp.ID,
+		EmployeeNumber: emp.EmployeeNumber,
+		FullName:       emp.FirstName + " " + emp.LastName,
+		StartDate:      start.Format("2006-01-02"),
+		EndDate:        end.Format("2006-01-02"),
+		WorklogIDs:     ids,
+		Message:        msg,
+	}
+}
+
+func isLeave(entryType string) bool {
+	switch entryType {
+	case "leave_day", "leave_double", "leave_hours", "leave_paid":
+		return true
+	}
+	return false
+}
+
+// leaveNextToDayOff flags leave taken the day before or after a holiday, rest day or weekend.
+func leaveNextToDayOff(data employeeData, cal calendar) []Anomaly {
+	var out []Anomaly
+	for _, day := range byDate(data.ft, func(r repository.FTRecord) bool { return isLeave(r.EntryType) }) {
+		d := day[0].WorkDate
+		var next []string
+		if why := cal.dayOff(data.emp.ID, d.AddDate(0, 0, -1)); why != "" {
+			next = append(next, fmt.Sprintf("%s on %s", why, d.AddDate(0, 0, -1).Format("2006-01-02")))
+		}
+		if why := cal.dayOff(data.emp.ID, d.AddDate(0, 0, 1)); why != "" {
+			next = append(next, fmt.Sprintf("%s on %s", why, d.AddDate(0, 0, 1).Format("2006-01-02")))
+		}
+		if len(next) == 0 {
+			continue
+		}
+		var kinds []string
+		var ids []uuid.UUID
+		for _, r := range day {
+			kinds = append(kinds, r.EntryType)
+			ids = append(ids, r.ID)
+		}
+		out = append(out, newAnomaly(TypeLeaveNextToDayOff, data.emp, d, d, ids,
+			fmt.Sprintf("%s next to %s", strings.Join(kinds, ", "), strings.Join(next, " and "))))
+	}
+	return out
+}
+
+// lateStreaks flags runs of working days that all have a late or early leave entry, the minutes
+// payroll deducts together. Days off do not break a run; a working day without either entry does.
+func lateStreaks(opts options, data employeeData, cal calendar) []Anomaly {
+	late := map[string][]repository.FTRecord{}
+	for _, r := range data.ft {
+		if r.EntryType == "late" || r.EntryType == "early_leave" {
+			key := r.WorkDate.Format("2006-01-02")
+			late[key] = append(late[key], r)
+		}
+	}
+	if len(late) < opts.lateStreak {
+		return nil
+	}
+
+	var (
+		out        []Anomaly
+		start, end time.Time
+		days       int
+		minutes    float64
+		ids        []uuid.UUID
+	)
+	flush := func() {
+		if days >= opts.lateStreak {
+			out = append(out, newAnomaly(TypeLateStreak, data.emp, start, end, ids,
+				fmt.Sprintf("late or left early on %d working days in a row, %g minutes in total", days, minutes)))
+		}
+		days, minutes, ids = 0, 0, nil
+	}
+	for d := opts.from; !d.After(opts.to); d = d.AddDate(0, 0, 1) {
+		if recs, ok := late[d.Format("2006-01-02")]; ok {
+			if days == 0 {
+				start = d
+			}
+			end = d
+			days++
+			for _, r := range recs {
+				minutes += r.Quantity
+				ids = append(ids, r.ID)
+			}
+			continue
+		}
+		if cal.dayOff(data.emp.ID, d) != "" {
+			continue
+		}
+		flush()
+	}
+	flush()
+	return out
+}
+
+// span is a PT session in minutes from midnight of the work date; an overnight session ends
+// after 1440.
+type span struct {
+	name       string
+	start, end int
+}
+
+func (s span) String() string {
+	return fmt.Sprintf("%s %s-%s", s.name, clock(s.start), clock(s.end))
+}
+
+func clock(m int) string {
+	return fmt.Sprintf("%02d:%02d", (m/60)%24, m%60)
+}
+
+func minuteOf(v *string) (int, bool) {
+	if v == nil {
+		return 0, false
+	}
+	t, err := time.Parse("15:04", *v)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// sessions returns the row's complete sessions, read the way the minute columns are computed.
+func sessions(rec repository.PTRecord) []span {
+	item := dto.FromPT(rec)
+	var out []span
+	for _, s := range []struct {
+		name    string
+		in, out *string
+	}{{"morning", item.MorningIn, item.MorningOut}, {"evening", item.EveningIn, item.EveningOut}} {
+		in, ok1 := minuteOf(s.in)
+		end, ok2 := minuteOf(s.out)
+		if !ok1 || !ok2 || in == end {
+			continue
+		}
+		if end < in {
+			end += 24 * 60
+		}
+		out = append(out, span{name: s.name, start: in, end: end})
+	}
+	return out
+}
+
+// ptOverlaps flags a day whose morning and evening sessions overlap, and an overnight session
+// that runs into a session of the next day.
+func ptOverlaps(data employeeData) []Anomaly {
+	var out []Anomaly
+	var prev *repository.PTRecord
+	var prevSpans []span
+	for i := range data.pt {
+		rec := data.pt[i]
+		spans := sessions(rec)
+		if len(spans) == 2 && spans[0].start < spans[1].end && spans[1].start < spans[0].end {
+			out = append(out, newAnomaly(TypePTOverlap, data.emp, rec.WorkDate, rec.WorkDate, []uuid.UUID{rec.ID},
+				fmt.Sprintf("%s overlaps %s", spans[0], spans[1])))
+		}
+		if prev != nil && prev.WorkDate.AddDate(0, 0, 1).Equal(rec.WorkDate) {
+			for _, p := range prevSpans {
+				for _, s := range spans {
+					if p.end > s.start+24*60 {
+						out = append(out, newAnomaly(TypePTOverlap, data.emp, prev.WorkDate, rec.WorkDate, []uuid.UUID{prev.ID, rec.ID},
+							fmt.Sprintf("%s on %s runs into %s on %s", p, prev.WorkDate.Format("2006-01-02"), s, rec.WorkDate.Format("2006-01-02"))))
+					}
+				}
+			}
+		}
+		prev, prevSpans = &data.pt[i], spans
+	}
+	return out
+}
+
+// ptOverLimit flags days a part-timer worked longer than the daily limit.
+func ptOverLimit(opts options, data employeeData) []Anomaly {
+	var out []Anomaly
+	for _, rec := range data.pt {
+		if rec.TotalMinutes > opts.ptMaxMinutes {
+			out = append(out, newAnomaly(TypePTOverDailyLimit, data.emp, rec.WorkDate, rec.WorkDate, []uuid.UUID{rec.ID},
+				fmt.Sprintf("worked %s, over the daily limit of %s", duration(rec.TotalMinutes), duration(opts.ptMaxMinutes))))
+		}
+	}
+	return out
+}
+
+func duration(m int) string {
+	return fmt.Sprintf("%d:%02d h", m/60, m%60)
+}
+
+// otWithoutAttendance flags OT and holiday work on a day of full-day leave, and, for employees
+// who clock in, on a day with no clock record.
+func otWithoutAttendance(data employeeData) []Anomaly {
+	var out []Anomaly
+	clocks := len(data.clocks) > 0
+	for _, day := range byDate(data.ft, nil) {
+		var leave *repository.FTRecord
+		for i := range day {
+			r := &day[i]
+			if (r.EntryType == "leave_day" || r.EntryType == "leave_double" || r.EntryType == "leave_paid") && r.Quantity >= 1 {
+				leave = r
+			}
+		}
+		key := day[0].WorkDate.Format("2006-01-02")
+		for _, r := range day {
+			if r.EntryType != "ot" && r.EntryType != "holiday_ot" && r.EntryType != "holiday_work" {
+				continue
+			}
+			switch {
+			case leave != nil:
+				out = append(out, newAnomaly(TypeOTWithoutAttendance, data.emp, r.WorkDate, r.WorkDate, []uuid.UUID{r.ID, leave.ID},
+					fmt.Sprintf("%s %g h on a day of full-day %s", r.EntryType, r.Quantity, leave.EntryType)))
+			case clocks && !data.clocks[key]:
+				out = append(out, newAnomaly(TypeOTWithoutAttendance, data.emp, r.WorkDate, r.WorkDate, []uuid.UUID{r.ID},
+					fmt.Sprintf("%s %g h with no clock record on the day", r.EntryType, r.Quantity)))
+			}
+		}
+	}
+	return out
+}
+
+// afterEmploymentEnd reports the FT and PT worklogs dated after the employee left, as one
+// anomaly per employee.
+func afterEmploymentEnd(data employeeData) []Anomaly {
+	end := data.emp.EmploymentEndDate
+	if end == nil {
+		return nil
+	}
+	type entry struct {
+		date time.Time
+		id   uuid.UUID
+	}
+	var after []entry
+	for _, r := range data.ft {
+		if r.WorkDate.After(*end) {
+			after = append(after, entry{r.WorkDate, r.ID})
+		}
+	}
+	for _, r := range data.pt {
+		if r.WorkDate.After(*end) {
+			after = append(after, entry{r.WorkDate, r.ID})
+		}
+	}
+	if len(after) == 0 {
+		return nil
+	}
+	sort.SliceStable(after, func(i, j int) bool { return after[i].date.Before(after[j].date) })
+	ids := make([]uuid.UUID, 0, len(after))
+	for _, e := range after {
+		ids = append(ids, e.id)
+	}
+	return []Anomaly{newAnomaly(TypeAfterEmploymentEnd, data.emp, after[0].date, after[len(after)-1].date, ids,
+		fmt.Sprintf("%d worklog(s) dated after employment ended on %s", len(after), end.Format("2006-01-02")))}
+}
+
+// byDate groups the FT rows that pass keep (all when nil) by work date, in date order.
+func byDate(rows []repository.FTRecord, keep func(repository.FTRecord) bool) [][]repository.FTRecord {
+	var out [][]repository.FTRecord
+	idx := map[string]int{}
+	for _, r := range rows {
+		if keep != nil && !keep(r) {
+			continue
+		}
+		key := r.WorkDate.Format("2006-01-02")
+		i, ok := idx[key]
+		if !ok {
+			i = len(out)
+			idx[key] = i
+			out = append(out, nil)
+		}
+		out[i] = append(out[i], r)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i][0].WorkDate.Before(out[j][0].WorkDate) })
+	return out
+}
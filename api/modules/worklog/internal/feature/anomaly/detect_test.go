@@ -0,0 +1,165 @@
+package anomaly
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/worklog/internal/repository"
+	"hrms/shared/contracts"
+)
+
+var testEmp = repository.TimesheetEmployee{ID: uuid.New(), EmployeeNumber: "E001", FirstName: "Somchai", LastName: "Jaidee"}
+
+// march returns a day of March 2026; the 2nd is a Monday.
+func march(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+
+func ftEntry(entryType string, d int, qty float64) repository.FTRecord {
+	return repository.FTRecord{ID: uuid.New(), EmployeeID: testEmp.ID, EntryType: entryType, WorkDate: march(d), Quantity: qty}
+}
+
+func ptEntry(d int, morningIn, morningOut, eveningIn, eveningOut string, total int) repository.PTRecord {
+	ptr := func(s string) *string {
+		if s == "" {
+			return nil
+		}
+		return &s
+	}
+	return repository.PTRecord{
+		ID: uuid.New(), EmployeeID: testEmp.ID, WorkDate: march(d),
+		MorningIn: ptr(morningIn), MorningOut: ptr(morningOut), EveningIn: ptr(eveningIn), EveningOut: ptr(eveningOut),
+		TotalMinutes: total,
+	}
+}
+
+func runCheck(typ string, data employeeData, cal calendar) []Anomaly {
+	opts := options{from: march(1), to: march(31), types: map[string]bool{typ: true}, lateStreak: DefaultLateStreak, ptMaxMinutes: DefaultPTMaxHours * 60}
+	data.emp = testEmp
+	return detect(opts, data, cal)
+}
+
+// spans lists the anomalies as "start..end" for comparison.
+func spans(as []Anomaly) []string {
+	out := make([]string, 0, len(as))
+	for _, a := range as {
+		out = append(out, a.StartDate+".."+a.EndDate)
+	}
+	return out
+}
+
+func TestLateStreakCountsEarlyLeave(t *testing.T) {
+	ft := []repository.FTRecord{
+		ftEntry("late", 5, 10),        // Thursday
+		ftEntry("early_leave", 6, 20), // Friday
+		ftEntry("late", 9, 5),         // Monday, after the weekend
+		ftEntry("early_leave", 9, 15),
+		ftEntry("early_leave", 11, 30), // Wednesday, after a clean Tuesday
+		ftEntry("ot", 11, 2),
+	}
+	got := runCheck(TypeLateStreak, employeeData{ft: ft}, calendar{})
+	if len(got) != 1 {
+		t.Fatalf("detect() = %+v, want one late streak", got)
+	}
+	a := got[0]
+	if a.Type != TypeLateStreak || a.StartDate != "2026-03-05" || a.EndDate != "2026-03-09" {
+		t.Errorf("streak = %s %s..%s, want %s 2026-03-05..2026-03-09", a.Type, a.StartDate, a.EndDate, TypeLateStreak)
+	}
+	if len(a.WorklogIDs) != 4 {
+		t.Errorf("streak has %d worklogs, want 4", len(a.WorklogIDs))
+	}
+	if want := "late or left early on 3 working days in a row, 50 minutes in total"; a.Message != want {
+		t.Errorf("message = %q, want %q", a.Message, want)
+	}
+}
+
+func TestLeaveNextToDayOff(t *testing.T) {
+	cal := calendar{
+		holidays: &contracts.ListHolidaysResponse{Holidays: []contracts.HolidayDTO{{Date: march(18), Name: "Founders Day"}}},
+		// Tuesday to Saturday from the 23rd, so Monday the 23rd is a rest day
+		shifts: &contracts.ResolveShiftsResponse{Assignments: []contracts.ShiftAssignmentDTO{
+			{EmployeeID: testEmp.ID, StartDate: march(23), Shift: contracts.ShiftDTO{Code: "TUE-SAT", WorkDays: []int{2, 3, 4, 5, 6}}},
+		}},
+	}
+	ft := []repository.FTRecord{
+		ftEntry("leave_day", 4, 1),    // Wednesday between working days
+		ftEntry("leave_day", 6, 1),    // Friday before the weekend
+		ftEntry("leave_paid", 17, 1),  // day before the holiday
+		ftEntry("leave_hours", 24, 4), // day after the shift's rest day
+		ftEntry("late", 27, 10),       // not leave
+	}
+	got := runCheck(TypeLeaveNextToDayOff, employeeData{ft: ft}, cal)
+	want := []string{"2026-03-06..2026-03-06", "2026-03-17..2026-03-17", "2026-03-24..2026-03-24"}
+	if !slices.Equal(spans(got), want) {
+		t.Fatalf("flagged %v, want %v", spans(got), want)
+	}
+	if want := "leave_paid next to holiday Founders Day on 2026-03-18"; got[1].Message != want {
+		t.Errorf("message = %q, want %q", got[1].Message, want)
+	}
+	if want := "leave_hours next to rest day of shift TUE-SAT on 2026-03-23"; got[2].Message != want {
+		t.Errorf("message = %q, want %q", got[2].Message, want)
+	}
+}
+
+func TestPTChecks(t *testing.T) {
+	pt := []repository.PTRecord{
+		ptEntry(2, "08:00", "13:00", "12:30", "17:00", 570), // sessions overlap and over the limit
+		ptEntry(3, "08:00", "12:00", "22:00", "02:00", 480), // overnight evening ...
+		ptEntry(4, "01:30", "05:00", "", "", 210),           // ... runs into the next morning
+		ptEntry(6, "08:00", "12:00", "13:00", "17:00", 480), // clean
+	}
+	overlaps := runCheck(TypePTOverlap, employeeData{pt: pt}, calendar{})
+	if want := []string{"2026-03-02..2026-03-02", "2026-03-03..2026-03-04"}; !slices.Equal(spans(overlaps), want) {
+		t.Errorf("overlaps %v, want %v", spans(overlaps), want)
+	}
+	over := runCheck(TypePTOverDailyLimit, employeeData{pt: pt}, calendar{})
+	if want := []string{"2026-03-02..2026-03-02"}; !slices.Equal(spans(over), want) {
+		t.Errorf("over the limit %v, want %v", spans(over), want)
+	}
+	if len(over) == 1 && over[0].Message != "worked 9:30 h, over the daily limit of 8:00 h" {
+		t.Errorf("message = %q", over[0].Message)
+	}
+}
+
+func TestOTWithoutAttendance(t *testing.T) {
+	ft := []repository.FTRecord{
+		ftEntry("leave_day", 2, 1),
+		ftEntry("ot", 2, 2), // on a day of full-day leave
+		ftEntry("leave_hours", 3, 4),
+		ftEntry("ot", 3, 1),           // half-day leave is fine
+		ftEntry("holiday_work", 7, 8), // no clock record
+		ftEntry("ot", 9, 2),
+	}
+	clocks := map[string]bool{"2026-03-03": true, "2026-03-09": true}
+	got := runCheck(TypeOTWithoutAttendance, employeeData{ft: ft, clocks: clocks}, calendar{})
+	if want := []string{"2026-03-02..2026-03-02", "2026-03-07..2026-03-07"}; !slices.Equal(spans(got), want) {
+		t.Fatalf("flagged %v, want %v", spans(got), want)
+	}
+	if len(got[0].WorklogIDs) != 2 || got[0].WorklogIDs[1] != ft[0].ID {
+		t.Errorf("leave day anomaly links %v, want the OT and the leave", got[0].WorklogIDs)
+	}
+	// without clock records at all only the leave day is flagged
+	if got := runCheck(TypeOTWithoutAttendance, employeeData{ft: ft}, calendar{}); len(got) != 1 {
+		t.Errorf("without clocks flagged %v, want only the leave day", spans(got))
+	}
+}
+
+func TestAfterEmploymentEnd(t *testing.T) {
+	end := march(10)
+	emp := testEmp
+	emp.EmploymentEndDate = &end
+	data := employeeData{
+		emp: emp,
+		ft:  []repository.FTRecord{ftEntry("ot", 10, 1), ftEntry("late", 12, 5)},
+		pt:  []repository.PTRecord{ptEntry(11, "08:00", "12:00", "", "", 240)},
+	}
+	opts := options{from: march(1), to: march(31), types: map[string]bool{TypeAfterEmploymentEnd: true}}
+	got := detect(opts, data, calendar{})
+	if len(got) != 1 || got[0].StartDate != "2026-03-11" || got[0].EndDate != "2026-03-12" || len(got[0].WorklogIDs) != 2 {
+		t.Fatalf("detect() = %+v, want one anomaly for the 2 worklogs of 11-12 March", got)
+	}
+	if want := "2 worklog(s) dated after employment ended on 2026-03-10"; got[0].Message != want {
+		t.Errorf("message = %q, want %q", got[0].Message, want)
+	}
+}
@@ -0,0 +1,77 @@
+package anomaly
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// Register anomaly report endpoint
+// @Summary Attendance anomaly report
+// @Description รายงานความผิดปกติของเวลาทำงานจาก worklog_ft/worklog_pt: ลาติดวันหยุดหรือวันหยุดประจำสัปดาห์ (ไม่มีกะใช้เสาร์-อาทิตย์), มาสายหรือออกก่อนเวลาติดต่อกันหลายวันทำงาน, กะพาร์ทไทม์ซ้อนกันหรือเกินชั่วโมงต่อวัน, OT ในวันที่ลาทั้งวันหรือไม่มีบันทึกเวลาเข้า-ออก (เฉพาะพนักงานที่มีบันทึกเวลา), รายการหลังวันสิ้นสุดการจ้าง
+// @Tags Worklogs Anomalies
+// @Produce json
+// @Param startDate query string true "YYYY-MM-DD"
+// @Param endDate query string true "YYYY-MM-DD (ไม่เกิน 366 วัน)"
+// @Param employeeId query string false "employee id"
+// @Param types query string false "คั่นด้วย , : leave_next_to_day_off,late_streak,pt_overlap,pt_over_daily_limit,ot_without_attendance,after_employment_end (default ทั้งหมด)"
+// @Param lateStreak query int false "จำนวนวันทำงานที่สายหรือออกก่อนเวลาติดต่อกันขั้นต่ำ (default 3)"
+// @Param ptMaxHours query number false "ชั่วโมงทำงานสูงสุดต่อวันของพาร์ทไทม์ (default 8)"
+// @Security BearerAuth
+// @Success 200 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /worklogs/anomalies [get]
+func Register(router fiber.Router) {
+	router.Get("/", func(c fiber.Ctx) error {
+		start, err := time.Parse("2006-01-02", c.Query("startDate"))
+		if err != nil {
+			return errs.BadRequest("startDate must be YYYY-MM-DD")
+		}
+		end, err := time.Parse("2006-01-02", c.Query("endDate"))
+		if err != nil {
+			return errs.BadRequest("endDate must be YYYY-MM-DD")
+		}
+		q := &Query{StartDate: start, EndDate: end}
+		if v := c.Query("employeeId"); v != "" {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				return errs.BadRequest("invalid employeeId")
+			}
+			q.EmployeeID = &id
+		}
+		if v := strings.TrimSpace(c.Query("types")); v != "" {
+			q.Types = strings.Split(v, ",")
+		}
+		if v := c.Query("lateStreak"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return errs.BadRequest("lateStreak must be a number")
+			}
+			q.LateStreak = n
+		}
+		if v := c.Query("ptMaxHours"); v != "" {
+			n, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return errs.BadRequest("ptMaxHours must be a number")
+			}
+			q.PTMaxHours = n
+		}
+
+		resp, err := mediator.Send[*Query, *Response](c.Context(), q)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
@@ -0,0 +1,208 @@
+package anomaly
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/worklog/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/contracts"
+)
+
+// MaxRangeDays caps the period one report scans.
+const MaxRangeDays = 366
+
+// Query scans the worklogs between StartDate and EndDate. Empty Types runs every check;
+// LateStreak and PTMaxHours fall back to the defaults when zero.
+type Query struct {
+	StartDate  time.Time
+	EndDate    time.Time
+	EmployeeID *uuid.UUID
+	Types      []string
+	LateStreak int
+	PTMaxHours float64
+}
+
+type Response struct {
+	Period struct {
+		StartDate string `json:"startDate"`
+		EndDate   string `json:"endDate"`
+	} `json:"period"`
+	Summary map[string]int `json:"summary"`
+	Data    []Anomaly      `json:"data"`
+}
+
+type handler struct {
+	repo repository.AnomalyRepository
+}
+
+func NewHandler(repo repository.AnomalyRepository) *handler {
+	return &handler{repo: repo}
+}
+
+// Handle runs the checks per employee and returns the anomalies by employee number and date.
+func (h *handler) Handle(ctx context.Context, q *Query) (*Response, error) {
+	if q.EndDate.Before(q.StartDate) {
+		return nil, errs.BadRequest("endDate must be on or after startDate")
+	}
+	if q.EndDate.Sub(q.StartDate) >= MaxRangeDays*24*time.Hour {
+		return nil, errs.BadRequest(fmt.Sprintf("the report covers at most %d days", MaxRangeDays))
+	}
+	opts := options{
+		from:       q.StartDate,
+		to:         q.EndDate,
+		types:      map[string]bool{},
+		lateStreak: q.LateStreak,
+	}
+	if opts.lateStreak == 0 {
+		opts.lateStreak = DefaultLateStreak
+	}
+	if opts.lateStreak < 2 {
+		return nil, errs.BadRequest("lateStreak must be at least 2")
+	}
+	maxHours := q.PTMaxHours
+	if maxHours == 0 {
+		maxHours = DefaultPTMaxHours
+	}
+	if maxHours < 0 || maxHours > 24 {
+		return nil, errs.BadRequest("ptMaxHours must be between 0 and 24")
+	}
+	opts.ptMaxMinutes = int(math.Round(maxHours * 60))
+	for _, t := range q.Types {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if !known(t) {
+			return nil, errs.BadRequest("unknown anomaly type: " + t)
+		}
+		opts.types[t] = true
+	}
+	if len(opts.types) == 0 {
+		for _, t := range Types {
+			opts.types[t] = true
+		}
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+
+	data, err := h.scan(ctx, tenant, q.EmployeeID, opts)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to scan worklog anomalies", zap.Error(err))
+		return nil, errs.Internal("failed to build anomaly report")
+	}
+
+	resp := &Response{Summary: map[string]int{}, Data: data}
+	resp.Period.StartDate = q.StartDate.Format("2006-01-02")
+	resp.Period.EndDate = q.EndDate.Format("2006-01-02")
+	for t := range opts.types {
+		resp.Summary[t] = 0
+	}
+	for _, a := range data {
+		resp.Summary[a.Type]++
+	}
+	return resp, nil
+}
+
+func (h *handler) scan(ctx context.Context, tenant contextx.TenantInfo, employeeID *uuid.UUID, opts options) ([]Anomaly, error) {
+	out := []Anomaly{}
+	ft, err := h.repo.ListFT(ctx, tenant, employeeID, opts.from, opts.to)
+	if err != nil {
+		return nil, err
+	}
+	pt, err := h.repo.ListPT(ctx, tenant, employeeID, opts.from, opts.to)
+	if err != nil {
+		return nil, err
+	}
+	if len(ft) == 0 && len(pt) == 0 {
+		return out, nil
+	}
+	clocks, err := h.repo.ListClockDays(ctx, tenant, employeeID, opts.from, opts.to)
+	if err != nil {
+		return nil, err
+	}
+
+	byEmp := map[uuid.UUID]*employeeData{}
+	var ids []uuid.UUID
+	get := func(id uuid.UUID) *employeeData {
+		d, ok := byEmp[id]
+		if !ok {
+			d = &employeeData{clocks: map[string]bool{}}
+			byEmp[id] = d
+			ids = append(ids, id)
+		}
+		return d
+	}
+	for _, r := range ft {
+		d := get(r.EmployeeID)
+		d.ft = append(d.ft, r)
+	}
+	for _, r := range pt {
+		d := get(r.EmployeeID)
+		d.pt = append(d.pt, r)
+	}
+	for _, c := range clocks {
+		if d, ok := byEmp[c.EmployeeID]; ok {
+			d.clocks[c.WorkDate.Format("2006-01-02")] = true
+		}
+	}
+	emps, err := h.repo.ListEmployees(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// the days either side of the period decide whether leave on its first or last day
+	// sits next to a day off
+	calFrom, calTo := opts.from.AddDate(0, 0, -1), opts.to.AddDate(0, 0, 1)
+	shifts, err := mediator.Send[*contracts.ResolveShiftsQuery, *contracts.ResolveShiftsResponse](ctx, &contracts.ResolveShiftsQuery{
+		CompanyID:   tenant.CompanyID,
+		EmployeeIDs: ids,
+		From:        calFrom,
+		To:          calTo,
+	})
+	if err != nil {
+		return nil, err
+	}
+	holidays := map[uuid.UUID]*contracts.ListHolidaysResponse{}
+	for _, e := range emps {
+		cal, ok := holidays[e.BranchID]
+		if !ok {
+			branchID := e.BranchID
+			cal, err = mediator.Send[*contracts.ListHolidaysQuery, *contracts.ListHolidaysResponse](ctx, &contracts.ListHolidaysQuery{
+				CompanyID: tenant.CompanyID,
+				BranchID:  &branchID,
+				From:      calFrom,
+				To:        calTo,
+			})
+			if err != nil {
+				return nil, err
+			}
+			holidays[e.BranchID] = cal
+		}
+		d := byEmp[e.ID]
+		d.emp = e
+		out = append(out, detect(opts, *d, calendar{holidays: cal, shifts: shifts})...)
+	}
+	return out, nil
+}
+
+func known(t string) bool {
+	for _, k := range Types {
+		if k == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,225 @@
+package clock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/worklog/internal/dto"
+	"hrms/modules/worklog/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/validator"
+	"hrms/shared/events"
+)
+
+type UpsertRequest struct {
+	EmployeeID uuid.UUID `json:"employeeId" validate:"required"`
+	WorkDate   string    `json:"workDate" validate:"required"`
+	ClockIn    string    `json:"clockIn" validate:"required"`
+	ClockOut   *string   `json:"clockOut"`
+	Note       *string   `json:"note" validate:"omitempty,max=500"`
+}
+
+type UpsertCommand struct {
+	Payload UpsertRequest
+}
+
+type UpsertResponse struct {
+	dto.ClockItem
+	Created bool `json:"-"`
+}
+
+type upsertHandler struct {
+	repo repository.ClockRepository
+	eb   eventbus.EventBus
+}
+
+func NewUpsertHandler(repo repository.ClockRepository, eb eventbus.EventBus) *upsertHandler {
+	return &upsertHandler{repo: repo, eb: eb}
+}
+
+// Handle records the clock times of a full-time employee for the day, replacing what was
+// recorded before. Replaced times are processed again by the next generation.
+func (h *upsertHandler) Handle(ctx context.Context, cmd *UpsertCommand) (*UpsertResponse, error) {
+	if err := validator.Validate(&cmd.Payload); err != nil {
+		return nil, err
+	}
+	workDate, err := time.Parse("2006-01-02", strings.TrimSpace(cmd.Payload.WorkDate))
+	if err != nil {
+		return nil, errs.BadRequest("workDate must be YYYY-MM-DD")
+	}
+	clockIn, clockOut, err := ParseClockTimes(workDate, cmd.Payload.ClockIn, cmd.Payload.ClockOut)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+
+	emp, err := h.repo.GetEmployee(ctx, tenant, cmd.Payload.EmployeeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.BadRequest("employee not found in this company")
+		}
+		logger.FromContext(ctx).Error("failed to load employee", zap.Error(err))
+		return nil, errs.Internal("failed to record clock times")
+	}
+	if !emp.FullTime {
+		return nil, errs.BadRequest("clock records are for full-time employees; record part-time hours in worklog PT")
+	}
+
+	var note *string
+	if cmd.Payload.Note != nil {
+		if n := strings.TrimSpace(*cmd.Payload.Note); n != "" {
+			note = &n
+		}
+	}
+	rec, inserted, err := h.repo.Upsert(ctx, repository.ClockRecord{
+		CompanyID:  tenant.CompanyID,
+		BranchID:   emp.BranchID,
+		EmployeeID: cmd.Payload.EmployeeID,
+		WorkDate:   workDate,
+		ClockIn:    clockIn,
+		ClockOut:   clockOut,
+		Source:     "manual",
+		Note:       note,
+	}, user.ID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to record clock times", zap.Error(err))
+		return nil, errs.Internal("failed to record clock times")
+	}
+
+	action := "UPDATE"
+	if inserted {
+		action = "CREATE"
+	}
+	item := dto.FromClock(*rec)
+	details := map[string]interface{}{
+		"employee_id": rec.EmployeeID.String(),
+		"work_date":   item.WorkDate,
+		"clock_in":    item.ClockIn,
+	}
+	if item.ClockOut != nil {
+		details["clock_out"] = *item.ClockOut
+	}
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   &rec.BranchID,
+		Action:     action,
+		EntityName: "WORKLOG_CLOCK",
+		EntityID:   rec.ID.String(),
+		Details:    details,
+		Timestamp:  time.Now(),
+	})
+
+	return &UpsertResponse{ClockItem: item, Created: inserted}, nil
+}
+
+// ParseClockTimes reads the clock-in and optional clock-out of workDate. A time is either
+// "YYYY-MM-DD HH:MM" or just "HH:MM" on workDate; a clock-out given as HH:MM that is not after
+// the clock-in is on the next day (overnight shift).
+func ParseClockTimes(workDate time.Time, in string, out *string) (time.Time, *time.Time, error) {
+	clockIn, _, err := parseClock(workDate, in)
+	if err != nil {
+		return time.Time{}, nil, errs.BadRequest("clockIn must be YYYY-MM-DD HH:MM or HH:MM")
+	}
+	if clockIn.Before(workDate.AddDate(0, 0, -1)) || !clockIn.Before(workDate.AddDate(0, 0, 2)) {
+		return time.Time{}, nil, errs.BadRequest("clockIn must be within a day of workDate")
+	}
+	if out == nil || strings.TrimSpace(*out) == "" {
+		return clockIn, nil, nil
+	}
+	clockOut, dated, err := parseClock(workDate, *out)
+	if err != nil {
+		return time.Time{}, nil, errs.BadRequest("clockOut must be YYYY-MM-DD HH:MM or HH:MM")
+	}
+	if !dated && !clockOut.After(clockIn) {
+		clockOut = clockOut.AddDate(0, 0, 1)
+	}
+	if !clockOut.After(clockIn) {
+		return time.Time{}, nil, errs.BadRequest("clockOut must be after clockIn")
+	}
+	if clockOut.Sub(clockIn) > 24*time.Hour {
+		return time.Time{}, nil, errs.BadRequest("clockOut must be within 24 hours of clockIn")
+	}
+	return clockIn, &clockOut, nil
+}
+
+// parseClock reads one clock time and reports whether it carried its own date.
+func parseClock(workDate time.Time, s string) (time.Time, bool, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{dto.ClockLayout, "2006-01-02T15:04", "2006-01-02T15:04:05", "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true, nil
+		}
+	}
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return time.Date(workDate.Year(), workDate.Month(), workDate.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC), false, nil
+}
+
+type DeleteCommand struct {
+	ID uuid.UUID `validate:"required"`
+}
+
+type deleteHandler struct {
+	repo repository.ClockRepository
+	eb   eventbus.EventBus
+}
+
+func NewDeleteHandler(repo repository.ClockRepository, eb eventbus.EventBus) *deleteHandler {
+	return &deleteHandler{repo: repo, eb: eb}
+}
+
+// Handle removes the clock record. Entries already generated from it stay in worklog FT.
+func (h *deleteHandler) Handle(ctx context.Context, cmd *DeleteCommand) (mediator.NoResponse, error) {
+	if err := validator.Validate(cmd); err != nil {
+		return mediator.NoResponse{}, err
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return mediator.NoResponse{}, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return mediator.NoResponse{}, errs.Unauthorized("missing user context")
+	}
+
+	if err := h.repo.SoftDelete(ctx, tenant, cmd.ID, user.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return mediator.NoResponse{}, errs.NotFound("clock record not found")
+		}
+		logger.FromContext(ctx).Error("failed to delete clock record", zap.Error(err))
+		return mediator.NoResponse{}, errs.Internal("failed to delete clock record")
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "DELETE",
+		EntityName: "WORKLOG_CLOCK",
+		EntityID:   cmd.ID.String(),
+		Timestamp:  time.Now(),
+	})
+	return mediator.NoResponse{}, nil
+}
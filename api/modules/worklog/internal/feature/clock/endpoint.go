@@ -0,0 +1,265 @@
+package clock
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/modules/worklog/internal/repository"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// Register clock endpoints
+// @Summary List clock records
+// @Description รายการเวลาเข้า-ออกงานจริงของพนักงานประจำ พร้อมนาทีสาย/ออกก่อน/OT ที่คำนวณแล้ว
+// @Tags Worklogs Clock
+// @Produce json
+// @Param page query int false "page"
+// @Param limit query int false "limit"
+// @Param employeeId query string false "employee id"
+// @Param startDate query string false "YYYY-MM-DD"
+// @Param endDate query string false "YYYY-MM-DD"
+// @Param processed query string false "yes|no"
+// @Security BearerAuth
+// @Success 200 {object} ListResponse
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /worklogs/clock [get]
+func Register(router fiber.Router) {
+	router.Get("/", func(c fiber.Ctx) error {
+		page, _ := strconv.Atoi(c.Query("page", "1"))
+		limit, _ := strconv.Atoi(c.Query("limit", "20"))
+		var f repository.ClockFilter
+		if v := c.Query("employeeId"); v != "" {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				return errs.BadRequest("invalid employeeId")
+			}
+			f.EmployeeID = &id
+		}
+		if v := c.Query("startDate"); v != "" {
+			d, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				return errs.BadRequest("invalid startDate")
+			}
+			f.StartDate = &d
+		}
+		if v := c.Query("endDate"); v != "" {
+			d, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				return errs.BadRequest("invalid endDate")
+			}
+			f.EndDate = &d
+		}
+		switch v := strings.TrimSpace(c.Query("processed")); v {
+		case "", "yes", "no":
+			f.Processed = v
+		default:
+			return errs.BadRequest("processed must be yes or no")
+		}
+
+		resp, err := mediator.Send[*ListQuery, *ListResponse](c.Context(), &ListQuery{
+			Page:   page,
+			Limit:  limit,
+			Filter: f,
+		})
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+
+	registerGenerate(router)
+	registerImport(router)
+	registerUpsert(router)
+	registerDelete(router)
+}
+
+// @Summary Record clock times
+// @Description บันทึกเวลาเข้า-ออกงานจริงของพนักงานประจำต่อวัน (มีอยู่แล้วจะแทนที่และต้องคำนวณใหม่) เวลาเป็น YYYY-MM-DD HH:MM หรือ HH:MM ของ workDate, clockOut แบบ HH:MM ที่ไม่เกิน clockIn = วันถัดไป (กะข้ามวัน)
+// @Tags Worklogs Clock
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpsertRequest true "clock payload"
+// @Success 200 {object} UpsertResponse
+// @Success 201 {object} UpsertResponse
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /worklogs/clock [post]
+func registerUpsert(router fiber.Router) {
+	router.Post("/", func(c fiber.Ctx) error {
+		var req UpsertRequest
+		if err := c.Bind().Body(&req); err != nil {
+			return errs.BadRequest("invalid request body")
+		}
+		resp, err := mediator.Send[*UpsertCommand, *UpsertResponse](c.Context(), &UpsertCommand{
+			Payload: req,
+		})
+		if err != nil {
+			return err
+		}
+		status := fiber.StatusOK
+		if resp.Created {
+			status = fiber.StatusCreated
+		}
+		return response.JSON(c, status, resp.ClockItem)
+	})
+}
+
+// @Summary Generate worklogs from clock records
+// @Description เทียบเวลาเข้า-ออกจริงกับกะของพนักงานแล้วสร้าง worklog FT สถานะ pending ให้ตรวจสอบ: late = นาทีสาย, early_leave = นาทีออกก่อน (เกินเวลาผ่อนผันของกะ), ot = ชั่วโมงที่อยู่ต่อหลังเลิกกะ (อย่างน้อย minOtMinutes) วันหยุดประจำสัปดาห์ของกะและวันหยุดบริษัท: ชั่วโมงในเวลากะบันทึกเป็น holiday_work ส่วนที่เกินเวลากะเป็น holiday_ot วันที่มีรายการประเภทนั้นอยู่แล้วจะไม่สร้างซ้ำ dryRun = แสดงผลโดยไม่บันทึก
+// @Tags Worklogs Clock
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body GenerateRequest true "generate payload"
+// @Success 200 {object} GenerateResponse
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /worklogs/clock/generate [post]
+func registerGenerate(router fiber.Router) {
+	router.Post("/generate", func(c fiber.Ctx) error {
+		var req GenerateRequest
+		if err := c.Bind().Body(&req); err != nil {
+			return errs.BadRequest("invalid request body")
+		}
+		resp, err := mediator.Send[*GenerateCommand, *GenerateResponse](c.Context(), &GenerateCommand{
+			Payload: req,
+		})
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
+
+// @Summary Import punch log from attendance devices
+// @Description นำเข้าไฟล์บันทึกการสแกนนิ้ว/ใบหน้า (ZKTeco attlog.dat หรือ CSV export, หรือ CSV ทั่วไปที่ระบุคอลัมน์) จับคู่เลขบัตรกับรหัสพนักงาน แล้วจับคู่การสแกนเป็นเวลาเข้า-ออกตามสถานะเข้า/ออกที่เครื่องบันทึก (ไฟล์ที่ไม่มีสถานะจับคู่ตามลำดับ) การสแกนออกที่ไม่มีการสแกนเข้าก่อนหน้าจะรายงานใน unpaired และไม่ถูกบันทึก (สแกนซ้ำสถานะเดียวกันภายใน 2 นาทีถือเป็นรายการซ้ำ) พนักงานพาร์ทไทม์: คู่แรก = ช่วงเช้า คู่ที่สอง = ช่วงเย็นของ worklog PT สถานะ pending, พนักงานประจำ: เข้าครั้งแรก-ออกครั้งสุดท้ายเป็นเวลาเข้า-ออกงาน แล้วสร้างรายการสาย/OT ให้ตรวจสอบ กะข้ามวันนับเป็นวันที่เข้างาน วันที่มีข้อมูลอยู่แล้วจะไม่ถูกแทนที่ เว้นแต่ overwrite (worklog PT ที่อนุมัติแล้วไม่ถูกแทนที่) dryRun = แสดงรายงานโดยไม่บันทึก
+// @Tags Worklogs Clock
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "punch log file (<=2MB)"
+// @Param format formData string false "zkteco|csv (default zkteco)"
+// @Param dateOrder formData string false "DMY|MDY|YMD สำหรับวันที่ที่ไม่ได้ขึ้นต้นด้วยปี (default DMY)"
+// @Param delimiter formData string false "ตัวคั่นคอลัมน์ของ csv (default ,)"
+// @Param hasHeader formData bool false "csv มีแถวหัวตาราง"
+// @Param badgeColumn formData string false "คอลัมน์เลขบัตร: ชื่อหัวตารางหรือลำดับคอลัมน์ (csv)"
+// @Param dateTimeColumn formData string false "คอลัมน์วันเวลา (csv)"
+// @Param dateColumn formData string false "คอลัมน์วันที่ เมื่อวันและเวลาแยกคอลัมน์ (csv)"
+// @Param timeColumn formData string false "คอลัมน์เวลา เมื่อวันและเวลาแยกคอลัมน์ (csv)"
+// @Param stateColumn formData string false "คอลัมน์สถานะเข้า/ออก เช่น 0/1, I/O, C/In, C/Out (csv, ไม่บังคับ)"
+// @Param startDate formData string false "YYYY-MM-DD นำเข้าเฉพาะวันทำงานตั้งแต่วันนี้"
+// @Param endDate formData string false "YYYY-MM-DD นำเข้าเฉพาะวันทำงานถึงวันนี้"
+// @Param overwrite formData bool false "แทนที่ข้อมูลเดิมของวันที่นำเข้า"
+// @Param dryRun formData bool false "แสดงรายงานโดยไม่บันทึก"
+// @Success 200 {object} ImportResponse
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /worklogs/clock/import [post]
+func registerImport(router fiber.Router) {
+	router.Post("/import", func(c fiber.Ctx) error {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return errs.BadRequest("file is required")
+		}
+		if fileHeader.Size <= 0 {
+			return errs.BadRequest("file is empty")
+		}
+		if fileHeader.Size > maxFileSizeBytes {
+			return errs.BadRequest("file too large (max 2MB)")
+		}
+
+		src, err := fileHeader.Open()
+		if err != nil {
+			return errs.BadRequest("cannot read file")
+		}
+		defer src.Close()
+
+		var buf bytes.Buffer
+		if _, err := buf.ReadFrom(io.LimitReader(src, maxFileSizeBytes+1)); err != nil {
+			return errs.BadRequest("cannot read file")
+		}
+		if int64(buf.Len()) > maxFileSizeBytes {
+			return errs.BadRequest("file too large (max 2MB)")
+		}
+
+		cmd := ImportCommand{
+			Data:           buf.Bytes(),
+			FileName:       strings.TrimSpace(fileHeader.Filename),
+			Format:         c.FormValue("format"),
+			DateOrder:      c.FormValue("dateOrder"),
+			Delimiter:      c.FormValue("delimiter"),
+			BadgeColumn:    c.FormValue("badgeColumn"),
+			DateTimeColumn: c.FormValue("dateTimeColumn"),
+			DateColumn:     c.FormValue("dateColumn"),
+			TimeColumn:     c.FormValue("timeColumn"),
+			StateColumn:    c.FormValue("stateColumn"),
+			StartDate:      c.FormValue("startDate"),
+			EndDate:        c.FormValue("endDate"),
+		}
+		for name, dst := range map[string]*bool{"hasHeader": &cmd.HasHeader, "overwrite": &cmd.Overwrite, "dryRun": &cmd.DryRun} {
+			if s := strings.TrimSpace(c.FormValue(name)); s != "" {
+				v, err := strconv.ParseBool(s)
+				if err != nil {
+					return errs.BadRequest("invalid " + name)
+				}
+				*dst = v
+			}
+		}
+
+		resp, err := mediator.Send[*ImportCommand, *ImportResponse](c.Context(), &cmd)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
+
+// @Summary Delete clock record
+// @Description ลบเวลาเข้า-ออกงาน (worklog ที่สร้างจากรายการนี้แล้วไม่ถูกลบ)
+// @Tags Worklogs Clock
+// @Security BearerAuth
+// @Param id path string true "clock record id"
+// @Success 204 "No Content"
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /worklogs/clock/{id} [delete]
+func registerDelete(router fiber.Router) {
+	router.Delete("/:id", func(c fiber.Ctx) error {
+		id, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return errs.BadRequest("invalid id")
+		}
+		if _, err := mediator.Send[*DeleteCommand, mediator.NoResponse](c.Context(), &DeleteCommand{
+			ID: id,
+		}); err != nil {
+			return err
+		}
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+}
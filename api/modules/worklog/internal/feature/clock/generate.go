@@ -0,0 +1,398 @@
+package clock
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/worklog/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/storage/sqldb/transactor"
+	"hrms/shared/common/validator"
+	"hrms/shared/contracts"
+	"hrms/shared/events"
+)
+
+// MaxGenerateDays caps the date range of one generation run.
+const MaxGenerateDays = 62
+
+type GenerateRequest struct {
+	StartDate  string     `json:"startDate" validate:"required"`
+	EndDate    string     `json:"endDate" validate:"required"`
+	EmployeeID *uuid.UUID `json:"employeeId"`
+	DryRun     bool       `json:"dryRun"`
+}
+
+type GenerateCommand struct {
+	Payload GenerateRequest
+}
+
+// GeneratedEntry is a pending FT entry proposed for a clock record. Result is created,
+// would_create (dry run) or exists (the employee already has that entry type on the day).
+type GeneratedEntry struct {
+	EntryType string     `json:"entryType"`
+	Quantity  float64    `json:"quantity"`
+	Result    string     `json:"result"`
+	EntryID   *uuid.UUID `json:"entryId,omitempty"`
+}
+
+type GenerateRow struct {
+	ClockID            uuid.UUID        `json:"clockId"`
+	EmployeeID         uuid.UUID        `json:"employeeId"`
+	WorkDate           string           `json:"workDate"`
+	ShiftCode          string           `json:"shiftCode,omitempty"`
+	DayType            string           `json:"dayType,omitempty"`
+	LateMinutes        int              `json:"lateMinutes"`
+	EarlyMinutes       int              `json:"earlyMinutes"`
+	OTMinutes          int              `json:"otMinutes"`
+	HolidayWorkMinutes int              `json:"holidayWorkMinutes"`
+	Entries            []GeneratedEntry `json:"entries"`
+	Notes              []string         `json:"notes,omitempty"`
+}
+
+type GenerateSummary struct {
+	Clocks   int `json:"clocks"`
+	NoShift  int `json:"noShift"`
+	Created  int `json:"created"`
+	Existing int `json:"existing"`
+}
+
+type GenerateResponse struct {
+	DryRun  bool            `json:"dryRun"`
+	Rows    []GenerateRow   `json:"rows"`
+	Summary GenerateSummary `json:"summary"`
+}
+
+// Day types of a clock record
+const (
+	dayWork    = "workday"
+	dayRest    = "rest_day"
+	dayHoliday = "holiday"
+)
+
+type generateHandler struct {
+	clockRepo repository.ClockRepository
+	ftRepo    repository.FTRepository
+	tx        transactor.Transactor
+	eb        eventbus.EventBus
+}
+
+func NewGenerateHandler(clockRepo repository.ClockRepository, ftRepo repository.FTRepository, tx transactor.Transactor, eb eventbus.EventBus) *generateHandler {
+	return &generateHandler{clockRepo: clockRepo, ftRepo: ftRepo, tx: tx, eb: eb}
+}
+
+// Handle compares the clock records in the range with the shift each employee works that day
+// and proposes pending FT entries for review: a late entry for the minutes late, an early_leave
+// entry for the minutes left early (payroll deducts both at the late rate), and an OT entry in
+// hours for the time worked past the end of the shift. On a rest day or a holiday the time worked
+// within the shift is holiday work and the time past it holiday OT.
+// A day that already has an entry of the type keeps it, so a run can be repeated safely.
+func (h *generateHandler) Handle(ctx context.Context, cmd *GenerateCommand) (*GenerateResponse, error) {
+	if err := validator.Validate(&cmd.Payload); err != nil {
+		return nil, err
+	}
+	from, err := time.Parse("2006-01-02", strings.TrimSpace(cmd.Payload.StartDate))
+	if err != nil {
+		return nil, errs.BadRequest("startDate must be YYYY-MM-DD")
+	}
+	to, err := time.Parse("2006-01-02", strings.TrimSpace(cmd.Payload.EndDate))
+	if err != nil {
+		return nil, errs.BadRequest("endDate must be YYYY-MM-DD")
+	}
+	if to.Before(from) {
+		return nil, errs.BadRequest("endDate must be on or after startDate")
+	}
+	if to.Sub(from) >= MaxGenerateDays*24*time.Hour {
+		return nil, errs.BadRequest("date range must not exceed 62 days")
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+
+	var employeeIDs []uuid.UUID
+	if cmd.Payload.EmployeeID != nil {
+		employeeIDs = []uuid.UUID{*cmd.Payload.EmployeeID}
+	}
+	resp := &GenerateResponse{DryRun: cmd.Payload.DryRun, Rows: []GenerateRow{}}
+	run := func(ctx context.Context) error {
+		rows, summary, err := h.generate(ctx, tenant, user.ID, from, to, employeeIDs, !cmd.Payload.DryRun)
+		resp.Rows, resp.Summary = rows, summary
+		return err
+	}
+	if cmd.Payload.DryRun {
+		err = run(ctx)
+	} else {
+		err = h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
+			return run(ctxTx)
+		})
+	}
+	if err != nil {
+		var appErr *errs.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		logger.FromContext(ctx).Error("failed to generate worklogs from clock records", zap.Error(err))
+		return nil, errs.Internal("failed to generate worklogs")
+	}
+
+	if !cmd.Payload.DryRun && resp.Summary.Created > 0 {
+		details := map[string]interface{}{
+			"start_date": from.Format("2006-01-02"),
+			"end_date":   to.Format("2006-01-02"),
+			"clocks":     resp.Summary.Clocks,
+			"created":    resp.Summary.Created,
+			"existing":   resp.Summary.Existing,
+			"no_shift":   resp.Summary.NoShift,
+		}
+		if cmd.Payload.EmployeeID != nil {
+			details["employee_id"] = cmd.Payload.EmployeeID.String()
+		}
+		h.eb.Publish(events.LogEvent{
+			ActorID:    user.ID,
+			CompanyID:  &tenant.CompanyID,
+			BranchID:   tenant.BranchIDPtr(),
+			Action:     "GENERATE",
+			EntityName: "WORKLOG_FT",
+			EntityID:   tenant.CompanyID.String(),
+			Details:    details,
+			Timestamp:  time.Now(),
+		})
+	}
+	return resp, nil
+}
+
+// generate evaluates the clock records of the given employees (all when empty) between from
+// and to, inserting the proposed entries when write is set.
+func (h *generateHandler) generate(ctx context.Context, tenant contextx.TenantInfo, actor uuid.UUID, from, to time.Time, employeeIDs []uuid.UUID, write bool) ([]GenerateRow, GenerateSummary, error) {
+	rows := []GenerateRow{}
+	var summary GenerateSummary
+
+	clocks, err := h.clockRepo.ListForGenerate(ctx, tenant, repository.ClockFilter{
+		EmployeeIDs: employeeIDs,
+		StartDate:   &from,
+		EndDate:     &to,
+	})
+	if err != nil || len(clocks) == 0 {
+		return rows, summary, err
+	}
+
+	var clocked []uuid.UUID
+	seen := map[uuid.UUID]bool{}
+	for _, c := range clocks {
+		if !seen[c.EmployeeID] {
+			seen[c.EmployeeID] = true
+			clocked = append(clocked, c.EmployeeID)
+		}
+	}
+	shifts, err := mediator.Send[*contracts.ResolveShiftsQuery, *contracts.ResolveShiftsResponse](ctx, &contracts.ResolveShiftsQuery{
+		CompanyID:   tenant.CompanyID,
+		EmployeeIDs: clocked,
+		From:        from,
+		To:          to,
+	})
+	if err != nil {
+		return nil, summary, err
+	}
+	holidays := map[uuid.UUID]*contracts.ListHolidaysResponse{}
+	entries, err := h.clockRepo.ListDayEntries(ctx, tenant.CompanyID, from, to)
+	if err != nil {
+		return nil, summary, err
+	}
+	existing := map[string]map[string]bool{}
+	for _, e := range entries {
+		key := dayKey(e.EmployeeID, e.WorkDate)
+		if existing[key] == nil {
+			existing[key] = map[string]bool{}
+		}
+		existing[key][e.EntryType] = true
+	}
+
+	for _, c := range clocks {
+		summary.Clocks++
+		row := GenerateRow{
+			ClockID:    c.ID,
+			EmployeeID: c.EmployeeID,
+			WorkDate:   c.WorkDate.Format("2006-01-02"),
+			Entries:    []GeneratedEntry{},
+		}
+		shift := shifts.For(c.EmployeeID, c.WorkDate)
+		if shift == nil {
+			summary.NoShift++
+			row.Notes = append(row.Notes, "no shift assigned on this day")
+			rows = append(rows, row)
+			continue
+		}
+		row.ShiftCode = shift.Code
+
+		cal, ok := holidays[c.BranchID]
+		if !ok {
+			branchID := c.BranchID
+			cal, err = mediator.Send[*contracts.ListHolidaysQuery, *contracts.ListHolidaysResponse](ctx, &contracts.ListHolidaysQuery{
+				CompanyID: tenant.CompanyID,
+				BranchID:  &branchID,
+				From:      from,
+				To:        to,
+			})
+			if err != nil {
+				return nil, summary, err
+			}
+			holidays[c.BranchID] = cal
+		}
+		row.DayType = dayWork
+		if cal.Contains(c.WorkDate) {
+			row.DayType = dayHoliday
+		} else if !shift.WorksOn(c.WorkDate) {
+			row.DayType = dayRest
+		}
+
+		ev := Evaluate(*shift, c.WorkDate, row.DayType, c.ClockIn, c.ClockOut)
+		row.LateMinutes, row.EarlyMinutes, row.OTMinutes, row.HolidayWorkMinutes = ev.Late, ev.Early, ev.OT, ev.HolidayWork
+		if c.ClockOut == nil {
+			row.Notes = append(row.Notes, "no clock-out; early leave and OT not checked")
+		}
+		dayTypes := existing[dayKey(c.EmployeeID, c.WorkDate)]
+
+		var proposed []GeneratedEntry
+		if ev.Late > 0 || ev.Early > 0 {
+			if hasLeave(dayTypes) {
+				row.Notes = append(row.Notes, "leave recorded on this day; late and early leave not generated")
+			} else {
+				if ev.Late > 0 {
+					proposed = append(proposed, GeneratedEntry{EntryType: "late", Quantity: float64(ev.Late)})
+				}
+				if ev.Early > 0 {
+					proposed = append(proposed, GeneratedEntry{EntryType: "early_leave", Quantity: float64(ev.Early)})
+				}
+			}
+		}
+		if ev.HolidayWork > 0 {
+			proposed = append(proposed, GeneratedEntry{EntryType: "holiday_work", Quantity: hours(ev.HolidayWork)})
+		}
+		if ev.OT > 0 {
+			entryType := "ot"
+			if row.DayType != dayWork {
+				entryType = "holiday_ot"
+			}
+			proposed = append(proposed, GeneratedEntry{EntryType: entryType, Quantity: hours(ev.OT)})
+		}
+
+		for _, e := range proposed {
+			switch {
+			case dayTypes[e.EntryType]:
+				e.Result = "exists"
+				summary.Existing++
+			case !write:
+				e.Result = "would_create"
+			default:
+				created, err := h.ftRepo.Insert(ctx, tenant, repository.FTRecord{
+					EmployeeID: c.EmployeeID,
+					EntryType:  e.EntryType,
+					WorkDate:   c.WorkDate,
+					Quantity:   e.Quantity,
+					Status:     "pending",
+					CreatedBy:  actor,
+					UpdatedBy:  actor,
+				})
+				if err != nil {
+					return nil, summary, err
+				}
+				e.Result = "created"
+				e.EntryID = &created.ID
+				summary.Created++
+			}
+			row.Entries = append(row.Entries, e)
+		}
+
+		if write {
+			if err := h.clockRepo.SetResult(ctx, c.ID, shift.ID, ev.Late, ev.Early, ev.OT, ev.HolidayWork, actor); err != nil {
+				return nil, summary, err
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, summary, nil
+}
+
+// Evaluation is what one clock record works out to against the shift, in minutes.
+type Evaluation struct {
+	Late        int
+	Early       int
+	OT          int
+	HolidayWork int
+}
+
+// Evaluate works out the minutes late, the minutes left early and the OT minutes of one clock
+// record against the shift. Minutes within the shift's grace are not counted, and OT shorter
+// than the shift's minimum is dropped. On a rest day or a holiday nobody is late or leaves
+// early: the time worked up to the end of the shift, less the break, is holiday work and the
+// time past it is OT. Without a clock-out only lateness can be measured.
+func Evaluate(s contracts.ShiftDTO, workDate time.Time, dayType string, clockIn time.Time, clockOut *time.Time) Evaluation {
+	var ev Evaluation
+	start, end := s.Window(workDate)
+	switch dayType {
+	case dayRest, dayHoliday:
+		if clockOut != nil {
+			within := *clockOut
+			if within.After(end) {
+				within = end
+			}
+			if worked := minutes(within.Sub(clockIn)); worked > 0 {
+				if worked > s.BreakMinutes {
+					worked -= s.BreakMinutes
+				}
+				ev.HolidayWork = worked
+			}
+			from := end
+			if clockIn.After(from) {
+				from = clockIn
+			}
+			ev.OT = minutes(clockOut.Sub(from))
+		}
+	default:
+		if d := minutes(clockIn.Sub(start)); d > s.LateGraceMinutes {
+			ev.Late = d
+		}
+		if clockOut != nil {
+			if d := minutes(end.Sub(*clockOut)); d > s.EarlyGraceMinutes {
+				ev.Early = d
+			}
+			ev.OT = minutes(clockOut.Sub(end))
+		}
+	}
+	if ev.OT <= 0 || ev.OT < s.MinOTMinutes {
+		ev.OT = 0
+	}
+	return ev
+}
+
+func minutes(d time.Duration) int {
+	return int(d / time.Minute)
+}
+
+// hours converts minutes to the hours an OT or holiday work entry is recorded in.
+func hours(m int) float64 {
+	return math.Round(float64(m)/60*100) / 100
+}
+
+func hasLeave(types map[string]bool) bool {
+	return types["leave_day"] || types["leave_double"] || types["leave_hours"] || types["leave_paid"]
+}
+
+func dayKey(employeeID uuid.UUID, d time.Time) string {
+	return employeeID.String() + "|" + d.Format("2006-01-02")
+}
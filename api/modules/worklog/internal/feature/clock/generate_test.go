@@ -0,0 +1,54 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"hrms/shared/contracts"
+)
+
+func TestEvaluate(t *testing.T) {
+	// 08:00-17:00 with an hour's break, 10 minutes grace either side, OT from 30 minutes
+	shift := contracts.ShiftDTO{
+		StartMinute:       8 * 60,
+		EndMinute:         17 * 60,
+		BreakMinutes:      60,
+		LateGraceMinutes:  10,
+		EarlyGraceMinutes: 10,
+		MinOTMinutes:      30,
+		WorkDays:          []int{1, 2, 3, 4, 5},
+	}
+	day := time.Date(2026, 2, 2, 0, 0, 0, 0, time.UTC)
+	at := func(h, m int) time.Time { return day.Add(time.Duration(h*60+m) * time.Minute) }
+	out := func(h, m int) *time.Time { t := at(h, m); return &t }
+
+	tests := []struct {
+		name    string
+		dayType string
+		in      time.Time
+		out     *time.Time
+		want    Evaluation
+	}{
+		{"on time", dayWork, at(8, 0), out(17, 0), Evaluation{}},
+		{"late within grace", dayWork, at(8, 10), out(17, 0), Evaluation{}},
+		{"late and left early", dayWork, at(8, 25), out(16, 15), Evaluation{Late: 25, Early: 45}},
+		{"OT below minimum", dayWork, at(8, 0), out(17, 29), Evaluation{}},
+		{"OT", dayWork, at(8, 0), out(19, 0), Evaluation{OT: 120}},
+		{"no clock-out", dayWork, at(9, 0), nil, Evaluation{Late: 60}},
+		{"rest day within shift", dayRest, at(8, 0), out(17, 0), Evaluation{HolidayWork: 480}},
+		{"rest day past shift", dayRest, at(8, 0), out(20, 0), Evaluation{HolidayWork: 480, OT: 180}},
+		{"rest day short, no break", dayRest, at(9, 0), out(9, 45), Evaluation{HolidayWork: 45}},
+		{"rest day late start is not late", dayRest, at(13, 0), out(17, 0), Evaluation{HolidayWork: 180}},
+		{"holiday past shift", dayHoliday, at(8, 0), out(18, 0), Evaluation{HolidayWork: 480, OT: 60}},
+		{"holiday after shift only", dayHoliday, at(18, 0), out(21, 0), Evaluation{OT: 180}},
+		{"holiday OT below minimum", dayHoliday, at(8, 0), out(17, 20), Evaluation{HolidayWork: 480}},
+		{"holiday no clock-out", dayHoliday, at(8, 0), nil, Evaluation{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Evaluate(shift, day, tt.dayType, tt.in, tt.out); got != tt.want {
+				t.Errorf("Evaluate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
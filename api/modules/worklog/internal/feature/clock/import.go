@@ -0,0 +1,612 @@
+package clock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/worklog/internal/dto"
+	"hrms/modules/worklog/internal/punch"
+	"hrms/modules/worklog/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/storage/sqldb/transactor"
+	"hrms/shared/common/validator"
+	"hrms/shared/events"
+)
+
+const maxFileSizeBytes = 2 * 1024 * 1024 // 2MB
+
+// errDryRun rolls back the import transaction of a dry run once the report is built.
+var errDryRun = errors.New("dry run")
+
+// ImportCommand imports a punch log. Format zkteco reads the ZKTeco attendance log or CSV export
+// as is; format csv needs the badge column and either a date-time column or a date and a time
+// column. StartDate and EndDate keep only the work dates in that range.
+type ImportCommand struct {
+	Data           []byte `validate:"required,min=1"`
+	FileName       string
+	Format         string `validate:"omitempty,oneof=zkteco csv"`
+	DateOrder      string `validate:"omitempty,oneof=DMY MDY YMD"`
+	Delimiter      string `validate:"omitempty,max=1"`
+	HasHeader      bool
+	BadgeColumn    string
+	DateTimeColumn string
+	DateColumn     string
+	TimeColumn     string
+	StateColumn    string
+	StartDate      string
+	EndDate        string
+	Overwrite      bool
+	DryRun         bool
+}
+
+// ImportPair is one in/out stretch read from the punches, as "YYYY-MM-DD HH:MM".
+type ImportPair struct {
+	In        string  `json:"in"`
+	Out       *string `json:"out,omitempty"`
+	Overnight bool    `json:"overnight,omitempty"`
+}
+
+// ImportDay is what the import made of one badge's punches on a work date. Kind is pt (worklog PT)
+// or ft (clock record). Result is created, updated, would_create, would_update (dry run),
+// exists (kept because overwrite is off or the worklog is approved) or skipped.
+type ImportDay struct {
+	Badge      string       `json:"badge"`
+	EmployeeID uuid.UUID    `json:"employeeId"`
+	WorkDate   string       `json:"workDate"`
+	Kind       string       `json:"kind"`
+	Pairs      []ImportPair `json:"pairs"`
+	Result     string       `json:"result"`
+	RecordID   *uuid.UUID   `json:"recordId,omitempty"`
+	Notes      []string     `json:"notes,omitempty"`
+}
+
+// UnmatchedBadge is a badge number with no employee in the company (or selected branch).
+type UnmatchedBadge struct {
+	Badge     string `json:"badge"`
+	Punches   int    `json:"punches"`
+	FirstLine int    `json:"firstLine"`
+}
+
+// ImportDuplicate is a repeated scan dropped within punch.DuplicateWindow of an earlier one.
+type ImportDuplicate struct {
+	Line   int    `json:"line"`
+	OfLine int    `json:"ofLine"`
+	Badge  string `json:"badge"`
+	Time   string `json:"time"`
+}
+
+// ImportUnpaired is an out punch with no in punch before it; it is not recorded.
+type ImportUnpaired struct {
+	Line  int    `json:"line"`
+	Badge string `json:"badge"`
+	Time  string `json:"time"`
+}
+
+// ImportError reports a line that could not be read
+type ImportError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+type ImportSummary struct {
+	Punches    int `json:"punches"`
+	Errors     int `json:"errors"`
+	Duplicates int `json:"duplicates"`
+	Unpaired   int `json:"unpaired"`
+	Unmatched  int `json:"unmatched"`
+	OutOfRange int `json:"outOfRange"`
+	Days       int `json:"days"`
+	Overnight  int `json:"overnight"`
+	Created    int `json:"created"`
+	Updated    int `json:"updated"`
+	Existing   int `json:"existing"`
+	Skipped    int `json:"skipped"`
+}
+
+// ImportResponse reports the import. Candidates are the late/OT entries generated from the
+// full-timers' clock records over the imported dates.
+type ImportResponse struct {
+	DryRun     bool              `json:"dryRun"`
+	Summary    ImportSummary     `json:"summary"`
+	Days       []ImportDay       `json:"days"`
+	Unmatched  []UnmatchedBadge  `json:"unmatched"`
+	Duplicates []ImportDuplicate `json:"duplicates"`
+	Unpaired   []ImportUnpaired  `json:"unpaired"`
+	Errors     []ImportError     `json:"errors"`
+	Candidates *GenerateResponse `json:"candidates,omitempty"`
+}
+
+type importHandler struct {
+	clockRepo repository.ClockRepository
+	ptRepo    repository.PTRepository
+	gen       *generateHandler
+	tx        transactor.Transactor
+	eb        eventbus.EventBus
+}
+
+func NewImportHandler(clockRepo repository.ClockRepository, ptRepo repository.PTRepository, ftRepo repository.FTRepository, tx transactor.Transactor, eb eventbus.EventBus) *importHandler {
+	return &importHandler{
+		clockRepo: clockRepo,
+		ptRepo:    ptRepo,
+		gen:       NewGenerateHandler(clockRepo, ftRepo, tx, eb),
+		tx:        tx,
+		eb:        eb,
+	}
+}
+
+// Handle reads the punch log, matches each badge to an employee number and pairs the punches of
+// a day into in/out stretches by the in/out state the terminal recorded (in order when the file
+// has none); an out punch with no in before it is reported as unpaired and not recorded. A
+// part-timer's first stretch becomes the morning and the second the evening of worklog PT
+// (pending); a full-timer's first in and last out become the day's clock record, from which late
+// and OT entries are then generated. A stretch that ends past midnight stays on the day it
+// started. Days already recorded are kept unless Overwrite is set, and approved PT worklogs are
+// never replaced. A dry run reports the same without saving.
+func (h *importHandler) Handle(ctx context.Context, cmd *ImportCommand) (*ImportResponse, error) {
+	cmd.Format = strings.ToLower(strings.TrimSpace(cmd.Format))
+	cmd.DateOrder = strings.ToUpper(strings.TrimSpace(cmd.DateOrder))
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+	var from, to *time.Time
+	if s := strings.TrimSpace(cmd.StartDate); s != "" {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return nil, errs.BadRequest("startDate must be YYYY-MM-DD")
+		}
+		from = &d
+	}
+	if s := strings.TrimSpace(cmd.EndDate); s != "" {
+		d, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return nil, errs.BadRequest("endDate must be YYYY-MM-DD")
+		}
+		to = &d
+	}
+	if from != nil && to != nil && to.Before(*from) {
+		return nil, errs.BadRequest("endDate must be on or after startDate")
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+
+	var (
+		punches []punch.Punch
+		err     error
+	)
+	if cmd.Format == "csv" {
+		m := punch.Mapping{
+			HasHeader:      cmd.HasHeader,
+			BadgeColumn:    cmd.BadgeColumn,
+			DateTimeColumn: cmd.DateTimeColumn,
+			DateColumn:     cmd.DateColumn,
+			TimeColumn:     cmd.TimeColumn,
+			StateColumn:    cmd.StateColumn,
+			DateOrder:      cmd.DateOrder,
+		}
+		if cmd.Delimiter != "" {
+			m.Delimiter = []rune(cmd.Delimiter)[0]
+		}
+		punches, err = punch.ParseCSV(cmd.Data, m)
+	} else {
+		punches, err = punch.ParseZKTeco(cmd.Data, cmd.DateOrder)
+	}
+	if err != nil {
+		return nil, errs.BadRequest(fmt.Sprintf("invalid punch log: %s", err.Error()))
+	}
+
+	resp := &ImportResponse{
+		DryRun:     cmd.DryRun,
+		Days:       []ImportDay{},
+		Unmatched:  []UnmatchedBadge{},
+		Duplicates: []ImportDuplicate{},
+		Unpaired:   []ImportUnpaired{},
+		Errors:     []ImportError{},
+	}
+	for _, p := range punches {
+		if p.Err != nil {
+			resp.Errors = append(resp.Errors, ImportError{Line: p.Line, Message: p.Err.Error()})
+			continue
+		}
+		resp.Summary.Punches++
+	}
+	resp.Summary.Errors = len(resp.Errors)
+
+	employees, err := h.clockRepo.ListBadgeEmployees(ctx, tenant)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to load employees for punch import", zap.Error(err))
+		return nil, errs.Internal("failed to import punch log")
+	}
+	badges := newBadgeIndex(employees)
+
+	// punches of unknown badges are reported, not paired
+	var known []punch.Punch
+	unmatched := map[string]*UnmatchedBadge{}
+	for _, p := range punches {
+		if p.Err != nil {
+			continue
+		}
+		if badges.find(p.Badge) != nil {
+			known = append(known, p)
+			continue
+		}
+		u, ok := unmatched[p.Badge]
+		if !ok {
+			u = &UnmatchedBadge{Badge: p.Badge, FirstLine: p.Line}
+			unmatched[p.Badge] = u
+		}
+		u.Punches++
+		resp.Summary.Unmatched++
+	}
+	for _, u := range unmatched {
+		resp.Unmatched = append(resp.Unmatched, *u)
+	}
+	sort.Slice(resp.Unmatched, func(i, j int) bool { return resp.Unmatched[i].FirstLine < resp.Unmatched[j].FirstLine })
+
+	days, dups, unpaired := punch.Group(known)
+	for _, d := range dups {
+		resp.Duplicates = append(resp.Duplicates, ImportDuplicate{
+			Line:   d.Line,
+			OfLine: d.OfLine,
+			Badge:  d.Badge,
+			Time:   d.Time.Format(dto.ClockLayout),
+		})
+	}
+	resp.Summary.Duplicates = len(resp.Duplicates)
+	for _, u := range unpaired {
+		resp.Unpaired = append(resp.Unpaired, ImportUnpaired{
+			Line:  u.Line,
+			Badge: u.Badge,
+			Time:  u.Time.Format(dto.ClockLayout),
+		})
+	}
+	resp.Summary.Unpaired = len(resp.Unpaired)
+
+	var inRange []punch.Day
+	for _, d := range days {
+		if (from != nil && d.WorkDate.Before(*from)) || (to != nil && d.WorkDate.After(*to)) {
+			resp.Summary.OutOfRange++
+			continue
+		}
+		inRange = append(inRange, d)
+	}
+	if len(inRange) > 0 {
+		first, last := inRange[0].WorkDate, inRange[0].WorkDate
+		for _, d := range inRange {
+			if d.WorkDate.Before(first) {
+				first = d.WorkDate
+			}
+			if d.WorkDate.After(last) {
+				last = d.WorkDate
+			}
+		}
+		if last.Sub(first) >= MaxGenerateDays*24*time.Hour {
+			return nil, errs.BadRequest("punch log covers more than 62 days; import it in parts with startDate and endDate")
+		}
+	}
+
+	err = h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
+		var (
+			ftFrom, ftTo time.Time
+			ftEmployees  []uuid.UUID
+			ftSeen       = map[uuid.UUID]bool{}
+		)
+		for _, d := range inRange {
+			emp := badges.find(d.Badge)
+			day := ImportDay{
+				Badge:      d.Badge,
+				EmployeeID: emp.ID,
+				WorkDate:   d.WorkDate.Format("2006-01-02"),
+				Kind:       "pt",
+				Pairs:      []ImportPair{},
+			}
+			if emp.FullTime {
+				day.Kind = "ft"
+			}
+			for _, p := range d.Pairs {
+				ip := ImportPair{In: p.In.Format(dto.ClockLayout), Overnight: p.Overnight()}
+				if p.Out != nil {
+					out := p.Out.Format(dto.ClockLayout)
+					ip.Out = &out
+				}
+				if ip.Overnight {
+					resp.Summary.Overnight++
+				}
+				day.Pairs = append(day.Pairs, ip)
+			}
+
+			switch {
+			case emp.EmploymentEndDate != nil && d.WorkDate.After(*emp.EmploymentEndDate):
+				day.Result = "skipped"
+				day.Notes = append(day.Notes, "after the employee's employment end date")
+			case emp.FullTime:
+				if err := h.importClock(ctxTx, tenant, user.ID, emp, d, cmd, &day); err != nil {
+					return err
+				}
+				if day.Result != "skipped" {
+					if !ftSeen[emp.ID] {
+						ftSeen[emp.ID] = true
+						ftEmployees = append(ftEmployees, emp.ID)
+					}
+					if ftFrom.IsZero() || d.WorkDate.Before(ftFrom) {
+						ftFrom = d.WorkDate
+					}
+					if d.WorkDate.After(ftTo) {
+						ftTo = d.WorkDate
+					}
+				}
+			default:
+				if err := h.importPT(ctxTx, tenant, user.ID, d, cmd, &day); err != nil {
+					return err
+				}
+			}
+
+			switch day.Result {
+			case "created", "would_create":
+				resp.Summary.Created++
+			case "updated", "would_update":
+				resp.Summary.Updated++
+			case "exists":
+				resp.Summary.Existing++
+			default:
+				resp.Summary.Skipped++
+			}
+			resp.Days = append(resp.Days, day)
+		}
+		resp.Summary.Days = len(resp.Days)
+
+		if len(ftEmployees) > 0 {
+			rows, summary, err := h.gen.generate(ctxTx, tenant, user.ID, ftFrom, ftTo, ftEmployees, !cmd.DryRun)
+			if err != nil {
+				return err
+			}
+			resp.Candidates = &GenerateResponse{DryRun: cmd.DryRun, Rows: rows, Summary: summary}
+		}
+		if cmd.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		var appErr *errs.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		logger.FromContext(ctx).Error("failed to import punch log", zap.Error(err))
+		return nil, errs.Internal("failed to import punch log")
+	}
+
+	if !cmd.DryRun {
+		details := map[string]interface{}{
+			"file_name":  cmd.FileName,
+			"punches":    resp.Summary.Punches,
+			"days":       resp.Summary.Days,
+			"created":    resp.Summary.Created,
+			"updated":    resp.Summary.Updated,
+			"existing":   resp.Summary.Existing,
+			"skipped":    resp.Summary.Skipped,
+			"unmatched":  resp.Summary.Unmatched,
+			"duplicates": resp.Summary.Duplicates,
+			"unpaired":   resp.Summary.Unpaired,
+			"errors":     resp.Summary.Errors,
+		}
+		if resp.Candidates != nil {
+			details["entries_created"] = resp.Candidates.Summary.Created
+		}
+		h.eb.Publish(events.LogEvent{
+			ActorID:    user.ID,
+			CompanyID:  &tenant.CompanyID,
+			BranchID:   tenant.BranchIDPtr(),
+			Action:     "IMPORT",
+			EntityName: "WORKLOG_CLOCK",
+			EntityID:   tenant.CompanyID.String(),
+			Details:    details,
+			Timestamp:  time.Now(),
+		})
+	}
+	return resp, nil
+}
+
+// importClock records a full-timer's first in and last out of the day as the clock record.
+func (h *importHandler) importClock(ctx context.Context, tenant contextx.TenantInfo, actor uuid.UUID, emp *repository.BadgeEmployee, d punch.Day, cmd *ImportCommand, day *ImportDay) error {
+	clockIn := d.Pairs[0].In
+	var clockOut *time.Time
+	for i := len(d.Pairs) - 1; i >= 0; i-- {
+		if out := d.Pairs[i].Out; out != nil && out.Sub(clockIn) <= 24*time.Hour {
+			clockOut = out
+			break
+		}
+	}
+	if clockOut == nil {
+		day.Notes = append(day.Notes, "no clock-out punch")
+	}
+	if len(d.Pairs) > 1 {
+		day.Notes = append(day.Notes, "several in/out pairs; first in and last out recorded")
+	}
+
+	current, err := h.clockRepo.GetByEmployeeDate(ctx, emp.ID, d.WorkDate)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if current != nil && !cmd.Overwrite {
+		day.Result = "exists"
+		day.RecordID = &current.ID
+		return nil
+	}
+
+	note := "imported from punch log"
+	rec, inserted, err := h.clockRepo.Upsert(ctx, repository.ClockRecord{
+		CompanyID:  tenant.CompanyID,
+		BranchID:   emp.BranchID,
+		EmployeeID: emp.ID,
+		WorkDate:   d.WorkDate,
+		ClockIn:    clockIn,
+		ClockOut:   clockOut,
+		Source:     "device",
+		Note:       &note,
+	}, actor)
+	if err != nil {
+		return err
+	}
+	day.Result = result(inserted, cmd.DryRun)
+	if !cmd.DryRun {
+		day.RecordID = &rec.ID
+	}
+	return nil
+}
+
+// importPT records a part-timer's first two complete pairs as the morning and evening.
+func (h *importHandler) importPT(ctx context.Context, tenant contextx.TenantInfo, actor uuid.UUID, d punch.Day, cmd *ImportCommand, day *ImportDay) error {
+	var complete []punch.Pair
+	for _, p := range d.Pairs {
+		if p.Out == nil {
+			day.Notes = append(day.Notes, fmt.Sprintf("punch at %s has no out punch; not recorded", p.In.Format("15:04")))
+			continue
+		}
+		complete = append(complete, p)
+	}
+	if len(complete) == 0 {
+		day.Result = "skipped"
+		return nil
+	}
+	if len(complete) > 2 {
+		day.Notes = append(day.Notes, fmt.Sprintf("%d in/out pairs; only the first two recorded", len(complete)))
+		complete = complete[:2]
+	}
+
+	rec := repository.PTRecord{
+		EmployeeID: day.EmployeeID,
+		WorkDate:   d.WorkDate,
+		Status:     "pending",
+		CreatedBy:  actor,
+		UpdatedBy:  actor,
+	}
+	rec.MorningIn, rec.MorningOut = clockTime(complete[0].In), clockTime(*complete[0].Out)
+	if len(complete) == 2 {
+		rec.EveningIn, rec.EveningOut = clockTime(complete[1].In), clockTime(*complete[1].Out)
+	}
+
+	current, err := h.ptRepo.GetByEmployeeDate(ctx, day.EmployeeID, d.WorkDate)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	switch {
+	case current == nil:
+		created, err := h.ptRepo.Insert(ctx, tenant, rec)
+		if err != nil {
+			return err
+		}
+		day.Result = result(true, cmd.DryRun)
+		if !cmd.DryRun {
+			day.RecordID = &created.ID
+		}
+	case current.Status == "approved":
+		day.Result = "exists"
+		day.RecordID = &current.ID
+		if cmd.Overwrite {
+			day.Notes = append(day.Notes, "worklog is approved; not replaced")
+		}
+	case !cmd.Overwrite:
+		day.Result = "exists"
+		day.RecordID = &current.ID
+	default:
+		if _, err := h.ptRepo.Update(ctx, tenant, current.ID, rec); err != nil {
+			return err
+		}
+		day.Result = result(false, cmd.DryRun)
+		day.RecordID = &current.ID
+	}
+	return nil
+}
+
+func result(inserted, dryRun bool) string {
+	switch {
+	case inserted && dryRun:
+		return "would_create"
+	case inserted:
+		return "created"
+	case dryRun:
+		return "would_update"
+	default:
+		return "updated"
+	}
+}
+
+func clockTime(t time.Time) *string {
+	s := t.Format("15:04")
+	return &s
+}
+
+// badgeIndex matches badge numbers to employee numbers, ignoring case, and ignoring leading
+// zeros when both are numeric (terminals often pad or strip them). A badge that matches more
+// than one employee only by its digits is not matched.
+type badgeIndex struct {
+	exact   map[string]*repository.BadgeEmployee
+	numeric map[string]*repository.BadgeEmployee
+}
+
+func newBadgeIndex(employees []repository.BadgeEmployee) badgeIndex {
+	idx := badgeIndex{
+		exact:   map[string]*repository.BadgeEmployee{},
+		numeric: map[string]*repository.BadgeEmployee{},
+	}
+	ambiguous := map[string]bool{}
+	for i := range employees {
+		e := &employees[i]
+		no := strings.ToLower(strings.TrimSpace(e.EmployeeNumber))
+		idx.exact[no] = e
+		if key, ok := numericKey(no); ok {
+			if _, seen := idx.numeric[key]; seen {
+				ambiguous[key] = true
+			}
+			idx.numeric[key] = e
+		}
+	}
+	for key := range ambiguous {
+		delete(idx.numeric, key)
+	}
+	return idx
+}
+
+func (idx badgeIndex) find(badge string) *repository.BadgeEmployee {
+	badge = strings.ToLower(strings.TrimSpace(badge))
+	if e, ok := idx.exact[badge]; ok {
+		return e
+	}
+	if key, ok := numericKey(badge); ok {
+		return idx.numeric[key]
+	}
+	return nil
+}
+
+func numericKey(s string) (string, bool) {
+	if s == "" {
+		return "", false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return "", false
+		}
+	}
+	if k := strings.TrimLeft(s, "0"); k != "" {
+		return k, true
+	}
+	return "0", true
+}
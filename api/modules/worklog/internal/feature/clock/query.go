@@ -0,0 +1,72 @@
+package clock
+
+import (
+	"context"
+	"math"
+
+	"go.uber.org/zap"
+
+	"hrms/modules/worklog/internal/dto"
+	"hrms/modules/worklog/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+)
+
+type ListQuery struct {
+	Page   int
+	Limit  int
+	Filter repository.ClockFilter
+}
+
+type ListResponse struct {
+	Data []dto.ClockItem `json:"data"`
+	Meta struct {
+		CurrentPage int `json:"currentPage"`
+		TotalPages  int `json:"totalPages"`
+		TotalItems  int `json:"totalItems"`
+	} `json:"meta"`
+}
+
+type listHandler struct {
+	repo repository.ClockRepository
+}
+
+func NewListHandler(repo repository.ClockRepository) *listHandler {
+	return &listHandler{repo: repo}
+}
+
+func (h *listHandler) Handle(ctx context.Context, q *ListQuery) (*ListResponse, error) {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.Limit <= 0 || q.Limit > 1000 {
+		q.Limit = 1000
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+
+	res, err := h.repo.List(ctx, tenant, q.Page, q.Limit, q.Filter)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list clock records", zap.Error(err))
+		return nil, errs.Internal("failed to list clock records")
+	}
+
+	data := make([]dto.ClockItem, 0, len(res.Rows))
+	for _, r := range res.Rows {
+		data = append(data, dto.FromClock(r))
+	}
+	totalPages := int(math.Ceil(float64(res.Total) / float64(q.Limit)))
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	resp := &ListResponse{Data: data}
+	resp.Meta.CurrentPage = q.Page
+	resp.Meta.TotalPages = totalPages
+	resp.Meta.TotalItems = res.Total
+	return resp, nil
+}
@@ -0,0 +1,191 @@
+package ft
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/worklog/internal/bulk"
+	"hrms/modules/worklog/internal/dto"
+	"hrms/modules/worklog/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/storage/sqldb/transactor"
+	"hrms/shared/common/validator"
+	"hrms/shared/events"
+)
+
+// Bulk operations
+const (
+	BulkCreate  = "create"
+	BulkUpdate  = "update"
+	BulkApprove = "approve"
+	BulkDelete  = "delete"
+)
+
+type BulkCreateRequest struct {
+	Items []CreateRequest `json:"items" validate:"required,min=1,max=500"`
+}
+
+// BulkUpdateItem is one row of a bulk update: the worklog id and the fields to change.
+type BulkUpdateItem struct {
+	ID uuid.UUID `json:"id" validate:"required"`
+	UpdateRequest
+}
+
+type BulkUpdateRequest struct {
+	Items []BulkUpdateItem `json:"items" validate:"required,min=1,max=500"`
+}
+
+type BulkIDsRequest struct {
+	IDs []uuid.UUID `json:"ids" validate:"required,min=1,max=500"`
+}
+
+// BulkCommand runs one operation over many worklogs: Creates for create, Updates for update
+// and IDs for approve and delete.
+type BulkCommand struct {
+	Op      string `validate:"required,oneof=create update approve delete"`
+	Creates []CreateRequest
+	Updates []BulkUpdateItem
+	IDs     []uuid.UUID
+}
+
+// BulkResult is the outcome of one row, in request order. Item is the worklog as saved (as it
+// was, for delete); Error is set when the row failed.
+type BulkResult struct {
+	Index int         `json:"index"`
+	ID    *uuid.UUID  `json:"id,omitempty"`
+	Item  *dto.FTItem `json:"item,omitempty"`
+	Error *bulk.Error `json:"error,omitempty"`
+}
+
+type BulkResponse struct {
+	Summary bulk.Summary `json:"summary"`
+	Results []BulkResult `json:"results"`
+}
+
+type bulkHandler struct {
+	repo repository.FTRepository
+	tx   transactor.Transactor
+	eb   eventbus.EventBus
+}
+
+func NewBulkHandler(repo repository.FTRepository, tx transactor.Transactor, eb eventbus.EventBus) *bulkHandler {
+	return &bulkHandler{repo: repo, tx: tx, eb: eb}
+}
+
+// Handle applies the operation to every row in one transaction with the same checks as the
+// single-row endpoints. Every row is checked; if any fails nothing is saved and the per-row
+// results come back in an unprocessable error. The activity log gets one entry for the batch.
+func (h *bulkHandler) Handle(ctx context.Context, cmd *BulkCommand) (*BulkResponse, error) {
+	if err := validator.Validate(cmd); err != nil {
+		return nil, err
+	}
+	var n int
+	switch cmd.Op {
+	case BulkCreate:
+		n = len(cmd.Creates)
+	case BulkUpdate:
+		n = len(cmd.Updates)
+	default:
+		n = len(cmd.IDs)
+	}
+	if n == 0 {
+		return nil, errs.BadRequest("no rows to " + cmd.Op)
+	}
+	if n > bulk.MaxItems {
+		return nil, errs.BadRequest("at most 500 rows per request")
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+	user, ok := contextx.UserFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing user context")
+	}
+
+	results := make([]BulkResult, n)
+	rowErrs, summary, err := bulk.Run(ctx, h.tx, n, func(ctx context.Context, i int) error {
+		results[i].Index = i
+		var (
+			rec *repository.FTRecord
+			err error
+		)
+		switch cmd.Op {
+		case BulkCreate:
+			p := cmd.Creates[i]
+			p.EntryType = strings.TrimSpace(p.EntryType)
+			if err := validator.Validate(&p); err != nil {
+				return err
+			}
+			rec, err = createEntry(ctx, h.repo, tenant, user.ID, p)
+		case BulkUpdate:
+			p := cmd.Updates[i]
+			results[i].ID = &cmd.Updates[i].ID
+			p.EntryType = strings.TrimSpace(p.EntryType)
+			p.Status = strings.TrimSpace(p.Status)
+			if err := validator.Validate(&p); err != nil {
+				return err
+			}
+			_, rec, err = updateEntry(ctx, h.repo, tenant, user.ID, p.ID, p.UpdateRequest)
+		case BulkApprove:
+			results[i].ID = &cmd.IDs[i]
+			_, rec, err = updateEntry(ctx, h.repo, tenant, user.ID, cmd.IDs[i], UpdateRequest{Status: "approved"})
+		case BulkDelete:
+			results[i].ID = &cmd.IDs[i]
+			rec, err = deleteEntry(ctx, h.repo, tenant, user.ID, cmd.IDs[i])
+		}
+		if err != nil {
+			return err
+		}
+		item := dto.FromFT(*rec)
+		results[i].ID = &item.ID
+		results[i].Item = &item
+		return nil
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to run bulk worklog operation", zap.String("op", cmd.Op), zap.Error(err))
+		return nil, errs.Internal("failed to " + cmd.Op + " worklogs")
+	}
+	for i, e := range rowErrs {
+		results[i].Error = e
+	}
+	resp := &BulkResponse{Summary: summary, Results: results}
+	if summary.Failed > 0 {
+		// rows that passed were rolled back with the rest
+		for i := range resp.Results {
+			resp.Results[i].Item = nil
+			if cmd.Op == BulkCreate {
+				resp.Results[i].ID = nil
+			}
+		}
+		return nil, bulk.FailedError(summary, resp)
+	}
+
+	ids := make([]string, 0, n)
+	for _, r := range results {
+		ids = append(ids, r.ID.String())
+	}
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "BULK_" + strings.ToUpper(cmd.Op),
+		EntityName: "WORKLOG_FT",
+		EntityID:   tenant.CompanyID.String(),
+		Details: map[string]interface{}{
+			"count": n,
+			"ids":   ids,
+		},
+		Timestamp: time.Now(),
+	})
+
+	return resp, nil
+}
@@ -0,0 +1,105 @@
+package ft
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/worklog/internal/bulk"
+	"hrms/modules/worklog/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/eventbus"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/storage/sqldb/dbtest"
+	"hrms/shared/common/storage/sqldb/transactor"
+	"hrms/shared/contracts"
+	"hrms/shared/events"
+)
+
+// recordingBus keeps published events instead of dispatching them.
+type recordingBus struct{ events []eventbus.Event }
+
+func (b *recordingBus) Publish(e eventbus.Event)           { b.events = append(b.events, e) }
+func (b *recordingBus) Subscribe(string, eventbus.Handler) {}
+
+// TestBulkAllOrNothing checks that one bad row keeps the whole batch out, that a clean batch is
+// saved and approved, and that each batch is logged once. It needs a migrated database in
+// TEST_DB_DSN; the fixtures are rolled back.
+func TestBulkAllOrNothing(t *testing.T) {
+	d := dbtest.Open(t)
+	mediator.Register[*contracts.ListHolidaysQuery, *contracts.ListHolidaysResponse](noHolidays{})
+
+	d.Rollback(t, func(ctx context.Context, db transactor.DBTX) {
+		branchID := d.InsertBranch(ctx, t, db, "BULK-TEST")
+		employeeID := d.InsertEmployee(ctx, t, db, branchID, dbtest.Employee{Number: "BULK-TEST-001", BasePay: 30000})
+		ctx = contextx.WithUser(d.BranchContext(ctx, branchID), contextx.UserInfo{ID: d.AdminID, Username: "admin", Role: "admin"})
+		eb := &recordingBus{}
+		h := NewBulkHandler(repository.NewFTRepository(d.DBTX), d.Tx, eb)
+		count := func() int {
+			var n int
+			if err := db.GetContext(ctx, &n, `SELECT count(*) FROM worklog_ft WHERE employee_id = $1 AND deleted_at IS NULL`, employeeID); err != nil {
+				t.Fatalf("count worklogs: %v", err)
+			}
+			return n
+		}
+
+		rows := []CreateRequest{
+			{EmployeeID: employeeID, EntryType: "late", WorkDate: "2099-01-05", Quantity: 15},
+			{EmployeeID: uuid.New(), EntryType: "late", WorkDate: "2099-01-05", Quantity: 15}, // not an employee
+			{EmployeeID: employeeID, EntryType: "ot", WorkDate: "2099-01-06", Quantity: 2},
+		}
+		_, err := h.Handle(ctx, &BulkCommand{Op: BulkCreate, Creates: rows})
+		var appErr *errs.AppError
+		if !errors.As(err, &appErr) || appErr.Code != errs.CodeUnprocessable {
+			t.Fatalf("create with a bad row: error = %v, want unprocessable", err)
+		}
+		failed, _ := appErr.Detail.(*BulkResponse)
+		if failed == nil || failed.Summary != (bulk.Summary{Total: 3, Succeeded: 2, Failed: 1}) {
+			t.Fatalf("detail = %+v, want 2 of 3 rows passing", appErr.Detail)
+		}
+		if e := failed.Results[1].Error; e == nil || e.Code != string(errs.CodeBadRequest) || failed.Results[0].Error != nil {
+			t.Errorf("row results = %+v, want only row 1 failing", failed.Results)
+		}
+		if n := count(); n != 0 {
+			t.Errorf("%d worklogs saved from a failed batch, want none", n)
+		}
+
+		created, err := h.Handle(ctx, &BulkCommand{Op: BulkCreate, Creates: []CreateRequest{rows[0], rows[2]}})
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if n := count(); n != 2 {
+			t.Fatalf("%d worklogs saved, want 2", n)
+		}
+		ids := []uuid.UUID{*created.Results[0].ID, *created.Results[1].ID}
+		approved, err := h.Handle(ctx, &BulkCommand{Op: BulkApprove, IDs: ids})
+		if err != nil {
+			t.Fatalf("approve: %v", err)
+		}
+		for _, r := range approved.Results {
+			if r.Item == nil || r.Item.Status != "approved" {
+				t.Errorf("approve result = %+v, want the approved worklog", r)
+			}
+		}
+		// approved worklogs cannot be deleted, so the batch is refused as a whole
+		if _, err := h.Handle(ctx, &BulkCommand{Op: BulkDelete, IDs: ids}); !errors.As(err, &appErr) || appErr.Code != errs.CodeUnprocessable {
+			t.Errorf("delete approved: error = %v, want unprocessable", err)
+		}
+		if n := count(); n != 2 {
+			t.Errorf("%d worklogs left after a refused delete, want 2", n)
+		}
+
+		if len(eb.events) != 2 {
+			t.Fatalf("published %d events, want one per saved batch", len(eb.events))
+		}
+		for i, action := range []string{"BULK_CREATE", "BULK_APPROVE"} {
+			e, ok := eb.events[i].(events.LogEvent)
+			if !ok || e.Action != action || e.Details["count"] != 2 {
+				t.Errorf("event %d = %+v, want %s of 2 worklogs", i, eb.events[i], action)
+			}
+		}
+	})
+}
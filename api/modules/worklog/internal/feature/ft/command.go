@@ -23,10 +23,11 @@ import (
 )
 
 type CreateRequest struct {
-	EmployeeID uuid.UUID `json:"employeeId" validate:"required"`
-	EntryType  string    `json:"entryType" validate:"required,oneof=late leave_day leave_double leave_hours ot"`
-	WorkDate   string    `json:"workDate" validate:"required"`
-	Quantity   float64   `json:"quantity" validate:"required,gt=0"`
+	EmployeeID  uuid.UUID  `json:"employeeId" validate:"required"`
+	EntryType   string     `json:"entryType" validate:"required,oneof=late early_leave leave_day leave_double leave_hours leave_paid ot holiday_work holiday_ot"`
+	WorkDate    string     `json:"workDate" validate:"required"`
+	Quantity    float64    `json:"quantity" validate:"required,gt=0"`
+	LeaveTypeID *uuid.UUID `json:"leaveTypeId"`
 }
 
 type CreateCommand struct {
@@ -63,41 +64,11 @@ func (h *createHandler) Handle(ctx context.Context, cmd *CreateCommand) (*Create
 		return nil, errs.Unauthorized("missing user context")
 	}
 
-	parsedDate, err := parseDate(cmd.Payload.WorkDate)
-	if err != nil {
-		return nil, err
-	}
-	entryType := cmd.Payload.EntryType
-
-	rec := repository.FTRecord{
-		EmployeeID: cmd.Payload.EmployeeID,
-		EntryType:  entryType,
-		WorkDate:   parsedDate,
-		Quantity:   cmd.Payload.Quantity,
-		Status:     "pending",
-		CreatedBy:  user.ID,
-		UpdatedBy:  user.ID,
-	}
-
 	var created *repository.FTRecord
 	if err := h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
 		var err error
-		exists, err := h.repo.ExistsActiveByEmployeeDateType(ctxTx, rec.EmployeeID, rec.WorkDate, rec.EntryType, nil)
-		if err != nil {
-			return err
-		}
-		if exists {
-			return errs.Conflict("worklog already exists for this employee, date, and entryType")
-		}
-
-		created, err = h.repo.Insert(ctxTx, tenant, rec)
-		if err != nil {
-			if repository.IsUniqueErrFT(err) {
-				return errs.Conflict("worklog already exists for this employee, date, and entryType")
-			}
-			return err
-		}
-		return nil
+		created, err = createEntry(ctxTx, h.repo, tenant, user.ID, cmd.Payload)
+		return err
 	}); err != nil {
 		var appErr *errs.AppError
 		if errors.As(err, &appErr) {
@@ -108,6 +79,16 @@ func (h *createHandler) Handle(ctx context.Context, cmd *CreateCommand) (*Create
 		return nil, errs.Internal("failed to create worklog")
 	}
 
+	details := map[string]interface{}{
+		"employee_id": created.EmployeeID.String(),
+		"work_date":   created.WorkDate.Format("2006-01-02"),
+		"entry_type":  created.EntryType,
+		"quantity":    created.Quantity,
+	}
+	if created.LeaveTypeID != nil {
+		details["leave_type_id"] = created.LeaveTypeID.String()
+	}
+
 	h.eb.Publish(events.LogEvent{
 		ActorID:    user.ID,
 		CompanyID:  &tenant.CompanyID,
@@ -115,18 +96,63 @@ func (h *createHandler) Handle(ctx context.Context, cmd *CreateCommand) (*Create
 		Action:     "CREATE",
 		EntityName: "WORKLOG_FT",
 		EntityID:   created.ID.String(),
-		Details: map[string]interface{}{
-			"employee_id": created.EmployeeID.String(),
-			"work_date":   created.WorkDate.Format("2006-01-02"),
-			"entry_type":  created.EntryType,
-			"quantity":    created.Quantity,
-		},
-		Timestamp: time.Now(),
+		Details:    details,
+		Timestamp:  time.Now(),
 	})
 
 	return &CreateResponse{FTItem: dto.FromFT(*created)}, nil
 }
 
+// createEntry checks one new entry against the holiday calendar, the entries already recorded
+// and the leave balance, then inserts it as pending. ctx carries the transaction.
+func createEntry(ctx context.Context, repo repository.FTRepository, tenant contextx.TenantInfo, actor uuid.UUID, p CreateRequest) (*repository.FTRecord, error) {
+	parsedDate, err := parseDate(p.WorkDate)
+	if err != nil {
+		return nil, err
+	}
+	branchID, err := repo.EmployeeBranchID(ctx, tenant, p.EmployeeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.BadRequest("employee not found in this company")
+		}
+		return nil, err
+	}
+	entryType, err := checkHoliday(ctx, tenant.CompanyID, branchID, p.EmployeeID, parsedDate, p.EntryType)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := repository.FTRecord{
+		EmployeeID:  p.EmployeeID,
+		EntryType:   entryType,
+		WorkDate:    parsedDate,
+		Quantity:    p.Quantity,
+		Status:      "pending",
+		LeaveTypeID: p.LeaveTypeID,
+		CreatedBy:   actor,
+		UpdatedBy:   actor,
+	}
+	exists, err := repo.ExistsActiveByEmployeeDateType(ctx, rec.EmployeeID, rec.WorkDate, rec.EntryType, nil)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errs.Conflict("worklog already exists for this employee, date, and entryType")
+	}
+	if err := checkLeave(ctx, tenant.CompanyID, rec.EmployeeID, rec.LeaveTypeID, rec.EntryType, rec.WorkDate, rec.Quantity, nil); err != nil {
+		return nil, err
+	}
+
+	created, err := repo.Insert(ctx, tenant, rec)
+	if err != nil {
+		if repository.IsUniqueErrFT(err) {
+			return nil, errs.Conflict("worklog already exists for this employee, date, and entryType")
+		}
+		return nil, err
+	}
+	return created, nil
+}
+
 func parseDate(dateStr string) (time.Time, error) {
 	parsedDate, err := time.Parse("2006-01-02", strings.TrimSpace(dateStr))
 	if err != nil {
@@ -136,10 +162,11 @@ func parseDate(dateStr string) (time.Time, error) {
 }
 
 type UpdateRequest struct {
-	EntryType string   `json:"entryType" validate:"omitempty,oneof=late leave_day leave_double leave_hours ot"`
-	WorkDate  string   `json:"workDate"`
-	Quantity  *float64 `json:"quantity" validate:"omitempty,gt=0"`
-	Status    string   `json:"status" validate:"omitempty,oneof=pending approved"`
+	EntryType   string     `json:"entryType" validate:"omitempty,oneof=late early_leave leave_day leave_double leave_hours leave_paid ot holiday_work holiday_ot"`
+	WorkDate    string     `json:"workDate"`
+	Quantity    *float64   `json:"quantity" validate:"omitempty,gt=0"`
+	Status      string     `json:"status" validate:"omitempty,oneof=pending approved"`
+	LeaveTypeID *uuid.UUID `json:"leaveTypeId"`
 }
 
 type UpdateCommand struct {
@@ -178,94 +205,129 @@ func (h *updateHandler) Handle(ctx context.Context, cmd *UpdateCommand) (*Update
 		return nil, errs.Unauthorized("missing user context")
 	}
 
-	current, err := h.repo.Get(ctx, tenant, cmd.ID)
+	var current, updated *repository.FTRecord
+	err := h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
+		var err error
+		current, updated, err = updateEntry(ctxTx, h.repo, tenant, user.ID, cmd.ID, cmd.Payload)
+		return err
+	})
+	if err != nil {
+		var appErr *errs.AppError
+		if errors.As(err, &appErr) {
+			logger.FromContext(ctx).Warn("failed to update worklog", zap.Error(err))
+			return nil, err
+		}
+		logger.FromContext(ctx).Error("failed to update worklog", zap.Error(err))
+		return nil, errs.Internal("failed to update worklog")
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "UPDATE",
+		EntityName: "WORKLOG_FT",
+		EntityID:   updated.ID.String(),
+		Details:    updateDetails(current, updated),
+		Timestamp:  time.Now(),
+	})
+
+	return &UpdateResponse{FTItem: dto.FromFT(*updated)}, nil
+}
+
+// updateEntry applies the changes in p to the entry and returns it before and after. An approved
+// entry cannot go back to pending; a new type or date is checked against the holiday calendar
+// and the entries already recorded, and a changed leave against the balance. ctx carries the
+// transaction.
+func updateEntry(ctx context.Context, repo repository.FTRepository, tenant contextx.TenantInfo, actor uuid.UUID, id uuid.UUID, p UpdateRequest) (*repository.FTRecord, *repository.FTRecord, error) {
+	current, err := repo.Get(ctx, tenant, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errs.NotFound("worklog not found")
+			return nil, nil, errs.NotFound("worklog not found")
 		}
-		logger.FromContext(ctx).Error("failed to load worklog", zap.Error(err))
-		return nil, errs.Internal("failed to load worklog")
+		return nil, nil, err
 	}
 
-	entryType, workDate, quantity, status, err := normalizeUpdatePayload(&cmd.Payload, current)
+	entryType, workDate, quantity, status, err := normalizeUpdatePayload(&p, current)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// only allow delete/update on pending; status transitions pending->approved allowed; approved cannot change status back
 	if current.Status == "approved" && status != "approved" {
-		return nil, errs.BadRequest("cannot revert approved worklog")
+		return nil, nil, errs.BadRequest("cannot revert approved worklog")
 	}
 
-	rec := repository.FTRecord{
-		EntryType: entryType,
-		WorkDate:  workDate,
-		Quantity:  quantity,
-		Status:    status,
-		UpdatedBy: user.ID,
+	moved := entryType != current.EntryType || !workDate.Equal(current.WorkDate)
+	if moved {
+		entryType, err = checkHoliday(ctx, current.CompanyID, current.BranchID, current.EmployeeID, workDate, entryType)
+		if err != nil {
+			return nil, nil, err
+		}
+		moved = entryType != current.EntryType || !workDate.Equal(current.WorkDate)
 	}
 
-	var updated *repository.FTRecord
-	err = h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
-		var err error
-		if entryType != current.EntryType || !workDate.Equal(current.WorkDate) {
-			exists, err := h.repo.ExistsActiveByEmployeeDateType(ctxTx, current.EmployeeID, workDate, entryType, &cmd.ID)
-			if err != nil {
-				return err
-			}
-			if exists {
-				return errs.Conflict("worklog already exists for this employee, date, and entryType")
-			}
-		}
+	leaveTypeID := current.LeaveTypeID
+	if p.LeaveTypeID != nil {
+		leaveTypeID = p.LeaveTypeID
+	} else if !isLeave(entryType) {
+		leaveTypeID = nil
+	}
 
-		updated, err = h.repo.Update(ctxTx, tenant, cmd.ID, rec)
+	if moved {
+		exists, err := repo.ExistsActiveByEmployeeDateType(ctx, current.EmployeeID, workDate, entryType, &id)
 		if err != nil {
-			if repository.IsUniqueErrFT(err) {
-				return errs.Conflict("worklog already exists for this employee, date, and entryType")
-			}
-			return err
+			return nil, nil, err
+		}
+		if exists {
+			return nil, nil, errs.Conflict("worklog already exists for this employee, date, and entryType")
+		}
+	}
+	if moved || quantity != current.Quantity || !sameLeaveType(leaveTypeID, current.LeaveTypeID) {
+		if err := checkLeave(ctx, current.CompanyID, current.EmployeeID, leaveTypeID, entryType, workDate, quantity, &id); err != nil {
+			return nil, nil, err
 		}
-		return nil
+	}
+
+	updated, err := repo.Update(ctx, tenant, id, repository.FTRecord{
+		EntryType:   entryType,
+		WorkDate:    workDate,
+		Quantity:    quantity,
+		Status:      status,
+		LeaveTypeID: leaveTypeID,
+		UpdatedBy:   actor,
 	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errs.NotFound("worklog not found")
+			return nil, nil, errs.NotFound("worklog not found")
 		}
-		var appErr *errs.AppError
-		if errors.As(err, &appErr) {
-			logger.FromContext(ctx).Warn("failed to update worklog", zap.Error(err))
-			return nil, err
+		if repository.IsUniqueErrFT(err) {
+			return nil, nil, errs.Conflict("worklog already exists for this employee, date, and entryType")
 		}
-		logger.FromContext(ctx).Error("failed to update worklog", zap.Error(err))
-		return nil, errs.Internal("failed to update worklog")
+		return nil, nil, err
 	}
+	return current, updated, nil
+}
 
+// updateDetails lists the fields an update changed, for the activity log.
+func updateDetails(current, updated *repository.FTRecord) map[string]interface{} {
 	details := map[string]interface{}{}
-	if entryType != current.EntryType {
-		details["entry_type"] = entryType
+	if updated.EntryType != current.EntryType {
+		details["entry_type"] = updated.EntryType
 	}
-	if !workDate.Equal(current.WorkDate) {
-		details["work_date"] = workDate.Format("2006-01-02")
+	if !updated.WorkDate.Equal(current.WorkDate) {
+		details["work_date"] = updated.WorkDate.Format("2006-01-02")
 	}
-	if quantity != current.Quantity {
-		details["quantity"] = quantity
+	if updated.Quantity != current.Quantity {
+		details["quantity"] = updated.Quantity
 	}
-	if status != current.Status {
-		details["status"] = status
+	if updated.Status != current.Status {
+		details["status"] = updated.Status
 	}
-
-	h.eb.Publish(events.LogEvent{
-		ActorID:    user.ID,
-		CompanyID:  &tenant.CompanyID,
-		BranchID:   tenant.BranchIDPtr(),
-		Action:     "UPDATE",
-		EntityName: "WORKLOG_FT",
-		EntityID:   updated.ID.String(),
-		Details:    details,
-		Timestamp:  time.Now(),
-	})
-
-	return &UpdateResponse{FTItem: dto.FromFT(*updated)}, nil
+	if !sameLeaveType(updated.LeaveTypeID, current.LeaveTypeID) {
+		details["leave_type_id"] = updated.LeaveTypeID
+	}
+	return details
 }
 
 func normalizeUpdatePayload(p *UpdateRequest, current *repository.FTRecord) (string, time.Time, float64, string, error) {
@@ -274,7 +336,7 @@ func normalizeUpdatePayload(p *UpdateRequest, current *repository.FTRecord) (str
 		entryType = current.EntryType
 	} else {
 		switch entryType {
-		case "late", "leave_day", "leave_double", "leave_hours", "ot":
+		case "late", "early_leave", "leave_day", "leave_double", "leave_hours", "leave_paid", "ot", "holiday_work", "holiday_ot":
 		default:
 			return "", time.Time{}, 0, "", errs.BadRequest("invalid entryType")
 		}
@@ -340,20 +402,10 @@ func (h *deleteHandler) Handle(ctx context.Context, cmd *DeleteCommand) (mediato
 		return mediator.NoResponse{}, errs.Unauthorized("missing user context")
 	}
 
-	rec, err := h.repo.Get(ctx, tenant, cmd.ID)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return mediator.NoResponse{}, errs.NotFound("worklog not found")
-		}
-		logger.FromContext(ctx).Error("failed to load worklog", zap.Error(err))
-		return mediator.NoResponse{}, errs.Internal("failed to load worklog")
-	}
-	if rec.Status != "pending" {
-		return mediator.NoResponse{}, errs.BadRequest("cannot delete non-pending worklog")
-	}
-	if err := h.repo.SoftDelete(ctx, tenant, cmd.ID, user.ID); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return mediator.NoResponse{}, errs.NotFound("worklog not found")
+	if _, err := deleteEntry(ctx, h.repo, tenant, user.ID, cmd.ID); err != nil {
+		var appErr *errs.AppError
+		if errors.As(err, &appErr) {
+			return mediator.NoResponse{}, err
 		}
 		logger.FromContext(ctx).Error("failed to delete worklog", zap.Error(err))
 		return mediator.NoResponse{}, errs.Internal("failed to delete worklog")
@@ -371,3 +423,24 @@ func (h *deleteHandler) Handle(ctx context.Context, cmd *DeleteCommand) (mediato
 
 	return mediator.NoResponse{}, nil
 }
+
+// deleteEntry removes a pending entry and returns it as it was.
+func deleteEntry(ctx context.Context, repo repository.FTRepository, tenant contextx.TenantInfo, actor uuid.UUID, id uuid.UUID) (*repository.FTRecord, error) {
+	rec, err := repo.Get(ctx, tenant, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("worklog not found")
+		}
+		return nil, err
+	}
+	if rec.Status != "pending" {
+		return nil, errs.BadRequest("cannot delete non-pending worklog")
+	}
+	if err := repo.SoftDelete(ctx, tenant, id, actor); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("worklog not found")
+		}
+		return nil, err
+	}
+	return rec, nil
+}
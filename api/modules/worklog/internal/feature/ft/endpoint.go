@@ -21,7 +21,7 @@ import (
 // @Param limit query int false "limit"
 // @Param employeeId query string false "employee id"
 // @Param status query string false "pending|approved|all"
-// @Param entryType query string false "late|leave_day|leave_double|leave_hours|ot"
+// @Param entryType query string false "late|early_leave|leave_day|leave_double|leave_hours|leave_paid|ot|holiday_work|holiday_ot"
 // @Param startDate query string false "YYYY-MM-DD"
 // @Param endDate query string false "YYYY-MM-DD"
 // @Security BearerAuth
@@ -87,6 +87,11 @@ func Register(router fiber.Router) {
 	// register detail/create/update/delete
 	registerGet(router)
 	registerCreate(router)
+	// bulk routes go before /:id so "bulk" is not read as an id
+	registerBulkCreate(router)
+	registerBulkUpdate(router)
+	registerBulkApprove(router)
+	registerBulkDelete(router)
 	registerUpdate(router)
 	registerDelete(router)
 }
@@ -122,7 +127,7 @@ func registerGet(router fiber.Router) {
 }
 
 // @Summary Create worklog FT
-// @Description บันทึก worklog (Full-time) สถานะเริ่มต้น pending
+// @Description บันทึก worklog (Full-time) สถานะเริ่มต้น pending ตรวจกับปฏิทินวันหยุด: ลาในวันหยุดไม่ได้, ot ในวันหยุดบันทึกเป็น holiday_ot, holiday_work/holiday_ot ต้องเป็นวันหยุดบริษัทหรือวันหยุดประจำสัปดาห์ของกะ, leave_paid ต้องระบุ leaveTypeId และตรวจยอดวันลาคงเหลือ
 // @Tags Worklogs FT
 // @Accept json
 // @Produce json
@@ -153,7 +158,7 @@ func registerCreate(router fiber.Router) {
 }
 
 // @Summary Update worklog FT
-// @Description แก้ไข worklog (Full-time). เปลี่ยนสถานะเป็น approved ได้, revert approved ไม่ได้
+// @Description แก้ไข worklog (Full-time). เปลี่ยนสถานะเป็น approved ได้, revert approved ไม่ได้ ระบุ leaveTypeId เพื่อเปลี่ยนประเภทการลา
 // @Tags Worklogs FT
 // @Accept json
 // @Produce json
@@ -217,3 +222,131 @@ func registerDelete(router fiber.Router) {
 		return c.SendStatus(fiber.StatusNoContent)
 	})
 }
+
+// @Summary Bulk create worklogs FT
+// @Description สร้าง worklog (Full-time) หลายรายการในครั้งเดียว (สูงสุด 500) ตรวจสอบเหมือนการสร้างทีละรายการ หากมีรายการใดไม่ผ่านจะไม่บันทึกทั้งหมด และตอบ 422 พร้อมผลรายแถวใน extra
+// @Tags Worklogs FT
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body BulkCreateRequest true "worklog rows"
+// @Success 201 {object} BulkResponse
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 422
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /worklogs/ft/bulk [post]
+func registerBulkCreate(router fiber.Router) {
+	router.Post("/bulk", func(c fiber.Ctx) error {
+		var req BulkCreateRequest
+		if err := c.Bind().Body(&req); err != nil {
+			return errs.BadRequest("invalid request body")
+		}
+		resp, err := mediator.Send[*BulkCommand, *BulkResponse](c.Context(), &BulkCommand{
+			Op:      BulkCreate,
+			Creates: req.Items,
+		})
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusCreated, resp)
+	})
+}
+
+// @Summary Bulk update worklogs FT
+// @Description แก้ไข worklog (Full-time) หลายรายการในครั้งเดียว (สูงสุด 500) แต่ละแถวระบุ id และฟิลด์ที่ต้องการแก้ หากมีรายการใดไม่ผ่านจะไม่บันทึกทั้งหมด และตอบ 422 พร้อมผลรายแถวใน extra
+// @Tags Worklogs FT
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body BulkUpdateRequest true "worklog rows"
+// @Success 200 {object} BulkResponse
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 422
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /worklogs/ft/bulk [patch]
+func registerBulkUpdate(router fiber.Router) {
+	router.Patch("/bulk", func(c fiber.Ctx) error {
+		var req BulkUpdateRequest
+		if err := c.Bind().Body(&req); err != nil {
+			return errs.BadRequest("invalid request body")
+		}
+		resp, err := mediator.Send[*BulkCommand, *BulkResponse](c.Context(), &BulkCommand{
+			Op:      BulkUpdate,
+			Updates: req.Items,
+		})
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
+
+// @Summary Bulk approve worklogs FT
+// @Description อนุมัติ worklog (Full-time) หลายรายการในครั้งเดียว (สูงสุด 500) รายการที่อนุมัติแล้วถือว่าผ่าน หากมีรายการใดไม่ผ่านจะไม่บันทึกทั้งหมด และตอบ 422 พร้อมผลรายแถวใน extra
+// @Tags Worklogs FT
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body BulkIDsRequest true "worklog ids"
+// @Success 200 {object} BulkResponse
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 422
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /worklogs/ft/bulk/approve [post]
+func registerBulkApprove(router fiber.Router) {
+	router.Post("/bulk/approve", func(c fiber.Ctx) error {
+		var req BulkIDsRequest
+		if err := c.Bind().Body(&req); err != nil {
+			return errs.BadRequest("invalid request body")
+		}
+		resp, err := mediator.Send[*BulkCommand, *BulkResponse](c.Context(), &BulkCommand{
+			Op:  BulkApprove,
+			IDs: req.IDs,
+		})
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
+
+// @Summary Bulk delete worklogs FT
+// @Description ลบ worklog (Full-time) หลายรายการในครั้งเดียว (สูงสุด 500) ได้เฉพาะสถานะ pending หากมีรายการใดไม่ผ่านจะไม่ลบทั้งหมด และตอบ 422 พร้อมผลรายแถวใน extra
+// @Tags Worklogs FT
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body BulkIDsRequest true "worklog ids"
+// @Success 200 {object} BulkResponse
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 422
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /worklogs/ft/bulk/delete [post]
+func registerBulkDelete(router fiber.Router) {
+	router.Post("/bulk/delete", func(c fiber.Ctx) error {
+		var req BulkIDsRequest
+		if err := c.Bind().Body(&req); err != nil {
+			return errs.BadRequest("invalid request body")
+		}
+		resp, err := mediator.Send[*BulkCommand, *BulkResponse](c.Context(), &BulkCommand{
+			Op:  BulkDelete,
+			IDs: req.IDs,
+		})
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
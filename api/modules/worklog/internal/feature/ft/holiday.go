@@ -0,0 +1,58 @@
+package ft
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/contracts"
+)
+
+// checkHoliday matches the entry type against the holiday calendar of the employee's branch:
+// leave is not taken on a holiday, OT on a holiday is recorded as holiday OT, and holiday work
+// or holiday OT needs the date to be a holiday or a rest day of the employee's shift. It returns
+// the entry type to store.
+func checkHoliday(ctx context.Context, companyID, branchID, employeeID uuid.UUID, workDate time.Time, entryType string) (string, error) {
+	resp, err := mediator.Send[*contracts.ListHolidaysQuery, *contracts.ListHolidaysResponse](ctx, &contracts.ListHolidaysQuery{
+		CompanyID: companyID,
+		BranchID:  &branchID,
+		From:      workDate,
+		To:        workDate,
+	})
+	if err != nil {
+		return "", err
+	}
+	holiday := resp.Find(workDate)
+
+	switch entryType {
+	case "leave_day", "leave_double", "leave_hours", "leave_paid":
+		if holiday != nil {
+			return "", errs.BadRequest(fmt.Sprintf("workDate is a holiday (%s); leave cannot be recorded on a holiday", holiday.Name))
+		}
+	case "ot":
+		if holiday != nil {
+			return "holiday_ot", nil
+		}
+	case "holiday_work", "holiday_ot":
+		if holiday != nil {
+			break
+		}
+		shifts, err := mediator.Send[*contracts.ResolveShiftsQuery, *contracts.ResolveShiftsResponse](ctx, &contracts.ResolveShiftsQuery{
+			CompanyID:   companyID,
+			EmployeeIDs: []uuid.UUID{employeeID},
+			From:        workDate,
+			To:          workDate,
+		})
+		if err != nil {
+			return "", err
+		}
+		if shift := shifts.For(employeeID, workDate); shift == nil || shift.WorksOn(workDate) {
+			return "", errs.BadRequest("workDate is not a holiday in the company calendar or a rest day of the employee's shift")
+		}
+	}
+	return entryType, nil
+}
@@ -0,0 +1,125 @@
+package ft
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/worklog/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/contracts"
+)
+
+func isLeave(entryType string) bool {
+	switch entryType {
+	case "leave_day", "leave_double", "leave_hours", "leave_paid":
+		return true
+	}
+	return false
+}
+
+func sameLeaveType(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// checkLeave validates the leave type of a leave entry. Paid leave always names its type;
+// unpaid and deducted leave may. When a type is given the leave module checks that it matches
+// the entry type and, for an enforced entitlement, that the employee's balance covers it.
+// excludeID is the entry being updated so its current days are not counted twice.
+func checkLeave(ctx context.Context, companyID, employeeID uuid.UUID, leaveTypeID *uuid.UUID, entryType string, workDate time.Time, quantity float64, excludeID *uuid.UUID) error {
+	if !isLeave(entryType) {
+		if leaveTypeID != nil {
+			return errs.BadRequest("leaveTypeId is only allowed on leave entries")
+		}
+		return nil
+	}
+	if leaveTypeID == nil {
+		if entryType == "leave_paid" {
+			return errs.BadRequest("leaveTypeId is required for leave_paid")
+		}
+		return nil
+	}
+	_, err := mediator.Send[*contracts.CheckLeaveEntryQuery, *contracts.CheckLeaveEntryResponse](ctx, &contracts.CheckLeaveEntryQuery{
+		CompanyID:      companyID,
+		EmployeeID:     employeeID,
+		LeaveTypeID:    *leaveTypeID,
+		EntryType:      entryType,
+		WorkDate:       workDate,
+		Quantity:       quantity,
+		ExcludeEntryID: excludeID,
+	})
+	return err
+}
+
+type leaveEntriesHandler struct {
+	repo repository.FTRepository
+}
+
+var _ mediator.RequestHandler[*contracts.CreateLeaveEntriesCommand, *contracts.CreateLeaveEntriesResponse] = (*leaveEntriesHandler)(nil)
+
+func NewLeaveEntriesHandler(repo repository.FTRepository) *leaveEntriesHandler {
+	return &leaveEntriesHandler{repo: repo}
+}
+
+// Handle records the days of an approved leave request as pending entries, so the next regular
+// run deducts unpaid leave and closes them like any other worklog. It runs in the caller's
+// transaction, so one conflicting day fails the whole request.
+func (h *leaveEntriesHandler) Handle(ctx context.Context, cmd *contracts.CreateLeaveEntriesCommand) (*contracts.CreateLeaveEntriesResponse, error) {
+	if !isLeave(cmd.EntryType) {
+		return nil, errs.BadRequest("invalid leave entryType")
+	}
+	tenant := contextx.TenantInfo{CompanyID: cmd.CompanyID}
+	branchID, err := h.repo.EmployeeBranchID(ctx, tenant, cmd.EmployeeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.BadRequest("employee not found in this company")
+		}
+		logger.FromContext(ctx).Error("failed to load employee", zap.Error(err))
+		return nil, errs.Internal("failed to record leave")
+	}
+
+	ids := make([]uuid.UUID, 0, len(cmd.Dates))
+	for _, d := range cmd.Dates {
+		if _, err := checkHoliday(ctx, cmd.CompanyID, branchID, cmd.EmployeeID, d, cmd.EntryType); err != nil {
+			return nil, err
+		}
+		exists, err := h.repo.ExistsActiveByEmployeeDateType(ctx, cmd.EmployeeID, d, cmd.EntryType, nil)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to check worklog", zap.Error(err))
+			return nil, errs.Internal("failed to record leave")
+		}
+		if exists {
+			return nil, errs.Conflict(fmt.Sprintf("worklog %s already exists on %s", cmd.EntryType, d.Format("2006-01-02")))
+		}
+		rec, err := h.repo.Insert(ctx, tenant, repository.FTRecord{
+			EmployeeID:  cmd.EmployeeID,
+			EntryType:   cmd.EntryType,
+			WorkDate:    d,
+			Quantity:    cmd.Quantity,
+			Status:      "pending",
+			LeaveTypeID: &cmd.LeaveTypeID,
+			CreatedBy:   cmd.ActorID,
+			UpdatedBy:   cmd.ActorID,
+		})
+		if err != nil {
+			if repository.IsUniqueErrFT(err) {
+				return nil, errs.Conflict(fmt.Sprintf("worklog %s already exists on %s", cmd.EntryType, d.Format("2006-01-02")))
+			}
+			logger.FromContext(ctx).Error("failed to insert leave worklog", zap.Error(err))
+			return nil, errs.Internal("failed to record leave")
+		}
+		ids = append(ids, rec.ID)
+	}
+	return &contracts.CreateLeaveEntriesResponse{EntryIDs: ids}, nil
+}
@@ -0,0 +1,73 @@
+package ft
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/modules/worklog/internal/repository"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/storage/sqldb/dbtest"
+	"hrms/shared/common/storage/sqldb/transactor"
+	"hrms/shared/contracts"
+)
+
+type noHolidays struct{}
+
+func (noHolidays) Handle(context.Context, *contracts.ListHolidaysQuery) (*contracts.ListHolidaysResponse, error) {
+	return &contracts.ListHolidaysResponse{}, nil
+}
+
+// TestApprovedUnpaidLeaveDeductedByNextRun records an approved unpaid leave request the way the
+// leave module does and checks that the next regular run deducts it. It needs a migrated
+// database in TEST_DB_DSN; the fixtures are rolled back.
+func TestApprovedUnpaidLeaveDeductedByNextRun(t *testing.T) {
+	d := dbtest.Open(t)
+	mediator.Register[*contracts.ListHolidaysQuery, *contracts.ListHolidaysResponse](noHolidays{})
+	month := dbtest.Month
+
+	d.Rollback(t, func(ctx context.Context, db transactor.DBTX) {
+		branchID := d.InsertBranch(ctx, t, db, "LEAVE-TEST")
+		employeeID := d.InsertEmployee(ctx, t, db, branchID, dbtest.Employee{Number: "LEAVE-TEST-001", BasePay: 30000})
+		var leaveTypeID uuid.UUID
+		if err := db.GetContext(ctx, &leaveTypeID, `
+INSERT INTO leave_type (company_id, code, name, pay_type, enforce_balance, created_by, updated_by)
+VALUES ($1, 'leave-test', 'Leave test', 'unpaid', FALSE, $2, $2)
+RETURNING id`, d.CompanyID, d.AdminID); err != nil {
+			t.Fatalf("insert leave type: %v", err)
+		}
+
+		h := NewLeaveEntriesHandler(repository.NewFTRepository(d.DBTX))
+		if _, err := h.Handle(ctx, &contracts.CreateLeaveEntriesCommand{
+			CompanyID:   d.CompanyID,
+			EmployeeID:  employeeID,
+			LeaveTypeID: leaveTypeID,
+			EntryType:   "leave_day",
+			Dates:       []time.Time{month.AddDate(0, 0, 4), month.AddDate(0, 0, 5)},
+			Quantity:    1,
+			ActorID:     d.AdminID,
+		}); err != nil {
+			t.Fatalf("record leave: %v", err)
+		}
+
+		runID := d.InsertRegularRun(ctx, t, db, branchID, month)
+
+		var item struct {
+			SalaryAmount       float64 `db:"salary_amount"`
+			LeaveDaysQty       float64 `db:"leave_days_qty"`
+			LeaveDaysDeduction float64 `db:"leave_days_deduction"`
+		}
+		if err := db.GetContext(ctx, &item, `
+SELECT salary_amount, leave_days_qty, leave_days_deduction
+FROM payroll_run_item
+WHERE run_id = $1 AND employee_id = $2`, runID, employeeID); err != nil {
+			t.Fatalf("load payroll item: %v", err)
+		}
+		// two days of 30,000 / 30
+		if item.SalaryAmount != 30000 || item.LeaveDaysQty != 2 || item.LeaveDaysDeduction != 2000 {
+			t.Errorf("item = %+v, want salary 30000 less 2 leave days of 2000", item)
+		}
+	})
+}
@@ -69,41 +69,11 @@ func (h *createHandler) Handle(ctx context.Context, cmd *CreateCommand) (*Create
 		return nil, errs.Unauthorized("missing user context")
 	}
 
-	parsedDate, err := validatePayload(&cmd.Payload)
-	if err != nil {
-		return nil, err
-	}
-
-	rec := repository.PTRecord{
-		EmployeeID: cmd.Payload.EmployeeID,
-		WorkDate:   parsedDate,
-		MorningIn:  cmd.Payload.MorningIn,
-		MorningOut: cmd.Payload.MorningOut,
-		EveningIn:  cmd.Payload.EveningIn,
-		EveningOut: cmd.Payload.EveningOut,
-		Status:     cmd.Payload.Status,
-		CreatedBy:  user.ID,
-		UpdatedBy:  user.ID,
-	}
-
 	var created *repository.PTRecord
 	if err := h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
-		exists, err := h.repo.ExistsActiveByEmployeeDate(ctxTx, rec.EmployeeID, rec.WorkDate)
-		if err != nil {
-			return err
-		}
-		if exists {
-			return errs.Conflict("worklog already exists for this employee on this date")
-		}
-
-		created, err = h.repo.Insert(ctxTx, tenant, rec)
-		if err != nil {
-			if repository.IsUniqueErrPT(err) {
-				return errs.Conflict("worklog already exists for this employee on this date")
-			}
-			return err
-		}
-		return nil
+		var err error
+		created, err = createEntry(ctxTx, h.repo, tenant, user.ID, cmd.Payload)
+		return err
 	}); err != nil {
 		var appErr *errs.AppError
 		if errors.As(err, &appErr) {
@@ -135,6 +105,43 @@ func (h *createHandler) Handle(ctx context.Context, cmd *CreateCommand) (*Create
 	return &CreateResponse{PTItem: dto.FromPT(*created)}, nil
 }
 
+// createEntry inserts one worklog as given; ctx carries the transaction.
+func createEntry(ctx context.Context, repo repository.PTRepository, tenant contextx.TenantInfo, actor uuid.UUID, p CreateRequest) (*repository.PTRecord, error) {
+	parsedDate, err := validatePayload(&p)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := repo.ExistsActiveByEmployeeDate(ctx, p.EmployeeID, parsedDate)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errs.Conflict("worklog already exists for this employee on this date")
+	}
+
+	created, err := repo.Insert(ctx, tenant, repository.PTRecord{
+		EmployeeID: p.EmployeeID,
+		WorkDate:   parsedDate,
+		MorningIn:  p.MorningIn,
+		MorningOut: p.MorningOut,
+		EveningIn:  p.EveningIn,
+		EveningOut: p.EveningOut,
+		Status:     p.Status,
+		CreatedBy:  actor,
+		UpdatedBy:  actor,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.BadRequest("employee not found in this company")
+		}
+		if repository.IsUniqueErrPT(err) {
+			return nil, errs.Conflict("worklog already exists for this employee on this date")
+		}
+		return nil, err
+	}
+	return created, nil
+}
+
 // validatePayload handles time format validation and parsing
 func validatePayload(p *CreateRequest) (time.Time, error) {
 	parsedDate, err := time.Parse("2006-01-02", strings.TrimSpace(p.WorkDate))
@@ -199,80 +206,105 @@ func (h *updateHandler) Handle(ctx context.Context, cmd *UpdateCommand) (*Update
 		return nil, errs.Unauthorized("missing user context")
 	}
 
-	current, err := h.repo.Get(ctx, tenant, cmd.ID)
+	var current, updated *repository.PTRecord
+	err := h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
+		var err error
+		current, updated, err = updateEntry(ctxTx, h.repo, tenant, user.ID, cmd.ID, cmd.Payload)
+		return err
+	})
+	if err != nil {
+		var appErr *errs.AppError
+		if errors.As(err, &appErr) {
+			return nil, err
+		}
+		logger.FromContext(ctx).Error("failed to update worklog", zap.Error(err))
+		return nil, errs.Internal("failed to update worklog")
+	}
+
+	h.eb.Publish(events.LogEvent{
+		ActorID:    user.ID,
+		CompanyID:  &tenant.CompanyID,
+		BranchID:   tenant.BranchIDPtr(),
+		Action:     "UPDATE",
+		EntityName: "WORKLOG_PT",
+		EntityID:   updated.ID.String(),
+		Details:    updateDetails(current, updated),
+		Timestamp:  time.Now(),
+	})
+
+	return &UpdateResponse{PTItem: dto.FromPT(*updated)}, nil
+}
+
+// updateEntry applies the changes in p to the worklog and returns it before and after. An
+// approved worklog cannot go back to pending. ctx carries the transaction.
+func updateEntry(ctx context.Context, repo repository.PTRepository, tenant contextx.TenantInfo, actor uuid.UUID, id uuid.UUID, p UpdateRequest) (*repository.PTRecord, *repository.PTRecord, error) {
+	current, err := repo.Get(ctx, tenant, id)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errs.NotFound("worklog not found")
+			return nil, nil, errs.NotFound("worklog not found")
 		}
-		logger.FromContext(ctx).Error("failed to load worklog", zap.Error(err))
-		return nil, errs.Internal("failed to load worklog")
+		return nil, nil, err
 	}
 
-	parsedDate, morningIn, morningOut, eveningIn, eveningOut, status, err := normalizeUpdatePayload(&cmd.Payload, current)
+	parsedDate, morningIn, morningOut, eveningIn, eveningOut, status, err := normalizeUpdatePayload(&p, current)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if current.Status == "approved" && status != "approved" {
-		return nil, errs.BadRequest("cannot revert approved worklog")
+		return nil, nil, errs.BadRequest("cannot revert approved worklog")
 	}
 
-	rec := repository.PTRecord{
+	updated, err := repo.Update(ctx, tenant, id, repository.PTRecord{
 		WorkDate:   parsedDate,
 		MorningIn:  morningIn,
 		MorningOut: morningOut,
 		EveningIn:  eveningIn,
 		EveningOut: eveningOut,
 		Status:     status,
-		UpdatedBy:  user.ID,
-	}
-
-	var updated *repository.PTRecord
-	err = h.tx.WithinTransaction(ctx, func(ctxTx context.Context, _ func(transactor.PostCommitHook)) error {
-		var err error
-		updated, err = h.repo.Update(ctxTx, tenant, cmd.ID, rec)
-		return err
+		UpdatedBy:  actor,
 	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, errs.NotFound("worklog not found")
+			return nil, nil, errs.NotFound("worklog not found")
 		}
-		logger.FromContext(ctx).Error("failed to update worklog", zap.Error(err))
-		return nil, errs.Internal("failed to update worklog")
+		if repository.IsUniqueErrPT(err) {
+			return nil, nil, errs.Conflict("worklog already exists for this employee on this date")
+		}
+		return nil, nil, err
 	}
+	return current, updated, nil
+}
 
+// updateDetails lists the fields an update changed, for the activity log.
+func updateDetails(current, updated *repository.PTRecord) map[string]interface{} {
 	details := map[string]interface{}{}
-	if !parsedDate.Equal(current.WorkDate) {
-		details["work_date"] = parsedDate.Format("2006-01-02")
+	if !updated.WorkDate.Equal(current.WorkDate) {
+		details["work_date"] = updated.WorkDate.Format("2006-01-02")
 	}
-	if morningIn != current.MorningIn {
-		details["morning_in"] = morningIn
+	if !sameTime(updated.MorningIn, current.MorningIn) {
+		details["morning_in"] = updated.MorningIn
 	}
-	if morningOut != current.MorningOut {
-		details["morning_out"] = morningOut
+	if !sameTime(updated.MorningOut, current.MorningOut) {
+		details["morning_out"] = updated.MorningOut
 	}
-	if eveningIn != current.EveningIn {
-		details["evening_in"] = eveningIn
+	if !sameTime(updated.EveningIn, current.EveningIn) {
+		details["evening_in"] = updated.EveningIn
 	}
-	if eveningOut != current.EveningOut {
-		details["evening_out"] = eveningOut
+	if !sameTime(updated.EveningOut, current.EveningOut) {
+		details["evening_out"] = updated.EveningOut
 	}
-	if status != current.Status {
-		details["status"] = status
+	if updated.Status != current.Status {
+		details["status"] = updated.Status
 	}
+	return details
+}
 
-	h.eb.Publish(events.LogEvent{
-		ActorID:    user.ID,
-		CompanyID:  &tenant.CompanyID,
-		BranchID:   tenant.BranchIDPtr(),
-		Action:     "UPDATE",
-		EntityName: "WORKLOG_PT",
-		EntityID:   updated.ID.String(),
-		Details:    details,
-		Timestamp:  time.Now(),
-	})
-
-	return &UpdateResponse{PTItem: dto.FromPT(*updated)}, nil
+func sameTime(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
 }
 
 func normalizeUpdatePayload(p *UpdateRequest, current *repository.PTRecord) (time.Time, *string, *string, *string, *string, string, error) {
@@ -359,20 +391,10 @@ func (h *deleteHandler) Handle(ctx context.Context, cmd *DeleteCommand) (mediato
 		return mediator.NoResponse{}, errs.Unauthorized("missing user context")
 	}
 
-	rec, err := h.repo.Get(ctx, tenant, cmd.ID)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return mediator.NoResponse{}, errs.NotFound("worklog not found")
-		}
-		logger.FromContext(ctx).Error("failed to load worklog", zap.Error(err))
-		return mediator.NoResponse{}, errs.Internal("failed to load worklog")
-	}
-	if rec.Status != "pending" {
-		return mediator.NoResponse{}, errs.BadRequest("cannot delete non-pending worklog")
-	}
-	if err := h.repo.SoftDelete(ctx, tenant, cmd.ID, user.ID); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return mediator.NoResponse{}, errs.NotFound("worklog not found")
+	if _, err := deleteEntry(ctx, h.repo, tenant, user.ID, cmd.ID); err != nil {
+		var appErr *errs.AppError
+		if errors.As(err, &appErr) {
+			return mediator.NoResponse{}, err
 		}
 		logger.FromContext(ctx).Error("failed to delete worklog", zap.Error(err))
 		return mediator.NoResponse{}, errs.Internal("failed to delete worklog")
@@ -390,3 +412,24 @@ func (h *deleteHandler) Handle(ctx context.Context, cmd *DeleteCommand) (mediato
 
 	return mediator.NoResponse{}, nil
 }
+
+// deleteEntry removes a pending worklog and returns it as it was.
+func deleteEntry(ctx context.Context, repo repository.PTRepository, tenant contextx.TenantInfo, actor uuid.UUID, id uuid.UUID) (*repository.PTRecord, error) {
+	rec, err := repo.Get(ctx, tenant, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("worklog not found")
+		}
+		return nil, err
+	}
+	if rec.Status != "pending" {
+		return nil, errs.BadRequest("cannot delete non-pending worklog")
+	}
+	if err := repo.SoftDelete(ctx, tenant, id, actor); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errs.NotFound("worklog not found")
+		}
+		return nil, err
+	}
+	return rec, nil
+}
@@ -79,6 +79,11 @@ func Register(router fiber.Router) {
 	// additional routes
 	registerGet(router)
 	registerCreate(router)
+	// bulk routes go before /:id so "bulk" is not read as an id
+	registerBulkCreate(router)
+	registerBulkUpdate(router)
+	registerBulkApprove(router)
+	registerBulkDelete(router)
 	registerUpdate(router)
 	registerDelete(router)
 }
@@ -207,3 +212,131 @@ func registerDelete(router fiber.Router) {
 		return c.SendStatus(fiber.StatusNoContent)
 	})
 }
+
+// @Summary Bulk create worklogs PT
+// @Description สร้าง worklog (Part-time) หลายรายการในครั้งเดียว (สูงสุด 500) ตรวจสอบเหมือนการสร้างทีละรายการ หากมีรายการใดไม่ผ่านจะไม่บันทึกทั้งหมด และตอบ 422 พร้อมผลรายแถวใน extra
+// @Tags Worklogs PT
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body BulkCreateRequest true "worklog rows"
+// @Success 201 {object} BulkResponse
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 422
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /worklogs/pt/bulk [post]
+func registerBulkCreate(router fiber.Router) {
+	router.Post("/bulk", func(c fiber.Ctx) error {
+		var req BulkCreateRequest
+		if err := c.Bind().Body(&req); err != nil {
+			return errs.BadRequest("invalid request body")
+		}
+		resp, err := mediator.Send[*BulkCommand, *BulkResponse](c.Context(), &BulkCommand{
+			Op:      BulkCreate,
+			Creates: req.Items,
+		})
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusCreated, resp)
+	})
+}
+
+// @Summary Bulk update worklogs PT
+// @Description แก้ไข worklog (Part-time) หลายรายการในครั้งเดียว (สูงสุด 500) แต่ละแถวระบุ id และฟิลด์ที่ต้องการแก้ หากมีรายการใดไม่ผ่านจะไม่บันทึกทั้งหมด และตอบ 422 พร้อมผลรายแถวใน extra
+// @Tags Worklogs PT
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body BulkUpdateRequest true "worklog rows"
+// @Success 200 {object} BulkResponse
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 422
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /worklogs/pt/bulk [patch]
+func registerBulkUpdate(router fiber.Router) {
+	router.Patch("/bulk", func(c fiber.Ctx) error {
+		var req BulkUpdateRequest
+		if err := c.Bind().Body(&req); err != nil {
+			return errs.BadRequest("invalid request body")
+		}
+		resp, err := mediator.Send[*BulkCommand, *BulkResponse](c.Context(), &BulkCommand{
+			Op:      BulkUpdate,
+			Updates: req.Items,
+		})
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
+
+// @Summary Bulk approve worklogs PT
+// @Description อนุมัติ worklog (Part-time) หลายรายการในครั้งเดียว (สูงสุด 500) รายการที่อนุมัติแล้วถือว่าผ่าน หากมีรายการใดไม่ผ่านจะไม่บันทึกทั้งหมด และตอบ 422 พร้อมผลรายแถวใน extra
+// @Tags Worklogs PT
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body BulkIDsRequest true "worklog ids"
+// @Success 200 {object} BulkResponse
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 422
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /worklogs/pt/bulk/approve [post]
+func registerBulkApprove(router fiber.Router) {
+	router.Post("/bulk/approve", func(c fiber.Ctx) error {
+		var req BulkIDsRequest
+		if err := c.Bind().Body(&req); err != nil {
+			return errs.BadRequest("invalid request body")
+		}
+		resp, err := mediator.Send[*BulkCommand, *BulkResponse](c.Context(), &BulkCommand{
+			Op:  BulkApprove,
+			IDs: req.IDs,
+		})
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
+
+// @Summary Bulk delete worklogs PT
+// @Description ลบ worklog (Part-time) หลายรายการในครั้งเดียว (สูงสุด 500) ได้เฉพาะสถานะ pending หากมีรายการใดไม่ผ่านจะไม่ลบทั้งหมด และตอบ 422 พร้อมผลรายแถวใน extra
+// @Tags Worklogs PT
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body BulkIDsRequest true "worklog ids"
+// @Success 200 {object} BulkResponse
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 422
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /worklogs/pt/bulk/delete [post]
+func registerBulkDelete(router fiber.Router) {
+	router.Post("/bulk/delete", func(c fiber.Ctx) error {
+		var req BulkIDsRequest
+		if err := c.Bind().Body(&req); err != nil {
+			return errs.BadRequest("invalid request body")
+		}
+		resp, err := mediator.Send[*BulkCommand, *BulkResponse](c.Context(), &BulkCommand{
+			Op:  BulkDelete,
+			IDs: req.IDs,
+		})
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
@@ -0,0 +1,56 @@
+package timesheet
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/google/uuid"
+
+	"hrms/shared/common/errs"
+	"hrms/shared/common/mediator"
+	"hrms/shared/common/response"
+)
+
+// Register timesheet endpoint
+// @Summary Monthly attendance timesheet
+// @Description ตารางเวลาทำงานรายเดือนของพนักงานประจำและพาร์ทไทม์ แสดงทุกวันของเดือน (รายการ FT แยกตามประเภท เวลาเข้า-ออกและนาทีของ PT วันหยุด วันหยุดตามกะ สถานะการคิดเงินเดือน) พร้อมยอดรวมของเดือนในหน่วยเดียวกับ payroll_run_item และยอดที่งวดเงินเดือนจะดึงไปคำนวณ
+// @Tags Worklogs Timesheet
+// @Produce json
+// @Param month query string true "month (YYYY-MM)"
+// @Param employeeId query string false "employee id"
+// @Param page query int false "page"
+// @Param limit query int false "limit (<=100, default 20)"
+// @Security BearerAuth
+// @Success 200 {object} Response
+// @Failure 400
+// @Failure 401
+// @Failure 403
+// @Failure 404
+// @Param X-Company-ID header string false "Company ID"
+// @Param X-Branch-ID header string false "Branch ID"
+// @Router /worklogs/timesheet [get]
+func Register(router fiber.Router) {
+	router.Get("/", func(c fiber.Ctx) error {
+		month, err := time.Parse("2006-01", c.Query("month"))
+		if err != nil {
+			return errs.BadRequest("month must be YYYY-MM")
+		}
+		page, _ := strconv.Atoi(c.Query("page", "1"))
+		limit, _ := strconv.Atoi(c.Query("limit", "20"))
+		q := &Query{Month: month, Page: page, Limit: limit}
+		if v := c.Query("employeeId"); v != "" {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				return errs.BadRequest("invalid employeeId")
+			}
+			q.EmployeeID = &id
+		}
+
+		resp, err := mediator.Send[*Query, *Response](c.Context(), q)
+		if err != nil {
+			return err
+		}
+		return response.JSON(c, fiber.StatusOK, resp)
+	})
+}
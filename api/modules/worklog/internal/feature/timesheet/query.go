@@ -0,0 +1,446 @@
+package timesheet
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"hrms/modules/worklog/internal/dto"
+	"hrms/modules/worklog/internal/repository"
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/errs"
+	"hrms/shared/common/logger"
+	"hrms/shared/common/mediator"
+	"hrms/shared/contracts"
+)
+
+// Day payroll states
+const (
+	PayrollOpen   = "open"   // at least one entry will be picked up by the next payroll calculation
+	PayrollClosed = "closed" // every entry was closed by an approved payroll run or paid in a PT payout
+)
+
+type Query struct {
+	Month      time.Time
+	EmployeeID *uuid.UUID
+	Page       int
+	Limit      int
+}
+
+type Employee struct {
+	ID                  uuid.UUID `json:"id"`
+	EmployeeNumber      string    `json:"employeeNumber"`
+	FullName            string    `json:"fullName"`
+	TypeCode            string    `json:"typeCode"`
+	BranchID            uuid.UUID `json:"branchId"`
+	EmploymentStartDate string    `json:"employmentStartDate"`
+	EmploymentEndDate   *string   `json:"employmentEndDate,omitempty"`
+}
+
+type Holiday struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+type FTEntry struct {
+	ID          uuid.UUID  `json:"id"`
+	EntryType   string     `json:"entryType"`
+	Quantity    float64    `json:"quantity"`
+	Status      string     `json:"status"`
+	LeaveTypeID *uuid.UUID `json:"leaveTypeId,omitempty"`
+}
+
+// PTEntry is the day's PT worklog. PaidOut is set when a paid PT payout already covers it, so
+// payroll leaves it out.
+type PTEntry struct {
+	ID             uuid.UUID `json:"id"`
+	MorningIn      *string   `json:"morningIn,omitempty"`
+	MorningOut     *string   `json:"morningOut,omitempty"`
+	MorningMinutes int       `json:"morningMinutes"`
+	EveningIn      *string   `json:"eveningIn,omitempty"`
+	EveningOut     *string   `json:"eveningOut,omitempty"`
+	EveningMinutes int       `json:"eveningMinutes"`
+	TotalMinutes   int       `json:"totalMinutes"`
+	TotalHours     float64   `json:"totalHours"`
+	Status         string    `json:"status"`
+	PaidOut        bool      `json:"paidOut"`
+}
+
+// Day is one cell of the month. FTByType sums the FT quantities per entry type; PayrollStatus
+// is empty when nothing is recorded on the day.
+type Day struct {
+	Date          string             `json:"date"`
+	Weekday       int                `json:"weekday"`
+	Employed      bool               `json:"employed"`
+	Holiday       *Holiday           `json:"holiday,omitempty"`
+	ShiftCode     string             `json:"shiftCode,omitempty"`
+	RestDay       bool               `json:"restDay"`
+	FT            []FTEntry          `json:"ft"`
+	FTByType      map[string]float64 `json:"ftByType"`
+	PT            *PTEntry           `json:"pt,omitempty"`
+	PayrollStatus string             `json:"payrollStatus,omitempty"`
+}
+
+// Totals uses the quantities and units of payroll_run_item: OT, holiday work and leave hours in
+// hours, late in minutes (early leave included, as payroll deducts it at the late rate), leave
+// in days.
+type Totals struct {
+	OTWeekdayHours   float64 `json:"otWeekdayHours"`
+	HolidayWorkHours float64 `json:"holidayWorkHours"`
+	HolidayOTHours   float64 `json:"holidayOtHours"`
+	OTHours          float64 `json:"otHours"`
+	LateMinutes      float64 `json:"lateMinutes"`
+	LeaveDays        float64 `json:"leaveDays"`
+	LeaveDoubleDays  float64 `json:"leaveDoubleDays"`
+	LeaveHours       float64 `json:"leaveHours"`
+	LeavePaidDays    float64 `json:"leavePaidDays"`
+	PTHours          float64 `json:"ptHours"`
+}
+
+// Payroll compares the month with its regular payroll run. Pending is what calculating the
+// run picks up now: pending entries from the period start to the end of the month (PT without
+// paid payouts). Item is what the run's payroll_run_item holds, once the employee is in a run.
+type Payroll struct {
+	PeriodStartDate string     `json:"periodStartDate"`
+	PeriodEndDate   string     `json:"periodEndDate"`
+	RunID           *uuid.UUID `json:"runId,omitempty"`
+	RunStatus       string     `json:"runStatus,omitempty"`
+	Pending         Totals     `json:"pending"`
+	Item            *Totals    `json:"item,omitempty"`
+}
+
+// Timesheet is one employee's month. Totals covers every entry recorded in the month whatever
+// its status.
+type Timesheet struct {
+	Employee Employee `json:"employee"`
+	Days     []Day    `json:"days"`
+	Totals   Totals   `json:"totals"`
+	Payroll  Payroll  `json:"payroll"`
+}
+
+type Response struct {
+	Month string      `json:"month"`
+	Data  []Timesheet `json:"data"`
+	Meta  struct {
+		CurrentPage int `json:"currentPage"`
+		TotalPages  int `json:"totalPages"`
+		TotalItems  int `json:"totalItems"`
+	} `json:"meta"`
+}
+
+type handler struct {
+	repo repository.TimesheetRepository
+}
+
+func NewHandler(repo repository.TimesheetRepository) *handler {
+	return &handler{repo: repo}
+}
+
+func (h *handler) Handle(ctx context.Context, q *Query) (*Response, error) {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.Limit <= 0 || q.Limit > 100 {
+		q.Limit = 20
+	}
+
+	tenant, ok := contextx.TenantFromContext(ctx)
+	if !ok {
+		return nil, errs.Unauthorized("missing tenant context")
+	}
+
+	monthStart := time.Date(q.Month.Year(), q.Month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	monthEnd := monthStart.AddDate(0, 1, -1)
+
+	emps, err := h.repo.ListEmployees(ctx, tenant, q.EmployeeID, monthStart, monthEnd, q.Page, q.Limit)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list timesheet employees", zap.Error(err))
+		return nil, errs.Internal("failed to load timesheet")
+	}
+	if q.EmployeeID != nil && emps.Total == 0 {
+		return nil, errs.NotFound("employee is not a full-time or part-time employee employed in this month")
+	}
+
+	data, err := h.build(ctx, tenant, emps.Rows, monthStart, monthEnd)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to build timesheet", zap.Error(err))
+		return nil, errs.Internal("failed to load timesheet")
+	}
+
+	totalPages := int(math.Ceil(float64(emps.Total) / float64(q.Limit)))
+	if totalPages == 0 {
+		totalPages = 1
+	}
+	resp := &Response{Month: monthStart.Format("2006-01"), Data: data}
+	resp.Meta.CurrentPage = q.Page
+	resp.Meta.TotalPages = totalPages
+	resp.Meta.TotalItems = emps.Total
+	return resp, nil
+}
+
+func (h *handler) build(ctx context.Context, tenant contextx.TenantInfo, emps []repository.TimesheetEmployee, monthStart, monthEnd time.Time) ([]Timesheet, error) {
+	out := make([]Timesheet, 0, len(emps))
+	if len(emps) == 0 {
+		return out, nil
+	}
+
+	var (
+		ids     []uuid.UUID
+		ftIDs   []uuid.UUID
+		runIDs  []uuid.UUID
+		runs    = map[uuid.UUID]*repository.PayrollRun{}
+		cals    = map[uuid.UUID]*contracts.ListHolidaysResponse{}
+		from    = monthStart
+		periods = map[uuid.UUID]time.Time{}
+	)
+	for _, e := range emps {
+		ids = append(ids, e.ID)
+		if e.TypeCode == "full_time" {
+			ftIDs = append(ftIDs, e.ID)
+		}
+		if _, ok := runs[e.BranchID]; ok {
+			continue
+		}
+		run, err := h.repo.GetRegularPayrollRun(ctx, tenant.CompanyID, e.BranchID, monthStart)
+		if err != nil {
+			return nil, err
+		}
+		runs[e.BranchID] = run
+		periods[e.BranchID] = monthStart
+		if run != nil {
+			runIDs = append(runIDs, run.ID)
+			// a run may start its period before the first of the month
+			periods[e.BranchID] = run.PeriodStartDate
+			if run.PeriodStartDate.Before(from) {
+				from = run.PeriodStartDate
+			}
+		}
+
+		branchID := e.BranchID
+		cal, err := mediator.Send[*contracts.ListHolidaysQuery, *contracts.ListHolidaysResponse](ctx, &contracts.ListHolidaysQuery{
+			CompanyID: tenant.CompanyID,
+			BranchID:  &branchID,
+			From:      monthStart,
+			To:        monthEnd,
+		})
+		if err != nil {
+			return nil, err
+		}
+		cals[e.BranchID] = cal
+	}
+
+	var shifts *contracts.ResolveShiftsResponse
+	if len(ftIDs) > 0 {
+		var err error
+		shifts, err = mediator.Send[*contracts.ResolveShiftsQuery, *contracts.ResolveShiftsResponse](ctx, &contracts.ResolveShiftsQuery{
+			CompanyID:   tenant.CompanyID,
+			EmployeeIDs: ftIDs,
+			From:        monthStart,
+			To:          monthEnd,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ftRows, err := h.repo.ListFT(ctx, ids, from, monthEnd)
+	if err != nil {
+		return nil, err
+	}
+	ptRows, err := h.repo.ListPT(ctx, ids, from, monthEnd)
+	if err != nil {
+		return nil, err
+	}
+	ftByEmp := map[uuid.UUID][]repository.FTRecord{}
+	for _, r := range ftRows {
+		ftByEmp[r.EmployeeID] = append(ftByEmp[r.EmployeeID], r)
+	}
+	ptByEmp := map[uuid.UUID][]repository.TimesheetPT{}
+	for _, r := range ptRows {
+		ptByEmp[r.EmployeeID] = append(ptByEmp[r.EmployeeID], r)
+	}
+	items := map[uuid.UUID]repository.PayrollRunItem{}
+	if len(runIDs) > 0 {
+		rows, err := h.repo.ListPayrollRunItems(ctx, runIDs, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, it := range rows {
+			items[it.EmployeeID] = it
+		}
+	}
+
+	for _, e := range emps {
+		ts := Timesheet{
+			Employee: fromEmployee(e),
+			Days:     make([]Day, 0, monthEnd.Day()),
+		}
+		ts.Payroll.PeriodStartDate = periods[e.BranchID].Format("2006-01-02")
+		ts.Payroll.PeriodEndDate = monthEnd.Format("2006-01-02")
+		if run := runs[e.BranchID]; run != nil {
+			ts.Payroll.RunID = &run.ID
+			ts.Payroll.RunStatus = run.Status
+			if it, ok := items[e.ID]; ok {
+				ts.Payroll.Item = fromItem(it)
+			}
+		}
+
+		days := map[string]*Day{}
+		for d := monthStart; !d.After(monthEnd); d = d.AddDate(0, 0, 1) {
+			ts.Days = append(ts.Days, newDay(e, d, cals[e.BranchID], shifts))
+		}
+		for i := range ts.Days {
+			days[ts.Days[i].Date] = &ts.Days[i]
+		}
+
+		periodStart := periods[e.BranchID]
+		for _, r := range ftByEmp[e.ID] {
+			inPeriod := !r.WorkDate.Before(periodStart)
+			counts := r.Status == "pending" && e.TypeCode == "full_time"
+			if counts && inPeriod {
+				ts.Payroll.Pending.addFT(r.EntryType, r.Quantity)
+			}
+			day, ok := days[r.WorkDate.Format("2006-01-02")]
+			if !ok {
+				continue
+			}
+			ts.Totals.addFT(r.EntryType, r.Quantity)
+			day.FT = append(day.FT, FTEntry{
+				ID:          r.ID,
+				EntryType:   r.EntryType,
+				Quantity:    r.Quantity,
+				Status:      r.Status,
+				LeaveTypeID: r.LeaveTypeID,
+			})
+			day.FTByType[r.EntryType] += r.Quantity
+			day.mark(counts)
+		}
+		for _, r := range ptByEmp[e.ID] {
+			inPeriod := !r.WorkDate.Before(periodStart)
+			counts := r.Status == "pending" && !r.PaidOut && e.TypeCode == "part_time"
+			if counts && inPeriod {
+				ts.Payroll.Pending.PTHours += r.TotalHours
+			}
+			day, ok := days[r.WorkDate.Format("2006-01-02")]
+			if !ok {
+				continue
+			}
+			ts.Totals.PTHours += r.TotalHours
+			item := dto.FromPT(r.PTRecord)
+			day.PT = &PTEntry{
+				ID:             item.ID,
+				MorningIn:      item.MorningIn,
+				MorningOut:     item.MorningOut,
+				MorningMinutes: item.MorningMinutes,
+				EveningIn:      item.EveningIn,
+				EveningOut:     item.EveningOut,
+				EveningMinutes: item.EveningMinutes,
+				TotalMinutes:   item.TotalMinutes,
+				TotalHours:     item.TotalHours,
+				Status:         item.Status,
+				PaidOut:        r.PaidOut,
+			}
+			day.mark(counts)
+		}
+		ts.Totals.round()
+		ts.Payroll.Pending.round()
+		out = append(out, ts)
+	}
+	return out, nil
+}
+
+func newDay(e repository.TimesheetEmployee, d time.Time, cal *contracts.ListHolidaysResponse, shifts *contracts.ResolveShiftsResponse) Day {
+	wd := int(d.Weekday())
+	if wd == 0 {
+		wd = 7
+	}
+	day := Day{
+		Date:     d.Format("2006-01-02"),
+		Weekday:  wd,
+		Employed: !d.Before(e.EmploymentStartDate) && (e.EmploymentEndDate == nil || !d.After(*e.EmploymentEndDate)),
+		FT:       []FTEntry{},
+		FTByType: map[string]float64{},
+	}
+	if h := cal.Find(d); h != nil {
+		day.Holiday = &Holiday{Name: h.Name, Kind: h.Kind}
+	}
+	if s := shifts.For(e.ID, d); s != nil {
+		day.ShiftCode = s.Code
+		day.RestDay = !s.WorksOn(d)
+	}
+	return day
+}
+
+// mark folds one entry into the day's payroll status: open wins over closed.
+func (d *Day) mark(counts bool) {
+	if counts {
+		d.PayrollStatus = PayrollOpen
+	} else if d.PayrollStatus == "" {
+		d.PayrollStatus = PayrollClosed
+	}
+}
+
+func (t *Totals) addFT(entryType string, qty float64) {
+	switch entryType {
+	case "ot":
+		t.OTWeekdayHours += qty
+		t.OTHours += qty
+	case "holiday_work":
+		t.HolidayWorkHours += qty
+		t.OTHours += qty
+	case "holiday_ot":
+		t.HolidayOTHours += qty
+		t.OTHours += qty
+	case "late", "early_leave":
+		t.LateMinutes += qty
+	case "leave_day":
+		t.LeaveDays += qty
+	case "leave_double":
+		t.LeaveDoubleDays += qty
+	case "leave_hours":
+		t.LeaveHours += qty
+	case "leave_paid":
+		t.LeavePaidDays += qty
+	}
+}
+
+// round keeps the two decimals the payroll columns store.
+func (t *Totals) round() {
+	for _, v := range []*float64{&t.OTWeekdayHours, &t.HolidayWorkHours, &t.HolidayOTHours, &t.OTHours,
+		&t.LateMinutes, &t.LeaveDays, &t.LeaveDoubleDays, &t.LeaveHours, &t.LeavePaidDays, &t.PTHours} {
+		*v = math.Round(*v*100) / 100
+	}
+}
+
+func fromEmployee(e repository.TimesheetEmployee) Employee {
+	out := Employee{
+		ID:                  e.ID,
+		EmployeeNumber:      e.EmployeeNumber,
+		FullName:            e.FirstName + " " + e.LastName,
+		TypeCode:            e.TypeCode,
+		BranchID:            e.BranchID,
+		EmploymentStartDate: e.EmploymentStartDate.Format("2006-01-02"),
+	}
+	if e.EmploymentEndDate != nil {
+		end := e.EmploymentEndDate.Format("2006-01-02")
+		out.EmploymentEndDate = &end
+	}
+	return out
+}
+
+func fromItem(it repository.PayrollRunItem) *Totals {
+	return &Totals{
+		OTWeekdayHours:   it.OTWeekdayHours,
+		HolidayWorkHours: it.HolidayWorkHours,
+		HolidayOTHours:   it.HolidayOTHours,
+		OTHours:          it.OTHours,
+		LateMinutes:      float64(it.LateMinutes),
+		LeaveDays:        it.LeaveDays,
+		LeaveDoubleDays:  it.LeaveDoubleDays,
+		LeaveHours:       it.LeaveHours,
+		PTHours:          it.PTHours,
+	}
+}
@@ -0,0 +1,107 @@
+package punch
+
+import (
+	"sort"
+	"time"
+)
+
+// DuplicateWindow is how close a scan of the same badge and state must follow the previous one
+// to count as a repeated scan rather than a new punch.
+const DuplicateWindow = 2 * time.Minute
+
+// MaxPairSpan is the longest stretch an in and an out punch can cover; punches further apart
+// are not one stretch of work.
+const MaxPairSpan = 16 * time.Hour
+
+// Duplicate is a repeated scan dropped before pairing.
+type Duplicate struct {
+	Punch
+	OfLine int
+}
+
+// Unpaired is an out punch with no in punch before it within MaxPairSpan. It is reported rather
+// than read as the start of a stretch.
+type Unpaired struct {
+	Punch
+}
+
+// Pair is an in punch and, when one follows within MaxPairSpan, its out punch.
+type Pair struct {
+	In      time.Time
+	Out     *time.Time
+	InLine  int
+	OutLine int
+}
+
+// Overnight reports whether the pair ends on a later calendar day than it starts.
+func (p Pair) Overnight() bool {
+	return p.Out != nil && p.Out.Format("2006-01-02") != p.In.Format("2006-01-02")
+}
+
+// Day is a badge's pairs that start on WorkDate, in order.
+type Day struct {
+	Badge    string
+	WorkDate time.Time
+	Pairs    []Pair
+}
+
+// Group sorts each badge's punches, drops repeated scans and pairs the rest into stretches. An in
+// punch is paired with the punch after it unless that one is recorded as another in, so a
+// stretch with no out is left open; an out punch that does not close a stretch is returned as
+// unpaired. Punches without a state are paired in order. A pair belongs to the day its in punch
+// falls on, so an overnight stretch stays on the day it started. Punches with Err are ignored.
+// Days are ordered by badge and date.
+func Group(punches []Punch) ([]Day, []Duplicate, []Unpaired) {
+	byBadge := map[string][]Punch{}
+	var badges []string
+	for _, p := range punches {
+		if p.Err != nil {
+			continue
+		}
+		if _, ok := byBadge[p.Badge]; !ok {
+			badges = append(badges, p.Badge)
+		}
+		byBadge[p.Badge] = append(byBadge[p.Badge], p)
+	}
+	sort.Strings(badges)
+
+	var (
+		days     []Day
+		dups     []Duplicate
+		unpaired []Unpaired
+	)
+	for _, badge := range badges {
+		ps := byBadge[badge]
+		sort.SliceStable(ps, func(i, j int) bool { return ps[i].Time.Before(ps[j].Time) })
+
+		kept := ps[:0:0]
+		for _, p := range ps {
+			if n := len(kept); n > 0 && p.State == kept[n-1].State && p.Time.Sub(kept[n-1].Time) <= DuplicateWindow {
+				dups = append(dups, Duplicate{Punch: p, OfLine: kept[n-1].Line})
+				continue
+			}
+			kept = append(kept, p)
+		}
+
+		var cur *Day
+		for i := 0; i < len(kept); i++ {
+			if kept[i].State == StateOut {
+				unpaired = append(unpaired, Unpaired{Punch: kept[i]})
+				continue
+			}
+			pair := Pair{In: kept[i].Time, InLine: kept[i].Line}
+			if i+1 < len(kept) && kept[i+1].State != StateIn && kept[i+1].Time.Sub(kept[i].Time) <= MaxPairSpan {
+				out := kept[i+1].Time
+				pair.Out, pair.OutLine = &out, kept[i+1].Line
+				i++
+			}
+			date := time.Date(pair.In.Year(), pair.In.Month(), pair.In.Day(), 0, 0, 0, 0, time.UTC)
+			if cur == nil || !cur.WorkDate.Equal(date) {
+				days = append(days, Day{Badge: badge, WorkDate: date})
+				cur = &days[len(days)-1]
+			}
+			cur.Pairs = append(cur.Pairs, pair)
+		}
+	}
+	return days, dups, unpaired
+}
@@ -0,0 +1,120 @@
+package punch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGroup(t *testing.T) {
+	at := func(day, h, m int) time.Time { return time.Date(2026, 2, day, h, m, 0, 0, time.UTC) }
+	type pair struct{ in, out int } // lines; out 0 when open
+	tests := []struct {
+		name     string
+		punches  []Punch
+		want     map[int][]pair // day of month -> pairs
+		unpaired []int
+		dups     []int
+	}{
+		{
+			name: "no state pairs in order",
+			punches: []Punch{
+				{Line: 1, Time: at(2, 8, 0)},
+				{Line: 2, Time: at(2, 12, 0)},
+				{Line: 3, Time: at(2, 13, 0)},
+			},
+			want: map[int][]pair{2: {{1, 2}, {3, 0}}},
+		},
+		{
+			name: "out before any in is reported, not read as an in",
+			punches: []Punch{
+				{Line: 1, Time: at(2, 6, 0), State: StateOut},
+				{Line: 2, Time: at(2, 22, 0), State: StateIn},
+				{Line: 3, Time: at(3, 6, 0), State: StateOut},
+			},
+			want:     map[int][]pair{2: {{2, 3}}},
+			unpaired: []int{1},
+		},
+		{
+			name: "in followed by another in is left open",
+			punches: []Punch{
+				{Line: 1, Time: at(2, 8, 0), State: StateIn},
+				{Line: 2, Time: at(2, 8, 30), State: StateIn},
+				{Line: 3, Time: at(2, 17, 0), State: StateOut},
+			},
+			want: map[int][]pair{2: {{1, 0}, {2, 3}}},
+		},
+		{
+			name: "two outs in a row",
+			punches: []Punch{
+				{Line: 1, Time: at(2, 8, 0), State: StateIn},
+				{Line: 2, Time: at(2, 17, 0), State: StateOut},
+				{Line: 3, Time: at(2, 18, 0), State: StateOut},
+			},
+			want:     map[int][]pair{2: {{1, 2}}},
+			unpaired: []int{3},
+		},
+		{
+			name: "out past MaxPairSpan",
+			punches: []Punch{
+				{Line: 1, Time: at(2, 6, 0), State: StateIn},
+				{Line: 2, Time: at(2, 23, 0), State: StateOut},
+			},
+			want:     map[int][]pair{2: {{1, 0}}},
+			unpaired: []int{2},
+		},
+		{
+			name: "repeated scan of the same state",
+			punches: []Punch{
+				{Line: 1, Time: at(2, 8, 0), State: StateIn},
+				{Line: 2, Time: at(2, 8, 1), State: StateIn},
+				{Line: 3, Time: at(2, 8, 2), State: StateOut},
+			},
+			want: map[int][]pair{2: {{1, 3}}},
+			dups: []int{2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := range tt.punches {
+				tt.punches[i].Badge = "7"
+			}
+			days, dups, unpaired := Group(tt.punches)
+
+			got := map[int][]pair{}
+			for _, d := range days {
+				for _, p := range d.Pairs {
+					got[d.WorkDate.Day()] = append(got[d.WorkDate.Day()], pair{p.InLine, p.OutLine})
+				}
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Group() days = %v, want %v", got, tt.want)
+			}
+			for day, want := range tt.want {
+				if len(got[day]) != len(want) {
+					t.Fatalf("Group() day %d = %v, want %v", day, got[day], want)
+				}
+				for i := range want {
+					if got[day][i] != want[i] {
+						t.Errorf("Group() day %d pair %d = %v, want %v", day, i, got[day][i], want[i])
+					}
+				}
+			}
+			if len(unpaired) != len(tt.unpaired) {
+				t.Fatalf("Group() unpaired = %+v, want lines %v", unpaired, tt.unpaired)
+			}
+			for i, u := range unpaired {
+				if u.Line != tt.unpaired[i] {
+					t.Errorf("Group() unpaired %d = line %d, want %d", i, u.Line, tt.unpaired[i])
+				}
+			}
+			if len(dups) != len(tt.dups) {
+				t.Fatalf("Group() duplicates = %+v, want lines %v", dups, tt.dups)
+			}
+			for i, d := range dups {
+				if d.Line != tt.dups[i] {
+					t.Errorf("Group() duplicate %d = line %d, want %d", i, d.Line, tt.dups[i])
+				}
+			}
+		})
+	}
+}
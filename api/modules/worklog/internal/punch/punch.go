@@ -0,0 +1,401 @@
+// Package punch reads punch logs exported from fingerprint and face-scan terminals: the
+// ZKTeco attendance log (attlog.dat), the ZKTeco CSV export, and any CSV described by a Mapping.
+package punch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Punch is one scan of a badge. A line whose badge or time cannot be read is returned with Err
+// set so the caller can report it.
+type Punch struct {
+	Line  int
+	Badge string
+	Time  time.Time
+	State State
+	Err   error
+}
+
+// State is whether the terminal recorded the scan as going in or out. Files without a state
+// column leave it StateUnknown.
+type State int
+
+const (
+	StateUnknown State = iota
+	StateIn
+	StateOut
+)
+
+// Date orders for dates that do not start with the year
+const (
+	DMY = "DMY"
+	MDY = "MDY"
+	YMD = "YMD"
+)
+
+// Mapping describes a generic CSV file. A column is a header name (case-insensitive) or a
+// 1-based column number. The time of a punch is either one DateTime column or a Date and a
+// Time column.
+type Mapping struct {
+	Delimiter      rune
+	HasHeader      bool
+	BadgeColumn    string
+	DateTimeColumn string
+	DateColumn     string
+	TimeColumn     string
+	StateColumn    string
+	DateOrder      string
+}
+
+// ParseZKTeco reads a ZKTeco export. A file whose first line has a header is read as the CSV
+// export; otherwise it is the attendance log, one "badge<TAB>YYYY-MM-DD HH:MM:SS<TAB>device<TAB>
+// state<TAB>verify<TAB>work code" per line.
+func ParseZKTeco(data []byte, dateOrder string) ([]Punch, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	first := firstLine(data)
+	if first == "" {
+		return nil, fmt.Errorf("file is empty")
+	}
+	if hasLetters(first) {
+		m, err := zkMapping(first, dateOrder)
+		if err != nil {
+			return nil, err
+		}
+		return ParseCSV(data, m)
+	}
+	return parseAttLog(data, dateOrder)
+}
+
+func parseAttLog(data []byte, dateOrder string) ([]Punch, error) {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	var out []Punch
+	no := 0
+	for sc.Scan() {
+		no++
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		p := Punch{Line: no}
+		f := strings.Fields(line)
+		switch {
+		case len(f) < 3:
+			p.Err = fmt.Errorf("expected badge, date and time")
+		default:
+			p.Badge = f[0]
+			p.Time, p.Err = ParseDateTime(f[1]+" "+f[2], dateOrder)
+			if len(f) > 4 {
+				p.State = ParseState(f[4])
+			}
+		}
+		out = append(out, p)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// zk header names, normalised by normalise
+var (
+	zkBadgeHeaders    = []string{"acno", "no", "userid", "enrollno", "enrollnumber", "pin", "badgenumber", "personid", "employeeid", "empno", "id"}
+	zkDateTimeHeaders = []string{"datetime", "checktime", "punchtime", "verifytime", "attendancetime", "time"}
+	zkStateHeaders    = []string{"state", "status", "checktype", "inoutmode", "inout", "attstate", "punchstate"}
+)
+
+func zkMapping(header, dateOrder string) (Mapping, error) {
+	delim := detectDelimiter(header)
+	r := csv.NewReader(strings.NewReader(header))
+	r.Comma = delim
+	cols, err := r.Read()
+	if err != nil {
+		return Mapping{}, fmt.Errorf("cannot read header: %w", err)
+	}
+	names := map[string]int{}
+	for i, c := range cols {
+		if _, ok := names[normalise(c)]; !ok {
+			names[normalise(c)] = i
+		}
+	}
+	m := Mapping{Delimiter: delim, HasHeader: true, DateOrder: dateOrder}
+	for _, h := range zkBadgeHeaders {
+		if i, ok := names[h]; ok {
+			m.BadgeColumn = strconv.Itoa(i + 1)
+			break
+		}
+	}
+	_, hasDate := names["date"]
+	for _, h := range zkDateTimeHeaders {
+		if i, ok := names[h]; ok {
+			if h == "time" && hasDate {
+				m.DateColumn = strconv.Itoa(names["date"] + 1)
+				m.TimeColumn = strconv.Itoa(i + 1)
+			} else {
+				m.DateTimeColumn = strconv.Itoa(i + 1)
+			}
+			break
+		}
+	}
+	for _, h := range zkStateHeaders {
+		if i, ok := names[h]; ok {
+			m.StateColumn = strconv.Itoa(i + 1)
+			break
+		}
+	}
+	if m.BadgeColumn == "" {
+		return Mapping{}, fmt.Errorf("no badge column (AC-No., No., User ID, PIN) in header")
+	}
+	if m.DateTimeColumn == "" && m.TimeColumn == "" {
+		return Mapping{}, fmt.Errorf("no time column (Time, Date/Time, Check Time) in header")
+	}
+	return m, nil
+}
+
+// ParseCSV reads a CSV file with the given mapping.
+func ParseCSV(data []byte, m Mapping) ([]Punch, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
+	r := csv.NewReader(bytes.NewReader(data))
+	if m.Delimiter != 0 {
+		r.Comma = m.Delimiter
+	}
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	r.TrimLeadingSpace = true
+
+	var header []string
+	if m.HasHeader {
+		h, err := r.Read()
+		if err != nil {
+			return nil, fmt.Errorf("cannot read header: %w", err)
+		}
+		header = h
+	}
+	badge, err := columnIndex(header, m.BadgeColumn, "badge")
+	if err != nil {
+		return nil, err
+	}
+	dt, dcol, tcol := -1, -1, -1
+	if m.DateTimeColumn != "" {
+		if dt, err = columnIndex(header, m.DateTimeColumn, "date-time"); err != nil {
+			return nil, err
+		}
+	} else {
+		if dcol, err = columnIndex(header, m.DateColumn, "date"); err != nil {
+			return nil, err
+		}
+		if tcol, err = columnIndex(header, m.TimeColumn, "time"); err != nil {
+			return nil, err
+		}
+	}
+	state := -1
+	if m.StateColumn != "" {
+		if state, err = columnIndex(header, m.StateColumn, "state"); err != nil {
+			return nil, err
+		}
+	}
+
+	var out []Punch
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var pe *csv.ParseError
+			if !errors.As(err, &pe) {
+				return nil, err
+			}
+			out = append(out, Punch{Line: pe.Line, Err: pe.Err})
+			continue
+		}
+		line, _ := r.FieldPos(0)
+		if len(rec) == 1 && strings.TrimSpace(rec[0]) == "" {
+			continue
+		}
+		p := Punch{Line: line}
+		field := func(i int) string {
+			if i >= 0 && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		p.Badge = field(badge)
+		p.State = ParseState(field(state))
+		value := field(dt)
+		if dt < 0 {
+			value = field(dcol) + " " + field(tcol)
+		}
+		switch {
+		case p.Badge == "":
+			p.Err = fmt.Errorf("missing badge number")
+		default:
+			p.Time, p.Err = ParseDateTime(value, m.DateOrder)
+		}
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// ParseState reads the in/out state of a punch: a ZKTeco state code (0 check-in, 1 check-out,
+// 2 break-out, 3 break-in, 4 OT-in, 5 OT-out) or a label such as "C/In", "Check Out", "I", "O",
+// "เข้า" or "ออก". Anything else is StateUnknown.
+func ParseState(s string) State {
+	switch {
+	case strings.Contains(s, "ออก"):
+		return StateOut
+	case strings.Contains(s, "เข้า"):
+		return StateIn
+	}
+	v := normalise(s)
+	switch v {
+	case "0", "3", "4", "i":
+		return StateIn
+	case "1", "2", "5", "o":
+		return StateOut
+	}
+	switch {
+	case strings.HasSuffix(v, "out"):
+		return StateOut
+	case strings.HasSuffix(v, "in"):
+		return StateIn
+	}
+	return StateUnknown
+}
+
+func columnIndex(header []string, ref, what string) (int, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return -1, fmt.Errorf("%s column is required", what)
+	}
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 1 {
+			return -1, fmt.Errorf("%s column must be 1 or more", what)
+		}
+		return n - 1, nil
+	}
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(h), ref) || normalise(h) == normalise(ref) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("%s column %q not found in header", what, ref)
+}
+
+// ParseDateTime reads "date time" as written by the terminals: the date is Y-M-D when it starts
+// with a four-digit year and otherwise follows dateOrder (DMY when empty); the time is H:MM with
+// optional seconds and AM/PM. Years in the Buddhist era are converted.
+func ParseDateTime(s, dateOrder string) (time.Time, error) {
+	s = strings.TrimSpace(strings.Replace(s, "T", " ", 1))
+	datePart, timePart, ok := strings.Cut(s, " ")
+	if !ok {
+		return time.Time{}, fmt.Errorf("invalid date-time %q", s)
+	}
+	parts := strings.FieldsFunc(datePart, func(r rune) bool { return r == '-' || r == '/' || r == '.' })
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("invalid date %q", datePart)
+	}
+	n := make([]int, 3)
+	for i, p := range parts {
+		v, err := strconv.Atoi(p)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q", datePart)
+		}
+		n[i] = v
+	}
+	var y, m, d int
+	switch {
+	case len(parts[0]) == 4 || dateOrder == YMD:
+		y, m, d = n[0], n[1], n[2]
+	case dateOrder == MDY:
+		m, d, y = n[0], n[1], n[2]
+	default:
+		d, m, y = n[0], n[1], n[2]
+	}
+	if y < 100 {
+		y += 2000
+	}
+	if y > 2400 {
+		y -= 543
+	}
+
+	timePart = strings.ToUpper(strings.Join(strings.Fields(timePart), " "))
+	pm := strings.HasSuffix(timePart, "PM")
+	am := strings.HasSuffix(timePart, "AM")
+	timePart = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(timePart, "PM"), "AM"))
+	tp := strings.Split(timePart, ":")
+	if len(tp) < 2 || len(tp) > 3 {
+		return time.Time{}, fmt.Errorf("invalid time %q", timePart)
+	}
+	hms := make([]int, 3)
+	for i, p := range tp {
+		v, err := strconv.Atoi(p)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time %q", timePart)
+		}
+		hms[i] = v
+	}
+	if am || pm {
+		if hms[0] < 1 || hms[0] > 12 {
+			return time.Time{}, fmt.Errorf("invalid time %q", timePart)
+		}
+		hms[0] %= 12
+		if pm {
+			hms[0] += 12
+		}
+	}
+	if m < 1 || m > 12 || d < 1 || d > 31 || hms[0] > 23 || hms[1] > 59 || hms[2] > 59 {
+		return time.Time{}, fmt.Errorf("invalid date-time %q", s)
+	}
+	t := time.Date(y, time.Month(m), d, hms[0], hms[1], hms[2], 0, time.UTC)
+	if t.Day() != d {
+		return time.Time{}, fmt.Errorf("invalid date %q", datePart)
+	}
+	return t, nil
+}
+
+func firstLine(data []byte) string {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		if l := strings.TrimSpace(sc.Text()); l != "" {
+			return l
+		}
+	}
+	return ""
+}
+
+func hasLetters(s string) bool {
+	for _, r := range s {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r > 127 {
+			return true
+		}
+	}
+	return false
+}
+
+func detectDelimiter(header string) rune {
+	best, count := ',', strings.Count(header, ",")
+	for _, d := range []rune{'\t', ';'} {
+		if c := strings.Count(header, string(d)); c > count {
+			best, count = d, c
+		}
+	}
+	return best
+}
+
+func normalise(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
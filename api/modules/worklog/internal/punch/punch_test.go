@@ -0,0 +1,59 @@
+package punch
+
+import "testing"
+
+func TestParseState(t *testing.T) {
+	tests := []struct {
+		in   string
+		want State
+	}{
+		{"0", StateIn},
+		{"1", StateOut},
+		{"2", StateOut},
+		{"3", StateIn},
+		{"4", StateIn},
+		{"5", StateOut},
+		{"C/In", StateIn},
+		{"C/Out", StateOut},
+		{"Check In", StateIn},
+		{"OverTime Out", StateOut},
+		{"Break Out", StateOut},
+		{"I", StateIn},
+		{"o", StateOut},
+		{"เข้างาน", StateIn},
+		{"ออกงาน", StateOut},
+		{"", StateUnknown},
+		{"15", StateUnknown},
+	}
+	for _, tt := range tests {
+		if got := ParseState(tt.in); got != tt.want {
+			t.Errorf("ParseState(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseZKTecoState(t *testing.T) {
+	attlog := "1\t2026-02-02 08:01:00\t1\t0\t1\t0\n1\t2026-02-02 17:05:00\t1\t1\t1\t0\n2\t2026-02-02 08:00:00\n"
+	ps, err := ParseZKTeco([]byte(attlog), "")
+	if err != nil {
+		t.Fatalf("ParseZKTeco() error = %v", err)
+	}
+	want := []State{StateIn, StateOut, StateUnknown}
+	if len(ps) != len(want) {
+		t.Fatalf("ParseZKTeco() read %d punches, want %d", len(ps), len(want))
+	}
+	for i, p := range ps {
+		if p.Err != nil || p.State != want[i] {
+			t.Errorf("punch %d = %+v, want state %v", i+1, p, want[i])
+		}
+	}
+
+	export := "AC-No.,Name,Time,State\n7,Somchai,02/02/2026 08:01,C/In\n7,Somchai,02/02/2026 17:05,C/Out\n"
+	ps, err = ParseZKTeco([]byte(export), DMY)
+	if err != nil {
+		t.Fatalf("ParseZKTeco() error = %v", err)
+	}
+	if len(ps) != 2 || ps[0].State != StateIn || ps[1].State != StateOut {
+		t.Errorf("ParseZKTeco() CSV export = %+v, want in then out", ps)
+	}
+}
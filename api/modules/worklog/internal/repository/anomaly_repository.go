@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/storage/sqldb/transactor"
+)
+
+// AnomalyRepository reads the worklogs, clock days and employees the anomaly report scans.
+type AnomalyRepository struct {
+	dbCtx transactor.DBTXContext
+}
+
+func NewAnomalyRepository(dbCtx transactor.DBTXContext) AnomalyRepository {
+	return AnomalyRepository{dbCtx: dbCtx}
+}
+
+// tenantWhere filters the worklog table aliased wl by the tenant's employees, the date range
+// and optionally one employee.
+func (r AnomalyRepository) tenantWhere(tenant contextx.TenantInfo, employeeID *uuid.UUID, from, to time.Time) (string, []interface{}) {
+	where := []string{"wl.deleted_at IS NULL", "e.company_id = $1", "wl.work_date BETWEEN $2 AND $3"}
+	args := []interface{}{tenant.CompanyID, from, to}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where = append(where, fmt.Sprintf("e.branch_id = $%d", len(args)))
+	}
+	if employeeID != nil {
+		args = append(args, *employeeID)
+		where = append(where, fmt.Sprintf("wl.employee_id = $%d", len(args)))
+	}
+	return strings.Join(where, " AND "), args
+}
+
+// ListFT returns the tenant's FT worklogs between from and to, by employee and date.
+func (r AnomalyRepository) ListFT(ctx context.Context, tenant contextx.TenantInfo, employeeID *uuid.UUID, from, to time.Time) ([]FTRecord, error) {
+	db := r.dbCtx(ctx)
+	where, args := r.tenantWhere(tenant, employeeID, from, to)
+	var out []FTRecord
+	err := db.SelectContext(ctx, &out, fmt.Sprintf(`
+SELECT wl.* FROM worklog_ft wl
+JOIN employees e ON e.id = wl.employee_id
+WHERE %s
+ORDER BY wl.employee_id, wl.work_date`, where), args...)
+	return out, err
+}
+
+// ListPT returns the tenant's PT worklogs between from and to, by employee and date.
+func (r AnomalyRepository) ListPT(ctx context.Context, tenant contextx.TenantInfo, employeeID *uuid.UUID, from, to time.Time) ([]PTRecord, error) {
+	db := r.dbCtx(ctx)
+	where, args := r.tenantWhere(tenant, employeeID, from, to)
+	var out []PTRecord
+	err := db.SelectContext(ctx, &out, fmt.Sprintf(`
+SELECT wl.* FROM worklog_pt wl
+JOIN employees e ON e.id = wl.employee_id
+WHERE %s
+ORDER BY wl.employee_id, wl.work_date`, where), args...)
+	return out, err
+}
+
+// ClockDay is a day an employee has a clock record.
+type ClockDay struct {
+	EmployeeID uuid.UUID `db:"employee_id"`
+	WorkDate   time.Time `db:"work_date"`
+}
+
+// ListClockDays returns the days between from and to the tenant's employees have clock records.
+func (r AnomalyRepository) ListClockDays(ctx context.Context, tenant contextx.TenantInfo, employeeID *uuid.UUID, from, to time.Time) ([]ClockDay, error) {
+	db := r.dbCtx(ctx)
+	where, args := r.tenantWhere(tenant, employeeID, from, to)
+	var out []ClockDay
+	err := db.SelectContext(ctx, &out, fmt.Sprintf(`
+SELECT wl.employee_id, wl.work_date FROM worklog_clock wl
+JOIN employees e ON e.id = wl.employee_id
+WHERE %s`, where), args...)
+	return out, err
+}
+
+// ListEmployees returns the employees by id, including those who have left.
+func (r AnomalyRepository) ListEmployees(ctx context.Context, ids []uuid.UUID) ([]TimesheetEmployee, error) {
+	db := r.dbCtx(ctx)
+	var out []TimesheetEmployee
+	err := db.SelectContext(ctx, &out, `
+SELECT e.id, e.employee_number, e.first_name, e.last_name, e.branch_id, COALESCE(et.code, '') AS type_code,
+       e.employment_start_date, e.employment_end_date
+FROM employees e
+LEFT JOIN employee_type et ON et.id = e.employee_type_id
+WHERE e.id = ANY($1)
+ORDER BY e.employee_number`, pq.Array(ids))
+	return out, err
+}
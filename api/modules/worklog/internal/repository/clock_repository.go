@@ -0,0 +1,284 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/storage/sqldb/transactor"
+)
+
+type ClockRepository struct {
+	dbCtx transactor.DBTXContext
+}
+
+// ClockRecord is a full-time employee's actual clock-in and clock-out for a work date, in local
+// wall-clock time. The shift and minutes are filled in when worklog entries are generated from it.
+type ClockRecord struct {
+	ID                 uuid.UUID  `db:"id"`
+	CompanyID          uuid.UUID  `db:"company_id"`
+	BranchID           uuid.UUID  `db:"branch_id"`
+	EmployeeID         uuid.UUID  `db:"employee_id"`
+	WorkDate           time.Time  `db:"work_date"`
+	ClockIn            time.Time  `db:"clock_in"`
+	ClockOut           *time.Time `db:"clock_out"`
+	Source             string     `db:"source"`
+	Note               *string    `db:"note"`
+	ShiftID            *uuid.UUID `db:"shift_id"`
+	LateMinutes        *int       `db:"late_minutes"`
+	EarlyMinutes       *int       `db:"early_minutes"`
+	OTMinutes          *int       `db:"ot_minutes"`
+	HolidayWorkMinutes *int       `db:"holiday_work_minutes"`
+	ProcessedAt        *time.Time `db:"processed_at"`
+	CreatedAt          time.Time  `db:"created_at"`
+	CreatedBy          uuid.UUID  `db:"created_by"`
+	UpdatedAt          time.Time  `db:"updated_at"`
+	UpdatedBy          uuid.UUID  `db:"updated_by"`
+	DeletedAt          *time.Time `db:"deleted_at"`
+	DeletedBy          *uuid.UUID `db:"deleted_by"`
+}
+
+type ClockListResult struct {
+	Rows  []ClockRecord
+	Total int
+}
+
+// ClockFilter narrows the clock records; zero values mean no filter.
+type ClockFilter struct {
+	EmployeeID  *uuid.UUID
+	EmployeeIDs []uuid.UUID
+	StartDate   *time.Time
+	EndDate     *time.Time
+	// Processed filters on whether entries were generated: "yes", "no" or "" for both.
+	Processed string
+}
+
+func NewClockRepository(dbCtx transactor.DBTXContext) ClockRepository {
+	return ClockRepository{dbCtx: dbCtx}
+}
+
+func (r ClockRepository) where(tenant contextx.TenantInfo, f ClockFilter) ([]string, []interface{}) {
+	where := []string{"wc.deleted_at IS NULL", "wc.company_id = $1"}
+	args := []interface{}{tenant.CompanyID}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where = append(where, fmt.Sprintf("wc.branch_id = $%d", len(args)))
+	}
+	if f.EmployeeID != nil {
+		args = append(args, *f.EmployeeID)
+		where = append(where, fmt.Sprintf("wc.employee_id = $%d", len(args)))
+	}
+	if len(f.EmployeeIDs) > 0 {
+		args = append(args, pq.Array(f.EmployeeIDs))
+		where = append(where, fmt.Sprintf("wc.employee_id = ANY($%d)", len(args)))
+	}
+	if f.StartDate != nil {
+		args = append(args, *f.StartDate)
+		where = append(where, fmt.Sprintf("wc.work_date >= $%d", len(args)))
+	}
+	if f.EndDate != nil {
+		args = append(args, *f.EndDate)
+		where = append(where, fmt.Sprintf("wc.work_date <= $%d", len(args)))
+	}
+	switch f.Processed {
+	case "yes":
+		where = append(where, "wc.processed_at IS NOT NULL")
+	case "no":
+		where = append(where, "wc.processed_at IS NULL")
+	}
+	return where, args
+}
+
+func (r ClockRepository) List(ctx context.Context, tenant contextx.TenantInfo, page, limit int, f ClockFilter) (ClockListResult, error) {
+	db := r.dbCtx(ctx)
+	where, args := r.where(tenant, f)
+	whereClause := strings.Join(where, " AND ")
+
+	var total int
+	if err := db.GetContext(ctx, &total, fmt.Sprintf(`SELECT COUNT(1) FROM worklog_clock wc WHERE %s`, whereClause), args...); err != nil {
+		return ClockListResult{}, err
+	}
+
+	args = append(args, limit, (page-1)*limit)
+	q := fmt.Sprintf(`
+SELECT wc.* FROM worklog_clock wc
+WHERE %s
+ORDER BY wc.work_date DESC, wc.clock_in DESC
+LIMIT $%d OFFSET $%d`, whereClause, len(args)-1, len(args))
+	var rows []ClockRecord
+	if err := db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return ClockListResult{}, err
+	}
+	return ClockListResult{Rows: rows, Total: total}, nil
+}
+
+// ListForGenerate returns the clock records in the range ordered by employee and date.
+func (r ClockRepository) ListForGenerate(ctx context.Context, tenant contextx.TenantInfo, f ClockFilter) ([]ClockRecord, error) {
+	db := r.dbCtx(ctx)
+	where, args := r.where(tenant, f)
+	q := fmt.Sprintf(`SELECT wc.* FROM worklog_clock wc WHERE %s ORDER BY wc.employee_id, wc.work_date`, strings.Join(where, " AND "))
+	var rows []ClockRecord
+	if err := db.SelectContext(ctx, &rows, q, args...); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r ClockRepository) Get(ctx context.Context, tenant contextx.TenantInfo, id uuid.UUID) (*ClockRecord, error) {
+	db := r.dbCtx(ctx)
+	q := `SELECT * FROM worklog_clock WHERE id = $1 AND company_id = $2 AND deleted_at IS NULL`
+	args := []interface{}{id, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		q += " AND branch_id = $3"
+		args = append(args, tenant.BranchID)
+	}
+	var rec ClockRecord
+	if err := db.GetContext(ctx, &rec, q, args...); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// ClockEmployee is what a clock record needs to know about the employee.
+type ClockEmployee struct {
+	BranchID uuid.UUID `db:"branch_id"`
+	FullTime bool      `db:"full_time"`
+}
+
+// GetEmployee returns the employee in the tenant, or sql.ErrNoRows.
+func (r ClockRepository) GetEmployee(ctx context.Context, tenant contextx.TenantInfo, employeeID uuid.UUID) (*ClockEmployee, error) {
+	db := r.dbCtx(ctx)
+	q := `
+SELECT e.branch_id, COALESCE(et.code = 'full_time', FALSE) AS full_time
+FROM employees e
+LEFT JOIN employee_type et ON et.id = e.employee_type_id
+WHERE e.id = $1 AND e.company_id = $2 AND e.deleted_at IS NULL`
+	args := []interface{}{employeeID, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		q += " AND e.branch_id = $3"
+		args = append(args, tenant.BranchID)
+	}
+	var out ClockEmployee
+	if err := db.GetContext(ctx, &out, q, args...); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetByEmployeeDate returns the employee's clock record for the day, or sql.ErrNoRows.
+func (r ClockRepository) GetByEmployeeDate(ctx context.Context, employeeID uuid.UUID, workDate time.Time) (*ClockRecord, error) {
+	db := r.dbCtx(ctx)
+	var rec ClockRecord
+	if err := db.GetContext(ctx, &rec, `SELECT * FROM worklog_clock WHERE employee_id = $1 AND work_date = $2 AND deleted_at IS NULL`, employeeID, workDate); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+// BadgeEmployee is an employee a badge number on a punch log can belong to.
+type BadgeEmployee struct {
+	ID                uuid.UUID  `db:"id"`
+	EmployeeNumber    string     `db:"employee_number"`
+	BranchID          uuid.UUID  `db:"branch_id"`
+	FullTime          bool       `db:"full_time"`
+	EmploymentEndDate *time.Time `db:"employment_end_date"`
+}
+
+// ListBadgeEmployees returns the tenant's employees, including those who have left, so punches
+// recorded before they left can still be matched.
+func (r ClockRepository) ListBadgeEmployees(ctx context.Context, tenant contextx.TenantInfo) ([]BadgeEmployee, error) {
+	db := r.dbCtx(ctx)
+	q := `
+SELECT e.id, e.employee_number, e.branch_id, COALESCE(et.code = 'full_time', FALSE) AS full_time, e.employment_end_date
+FROM employees e
+LEFT JOIN employee_type et ON et.id = e.employee_type_id
+WHERE e.company_id = $1 AND e.deleted_at IS NULL`
+	args := []interface{}{tenant.CompanyID}
+	if tenant.HasBranchID() {
+		q += " AND e.branch_id = $2"
+		args = append(args, tenant.BranchID)
+	}
+	var out []BadgeEmployee
+	if err := db.SelectContext(ctx, &out, q, args...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Upsert records the employee's clock times for the day, replacing the times already recorded.
+// Replacing the times clears the generated minutes so the day is processed again.
+func (r ClockRepository) Upsert(ctx context.Context, rec ClockRecord, actor uuid.UUID) (*ClockRecord, bool, error) {
+	db := r.dbCtx(ctx)
+	const q = `
+INSERT INTO worklog_clock (company_id, branch_id, employee_id, work_date, clock_in, clock_out, source, note, created_by, updated_by)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+ON CONFLICT (employee_id, work_date) WHERE deleted_at IS NULL
+DO UPDATE SET clock_in = EXCLUDED.clock_in, clock_out = EXCLUDED.clock_out, source = EXCLUDED.source,
+              note = COALESCE(EXCLUDED.note, worklog_clock.note), updated_by = EXCLUDED.updated_by,
+              shift_id = NULL, late_minutes = NULL, early_minutes = NULL, ot_minutes = NULL,
+              holiday_work_minutes = NULL, processed_at = NULL
+RETURNING *, (xmax = 0) AS inserted`
+	var out struct {
+		ClockRecord
+		Inserted bool `db:"inserted"`
+	}
+	if err := db.GetContext(ctx, &out, q, rec.CompanyID, rec.BranchID, rec.EmployeeID, rec.WorkDate,
+		rec.ClockIn, rec.ClockOut, rec.Source, rec.Note, actor); err != nil {
+		return nil, false, err
+	}
+	return &out.ClockRecord, out.Inserted, nil
+}
+
+// SetResult stores what generation worked out for the record.
+func (r ClockRepository) SetResult(ctx context.Context, id uuid.UUID, shiftID uuid.UUID, late, early, ot, holidayWork int, actor uuid.UUID) error {
+	db := r.dbCtx(ctx)
+	_, err := db.ExecContext(ctx, `
+UPDATE worklog_clock
+SET shift_id = $2, late_minutes = $3, early_minutes = $4, ot_minutes = $5, holiday_work_minutes = $6,
+    processed_at = now(), updated_by = $7
+WHERE id = $1`, id, shiftID, late, early, ot, holidayWork, actor)
+	return err
+}
+
+func (r ClockRepository) SoftDelete(ctx context.Context, tenant contextx.TenantInfo, id uuid.UUID, actor uuid.UUID) error {
+	db := r.dbCtx(ctx)
+	q := `UPDATE worklog_clock SET deleted_at = now(), deleted_by = $1, updated_by = $1
+WHERE id = $2 AND company_id = $3 AND deleted_at IS NULL`
+	args := []interface{}{actor, id, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		q += " AND branch_id = $4"
+		args = append(args, tenant.BranchID)
+	}
+	res, err := db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+	if rows, _ := res.RowsAffected(); rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DayEntry is an FT entry type recorded for an employee on a date.
+type DayEntry struct {
+	EmployeeID uuid.UUID `db:"employee_id"`
+	WorkDate   time.Time `db:"work_date"`
+	EntryType  string    `db:"entry_type"`
+}
+
+// ListDayEntries returns the FT entry types recorded in the company between from and to.
+func (r ClockRepository) ListDayEntries(ctx context.Context, companyID uuid.UUID, from, to time.Time) ([]DayEntry, error) {
+	db := r.dbCtx(ctx)
+	var out []DayEntry
+	err := db.SelectContext(ctx, &out, `
+SELECT employee_id, work_date, entry_type
+FROM worklog_ft
+WHERE company_id = $1 AND work_date BETWEEN $2 AND $3 AND deleted_at IS NULL`, companyID, from, to)
+	return out, err
+}
@@ -20,20 +20,22 @@ type FTRepository struct {
 }
 
 type FTRecord struct {
-	ID         uuid.UUID  `db:"id"`
-	CompanyID  uuid.UUID  `db:"company_id"`
-	BranchID   uuid.UUID  `db:"branch_id"`
-	EmployeeID uuid.UUID  `db:"employee_id"`
-	EntryType  string     `db:"entry_type"`
-	WorkDate   time.Time  `db:"work_date"`
-	Quantity   float64    `db:"quantity"`
-	Status     string     `db:"status"`
-	CreatedAt  time.Time  `db:"created_at"`
-	CreatedBy  uuid.UUID  `db:"created_by"`
-	UpdatedAt  time.Time  `db:"updated_at"`
-	UpdatedBy  uuid.UUID  `db:"updated_by"`
-	DeletedAt  *time.Time `db:"deleted_at"`
-	DeletedBy  *uuid.UUID `db:"deleted_by"`
+	ID           uuid.UUID  `db:"id"`
+	CompanyID    uuid.UUID  `db:"company_id"`
+	BranchID     uuid.UUID  `db:"branch_id"`
+	EmployeeID   uuid.UUID  `db:"employee_id"`
+	EntryType    string     `db:"entry_type"`
+	WorkDate     time.Time  `db:"work_date"`
+	Quantity     float64    `db:"quantity"`
+	Status       string     `db:"status"`
+	PayrollRunID *uuid.UUID `db:"payroll_run_id"`
+	LeaveTypeID  *uuid.UUID `db:"leave_type_id"`
+	CreatedAt    time.Time  `db:"created_at"`
+	CreatedBy    uuid.UUID  `db:"created_by"`
+	UpdatedAt    time.Time  `db:"updated_at"`
+	UpdatedBy    uuid.UUID  `db:"updated_by"`
+	DeletedAt    *time.Time `db:"deleted_at"`
+	DeletedBy    *uuid.UUID `db:"deleted_by"`
 }
 
 type FTListResult struct {
@@ -138,6 +140,22 @@ WHERE wl.id=$1 AND e.company_id=$2 AND e.branch_id=$3 AND wl.deleted_at IS NULL
 	return &rec, nil
 }
 
+// EmployeeBranchID returns the branch of an employee in the tenant, or sql.ErrNoRows.
+func (r FTRepository) EmployeeBranchID(ctx context.Context, tenant contextx.TenantInfo, employeeID uuid.UUID) (uuid.UUID, error) {
+	db := r.dbCtx(ctx)
+	q := "SELECT branch_id FROM employees WHERE id=$1 AND company_id=$2"
+	args := []interface{}{employeeID, tenant.CompanyID}
+	if tenant.HasBranchID() {
+		q += " AND branch_id=$3"
+		args = append(args, tenant.BranchID)
+	}
+	var branchID uuid.UUID
+	if err := db.GetContext(ctx, &branchID, q, args...); err != nil {
+		return uuid.Nil, err
+	}
+	return branchID, nil
+}
+
 func (r FTRepository) ExistsActiveByEmployeeDateType(ctx context.Context, employeeID uuid.UUID, workDate time.Time, entryType string, excludeID *uuid.UUID) (bool, error) {
 	db := r.dbCtx(ctx)
 	q := `
@@ -173,12 +191,12 @@ func (r FTRepository) Insert(ctx context.Context, tenant contextx.TenantInfo, re
 	}
 
 	const insertQ = `
-INSERT INTO worklog_ft (employee_id, company_id, branch_id, entry_type, work_date, quantity, status, created_by, updated_by)
-VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+INSERT INTO worklog_ft (employee_id, company_id, branch_id, entry_type, work_date, quantity, status, leave_type_id, created_by, updated_by)
+VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
 RETURNING *`
 	var out FTRecord
 	if err := db.GetContext(ctx, &out, insertQ,
-		rec.EmployeeID, tenant.CompanyID, branchID, rec.EntryType, rec.WorkDate, rec.Quantity, rec.Status, rec.CreatedBy, rec.UpdatedBy); err != nil {
+		rec.EmployeeID, tenant.CompanyID, branchID, rec.EntryType, rec.WorkDate, rec.Quantity, rec.Status, rec.LeaveTypeID, rec.CreatedBy, rec.UpdatedBy); err != nil {
 		return nil, err
 	}
 	return &out, nil
@@ -189,17 +207,17 @@ func (r FTRepository) Update(ctx context.Context, tenant contextx.TenantInfo, id
 	// Ensure worklog belongs to an employee of this company
 	q := `
 UPDATE worklog_ft
-SET entry_type=$1, work_date=$2, quantity=$3, status=$4, updated_by=$5
+SET entry_type=$1, work_date=$2, quantity=$3, status=$4, updated_by=$5, leave_type_id=$8
 FROM employees e
 WHERE worklog_ft.id=$6 AND worklog_ft.employee_id = e.id AND e.company_id=$7 AND worklog_ft.deleted_at IS NULL
 RETURNING worklog_ft.*`
-	args := []interface{}{rec.EntryType, rec.WorkDate, rec.Quantity, rec.Status, rec.UpdatedBy, id, tenant.CompanyID}
+	args := []interface{}{rec.EntryType, rec.WorkDate, rec.Quantity, rec.Status, rec.UpdatedBy, id, tenant.CompanyID, rec.LeaveTypeID}
 	if tenant.HasBranchID() {
 		q = `
 UPDATE worklog_ft
-SET entry_type=$1, work_date=$2, quantity=$3, status=$4, updated_by=$5
+SET entry_type=$1, work_date=$2, quantity=$3, status=$4, updated_by=$5, leave_type_id=$8
 FROM employees e
-WHERE worklog_ft.id=$6 AND worklog_ft.employee_id = e.id AND e.company_id=$7 AND e.branch_id=$8 AND worklog_ft.deleted_at IS NULL
+WHERE worklog_ft.id=$6 AND worklog_ft.employee_id = e.id AND e.company_id=$7 AND e.branch_id=$9 AND worklog_ft.deleted_at IS NULL
 RETURNING worklog_ft.*`
 		args = append(args, tenant.BranchID)
 	}
@@ -34,6 +34,7 @@ type PTRecord struct {
 	TotalMinutes   int        `db:"total_minutes"`
 	TotalHours     float64    `db:"total_hours"`
 	Status         string     `db:"status"`
+	PayrollRunID   *uuid.UUID `db:"payroll_run_id"`
 	CreatedAt      time.Time  `db:"created_at"`
 	CreatedBy      uuid.UUID  `db:"created_by"`
 	UpdatedAt      time.Time  `db:"updated_at"`
@@ -154,6 +155,17 @@ SELECT EXISTS (
 	return exists, nil
 }
 
+// GetByEmployeeDate returns the employee's worklog for the day, or sql.ErrNoRows.
+func (r PTRepository) GetByEmployeeDate(ctx context.Context, employeeID uuid.UUID, workDate time.Time) (*PTRecord, error) {
+	db := r.dbCtx(ctx)
+	const q = `SELECT * FROM worklog_pt WHERE employee_id=$1 AND work_date=$2 AND deleted_at IS NULL LIMIT 1`
+	var rec PTRecord
+	if err := db.GetContext(ctx, &rec, q, employeeID, workDate); err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
 func (r PTRepository) Insert(ctx context.Context, tenant contextx.TenantInfo, rec PTRecord) (*PTRecord, error) {
 	db := r.dbCtx(ctx)
 	// Validate employee belongs to company and get branch
@@ -165,7 +177,7 @@ func (r PTRepository) Insert(ctx context.Context, tenant contextx.TenantInfo, re
 		args = append(args, tenant.BranchID)
 	}
 	if err := db.GetContext(ctx, &branchID, q, args...); err != nil {
-		return nil, fmt.Errorf("employee not found in this company")
+		return nil, fmt.Errorf("employee not found in this company: %w", err)
 	}
 
 	const insertQ = `
@@ -3,15 +3,21 @@ package repository
 import "hrms/shared/common/storage/sqldb/transactor"
 
 type Repository struct {
-	dbCtx  transactor.DBTXContext
-	FTRepo FTRepository
-	PTRepo PTRepository
+	dbCtx       transactor.DBTXContext
+	FTRepo      FTRepository
+	PTRepo      PTRepository
+	ClockRepo   ClockRepository
+	SheetRepo   TimesheetRepository
+	AnomalyRepo AnomalyRepository
 }
 
 func NewRepository(dbCtx transactor.DBTXContext) Repository {
 	return Repository{
-		dbCtx:  dbCtx,
-		FTRepo: NewFTRepository(dbCtx),
-		PTRepo: NewPTRepository(dbCtx),
+		dbCtx:       dbCtx,
+		FTRepo:      NewFTRepository(dbCtx),
+		PTRepo:      NewPTRepository(dbCtx),
+		ClockRepo:   NewClockRepository(dbCtx),
+		SheetRepo:   NewTimesheetRepository(dbCtx),
+		AnomalyRepo: NewAnomalyRepository(dbCtx),
 	}
 }
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/storage/sqldb/transactor"
+)
+
+// TimesheetRepository reads what a month of attendance looks like: the employees, their FT
+// and PT worklogs and the regular payroll run the month is paid in.
+type TimesheetRepository struct {
+	dbCtx transactor.DBTXContext
+}
+
+func NewTimesheetRepository(dbCtx transactor.DBTXContext) TimesheetRepository {
+	return TimesheetRepository{dbCtx: dbCtx}
+}
+
+// TimesheetEmployee is a full-time or part-time employee employed at some point of the month.
+type TimesheetEmployee struct {
+	ID                  uuid.UUID  `db:"id"`
+	EmployeeNumber      string     `db:"employee_number"`
+	FirstName           string     `db:"first_name"`
+	LastName            string     `db:"last_name"`
+	BranchID            uuid.UUID  `db:"branch_id"`
+	TypeCode            string     `db:"type_code"`
+	EmploymentStartDate time.Time  `db:"employment_start_date"`
+	EmploymentEndDate   *time.Time `db:"employment_end_date"`
+}
+
+type TimesheetEmployeeResult struct {
+	Rows  []TimesheetEmployee
+	Total int
+}
+
+// ListEmployees returns the tenant's full-time and part-time employees employed between from
+// and to, ordered by employee number. employeeID narrows the list to one employee.
+func (r TimesheetRepository) ListEmployees(ctx context.Context, tenant contextx.TenantInfo, employeeID *uuid.UUID, from, to time.Time, page, limit int) (TimesheetEmployeeResult, error) {
+	db := r.dbCtx(ctx)
+	where := []string{
+		"e.deleted_at IS NULL",
+		"et.code IN ('full_time','part_time')",
+		"e.company_id = $1",
+		"e.employment_start_date <= $3",
+		"(e.employment_end_date IS NULL OR e.employment_end_date >= $2)",
+	}
+	args := []interface{}{tenant.CompanyID, from, to}
+	if tenant.HasBranchID() {
+		args = append(args, tenant.BranchID)
+		where = append(where, fmt.Sprintf("e.branch_id = $%d", len(args)))
+	}
+	if employeeID != nil {
+		args = append(args, *employeeID)
+		where = append(where, fmt.Sprintf("e.id = $%d", len(args)))
+	}
+	whereClause := strings.Join(where, " AND ")
+
+	var total int
+	if err := db.GetContext(ctx, &total, fmt.Sprintf(`
+SELECT COUNT(1) FROM employees e
+JOIN employee_type et ON et.id = e.employee_type_id
+WHERE %s`, whereClause), args...); err != nil {
+		return TimesheetEmployeeResult{}, err
+	}
+
+	args = append(args, limit, (page-1)*limit)
+	var rows []TimesheetEmployee
+	if err := db.SelectContext(ctx, &rows, fmt.Sprintf(`
+SELECT e.id, e.employee_number, e.first_name, e.last_name, e.branch_id, et.code AS type_code,
+       e.employment_start_date, e.employment_end_date
+FROM employees e
+JOIN employee_type et ON et.id = e.employee_type_id
+WHERE %s
+ORDER BY e.employee_number
+LIMIT $%d OFFSET $%d`, whereClause, len(args)-1, len(args)), args...); err != nil {
+		return TimesheetEmployeeResult{}, err
+	}
+	return TimesheetEmployeeResult{Rows: rows, Total: total}, nil
+}
+
+// ListFT returns the employees' FT worklogs between from and to, in date order.
+func (r TimesheetRepository) ListFT(ctx context.Context, employeeIDs []uuid.UUID, from, to time.Time) ([]FTRecord, error) {
+	db := r.dbCtx(ctx)
+	var out []FTRecord
+	err := db.SelectContext(ctx, &out, `
+SELECT * FROM worklog_ft
+WHERE employee_id = ANY($1) AND work_date BETWEEN $2 AND $3 AND deleted_at IS NULL
+ORDER BY work_date, created_at`, pq.Array(employeeIDs), from, to)
+	return out, err
+}
+
+// TimesheetPT is a PT worklog with whether a paid PT payout already covers it.
+type TimesheetPT struct {
+	PTRecord
+	PaidOut bool `db:"paid_out"`
+}
+
+// ListPT returns the employees' PT worklogs between from and to, in date order.
+func (r TimesheetRepository) ListPT(ctx context.Context, employeeIDs []uuid.UUID, from, to time.Time) ([]TimesheetPT, error) {
+	db := r.dbCtx(ctx)
+	var out []TimesheetPT
+	err := db.SelectContext(ctx, &out, `
+SELECT w.*, EXISTS (
+    SELECT 1
+    FROM payout_pt_item pi
+    JOIN payout_pt p ON p.id = pi.payout_id
+    WHERE pi.worklog_id = w.id AND pi.deleted_at IS NULL AND p.deleted_at IS NULL AND p.status = 'paid'
+) AS paid_out
+FROM worklog_pt w
+WHERE w.employee_id = ANY($1) AND w.work_date BETWEEN $2 AND $3 AND w.deleted_at IS NULL
+ORDER BY w.work_date`, pq.Array(employeeIDs), from, to)
+	return out, err
+}
+
+// PayrollRun is the regular payroll run of a branch for a month.
+type PayrollRun struct {
+	ID              uuid.UUID `db:"id"`
+	BranchID        uuid.UUID `db:"branch_id"`
+	Status          string    `db:"status"`
+	PeriodStartDate time.Time `db:"period_start_date"`
+}
+
+// GetRegularPayrollRun returns the branch's regular payroll run for the month that has not been
+// reversed, or nil when there is none yet.
+func (r TimesheetRepository) GetRegularPayrollRun(ctx context.Context, companyID, branchID uuid.UUID, month time.Time) (*PayrollRun, error) {
+	db := r.dbCtx(ctx)
+	var out PayrollRun
+	err := db.GetContext(ctx, &out, `
+SELECT id, branch_id, status, period_start_date
+FROM payroll_run
+WHERE company_id = $1 AND branch_id = $2 AND payroll_month_date = $3
+  AND run_type = 'regular' AND status <> 'reversed' AND deleted_at IS NULL`, companyID, branchID, month)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PayrollRunItem holds the attendance quantities a payroll run stored for an employee.
+type PayrollRunItem struct {
+	RunID            uuid.UUID `db:"run_id"`
+	EmployeeID       uuid.UUID `db:"employee_id"`
+	OTWeekdayHours   float64   `db:"ot_weekday_hours"`
+	HolidayWorkHours float64   `db:"holiday_work_hours"`
+	HolidayOTHours   float64   `db:"holiday_ot_hours"`
+	OTHours          float64   `db:"ot_hours"`
+	LateMinutes      int       `db:"late_minutes_qty"`
+	LeaveDays        float64   `db:"leave_days_qty"`
+	LeaveDoubleDays  float64   `db:"leave_double_qty"`
+	LeaveHours       float64   `db:"leave_hours_qty"`
+	PTHours          float64   `db:"pt_hours_worked"`
+}
+
+// ListPayrollRunItems returns the items of the runs for the employees.
+func (r TimesheetRepository) ListPayrollRunItems(ctx context.Context, runIDs, employeeIDs []uuid.UUID) ([]PayrollRunItem, error) {
+	db := r.dbCtx(ctx)
+	var out []PayrollRunItem
+	err := db.SelectContext(ctx, &out, `
+SELECT run_id, employee_id, ot_weekday_hours, holiday_work_hours, holiday_ot_hours, ot_hours,
+       late_minutes_qty, leave_days_qty, leave_double_qty, leave_hours_qty, pt_hours_worked
+FROM payroll_run_item
+WHERE run_id = ANY($1) AND employee_id = ANY($2)`, pq.Array(runIDs), pq.Array(employeeIDs))
+	return out, err
+}
@@ -1,14 +1,18 @@
 package worklog
 
 import (
+	"hrms/modules/worklog/internal/feature/anomaly"
+	"hrms/modules/worklog/internal/feature/clock"
 	"hrms/modules/worklog/internal/feature/ft"
 	"hrms/modules/worklog/internal/feature/pt"
+	"hrms/modules/worklog/internal/feature/timesheet"
 	"hrms/modules/worklog/internal/repository"
 	"hrms/shared/common/eventbus"
 	"hrms/shared/common/jwt"
 	"hrms/shared/common/mediator"
 	"hrms/shared/common/middleware"
 	"hrms/shared/common/module"
+	"hrms/shared/contracts"
 
 	"github.com/gofiber/fiber/v3"
 )
@@ -38,6 +42,9 @@ func (m *Module) Init(eb eventbus.EventBus) error {
 	mediator.Register[*ft.CreateCommand, *ft.CreateResponse](ft.NewCreateHandler(m.repo.FTRepo, m.ctx.Transactor, eb))
 	mediator.Register[*ft.UpdateCommand, *ft.UpdateResponse](ft.NewUpdateHandler(m.repo.FTRepo, m.ctx.Transactor, eb))
 	mediator.Register[*ft.DeleteCommand, mediator.NoResponse](ft.NewDeleteHandler(m.repo.FTRepo, eb))
+	mediator.Register[*ft.BulkCommand, *ft.BulkResponse](ft.NewBulkHandler(m.repo.FTRepo, m.ctx.Transactor, eb))
+	// contract handler used by leave requests
+	mediator.Register[*contracts.CreateLeaveEntriesCommand, *contracts.CreateLeaveEntriesResponse](ft.NewLeaveEntriesHandler(m.repo.FTRepo))
 
 	// PT
 	mediator.Register[*pt.ListQuery, *pt.ListResponse](pt.NewListHandler(m.repo.PTRepo))
@@ -45,6 +52,20 @@ func (m *Module) Init(eb eventbus.EventBus) error {
 	mediator.Register[*pt.CreateCommand, *pt.CreateResponse](pt.NewCreateHandler(m.repo.PTRepo, m.ctx.Transactor, eb))
 	mediator.Register[*pt.UpdateCommand, *pt.UpdateResponse](pt.NewUpdateHandler(m.repo.PTRepo, m.ctx.Transactor, eb))
 	mediator.Register[*pt.DeleteCommand, mediator.NoResponse](pt.NewDeleteHandler(m.repo.PTRepo, eb))
+	mediator.Register[*pt.BulkCommand, *pt.BulkResponse](pt.NewBulkHandler(m.repo.PTRepo, m.ctx.Transactor, eb))
+
+	// Clock
+	mediator.Register[*clock.ListQuery, *clock.ListResponse](clock.NewListHandler(m.repo.ClockRepo))
+	mediator.Register[*clock.UpsertCommand, *clock.UpsertResponse](clock.NewUpsertHandler(m.repo.ClockRepo, eb))
+	mediator.Register[*clock.DeleteCommand, mediator.NoResponse](clock.NewDeleteHandler(m.repo.ClockRepo, eb))
+	mediator.Register[*clock.GenerateCommand, *clock.GenerateResponse](clock.NewGenerateHandler(m.repo.ClockRepo, m.repo.FTRepo, m.ctx.Transactor, eb))
+	mediator.Register[*clock.ImportCommand, *clock.ImportResponse](clock.NewImportHandler(m.repo.ClockRepo, m.repo.PTRepo, m.repo.FTRepo, m.ctx.Transactor, eb))
+
+	// Timesheet
+	mediator.Register[*timesheet.Query, *timesheet.Response](timesheet.NewHandler(m.repo.SheetRepo))
+
+	// Anomaly report
+	mediator.Register[*anomaly.Query, *anomaly.Response](anomaly.NewHandler(m.repo.AnomalyRepo))
 
 	return nil
 }
@@ -58,4 +79,13 @@ func (m *Module) RegisterRoutes(r fiber.Router) {
 	// PT
 	ptGroup := group.Group("/pt")
 	pt.Register(ptGroup)
+	// Clock records, punch log import and late/early/OT generation
+	clockGroup := group.Group("/clock")
+	clock.Register(clockGroup)
+	// Monthly timesheet per employee
+	timesheetGroup := group.Group("/timesheet")
+	timesheet.Register(timesheetGroup)
+	// Attendance anomaly report
+	anomalyGroup := group.Group("/anomalies")
+	anomaly.Register(anomalyGroup)
 }
@@ -0,0 +1,176 @@
+// Package dbtest runs module tests against a migrated database named by TEST_DB_DSN and seeds
+// the rows most payroll fixtures need. Every test body runs in a transaction that is rolled back.
+package dbtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"hrms/shared/common/contextx"
+	"hrms/shared/common/storage/sqldb"
+	"hrms/shared/common/storage/sqldb/transactor"
+)
+
+// errRollback ends every fixture transaction so nothing is kept.
+var errRollback = errors.New("rollback")
+
+// Month is the payroll month fixtures use. The seeded payroll config and org profile are
+// open-ended, so a far month has no runs yet.
+var Month = time.Date(2099, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// DB is a connection to the test database with the seeded company and admin user.
+type DB struct {
+	Tx        transactor.Transactor
+	DBTX      transactor.DBTXContext
+	CompanyID uuid.UUID
+	AdminID   uuid.UUID
+
+	seq int
+}
+
+// Open connects to TEST_DB_DSN and skips the test when it is not set.
+func Open(t *testing.T) *DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DB_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN is not set")
+	}
+	dbCtx, closeDB, err := sqldb.NewDBContext(dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { closeDB() })
+
+	d := &DB{}
+	d.Tx, d.DBTX = transactor.New(dbCtx.DB(), transactor.WithNestedTransactionStrategy(transactor.NestedTransactionsSavepoints))
+	ctx := context.Background()
+	if err := dbCtx.DB().GetContext(ctx, &d.CompanyID, `SELECT id FROM companies WHERE code = 'DEFAULT'`); err != nil {
+		t.Fatalf("load company: %v", err)
+	}
+	if err := dbCtx.DB().GetContext(ctx, &d.AdminID, `SELECT id FROM users WHERE username = 'admin' AND deleted_at IS NULL`); err != nil {
+		t.Fatalf("load admin: %v", err)
+	}
+	return d
+}
+
+// Rollback runs fn in a transaction under the company's tenant and rolls it back.
+func (d *DB) Rollback(t *testing.T, fn func(ctx context.Context, db transactor.DBTX)) {
+	t.Helper()
+	ctx := contextx.TenantToContext(context.Background(), contextx.TenantInfo{CompanyID: d.CompanyID})
+	err := d.Tx.WithinTransaction(ctx, func(ctx context.Context, _ func(transactor.PostCommitHook)) error {
+		fn(ctx, d.DBTX(ctx))
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatal(err)
+	}
+}
+
+// BranchContext scopes ctx to the company and the given branch.
+func (d *DB) BranchContext(ctx context.Context, branchID uuid.UUID) context.Context {
+	return contextx.TenantToContext(ctx, contextx.TenantInfo{CompanyID: d.CompanyID, BranchID: branchID})
+}
+
+// InsertBranch creates a branch of the company.
+func (d *DB) InsertBranch(ctx context.Context, t *testing.T, db transactor.DBTX, code string) uuid.UUID {
+	t.Helper()
+	var id uuid.UUID
+	if err := db.GetContext(ctx, &id, `
+INSERT INTO branches (company_id, code, name, created_by, updated_by)
+VALUES ($1, $2, $2, $3, $3)
+RETURNING id`, d.CompanyID, code, d.AdminID); err != nil {
+		t.Fatalf("insert branch %s: %v", code, err)
+	}
+	return id
+}
+
+// Employee describes a fixture employee; the zero values give a plain full-timer who joined
+// before Month without social security, provident fund, tax withholding or allowances.
+type Employee struct {
+	Number      string
+	BasePay     float64
+	SSOWage     float64 // 0 = not insured
+	PFRate      float64 // 0 = no provident fund
+	WithholdTax bool
+	Allowances  bool   // housing and both attendance bonuses
+	Start       string // "" = 2020-01-01
+	End         string // "" = still employed
+}
+
+// InsertEmployee adds e to the branch.
+func (d *DB) InsertEmployee(ctx context.Context, t *testing.T, db transactor.DBTX, branchID uuid.UUID, e Employee) uuid.UUID {
+	t.Helper()
+	if e.Start == "" {
+		e.Start = "2020-01-01"
+	}
+	var end *string
+	if e.End != "" {
+		end = &e.End
+	}
+	var ssoWage *float64
+	if e.SSOWage > 0 {
+		ssoWage = &e.SSOWage
+	}
+	d.seq++
+	var id uuid.UUID
+	if err := db.GetContext(ctx, &id, `
+INSERT INTO employees (
+  employee_number, title_id, first_name, last_name,
+  id_document_type_id, id_document_number, employee_type_id,
+  company_id, branch_id, base_pay_amount, employment_start_date, employment_end_date,
+  sso_contribute, sso_declared_wage, withhold_tax,
+  provident_fund_contribute, provident_fund_rate_employee, provident_fund_rate_employer,
+  allow_housing, allow_attendance_bonus_nolate, allow_attendance_bonus_noleave,
+  created_by, updated_by
+) VALUES (
+  $1, (SELECT id FROM person_title WHERE code = 'mr'), 'Test', $1,
+  (SELECT id FROM id_document_type WHERE code = 'th_cid'), $2,
+  (SELECT id FROM employee_type WHERE code = 'full_time'),
+  $3, $4, $5, $6::date, $7::date,
+  $8 IS NOT NULL, $8, $9,
+  $10::numeric > 0, $10, $10,
+  $11, $11, $11,
+  $12, $12
+)
+RETURNING id`, e.Number, fmt.Sprintf("%013d", 1100000000100+d.seq), d.CompanyID, branchID, e.BasePay, e.Start, end,
+		ssoWage, e.WithholdTax, e.PFRate, e.Allowances, d.AdminID); err != nil {
+		t.Fatalf("insert employee %s: %v", e.Number, err)
+	}
+	return id
+}
+
+// InsertRegularRun creates the pending regular run of the branch for month, which computes
+// its items from the branch's employees and pending entries.
+func (d *DB) InsertRegularRun(ctx context.Context, t *testing.T, db transactor.DBTX, branchID uuid.UUID, month time.Time) uuid.UUID {
+	t.Helper()
+	var id uuid.UUID
+	if err := db.GetContext(ctx, &id, `
+INSERT INTO payroll_run (
+  payroll_month_date, period_start_date, pay_date,
+  social_security_rate_employee, social_security_rate_employer,
+  company_id, branch_id, created_by, updated_by
+) VALUES ($1, $1, $2, 0.05, 0.05, $3, $4, $5, $5)
+RETURNING id`, month, month.AddDate(0, 1, -1), d.CompanyID, branchID, d.AdminID); err != nil {
+		t.Fatalf("insert payroll run: %v", err)
+	}
+	return id
+}
+
+// InsertWorklog records a pending full-time worklog entry.
+func (d *DB) InsertWorklog(ctx context.Context, t *testing.T, db transactor.DBTX, branchID, employeeID uuid.UUID, entryType string, date time.Time, qty float64) uuid.UUID {
+	t.Helper()
+	var id uuid.UUID
+	if err := db.GetContext(ctx, &id, `
+INSERT INTO worklog_ft (employee_id, company_id, branch_id, entry_type, work_date, quantity, status, created_by, updated_by)
+VALUES ($1, $2, $3, $4, $5, $6, 'pending', $7, $7)
+RETURNING id`, employeeID, d.CompanyID, branchID, entryType, date, qty, d.AdminID); err != nil {
+		t.Fatalf("insert %s worklog: %v", entryType, err)
+	}
+	return id
+}
@@ -0,0 +1,107 @@
+package contracts
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ===== Approval Workflow Contracts =====
+// Document modules hand approval decisions to the approval module. A document type with no
+// active approval chain is not governed (Required=false) and keeps its direct approval.
+
+// Document types that can have an approval chain
+const (
+	ApprovalDocPayrollRun       = "payroll_run"
+	ApprovalDocBonusCycle       = "bonus_cycle"
+	ApprovalDocSalaryRaiseCycle = "salary_raise_cycle"
+	ApprovalDocDebtTxn          = "debt_txn"
+	ApprovalDocLeaveRequest     = "leave_request"
+)
+
+// ApprovalStepDTO is one step of an approval request with its decision
+type ApprovalStepDTO struct {
+	StepNo         int        `json:"stepNo"`
+	Name           string     `json:"name"`
+	ApproverRole   *string    `json:"approverRole,omitempty"`
+	ApproverUserID *uuid.UUID `json:"approverUserId,omitempty"`
+	Status         string     `json:"status"`
+	ActedBy        *uuid.UUID `json:"actedBy,omitempty"`
+	ActedAt        *time.Time `json:"actedAt,omitempty"`
+	Comment        *string    `json:"comment,omitempty"`
+}
+
+// ApprovalRequestDTO represents one submission of a document through its approval chain
+type ApprovalRequestDTO struct {
+	ID            uuid.UUID         `json:"id"`
+	DocType       string            `json:"docType"`
+	DocID         uuid.UUID         `json:"docId"`
+	Status        string            `json:"status"`
+	CurrentStep   int               `json:"currentStep"`
+	StepCount     int               `json:"stepCount"`
+	PreparedBy    uuid.UUID         `json:"preparedBy"`
+	SubmitComment *string           `json:"submitComment,omitempty"`
+	SubmittedAt   time.Time         `json:"submittedAt"`
+	ClosedAt      *time.Time        `json:"closedAt,omitempty"`
+	ClosedBy      *uuid.UUID        `json:"closedBy,omitempty"`
+	Steps         []ApprovalStepDTO `json:"steps"`
+}
+
+// SubmitApprovalCommand opens an approval request for a document submitted by ActorID.
+// PreparedBy is the document's maker when that is not the submitter (zero means ActorID).
+type SubmitApprovalCommand struct {
+	CompanyID  uuid.UUID
+	BranchID   *uuid.UUID
+	DocType    string
+	DocID      uuid.UUID
+	ActorID    uuid.UUID
+	PreparedBy uuid.UUID
+	Comment    string
+}
+
+// SubmitApprovalResponse contains the opened request; Required is false when no chain applies
+type SubmitApprovalResponse struct {
+	Required bool                `json:"required"`
+	Request  *ApprovalRequestDTO `json:"request,omitempty"`
+}
+
+// DecideApprovalCommand approves or rejects the current step of a document's open request.
+// When the document has no open request yet, one is opened on behalf of PreparedBy first,
+// unless RequireSubmitted is set: then a governed document must go through SubmitApprovalCommand.
+// Preparers lists everyone who built or edited the document; none of them may decide it, whether
+// or not a chain governs the document.
+type DecideApprovalCommand struct {
+	CompanyID        uuid.UUID
+	BranchID         *uuid.UUID
+	DocType          string
+	DocID            uuid.UUID
+	PreparedBy       uuid.UUID
+	Preparers        []uuid.UUID
+	ActorID          uuid.UUID
+	ActorRole        string
+	Approve          bool
+	Comment          string
+	RequireSubmitted bool
+}
+
+// DecideApprovalResponse reports the request after the decision.
+// Final is set once the request is closed: the last step approved it, or a step rejected it.
+type DecideApprovalResponse struct {
+	Required bool                `json:"required"`
+	Final    bool                `json:"final"`
+	Request  *ApprovalRequestDTO `json:"request,omitempty"`
+}
+
+// CancelApprovalCommand withdraws a document's open request (preparer or admin)
+type CancelApprovalCommand struct {
+	CompanyID uuid.UUID
+	DocType   string
+	DocID     uuid.UUID
+	ActorID   uuid.UUID
+	ActorRole string
+}
+
+// CancelApprovalResponse reports whether an open request was withdrawn
+type CancelApprovalResponse struct {
+	Cancelled bool `json:"cancelled"`
+}
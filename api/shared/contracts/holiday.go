@@ -0,0 +1,58 @@
+package contracts
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ===== Holiday Calendar Contracts =====
+// Modules that care about working days (worklog, leave) ask the holiday module which dates
+// are holidays. A branch observes the company-wide holidays plus its own.
+
+// Holiday kinds
+const (
+	HolidayKindPublic  = "public"
+	HolidayKindCompany = "company"
+)
+
+// HolidayDTO is one day off on the calendar
+type HolidayDTO struct {
+	Date     time.Time  `json:"date"`
+	Name     string     `json:"name"`
+	Kind     string     `json:"kind"`
+	BranchID *uuid.UUID `json:"branchId,omitempty"`
+}
+
+// ListHolidaysQuery returns the holidays observed by a branch between From and To (inclusive).
+// A nil BranchID returns only the company-wide holidays.
+type ListHolidaysQuery struct {
+	CompanyID uuid.UUID
+	BranchID  *uuid.UUID
+	From      time.Time
+	To        time.Time
+}
+
+// ListHolidaysResponse contains the holidays ordered by date
+type ListHolidaysResponse struct {
+	Holidays []HolidayDTO `json:"holidays"`
+}
+
+// Contains reports whether d (compared by calendar date) is one of the holidays.
+func (r *ListHolidaysResponse) Contains(d time.Time) bool {
+	return r.Find(d) != nil
+}
+
+// Find returns the holiday on d, or nil when d is not a holiday.
+func (r *ListHolidaysResponse) Find(d time.Time) *HolidayDTO {
+	if r == nil {
+		return nil
+	}
+	day := d.Format("2006-01-02")
+	for i := range r.Holidays {
+		if r.Holidays[i].Date.Format("2006-01-02") == day {
+			return &r.Holidays[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package contracts
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ===== Leave Contracts =====
+// Worklog asks the leave module whether a leave entry fits its leave type and the employee's
+// balance before saving it. Balances are counted in days. An approved leave request asks
+// worklog to record its days.
+
+// Leave pay types
+const (
+	LeavePayPaid     = "paid"
+	LeavePayUnpaid   = "unpaid"
+	LeavePayDeducted = "deducted"
+)
+
+// LeaveTypeDTO describes a company's leave type
+type LeaveTypeDTO struct {
+	ID             uuid.UUID `json:"id"`
+	Code           string    `json:"code"`
+	Name           string    `json:"name"`
+	PayType        string    `json:"payType"`
+	EnforceBalance bool      `json:"enforceBalance"`
+}
+
+// CheckLeaveEntryQuery validates a worklog leave entry of Quantity (days, or hours for
+// leave_hours) against the leave type. ExcludeEntryID leaves out the entry being edited.
+type CheckLeaveEntryQuery struct {
+	CompanyID      uuid.UUID
+	EmployeeID     uuid.UUID
+	LeaveTypeID    uuid.UUID
+	EntryType      string
+	WorkDate       time.Time
+	Quantity       float64
+	ExcludeEntryID *uuid.UUID
+}
+
+// CheckLeaveEntryResponse reports the entry in days and the balance left before it.
+// HasQuota is false for leave types without a yearly entitlement; Remaining is then zero.
+type CheckLeaveEntryResponse struct {
+	LeaveType LeaveTypeDTO `json:"leaveType"`
+	Days      float64      `json:"days"`
+	HasQuota  bool         `json:"hasQuota"`
+	Remaining float64      `json:"remaining"`
+}
+
+// CreateLeaveEntriesCommand records an approved leave request in worklog_ft: one pending entry
+// of EntryType per date, Quantity each (days, or hours for leave_hours). The leave module has
+// already checked the balance; worklog checks the holiday calendar and existing entries.
+type CreateLeaveEntriesCommand struct {
+	CompanyID   uuid.UUID
+	EmployeeID  uuid.UUID
+	LeaveTypeID uuid.UUID
+	EntryType   string
+	Dates       []time.Time
+	Quantity    float64
+	ActorID     uuid.UUID
+}
+
+// CreateLeaveEntriesResponse contains the created worklog entries in date order
+type CreateLeaveEntriesResponse struct {
+	EntryIDs []uuid.UUID `json:"entryIds"`
+}
@@ -0,0 +1,35 @@
+package contracts
+
+import "github.com/google/uuid"
+
+// ===== Final Settlement Contracts =====
+// The employee module posts an offboarded employee's final settlement to a pending off-cycle
+// payroll run so it is paid, taxed and recorded like any other payroll item.
+
+// SettlementLine is one named amount on the payroll item. TxnID links a loan repayment line
+// to the debt_txn it settles, so approving the run approves that transaction. TaxExempt is the
+// part of an income line left out of the withholding tax base (severance under s.42(17)).
+type SettlementLine struct {
+	Name      string
+	Value     float64
+	TxnID     *uuid.UUID
+	TaxExempt float64
+}
+
+// PostSettlementCommand adds the employee to the off-cycle run and sets the settlement lines.
+// Incomes go to others_income, Deductions to others_deduction and LoanRepayments to loan_repayments
+// (linked to a pending repayment debt_txn, so approving the run brings the outstanding balance down).
+type PostSettlementCommand struct {
+	RunID          uuid.UUID
+	EmployeeID     uuid.UUID
+	ActorID        uuid.UUID
+	Incomes        []SettlementLine
+	Deductions     []SettlementLine
+	LoanRepayments []SettlementLine
+}
+
+// PostSettlementResponse contains the payroll item holding the settlement
+type PostSettlementResponse struct {
+	ItemID uuid.UUID `json:"itemId"`
+	NetPay float64   `json:"netPay"`
+}
@@ -0,0 +1,93 @@
+package contracts
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ===== Shift Contracts =====
+// The shift module owns shift definitions and which shift each employee works over a date
+// range. Worklog resolves the shift of a clock record to work out late, early-leave and OT time.
+
+// ShiftDTO is a shift's schedule. Times are minutes after midnight in local wall-clock time;
+// an end on or before the start means the shift ends the next day.
+type ShiftDTO struct {
+	ID                uuid.UUID `json:"id"`
+	Code              string    `json:"code"`
+	Name              string    `json:"name"`
+	StartMinute       int       `json:"startMinute"`
+	EndMinute         int       `json:"endMinute"`
+	BreakMinutes      int       `json:"breakMinutes"`
+	LateGraceMinutes  int       `json:"lateGraceMinutes"`
+	EarlyGraceMinutes int       `json:"earlyGraceMinutes"`
+	MinOTMinutes      int       `json:"minOtMinutes"`
+	WorkDays          []int     `json:"workDays"`
+}
+
+// Window returns when the shift starts and ends on workDate.
+func (s ShiftDTO) Window(workDate time.Time) (time.Time, time.Time) {
+	day := time.Date(workDate.Year(), workDate.Month(), workDate.Day(), 0, 0, 0, 0, workDate.Location())
+	start := day.Add(time.Duration(s.StartMinute) * time.Minute)
+	end := day.Add(time.Duration(s.EndMinute) * time.Minute)
+	if s.EndMinute <= s.StartMinute {
+		end = end.AddDate(0, 0, 1)
+	}
+	return start, end
+}
+
+// WorksOn reports whether workDate is one of the shift's working days (ISO weekdays, Monday = 1).
+func (s ShiftDTO) WorksOn(workDate time.Time) bool {
+	wd := int(workDate.Weekday())
+	if wd == 0 {
+		wd = 7
+	}
+	for _, d := range s.WorkDays {
+		if d == wd {
+			return true
+		}
+	}
+	return false
+}
+
+// ShiftAssignmentDTO is the shift an employee works from StartDate to EndDate (nil = open-ended)
+type ShiftAssignmentDTO struct {
+	EmployeeID uuid.UUID  `json:"employeeId"`
+	StartDate  time.Time  `json:"startDate"`
+	EndDate    *time.Time `json:"endDate,omitempty"`
+	Shift      ShiftDTO   `json:"shift"`
+}
+
+// ResolveShiftsQuery returns the assignments of the employees that overlap From..To (inclusive).
+// An empty EmployeeIDs returns the assignments of every employee of the company.
+type ResolveShiftsQuery struct {
+	CompanyID   uuid.UUID
+	EmployeeIDs []uuid.UUID
+	From        time.Time
+	To          time.Time
+}
+
+// ResolveShiftsResponse contains the assignments ordered by employee and start date
+type ResolveShiftsResponse struct {
+	Assignments []ShiftAssignmentDTO `json:"assignments"`
+}
+
+// For returns the shift the employee works on d (compared by calendar date), or nil when the
+// employee has no shift that day.
+func (r *ResolveShiftsResponse) For(employeeID uuid.UUID, d time.Time) *ShiftDTO {
+	if r == nil {
+		return nil
+	}
+	day := d.Format("2006-01-02")
+	for i := range r.Assignments {
+		a := &r.Assignments[i]
+		if a.EmployeeID != employeeID || a.StartDate.Format("2006-01-02") > day {
+			continue
+		}
+		if a.EndDate != nil && a.EndDate.Format("2006-01-02") < day {
+			continue
+		}
+		return &a.Shift
+	}
+	return nil
+}
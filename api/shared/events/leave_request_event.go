@@ -0,0 +1,33 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LeaveRequestEvent is published when a leave request is submitted, approved (one step or the
+// whole request) or rejected, so the people who act on it next can be notified. Action is
+// SUBMIT, APPROVE or REJECT as in the activity log and Status is the request's status after it.
+// While the request is pending, ApproverRoles and ApproverUserID name who decides it now.
+type LeaveRequestEvent struct {
+	Action         string
+	CompanyID      uuid.UUID
+	BranchID       uuid.UUID
+	RequestID      uuid.UUID
+	EmployeeID     uuid.UUID
+	RequestedBy    uuid.UUID
+	ActorID        uuid.UUID
+	Status         string
+	StartDate      time.Time
+	EndDate        time.Time
+	Days           float64
+	ApproverRoles  []string
+	ApproverUserID *uuid.UUID
+	Comment        string
+	Timestamp      time.Time
+}
+
+func (e LeaveRequestEvent) Name() string {
+	return "LeaveRequestEvent"
+}
@@ -0,0 +1,89 @@
+CREATE OR REPLACE FUNCTION payroll_org_profile_apply_to_pending_runs()
+RETURNS TRIGGER LANGUAGE plpgsql AS $$
+BEGIN
+  UPDATE payroll_run pr
+  SET org_profile_id = NEW.id,
+      org_profile_snapshot = jsonb_build_object(
+        'profile_id', NEW.id,
+        'version_no', NEW.version_no,
+        'effective_start', lower(NEW.effective_daterange),
+        'effective_end', upper(NEW.effective_daterange),
+        'company_name', NEW.company_name,
+        'address_line1', NEW.address_line1,
+        'address_line2', NEW.address_line2,
+        'subdistrict', NEW.subdistrict,
+        'district', NEW.district,
+        'province', NEW.province,
+        'postal_code', NEW.postal_code,
+        'phone_main', NEW.phone_main,
+        'phone_alt', NEW.phone_alt,
+        'email', NEW.email,
+        'tax_id', NEW.tax_id,
+        'slip_footer_note', NEW.slip_footer_note,
+        'logo_id', NEW.logo_id
+      )
+  WHERE pr.status = 'pending'
+    AND pr.deleted_at IS NULL
+    AND pr.company_id = NEW.company_id
+    AND NEW.effective_daterange @> pr.payroll_month_date;
+
+  RETURN NEW;
+END$$;
+
+CREATE OR REPLACE FUNCTION payroll_run_apply_org_profile()
+RETURNS TRIGGER LANGUAGE plpgsql AS $$
+DECLARE
+  v_profile payroll_org_profile%ROWTYPE;
+BEGIN
+  -- Always prefer explicitly set org_profile_id if provided
+  IF NEW.org_profile_id IS NULL THEN
+    -- Pass NEW.company_id to ensure we get the profile for the correct tenant
+    SELECT * INTO v_profile FROM get_effective_org_profile(NEW.payroll_month_date, NEW.company_id);
+  ELSE
+    -- If org_profile_id is provided, verify it belongs to the same company
+    SELECT * INTO v_profile FROM payroll_org_profile WHERE id = NEW.org_profile_id AND company_id = NEW.company_id;
+  END IF;
+
+  IF v_profile.id IS NULL THEN
+    RAISE EXCEPTION 'ไม่พบ org profile สำหรับบริษัท % และวันที่ %', NEW.company_id, NEW.payroll_month_date;
+  END IF;
+
+  -- Ensure the profile covers the payroll month
+  IF NOT (v_profile.effective_daterange @> NEW.payroll_month_date) THEN
+    RAISE EXCEPTION 'org profile % ไม่ครอบคลุมเดือนจ่าย %', v_profile.id, NEW.payroll_month_date;
+  END IF;
+
+  NEW.org_profile_id := v_profile.id;
+  NEW.org_profile_snapshot := jsonb_build_object(
+    'profile_id', v_profile.id,
+    'version_no', v_profile.version_no,
+    'effective_start', lower(v_profile.effective_daterange),
+    'effective_end', upper(v_profile.effective_daterange),
+    'company_name', v_profile.company_name,
+    'address_line1', v_profile.address_line1,
+    'address_line2', v_profile.address_line2,
+    'subdistrict', v_profile.subdistrict,
+    'district', v_profile.district,
+    'province', v_profile.province,
+    'postal_code', v_profile.postal_code,
+    'phone_main', v_profile.phone_main,
+    'phone_alt', v_profile.phone_alt,
+    'email', v_profile.email,
+    'tax_id', v_profile.tax_id,
+    'slip_footer_note', v_profile.slip_footer_note,
+    'logo_id', v_profile.logo_id
+  );
+
+  RETURN NEW;
+END$$;
+
+UPDATE payroll_run pr
+SET org_profile_snapshot = pr.org_profile_snapshot - 'sso_employer_account_no'
+WHERE pr.status = 'pending'
+  AND pr.deleted_at IS NULL;
+
+ALTER TABLE branches DROP CONSTRAINT IF EXISTS branches_sso_branch_no_ck;
+ALTER TABLE branches DROP COLUMN IF EXISTS sso_branch_no;
+
+ALTER TABLE payroll_org_profile DROP CONSTRAINT IF EXISTS payroll_org_profile_sso_account_ck;
+ALTER TABLE payroll_org_profile DROP COLUMN IF EXISTS sso_employer_account_no;
@@ -0,0 +1,14 @@
+UPDATE payroll_run SET status = 'pending' WHERE status = 'submitted';
+
+ALTER DOMAIN payroll_run_status DROP CONSTRAINT IF EXISTS payroll_run_status_chk;
+ALTER DOMAIN payroll_run_status ADD CONSTRAINT payroll_run_status_chk
+  CHECK (VALUE IN ('pending','approved','reversed'));
+
+DROP TABLE IF EXISTS approval_request_step;
+DROP TABLE IF EXISTS approval_request;
+DROP TABLE IF EXISTS approval_chain_step;
+DROP TABLE IF EXISTS approval_chain;
+
+DROP DOMAIN IF EXISTS approval_step_status;
+DROP DOMAIN IF EXISTS approval_request_status;
+DROP DOMAIN IF EXISTS approval_doc_type;
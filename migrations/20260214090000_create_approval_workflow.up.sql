@@ -0,0 +1,134 @@
+-- =============================================
+-- Approval Workflow (ลำดับการอนุมัติเอกสารแบบหลายขั้น / maker-checker)
+-- =============================================
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'approval_doc_type') THEN
+    -- เอกสารที่กำหนดลำดับการอนุมัติได้
+    CREATE DOMAIN approval_doc_type AS TEXT
+      CONSTRAINT approval_doc_type_chk
+      CHECK (VALUE IN ('payroll_run','bonus_cycle','salary_raise_cycle','debt_txn'));
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'approval_request_status') THEN
+    -- pending = อยู่ระหว่างอนุมัติ, approved = อนุมัติครบทุกขั้น, rejected = ตีกลับ, cancelled = ผู้จัดทำถอนเรื่อง
+    CREATE DOMAIN approval_request_status AS TEXT
+      CONSTRAINT approval_request_status_chk
+      CHECK (VALUE IN ('pending','approved','rejected','cancelled'));
+  END IF;
+
+  IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'approval_step_status') THEN
+    -- waiting = ยังไม่ถึง/รอตัดสิน, skipped = ไม่ได้พิจารณาเพราะคำขอปิดไปก่อน
+    CREATE DOMAIN approval_step_status AS TEXT
+      CONSTRAINT approval_step_status_chk
+      CHECK (VALUE IN ('waiting','approved','rejected','skipped'));
+  END IF;
+END$$;
+
+-- ===== 1) approval_chain (ลำดับการอนุมัติต่อประเภทเอกสาร) =====
+CREATE TABLE IF NOT EXISTS approval_chain (
+  id          UUID PRIMARY KEY DEFAULT uuidv7(),
+  company_id  UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+  branch_id   UUID NULL REFERENCES branches(id) ON DELETE CASCADE, -- NULL = ใช้ทั้งบริษัท, NOT NULL = เฉพาะสาขา (มาก่อนของบริษัท)
+  doc_type    approval_doc_type NOT NULL,
+  name        TEXT NOT NULL,
+  is_active   BOOLEAN NOT NULL DEFAULT TRUE,
+
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by  UUID NOT NULL REFERENCES users(id),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_by  UUID NOT NULL REFERENCES users(id),
+  deleted_at  TIMESTAMPTZ NULL,
+  deleted_by  UUID REFERENCES users(id)
+);
+
+-- เปิดใช้ได้ครั้งละหนึ่งลำดับต่อบริษัท/สาขา/ประเภทเอกสาร
+CREATE UNIQUE INDEX IF NOT EXISTS approval_chain_active_uk
+  ON approval_chain (company_id, COALESCE(branch_id, '00000000-0000-0000-0000-000000000000'::uuid), doc_type)
+  WHERE deleted_at IS NULL AND is_active;
+
+CREATE INDEX IF NOT EXISTS approval_chain_company_idx
+  ON approval_chain (company_id, doc_type)
+  WHERE deleted_at IS NULL;
+
+DROP TRIGGER IF EXISTS tg_approval_chain_set_updated ON approval_chain;
+CREATE TRIGGER tg_approval_chain_set_updated
+BEFORE UPDATE ON approval_chain
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- ===== 2) approval_chain_step (ขั้นตอน: ระบุ role หรือผู้อนุมัติเจาะจง) =====
+CREATE TABLE IF NOT EXISTS approval_chain_step (
+  chain_id          UUID NOT NULL REFERENCES approval_chain(id) ON DELETE CASCADE,
+  step_no           INT NOT NULL CHECK (step_no >= 1),
+  name              TEXT NOT NULL,
+  approver_role     TEXT NULL CHECK (approver_role IN ('admin','hr')),
+  approver_user_id  UUID NULL REFERENCES users(id),
+  PRIMARY KEY (chain_id, step_no),
+  CONSTRAINT approval_chain_step_approver_ck
+    CHECK (approver_role IS NOT NULL OR approver_user_id IS NOT NULL)
+);
+
+-- ===== 3) approval_request (คำขออนุมัติของเอกสารหนึ่งฉบับ ต่อการส่งหนึ่งครั้ง) =====
+CREATE TABLE IF NOT EXISTS approval_request (
+  id              UUID PRIMARY KEY DEFAULT uuidv7(),
+  company_id      UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+  branch_id       UUID NULL REFERENCES branches(id) ON DELETE CASCADE,
+  chain_id        UUID NOT NULL REFERENCES approval_chain(id),
+  doc_type        approval_doc_type NOT NULL,
+  doc_id          UUID NOT NULL,
+  status          approval_request_status NOT NULL DEFAULT 'pending',
+  current_step    INT NOT NULL DEFAULT 1,
+  step_count      INT NOT NULL CHECK (step_count >= 1),
+
+  prepared_by     UUID NOT NULL REFERENCES users(id),
+  submit_comment  TEXT NULL,
+  submitted_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  closed_at       TIMESTAMPTZ NULL,
+  closed_by       UUID NULL REFERENCES users(id),
+  updated_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+  CONSTRAINT approval_request_step_ck
+    CHECK (current_step BETWEEN 1 AND step_count),
+  CONSTRAINT approval_request_closed_ck
+    CHECK ((status = 'pending') = (closed_at IS NULL))
+);
+
+-- เอกสารหนึ่งฉบับมีคำขอที่ค้างอยู่ได้ครั้งละหนึ่งรายการ
+CREATE UNIQUE INDEX IF NOT EXISTS approval_request_open_uk
+  ON approval_request (doc_type, doc_id)
+  WHERE status = 'pending';
+
+CREATE INDEX IF NOT EXISTS approval_request_doc_idx
+  ON approval_request (doc_type, doc_id, submitted_at DESC);
+
+CREATE INDEX IF NOT EXISTS approval_request_inbox_idx
+  ON approval_request (company_id, status, submitted_at);
+
+DROP TRIGGER IF EXISTS tg_approval_request_set_updated ON approval_request;
+CREATE TRIGGER tg_approval_request_set_updated
+BEFORE UPDATE ON approval_request
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- ===== 4) approval_request_step (สำเนาขั้นตอนตอนส่ง + ผลการตัดสินแต่ละขั้น) =====
+-- คัดลอกจาก approval_chain_step ตอนส่ง แก้ลำดับภายหลังไม่กระทบคำขอที่ค้างอยู่
+CREATE TABLE IF NOT EXISTS approval_request_step (
+  request_id        UUID NOT NULL REFERENCES approval_request(id) ON DELETE CASCADE,
+  step_no           INT NOT NULL,
+  name              TEXT NOT NULL,
+  approver_role     TEXT NULL,
+  approver_user_id  UUID NULL REFERENCES users(id),
+  status            approval_step_status NOT NULL DEFAULT 'waiting',
+  acted_by          UUID NULL REFERENCES users(id),
+  acted_at          TIMESTAMPTZ NULL,
+  comment           TEXT NULL,
+  PRIMARY KEY (request_id, step_no),
+  CONSTRAINT approval_request_step_acted_ck
+    CHECK (status NOT IN ('approved','rejected') OR (acted_by IS NOT NULL AND acted_at IS NOT NULL))
+);
+
+-- ===== 5) payroll_run: ส่งอนุมัติแล้ว (submitted) ระหว่างรอผู้อนุมัติ =====
+-- submitted = ส่งเข้าลำดับอนุมัติแล้ว แก้ไขรายการไม่ได้ (guard ของ item ยอมเฉพาะ pending) ตีกลับแล้วคืนเป็น pending
+ALTER DOMAIN payroll_run_status DROP CONSTRAINT IF EXISTS payroll_run_status_chk;
+ALTER DOMAIN payroll_run_status ADD CONSTRAINT payroll_run_status_chk
+  CHECK (VALUE IN ('pending','submitted','approved','reversed'));
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS gl_account_mapping;
+DROP DOMAIN IF EXISTS gl_account_key;
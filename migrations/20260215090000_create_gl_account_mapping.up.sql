@@ -0,0 +1,40 @@
+-- =============================================
+-- GL Account Mapping (ผังบัญชีสำหรับบันทึกรายการเงินเดือนเข้าระบบบัญชี)
+-- =============================================
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'gl_account_key') THEN
+    -- บัญชีที่สมุดรายวันเงินเดือนใช้ (ฝั่งเดบิต: ค่าใช้จ่าย, ฝั่งเครดิต: หนี้สิน/ลูกหนี้/เงินเดือนค้างจ่าย)
+    CREATE DOMAIN gl_account_key AS TEXT
+      CONSTRAINT gl_account_key_chk
+      CHECK (VALUE IN (
+        'salary_expense','ot_expense','sso_employer_expense','pf_employer_expense',
+        'tax_payable','sso_payable','pf_payable','loan_receivable','advance_receivable',
+        'other_deduction','net_pay_payable'
+      ));
+  END IF;
+END$$;
+
+CREATE TABLE IF NOT EXISTS gl_account_mapping (
+  id             UUID PRIMARY KEY DEFAULT uuidv7(),
+  company_id     UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+  account_key    gl_account_key NOT NULL,
+  department_id  UUID NULL REFERENCES department(id) ON DELETE CASCADE, -- NULL = ค่าเริ่มต้นของบริษัท, NOT NULL = รหัสบัญชีเฉพาะแผนก (มาก่อน)
+  account_code   TEXT NOT NULL CHECK (btrim(account_code) <> ''),
+  account_name   TEXT NOT NULL,
+
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by     UUID NOT NULL REFERENCES users(id),
+  updated_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_by     UUID NOT NULL REFERENCES users(id)
+);
+
+-- หนึ่งรหัสบัญชีต่อบริษัท/บัญชี/แผนก
+CREATE UNIQUE INDEX IF NOT EXISTS gl_account_mapping_uk
+  ON gl_account_mapping (company_id, account_key, COALESCE(department_id, '00000000-0000-0000-0000-000000000000'::uuid));
+
+DROP TRIGGER IF EXISTS tg_gl_account_mapping_set_updated ON gl_account_mapping;
+CREATE TRIGGER tg_gl_account_mapping_set_updated
+BEFORE UPDATE ON gl_account_mapping
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS employee_settlement;
+
+DROP DOMAIN IF EXISTS termination_reason;
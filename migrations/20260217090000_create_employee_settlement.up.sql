@@ -0,0 +1,75 @@
+-- =============================================
+-- Employee Settlement (เงินชดเชย/ยอดจ่ายสุดท้ายเมื่อพ้นสภาพพนักงาน)
+--   severance   = ค่าชดเชยตาม พ.ร.บ.คุ้มครองแรงงาน ม.118 ตามอายุงาน
+--   notice pay  = ค่าจ้างแทนการบอกกล่าวล่วงหน้า ม.17/1
+--   leave payout = ค่าจ้างวันลาพักผ่อนที่ยังไม่ได้ใช้ ม.67
+-- =============================================
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'termination_reason') THEN
+    -- resignation = ลาออก, termination = เลิกจ้าง, termination_for_cause = เลิกจ้างโดยมีความผิด (ม.119)
+    -- contract_end = ครบสัญญาจ้าง, retirement = เกษียณอายุ
+    CREATE DOMAIN termination_reason AS TEXT
+      CONSTRAINT termination_reason_chk
+      CHECK (VALUE IN ('resignation','termination','termination_for_cause','contract_end','retirement'));
+  END IF;
+END$$;
+
+CREATE TABLE IF NOT EXISTS employee_settlement (
+  id                     UUID PRIMARY KEY DEFAULT uuidv7(),
+  company_id             UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+  branch_id              UUID NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+  employee_id            UUID NOT NULL REFERENCES employees(id),
+
+  termination_date       DATE NOT NULL,                 -- วันทำงานวันสุดท้าย (= employment_end_date)
+  reason                 termination_reason NOT NULL,
+  notice_date            DATE NULL,                     -- วันที่บอกกล่าว (NULL = บอกกล่าววันเดียวกับวันสุดท้าย)
+  tenure_days            INT NOT NULL,                  -- อายุงานนับวัน รวมวันเริ่มและวันสุดท้าย
+
+  base_pay_amount        NUMERIC(12,2) NOT NULL,        -- เงินเดือน (FT) หรือค่าจ้างรายชั่วโมง (PT) ณ วันออก
+  daily_rate             NUMERIC(12,2) NOT NULL,        -- ค่าจ้างรายวันอัตราสุดท้าย
+
+  -- เงินเดือนงวดสุดท้ายตามสัดส่วน (จ่ายในงวดปกติของเดือนนั้น เก็บไว้แสดงในเอกสาร)
+  final_month_salary     NUMERIC(12,2) NOT NULL DEFAULT 0,
+  proration_basis        payroll_proration_basis NULL,
+  proration_days         NUMERIC(6,2) NULL,
+  proration_period_days  NUMERIC(6,2) NULL,
+
+  unused_leave_days      NUMERIC(6,2) NOT NULL DEFAULT 0,
+  leave_payout           NUMERIC(12,2) NOT NULL DEFAULT 0,
+  severance_days         INT NOT NULL DEFAULT 0,
+  severance_amount       NUMERIC(12,2) NOT NULL DEFAULT 0,
+  notice_pay_days        INT NOT NULL DEFAULT 0,
+  notice_pay_amount      NUMERIC(12,2) NOT NULL DEFAULT 0,
+
+  -- ยอดเรียกคืน: หนี้คงค้างหลังหักงวดผ่อนที่งวดปกติจะหักให้ และเงินเบิกล่วงหน้าของงวดหลังวันออก
+  debt_recovery          NUMERIC(12,2) NOT NULL DEFAULT 0,
+  advance_recovery       NUMERIC(12,2) NOT NULL DEFAULT 0,
+
+  -- ยอดสุทธิ = leave_payout + severance_amount + notice_pay_amount - debt_recovery - advance_recovery (ก่อนภาษี)
+  net_amount             NUMERIC(12,2) NOT NULL DEFAULT 0,
+
+  payroll_run_id         UUID NULL REFERENCES payroll_run(id) ON DELETE SET NULL, -- งวดพิเศษ (off_cycle) ที่ลงรายการจ่าย
+  note                   TEXT NULL,
+
+  created_at             TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by             UUID NOT NULL REFERENCES users(id),
+  updated_at             TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_by             UUID NOT NULL REFERENCES users(id),
+
+  CONSTRAINT employee_settlement_notice_ck
+    CHECK (notice_date IS NULL OR notice_date <= termination_date)
+);
+
+-- พนักงานหนึ่งคนมีเอกสารพ้นสภาพได้หนึ่งฉบับ
+CREATE UNIQUE INDEX IF NOT EXISTS employee_settlement_employee_uk
+  ON employee_settlement (employee_id);
+
+CREATE INDEX IF NOT EXISTS employee_settlement_tenant_idx
+  ON employee_settlement (company_id, branch_id, termination_date);
+
+DROP TRIGGER IF EXISTS tg_employee_settlement_set_updated ON employee_settlement;
+CREATE TRIGGER tg_employee_settlement_set_updated
+BEFORE UPDATE ON employee_settlement
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
@@ -0,0 +1,5 @@
+DROP FUNCTION IF EXISTS is_company_holiday(UUID, UUID, DATE);
+
+DROP TABLE IF EXISTS company_holiday;
+
+DROP DOMAIN IF EXISTS holiday_kind;
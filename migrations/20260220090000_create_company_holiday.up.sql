@@ -0,0 +1,61 @@
+-- =============================================
+-- Company Holiday (ปฏิทินวันหยุดของบริษัท / สาขา)
+-- =============================================
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'holiday_kind') THEN
+    -- public = วันหยุดนักขัตฤกษ์, company = วันหยุดเฉพาะของบริษัท
+    CREATE DOMAIN holiday_kind AS TEXT
+      CONSTRAINT holiday_kind_chk
+      CHECK (VALUE IN ('public','company'));
+  END IF;
+END$$;
+
+CREATE TABLE IF NOT EXISTS company_holiday (
+  id            UUID PRIMARY KEY DEFAULT uuidv7(),
+  company_id    UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+  branch_id     UUID NULL REFERENCES branches(id) ON DELETE CASCADE, -- NULL = ทั้งบริษัท, NOT NULL = เฉพาะสาขา (เพิ่มจากของบริษัท)
+  holiday_date  DATE NOT NULL,
+  name          TEXT NOT NULL,
+  kind          holiday_kind NOT NULL DEFAULT 'public',
+  source_uid    TEXT NULL, -- UID ของ VEVENT เมื่อนำเข้าจากไฟล์ iCalendar
+
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by    UUID NOT NULL REFERENCES users(id),
+  updated_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_by    UUID NOT NULL REFERENCES users(id),
+  deleted_at    TIMESTAMPTZ NULL,
+  deleted_by    UUID REFERENCES users(id)
+);
+
+-- วันหนึ่งมีได้รายการเดียวต่อบริษัท/สาขา (นำเข้าซ้ำจะทับรายการเดิม)
+CREATE UNIQUE INDEX IF NOT EXISTS company_holiday_date_uk
+  ON company_holiday (company_id, COALESCE(branch_id, '00000000-0000-0000-0000-000000000000'::uuid), holiday_date)
+  WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS company_holiday_company_date_idx
+  ON company_holiday (company_id, holiday_date)
+  WHERE deleted_at IS NULL;
+
+DROP TRIGGER IF EXISTS tg_company_holiday_set_updated ON company_holiday;
+CREATE TRIGGER tg_company_holiday_set_updated
+BEFORE UPDATE ON company_holiday
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- เป็นวันหยุดของพนักงานในสาขานี้หรือไม่ (วันหยุดของบริษัท + วันหยุดเฉพาะสาขา)
+CREATE OR REPLACE FUNCTION is_company_holiday(
+  p_company_id UUID,
+  p_branch_id  UUID,
+  p_date       DATE
+) RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+  SELECT EXISTS (
+    SELECT 1
+    FROM company_holiday h
+    WHERE h.company_id = p_company_id
+      AND (h.branch_id IS NULL OR h.branch_id = p_branch_id)
+      AND h.holiday_date = p_date
+      AND h.deleted_at IS NULL
+  );
+$$;
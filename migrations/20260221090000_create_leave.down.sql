@@ -0,0 +1,21 @@
+DROP FUNCTION IF EXISTS tenure_months(DATE, DATE);
+DROP FUNCTION IF EXISTS leave_usage(UUID, UUID, INT, UUID);
+DROP FUNCTION IF EXISTS leave_entry_days(TEXT, NUMERIC, UUID, DATE);
+
+DROP TABLE IF EXISTS leave_balance;
+
+DROP INDEX IF EXISTS worklog_ft_leave_type_idx;
+ALTER TABLE worklog_ft DROP CONSTRAINT IF EXISTS worklog_ft_leave_type_ck;
+ALTER TABLE worklog_ft DROP COLUMN IF EXISTS leave_type_id;
+
+DROP TABLE IF EXISTS leave_entitlement_rule;
+DROP TABLE IF EXISTS leave_type;
+
+-- ลาแบบได้รับค่าจ้างไม่มีในระบบเดิม
+DELETE FROM worklog_ft WHERE entry_type = 'leave_paid';
+
+ALTER DOMAIN work_entry_type DROP CONSTRAINT IF EXISTS work_entry_type_chk;
+ALTER DOMAIN work_entry_type ADD CONSTRAINT work_entry_type_chk
+  CHECK (VALUE IN ('late','leave_day','leave_double','leave_hours','ot','holiday_work','holiday_ot'));
+
+DROP DOMAIN IF EXISTS leave_pay_type;
@@ -0,0 +1,148 @@
+-- =============================================
+-- Leave (ประเภทการลา สิทธิ์ลาต่อปีตามอายุงาน การยกยอด และยอดคงเหลือรายพนักงาน)
+--   paid     = ลาโดยได้รับค่าจ้าง ใช้สิทธิ์ ไม่หักเงิน (บันทึกเป็น worklog_ft.leave_paid)
+--   unpaid   = ลาไม่รับค่าจ้าง หักตามวัน/ชั่วโมง (leave_day / leave_hours)
+--   deducted = ลาที่ถูกหักแบบทวีคูณ (leave_double)
+-- ยอดที่ใช้ไป = worklog_ft ที่ผูก leave_type_id และอนุมัติแล้ว (ลารายชั่วโมงคิดเป็นวันตาม work_hours_per_day)
+-- =============================================
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'leave_pay_type') THEN
+    CREATE DOMAIN leave_pay_type AS TEXT
+      CONSTRAINT leave_pay_type_chk
+      CHECK (VALUE IN ('paid','unpaid','deducted'));
+  END IF;
+END$$;
+
+-- ลาโดยได้รับค่าจ้าง: ไม่เข้าสูตรหักเงินใน payroll
+ALTER DOMAIN work_entry_type DROP CONSTRAINT IF EXISTS work_entry_type_chk;
+ALTER DOMAIN work_entry_type ADD CONSTRAINT work_entry_type_chk
+  CHECK (VALUE IN ('late','leave_day','leave_double','leave_hours','leave_paid','ot','holiday_work','holiday_ot'));
+
+-- ===== 1) leave_type (ประเภทการลาต่อบริษัท) =====
+CREATE TABLE IF NOT EXISTS leave_type (
+  id                   UUID PRIMARY KEY DEFAULT uuidv7(),
+  company_id           UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+  code                 TEXT NOT NULL,
+  name                 TEXT NOT NULL,
+  pay_type             leave_pay_type NOT NULL,
+  enforce_balance      BOOLEAN NOT NULL DEFAULT TRUE,          -- ห้ามลาเกินยอดคงเหลือ (เฉพาะประเภทที่มีสิทธิ์ต่อปี)
+  carry_over_max_days  NUMERIC(5,2) NOT NULL DEFAULT 0 CHECK (carry_over_max_days >= 0), -- 0 = ไม่ยกยอดไปปีถัดไป
+  is_active            BOOLEAN NOT NULL DEFAULT TRUE,
+
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by  UUID NOT NULL REFERENCES users(id),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_by  UUID NOT NULL REFERENCES users(id),
+  deleted_at  TIMESTAMPTZ NULL,
+  deleted_by  UUID REFERENCES users(id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS leave_type_code_uk
+  ON leave_type (company_id, lower(code))
+  WHERE deleted_at IS NULL;
+
+DROP TRIGGER IF EXISTS tg_leave_type_set_updated ON leave_type;
+CREATE TRIGGER tg_leave_type_set_updated
+BEFORE UPDATE ON leave_type
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- ===== 2) leave_entitlement_rule (สิทธิ์ต่อปีตามอายุงาน) =====
+-- ใช้แถวที่ min_tenure_months มากที่สุดที่ไม่เกินอายุงาน ณ สิ้นปี, ประเภทที่ไม่มีแถวเลย = ไม่จำกัดสิทธิ์
+CREATE TABLE IF NOT EXISTS leave_entitlement_rule (
+  leave_type_id      UUID NOT NULL REFERENCES leave_type(id) ON DELETE CASCADE,
+  min_tenure_months  INT NOT NULL CHECK (min_tenure_months >= 0),
+  days_per_year      NUMERIC(5,2) NOT NULL CHECK (days_per_year >= 0),
+  PRIMARY KEY (leave_type_id, min_tenure_months)
+);
+
+-- ===== 3) worklog_ft ผูกประเภทการลา (leave_paid ต้องระบุ, ประเภทอื่นที่ไม่ใช่การลาห้ามระบุ) =====
+ALTER TABLE worklog_ft
+  ADD COLUMN IF NOT EXISTS leave_type_id UUID NULL REFERENCES leave_type(id);
+
+ALTER TABLE worklog_ft DROP CONSTRAINT IF EXISTS worklog_ft_leave_type_ck;
+ALTER TABLE worklog_ft ADD CONSTRAINT worklog_ft_leave_type_ck
+  CHECK (
+    CASE
+      WHEN entry_type = 'leave_paid' THEN leave_type_id IS NOT NULL
+      WHEN entry_type IN ('leave_day','leave_double','leave_hours') THEN TRUE
+      ELSE leave_type_id IS NULL
+    END
+  );
+
+CREATE INDEX IF NOT EXISTS worklog_ft_leave_type_idx
+  ON worklog_ft (employee_id, leave_type_id, work_date)
+  WHERE deleted_at IS NULL AND leave_type_id IS NOT NULL;
+
+-- ===== 4) leave_balance (ยอดสิทธิ์ต่อพนักงาน/ประเภท/ปี) =====
+CREATE TABLE IF NOT EXISTS leave_balance (
+  id               UUID PRIMARY KEY DEFAULT uuidv7(),
+  company_id       UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+  employee_id      UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+  leave_type_id    UUID NOT NULL REFERENCES leave_type(id) ON DELETE CASCADE,
+  balance_year     INT NOT NULL CHECK (balance_year BETWEEN 2000 AND 2100),
+  entitled_days    NUMERIC(6,2) NOT NULL DEFAULT 0,   -- สิทธิ์ของปีตามอายุงาน
+  carried_days     NUMERIC(6,2) NOT NULL DEFAULT 0,   -- ยกยอดจากปีก่อน (ไม่เกิน carry_over_max_days)
+  adjustment_days  NUMERIC(6,2) NOT NULL DEFAULT 0,   -- ปรับเพิ่ม/ลดโดย HR
+  note             TEXT NULL,
+
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by  UUID NOT NULL REFERENCES users(id),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_by  UUID NOT NULL REFERENCES users(id),
+
+  CONSTRAINT leave_balance_uk UNIQUE (employee_id, leave_type_id, balance_year)
+);
+
+CREATE INDEX IF NOT EXISTS leave_balance_company_year_idx
+  ON leave_balance (company_id, balance_year);
+
+DROP TRIGGER IF EXISTS tg_leave_balance_set_updated ON leave_balance;
+CREATE TRIGGER tg_leave_balance_set_updated
+BEFORE UPDATE ON leave_balance
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- ===== 5) ยอดที่ใช้ไปต่อปี =====
+-- จำนวนวันของรายการลา: ลารายชั่วโมงหารด้วยชั่วโมงทำงานต่อวันของ config ที่มีผล ณ วันนั้น
+CREATE OR REPLACE FUNCTION leave_entry_days(
+  p_entry_type  TEXT,
+  p_quantity    NUMERIC,
+  p_company_id  UUID,
+  p_work_date   DATE
+) RETURNS NUMERIC
+LANGUAGE sql STABLE AS $$
+  SELECT CASE
+    WHEN p_entry_type = 'leave_hours' THEN
+      ROUND(p_quantity / COALESCE(NULLIF((get_effective_payroll_config(p_work_date, p_company_id)).work_hours_per_day, 0), 8.0), 4)
+    ELSE p_quantity
+  END;
+$$;
+
+-- used = อนุมัติแล้ว, pending = รออนุมัติ (p_exclude_id ไม่นับรายการที่กำลังแก้ไข)
+CREATE OR REPLACE FUNCTION leave_usage(
+  p_employee_id    UUID,
+  p_leave_type_id  UUID,
+  p_year           INT,
+  p_exclude_id     UUID DEFAULT NULL
+) RETURNS TABLE (used_days NUMERIC, pending_days NUMERIC)
+LANGUAGE sql STABLE AS $$
+  SELECT
+    COALESCE(SUM(leave_entry_days(wl.entry_type, wl.quantity, wl.company_id, wl.work_date)) FILTER (WHERE wl.status = 'approved'), 0),
+    COALESCE(SUM(leave_entry_days(wl.entry_type, wl.quantity, wl.company_id, wl.work_date)) FILTER (WHERE wl.status = 'pending'), 0)
+  FROM worklog_ft wl
+  WHERE wl.employee_id = p_employee_id
+    AND wl.leave_type_id = p_leave_type_id
+    AND wl.deleted_at IS NULL
+    AND wl.work_date BETWEEN make_date(p_year, 1, 1) AND make_date(p_year, 12, 31)
+    AND (p_exclude_id IS NULL OR wl.id <> p_exclude_id);
+$$;
+
+-- อายุงานเป็นเดือนเต็ม ณ วันที่กำหนด
+CREATE OR REPLACE FUNCTION tenure_months(p_start DATE, p_as_of DATE)
+RETURNS INT
+LANGUAGE sql IMMUTABLE AS $$
+  SELECT CASE WHEN p_as_of < p_start THEN 0
+    ELSE (EXTRACT(YEAR FROM age(p_as_of, p_start)) * 12 + EXTRACT(MONTH FROM age(p_as_of, p_start)))::INT
+  END;
+$$;
@@ -0,0 +1,31 @@
+DROP FUNCTION IF EXISTS leave_usage(UUID, UUID, INT, UUID, UUID);
+CREATE OR REPLACE FUNCTION leave_usage(
+  p_employee_id    UUID,
+  p_leave_type_id  UUID,
+  p_year           INT,
+  p_exclude_id     UUID DEFAULT NULL
+) RETURNS TABLE (used_days NUMERIC, pending_days NUMERIC)
+LANGUAGE sql STABLE AS $$
+  SELECT
+    COALESCE(SUM(leave_entry_days(wl.entry_type, wl.quantity, wl.company_id, wl.work_date)) FILTER (WHERE wl.status = 'approved'), 0),
+    COALESCE(SUM(leave_entry_days(wl.entry_type, wl.quantity, wl.company_id, wl.work_date)) FILTER (WHERE wl.status = 'pending'), 0)
+  FROM worklog_ft wl
+  WHERE wl.employee_id = p_employee_id
+    AND wl.leave_type_id = p_leave_type_id
+    AND wl.deleted_at IS NULL
+    AND wl.work_date BETWEEN make_date(p_year, 1, 1) AND make_date(p_year, 12, 31)
+    AND (p_exclude_id IS NULL OR wl.id <> p_exclude_id);
+$$;
+
+DROP TABLE IF EXISTS leave_request;
+
+DELETE FROM approval_request_step s USING approval_request r
+WHERE s.request_id = r.id AND r.doc_type = 'leave_request';
+DELETE FROM approval_request WHERE doc_type = 'leave_request';
+DELETE FROM approval_chain WHERE doc_type = 'leave_request';
+
+ALTER DOMAIN approval_doc_type DROP CONSTRAINT IF EXISTS approval_doc_type_chk;
+ALTER DOMAIN approval_doc_type ADD CONSTRAINT approval_doc_type_chk
+  CHECK (VALUE IN ('payroll_run','bonus_cycle','salary_raise_cycle','debt_txn'));
+
+DROP DOMAIN IF EXISTS leave_request_status;
@@ -0,0 +1,98 @@
+-- =============================================
+-- Leave Request (คำขอลา → ลำดับการอนุมัติ → สร้าง worklog_ft อัตโนมัติเมื่ออนุมัติ)
+--   pending   = รออนุมัติ (นับเป็นยอดรออนุมัติของสิทธิ์ลา)
+--   approved  = อนุมัติแล้ว สร้างรายการลาใน worklog_ft สถานะ approved ครบทุกวัน
+--   rejected  = ไม่อนุมัติ
+--   cancelled = ผู้ขอยกเลิกก่อนอนุมัติ
+-- =============================================
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'leave_request_status') THEN
+    CREATE DOMAIN leave_request_status AS TEXT
+      CONSTRAINT leave_request_status_chk
+      CHECK (VALUE IN ('pending','approved','rejected','cancelled'));
+  END IF;
+END$$;
+
+-- คำขอลาใช้ลำดับการอนุมัติได้
+ALTER DOMAIN approval_doc_type DROP CONSTRAINT IF EXISTS approval_doc_type_chk;
+ALTER DOMAIN approval_doc_type ADD CONSTRAINT approval_doc_type_chk
+  CHECK (VALUE IN ('payroll_run','bonus_cycle','salary_raise_cycle','debt_txn','leave_request'));
+
+-- ===== 1) leave_request =====
+CREATE TABLE IF NOT EXISTS leave_request (
+  id              UUID PRIMARY KEY DEFAULT uuidv7(),
+  company_id      UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+  branch_id       UUID NOT NULL REFERENCES branches(id) ON DELETE CASCADE, -- สาขาของพนักงาน ณ วันที่ขอ
+  employee_id     UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+  leave_type_id   UUID NOT NULL REFERENCES leave_type(id),
+  entry_type      work_entry_type NOT NULL CHECK (entry_type IN ('leave_day','leave_double','leave_hours','leave_paid')),
+  start_date      DATE NOT NULL,
+  end_date        DATE NOT NULL,
+  hours           NUMERIC(5,2) NULL CHECK (hours IS NULL OR hours > 0), -- ลาบางชั่วโมง (วันเดียว, leave_hours)
+  work_dates      DATE[] NOT NULL,      -- วันที่จะบันทึกลา (ไม่รวมเสาร์-อาทิตย์และวันหยุดของสาขา)
+  days            NUMERIC(6,2) NOT NULL CHECK (days > 0), -- วันลาที่ใช้สิทธิ์
+  reason          TEXT NULL,
+  status          leave_request_status NOT NULL DEFAULT 'pending',
+  decided_at      TIMESTAMPTZ NULL,
+  decided_by      UUID NULL REFERENCES users(id),
+  decision_comment TEXT NULL,
+
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by  UUID NOT NULL REFERENCES users(id),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_by  UUID NOT NULL REFERENCES users(id),
+
+  CONSTRAINT leave_request_range_ck
+    CHECK (end_date >= start_date AND date_part('year', start_date) = date_part('year', end_date)),
+  CONSTRAINT leave_request_hours_ck
+    CHECK ((entry_type = 'leave_hours') = (hours IS NOT NULL) AND (hours IS NULL OR start_date = end_date)),
+  CONSTRAINT leave_request_decided_ck
+    CHECK (status = 'pending' OR decided_at IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS leave_request_company_status_idx
+  ON leave_request (company_id, status, start_date);
+
+CREATE INDEX IF NOT EXISTS leave_request_employee_idx
+  ON leave_request (employee_id, leave_type_id, start_date);
+
+DROP TRIGGER IF EXISTS tg_leave_request_set_updated ON leave_request;
+CREATE TRIGGER tg_leave_request_set_updated
+BEFORE UPDATE ON leave_request
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- ===== 2) ยอดที่ใช้ไป: คำขอที่รออนุมัตินับเป็น pending ด้วย =====
+-- p_exclude_request_id ไม่นับคำขอที่กำลังพิจารณา
+DROP FUNCTION IF EXISTS leave_usage(UUID, UUID, INT, UUID);
+CREATE OR REPLACE FUNCTION leave_usage(
+  p_employee_id         UUID,
+  p_leave_type_id       UUID,
+  p_year                INT,
+  p_exclude_id          UUID DEFAULT NULL,
+  p_exclude_request_id  UUID DEFAULT NULL
+) RETURNS TABLE (used_days NUMERIC, pending_days NUMERIC)
+LANGUAGE sql STABLE AS $$
+  SELECT
+    w.used_days,
+    w.pending_days + COALESCE((
+      SELECT SUM(lr.days) FROM leave_request lr
+      WHERE lr.employee_id = p_employee_id
+        AND lr.leave_type_id = p_leave_type_id
+        AND lr.status = 'pending'
+        AND lr.start_date BETWEEN make_date(p_year, 1, 1) AND make_date(p_year, 12, 31)
+        AND (p_exclude_request_id IS NULL OR lr.id <> p_exclude_request_id)
+    ), 0)
+  FROM (
+    SELECT
+      COALESCE(SUM(leave_entry_days(wl.entry_type, wl.quantity, wl.company_id, wl.work_date)) FILTER (WHERE wl.status = 'approved'), 0) AS used_days,
+      COALESCE(SUM(leave_entry_days(wl.entry_type, wl.quantity, wl.company_id, wl.work_date)) FILTER (WHERE wl.status = 'pending'), 0) AS pending_days
+    FROM worklog_ft wl
+    WHERE wl.employee_id = p_employee_id
+      AND wl.leave_type_id = p_leave_type_id
+      AND wl.deleted_at IS NULL
+      AND wl.work_date BETWEEN make_date(p_year, 1, 1) AND make_date(p_year, 12, 31)
+      AND (p_exclude_id IS NULL OR wl.id <> p_exclude_id)
+  ) w;
+$$;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS worklog_clock;
+DROP TABLE IF EXISTS employee_shift;
+DROP TABLE IF EXISTS work_shift;
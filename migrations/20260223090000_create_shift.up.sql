@@ -0,0 +1,116 @@
+-- =============================================
+-- Work Shift (กะการทำงาน, การกำหนดกะให้พนักงานตามช่วงวันที่ และเวลาเข้า-ออกจริง)
+-- เวลาเข้า-ออกจริง (worklog_clock) เทียบกับกะเพื่อคำนวณ นาทีสาย / นาทีออกก่อน / OT
+-- แล้วสร้าง worklog_ft สถานะ pending ให้ตรวจสอบ
+-- =============================================
+
+-- ===== 1) work_shift (กะต่อบริษัท) =====
+-- end_time <= start_time = กะข้ามวัน (ออกงานวันถัดไป)
+CREATE TABLE IF NOT EXISTS work_shift (
+  id                   UUID PRIMARY KEY DEFAULT uuidv7(),
+  company_id           UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+  code                 TEXT NOT NULL,
+  name                 TEXT NOT NULL,
+  start_time           TIME NOT NULL,
+  end_time             TIME NOT NULL,
+  break_minutes        INT NOT NULL DEFAULT 60 CHECK (break_minutes >= 0),
+  late_grace_minutes   INT NOT NULL DEFAULT 0 CHECK (late_grace_minutes >= 0),   -- มาสายไม่เกินนี้ไม่นับสาย
+  early_grace_minutes  INT NOT NULL DEFAULT 0 CHECK (early_grace_minutes >= 0),  -- ออกก่อนไม่เกินนี้ไม่นับ
+  min_ot_minutes       INT NOT NULL DEFAULT 30 CHECK (min_ot_minutes >= 0),      -- อยู่ต่อหลังเลิกกะอย่างน้อยเท่านี้จึงเสนอเป็น OT
+  work_days            SMALLINT[] NOT NULL DEFAULT '{1,2,3,4,5}', -- วันทำงาน ISO (1 = จันทร์ ... 7 = อาทิตย์)
+  is_active            BOOLEAN NOT NULL DEFAULT TRUE,
+
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by  UUID NOT NULL REFERENCES users(id),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_by  UUID NOT NULL REFERENCES users(id),
+  deleted_at  TIMESTAMPTZ NULL,
+  deleted_by  UUID REFERENCES users(id),
+
+  CONSTRAINT work_shift_time_ck CHECK (start_time <> end_time),
+  CONSTRAINT work_shift_days_ck CHECK (cardinality(work_days) > 0 AND work_days <@ '{1,2,3,4,5,6,7}'::SMALLINT[])
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS work_shift_code_uk
+  ON work_shift (company_id, lower(code))
+  WHERE deleted_at IS NULL;
+
+DROP TRIGGER IF EXISTS tg_work_shift_set_updated ON work_shift;
+CREATE TRIGGER tg_work_shift_set_updated
+BEFORE UPDATE ON work_shift
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- ===== 2) employee_shift (กะของพนักงานตามช่วงวันที่) =====
+-- end_date NULL = ใช้ต่อไปจนกว่าจะเปลี่ยนกะ, ช่วงของพนักงานคนเดียวกันห้ามทับกัน
+CREATE TABLE IF NOT EXISTS employee_shift (
+  id           UUID PRIMARY KEY DEFAULT uuidv7(),
+  company_id   UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+  employee_id  UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+  shift_id     UUID NOT NULL REFERENCES work_shift(id),
+  start_date   DATE NOT NULL,
+  end_date     DATE NULL,
+
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by  UUID NOT NULL REFERENCES users(id),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_by  UUID NOT NULL REFERENCES users(id),
+
+  CONSTRAINT employee_shift_range_ck CHECK (end_date IS NULL OR end_date >= start_date),
+  CONSTRAINT employee_shift_no_overlap
+    EXCLUDE USING gist (
+      employee_id WITH =,
+      daterange(start_date, end_date, '[]') WITH &&
+    )
+);
+
+CREATE INDEX IF NOT EXISTS employee_shift_company_idx
+  ON employee_shift (company_id, start_date);
+
+DROP TRIGGER IF EXISTS tg_employee_shift_set_updated ON employee_shift;
+CREATE TRIGGER tg_employee_shift_set_updated
+BEFORE UPDATE ON employee_shift
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- ===== 3) worklog_clock (เวลาเข้า-ออกจริงต่อวัน) =====
+-- เวลาเป็นเวลาท้องถิ่นแบบเดียวกับ worklog_pt, work_date = วันของกะ (กะข้ามวันออกงานวันถัดไปได้)
+-- late/early/ot_minutes บันทึกตอนคำนวณล่าสุด (processed_at)
+CREATE TABLE IF NOT EXISTS worklog_clock (
+  id             UUID PRIMARY KEY DEFAULT uuidv7(),
+  company_id     UUID NOT NULL REFERENCES companies(id) ON DELETE CASCADE,
+  branch_id      UUID NOT NULL REFERENCES branches(id) ON DELETE CASCADE,
+  employee_id    UUID NOT NULL REFERENCES employees(id),
+  work_date      DATE NOT NULL,
+  clock_in       TIMESTAMP NOT NULL,
+  clock_out      TIMESTAMP NULL,
+  source         TEXT NOT NULL DEFAULT 'manual' CHECK (source IN ('manual','device')),
+  note           TEXT NULL,
+
+  shift_id       UUID NULL REFERENCES work_shift(id),
+  late_minutes   INT NULL,
+  early_minutes  INT NULL,
+  ot_minutes     INT NULL,
+  processed_at   TIMESTAMPTZ NULL,
+
+  created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  created_by  UUID NOT NULL REFERENCES users(id),
+  updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_by  UUID NOT NULL REFERENCES users(id),
+  deleted_at  TIMESTAMPTZ NULL,
+  deleted_by  UUID REFERENCES users(id),
+
+  CONSTRAINT worklog_clock_out_ck CHECK (clock_out IS NULL OR clock_out > clock_in),
+  CONSTRAINT worklog_clock_in_ck CHECK (clock_in >= work_date - INTERVAL '1 day' AND clock_in < work_date + INTERVAL '2 days')
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS worklog_clock_employee_date_uk
+  ON worklog_clock (employee_id, work_date)
+  WHERE deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS worklog_clock_company_date_idx
+  ON worklog_clock (company_id, work_date)
+  WHERE deleted_at IS NULL;
+
+DROP TRIGGER IF EXISTS tg_worklog_clock_set_updated ON worklog_clock;
+CREATE TRIGGER tg_worklog_clock_set_updated
+BEFORE UPDATE ON worklog_clock
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
@@ -0,0 +1,347 @@
+-- คืนฟังก์ชันก่อนผูกยอดเรียกคืนกับงวด
+-- =============================================
+-- อนุมัติงวด: ปิด worklog/เงินเบิก/หนี้เฉพาะงวดปกติ ส่วนยอดสะสมภาษี/ประกันสังคม/รายได้ บวกเพิ่มทุกงวด
+-- =============================================
+CREATE OR REPLACE FUNCTION public.payroll_run_on_approve_actions() RETURNS trigger AS $$
+DECLARE
+  v_end_date DATE;
+  v_year INT;
+BEGIN
+  -- ทำงานเฉพาะเมื่อมีการเปลี่ยนสถานะเป็น 'approved'
+  IF NEW.status = 'approved' AND OLD.status <> 'approved' THEN
+    
+    v_end_date := (NEW.payroll_month_date + interval '1 month' - interval '1 day')::date;
+    v_year := EXTRACT(YEAR FROM NEW.payroll_month_date)::INT;
+
+    -- งวดเสริม (off-cycle / bonus_only / correction) ไม่ได้ดึง worklog, เงินเบิกล่วงหน้า และค่างวดหนี้อัตโนมัติ
+    -- จึงไม่ปิดสถานะรายการเหล่านั้น ปล่อยให้งวดปกติของเดือนเป็นผู้ปิด
+    IF NEW.run_type = 'regular' THEN
+
+    -- =================================================================
+    -- 1. อัปเดตสถานะ Worklog (FT & PT) -> Approved
+    -- =================================================================
+    UPDATE worklog_ft w
+    SET status = 'approved',
+        updated_at = now(),
+        updated_by = NEW.updated_by
+    FROM payroll_run_item pri
+    WHERE pri.run_id = NEW.id
+      AND w.employee_id = pri.employee_id
+      AND w.work_date >= NEW.period_start_date AND w.work_date <= v_end_date
+      AND w.status = 'pending'
+      AND w.deleted_at IS NULL;
+
+    UPDATE worklog_pt w
+    SET status = 'approved',
+        updated_at = now(),
+        updated_by = NEW.updated_by
+    FROM payroll_run_item pri
+    WHERE pri.run_id = NEW.id
+      AND w.employee_id = pri.employee_id
+      AND w.work_date >= NEW.period_start_date AND w.work_date <= v_end_date
+      AND w.status = 'pending'
+      AND w.deleted_at IS NULL;
+
+    -- =================================================================
+    -- 2. อัปเดต Salary Advance -> Processed
+    -- =================================================================
+    UPDATE salary_advance sa
+    SET status = 'processed',
+        updated_at = now(),
+        updated_by = NEW.updated_by
+    FROM payroll_run_item pri
+    WHERE pri.run_id = NEW.id
+      AND sa.employee_id = pri.employee_id
+      AND sa.payroll_month_date = NEW.payroll_month_date
+      AND sa.status = 'pending'
+      AND sa.deleted_at IS NULL;
+
+    -- =================================================================
+    -- 3. อัปเดต Debt Transaction -> Approved
+    -- =================================================================
+    UPDATE debt_txn dt
+    SET status = 'approved',
+        updated_at = now(),
+        updated_by = NEW.updated_by
+    FROM payroll_run_item pri,
+         jsonb_array_elements(pri.loan_repayments) AS elem
+    WHERE pri.run_id = NEW.id
+      AND elem->>'txn_id' IS NOT NULL
+      AND dt.id = (elem->>'txn_id')::uuid
+      AND dt.status = 'pending'
+      AND dt.deleted_at IS NULL;
+
+    UPDATE debt_txn dt
+    SET status = 'approved',
+        updated_at = now(),
+        updated_by = NEW.updated_by
+    FROM payroll_run_item pri
+    WHERE pri.run_id = NEW.id
+      AND dt.employee_id = pri.employee_id
+      AND dt.payroll_month_date = NEW.payroll_month_date
+      AND dt.txn_type = 'repayment'
+      AND dt.status = 'pending'
+      AND dt.deleted_at IS NULL;
+
+    END IF;
+
+    -- =================================================================
+    -- 4. อัปเดต Payroll Accumulation (SSO, Tax, Income, PF) with company_id
+    -- =================================================================
+    
+    -- 4.1 SSO (รายปี)
+    INSERT INTO payroll_accumulation (
+      employee_id, company_id, accum_type, accum_year, amount, updated_at, updated_by
+    )
+    SELECT 
+      pri.employee_id, pri.company_id, 'sso', v_year, pri.sso_month_amount, now(), NEW.updated_by
+    FROM payroll_run_item pri
+    WHERE pri.run_id = NEW.id AND pri.sso_month_amount > 0
+    ON CONFLICT (employee_id, accum_type, COALESCE(accum_year, -1))
+    DO UPDATE SET 
+      amount = payroll_accumulation.amount + EXCLUDED.amount,
+      updated_at = EXCLUDED.updated_at,
+      updated_by = EXCLUDED.updated_by;
+
+    -- 4.2 TAX (รายปี)
+    INSERT INTO payroll_accumulation (
+      employee_id, company_id, accum_type, accum_year, amount, updated_at, updated_by
+    )
+    SELECT 
+      pri.employee_id, pri.company_id, 'tax', v_year, pri.tax_month_amount, now(), NEW.updated_by
+    FROM payroll_run_item pri
+    WHERE pri.run_id = NEW.id AND pri.tax_month_amount > 0
+    ON CONFLICT (employee_id, accum_type, COALESCE(accum_year, -1))
+    DO UPDATE SET 
+      amount = payroll_accumulation.amount + EXCLUDED.amount,
+      updated_at = EXCLUDED.updated_at,
+      updated_by = EXCLUDED.updated_by;
+
+    -- 4.3 Income (รายปี)
+    INSERT INTO payroll_accumulation (
+      employee_id, company_id, accum_type, accum_year, amount, updated_at, updated_by
+    )
+    SELECT 
+      pri.employee_id, pri.company_id, 'income', v_year, pri.income_total, now(), NEW.updated_by
+    FROM payroll_run_item pri
+    WHERE pri.run_id = NEW.id AND pri.income_total > 0
+    ON CONFLICT (employee_id, accum_type, COALESCE(accum_year, -1))
+    DO UPDATE SET 
+      amount = payroll_accumulation.amount + EXCLUDED.amount,
+      updated_at = EXCLUDED.updated_at,
+      updated_by = EXCLUDED.updated_by;
+
+    -- 4.4 Provident Fund (ตลอดชีพ / accum_year = NULL)
+    INSERT INTO payroll_accumulation (
+      employee_id, company_id, accum_type, accum_year, amount, updated_at, updated_by
+    )
+    SELECT 
+      pri.employee_id, pri.company_id, 'pf', NULL, pri.pf_month_amount, now(), NEW.updated_by
+    FROM payroll_run_item pri
+    WHERE pri.run_id = NEW.id AND pri.pf_month_amount > 0
+    ON CONFLICT (employee_id, accum_type, COALESCE(accum_year, -1))
+    DO UPDATE SET 
+      amount = payroll_accumulation.amount + EXCLUDED.amount,
+      updated_at = EXCLUDED.updated_at,
+      updated_by = EXCLUDED.updated_by;
+
+    -- 4.5 Loan Outstanding (ตลอดชีพ / accum_year = NULL)
+    -- อัพเดท/เซ็ตค่าหนี้สินคงค้างปัจจุบันของพนักงาน
+    INSERT INTO payroll_accumulation (
+      employee_id, company_id, accum_type, accum_year, amount, updated_at, updated_by
+    )
+    SELECT 
+      pri.employee_id, pri.company_id, 'loan_outstanding', NULL, pri.loan_outstanding_total, now(), NEW.updated_by
+    FROM payroll_run_item pri
+    WHERE pri.run_id = NEW.id
+    ON CONFLICT (employee_id, accum_type, COALESCE(accum_year, -1))
+    DO UPDATE SET 
+      amount = EXCLUDED.amount,  -- Replace with new total, not add
+      updated_at = EXCLUDED.updated_at,
+      updated_by = EXCLUDED.updated_by;
+
+  END IF;
+
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- =============================================
+-- payroll_run_reverse: ย้อนผลของ payroll_run_on_approve_actions สำหรับงวดที่อนุมัติแล้ว
+--   - ยอดสะสม sso/tax/income (รายปี) และ pf หักคืนตามรายการในงวด
+--   - หนี้คงค้าง (loan_outstanding) คืนเป็นยอดก่อนงวด
+--   - ค่างวดหนี้ที่ตัดในงวด กลับเป็น pending
+--   - งวดปกติ: worklog, เงินเบิกล่วงหน้า และรายการคืนเงินของเดือน กลับเป็น pending
+-- ย้อนได้เฉพาะงวดล่าสุดของพนักงานเหล่านั้น (ไม่มีงวดที่อนุมัติทีหลังทับอยู่)
+-- =============================================
+CREATE OR REPLACE FUNCTION public.payroll_run_reverse(p_run_id UUID, p_actor UUID, p_reason TEXT)
+RETURNS void LANGUAGE plpgsql AS $$
+DECLARE
+  v_run RECORD;
+  v_end_date DATE;
+  v_year INT;
+BEGIN
+  IF COALESCE(btrim(p_reason), '') = '' THEN
+    RAISE EXCEPTION 'reversal reason is required';
+  END IF;
+
+  SELECT * INTO v_run FROM payroll_run WHERE id = p_run_id FOR UPDATE;
+  IF NOT FOUND OR v_run.deleted_at IS NOT NULL THEN
+    RAISE EXCEPTION 'payroll_run % not found', p_run_id;
+  END IF;
+  IF v_run.status <> 'approved' THEN
+    RAISE EXCEPTION 'only approved payroll_run can be reversed (current: %)', v_run.status;
+  END IF;
+
+  IF EXISTS (
+    SELECT 1
+    FROM payroll_run_item pri
+    JOIN payroll_run_item later_item ON later_item.employee_id = pri.employee_id
+    JOIN payroll_run later ON later.id = later_item.run_id
+    WHERE pri.run_id = v_run.id
+      AND later.id <> v_run.id
+      AND later.company_id = v_run.company_id
+      AND later.status = 'approved'
+      AND later.deleted_at IS NULL
+      AND later.approved_at > v_run.approved_at
+  ) THEN
+    RAISE EXCEPTION 'payroll_run % has later approved runs for the same employees; reverse those first', p_run_id
+      USING ERRCODE = 'P0001', HINT = 'later_run_approved';
+  END IF;
+
+  PERFORM set_config('hrms.payroll_reversal', p_run_id::text, true);
+
+  v_end_date := (v_run.payroll_month_date + interval '1 month' - interval '1 day')::date;
+  v_year := EXTRACT(YEAR FROM v_run.payroll_month_date)::INT;
+
+  -- 1. ยอดสะสม
+  UPDATE payroll_accumulation pa
+  SET amount = pa.amount - x.amount,
+      updated_at = now(),
+      updated_by = p_actor
+  FROM (
+    SELECT pri.employee_id, 'sso'::text AS accum_type, v_year AS accum_year, pri.sso_month_amount AS amount
+    FROM payroll_run_item pri WHERE pri.run_id = v_run.id AND pri.sso_month_amount > 0
+    UNION ALL
+    SELECT pri.employee_id, 'tax', v_year, pri.tax_month_amount
+    FROM payroll_run_item pri WHERE pri.run_id = v_run.id AND pri.tax_month_amount > 0
+    UNION ALL
+    SELECT pri.employee_id, 'income', v_year, pri.income_total
+    FROM payroll_run_item pri WHERE pri.run_id = v_run.id AND pri.income_total > 0
+    UNION ALL
+    SELECT pri.employee_id, 'pf', NULL, pri.pf_month_amount
+    FROM payroll_run_item pri WHERE pri.run_id = v_run.id AND pri.pf_month_amount > 0
+  ) x
+  WHERE pa.employee_id = x.employee_id
+    AND pa.accum_type = x.accum_type
+    AND COALESCE(pa.accum_year, -1) = COALESCE(x.accum_year, -1);
+
+  UPDATE payroll_accumulation pa
+  SET amount = COALESCE(pri.loan_outstanding_prev, 0),
+      updated_at = now(),
+      updated_by = p_actor
+  FROM payroll_run_item pri
+  WHERE pri.run_id = v_run.id
+    AND pa.employee_id = pri.employee_id
+    AND pa.accum_type = 'loan_outstanding'
+    AND pa.accum_year IS NULL;
+
+  -- 2. ค่างวดหนี้ที่ตัดในงวดนี้
+  UPDATE debt_txn dt
+  SET status = 'pending',
+      updated_at = now(),
+      updated_by = p_actor
+  FROM payroll_run_item pri,
+       jsonb_array_elements(pri.loan_repayments) AS elem
+  WHERE pri.run_id = v_run.id
+    AND elem->>'txn_id' IS NOT NULL
+    AND dt.id = (elem->>'txn_id')::uuid
+    AND dt.status = 'approved'
+    AND dt.deleted_at IS NULL;
+
+  IF v_run.run_type = 'regular' THEN
+    UPDATE debt_txn dt
+    SET status = 'pending',
+        updated_at = now(),
+        updated_by = p_actor
+    FROM payroll_run_item pri
+    WHERE pri.run_id = v_run.id
+      AND dt.employee_id = pri.employee_id
+      AND dt.payroll_month_date = v_run.payroll_month_date
+      AND dt.txn_type = 'repayment'
+      AND dt.status = 'approved'
+      AND dt.deleted_at IS NULL;
+
+    -- 3. เงินเบิกล่วงหน้า
+    UPDATE salary_advance sa
+    SET status = 'pending',
+        updated_at = now(),
+        updated_by = p_actor
+    FROM payroll_run_item pri
+    WHERE pri.run_id = v_run.id
+      AND sa.employee_id = pri.employee_id
+      AND sa.payroll_month_date = v_run.payroll_month_date
+      AND sa.status = 'processed'
+      AND sa.deleted_at IS NULL;
+
+    -- 4. Worklog
+    UPDATE worklog_ft w
+    SET status = 'pending',
+        updated_at = now(),
+        updated_by = p_actor
+    FROM payroll_run_item pri
+    WHERE pri.run_id = v_run.id
+      AND w.employee_id = pri.employee_id
+      AND w.work_date >= v_run.period_start_date AND w.work_date <= v_end_date
+      AND w.status = 'approved'
+      AND w.deleted_at IS NULL;
+
+    UPDATE worklog_pt w
+    SET status = 'pending',
+        updated_at = now(),
+        updated_by = p_actor
+    FROM payroll_run_item pri
+    WHERE pri.run_id = v_run.id
+      AND w.employee_id = pri.employee_id
+      AND w.work_date >= v_run.period_start_date AND w.work_date <= v_end_date
+      AND w.status = 'approved'
+      AND w.deleted_at IS NULL;
+  END IF;
+
+  UPDATE payroll_run
+  SET status = 'reversed',
+      reversed_at = now(),
+      reversed_by = p_actor,
+      reversal_reason = btrim(p_reason),
+      updated_by = p_actor
+  WHERE id = v_run.id;
+
+  PERFORM set_config('hrms.payroll_reversal', '', true);
+END;
+$$;
+
+-- Guard: หลังเป็น processed แล้ว ห้ามแก้ไขใด ๆ ยกเว้นคืนเป็น pending ตอนกลับรายการงวดเงินเดือน
+CREATE OR REPLACE FUNCTION salary_advance_guard_update()
+RETURNS TRIGGER LANGUAGE plpgsql AS $$
+BEGIN
+  IF OLD.status = 'processed' THEN
+    IF (NEW.employee_id IS DISTINCT FROM OLD.employee_id)
+      OR (NEW.payroll_month_date IS DISTINCT FROM OLD.payroll_month_date)
+      OR (NEW.advance_date IS DISTINCT FROM OLD.advance_date)
+      OR (NEW.amount IS DISTINCT FROM OLD.amount)
+      OR (NEW.deleted_at IS DISTINCT FROM OLD.deleted_at)
+      OR (NEW.deleted_by IS DISTINCT FROM OLD.deleted_by)
+      OR (NEW.status <> 'processed' AND NOT (NEW.status = 'pending' AND payroll_reversal_in_progress()))
+    THEN
+      RAISE EXCEPTION 'Processed advance cannot be modified or deleted';
+    END IF;
+  END IF;
+
+  RETURN NEW;
+END$$;
+
+
+ALTER TABLE employee_settlement DROP COLUMN IF EXISTS debt_recovery_txn_id;
+
+DROP INDEX IF EXISTS salary_advance_payroll_run_idx;
+ALTER TABLE salary_advance DROP COLUMN IF EXISTS payroll_run_id;
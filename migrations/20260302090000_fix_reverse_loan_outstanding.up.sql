@@ -0,0 +1,135 @@
+-- =============================================
+-- แก้การย้อนงวด: หนี้คงค้าง (loan_outstanding) เดิมถูกเขียนทับด้วยยอดก่อนงวด (loan_outstanding_prev)
+-- ทำให้หนี้/การชำระที่บันทึกหลังอนุมัติงวดหายไป ตอนนี้หักเฉพาะส่วนต่างที่งวดนี้เปลี่ยนไป
+-- เหมือนยอดสะสมอื่น
+-- =============================================
+
+-- =============================================
+-- payroll_run_reverse: ย้อนผลของ payroll_run_on_approve_actions สำหรับงวดที่อนุมัติแล้ว
+--   - ยอดสะสม sso/tax/income (รายปี) และ pf หักคืนตามรายการในงวด
+--   - หนี้คงค้าง (loan_outstanding) หักส่วนต่างที่งวดนี้เปลี่ยนไป (ยอดก่อนงวด − ยอดหลังงวด)
+--     หนี้/การชำระที่บันทึกหลังอนุมัติงวดยังคงอยู่
+--   - รายการหนี้ เงินเบิกล่วงหน้า และ worklog ที่งวดนี้ปิด (payroll_run_id = งวดนี้) กลับเป็น pending และปลดการผูกงวด
+--     รายการที่อนุมัติเองนอกงวด หรือถูกงวดอื่นปิด ไม่ถูกแตะ
+-- ย้อนได้เฉพาะงวดล่าสุดของพนักงานเหล่านั้น (ไม่มีงวดที่อนุมัติทีหลังทับอยู่)
+-- =============================================
+CREATE OR REPLACE FUNCTION public.payroll_run_reverse(p_run_id UUID, p_actor UUID, p_reason TEXT)
+RETURNS void LANGUAGE plpgsql AS $$
+DECLARE
+  v_run RECORD;
+  v_year INT;
+BEGIN
+  IF COALESCE(btrim(p_reason), '') = '' THEN
+    RAISE EXCEPTION 'reversal reason is required';
+  END IF;
+
+  SELECT * INTO v_run FROM payroll_run WHERE id = p_run_id FOR UPDATE;
+  IF NOT FOUND OR v_run.deleted_at IS NOT NULL THEN
+    RAISE EXCEPTION 'payroll_run % not found', p_run_id;
+  END IF;
+  IF v_run.status <> 'approved' THEN
+    RAISE EXCEPTION 'only approved payroll_run can be reversed (current: %)', v_run.status;
+  END IF;
+
+  IF EXISTS (
+    SELECT 1
+    FROM payroll_run_item pri
+    JOIN payroll_run_item later_item ON later_item.employee_id = pri.employee_id
+    JOIN payroll_run later ON later.id = later_item.run_id
+    WHERE pri.run_id = v_run.id
+      AND later.id <> v_run.id
+      AND later.company_id = v_run.company_id
+      AND later.status = 'approved'
+      AND later.deleted_at IS NULL
+      AND later.approved_at > v_run.approved_at
+  ) THEN
+    RAISE EXCEPTION 'payroll_run % has later approved runs for the same employees; reverse those first', p_run_id
+      USING ERRCODE = 'P0001', HINT = 'later_run_approved';
+  END IF;
+
+  PERFORM set_config('hrms.payroll_reversal', p_run_id::text, true);
+
+  v_year := EXTRACT(YEAR FROM v_run.payroll_month_date)::INT;
+
+  -- 1. ยอดสะสม
+  UPDATE payroll_accumulation pa
+  SET amount = pa.amount - x.amount,
+      updated_at = now(),
+      updated_by = p_actor
+  FROM (
+    SELECT pri.employee_id, 'sso'::text AS accum_type, v_year AS accum_year, pri.sso_month_amount AS amount
+    FROM payroll_run_item pri WHERE pri.run_id = v_run.id AND pri.sso_month_amount > 0
+    UNION ALL
+    SELECT pri.employee_id, 'tax', v_year, pri.tax_month_amount
+    FROM payroll_run_item pri WHERE pri.run_id = v_run.id AND pri.tax_month_amount > 0
+    UNION ALL
+    SELECT pri.employee_id, 'income', v_year, pri.income_total
+    FROM payroll_run_item pri WHERE pri.run_id = v_run.id AND pri.income_total > 0
+    UNION ALL
+    SELECT pri.employee_id, 'pf', NULL, pri.pf_month_amount
+    FROM payroll_run_item pri WHERE pri.run_id = v_run.id AND pri.pf_month_amount > 0
+  ) x
+  WHERE pa.employee_id = x.employee_id
+    AND pa.accum_type = x.accum_type
+    AND COALESCE(pa.accum_year, -1) = COALESCE(x.accum_year, -1);
+
+  UPDATE payroll_accumulation pa
+  SET amount = pa.amount + (COALESCE(pri.loan_outstanding_prev, 0) - COALESCE(pri.loan_outstanding_total, 0)),
+      updated_at = now(),
+      updated_by = p_actor
+  FROM payroll_run_item pri
+  WHERE pri.run_id = v_run.id
+    AND pa.employee_id = pri.employee_id
+    AND pa.accum_type = 'loan_outstanding'
+    AND pa.accum_year IS NULL;
+
+  -- 2. ค่างวดหนี้และรายการคืนเงินที่งวดนี้ปิด
+  UPDATE debt_txn dt
+  SET status = 'pending',
+      payroll_run_id = NULL,
+      updated_at = now(),
+      updated_by = p_actor
+  WHERE dt.payroll_run_id = v_run.id
+    AND dt.status = 'approved'
+    AND dt.deleted_at IS NULL;
+
+  -- 3. เงินเบิกล่วงหน้าที่งวดนี้หัก กลับเป็น pending และปลดการผูกงวด
+  UPDATE salary_advance sa
+  SET status = 'pending',
+      payroll_run_id = NULL,
+      updated_at = now(),
+      updated_by = p_actor
+  WHERE sa.payroll_run_id = v_run.id
+    AND sa.status = 'processed'
+    AND sa.deleted_at IS NULL;
+
+  -- 4. Worklog ที่งวดนี้ปิด
+  UPDATE worklog_ft w
+  SET status = 'pending',
+      payroll_run_id = NULL,
+      updated_at = now(),
+      updated_by = p_actor
+  WHERE w.payroll_run_id = v_run.id
+    AND w.status = 'approved'
+    AND w.deleted_at IS NULL;
+
+  UPDATE worklog_pt w
+  SET status = 'pending',
+      payroll_run_id = NULL,
+      updated_at = now(),
+      updated_by = p_actor
+  WHERE w.payroll_run_id = v_run.id
+    AND w.status = 'approved'
+    AND w.deleted_at IS NULL;
+
+  UPDATE payroll_run
+  SET status = 'reversed',
+      reversed_at = now(),
+      reversed_by = p_actor,
+      reversal_reason = btrim(p_reason),
+      updated_by = p_actor
+  WHERE id = v_run.id;
+
+  PERFORM set_config('hrms.payroll_reversal', '', true);
+END;
+$$;